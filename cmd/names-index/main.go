@@ -0,0 +1,88 @@
+// Command names-index builds the binary index of a names-by-state dataset
+// directory. It is run through go generate:
+//
+//	go generate ./data/namesbystate
+//
+// With -embed it also writes index_embed.go so the index is compiled into
+// the dataset package alongside the text files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/curtiscovington/ssa-names/internal/index"
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+const embedSource = `// Code generated by names-index; DO NOT EDIT.
+
+package %s
+
+import "embed"
+
+//go:embed %s
+var indexFiles embed.FS
+
+func init() {
+	Files = newIndexedFS(textFiles, indexFiles)
+}
+`
+
+func main() {
+	dir := flag.String("dir", ".", "dataset directory containing the state .TXT files")
+	out := flag.String("o", "", "output path (defaults to names.idx in -dir)")
+	embed := flag.Bool("embed", false, "also write index_embed.go embedding the index into the dataset package")
+	pkg := flag.String("package", "namesbystate", "package name for index_embed.go")
+	flag.Parse()
+
+	if *out == "" {
+		*out = filepath.Join(*dir, index.FileName)
+	}
+
+	if err := build(*dir, *out); err != nil {
+		log.Fatal(err)
+	}
+
+	if *embed {
+		source := fmt.Sprintf(embedSource, *pkg, filepath.Base(*out))
+		if err := os.WriteFile(filepath.Join(*dir, "index_embed.go"), []byte(source), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func build(dir, out string) error {
+	// A plain directory never serves its names.idx to the loaders, so the
+	// text files are always the source.
+	fsys := os.DirFS(dir)
+
+	states, err := namesdata.States(fsys)
+	if err != nil {
+		return err
+	}
+
+	builder := index.NewBuilder()
+	for _, state := range states {
+		records, err := namesdata.LoadStateRecords(fsys, state)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			builder.Add(index.Record(r))
+		}
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if _, err := builder.WriteTo(file); err != nil {
+		file.Close()
+		return fmt.Errorf("write %s: %w", out, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,94 @@
+//go:build js && wasm
+
+// Command names-wasm exposes the dataset queries to JavaScript when compiled
+// with GOOS=js GOARCH=wasm. It registers a global "ssaNames" object whose
+// functions accept a plain options object and return plain JS values, or an
+// object with an "error" field when the query fails. The queries run on the
+// embedded dataset until loadFiles replaces it with files the page fetched.
+// The queries themselves live in internal/wasmapi; this file only converts
+// values.
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	dataset "github.com/curtiscovington/ssa-names/data/namesbystate"
+	"github.com/curtiscovington/ssa-names/internal/wasmapi"
+)
+
+// current is the dataset the queries run on. JS calls into Go one at a time,
+// so it needs no locking.
+var current = wasmapi.New(dataset.Files)
+
+func main() {
+	api := js.Global().Get("Object").New()
+	api.Set("topNames", export(func(raw string) string { return current.Top(raw) }))
+	api.Set("trend", export(func(raw string) string { return current.Trend(raw) }))
+	api.Set("generate", export(func(raw string) string { return current.Generate(raw) }))
+	api.Set("loadFiles", js.FuncOf(loadFiles))
+	js.Global().Set("ssaNames", api)
+
+	// Keep the Go runtime alive so the exported functions stay callable.
+	select {}
+}
+
+// export wraps query as a JS function: its options object is passed to
+// query as JSON, and the JSON query returns is parsed back into a JS value.
+func export(query func(string) string) js.Func {
+	json := js.Global().Get("JSON")
+	return js.FuncOf(func(_ js.Value, args []js.Value) any {
+		options := "{}"
+		if len(args) > 0 && args[0].Type() == js.TypeObject {
+			options = json.Call("stringify", args[0]).String()
+		}
+		return json.Call("parse", query(options))
+	})
+}
+
+// loadFiles answers the JavaScript loadFiles({"CA.TXT": buffer, ...}), whose
+// values are ArrayBuffers or typed arrays such as those fetch returns, by
+// making those files the dataset every later query runs on. It returns
+// {files: n}, or {error: "..."} leaving the current dataset in place.
+func loadFiles(_ js.Value, args []js.Value) any {
+	result := js.Global().Get("Object").New()
+	files, err := copyFiles(args)
+	if err == nil {
+		var loaded *wasmapi.Dataset
+		if loaded, err = wasmapi.Load(files); err == nil {
+			current = loaded
+			result.Set("files", len(files))
+			return result
+		}
+	}
+	result.Set("error", err.Error())
+	return result
+}
+
+// copyFiles copies the byte buffers in the object args[0] into Go memory,
+// keyed by the object's property names.
+func copyFiles(args []js.Value) (map[string][]byte, error) {
+	if len(args) == 0 || args[0].Type() != js.TypeObject {
+		return nil, fmt.Errorf("loadFiles expects an object mapping file names to ArrayBuffers")
+	}
+	uint8Array := js.Global().Get("Uint8Array")
+	arrayBuffer := js.Global().Get("ArrayBuffer")
+	names := js.Global().Get("Object").Call("keys", args[0])
+	files := make(map[string][]byte, names.Length())
+	for i := range names.Length() {
+		name := names.Index(i).String()
+		value := args[0].Get(name)
+		switch {
+		case value.InstanceOf(arrayBuffer):
+			value = uint8Array.New(value)
+		case arrayBuffer.Call("isView", value).Bool():
+			value = uint8Array.New(value.Get("buffer"), value.Get("byteOffset"), value.Get("byteLength"))
+		default:
+			return nil, fmt.Errorf("%s: expected an ArrayBuffer or typed array", name)
+		}
+		data := make([]byte, value.Length())
+		js.CopyBytesToGo(data, value)
+		files[name] = data
+	}
+	return files, nil
+}
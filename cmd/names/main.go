@@ -1,10 +1,11 @@
 package main
 
 import (
-	"log"
 	"os"
 
 	dataset "github.com/curtiscovington/ssa-names/data/namesbystate"
+	"github.com/curtiscovington/ssa-names/data/namesbyterritory"
+	"github.com/curtiscovington/ssa-names/data/namesnational"
 	"github.com/curtiscovington/ssa-names/internal/cli"
 )
 
@@ -13,7 +14,11 @@ var version = "dev"
 func main() {
 	cli.Version = version
 	app := cli.NewApp(dataset.Files, os.Stdout, os.Stderr)
+	app.National = cli.NationalDataset(namesnational.Files, cli.DefaultNationalDataDir())
+	app.Territories = cli.TerritoryDataset(namesbyterritory.Files, cli.DefaultTerritoryDataDir())
+	app.Stdin = os.Stdin
+	app.ConfigPath = cli.DefaultConfigPath()
 	if err := app.Run(os.Args[1:]); err != nil {
-		log.Fatal(err)
+		os.Exit(app.HandleError(err))
 	}
 }
@@ -1,8 +1,74 @@
 package namesbystate
 
-import "embed"
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/curtiscovington/ssa-names/internal/index"
+)
+
+//go:generate go run ../../cmd/names-index -dir . -embed
+
+// Files holds the embedded names-by-state dataset. When the build-time index
+// has been generated (go generate ./data/namesbystate), Files also serves it
+// as names.idx and loaders use it instead of parsing the text files. Files is
+// an fs.FS rather than an embed.FS so the index can be overlaid.
+var Files fs.FS = textFiles
 
-// Files holds the embedded names-by-state dataset.
-//
 //go:embed *.TXT
-var Files embed.FS
+var textFiles embed.FS
+
+// indexedFS overlays the embedded index files onto the text files and parses
+// the index on first use.
+type indexedFS struct {
+	text   fs.FS
+	index  fs.FS
+	parsed func() (*index.Index, error)
+}
+
+func newIndexedFS(text, indexFiles fs.FS) *indexedFS {
+	return &indexedFS{
+		text:  text,
+		index: indexFiles,
+		parsed: sync.OnceValues(func() (*index.Index, error) {
+			data, err := fs.ReadFile(indexFiles, index.FileName)
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", index.FileName, err)
+			}
+			return index.Parse(data)
+		}),
+	}
+}
+
+// Index returns the parsed embedded index.
+func (f *indexedFS) Index() (*index.Index, error) {
+	return f.parsed()
+}
+
+func (f *indexedFS) Open(name string) (fs.File, error) {
+	if file, err := f.index.Open(name); err == nil {
+		return file, nil
+	}
+	return f.text.Open(name)
+}
+
+func (f *indexedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(f.text, name)
+	if err != nil {
+		return nil, err
+	}
+	if name != "." {
+		return entries, nil
+	}
+	extra, err := fs.ReadDir(f.index, name)
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, extra...)
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return entries, nil
+}
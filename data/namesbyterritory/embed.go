@@ -0,0 +1,15 @@
+// Package namesbyterritory embeds the SSA territory files, PR.TXT and TR.TXT,
+// when they are unzipped into this directory before building. The repository
+// ships only README, so the territory data is download-only: run names
+// update-data --territories to fetch it.
+package namesbyterritory
+
+import "embed"
+
+// Files holds the embedded names-by-territory dataset: README, plus the SSA
+// PR.TXT and TR.TXT files, in the same format as the state files, when a
+// local build unzipped them here. Loaders read only .TXT files, so README is
+// never taken for data.
+//
+//go:embed [A-Z]*
+var Files embed.FS
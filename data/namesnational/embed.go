@@ -0,0 +1,13 @@
+// Package namesnational embeds the SSA national yobYYYY.txt files when they
+// are unzipped into this directory before building. The repository ships only
+// README.txt, so the national data is download-only: run names update-data
+// --national to fetch it.
+package namesnational
+
+import "embed"
+
+// Files holds the embedded national dataset: README.txt, plus the SSA
+// yobYYYY.txt files when a local build unzipped them here.
+//
+//go:embed *.txt
+var Files embed.FS
@@ -0,0 +1,386 @@
+// Package actuarial estimates how many of the people born in past years are
+// still alive, by applying life tables of survival probabilities by birth
+// year, age, and sex to yearly birth counts.
+//
+// The estimates rest on a few simplifying assumptions:
+//   - Survival depends only on birth year, age, and sex. Cohort life tables,
+//     such as those of SSA Actuarial Study No. 120, follow each birth year
+//     through the mortality it actually met; a period LifeTable instead
+//     applies one year's mortality to every cohort.
+//   - Nobody migrates. Everyone counted at birth is assumed to remain in the
+//     population being estimated, and immigrants are not added.
+//   - Someone born in year Y is age asOf-Y in year asOf.
+//
+// Default returns the period table embedded from lifetable.csv. It is not
+// SSA data but a Gompertz-Makeham model calibrated to approximate recent US
+// period life tables, so its estimates are rough. ParseTable loads a
+// published table, such as the Study No. 120 cohort tables, instead.
+package actuarial
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed lifetable.csv
+var defaultLifeTable string
+
+// Table gives survival probabilities by birth cohort.
+type Table interface {
+	// CohortSurvival returns the probability that someone born in
+	// birthYear is still alive at age: sex "M" or "F" selects a column,
+	// and any other value averages the two.
+	CohortSurvival(birthYear int, sex string, age int) float64
+}
+
+// LifeTable holds the fraction of births surviving to each exact age, by
+// sex.
+type LifeTable struct {
+	male   []float64
+	female []float64
+}
+
+var loadDefault = sync.OnceValue(func() Table {
+	table, err := ParseLifeTable(strings.NewReader(defaultLifeTable))
+	if err != nil {
+		panic(fmt.Sprintf("actuarial: embedded life table: %v", err))
+	}
+	return table
+})
+
+// Default returns the embedded period life table, an approximate model
+// applied to every birth year.
+func Default() Table {
+	return loadDefault()
+}
+
+var errNoRows = errors.New("life table has no rows")
+
+// ParseTable reads a life table in either CSV form, telling them apart by
+// the header: "age,male,female" for a period table (see ParseLifeTable) or
+// "birth_year,age,male,female" for cohort tables (see
+// ParseCohortLifeTable).
+func ParseTable(r io.Reader) (Table, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := csvHeader(string(data))
+	if strings.HasPrefix(header, "birth_year,") {
+		return ParseCohortLifeTable(strings.NewReader(string(data)))
+	}
+	return ParseLifeTable(strings.NewReader(string(data)))
+}
+
+// csvHeader returns the first line of data that is neither blank nor a
+// comment, lowercased and without spaces.
+func csvHeader(data string) (string, bool) {
+	for _, line := range strings.Split(data, "\n") {
+		text := strings.TrimSpace(line)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		return strings.ToLower(strings.ReplaceAll(text, " ", "")), true
+	}
+	return "", false
+}
+
+// ParseLifeTable reads a life table in CSV form: a header row, then one row
+// per age from 0 upward holding the age and the male and female survivors to
+// that exact age (lx), e.g. "0,100000,100000". Survivors are scaled by the
+// age-0 row, so any radix works. Blank lines and lines starting with # are
+// ignored.
+func ParseLifeTable(r io.Reader) (*LifeTable, error) {
+	table := &LifeTable{}
+	scanner := bufio.NewScanner(r)
+	header := false
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if !header {
+			header = true
+			continue
+		}
+
+		fields := strings.Split(text, ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected age,male,female", line)
+		}
+		age, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+		if err != nil || age != len(table.male) {
+			return nil, fmt.Errorf("line %d: expected age %d", line, len(table.male))
+		}
+		var survivors [2]float64
+		for i, field := range fields[1:] {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("line %d: invalid survivor count %q", line, field)
+			}
+			survivors[i] = v
+		}
+		table.male = append(table.male, survivors[0])
+		table.female = append(table.female, survivors[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(table.male) == 0 {
+		return nil, errNoRows
+	}
+	if err := table.normalize(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// normalize checks that survivors never increase with age and scales them
+// by the age-0 row.
+func (t *LifeTable) normalize() error {
+	for _, column := range [][]float64{t.male, t.female} {
+		radix := column[0]
+		if radix <= 0 {
+			return errors.New("life table survivors at age 0 must be positive")
+		}
+		for age := 1; age < len(column); age++ {
+			if column[age] > column[age-1] {
+				return fmt.Errorf("life table survivors increase at age %d", age)
+			}
+		}
+		for age := range column {
+			column[age] /= radix
+		}
+	}
+	return nil
+}
+
+// MaxAge returns the oldest age in the table. Survival beyond it is zero.
+func (t *LifeTable) MaxAge() int {
+	return len(t.male) - 1
+}
+
+// Survival returns the probability that someone born age years ago is
+// still alive: sex "M" or "F" selects a column, and any other value averages
+// the two.
+func (t *LifeTable) Survival(sex string, age int) float64 {
+	if age < 0 || age > t.MaxAge() {
+		return 0
+	}
+	switch strings.ToUpper(strings.TrimSpace(sex)) {
+	case "M":
+		return t.male[age]
+	case "F":
+		return t.female[age]
+	}
+	return (t.male[age] + t.female[age]) / 2
+}
+
+// CohortSurvival implements Table. A period table ignores the birth year.
+func (t *LifeTable) CohortSurvival(_ int, sex string, age int) float64 {
+	return t.Survival(sex, age)
+}
+
+// CohortLifeTable holds a life table for each of a series of birth years,
+// such as the cohort tables of SSA Actuarial Study No. 120, published for
+// every tenth birth year.
+type CohortLifeTable struct {
+	years  []int
+	tables []*LifeTable
+}
+
+// ParseCohortLifeTable reads cohort life tables in CSV form: a header row,
+// then rows of birth year, age, and the male and female survivors to that
+// exact age (lx), e.g. "1950,0,100000,100000". Each birth year's rows run
+// from age 0 upward and are scaled by its age-0 row. Blank lines and lines
+// starting with # are ignored.
+func ParseCohortLifeTable(r io.Reader) (*CohortLifeTable, error) {
+	cohorts := &CohortLifeTable{}
+	var current *LifeTable
+	scanner := bufio.NewScanner(r)
+	header := false
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if !header {
+			header = true
+			continue
+		}
+
+		fields := strings.Split(text, ",")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("line %d: expected birth_year,age,male,female", line)
+		}
+		year, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid birth year %q", line, fields[0])
+		}
+		if n := len(cohorts.years); n == 0 || year != cohorts.years[n-1] {
+			if n > 0 && year < cohorts.years[n-1] {
+				return nil, fmt.Errorf("line %d: birth years must increase", line)
+			}
+			current = &LifeTable{}
+			cohorts.years = append(cohorts.years, year)
+			cohorts.tables = append(cohorts.tables, current)
+		}
+		age, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+		if err != nil || age != len(current.male) {
+			return nil, fmt.Errorf("line %d: expected age %d for birth year %d", line, len(current.male), year)
+		}
+		var survivors [2]float64
+		for i, field := range fields[2:] {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("line %d: invalid survivor count %q", line, field)
+			}
+			survivors[i] = v
+		}
+		current.male = append(current.male, survivors[0])
+		current.female = append(current.female, survivors[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(cohorts.years) == 0 {
+		return nil, errNoRows
+	}
+	for i, table := range cohorts.tables {
+		if err := table.normalize(); err != nil {
+			return nil, fmt.Errorf("birth year %d: %w", cohorts.years[i], err)
+		}
+	}
+	return cohorts, nil
+}
+
+// Years returns the birth years the tables were published for, in order.
+func (c *CohortLifeTable) Years() []int {
+	return slices.Clone(c.years)
+}
+
+// CohortSurvival implements Table. Birth years between two published
+// cohorts interpolate linearly between their survival at age; years before
+// the first or after the last use the nearest cohort.
+func (c *CohortLifeTable) CohortSurvival(birthYear int, sex string, age int) float64 {
+	i, found := slices.BinarySearch(c.years, birthYear)
+	switch {
+	case found:
+		return c.tables[i].Survival(sex, age)
+	case i == 0:
+		return c.tables[0].Survival(sex, age)
+	case i == len(c.years):
+		return c.tables[i-1].Survival(sex, age)
+	}
+	lo, hi := c.years[i-1], c.years[i]
+	w := float64(birthYear-lo) / float64(hi-lo)
+	return (1-w)*c.tables[i-1].Survival(sex, age) + w*c.tables[i].Survival(sex, age)
+}
+
+// Births is the number of people of one sex born in one year.
+type Births struct {
+	Year  int
+	Sex   string
+	Count int
+}
+
+// Cohort is the estimate for everyone born in one year.
+type Cohort struct {
+	Year   int
+	Age    int
+	Births int
+	// Alive is the expected number still living.
+	Alive float64
+}
+
+// Survival returns the fraction of the cohort expected to be alive.
+func (c Cohort) Survival() float64 {
+	if c.Births == 0 {
+		return 0
+	}
+	return c.Alive / float64(c.Births)
+}
+
+// Estimate is the expected number of living people among the births given to
+// EstimateLiving.
+type Estimate struct {
+	// AsOf is the year the estimate applies to.
+	AsOf int
+	// Cohorts is sorted by birth year, with the sexes combined.
+	Cohorts []Cohort
+	Births  int
+	Alive   float64
+}
+
+// Survival returns the fraction of all births expected to be alive.
+func (e Estimate) Survival() float64 {
+	if e.Births == 0 {
+		return 0
+	}
+	return e.Alive / float64(e.Births)
+}
+
+// MedianAge returns the age that splits the expected living population in
+// half, or -1 when nobody is expected to be alive.
+func (e Estimate) MedianAge() int {
+	if e.Alive <= 0 {
+		return -1
+	}
+	running := 0.0
+	for i := len(e.Cohorts) - 1; i >= 0; i-- {
+		running += e.Cohorts[i].Alive
+		if running >= e.Alive/2 {
+			return e.Cohorts[i].Age
+		}
+	}
+	return e.Cohorts[0].Age
+}
+
+// EstimateLiving applies table to births to estimate how many of those
+// people are alive in asOf, looking up each birth year's own survival. Births
+// after asOf are ignored. A nil table uses Default.
+func EstimateLiving(births []Births, asOf int, table Table) (Estimate, error) {
+	if table == nil {
+		table = Default()
+	}
+	byYear := make(map[int]*Cohort)
+	for _, b := range births {
+		if b.Count < 0 {
+			return Estimate{}, fmt.Errorf("negative birth count for %d", b.Year)
+		}
+		if b.Year > asOf || b.Count == 0 {
+			continue
+		}
+		cohort, ok := byYear[b.Year]
+		if !ok {
+			cohort = &Cohort{Year: b.Year, Age: asOf - b.Year}
+			byYear[b.Year] = cohort
+		}
+		cohort.Births += b.Count
+		cohort.Alive += float64(b.Count) * table.CohortSurvival(b.Year, b.Sex, cohort.Age)
+	}
+	if len(byYear) == 0 {
+		return Estimate{}, fmt.Errorf("no births in or before %d", asOf)
+	}
+
+	est := Estimate{AsOf: asOf, Cohorts: make([]Cohort, 0, len(byYear))}
+	for _, cohort := range byYear {
+		est.Cohorts = append(est.Cohorts, *cohort)
+		est.Births += cohort.Births
+		est.Alive += cohort.Alive
+	}
+	sort.Slice(est.Cohorts, func(i, j int) bool { return est.Cohorts[i].Year < est.Cohorts[j].Year })
+	return est, nil
+}
@@ -0,0 +1,118 @@
+package actuarial_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/actuarial"
+)
+
+func TestDefaultLifeTable(t *testing.T) {
+	table := actuarial.Default()
+	if got := table.CohortSurvival(1950, "F", 0); got != 1 {
+		t.Fatalf("expected survival 1 at birth, got %v", got)
+	}
+	for age := 1; age <= 110; age++ {
+		if table.CohortSurvival(1950, "M", age) > table.CohortSurvival(1950, "M", age-1) {
+			t.Fatalf("male survival increases at age %d", age)
+		}
+	}
+	if m, f := table.CohortSurvival(1940, "M", 80), table.CohortSurvival(1940, "F", 80); m >= f || m < 0.3 || f > 0.8 {
+		t.Fatalf("implausible survival to 80: male %v, female %v", m, f)
+	}
+	if got := table.CohortSurvival(1940, "", 80); math.Abs(got-(table.CohortSurvival(1940, "M", 80)+table.CohortSurvival(1940, "F", 80))/2) > 1e-12 {
+		t.Fatalf("expected both sexes to average the columns, got %v", got)
+	}
+	if got := table.CohortSurvival(1900, "M", 130); got != 0 {
+		t.Fatalf("expected no survivors past the table, got %v", got)
+	}
+}
+
+func TestCohortLifeTable(t *testing.T) {
+	table, err := actuarial.ParseTable(strings.NewReader("# two cohorts\nbirth_year,age,male,female\n" +
+		"1900,0,1000,1000\n1900,1,600,700\n" +
+		"1920,0,1000,1000\n1920,1,800,900\n"))
+	if err != nil {
+		t.Fatalf("ParseTable: %v", err)
+	}
+	cohorts, ok := table.(*actuarial.CohortLifeTable)
+	if !ok || len(cohorts.Years()) != 2 {
+		t.Fatalf("expected two cohort tables, got %#v", table)
+	}
+	for _, tc := range []struct {
+		year int
+		want float64
+	}{
+		{1900, 0.6},
+		{1910, 0.7}, // halfway between the published cohorts
+		{1915, 0.75},
+		{1880, 0.6}, // before the first cohort
+		{2000, 0.8}, // after the last
+	} {
+		if got := table.CohortSurvival(tc.year, "M", 1); math.Abs(got-tc.want) > 1e-12 {
+			t.Fatalf("male survival to 1 for %d: expected %v, got %v", tc.year, tc.want, got)
+		}
+	}
+
+	// Each birth year uses its own cohort's survival.
+	est, err := actuarial.EstimateLiving([]actuarial.Births{
+		{Year: 1900, Sex: "F", Count: 100},
+		{Year: 1920, Sex: "F", Count: 100},
+	}, 1921, table)
+	if err != nil {
+		t.Fatalf("EstimateLiving: %v", err)
+	}
+	// 1900 is past the table's last age; 1920 is age 1: 100*0.9.
+	if math.Abs(est.Alive-90) > 1e-9 {
+		t.Fatalf("expected 90 alive, got %v", est.Alive)
+	}
+
+	if _, err := actuarial.ParseCohortLifeTable(strings.NewReader("birth_year,age,male,female\n1920,0,100,100\n1900,0,100,100\n")); err == nil {
+		t.Fatal("expected an error for decreasing birth years")
+	}
+	if _, err := actuarial.ParseCohortLifeTable(strings.NewReader("birth_year,age,male,female\n1900,0,100,100\n1900,2,90,90\n")); err == nil {
+		t.Fatal("expected an error for a skipped age")
+	}
+	if _, err := actuarial.ParseTable(strings.NewReader("age,male,female\n0,100,100\n")); err != nil {
+		t.Fatalf("expected a period table to parse, got %v", err)
+	}
+}
+
+func TestEstimateLiving(t *testing.T) {
+	table, err := actuarial.ParseLifeTable(strings.NewReader("age,male,female\n0,1000,1000\n1,800,900\n2,400,500\n"))
+	if err != nil {
+		t.Fatalf("ParseLifeTable: %v", err)
+	}
+
+	est, err := actuarial.EstimateLiving([]actuarial.Births{
+		{Year: 2000, Sex: "F", Count: 100},
+		{Year: 2000, Sex: "M", Count: 100},
+		{Year: 2001, Sex: "F", Count: 10},
+		{Year: 2002, Sex: "M", Count: 50},
+		{Year: 2003, Sex: "M", Count: 70}, // after asOf
+	}, 2002, table)
+	if err != nil {
+		t.Fatalf("EstimateLiving: %v", err)
+	}
+	if len(est.Cohorts) != 3 || est.Births != 260 {
+		t.Fatalf("unexpected cohorts: %+v", est)
+	}
+	// 2000: 100*0.5 + 100*0.4, 2001: 10*0.9, 2002: 50*1.
+	if want := 90.0 + 9 + 50; math.Abs(est.Alive-want) > 1e-9 {
+		t.Fatalf("expected %v alive, got %v", want, est.Alive)
+	}
+	if est.Cohorts[0].Age != 2 || math.Abs(est.Cohorts[0].Survival()-0.45) > 1e-9 {
+		t.Fatalf("unexpected 2000 cohort: %+v", est.Cohorts[0])
+	}
+	if got := est.MedianAge(); got != 2 {
+		t.Fatalf("expected median living age 2, got %d", got)
+	}
+
+	if _, err := actuarial.EstimateLiving(nil, 2002, table); err == nil {
+		t.Fatal("expected an error without births")
+	}
+	if _, err := actuarial.ParseLifeTable(strings.NewReader("age,male,female\n0,100,100\n1,120,90\n")); err == nil {
+		t.Fatal("expected an error for increasing survivors")
+	}
+}
@@ -0,0 +1,147 @@
+// Package cache provides a filter-keyed LRU cache for computed name
+// aggregates and samplers, so long-running callers such as the HTTP server
+// can answer repeated queries without rescanning the dataset.
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// Key identifies a cached aggregate by its query filters.
+type Key struct {
+	State  string
+	Years  string
+	Gender string
+}
+
+// NewKey builds a normalized Key. years should be a canonical description of
+// the year filter (e.g. "2015-2019"), with an empty string meaning all years.
+func NewKey(state, years, gender string) Key {
+	return Key{
+		State:  strings.ToUpper(strings.TrimSpace(state)),
+		Years:  strings.TrimSpace(years),
+		Gender: strings.ToUpper(strings.TrimSpace(gender)),
+	}
+}
+
+// Entry holds the computed values for a Key. Sampler, when set, is a sampler
+// over Aggregated kept for reuse by later draws; check its strategy and
+// temperature before using it.
+type Entry struct {
+	Aggregated []namesdata.NameCount
+	Total      int
+	Sampler    *namesdata.NameSampler
+}
+
+// Stats reports cache effectiveness counters.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+	Capacity  int
+}
+
+// Cache is a fixed-capacity LRU cache safe for concurrent use.
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[Key]*list.Element
+	stats    Stats
+}
+
+type item struct {
+	key   Key
+	entry Entry
+}
+
+// New returns a cache holding at most capacity entries. A capacity below one
+// is treated as one.
+func New(capacity int) *Cache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Cache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[Key]*list.Element, capacity),
+	}
+}
+
+// Get returns the entry for key and marks it as recently used.
+func (c *Cache) Get(key Key) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return Entry{}, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*item).entry, true
+}
+
+// Add stores entry under key, evicting the least recently used entry when the
+// cache is full.
+func (c *Cache) Add(key Key, entry Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*item).entry = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&item{key: key, entry: entry})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*item).key)
+		c.stats.Evictions++
+	}
+}
+
+// GetOrLoad returns the cached entry for key, calling load and caching its
+// result on a miss. Errors from load are returned and not cached. Concurrent
+// misses for the same key may each call load; the last result wins.
+func (c *Cache) GetOrLoad(key Key, load func() (Entry, error)) (Entry, error) {
+	if entry, ok := c.Get(key); ok {
+		return entry, nil
+	}
+
+	entry, err := load()
+	if err != nil {
+		return Entry{}, err
+	}
+	c.Add(key, entry)
+	return entry, nil
+}
+
+// Purge removes every entry, for example after the dataset changes. The hit,
+// miss, and eviction counters are preserved.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[Key]*list.Element, c.capacity)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+	stats.Capacity = c.capacity
+	return stats
+}
@@ -0,0 +1,78 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/cache"
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestCacheLRUEviction(t *testing.T) {
+	c := cache.New(2)
+
+	ca := cache.NewKey("ca", "2019", "f")
+	ny := cache.NewKey("NY", "2019", "F")
+	tx := cache.NewKey("TX", "", "")
+
+	if ca != cache.NewKey(" CA ", "2019", "F") {
+		t.Fatalf("expected normalized keys to match")
+	}
+
+	c.Add(ca, cache.Entry{Total: 1})
+	c.Add(ny, cache.Entry{Total: 2})
+	if _, ok := c.Get(ca); !ok {
+		t.Fatalf("expected CA to be cached")
+	}
+
+	// NY is now least recently used and should be evicted.
+	c.Add(tx, cache.Entry{Total: 3})
+	if _, ok := c.Get(ny); ok {
+		t.Fatalf("expected NY to be evicted")
+	}
+	if entry, ok := c.Get(tx); !ok || entry.Total != 3 {
+		t.Fatalf("expected TX entry, got %+v ok=%v", entry, ok)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Evictions != 1 || stats.Size != 2 || stats.Capacity != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	c.Purge()
+	if c.Stats().Size != 0 {
+		t.Fatalf("expected empty cache after purge")
+	}
+}
+
+func TestCacheGetOrLoad(t *testing.T) {
+	c := cache.New(4)
+	key := cache.NewKey("", "", "M")
+
+	loads := 0
+	load := func() (cache.Entry, error) {
+		loads++
+		return cache.Entry{Aggregated: []namesdata.NameCount{{Name: "Liam", Count: 5}}, Total: 5}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		entry, err := c.GetOrLoad(key, load)
+		if err != nil {
+			t.Fatalf("GetOrLoad: %v", err)
+		}
+		if entry.Total != 5 || entry.Aggregated[0].Name != "Liam" {
+			t.Fatalf("unexpected entry: %+v", entry)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("expected a single load, got %d", loads)
+	}
+
+	failing := cache.NewKey("ZZ", "", "")
+	if _, err := c.GetOrLoad(failing, func() (cache.Entry, error) { return cache.Entry{}, errors.New("boom") }); err == nil {
+		t.Fatalf("expected load error")
+	}
+	if _, ok := c.Get(failing); ok {
+		t.Fatalf("expected failed load not to be cached")
+	}
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runAge(args []string) error {
+	fs := flag.NewFlagSet("age", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	nameFlag := fs.String("name", "", "name to estimate birth years for (may also be given as an argument)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	width := fs.Int("width", 30, "width of the histogram bars in characters")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(*nameFlag)
+	switch {
+	case name == "" && len(positional) == 1:
+		name = strings.TrimSpace(positional[0])
+	case len(positional) > 1 || (name != "" && len(positional) > 0):
+		return errors.New("age: provide exactly one name")
+	}
+	if name == "" {
+		return errors.New("age: a name is required")
+	}
+	if *width < 1 {
+		return errors.New("age: --width must be at least 1")
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("age: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	dist, err := namesdata.BirthYears(a.scopedStream(scope, namesdata.Filter{State: *state}), *gender, name)
+	if err != nil {
+		return err
+	}
+
+	q1, median, q3 := dist.Quantile(0.25), dist.Median(), dist.Quantile(0.75)
+	metadata := map[string]string{
+		"name":        dist.Name,
+		"births":      fmt.Sprintf("%d", dist.Total),
+		"median_year": fmt.Sprintf("%d", median),
+		"q1_year":     fmt.Sprintf("%d", q1),
+		"q3_year":     fmt.Sprintf("%d", q3),
+		"peak_year":   fmt.Sprintf("%d", dist.Peak()),
+		"as_of":       fmt.Sprintf("%d", dist.LatestYear),
+		"median_age":  fmt.Sprintf("%d", dist.Age(median)),
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	// Bucket the births by decade for the histogram, keeping empty decades
+	// so gaps stay visible.
+	byDecade := make(map[int]int)
+	for i, year := range dist.Years {
+		byDecade[year-year%10] += dist.Counts[i]
+	}
+	first, last := dist.Years[0], dist.Years[len(dist.Years)-1]
+	var decades []int
+	for decade := first - first%10; decade <= last; decade += 10 {
+		decades = append(decades, decade)
+	}
+	largest := 0
+	for _, count := range byDecade {
+		largest = max(largest, count)
+	}
+
+	rows := make([][]any, len(decades))
+	for i, decade := range decades {
+		count := byDecade[decade]
+		bar := int(float64(count) / float64(largest) * float64(*width))
+		if bar == 0 && count > 0 {
+			bar = 1
+		}
+		rows[i] = []any{
+			fmt.Sprintf("%ds", decade),
+			count,
+			percentCell(float64(count)/float64(dist.Total), 2),
+			strings.Repeat("█", bar),
+		}
+	}
+
+	title := fmt.Sprintf("Likely birth years for people named %s in %s", dist.Name, displayLocation)
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	footer := []string{
+		fmt.Sprintf("Median birth year %d (middle half born %d-%d); births peaked in %d.", median, q1, q3, dist.Peak()),
+		fmt.Sprintf("As of %d, a typical %s is %d years old (middle half %d-%d).", dist.LatestYear, dist.Name, dist.Age(median), dist.Age(q3), dist.Age(q1)),
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Decade", "Births", "Share", "Histogram"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
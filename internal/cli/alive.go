@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/actuarial"
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runAlive(args []string) error {
+	fs := flag.NewFlagSet("alive", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	nameFlag := fs.String("name", "", "name to estimate the living population for (may also be given as an argument)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	asOf := fs.Int("as-of", 0, "year to estimate for (0 for the latest year in the data)")
+	lifeTablePath := fs.String("life-table", "", "optional CSV life table (birth_year,age,male,female cohort or age,male,female period survivors) to use instead of the embedded model")
+	width := fs.Int("width", 30, "width of the histogram bars in characters")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(*nameFlag)
+	switch {
+	case name == "" && len(positional) == 1:
+		name = strings.TrimSpace(positional[0])
+	case len(positional) > 1 || (name != "" && len(positional) > 0):
+		return errors.New("alive: provide exactly one name")
+	}
+	if name == "" {
+		return errors.New("alive: a name is required")
+	}
+	if *width < 1 {
+		return errors.New("alive: --width must be at least 1")
+	}
+	if *asOf < 0 {
+		return errors.New("alive: --as-of must be a year")
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("alive: %w", err)
+	}
+
+	table := actuarial.Default()
+	if trimmed := strings.TrimSpace(*lifeTablePath); trimmed != "" {
+		file, err := os.Open(trimmed)
+		if err != nil {
+			return fmt.Errorf("alive: %w", err)
+		}
+		table, err = actuarial.ParseTable(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("alive: life table %s: %w", trimmed, err)
+		}
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	// Survival differs by sex, so births are gathered per gender.
+	genders := []string{"F", "M"}
+	if trimmed := strings.ToUpper(strings.TrimSpace(*gender)); trimmed != "" {
+		genders = []string{trimmed}
+	}
+	var (
+		births     []actuarial.Births
+		display    string
+		latestYear int
+	)
+	for _, g := range genders {
+		dist, err := namesdata.BirthYears(a.scopedStream(scope, namesdata.Filter{State: *state}), g, name)
+		if errors.Is(err, namesdata.ErrNoMatches) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		display = dist.Name
+		latestYear = dist.LatestYear
+		for i, year := range dist.Years {
+			births = append(births, actuarial.Births{Year: year, Sex: g, Count: dist.Counts[i]})
+		}
+	}
+	if len(births) == 0 {
+		return namesdata.ErrNoMatches
+	}
+	year := *asOf
+	if year == 0 {
+		year = latestYear
+	}
+
+	est, err := actuarial.EstimateLiving(births, year, table)
+	if err != nil {
+		return fmt.Errorf("alive: %w", err)
+	}
+
+	metadata := map[string]string{
+		"name":       display,
+		"as_of":      fmt.Sprintf("%d", est.AsOf),
+		"births":     fmt.Sprintf("%d", est.Births),
+		"alive":      fmt.Sprintf("%.0f", est.Alive),
+		"median_age": fmt.Sprintf("%d", est.MedianAge()),
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+	assumption := "Applies each birth year's cohort survival rates by age and sex and assumes nobody migrates; treat the counts as rough estimates."
+	if _, cohort := table.(*actuarial.CohortLifeTable); cohort {
+		metadata["life_table"] = "cohort"
+	} else {
+		metadata["life_table"] = "period"
+		assumption = "Assumes one period's survival rates by age and sex apply to every birth year and that nobody migrates; treat the counts as rough estimates."
+	}
+
+	// Bucket the cohorts by birth decade, keeping empty decades so gaps stay
+	// visible, as the age command does.
+	type bucket struct {
+		births int
+		alive  float64
+	}
+	byDecade := make(map[int]*bucket)
+	for _, cohort := range est.Cohorts {
+		decade := cohort.Year - cohort.Year%10
+		if byDecade[decade] == nil {
+			byDecade[decade] = &bucket{}
+		}
+		byDecade[decade].births += cohort.Births
+		byDecade[decade].alive += cohort.Alive
+	}
+	first, last := est.Cohorts[0].Year, est.Cohorts[len(est.Cohorts)-1].Year
+	largest := 0.0
+	for _, b := range byDecade {
+		largest = max(largest, b.alive)
+	}
+
+	var rows [][]any
+	for decade := first - first%10; decade <= last; decade += 10 {
+		b := byDecade[decade]
+		if b == nil {
+			b = &bucket{}
+		}
+		youngest, oldest := max(est.AsOf-min(decade+9, last), 0), est.AsOf-max(decade, first)
+		var survival any
+		share, bar := percentCell(0, 2), 0
+		if b.births > 0 {
+			survival = percentCell(b.alive/float64(b.births), 2)
+		}
+		if est.Alive > 0 {
+			share = percentCell(b.alive/est.Alive, 2)
+		}
+		if largest > 0 {
+			bar = int(b.alive / largest * float64(*width))
+			if bar == 0 && b.alive >= 0.5 {
+				bar = 1
+			}
+		}
+		rows = append(rows, []any{
+			fmt.Sprintf("%ds", decade),
+			formatYearSegment(youngest, oldest),
+			b.births,
+			survival,
+			decimalCell(b.alive, 0),
+			share,
+			strings.Repeat("█", bar),
+		})
+	}
+
+	title := fmt.Sprintf("Estimated living people named %s in %s as of %d", display, displayLocation, est.AsOf)
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	footer := []string{
+		fmt.Sprintf("An estimated %.0f of the %d people named %s born %s are alive in %d (%.1f%%); their median age is %d.",
+			est.Alive, est.Births, display, formatYearSegment(first, last), est.AsOf, est.Survival()*100, est.MedianAge()),
+		assumption,
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Decade", "Ages", "Births", "Survival", "Alive", "Share", "Histogram"},
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
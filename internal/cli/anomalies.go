@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runAnomalies(args []string) error {
+	fs := flag.NewFlagSet("anomalies", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	year := fs.Int("year", 0, "year to scan for spikes (defaults to the latest year in the dataset)")
+	baseline := fs.Int("baseline", 5, "number of years before --year to measure each name's usual count over")
+	minZ := fs.Float64("z", 3, "minimum z-score of a name's count against its baseline to list it")
+	minCount := fs.Int("min-count", 100, "minimum count in --year for a name to be considered")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	limit := fs.Int("limit", 20, "maximum number of names to list (0 for all)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("anomalies: unexpected argument %q", fs.Arg(0))
+	}
+	if *year < 0 {
+		return errors.New("anomalies: --year must be a positive year")
+	}
+	if *baseline < 1 {
+		return errors.New("anomalies: --baseline must be at least 1")
+	}
+	if *minZ <= 0 {
+		return errors.New("anomalies: --z must be positive")
+	}
+	if *minCount < 1 {
+		return errors.New("anomalies: --min-count must be at least 1")
+	}
+	if *limit < 0 {
+		return errors.New("anomalies: --limit must be 0 or greater")
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("anomalies: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+
+	target := *year
+	if target == 0 {
+		for _, r := range records {
+			target = max(target, r.Year)
+		}
+	}
+	anomalies, err := namesdata.Anomalies(records, *gender, target, *baseline, *minZ, *minCount)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]string{
+		"year":      fmt.Sprintf("%d", target),
+		"baseline":  formatYearSegment(target-*baseline, target-1),
+		"z":         fmt.Sprintf("%g", *minZ),
+		"min_count": fmt.Sprintf("%d", *minCount),
+		"matches":   fmt.Sprintf("%d", len(anomalies)),
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	listed := anomalies
+	if *limit > 0 && len(listed) > *limit {
+		listed = listed[:*limit]
+	}
+	rows := make([][]any, len(listed))
+	for i, an := range listed {
+		rows[i] = []any{
+			i + 1,
+			an.Name,
+			an.Count,
+			decimalCell(an.Mean, 1),
+			decimalCell(an.Spike(), 0),
+			decimalCell(an.Z, 1),
+		}
+	}
+
+	title := fmt.Sprintf("Names spiking in %s in %d against %s", displayLocation, target, formatYearSegment(target-*baseline, target-1))
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	footer := []string{
+		fmt.Sprintf("Z is how many standard deviations a name's %d count sits above its mean over the baseline years, with the deviation floored at the square root of the mean; names given fewer than %d times in %d are left out.", target, *minCount, target),
+	}
+	if len(anomalies) == 0 {
+		footer = append(footer, fmt.Sprintf("No names spiked by %g or more.", *minZ))
+	} else {
+		top := anomalies[0]
+		footer = append(footer, fmt.Sprintf("The biggest spike was %s: %d births against a baseline of %.1f.", top.Name, top.Count, top.Mean))
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Rank", "Name", "Count", "Baseline", "Spike", "Z"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
@@ -1,18 +1,23 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"io/fs"
+	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/curtiscovington/ssa-names/internal/cache"
+	"github.com/curtiscovington/ssa-names/internal/metrics"
 	"github.com/curtiscovington/ssa-names/internal/namesdata"
 	"github.com/curtiscovington/ssa-names/internal/visualize"
 )
@@ -25,6 +30,64 @@ type App struct {
 	Dataset fs.FS
 	Stdout  io.Writer
 	Stderr  io.Writer
+
+	// Stdin is read by commands given --stdin. When nil, os.Stdin is used.
+	Stdin io.Reader
+
+	// National is the optional SSA national dataset (yobYYYY.txt files)
+	// queried by commands run with --scope national.
+	National fs.FS
+
+	// Territories is the optional SSA territory dataset (PR.TXT, TR.TXT),
+	// layered onto Dataset for runs given --include-territories.
+	Territories fs.FS
+
+	// Seed seeds every source of randomness used during a run. When zero, a
+	// time-based seed is chosen on first use and reported in the output
+	// metadata so the run can be reproduced. A --seed flag overrides it.
+	Seed int64
+
+	// ConfigPath is the TOML file supplying flag defaults, read at the start
+	// of every run. Command-line flags override it. A missing file is
+	// ignored, and an empty path disables the config file.
+	ConfigPath string
+
+	// Cache, when set, keeps name aggregates across runs so an App embedded
+	// in a long-running process (a server, tests, or an interactive UI)
+	// does not rescan the dataset for repeated queries. Entries are keyed
+	// by scope, state, years, and gender. Call InvalidateCache after
+	// changing Dataset or National.
+	Cache *cache.Cache
+
+	// Metrics, when set, records the time spent loading aggregates from the
+	// dataset, and Handler exposes it at /metrics.
+	Metrics *metrics.Metrics
+
+	config *config
+	seed   int64
+	rng    *rand.Rand
+	// datasetClosers release the zip archives opened by --dataset.
+	datasetClosers []io.Closer
+	// baseDataset is the Dataset a run started with, which --dataset
+	// refers to as "embedded".
+	baseDataset fs.FS
+	// datasetOverridden is set while a run queries a dataset given by
+	// --dataset (or the config file), whose aggregates are not cached.
+	datasetOverridden bool
+	// skipDatasetCheck is set for validate, which reports every problem in
+	// a --dataset itself instead of failing on its first record.
+	skipDatasetCheck bool
+	// withTerritories is set while a run includes the territory files;
+	// territoryless is the dataset they were layered onto.
+	withTerritories bool
+	territoryless   fs.FS
+	// territoriesFlag holds an --include-territories given after the
+	// command name. It is applied once the command's flags parse, so a
+	// build without the territory files reports that instead of a bad flag.
+	territoriesFlag *bool
+	// errorFormat is the --format value of the last run, which HandleError
+	// uses to decide whether to report failures as JSON.
+	errorFormat string
 }
 
 // NewApp constructs an App with the provided dataset and I/O writers.
@@ -32,8 +95,48 @@ func NewApp(dataset fs.FS, stdout, stderr io.Writer) *App {
 	return &App{Dataset: dataset, Stdout: stdout, Stderr: stderr}
 }
 
+// InvalidateCache drops every cached aggregate, for use after the dataset
+// behind the App changes. It is a no-op without a Cache.
+func (a *App) InvalidateCache() {
+	if a.Cache != nil {
+		a.Cache.Purge()
+	}
+}
+
 // Run dispatches to the appropriate sub-command based on the provided args.
+// Global flags such as --seed may precede the sub-command name.
 func (a *App) Run(args []string) error {
+	a.seed = a.Seed
+	a.rng = nil
+	a.errorFormat = ""
+	a.skipDatasetCheck = commandName(args) == "validate"
+
+	// --dataset only applies to this run.
+	dataset := a.Dataset
+	a.baseDataset = dataset
+	defer func() {
+		a.Dataset = dataset
+		a.datasetOverridden = false
+		a.withTerritories = false
+		a.territoryless = nil
+		a.territoriesFlag = nil
+		a.closeDataset()
+	}()
+
+	cfg, err := loadConfig(a.ConfigPath)
+	if err != nil {
+		return err
+	}
+	a.config = cfg
+	if err := a.applyRunConfig(); err != nil {
+		return err
+	}
+
+	args, err = a.parseGlobalFlags(args)
+	if err != nil {
+		return err
+	}
+
 	if len(args) > 0 {
 		switch args[0] {
 		case "version", "--version", "-v":
@@ -47,18 +150,373 @@ func (a *App) Run(args []string) error {
 	}
 
 	switch args[0] {
+	case "top":
+		return a.runTop(args[1:])
 	case "generate":
 		return a.runGenerate(args[1:])
 	case "trend":
 		return a.runTrend(args[1:])
+	case "export":
+		return a.runExport(args[1:])
+	case "check":
+		return a.runCheck(args[1:])
+	case "search":
+		return a.runSearch(args[1:])
+	case "serve":
+		return a.runServe(args[1:])
+	case "compare":
+		return a.runCompare(args[1:])
+	case "neutral":
+		return a.runNeutral(args[1:])
+	case "unisex":
+		return a.runUnisex(args[1:])
+	case "gender":
+		return a.runGender(args[1:])
+	case "states":
+		return a.runStates(args[1:])
+	case "clusters":
+		return a.runClusters(args[1:])
+	case "divergence":
+		return a.runDivergence(args[1:])
+	case "distinctive":
+		return a.runDistinctive(args[1:])
+	case "peak":
+		return a.runPeak(args[1:])
+	case "history":
+		return a.runHistory(args[1:])
+	case "similar":
+		return a.runSimilar(args[1:])
+	case "rank":
+		return a.runRank(args[1:])
+	case "movers":
+		return a.runMovers(args[1:])
+	case "age":
+		return a.runAge(args[1:])
+	case "alive":
+		return a.runAlive(args[1:])
+	case "heatmap":
+		return a.runHeatmap(args[1:])
+	case "lifecycle":
+		return a.runLifecycle(args[1:])
+	case "debuts":
+		return a.runDebuts(args[1:])
+	case "anomalies":
+		return a.runAnomalies(args[1:])
+	case "letters":
+		return a.runLetters(args[1:])
+	case "endings":
+		return a.runEndings(args[1:])
+	case "lengths":
+		return a.runLengths(args[1:])
+	case "concentration":
+		return a.runConcentration(args[1:])
+	case "diversity":
+		return a.runDiversity(args[1:])
+	case "config":
+		return a.runConfig(args[1:])
+	case "update-data":
+		return a.runUpdateData(args[1:])
+	case "validate":
+		return a.runValidate(args[1:])
+	case "stats":
+		return a.runStats(args[1:])
 	case "help", "-h", "--help":
 		a.printUsage()
 		return nil
 	default:
 		fmt.Fprintf(a.Stderr, "unknown command: %s\n\n", args[0])
 		a.printUsage()
-		return fmt.Errorf("unknown command: %s", args[0])
+		return asUsageError(fmt.Errorf("unknown command: %s", args[0]))
+	}
+}
+
+// parseGlobalFlags consumes global flags that appear before the sub-command
+// and returns the remaining arguments.
+func (a *App) parseGlobalFlags(args []string) ([]string, error) {
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		takesValue, ok := globalFlags[name]
+		if !strings.HasPrefix(args[0], "-") || !ok {
+			return args, nil
+		}
+		if !takesValue {
+			enabled := true
+			if hasValue {
+				var err error
+				if enabled, err = strconv.ParseBool(value); err != nil {
+					return nil, asUsageError(fmt.Errorf("invalid boolean value %q for -%s: %w", value, name, err))
+				}
+			}
+			if err := a.useTerritories(enabled); err != nil {
+				return nil, err
+			}
+			args = args[1:]
+			continue
+		}
+		consumed := 1
+		if !hasValue {
+			if len(args) < 2 {
+				return nil, asUsageError(fmt.Errorf("flag needs an argument: -%s", name))
+			}
+			value = args[1]
+			consumed = 2
+		}
+		switch name {
+		case "seed":
+			seed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, asUsageError(fmt.Errorf("invalid value %q for flag -seed: %w", value, err))
+			}
+			a.seed = seed
+		case "dataset":
+			if err := a.useDataset(value); err != nil {
+				return nil, err
+			}
+		}
+		args = args[consumed:]
+	}
+	return args, nil
+}
+
+// globalFlags lists the flags parseGlobalFlags accepts before the command
+// name, and whether each takes a value.
+var globalFlags = map[string]bool{"seed": true, "dataset": true, "include-territories": false}
+
+// commandName returns the sub-command args will run, skipping the global
+// flags before it the way parseGlobalFlags does.
+func commandName(args []string) string {
+	for len(args) > 0 {
+		name, _, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if !strings.HasPrefix(args[0], "-") {
+			return args[0]
+		}
+		takesValue, ok := globalFlags[name]
+		if !ok {
+			return ""
+		}
+		if hasValue || !takesValue {
+			args = args[1:]
+		} else {
+			args = args[min(2, len(args)):]
+		}
+	}
+	return ""
+}
+
+// registerGlobalFlags exposes the global flags on a sub-command's flag set so
+// they are also accepted after the sub-command name.
+func (a *App) registerGlobalFlags(fs *flag.FlagSet) {
+	fs.Int64Var(&a.seed, "seed", a.seed, "RNG seed for reproducible runs (0 picks one and reports it)")
+	fs.Func("dataset", "directory or .zip of SSA-format state .TXT files to query instead of the embedded dataset (comma-separate several, including \"embedded\", to merge them)", a.useDataset)
+	fs.BoolFunc("include-territories", "also query the SSA territory files (PR for Puerto Rico, TR for the other territories; download-only: run names update-data --territories first)", func(value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		a.territoriesFlag = &enabled
+		return nil
+	})
+}
+
+// applyTerritoriesFlag applies an --include-territories parsed from a
+// command's flags.
+func (a *App) applyTerritoriesFlag() error {
+	if a.territoriesFlag == nil {
+		return nil
 	}
+	enabled := *a.territoriesFlag
+	a.territoriesFlag = nil
+	return a.useTerritories(enabled)
+}
+
+// embeddedDatasetName is the --dataset entry that stands for the dataset
+// the App was built with, so it can be merged with other sources.
+const embeddedDatasetName = "embedded"
+
+// useDataset points the current run at the state files in value: a
+// directory or a zip archive such as the SSA's namesbystate.zip, or a
+// comma-separated list of them (including "embedded") merged with
+// namesdata.Merge. Unless a.skipDatasetCheck is set, it first checks that
+// the result holds at least one parseable state file.
+func (a *App) useDataset(value string) error {
+	paths := splitNames(value)
+	if len(paths) == 0 {
+		return errors.New("dataset: directory is required")
+	}
+
+	var (
+		sources []fs.FS
+		closers []io.Closer
+	)
+	closeAll := func() {
+		for _, closer := range closers {
+			closer.Close()
+		}
+	}
+	for _, path := range paths {
+		fsys, closer, err := a.openDataset(path)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		sources = append(sources, fsys)
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+	}
+	fsys := sources[0]
+	if len(sources) > 1 {
+		fsys = namesdata.Merge(sources...)
+	}
+
+	if !a.skipDatasetCheck {
+		if err := namesdata.ValidateDataset(fsys); err != nil {
+			closeAll()
+			return fmt.Errorf("dataset %s: %w", strings.Join(paths, ","), err)
+		}
+	}
+	a.closeDataset()
+	if a.withTerritories {
+		a.territoryless = fsys
+		fsys = namesdata.Merge(fsys, a.Territories)
+	}
+	a.Dataset = fsys
+	a.datasetClosers = closers
+	a.datasetOverridden = true
+	return nil
+}
+
+// errTerritoriesUnavailable is returned for --include-territories when the
+// build has no territory files.
+var errTerritoriesUnavailable = errors.New("the territory dataset is not available in this build; run names update-data --territories to download it")
+
+// TerritoryDataset returns the territory files to query: embedded when it
+// holds any, and otherwise dir, where update-data --territories unpacks
+// them, once that exists.
+func TerritoryDataset(embedded fs.FS, dir string) fs.FS {
+	if embedded != nil {
+		if codes, err := namesdata.States(embedded); err == nil && len(codes) > 0 {
+			return embedded
+		}
+	}
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return os.DirFS(dir)
+	}
+	return embedded
+}
+
+// useTerritories layers the territory files onto the current run's dataset,
+// or removes them again when enabled is false. The territories have their
+// own codes, so they add to national totals and can be queried with
+// -state PR without changing any state's numbers.
+func (a *App) useTerritories(enabled bool) error {
+	if enabled == a.withTerritories {
+		return nil
+	}
+	if !enabled {
+		a.Dataset = a.territoryless
+		a.withTerritories = false
+		a.territoryless = nil
+		return nil
+	}
+	if a.Territories == nil {
+		return errTerritoriesUnavailable
+	}
+	codes, err := namesdata.States(a.Territories)
+	if err != nil {
+		return fmt.Errorf("territories: %w", err)
+	}
+	if len(codes) == 0 {
+		return errTerritoriesUnavailable
+	}
+	a.territoryless = a.Dataset
+	a.Dataset = namesdata.Merge(a.Dataset, a.Territories)
+	a.withTerritories = true
+	return nil
+}
+
+// openDataset opens one --dataset entry. The closer is nil unless path is
+// a zip archive.
+func (a *App) openDataset(path string) (fs.FS, io.Closer, error) {
+	if path == embeddedDatasetName {
+		if a.baseDataset != nil {
+			return a.baseDataset, nil, nil
+		}
+		return a.Dataset, nil, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dataset: %w", err)
+	}
+	switch {
+	case info.IsDir():
+		return os.DirFS(path), nil, nil
+	case strings.EqualFold(filepath.Ext(path), ".zip"):
+		fsys, closer, err := namesdata.OpenZip(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("dataset: %w", err)
+		}
+		return fsys, closer, nil
+	}
+	return nil, nil, fmt.Errorf("dataset: %s is not a directory or .zip archive", path)
+}
+
+// closeDataset releases the archives behind a --dataset, if any.
+func (a *App) closeDataset() {
+	for _, closer := range a.datasetClosers {
+		closer.Close()
+	}
+	a.datasetClosers = nil
+}
+
+// random returns the run's shared RNG, seeding it on first use.
+func (a *App) random() *rand.Rand {
+	if a.rng == nil {
+		if a.seed == 0 {
+			a.seed = time.Now().UnixNano()
+		}
+		a.rng = rand.New(rand.NewSource(a.seed))
+	}
+	return a.rng
+}
+
+// render writes the report to stdout, recording the seed in the metadata
+// whenever the run was seeded or consumed randomness. When requested, the
+// title, footer, and metadata are also written to a JSON sidecar file.
+func (a *App) render(output *outputOptions, rpt report) error {
+	if a.seed != 0 {
+		if rpt.Metadata == nil {
+			rpt.Metadata = map[string]string{}
+		}
+		rpt.Metadata["seed"] = fmt.Sprintf("%d", a.seed)
+	}
+	if a.withTerritories {
+		if rpt.Metadata == nil {
+			rpt.Metadata = map[string]string{}
+		}
+		rpt.Metadata["territories"] = "included"
+	}
+	if output.MetadataFile != "" {
+		if err := writeMetadataSidecar(output.MetadataFile, rpt); err != nil {
+			return err
+		}
+	}
+	output.colorize = a.useColor(output)
+	return renderReport(a.Stdout, *output, rpt)
+}
+
+// useColor reports whether table output to stdout should use ANSI colors.
+func (a *App) useColor(output *outputOptions) bool {
+	return output.Format == formatTable && output.Color.enabled(a.Stdout)
+}
+
+// loadRecords loads a single state's records, or every state's when state is
+// empty.
+func (a *App) loadRecords(state string) ([]namesdata.Record, error) {
+	if trimmed := strings.TrimSpace(state); trimmed != "" {
+		return namesdata.LoadStateRecords(a.Dataset, trimmed)
+	}
+	return namesdata.LoadAllRecords(a.Dataset)
 }
 
 func (a *App) printVersion() {
@@ -74,6 +532,9 @@ type yearFilter struct {
 	years map[int]struct{}
 }
 
+// allYears is the yearFilter matching every year.
+var allYears = yearFilter{all: true}
+
 func parseYearFilter(raw string) (yearFilter, error) {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" || trimmed == "0" {
@@ -147,6 +608,24 @@ func (f yearFilter) Contains(year int) bool {
 	return ok
 }
 
+// Bounds returns the earliest and latest explicitly selected years. Both are
+// zero when the filter matches all years.
+func (f yearFilter) Bounds() (int, int) {
+	if f.all {
+		return 0, 0
+	}
+	first, last := 0, 0
+	for year := range f.years {
+		if first == 0 || year < first {
+			first = year
+		}
+		if year > last {
+			last = year
+		}
+	}
+	return first, last
+}
+
 func (f yearFilter) String() string {
 	if f.all {
 		return ""
@@ -173,6 +652,33 @@ func (f yearFilter) String() string {
 	return strings.Join(segments, ", ")
 }
 
+// chartTheme resolves the --theme and --palette flags of chart output.
+func chartTheme(name, paletteCSV string) (visualize.Theme, error) {
+	theme, err := visualize.LookupTheme(name)
+	if err != nil {
+		return visualize.Theme{}, fmt.Errorf("--theme: %w", err)
+	}
+	palette, err := visualize.ParsePalette(paletteCSV)
+	if err != nil {
+		return visualize.Theme{}, fmt.Errorf("--palette: %w", err)
+	}
+	return theme.WithPalette(palette), nil
+}
+
+// addPlotStyleFlag registers --plot-style for commands with --plot.
+func addPlotStyleFlag(fs *flag.FlagSet) *string {
+	return fs.String("plot-style", visualize.PlotStyleBlock, "sparkline style for --plot: block, or braille for 2x4 dots per character")
+}
+
+// parsePlotStyle validates a --plot-style value.
+func parsePlotStyle(raw string) (string, error) {
+	style, err := visualize.ParsePlotStyle(raw)
+	if err != nil {
+		return "", fmt.Errorf("--plot-style: %w", err)
+	}
+	return style, nil
+}
+
 func formatYearSegment(start, end int) string {
 	if start == end {
 		return fmt.Sprintf("%d", start)
@@ -193,29 +699,164 @@ func filterRecordsByYear(records []namesdata.Record, filter yearFilter) []namesd
 	return filtered
 }
 
+// validateYearRange checks optional --from/--to bounds, where zero leaves the
+// corresponding end of the range open.
+func validateYearRange(from, to int) error {
+	if from < 0 || to < 0 {
+		return errors.New("--from and --to must be positive")
+	}
+	if from != 0 && to != 0 && to < from {
+		return fmt.Errorf("invalid year range %d-%d", from, to)
+	}
+	return nil
+}
+
+// parseYearSpan parses a single year or contiguous range such as 1990-2020.
+// An empty value yields the zero, unbounded range.
+func parseYearSpan(raw string) (namesdata.YearRange, error) {
+	filter, err := parseYearFilter(raw)
+	if err != nil {
+		return namesdata.YearRange{}, err
+	}
+	if strings.Contains(filter.String(), ",") {
+		return namesdata.YearRange{}, fmt.Errorf("%s is not a single year or contiguous range", filter.String())
+	}
+	from, to := filter.Bounds()
+	return namesdata.YearRange{From: from, To: to}, nil
+}
+
+// filterRecordsByYearRange keeps records within the inclusive bounds, where a
+// zero bound is open-ended.
+func filterRecordsByYearRange(records []namesdata.Record, from, to int) []namesdata.Record {
+	if from == 0 && to == 0 {
+		return records
+	}
+	filtered := make([]namesdata.Record, 0, len(records))
+	for _, record := range records {
+		if from != 0 && record.Year < from {
+			continue
+		}
+		if to != 0 && record.Year > to {
+			continue
+		}
+		filtered = append(filtered, record)
+	}
+	return filtered
+}
+
+// missingTrendName returns the first requested name that no series observes
+// in any year. A name split by gender counts as found when either of its
+// series is present.
+func missingTrendName(series []namesdata.TrendSeries) (string, bool) {
+	found := make(map[string]bool, len(series))
+	order := make([]string, 0, len(series))
+	for _, s := range series {
+		key := strings.ToUpper(s.Name)
+		if _, ok := found[key]; !ok {
+			order = append(order, s.Name)
+			found[key] = false
+		}
+		for _, point := range s.Points {
+			if point.Present {
+				found[key] = true
+				break
+			}
+		}
+	}
+	for _, name := range order {
+		if !found[strings.ToUpper(name)] {
+			return name, true
+		}
+	}
+	return "", false
+}
+
 func (a *App) runTop(args []string) error {
 	fs := flag.NewFlagSet("names", flag.ContinueOnError)
 	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
 
 	state := fs.String("state", "", "optional two-letter state abbreviation (e.g. CA)")
 	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
 	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
 	topN := fs.Int("top", 10, "number of names to display")
-	name := fs.String("name", "", "specific name to report rank for (requires -year)")
-	formatFlag := fs.String("format", "table", "output format: table, json, or csv")
-
-	if err := fs.Parse(args); err != nil {
+	offset := fs.Int("offset", 0, "number of top names to skip before listing (e.g. 499 to start at rank 500)")
+	page := fs.Int("page", 0, "1-based page of -page-size names to list (0 lists from -offset)")
+	pageSize := fs.Int("page-size", 0, "names per page with -page (defaults to -top)")
+	name := fs.String("name", "", "name, or comma-separated names, to report the rank of (requires -year)")
+	by := fs.String("by", "", "optional grouping: state (one row per state) or decade (one column pair per decade)")
+	fs.StringVar(by, "group-by", "", "alias for -by")
+	rankBy := fs.String("rank-by", "count", "order names by total count or by share (average yearly share of births)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
 		return err
 	}
 
+	byShare := false
+	switch strings.ToLower(strings.TrimSpace(*rankBy)) {
+	case "count":
+	case "share":
+		byShare = true
+	default:
+		return fmt.Errorf("unsupported --rank-by %q (expected count or share)", *rankBy)
+	}
+	if byShare && strings.TrimSpace(*by) != "" {
+		return errors.New("--rank-by share cannot be combined with --by")
+	}
+
 	yearFilter, err := parseYearFilter(*year)
 	if err != nil {
 		return err
 	}
 
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return err
+	}
+
 	if *topN < 1 {
 		return errors.New("-top must be 1 or greater")
 	}
+	if *offset < 0 || *page < 0 || *pageSize < 0 {
+		return errors.New("-offset, -page, and -page-size must be 0 or greater")
+	}
+	if *page > 0 && *offset > 0 {
+		return errors.New("-page cannot be combined with -offset")
+	}
+	if *pageSize > 0 && *page == 0 {
+		return errors.New("-page-size requires -page")
+	}
+	paginated := *offset > 0 || *page > 0
+	if paginated && strings.TrimSpace(*by) != "" {
+		return errors.New("-offset and -page cannot be combined with --by")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(*by)) {
+	case "":
+	case "state":
+		if strings.TrimSpace(*state) != "" || strings.TrimSpace(*name) != "" {
+			return errors.New("--by state cannot be combined with -state or -name")
+		}
+		if scope == scopeNational {
+			return errors.New("--by state cannot be combined with --scope national")
+		}
+		if err := output.resolve(); err != nil {
+			return err
+		}
+		return a.runTopByState(yearFilter, *gender, *topN, output)
+	case "decade":
+		if strings.TrimSpace(*name) != "" {
+			return errors.New("--by decade cannot be combined with -name")
+		}
+		if err := output.resolve(); err != nil {
+			return err
+		}
+		return a.runTopByDecade(scope, strings.TrimSpace(*state), yearFilter, *gender, *topN, output)
+	default:
+		return fmt.Errorf("unsupported grouping %q (expected state or decade)", *by)
+	}
 
 	if strings.TrimSpace(*name) != "" && yearFilter.All() {
 		return errors.New("-year must be set when using -name")
@@ -223,22 +864,39 @@ func (a *App) runTop(args []string) error {
 
 	trimmedState := strings.TrimSpace(*state)
 
-	var records []namesdata.Record
-	if trimmedState == "" {
-		records, err = namesdata.LoadAllRecords(a.Dataset)
+	var (
+		aggregated []namesdata.NameCount
+		ranks      map[string]int
+		shares     []float64
+	)
+	if byShare {
+		records, err := a.scopedRecords(scope, trimmedState)
+		if err != nil {
+			return err
+		}
+		var entries []namesdata.NameShare
+		entries, ranks = namesdata.AggregateNamesByShare(filterRecordsByYear(records, yearFilter), *gender)
+		aggregated = make([]namesdata.NameCount, len(entries))
+		shares = make([]float64, len(entries))
+		for i, entry := range entries {
+			aggregated[i] = entry.NameCount
+			shares[i] = entry.Share
+		}
 	} else {
-		records, err = namesdata.LoadStateRecords(a.Dataset, trimmedState)
-	}
-	if err != nil {
-		return err
+		var total int
+		aggregated, total, err = a.cachedAggregate(scope, trimmedState, *gender, yearFilter)
+		if err != nil {
+			return err
+		}
+		ranks = make(map[string]int, len(aggregated))
+		shares = make([]float64, len(aggregated))
+		for i, entry := range aggregated {
+			ranks[strings.ToUpper(entry.Name)] = i + 1
+			shares[i] = float64(entry.Count) / float64(total)
+		}
 	}
 
-	filteredRecords := filterRecordsByYear(records, yearFilter)
-
-	aggregated, ranks := namesdata.AggregateNames(filteredRecords, 0, *gender)
-
-	format, err := parseOutputFormat(*formatFlag)
-	if err != nil {
+	if err := output.resolve(); err != nil {
 		return err
 	}
 
@@ -251,6 +909,9 @@ func (a *App) runTop(args []string) error {
 		displayLocation = "the United States"
 	}
 	metadata["state"] = metadataState
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
 
 	if desc := yearFilter.String(); desc != "" {
 		metadata["year"] = desc
@@ -258,71 +919,136 @@ func (a *App) runTop(args []string) error {
 	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
 		metadata["gender"] = strings.ToUpper(trimmed)
 	}
+	if byShare {
+		metadata["rank_by"] = "share"
+	}
 
+	headers := []string{"Rank", "Name", "Count", "Share"}
 	if len(aggregated) == 0 {
-		rpt := report{
-			Lines:    []string{"No matching names found."},
-			Metadata: metadata,
-			Headers:  []string{"Rank", "Name", "Count"},
-			Rows:     nil,
-		}
-		return renderReport(a.Stdout, format, rpt)
+		return namesdata.ErrNoMatches
 	}
 
 	lines := make([]string, 0, 3)
 
-	if trimmed := strings.TrimSpace(*name); trimmed != "" {
-		rank, entry, err := namesdata.RankFromAggregate(aggregated, ranks, trimmed)
+	queried := make(map[string]bool)
+	if queries := splitNames(*name); len(queries) > 0 {
+		found, err := namesdata.RankManyFromAggregate(aggregated, ranks, queries)
 		if err != nil {
 			return err
 		}
-		rankLine := fmt.Sprintf("%s ranks #%d in %s", entry.Name, rank, displayLocation)
-		if desc := yearFilter.String(); desc != "" {
-			rankLine += fmt.Sprintf(" for %s", desc)
-		}
-		if strings.TrimSpace(*gender) != "" {
-			rankLine += fmt.Sprintf(" (%s)", strings.ToUpper(*gender))
+		var queriedNames, queriedRanks, queriedCounts []string
+		for _, r := range found {
+			if !r.Found() {
+				// Report the first missing name with its suggestions.
+				_, _, err := namesdata.RankFromAggregate(aggregated, ranks, r.Query)
+				return err
+			}
+			rankLine := fmt.Sprintf("%s ranks #%d in %s", r.Entry.Name, r.Rank, displayLocation)
+			if desc := yearFilter.String(); desc != "" {
+				rankLine += fmt.Sprintf(" for %s", desc)
+			}
+			if strings.TrimSpace(*gender) != "" {
+				rankLine += fmt.Sprintf(" (%s)", strings.ToUpper(*gender))
+			}
+			rankLine += fmt.Sprintf(" with %d occurrences", r.Entry.Count)
+			lines = append(lines, rankLine)
+
+			queried[r.Entry.Name] = true
+			queriedNames = append(queriedNames, r.Entry.Name)
+			queriedRanks = append(queriedRanks, fmt.Sprintf("%d", r.Rank))
+			queriedCounts = append(queriedCounts, fmt.Sprintf("%d", r.Entry.Count))
 		}
-		rankLine += fmt.Sprintf(" with %d occurrences", entry.Count)
-		lines = append(lines, rankLine, "")
+		lines = append(lines, "")
 
-		metadata["queried_name"] = entry.Name
-		metadata["queried_rank"] = fmt.Sprintf("%d", rank)
-		metadata["queried_count"] = fmt.Sprintf("%d", entry.Count)
+		metadata["queried_name"] = strings.Join(queriedNames, ", ")
+		metadata["queried_rank"] = strings.Join(queriedRanks, ", ")
+		metadata["queried_count"] = strings.Join(queriedCounts, ", ")
 	}
 
-	topNames := aggregated
-	if *topN > 0 && len(topNames) > *topN {
-		topNames = topNames[:*topN]
+	// Huge pages and limits are clamped before any arithmetic, so they
+	// cannot overflow into negative slice bounds.
+	limit, start := *topN, *offset
+	if *page > 0 {
+		if *pageSize > 0 {
+			limit = *pageSize
+		}
+		if *page-1 > len(aggregated)/limit {
+			start = len(aggregated)
+		} else {
+			start = (*page - 1) * limit
+		}
+	}
+	start = min(start, len(aggregated))
+	topNames := aggregated[start : start+min(limit, len(aggregated)-start)]
+
+	var footer []string
+	if paginated {
+		metadata["offset"] = fmt.Sprintf("%d", start)
+		metadata["total_names"] = fmt.Sprintf("%d", len(aggregated))
+		position := fmt.Sprintf("Ranks %d-%d of %d.", start+1, start+len(topNames), len(aggregated))
+		if len(topNames) == 0 {
+			position = fmt.Sprintf("No names past rank %d; %d names match.", start, len(aggregated))
+		}
+		if *page > 0 {
+			pages := len(aggregated) / limit
+			if len(aggregated)%limit != 0 {
+				pages++
+			}
+			metadata["page"] = fmt.Sprintf("%d", *page)
+			metadata["page_size"] = fmt.Sprintf("%d", limit)
+			metadata["pages"] = fmt.Sprintf("%d", pages)
+			position = fmt.Sprintf("Page %d of %d. %s", *page, pages, position)
+		}
+		footer = append(footer, position)
 	}
 
 	title := fmt.Sprintf("Top %d names in %s", len(topNames), displayLocation)
+	switch {
+	case start > 0 && len(topNames) == 0:
+		title = fmt.Sprintf("Names ranked after %d in %s", start, displayLocation)
+	case start > 0:
+		title = fmt.Sprintf("Names ranked %d-%d in %s", start+1, start+len(topNames), displayLocation)
+	}
 	if desc := yearFilter.String(); desc != "" {
 		title += fmt.Sprintf(" for %s", desc)
 	}
 	if strings.TrimSpace(*gender) != "" {
 		title += fmt.Sprintf(" (%s)", strings.ToUpper(*gender))
 	}
+	if byShare {
+		title += " by average yearly share"
+	}
 	title += ":"
 	lines = append(lines, title)
 
-	rows := make([][]string, len(topNames))
+	rows := make([][]any, len(topNames))
+	var highlight []int
 	for i, entry := range topNames {
-		rows[i] = []string{
-			fmt.Sprintf("%d", i+1),
+		rows[i] = []any{
+			start + i + 1,
 			entry.Name,
-			fmt.Sprintf("%d", entry.Count),
+			entry.Count,
+			percentCell(shares[start+i], 2),
+		}
+		if queried[entry.Name] {
+			highlight = append(highlight, i)
 		}
 	}
 
+	if byShare {
+		footer = append(footer, "Share is each name's average share of births per year, so years with more births do not outweigh the rest.")
+	}
+
 	rpt := report{
-		Lines:    lines,
-		Metadata: metadata,
-		Headers:  []string{"Rank", "Name", "Count"},
-		Rows:     rows,
+		Lines:     lines,
+		Footer:    footer,
+		Metadata:  metadata,
+		Headers:   headers,
+		Rows:      rows,
+		Highlight: highlight,
 	}
 
-	return renderReport(a.Stdout, format, rpt)
+	return a.render(output, rpt)
 }
 
 func (a *App) runGenerate(args []string) error {
@@ -330,102 +1056,315 @@ func (a *App) runGenerate(args []string) error {
 	fs.SetOutput(a.Stderr)
 
 	state := fs.String("state", "", "optional two-letter state abbreviation")
-	year := fs.Int("year", 0, "specific year to filter on (0 for all years)")
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
 	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	recency := fs.String("recency", "none", "recency weighting across the selected years: none, linear, or exponential")
+	halfLife := fs.Float64("half-life", defaultHalfLife, "years for the weight to halve with --recency exponential")
 	count := fs.Int("count", 1, "number of names to generate")
-	formatFlag := fs.String("format", "table", "output format: table, json, or csv")
-	seed := fs.Int64("seed", 0, "optional RNG seed for reproducible suggestions")
-
-	if err := fs.Parse(args); err != nil {
+	unique := fs.Bool("unique", false, "draw distinct names (sampling without replacement)")
+	temperature := fs.Float64("temperature", 1, "exponent applied to counts before sampling: below 1 favors rarer names, above 1 the most popular")
+	pair := fs.Bool("pair", false, "generate first and middle name pairs, never repeating the first name as the middle")
+	middleYear := fs.String("middle-year", "", "year filter for middle names with --pair (defaults to -year)")
+	startsWith := fs.String("starts-with", "", "only draw names starting with this prefix")
+	endsWith := fs.String("ends-with", "", "only draw names ending with this suffix")
+	minLength := fs.Int("min-length", 0, "only draw names with at least this many letters (0 for no minimum)")
+	maxLength := fs.Int("max-length", 0, "only draw names with at most this many letters (0 for no maximum)")
+	exclude := fs.String("exclude", "", "comma-separated names never to draw")
+	excludeFile := fs.String("exclude-file", "", "file listing names never to draw, one per line")
+	skipTop := fs.Int("skip-top", 0, "skip this many of the most popular names matching the filters before drawing")
+	synthetic := fs.Bool("synthetic", false, "invent new names with a character-level Markov model trained on the selected names")
+	order := fs.Int("order", 3, "letters of context for --synthetic (2 or 3; lower invents more freely)")
+	secure := fs.Bool("secure", false, "draw from crypto/rand instead of a seeded generator, so picks cannot be predicted (incompatible with --seed)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+	a.registerGlobalFlags(fs)
+
+	if err := a.parseFlags(fs, args); err != nil {
 		return err
 	}
 
-	trimmedState := strings.TrimSpace(*state)
-
 	if *count < 1 {
 		return errors.New("--count must be at least 1")
 	}
+	random := a.random
+	if *secure {
+		if a.seed != 0 {
+			return errors.New("--secure cannot be combined with --seed")
+		}
+		random = namesdata.NewSecureRand
+	}
 
-	format, err := parseOutputFormat(*formatFlag)
+	filters, err := parseDrawFilters(*state, *year, *gender, *recency, *halfLife, *scopeFlag)
 	if err != nil {
 		return err
 	}
+	scope, trimmedState, yearFilter := filters.scope, filters.state, filters.years
 
-	metadata := map[string]string{}
-	if trimmedState != "" {
-		metadata["state"] = strings.ToUpper(trimmedState)
-	} else {
-		metadata["state"] = "NATIONAL"
+	if strings.TrimSpace(*middleYear) != "" && !*pair {
+		return errors.New("--middle-year requires --pair")
 	}
-	if *year != 0 {
-		metadata["year"] = fmt.Sprintf("%d", *year)
+	middleFilter, err := parseYearFilter(*middleYear)
+	if err != nil {
+		return fmt.Errorf("--middle-year: %w", err)
 	}
-	if trimmedGender := strings.TrimSpace(*gender); trimmedGender != "" {
-		metadata["gender"] = strings.ToUpper(trimmedGender)
+	if *synthetic && *pair {
+		return errors.New("--synthetic cannot be combined with --pair")
 	}
-	metadata["sample_count"] = fmt.Sprintf("%d", *count)
-
-	var rng *rand.Rand
-	if *seed != 0 {
-		rng = rand.New(rand.NewSource(*seed))
-		metadata["seed"] = fmt.Sprintf("%d", *seed)
+	if *temperature < 0 || math.IsNaN(*temperature) || math.IsInf(*temperature, 0) {
+		return errors.New("--temperature must be 0 or greater")
+	}
+	if *skipTop < 0 {
+		return errors.New("--skip-top must be 0 or greater")
+	}
+	if *synthetic && *skipTop > 0 {
+		return errors.New("--skip-top cannot be combined with --synthetic")
+	}
+	if *synthetic && *temperature != 1 {
+		return errors.New("--temperature cannot be combined with --synthetic")
+	}
+	if *synthetic && (*order < namesdata.MinMarkovOrder || *order > namesdata.MaxMarkovOrder) {
+		return fmt.Errorf("--order must be between %d and %d", namesdata.MinMarkovOrder, namesdata.MaxMarkovOrder)
 	}
 
-	aggregated, total, err := namesdata.AggregateFromFS(a.Dataset, trimmedState, *year, *gender)
-	if err != nil {
-		if strings.Contains(err.Error(), "no matching records") {
-			metadata["total_occurrences"] = "0"
-			lines := []string{"No matching names found."}
-			rpt := report{
-				Lines:    lines,
-				Metadata: metadata,
-				Headers:  []string{"Pick", "Name", "DatasetCount", "Chance"},
-			}
-			return renderReport(a.Stdout, format, rpt)
-		}
+	constraints := namesdata.NameConstraints{
+		StartsWith: *startsWith,
+		EndsWith:   *endsWith,
+		MinLength:  *minLength,
+		MaxLength:  *maxLength,
+		Exclude:    splitNames(*exclude),
+	}
+	if err := constraints.Validate(); err != nil {
 		return err
 	}
-	metadata["total_occurrences"] = fmt.Sprintf("%d", total)
+	// Names from --exclude-file are counted rather than listed in the
+	// description, which could otherwise run to thousands of names.
+	described := constraints.String()
+	if path := strings.TrimSpace(*excludeFile); path != "" {
+		fileExcluded, err := readNameList(path)
+		if err != nil {
+			return fmt.Errorf("--exclude-file: %w", err)
+		}
+		constraints.Exclude = append(constraints.Exclude, fileExcluded...)
+		if described != "" {
+			described += ", "
+		}
+		noun := "names"
+		if len(fileExcluded) == 1 {
+			noun = "name"
+		}
+		described += fmt.Sprintf("excluding %d %s from %s", len(fileExcluded), noun, path)
+	}
 
-	sampler, err := namesdata.NewNameSampler(aggregated)
-	if err != nil {
+	if err := output.resolve(); err != nil {
 		return err
 	}
 
-	if rng == nil {
-		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	headers := []string{"Pick", "Name", "DatasetCount", "Chance"}
+	switch {
+	case *pair:
+		headers = []string{"Pick", "Name", "First", "First Chance", "Middle", "Middle Chance"}
+	case *synthetic:
+		headers = []string{"Pick", "Name", "Nearest Real Name"}
+	}
+
+	metadata := filters.metadata()
+	metadata["sample_count"] = fmt.Sprintf("%d", *count)
+	if *unique {
+		metadata["unique"] = "true"
+	}
+	if *temperature != 1 {
+		metadata["temperature"] = strconv.FormatFloat(*temperature, 'g', -1, 64)
+	}
+	if *pair {
+		metadata["pair"] = "true"
+		if desc := middleFilter.String(); desc != "" {
+			metadata["middle_year"] = desc
+		}
+	}
+	if described != "" {
+		metadata["constraints"] = described
+	}
+	if *skipTop > 0 {
+		metadata["skip_top"] = fmt.Sprintf("%d", *skipTop)
+	}
+	if *secure {
+		metadata["secure"] = "true"
+	}
+	if *synthetic {
+		metadata["synthetic"] = "true"
+		metadata["markov_order"] = fmt.Sprintf("%d", *order)
 	}
 
-	scope := metadata["state"]
-	if strings.EqualFold(scope, "NATIONAL") {
-		scope = "National"
+	location := metadata["state"]
+	if strings.EqualFold(location, "NATIONAL") {
+		location = "National"
 	}
 	title := fmt.Sprintf("Generated %d name", *count)
+	if *synthetic {
+		title = fmt.Sprintf("Generated %d synthetic name", *count)
+	}
+	if *pair {
+		title += " pair"
+	}
 	if *count != 1 {
 		title += "s"
 	}
-	title += fmt.Sprintf(" for %s", scope)
-	if *year != 0 {
-		title += fmt.Sprintf(" in %d", *year)
+	title += fmt.Sprintf(" for %s", location)
+	if desc := yearFilter.String(); desc != "" {
+		title += fmt.Sprintf(" in %s", desc)
 	}
 	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
 		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
 	}
-
+	if desc, ok := metadata["constraints"]; ok {
+		title += fmt.Sprintf(", %s", desc)
+	}
+	if *skipTop > 0 {
+		title += fmt.Sprintf(", outside the top %d", *skipTop)
+	}
+	if desc, ok := metadata["middle_year"]; ok {
+		title += fmt.Sprintf(", middle names from %s", desc)
+	}
+	if desc, ok := metadata["temperature"]; ok {
+		title += fmt.Sprintf(", temperature %s", desc)
+	}
 	lines := []string{title, ""}
-	rows := make([][]string, *count)
 
-	for i := 0; i < *count; i++ {
-		entry, err := sampler.Pick(rng)
+	if *unique && !*pair && !*synthetic && constraints.IsZero() && !filters.weighted() && *temperature == 1 && *skipTop == 0 && a.Cache == nil {
+		// Plain distinct draws only need the picks' counts, so they are
+		// sampled straight from the record stream rather than from a
+		// sorted aggregate and sampler.
+		picks, total, err := namesdata.SampleK(a.yearFilteredStream(scope, trimmedState, *gender, yearFilter), *count, random())
+		if err != nil {
+			return err
+		}
+		metadata["total_occurrences"] = fmt.Sprintf("%d", total)
+		share := func(entry namesdata.NameCount) float64 { return float64(entry.Count) / float64(total) }
+		return a.render(output, report{Lines: lines, Metadata: metadata, Headers: headers, Rows: generatedRows(picks, share, metadata)})
+	}
+
+	entry, err := a.drawAggregate(filters)
+	if err != nil {
+		return err
+	}
+	aggregated, total := entry.Aggregated, entry.Total
+
+	if *synthetic {
+		// Invented names must be new to the whole dataset, not just to the
+		// filtered names the model learns from.
+		known, _, err := a.cachedAggregate(scope, "", "", allYears)
+		if err != nil {
+			return err
+		}
+		rows, err := generateSyntheticRows(aggregated, known, *order, *count, *unique, constraints, metadata, random())
+		if err != nil {
+			return err
+		}
+		return a.render(output, report{Lines: lines, Metadata: metadata, Headers: headers, Rows: rows})
+	}
+
+	// The most popular names are skipped by their rank among every name
+	// matching the filters, and constraints are then applied before the
+	// sampler is built, so chances are shares of the remaining names only.
+	pool, poolTotal := aggregated, total
+	if *skipTop > 0 {
+		pool, poolTotal = namesdata.SkipTop(aggregated, *skipTop)
+	}
+	if !constraints.IsZero() {
+		pool, poolTotal = constraints.Select(pool)
+	}
+	if *skipTop > 0 || !constraints.IsZero() {
+		metadata["eligible_names"] = fmt.Sprintf("%d", len(pool))
+	}
+	metadata["total_occurrences"] = fmt.Sprintf("%d", poolTotal)
+	if len(pool) == 0 {
+		switch {
+		case constraints.IsZero():
+			return fmt.Errorf("%w: no names remain outside the top %d", namesdata.ErrNoMatches, *skipTop)
+		case *skipTop > 0:
+			return fmt.Errorf("%w: no names outside the top %d match the constraints (%s)", namesdata.ErrNoMatches, *skipTop, described)
+		}
+		return fmt.Errorf("%w: no names match the constraints (%s)", namesdata.ErrNoMatches, described)
+	}
+
+	var sampler *namesdata.NameSampler
+	if *skipTop == 0 && constraints.IsZero() {
+		sampler, err = a.drawSampler(filters, entry, *temperature, *count)
+	} else {
+		sampler, err = namesdata.NewTemperedNameSampler(pool, *temperature, namesdata.SamplerAuto, *count)
+	}
+	if err != nil {
+		return err
+	}
+
+	rng := random()
+
+	if *pair {
+		// Only --exclude applies to middle names; --skip-top and the other
+		// constraints describe the first name.
+		middleAggregated := aggregated
+		if !middleFilter.All() {
+			middleAggregated, _, err = a.cachedAggregate(scope, trimmedState, *gender, middleFilter)
+			if err != nil {
+				return fmt.Errorf("middle names: %w", err)
+			}
+		}
+		middleAggregated, _ = namesdata.NameConstraints{Exclude: constraints.Exclude}.Select(middleAggregated)
+		middleSampler := sampler
+		if !middleFilter.All() || !constraints.IsZero() || *skipTop > 0 {
+			middleSampler, err = namesdata.NewTemperedNameSampler(middleAggregated, *temperature, namesdata.SamplerAuto, *count)
+			if err != nil {
+				return fmt.Errorf("middle names: %w", err)
+			}
+		}
+		pairs, err := namesdata.NewPairSampler(sampler, middleSampler)
+		if err != nil {
+			return err
+		}
+		rows, err := generatePairRows(pairs, *count, *unique, sampler.Chance, middleSampler.Chance, metadata, rng)
+		if err != nil {
+			return err
+		}
+		return a.render(output, report{Lines: lines, Metadata: metadata, Headers: headers, Rows: rows})
+	}
+
+	var picks []namesdata.NameCount
+	if *unique {
+		picks, err = sampler.PickUnique(*count, rng)
 		if err != nil {
 			return err
 		}
-		probability := float64(entry.Count) / float64(total)
-		rows[i] = []string{
-			fmt.Sprintf("%d", i+1),
+	} else {
+		picks = make([]namesdata.NameCount, *count)
+		for i := range picks {
+			picks[i], err = sampler.Pick(rng)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	rpt := report{
+		Lines:    lines,
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     generatedRows(picks, sampler.Chance, metadata),
+	}
+
+	return a.render(output, rpt)
+}
+
+// generatedRows lists picks with each name's chance of being drawn, as
+// reported by chance. The first pick is recorded in the metadata.
+func generatedRows(picks []namesdata.NameCount, chance func(namesdata.NameCount) float64, metadata map[string]string) [][]any {
+	rows := make([][]any, len(picks))
+	for i, entry := range picks {
+		probability := chance(entry)
+		rows[i] = []any{
+			i + 1,
 			entry.Name,
-			fmt.Sprintf("%d", entry.Count),
-			fmt.Sprintf("%.2f%%", probability*100),
+			entry.Count,
+			percentCell(probability, 2),
 		}
 
 		if i == 0 {
@@ -434,84 +1373,414 @@ func (a *App) runGenerate(args []string) error {
 			metadata["chance"] = fmt.Sprintf("%.6f", probability)
 		}
 	}
+	return rows
+}
 
-	rpt := report{
-		Lines:    lines,
-		Metadata: metadata,
-		Headers:  []string{"Pick", "Name", "DatasetCount", "Chance"},
-		Rows:     rows,
+// generatePairRows draws count first and middle name pairs, with distinct
+// first names when unique is set. Each chance is the name's chance of being
+// drawn from its own pool. The first pair is recorded in the metadata.
+func generatePairRows(pairs *namesdata.PairSampler, count int, unique bool, firstChance, middleChance func(namesdata.NameCount) float64, metadata map[string]string, rng *rand.Rand) ([][]any, error) {
+	var picks []namesdata.NamePair
+	if unique {
+		var err error
+		picks, err = pairs.PickUnique(count, rng)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		picks = make([]namesdata.NamePair, count)
+		for i := range picks {
+			pick, err := pairs.Pick(rng)
+			if err != nil {
+				return nil, err
+			}
+			picks[i] = pick
+		}
+	}
+
+	rows := make([][]any, len(picks))
+	for i, pick := range picks {
+		rows[i] = []any{
+			i + 1,
+			pick.String(),
+			pick.First.Name,
+			percentCell(firstChance(pick.First), 2),
+			pick.Middle.Name,
+			percentCell(middleChance(pick.Middle), 2),
+		}
+		if i == 0 {
+			metadata["generated_name"] = pick.String()
+			metadata["generated_first"] = pick.First.Name
+			metadata["generated_middle"] = pick.Middle.Name
+		}
+	}
+	return rows, nil
+}
+
+// generateSyntheticRows invents count names with a Markov model of the
+// given order trained on aggregated, keeping those that are missing from
+// known, satisfy constraints, and, with unique, differ from every earlier
+// pick. Each row names the closest real name when one is within a few
+// edits. The first pick is recorded in the metadata.
+func generateSyntheticRows(aggregated, known []namesdata.NameCount, order, count int, unique bool, constraints namesdata.NameConstraints, metadata map[string]string, rng *rand.Rand) ([][]any, error) {
+	model, err := namesdata.NewMarkovModel(order)
+	if err != nil {
+		return nil, err
+	}
+	model.Train(aggregated)
+	metadata["training_names"] = fmt.Sprintf("%d", len(aggregated))
+
+	existing := make(map[string]bool, len(known))
+	for _, entry := range known {
+		existing[strings.ToUpper(entry.Name)] = true
+	}
+
+	seen := make(map[string]bool)
+	rows := make([][]any, count)
+	for i := range rows {
+		name, err := model.SampleFunc(rng, func(name string) bool {
+			return !existing[strings.ToUpper(name)] && constraints.Match(name) && !(unique && seen[name])
+		})
+		if err != nil {
+			return nil, err
+		}
+		seen[name] = true
+		var nearest any
+		if closest := namesdata.ClosestNames(aggregated, name, 1); len(closest) > 0 {
+			nearest = closest[0]
+		}
+		rows[i] = []any{i + 1, name, nearest}
+		if i == 0 {
+			metadata["generated_name"] = name
+		}
+	}
+	return rows, nil
+}
+
+// defaultHalfLife is the default --half-life for exponential recency, in
+// years.
+const defaultHalfLife = 10
+
+// drawFilters are the filters generate and /generate/stream draw names
+// under, parsed and validated once.
+type drawFilters struct {
+	scope    string
+	state    string
+	gender   string
+	years    yearFilter
+	recency  string
+	halfLife float64
+	weight   namesdata.YearWeight
+}
+
+// parseDrawFilters validates the raw filter values shared by generate and
+// /generate/stream.
+func parseDrawFilters(state, year, gender, recency string, halfLife float64, scopeFlag string) (drawFilters, error) {
+	f := drawFilters{
+		state:    strings.TrimSpace(state),
+		gender:   gender,
+		recency:  strings.ToLower(strings.TrimSpace(recency)),
+		halfLife: halfLife,
+	}
+	var err error
+	if f.scope, err = parseScope(scopeFlag, f.state); err != nil {
+		return drawFilters{}, err
+	}
+	if f.years, err = parseYearFilter(year); err != nil {
+		return drawFilters{}, err
+	}
+	if f.weight, err = recencyWeight(recency, f.years, halfLife); err != nil {
+		return drawFilters{}, err
+	}
+	return f, nil
+}
+
+// metadata describes the filters for a report or stream.
+func (f drawFilters) metadata() map[string]string {
+	metadata := map[string]string{"state": "NATIONAL"}
+	if f.state != "" {
+		metadata["state"] = strings.ToUpper(f.state)
+	}
+	if f.scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if desc := f.years.String(); desc != "" {
+		metadata["year"] = desc
+	}
+	if f.weighted() {
+		metadata["recency"] = f.recency
+		if f.recency == "exponential" {
+			metadata["half_life"] = strconv.FormatFloat(f.halfLife, 'g', -1, 64)
+		}
+	}
+	if trimmed := strings.TrimSpace(f.gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+	return metadata
+}
+
+// weighted reports whether a recency curve weights the selected years.
+func (f drawFilters) weighted() bool {
+	return f.recency != "" && f.recency != "none"
+}
+
+// drawAggregate totals the names matching f, going through the cache unless
+// a recency curve weights the years.
+func (a *App) drawAggregate(f drawFilters) (cache.Entry, error) {
+	if f.weighted() {
+		aggregated, total, err := a.scopedAggregate(f.scope, f.state, f.gender, f.weight)
+		return cache.Entry{Aggregated: aggregated, Total: total}, err
 	}
+	return a.cachedEntry(f.scope, f.state, f.gender, f.years)
+}
 
-	return renderReport(a.Stdout, format, rpt)
+// drawSampler returns a sampler at temperature over entry, the aggregate
+// drawAggregate returned for f, for draws picks. Unweighted draws through
+// the shared cache reuse the alias-table sampler kept in the entry when its
+// temperature matches, and otherwise keep a new one there for later runs.
+func (a *App) drawSampler(f drawFilters, entry cache.Entry, temperature float64, draws int) (*namesdata.NameSampler, error) {
+	c := a.sharedCache()
+	if f.weighted() || c == nil {
+		return namesdata.NewTemperedNameSampler(entry.Aggregated, temperature, namesdata.SamplerAuto, draws)
+	}
+	if s := entry.Sampler; s != nil && s.Strategy() == namesdata.SamplerAlias && s.Temperature() == temperature {
+		return s, nil
+	}
+	sampler, err := namesdata.NewTemperedNameSampler(entry.Aggregated, temperature, namesdata.SamplerAlias, 0)
+	if err != nil {
+		return nil, err
+	}
+	entry.Sampler = sampler
+	c.Add(aggregateKey(f.scope, f.state, f.gender, f.years), entry)
+	return sampler, nil
+}
+
+// recencyWeight builds the per-year weighting used by generate. The "linear"
+// curve ramps from 1/n for the earliest selected year up to 1 for the latest,
+// and the "exponential" curve halves the weight every halfLife years before
+// the latest, so recent years dominate without discarding older ones.
+// halfLife is only used by the exponential curve.
+func recencyWeight(curve string, filter yearFilter, halfLife float64) (namesdata.YearWeight, error) {
+	switch strings.ToLower(strings.TrimSpace(curve)) {
+	case "", "none":
+		return func(year int) float64 {
+			if !filter.Contains(year) {
+				return 0
+			}
+			return 1
+		}, nil
+	case "linear":
+		if filter.All() {
+			return nil, errors.New("--recency requires a -year range")
+		}
+		first, last := filter.Bounds()
+		span := float64(last - first + 1)
+		return func(year int) float64 {
+			if !filter.Contains(year) {
+				return 0
+			}
+			return float64(year-first+1) / span
+		}, nil
+	case "exponential":
+		if filter.All() {
+			return nil, errors.New("--recency requires a -year range")
+		}
+		if halfLife <= 0 || math.IsInf(halfLife, 0) || math.IsNaN(halfLife) {
+			return nil, errors.New("--half-life must be a positive number of years")
+		}
+		_, last := filter.Bounds()
+		decay := namesdata.HalfLifeWeight(last, halfLife)
+		return func(year int) float64 {
+			if !filter.Contains(year) {
+				return 0
+			}
+			return decay(year)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported recency curve %q (expected none, linear, or exponential)", curve)
+	}
 }
 
 func (a *App) runTrend(args []string) error {
 	fs := flag.NewFlagSet("trend", flag.ContinueOnError)
 	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
 
 	name := fs.String("name", "", "name to track")
 	namesCSV := fs.String("names", "", "comma-separated list of names to track")
 	state := fs.String("state", "", "optional two-letter state abbreviation")
 	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
 	plot := fs.Bool("plot", false, "render ASCII sparkline for the selected metric")
-	metric := fs.String("metric", "rank", "metric for plotting: rank, count, or share")
+	metric := fs.String("metric", "rank", "metric for plotting: rank, count, share, or volatility (adds rolling rank volatility columns)")
 	width := fs.Int("width", 80, "plot width when --plot is enabled")
 	height := fs.Int("height", 10, "plot height when --plot is enabled")
+	plotStyle := addPlotStyleFlag(fs)
 	svgPath := fs.String("svg", "", "optional file path to write an SVG chart")
 	svgWidth := fs.Int("svg-width", 800, "SVG width in pixels")
 	svgHeight := fs.Int("svg-height", 400, "SVG height in pixels")
-	formatFlag := fs.String("format", "table", "output format: table, json, or csv")
-
-	if err := fs.Parse(args); err != nil {
+	pngPath := fs.String("png", "", "optional file path to write a PNG chart")
+	vegaPath := fs.String("vega", "", "optional file path to write a Vega-Lite JSON chart specification")
+	pngWidth := fs.Int("png-width", 800, "PNG width in pixels before scaling")
+	pngHeight := fs.Int("png-height", 400, "PNG height in pixels before scaling")
+	pngScale := fs.Int("png-scale", 1, "PNG pixel density multiplier (2 for high-DPI displays)")
+	themeName := fs.String("theme", "light", "color theme for --svg and --png charts: "+strings.Join(visualize.ThemeNames(), ", "))
+	paletteCSV := fs.String("palette", "", "comma-separated series colors for --svg, --png, and --vega charts, e.g. #1b9e77,#d95f02")
+	annotatePeaks := fs.Bool("annotate-peaks", false, "mark each series' peak with its name, year, and value in --svg and --png charts")
+	facet := fs.Bool("facet", false, "draw --svg and --png charts as small multiples, one panel per name")
+	facetSharedY := fs.Bool("facet-shared-y", false, "give every --facet panel the same value range")
+	autoTop := fs.Int("auto-top", 0, "track the N most popular names over the selected period instead of -name/-names")
+	from := fs.Int("from", 0, "first year to include (0 for the earliest year)")
+	to := fs.Int("to", 0, "last year to include (0 for the latest year)")
+	fs.IntVar(from, "since", 0, "alias for --from")
+	fs.IntVar(to, "until", 0, "alias for --to")
+	yearRange := fs.String("year", "", "single year or contiguous range to include, e.g. 1990-2020 (alternative to --since/--until)")
+	splitGender := fs.Bool("split-gender", false, "track each name as separate M and F series (cannot be combined with -gender)")
+	forecast := fs.Int("forecast", 0, "project each series this many years past the last year")
+	forecastModel := fs.String("forecast-model", "linear", "forecast model: linear (least-squares line) or holt (exponential smoothing)")
+	smooth := fs.Int("smooth", 0, "replace each year with the centered moving average over this many years (odd, 0 for raw values)")
+	changepoints := fs.Bool("changepoints", false, "detect years where each name's share of births shifted to a new level and mark them in the table and charts")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
 		return err
 	}
 
+	if *autoTop < 0 {
+		return errors.New("trend: --auto-top must be positive")
+	}
+	if *forecast < 0 {
+		return errors.New("trend: --forecast must be 0 or greater")
+	}
+	if *smooth < 0 || (*smooth > 0 && *smooth%2 == 0) {
+		return errors.New("trend: --smooth must be 0 or an odd number of years")
+	}
+	model, err := namesdata.ParseForecastModel(*forecastModel)
+	if err != nil {
+		return fmt.Errorf("trend: %w", err)
+	}
+	if *splitGender && strings.TrimSpace(*gender) != "" {
+		return errors.New("trend: --split-gender cannot be combined with -gender")
+	}
+	theme, err := chartTheme(*themeName, *paletteCSV)
+	if err != nil {
+		return fmt.Errorf("trend: %w", err)
+	}
+	chartOpts := visualize.ChartOptions{Theme: theme, AnnotatePeaks: *annotatePeaks, Facet: *facet, SharedScale: *facetSharedY}
+	style, err := parsePlotStyle(*plotStyle)
+	if err != nil {
+		return fmt.Errorf("trend: %w", err)
+	}
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("trend: %w", err)
+	}
+	if trimmed := strings.TrimSpace(*yearRange); trimmed != "" {
+		if *from != 0 || *to != 0 {
+			return errors.New("trend: --year cannot be combined with --since/--until")
+		}
+		span, err := parseYearSpan(trimmed)
+		if err != nil {
+			return fmt.Errorf("trend: --year: %w", err)
+		}
+		*from, *to = span.From, span.To
+	}
+	if err := validateYearRange(*from, *to); err != nil {
+		return fmt.Errorf("trend: %w", err)
+	}
+	span := namesdata.YearRange{From: *from, To: *to}
+
 	namesList := make([]string, 0, 4)
 	if trimmed := strings.TrimSpace(*name); trimmed != "" {
 		namesList = append(namesList, trimmed)
 	}
-	if trimmed := strings.TrimSpace(*namesCSV); trimmed != "" {
-		parts := strings.Split(trimmed, ",")
-		for _, part := range parts {
-			if t := strings.TrimSpace(part); t != "" {
-				namesList = append(namesList, t)
-			}
-		}
-	}
+	namesList = append(namesList, splitNames(*namesCSV)...)
 
-	if len(namesList) == 0 {
+	if len(namesList) > 0 && *autoTop > 0 {
+		return errors.New("trend: --auto-top cannot be combined with -name or -names")
+	}
+	if len(namesList) == 0 && *autoTop == 0 {
 		return errors.New("trend: at least one -name or -names value is required")
 	}
 
+	if *autoTop > 0 {
+		// Auto-selected names are meant to be viewed at a glance, so plot
+		// unless the caller explicitly opted out.
+		plotSet := false
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "plot" {
+				plotSet = true
+			}
+		})
+		if !plotSet {
+			*plot = true
+		}
+	}
+
 	metricValue := strings.ToLower(strings.TrimSpace(*metric))
 	switch metricValue {
-	case "rank", "count", "share":
+	case "rank", "count", "share", "volatility":
 	default:
 		return fmt.Errorf("trend: unsupported metric %q", metricValue)
 	}
 
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+
+	if *autoTop > 0 {
+		for _, entry := range namesdata.TopNames(filterRecordsByYearRange(records, span.From, span.To), 0, *gender, *autoTop) {
+			namesList = append(namesList, entry.Name)
+		}
+		if len(namesList) == 0 {
+			return namesdata.ErrNoMatches
+		}
+	}
+
 	var (
-		records []namesdata.Record
-		err     error
+		years  []int
+		series []namesdata.TrendSeries
+		totals map[int]int
 	)
-
-	if trimmed := strings.TrimSpace(*state); trimmed != "" {
-		records, err = namesdata.LoadStateRecords(a.Dataset, trimmed)
+	if *splitGender {
+		years, series, totals, err = namesdata.TrendByGender(records, namesList, span)
 	} else {
-		records, err = namesdata.LoadAllRecords(a.Dataset)
+		years, series, totals, err = namesdata.Trend(records, *gender, namesList, span)
 	}
 	if err != nil {
 		return err
 	}
-
-	years, series, totals, err := namesdata.Trend(records, *gender, namesList)
-	if err != nil {
-		return err
+	if missing, ok := missingTrendName(series); ok {
+		aggregated := namesdata.TopNames(filterRecordsByYearRange(records, span.From, span.To), 0, *gender, 0)
+		return &namesdata.NameNotFoundError{Name: missing, Suggestions: namesdata.ClosestNames(aggregated, missing, 3)}
+	}
+	if *smooth > 1 {
+		series, totals, err = namesdata.Smooth(years, series, totals, *smooth)
+		if err != nil {
+			return fmt.Errorf("trend: %w", err)
+		}
+	}
+	observedYears := years
+	if *forecast > 0 {
+		years, series, totals, err = namesdata.Forecast(years, series, totals, *forecast, model)
+		if err != nil {
+			return fmt.Errorf("trend: %w", err)
+		}
 	}
 
 	nameLabels := make([]string, len(series))
 	for i, s := range series {
-		nameLabels[i] = s.Name
+		nameLabels[i] = s.Label()
+	}
+
+	var shifts [][]namesdata.Changepoint
+	if *changepoints {
+		shifts = make([][]namesdata.Changepoint, len(series))
+		for i, s := range series {
+			shifts[i] = s.Changepoints(namesdata.DefaultChangepointShift)
+		}
+		chartOpts.Changepoints = shifts
 	}
 
 	scopeParts := make([]string, 0, 2)
@@ -523,9 +1792,13 @@ func (a *App) runTrend(args []string) error {
 	} else {
 		scopeParts = append(scopeParts, "National")
 	}
+	yearDesc := ""
+	if span != (namesdata.YearRange{}) {
+		yearDesc = formatYearSegment(observedYears[0], observedYears[len(observedYears)-1])
+		scopeParts = append(scopeParts, yearDesc)
+	}
 
-	format, err := parseOutputFormat(*formatFlag)
-	if err != nil {
+	if err := output.resolve(); err != nil {
 		return err
 	}
 
@@ -544,6 +1817,32 @@ func (a *App) runTrend(args []string) error {
 	if len(scopeParts) > 0 {
 		metadata["scope"] = strings.Join(scopeParts, ", ")
 	}
+	if yearDesc != "" {
+		metadata["year"] = yearDesc
+	}
+	if *autoTop > 0 {
+		metadata["auto_top"] = fmt.Sprintf("%d", *autoTop)
+	}
+	if *splitGender {
+		metadata["split_gender"] = "true"
+	}
+	if *smooth > 1 {
+		metadata["smooth"] = fmt.Sprintf("%d", *smooth)
+	}
+	if *changepoints {
+		found := 0
+		for _, cps := range shifts {
+			found += len(cps)
+		}
+		metadata["changepoints"] = fmt.Sprintf("%d", found)
+	}
+	if *forecast > 0 {
+		metadata["forecast"] = fmt.Sprintf("%d", *forecast)
+		metadata["forecast_model"] = string(model)
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
 
 	title := fmt.Sprintf("Trend for %s", strings.Join(nameLabels, ", "))
 	if len(scopeParts) > 0 {
@@ -553,37 +1852,111 @@ func (a *App) runTrend(args []string) error {
 
 	lines := []string{title, ""}
 
+	volatility := metricValue == "volatility"
 	headers := []string{"Year"}
-	for _, s := range series {
-		headers = append(headers, fmt.Sprintf("%s Rank", s.Name))
-		headers = append(headers, fmt.Sprintf("%s Count", s.Name))
+	rolling := make([][]float64, len(series))
+	for i, s := range series {
+		headers = append(headers, fmt.Sprintf("%s Rank", s.Label()))
+		headers = append(headers, fmt.Sprintf("%s Count", s.Label()))
+		if volatility {
+			headers = append(headers, fmt.Sprintf("%s Volatility", s.Label()))
+			rolling[i] = s.RollingVolatility()
+		}
+	}
+	if *changepoints {
+		headers = append(headers, "Changepoints")
+	}
+	if *forecast > 0 {
+		headers = append(headers, "Forecast")
 	}
 
-	rows := make([][]string, len(years))
+	rows := make([][]any, len(years))
 	for rowIdx, year := range years {
-		row := make([]string, len(headers))
-		row[0] = fmt.Sprintf("%d", year)
+		row := make([]any, len(headers))
+		row[0] = year
 
 		col := 1
-		for _, seriesEntry := range series {
+		for i, seriesEntry := range series {
 			point := seriesEntry.Points[rowIdx]
-			rank := "-"
-			count := "-"
 			if point.Present {
-				rank = fmt.Sprintf("%d", point.Rank)
-				count = fmt.Sprintf("%d", point.Count)
+				row[col] = point.Rank
+				row[col+1] = point.Count
+			}
+			col += 2
+			if volatility {
+				if v := rolling[i][rowIdx]; point.Present && !math.IsNaN(v) {
+					row[col] = decimalCell(v, 2)
+				}
+				col++
+			}
+		}
+		if *changepoints {
+			var marks []string
+			for i, cps := range shifts {
+				for _, cp := range cps {
+					if cp.Year != year {
+						continue
+					}
+					arrow := "↓"
+					if cp.Rising() {
+						arrow = "↑"
+					}
+					marks = append(marks, fmt.Sprintf("%s %s", series[i].Label(), arrow))
+				}
+			}
+			if len(marks) > 0 {
+				row[col] = strings.Join(marks, ", ")
 			}
-			row[col] = rank
-			col++
-			row[col] = count
 			col++
 		}
+		if *forecast > 0 {
+			row[col] = year > observedYears[len(observedYears)-1]
+		}
 		rows[rowIdx] = row
 	}
 
 	footer := make([]string, 0)
+	if volatility {
+		footer = append(footer, fmt.Sprintf("Volatility is the standard deviation of year-over-year rank changes, over the trailing %d changes in the table; lower is steadier.", namesdata.VolatilityWindow))
+		for _, s := range series {
+			if v, ok := s.Volatility(); ok {
+				footer = append(footer, fmt.Sprintf("%s: overall rank volatility %.2f", s.Label(), v))
+			} else {
+				footer = append(footer, fmt.Sprintf("%s: too few consecutive ranked years to measure volatility", s.Label()))
+			}
+		}
+	}
+	if *smooth > 1 {
+		footer = append(footer, fmt.Sprintf("Ranks, counts, and totals are %d-year centered moving averages, shortened at either end of the range.", *smooth))
+	}
+	if *changepoints {
+		footer = append(footer, "Changepoints are years where a name's share of births moved to a new level that at least doubled or halved the one before, lasting three or more years on each side.")
+		for i, cps := range shifts {
+			if len(cps) == 0 {
+				footer = append(footer, fmt.Sprintf("%s: no changepoints", series[i].Label()))
+				continue
+			}
+			parts := make([]string, len(cps))
+			for j, cp := range cps {
+				direction := "fell"
+				if cp.Rising() {
+					direction = "rose"
+				}
+				parts[j] = fmt.Sprintf("%s from %.4f%% to %.4f%% in %d", direction, cp.Before*100, cp.After*100, cp.Year)
+			}
+			footer = append(footer, fmt.Sprintf("%s: share %s", series[i].Label(), strings.Join(parts, "; ")))
+		}
+	}
+	if *forecast > 0 {
+		modelDesc := "a least-squares line"
+		if model == namesdata.ForecastHolt {
+			modelDesc = "Holt exponential smoothing"
+		}
+		footer = append(footer, fmt.Sprintf("Years %s are forecast with %s fitted to %s; treat them as rough projections.",
+			formatYearSegment(observedYears[len(observedYears)-1]+1, years[len(years)-1]), modelDesc, formatYearSegment(observedYears[0], observedYears[len(observedYears)-1])))
+	}
 	if *plot {
-		plotOutput, err := visualize.Sparkline(years, series, totals, metricValue, *width, *height)
+		plotOutput, err := visualize.Sparkline(years, series, totals, metricValue, *width, *height, visualize.PlotOptions{Color: a.useColor(output), Style: style})
 		if err != nil {
 			return err
 		}
@@ -592,7 +1965,7 @@ func (a *App) runTrend(args []string) error {
 	}
 
 	if trimmed := strings.TrimSpace(*svgPath); trimmed != "" {
-		svgOutput, err := visualize.SVG(years, series, totals, metricValue, *svgWidth, *svgHeight, scopeParts)
+		svgOutput, err := visualize.SVG(years, series, totals, metricValue, *svgWidth, *svgHeight, scopeParts, chartOpts)
 		if err != nil {
 			return err
 		}
@@ -605,6 +1978,34 @@ func (a *App) runTrend(args []string) error {
 		footer = append(footer, fmt.Sprintf("SVG chart written to %s", trimmed))
 	}
 
+	if trimmed := strings.TrimSpace(*pngPath); trimmed != "" {
+		var buf bytes.Buffer
+		if err := visualize.PNG(&buf, years, series, totals, metricValue, *pngWidth, *pngHeight, *pngScale, scopeParts, chartOpts); err != nil {
+			return err
+		}
+		if err := os.WriteFile(trimmed, buf.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("write png: %w", err)
+		}
+		if len(footer) > 0 {
+			footer = append(footer, "")
+		}
+		footer = append(footer, fmt.Sprintf("PNG chart written to %s", trimmed))
+	}
+
+	if trimmed := strings.TrimSpace(*vegaPath); trimmed != "" {
+		spec, err := visualize.VegaLite(years, series, totals, metricValue, scopeParts, theme.Palette)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(trimmed, append(spec, '\n'), 0o644); err != nil {
+			return fmt.Errorf("write vega: %w", err)
+		}
+		if len(footer) > 0 {
+			footer = append(footer, "")
+		}
+		footer = append(footer, fmt.Sprintf("Vega-Lite spec written to %s", trimmed))
+	}
+
 	rpt := report{
 		Lines:    lines,
 		Footer:   footer,
@@ -613,14 +2014,46 @@ func (a *App) runTrend(args []string) error {
 		Rows:     rows,
 	}
 
-	return renderReport(a.Stdout, format, rpt)
+	return a.render(output, rpt)
 }
 
 func (a *App) printUsage() {
 	fmt.Fprintln(a.Stdout, "Usage:")
-	fmt.Fprintln(a.Stdout, "  names [flags]           # Show top names for a state (default command)")
+	fmt.Fprintln(a.Stdout, "  names [top] [flags]     # Show top names for a state (default command)")
 	fmt.Fprintln(a.Stdout, "  names generate [flags]  # Generate a random name using popularity weights")
 	fmt.Fprintln(a.Stdout, "  names trend [flags]     # Show popularity trend over time")
+	fmt.Fprintln(a.Stdout, "  names export ranks      # Export every name's rank for every year")
+	fmt.Fprintln(a.Stdout, "  names check [flags]     # Check names against a roster for collisions")
+	fmt.Fprintln(a.Stdout, "  names search [flags]    # Find names by wildcard or regex pattern")
+	fmt.Fprintln(a.Stdout, "  names serve [flags]     # Serve the query commands as an HTTP JSON API")
+	fmt.Fprintln(a.Stdout, "  names compare [flags]   # Compare names head to head for one year")
+	fmt.Fprintln(a.Stdout, "  names neutral [flags]   # List the most popular gender-neutral names")
+	fmt.Fprintln(a.Stdout, "  names unisex [name]     # Rank names by unisex index, or trace one name's over time")
+	fmt.Fprintln(a.Stdout, "  names gender <name>     # Split a name's births by gender for every year")
+	fmt.Fprintln(a.Stdout, "  names states <name>     # Rank states by a name's share of births")
+	fmt.Fprintln(a.Stdout, "  names clusters          # Group states with similar naming preferences")
+	fmt.Fprintln(a.Stdout, "  names divergence        # Rank states by how unusual their names are nationally")
+	fmt.Fprintln(a.Stdout, "  names distinctive       # List names far more common in a state than nationally")
+	fmt.Fprintln(a.Stdout, "  names peak <name>       # Report a name's peak year and decline since")
+	fmt.Fprintln(a.Stdout, "  names history <name>    # Show a name's count, rank, and share for every year")
+	fmt.Fprintln(a.Stdout, "  names similar <name>    # Find names whose popularity rose and fell alike")
+	fmt.Fprintln(a.Stdout, "  names rank <names>      # Report the rank of many names at once (--stdin for a roster)")
+	fmt.Fprintln(a.Stdout, "  names movers [flags]    # List the biggest rank gainers and losers between periods")
+	fmt.Fprintln(a.Stdout, "  names age <name>        # Estimate the likely birth years for a name")
+	fmt.Fprintln(a.Stdout, "  names alive <name>      # Estimate how many people with a name are alive")
+	fmt.Fprintln(a.Stdout, "  names heatmap <name>    # Chart a name's share of births by state and year")
+	fmt.Fprintln(a.Stdout, "  names lifecycle         # Find extinct names and names that made a comeback")
+	fmt.Fprintln(a.Stdout, "  names debuts [flags]    # List names appearing for the first time in a year")
+	fmt.Fprintln(a.Stdout, "  names anomalies         # List names whose count spiked against their recent baseline")
+	fmt.Fprintln(a.Stdout, "  names letters [flags]   # Share of births by initial letter each year")
+	fmt.Fprintln(a.Stdout, "  names endings [flags]   # Share of births by final letters each year")
+	fmt.Fprintln(a.Stdout, "  names lengths [flags]   # Mean, median, and histogram of name lengths")
+	fmt.Fprintln(a.Stdout, "  names concentration     # Share of births captured by the top names each year")
+	fmt.Fprintln(a.Stdout, "  names diversity         # Entropy, Gini, and effective number of names each year")
+	fmt.Fprintln(a.Stdout, "  names config [command]  # Print the effective config file defaults")
+	fmt.Fprintln(a.Stdout, "  names update-data       # Download the latest SSA state, --national, or --territories files (--check for staleness)")
+	fmt.Fprintln(a.Stdout, "  names validate          # Check every line of the dataset for format problems")
+	fmt.Fprintln(a.Stdout, "  names stats             # Summarize the years, states, and records in the dataset")
 	fmt.Fprintln(a.Stdout)
 	fmt.Fprintln(a.Stdout, "Run 'names -h' or 'names trend -h' for detailed flag information.")
 }
@@ -1,15 +1,33 @@
 package cli_test
 
 import (
+	"archive/zip"
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image/png"
+	"io"
+	"io/fs"
+	"math"
 	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 
+	"github.com/curtiscovington/ssa-names/data/namesbystate"
+	"github.com/curtiscovington/ssa-names/data/namesnational"
+	"github.com/curtiscovington/ssa-names/internal/cache"
 	"github.com/curtiscovington/ssa-names/internal/cli"
+	"github.com/curtiscovington/ssa-names/internal/metrics"
 	"github.com/curtiscovington/ssa-names/internal/namesdata"
 )
 
@@ -34,11 +52,45 @@ func sampleFS() fstest.MapFS {
 }
 
 type jsonOutput struct {
-	Metadata map[string]string   `json:"metadata"`
-	Headers  []string            `json:"headers"`
-	Lines    []string            `json:"lines"`
-	Rows     []map[string]string `json:"rows"`
-	Footer   []string            `json:"footer"`
+	SchemaVersion int               `json:"schema_version"`
+	Metadata      map[string]string `json:"metadata"`
+	Headers       []string          `json:"headers"`
+	Lines         []string          `json:"lines"`
+	Rows          []jsonRow         `json:"rows"`
+	Footer        []string          `json:"footer"`
+}
+
+// jsonRow decodes a typed JSON row back into strings so assertions can compare
+// cells uniformly: numbers use their shortest form and null becomes "-".
+type jsonRow map[string]string
+
+func (r *jsonRow) UnmarshalJSON(data []byte) error {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	row := make(jsonRow, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case nil:
+			row[key] = "-"
+		case float64:
+			row[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case string:
+			row[key] = v
+		default:
+			row[key] = fmt.Sprint(v)
+		}
+	}
+	*r = row
+	return nil
+}
+
+// cellNear reports whether the cell under key in row is a number within
+// 1e-9 of want.
+func cellNear(row jsonRow, key string, want float64) bool {
+	got, err := strconv.ParseFloat(row[key], 64)
+	return err == nil && math.Abs(got-want) < 1e-9
 }
 
 func TestAppTopJSON(t *testing.T) {
@@ -74,6 +126,77 @@ func TestAppTopJSON(t *testing.T) {
 	}
 }
 
+func TestAppTopPagination(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"--state", "CA", "--year", "2019", "--format", "json", "--page", "2", "--page-size", "3"}); err != nil {
+		t.Fatalf("Run top --page: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 1 || payload.Rows[0]["Rank"] != "4" || payload.Rows[0]["Name"] != "Noah" {
+		t.Fatalf("expected only rank 4 on page 2, got %+v", payload.Rows)
+	}
+	if payload.Metadata["page"] != "2" || payload.Metadata["pages"] != "2" || payload.Metadata["offset"] != "3" || payload.Metadata["total_names"] != "4" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"--state", "CA", "--year", "2019", "--offset", "1", "--top", "1"}); err != nil {
+		t.Fatalf("Run top --offset: %v", err)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "Names ranked 2-2 in CA for 2019:") || !strings.Contains(out, "Ranks 2-2 of 4.") {
+		t.Fatalf("expected ranks 2-2, got:\n%s", out)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"--state", "CA", "--year", "2019", "--offset", "10"}); err != nil {
+		t.Fatalf("Run top past the end: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "No names past rank 4; 4 names match.") {
+		t.Fatalf("expected a past-the-end note, got:\n%s", stdout.String())
+	}
+
+	// Huge pages and limits list nothing rather than overflowing.
+	for _, args := range [][]string{
+		{"--page", "999999999999999999", "--page-size", "10"},
+		{"--offset", "5", "--top", "9223372036854775807"},
+	} {
+		stdout.Reset()
+		if err := app.Run(append([]string{"--state", "CA", "--year", "2019"}, args...)); err != nil {
+			t.Fatalf("Run top %v: %v", args, err)
+		}
+		if !strings.Contains(stdout.String(), "No names past rank 4; 4 names match.") {
+			t.Fatalf("expected a past-the-end note for %v, got:\n%s", args, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"--state", "CA", "--year", "2019", "--offset", "1", "--top", "9223372036854775807", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run top with a huge --top: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "4,Noah,70") {
+		t.Fatalf("expected the rest of the ranking, got:\n%s", stdout.String())
+	}
+
+	for _, args := range [][]string{
+		{"--offset", "1", "--page", "2"},
+		{"--page-size", "5"},
+		{"--offset", "-1"},
+		{"--by", "decade", "--page", "2"},
+	} {
+		if err := app.Run(args); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
 func TestAppTopNationalYearRangeJSON(t *testing.T) {
 	fs := sampleFS()
 	stdout := &bytes.Buffer{}
@@ -153,25 +276,52 @@ func TestAppTopNoResultsJSON(t *testing.T) {
 	stderr := &bytes.Buffer{}
 	app := cli.NewApp(fs, stdout, stderr)
 
-	if err := app.Run([]string{"--state", "CA", "--year", "2001", "--format", "json"}); err != nil {
-		t.Fatalf("Run top no results: %v", err)
+	err := app.Run([]string{"--state", "CA", "--year", "2001", "--format", "json"})
+	if code := app.HandleError(err); code != cli.ExitNoMatches {
+		t.Fatalf("expected exit code %d for no results, got %d (%v)", cli.ExitNoMatches, code, err)
 	}
 
-	var payload jsonOutput
+	var payload struct {
+		Error struct {
+			Code     string `json:"code"`
+			ExitCode int    `json:"exit_code"`
+		} `json:"error"`
+	}
 	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
 		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
 	}
-
-	if len(payload.Rows) != 0 {
-		t.Fatalf("expected zero rows, got %d", len(payload.Rows))
+	if payload.Error.Code != "no_matches" || payload.Error.ExitCode != cli.ExitNoMatches {
+		t.Fatalf("unexpected error payload: %+v", payload.Error)
 	}
+}
+
+func TestAppNoMatchExitCodes(t *testing.T) {
+	fs := sampleFS()
+	app := cli.NewApp(fs, &bytes.Buffer{}, &bytes.Buffer{})
 
-	if len(payload.Lines) == 0 || payload.Lines[0] != "No matching names found." {
-		t.Fatalf("expected message about no results, got %v", payload.Lines)
+	for _, args := range [][]string{
+		{"top", "--state", "CA", "--year", "1800"},
+		{"generate", "--state", "CA", "--year", "1800"},
+		{"generate", "--state", "CA", "--year", "1800", "--unique", "--count", "2"},
+		{"search", "--pattern", "Zzzzq*", "--format", "json"},
+		{"neutral", "--state", "CA"},
+		{"unisex", "--state", "CA", "--min-count", "100000"},
+		{"top", "--by", "decade", "--year", "2019", "--gender", "X"},
+		{"top", "--by", "state", "--year", "2019", "--gender", "X"},
+	} {
+		err := app.Run(args)
+		if !errors.Is(err, namesdata.ErrNoMatches) || cli.ExitCode(err) != cli.ExitNoMatches {
+			t.Fatalf("%v: expected ErrNoMatches with exit code %d, got %v (exit %d)", args, cli.ExitNoMatches, err, cli.ExitCode(err))
+		}
 	}
 
-	if stderr.Len() != 0 {
-		t.Fatalf("expected no stderr output, got %q", stderr.String())
+	err := app.Run([]string{"trend", "--state", "CA", "--names", "Olivia,Emmma"})
+	var notFound *namesdata.NameNotFoundError
+	if !errors.As(err, &notFound) || cli.ExitCode(err) != cli.ExitNotFound {
+		t.Fatalf("expected a NameNotFoundError with exit code %d, got %v (exit %d)", cli.ExitNotFound, err, cli.ExitCode(err))
+	}
+	if notFound.Name != "Emmma" || len(notFound.Suggestions) == 0 || notFound.Suggestions[0] != "Emma" {
+		t.Fatalf("unexpected not-found error: %+v", notFound)
 	}
 }
 
@@ -268,6 +418,3359 @@ func TestAppGenerateMultiple(t *testing.T) {
 	}
 }
 
+func TestAppGenerateUnique(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"generate", "--state", "CA", "--year", "2019", "--format", "json", "--seed", "9", "--count", "4", "--unique"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run generate unique: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["unique"] != "true" || len(payload.Rows) != 4 {
+		t.Fatalf("unexpected output: %+v", payload)
+	}
+	seen := make(map[string]bool)
+	for _, row := range payload.Rows {
+		if seen[row["Name"]] {
+			t.Fatalf("duplicate name in unique draw: %+v", payload.Rows)
+		}
+		seen[row["Name"]] = true
+	}
+
+	args[len(args)-2] = "5"
+	if err := app.Run(args); err == nil {
+		t.Fatalf("expected error when --count exceeds the distinct names available")
+	}
+}
+
+func TestAppGeneratePair(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--pair", "--middle-year", "2018", "--count", "5", "--seed", "3", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run generate --pair: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["pair"] != "true" || payload.Metadata["middle_year"] != "2018" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+	if len(payload.Rows) != 5 {
+		t.Fatalf("expected 5 pairs, got %+v", payload.Rows)
+	}
+	for _, row := range payload.Rows {
+		if row["First"] == row["Middle"] || row["Name"] != row["First"]+" "+row["Middle"] {
+			t.Fatalf("unexpected pair row: %+v", row)
+		}
+	}
+
+	if err := app.Run([]string{"generate", "--middle-year", "2018"}); err == nil {
+		t.Fatalf("expected error for --middle-year without --pair")
+	}
+}
+
+func TestAppGenerateConstraints(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--exclude", "olivia", "--max-length", "4", "--count", "3", "--seed", "5", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run generate constraints: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 3 {
+		t.Fatalf("expected 3 picks, got %+v", payload.Rows)
+	}
+	for _, row := range payload.Rows {
+		if row["Name"] != "Emma" || row["Chance"] != "1" {
+			t.Fatalf("expected only Emma, with all of the remaining weight, got %+v", row)
+		}
+	}
+	if payload.Metadata["constraints"] != "at most 4 letters, excluding olivia" || payload.Metadata["eligible_names"] != "1" || payload.Metadata["total_occurrences"] != "90" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+
+	err := app.Run([]string{"generate", "--state", "CA", "--starts-with", "Z"})
+	if !errors.Is(err, namesdata.ErrNoMatches) || !strings.Contains(err.Error(), "no names match the constraints (starting with Z)") {
+		t.Fatalf("expected a no-match error naming the constraints, got %v", err)
+	}
+
+	if err := app.Run([]string{"generate", "--min-length", "6", "--max-length", "4"}); err == nil {
+		t.Fatalf("expected error when --min-length exceeds --max-length")
+	}
+}
+
+func TestAppGenerateTemperature(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--temperature", "0", "--count", "4", "--seed", "2", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run generate --temperature: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["temperature"] != "0" || len(payload.Rows) != 4 {
+		t.Fatalf("unexpected output: %+v", payload)
+	}
+	for _, row := range payload.Rows {
+		if row["Chance"] != "0.5" {
+			t.Fatalf("expected every name equally likely at temperature 0, got %+v", row)
+		}
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--temperature", "2", "--unique", "--count", "2", "--seed", "2"}); err != nil {
+		t.Fatalf("Run generate --temperature --unique: %v", err)
+	}
+	if !strings.Contains(stdout.String(), ", temperature 2") {
+		t.Fatalf("expected the temperature in the title, got:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"generate", "--temperature", "-1"}); err == nil {
+		t.Fatalf("expected error for a negative temperature")
+	}
+	if err := app.Run([]string{"generate", "--synthetic", "--temperature", "0.5"}); err == nil {
+		t.Fatalf("expected error for --temperature with --synthetic")
+	}
+}
+
+func TestAppGenerateExcludeFile(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	path := filepath.Join(t.TempDir(), "used_names.txt")
+	if err := os.WriteFile(path, []byte("# siblings\nOLIVIA\n\nzoe, mia\n"), 0o644); err != nil {
+		t.Fatalf("write exclude file: %v", err)
+	}
+
+	args := []string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--exclude-file", path, "--count", "3", "--seed", "6", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run generate --exclude-file: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	for _, row := range payload.Rows {
+		if row["Name"] != "Emma" {
+			t.Fatalf("expected only Emma once the file's names are excluded, got %+v", row)
+		}
+	}
+	if want := "excluding 3 names from " + path; payload.Metadata["constraints"] != want || payload.Metadata["total_occurrences"] != "90" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+
+	err := app.Run([]string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--exclude", "Emma", "--exclude-file", path})
+	if !errors.Is(err, namesdata.ErrNoMatches) || !strings.Contains(err.Error(), "no names match the constraints (excluding Emma, excluding 3 names from "+path+")") {
+		t.Fatalf("expected a no-match error naming the constraints, got %v", err)
+	}
+
+	if err := app.Run([]string{"generate", "--exclude-file", filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Fatalf("expected error for a missing --exclude-file")
+	}
+}
+
+func TestAppGenerateSkipTop(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--skip-top", "1", "--count", "3", "--seed", "4", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run generate --skip-top: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	for _, row := range payload.Rows {
+		if row["Name"] != "Emma" || row["Chance"] != "1" {
+			t.Fatalf("expected only Emma once Olivia is skipped, got %+v", row)
+		}
+	}
+	if payload.Metadata["skip_top"] != "1" || payload.Metadata["eligible_names"] != "1" || payload.Metadata["total_occurrences"] != "90" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+
+	err := app.Run([]string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--skip-top", "2"})
+	if !errors.Is(err, namesdata.ErrNoMatches) || !strings.Contains(err.Error(), "no names remain outside the top 2") {
+		t.Fatalf("expected a no-match error, got %v", err)
+	}
+
+	if err := app.Run([]string{"generate", "--skip-top", "-1"}); err == nil {
+		t.Fatalf("expected error for a negative --skip-top")
+	}
+}
+
+func TestAppGenerateSecure(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	for _, extra := range [][]string{nil, {"--unique"}, {"--pair"}} {
+		stdout.Reset()
+		args := append([]string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--secure", "--count", "2", "--format", "json"}, extra...)
+		if err := app.Run(args); err != nil {
+			t.Fatalf("Run generate --secure %v: %v", extra, err)
+		}
+
+		var payload jsonOutput
+		if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+			t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+		}
+		if payload.Metadata["secure"] != "true" || len(payload.Rows) != 2 {
+			t.Fatalf("unexpected output for %v: %+v", extra, payload)
+		}
+		if seed, ok := payload.Metadata["seed"]; ok {
+			t.Fatalf("expected no seed for a secure run, got %s", seed)
+		}
+	}
+
+	if err := app.Run([]string{"generate", "--secure", "--seed", "5"}); err == nil {
+		t.Fatalf("expected error for --secure with --seed")
+	}
+}
+
+func TestAppGenerateYearRangeRecency(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"generate", "--state", "CA", "--year", "2018-2019", "--gender", "F", "--recency", "linear", "--format", "json", "--seed", "7"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run generate range: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+
+	if payload.Metadata["year"] != "2018-2019" || payload.Metadata["recency"] != "linear" {
+		t.Fatalf("unexpected metadata: %+v", payload.Metadata)
+	}
+
+	// Totals and counts are real births; only the chances are weighted,
+	// with 2018 at 1/2: Olivia 140 + 40 = 180, Emma 90 + 25 = 115.
+	if payload.Metadata["total_occurrences"] != "360" {
+		t.Fatalf("expected 360 births, got %s", payload.Metadata["total_occurrences"])
+	}
+	switch payload.Metadata["generated_name"] + " " + payload.Metadata["generated_count"] + " " + payload.Metadata["chance"] {
+	case "Olivia 220 0.610169", "Emma 140 0.389831":
+	default:
+		t.Fatalf("expected real counts with weighted chances, got %+v", payload.Metadata)
+	}
+
+	if err := app.Run([]string{"generate", "--recency", "linear"}); err == nil {
+		t.Fatalf("expected error when --recency is used without a year range")
+	}
+}
+
+func TestAppGenerateExponentialRecency(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, io.Discard)
+	args := []string{"generate", "--state", "CA", "--year", "2018-2019", "--gender", "F", "--recency", "exponential", "--half-life", "0.5", "--format", "json", "--seed", "7"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run generate --recency exponential: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// Two half-lives back, 2018 counts weigh 1/4: Olivia 140 + 20 = 160,
+	// Emma 90 + 12.5 = 102.5, while the counts stay real births.
+	if payload.Metadata["recency"] != "exponential" || payload.Metadata["half_life"] != "0.5" || payload.Metadata["total_occurrences"] != "360" ||
+		payload.Metadata["generated_name"] != "Emma" || payload.Metadata["generated_count"] != "140" || payload.Metadata["chance"] != "0.390476" {
+		t.Fatalf("unexpected metadata: %+v", payload.Metadata)
+	}
+
+	if err := app.Run([]string{"generate", "--year", "2018-2019", "--recency", "exponential", "--half-life", "0"}); err == nil {
+		t.Fatal("expected an error for a zero half-life")
+	}
+}
+
+func TestAppGlobalSeed(t *testing.T) {
+	fs := sampleFS()
+
+	run := func(args ...string) jsonOutput {
+		t.Helper()
+		stdout := &bytes.Buffer{}
+		app := cli.NewApp(fs, stdout, &bytes.Buffer{})
+		if err := app.Run(args); err != nil {
+			t.Fatalf("Run %v: %v", args, err)
+		}
+		var payload jsonOutput
+		if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+			t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+		}
+		return payload
+	}
+
+	first := run("--seed", "42", "generate", "--state", "CA", "--count", "5", "--format", "json")
+	second := run("generate", "--seed=42", "--state", "CA", "--count", "5", "--format", "json")
+	if first.Metadata["seed"] != "42" || second.Metadata["seed"] != "42" {
+		t.Fatalf("expected seed metadata 42, got %q and %q", first.Metadata["seed"], second.Metadata["seed"])
+	}
+	for i := range first.Rows {
+		if first.Rows[i]["Name"] != second.Rows[i]["Name"] {
+			t.Fatalf("row %d differs between seeded runs: %+v vs %+v", i, first.Rows[i], second.Rows[i])
+		}
+	}
+
+	unseeded := run("generate", "--state", "CA", "--format", "json")
+	if unseeded.Metadata["seed"] == "" {
+		t.Fatalf("expected generated seed to be recorded in metadata")
+	}
+
+	top := run("--seed", "5", "--state", "CA", "--format", "json")
+	if top.Metadata["seed"] != "5" {
+		t.Fatalf("expected seed metadata on top output, got %q", top.Metadata["seed"])
+	}
+}
+
+func TestAppExportSQLite(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	path := filepath.Join(t.TempDir(), "ca.db")
+	if err := app.Run([]string{"export", "sqlite", "--out", path, "--state", "CA", "--year", "2019", "--format", "json"}); err != nil {
+		t.Fatalf("Run export sqlite: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	counts := make(map[string]string)
+	for _, row := range payload.Rows {
+		counts[row["Table"]] = row["Rows"]
+	}
+	if counts["records"] != "5" || counts["yearly_ranks"] != "4" || counts["state_totals"] != "2" {
+		t.Fatalf("unexpected table counts: %+v", counts)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Fatalf("expected database at %s: %v", path, err)
+	}
+
+	if err := app.Run([]string{"export", "sqlite", "--out", path, "--year", "1900"}); err == nil {
+		t.Fatalf("expected error when no records match")
+	}
+}
+
+func TestAppExportRanksLong(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"export", "ranks", "--gender", "F", "--min-count", "100", "--layout", "long", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run export ranks: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+
+	if len(payload.Rows) != 4 {
+		t.Fatalf("expected 4 long rows, got %d: %+v", len(payload.Rows), payload.Rows)
+	}
+
+	first := payload.Rows[0]
+	if first["Name"] != "Olivia" || first["Year"] != "2018" || first["Rank"] != "2" || first["Count"] != "80" {
+		t.Fatalf("unexpected first row: %+v", first)
+	}
+
+	if err := app.Run([]string{"export", "ranks", "--layout", "diagonal"}); err == nil {
+		t.Fatalf("expected error for unsupported layout")
+	}
+}
+
+func TestAppTrendAutoTop(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"trend", "--auto-top", "2", "--from", "2019", "--format", "json", "--width", "10"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run trend auto-top: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+
+	// 2019 national totals: Olivia 200, Liam 160.
+	if payload.Metadata["names"] != "Olivia, Liam" {
+		t.Fatalf("unexpected auto-selected names: %q", payload.Metadata["names"])
+	}
+	if len(payload.Rows) != 1 || payload.Rows[0]["Year"] != "2019" {
+		t.Fatalf("expected a single 2019 row, got %+v", payload.Rows)
+	}
+	if len(payload.Footer) == 0 || !strings.HasPrefix(payload.Footer[0], "Plot (metric=rank)") {
+		t.Fatalf("expected auto-top to plot by default, got %v", payload.Footer)
+	}
+
+	if err := app.Run([]string{"trend", "--auto-top", "2", "--name", "Emma"}); err == nil {
+		t.Fatalf("expected error when combining --auto-top with -name")
+	}
+}
+
+func TestAppTrendYearRange(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	for _, args := range [][]string{
+		{"trend", "--name", "Olivia", "--state", "CA", "--since", "2019", "--format", "json"},
+		{"trend", "--name", "Olivia", "--state", "CA", "--year", "2019-2030", "--format", "json"},
+	} {
+		stdout.Reset()
+		if err := app.Run(args); err != nil {
+			t.Fatalf("Run %v: %v", args, err)
+		}
+
+		var payload jsonOutput
+		if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+			t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+		}
+		if len(payload.Rows) != 1 || payload.Rows[0]["Year"] != "2019" || payload.Rows[0]["Olivia Count"] != "140" {
+			t.Fatalf("expected only the 2019 row for %v, got %+v", args, payload.Rows)
+		}
+		if payload.Metadata["year"] != "2019" || payload.Lines[0] != "Trend for Olivia (CA, 2019):" {
+			t.Fatalf("unexpected year scope for %v: %v %q", args, payload.Metadata, payload.Lines[0])
+		}
+	}
+
+	if err := app.Run([]string{"trend", "--name", "Olivia", "--year", "2018,2020"}); err == nil {
+		t.Fatalf("expected error for a non-contiguous --year")
+	}
+	if err := app.Run([]string{"trend", "--name", "Olivia", "--year", "2019", "--until", "2020"}); err == nil {
+		t.Fatalf("expected error when combining --year with --until")
+	}
+}
+
+func TestAppTrendSplitGender(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"trend", "--name", "Olivia", "--state", "CA", "--split-gender", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run trend split-gender: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+
+	expectedHeaders := []string{"Year", "Olivia (F) Rank", "Olivia (F) Count", "Olivia (M) Rank", "Olivia (M) Count"}
+	if strings.Join(payload.Headers, "|") != strings.Join(expectedHeaders, "|") {
+		t.Fatalf("unexpected headers: %v", payload.Headers)
+	}
+	row := payload.Rows[1]
+	if row["Olivia (F) Count"] != "140" || row["Olivia (M) Count"] != "-" {
+		t.Fatalf("unexpected 2019 row: %+v", row)
+	}
+	if payload.Metadata["split_gender"] != "true" {
+		t.Fatalf("expected split_gender metadata, got %+v", payload.Metadata)
+	}
+
+	if err := app.Run([]string{"trend", "--name", "Olivia", "--split-gender", "--gender", "F"}); err == nil {
+		t.Fatalf("expected error when combining --split-gender with -gender")
+	}
+}
+
+func TestAppTrendVolatility(t *testing.T) {
+	fsys := fstest.MapFS{
+		"TX.TXT": {Data: []byte(
+			"TX,F,2016,Ava,100\nTX,F,2016,Bea,50\nTX,F,2016,Cat,40\n" +
+				"TX,F,2017,Ava,100\nTX,F,2017,Cat,50\nTX,F,2017,Bea,40\n" +
+				"TX,F,2018,Ava,100\nTX,F,2018,Bea,50\nTX,F,2018,Cat,40\n" +
+				"TX,F,2019,Ava,100\nTX,F,2019,Cat,50\nTX,F,2019,Bea,40\n",
+		)},
+	}
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(fsys, stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"trend", "-names", "Ava,Bea", "--state", "TX", "--metric", "volatility", "--format", "json"}); err != nil {
+		t.Fatalf("Run trend --metric volatility: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	rows := payload.Rows
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+	if rows[0]["Bea Volatility"] != "-" || rows[2]["Bea Volatility"] != "1" || rows[3]["Ava Volatility"] != "0" {
+		t.Fatalf("unexpected volatility columns: %v", rows)
+	}
+	footer := strings.Join(payload.Footer, "\n")
+	for _, want := range []string{"Ava: overall rank volatility 0.00", "Bea: overall rank volatility 0.94"} {
+		if !strings.Contains(footer, want) {
+			t.Fatalf("expected footer to contain %q, got:\n%s", want, footer)
+		}
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"trend", "-name", "Bea", "--state", "TX", "--metric", "volatility", "--plot", "--width", "4", "--height", "3"}); err != nil {
+		t.Fatalf("Run trend volatility plot: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Plot (metric=volatility)") {
+		t.Fatalf("expected a volatility plot, got:\n%s", stdout.String())
+	}
+}
+
+func TestAppTrendForecast(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	svgPath := filepath.Join(t.TempDir(), "olivia.svg")
+	args := []string{"trend", "--name", "Olivia", "--state", "CA", "--forecast", "2", "--svg", svgPath, "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run trend forecast: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 4 || payload.Headers[len(payload.Headers)-1] != "Forecast" {
+		t.Fatalf("expected two observed and two forecast rows, got %v %+v", payload.Headers, payload.Rows)
+	}
+	if row := payload.Rows[1]; row["Year"] != "2019" || row["Forecast"] != "false" {
+		t.Fatalf("unexpected observed row: %+v", row)
+	}
+	if row := payload.Rows[2]; row["Year"] != "2020" || row["Olivia Count"] != "200" || row["Olivia Rank"] != "1" || row["Forecast"] != "true" {
+		t.Fatalf("unexpected forecast row: %+v", row)
+	}
+	if payload.Metadata["forecast"] != "2" || payload.Metadata["forecast_model"] != "linear" {
+		t.Fatalf("unexpected forecast metadata: %+v", payload.Metadata)
+	}
+
+	svg, err := os.ReadFile(svgPath)
+	if err != nil {
+		t.Fatalf("read svg: %v", err)
+	}
+	if !strings.Contains(string(svg), "stroke-dasharray") || !strings.Contains(string(svg), ">Forecast</text>") {
+		t.Fatalf("expected a dashed forecast line in the svg")
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"trend", "--name", "Olivia", "--state", "CA", "--forecast", "1", "--forecast-model", "holt", "--plot"}); err != nil {
+		t.Fatalf("Run trend holt forecast: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Legend: █ Olivia, · forecast") || !strings.Contains(stdout.String(), "Holt exponential smoothing") {
+		t.Fatalf("expected forecast legend and model note, got:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"trend", "--name", "Olivia", "--forecast", "2", "--forecast-model", "arima"}); err == nil {
+		t.Fatalf("expected error for an unknown forecast model")
+	}
+}
+
+func TestAppTrendChangepoints(t *testing.T) {
+	var data strings.Builder
+	for year := 2010; year <= 2017; year++ {
+		fmt.Fprintf(&data, "TX,F,%d,Ava,100\n", year)
+		if year >= 2014 {
+			fmt.Fprintf(&data, "TX,F,%d,Zed,50\n", year)
+		}
+	}
+	fsys := fstest.MapFS{"TX.TXT": {Data: []byte(data.String())}}
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(fsys, stdout, &bytes.Buffer{})
+
+	svgPath := filepath.Join(t.TempDir(), "zed.svg")
+	if err := app.Run([]string{"trend", "-names", "Ava,Zed", "--state", "TX", "--changepoints", "--svg", svgPath, "--format", "json"}); err != nil {
+		t.Fatalf("Run trend --changepoints: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Headers[len(payload.Headers)-1] != "Changepoints" {
+		t.Fatalf("expected a Changepoints column, got %v", payload.Headers)
+	}
+	if row := payload.Rows[4]; row["Year"] != "2014" || row["Changepoints"] != "Zed ↑" {
+		t.Fatalf("unexpected 2014 row: %+v", row)
+	}
+	if row := payload.Rows[3]; row["Changepoints"] != "-" {
+		t.Fatalf("expected no changepoint in 2013, got %+v", row)
+	}
+	if payload.Metadata["changepoints"] != "1" {
+		t.Fatalf("unexpected changepoints metadata: %+v", payload.Metadata)
+	}
+	footer := strings.Join(payload.Footer, "\n")
+	for _, want := range []string{"Ava: no changepoints", "Zed: share rose from 0.0000% to 33.3333% in 2014"} {
+		if !strings.Contains(footer, want) {
+			t.Fatalf("expected footer to contain %q, got:\n%s", want, footer)
+		}
+	}
+
+	svg, err := os.ReadFile(svgPath)
+	if err != nil {
+		t.Fatalf("read svg: %v", err)
+	}
+	if !strings.Contains(string(svg), `class="peak"`) || !strings.Contains(string(svg), ">2014</text>") {
+		t.Fatalf("expected a labeled changepoint marker in the svg")
+	}
+}
+
+func TestAppTrendSmooth(t *testing.T) {
+	fsys := fstest.MapFS{
+		"TX.TXT": {Data: []byte(
+			"TX,F,2016,Ava,100\nTX,F,2017,Ava,400\nTX,F,2018,Ava,100\nTX,F,2019,Ava,400\n",
+		)},
+	}
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(fsys, stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"trend", "-name", "Ava", "--state", "TX", "--smooth", "3", "--format", "json"}); err != nil {
+		t.Fatalf("Run trend --smooth: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	counts := make([]string, len(payload.Rows))
+	for i, row := range payload.Rows {
+		counts[i] = row["Ava Count"]
+	}
+	if strings.Join(counts, ",") != "250,200,300,250" {
+		t.Fatalf("unexpected smoothed counts: %v", counts)
+	}
+	if payload.Metadata["smooth"] != "3" {
+		t.Fatalf("expected smooth metadata, got %+v", payload.Metadata)
+	}
+
+	for _, window := range []string{"2", "-1"} {
+		if err := app.Run([]string{"trend", "-name", "Ava", "--state", "TX", "--smooth", window}); err == nil {
+			t.Fatalf("expected an error for --smooth %s", window)
+		}
+	}
+}
+
+func TestAppTrendVega(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	specPath := filepath.Join(t.TempDir(), "trend.vl.json")
+	args := []string{"trend", "--names", "Olivia,Emma", "--state", "CA", "--gender", "F", "--vega", specPath}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run trend vega: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Vega-Lite spec written to "+specPath) {
+		t.Fatalf("expected vega footer, got:\n%s", stdout.String())
+	}
+
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		t.Fatalf("read spec: %v", err)
+	}
+	var spec struct {
+		Schema string `json:"$schema"`
+		Data   struct {
+			Values []struct {
+				Year   int      `json:"year"`
+				Series string   `json:"series"`
+				Value  *float64 `json:"value"`
+				Count  *int     `json:"count"`
+			} `json:"values"`
+		} `json:"data"`
+		Encoding struct {
+			Y struct {
+				Field string `json:"field"`
+				Scale struct {
+					Reverse bool `json:"reverse"`
+				} `json:"scale"`
+			} `json:"y"`
+			Color struct {
+				Field string `json:"field"`
+			} `json:"color"`
+		} `json:"encoding"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("unmarshal spec: %v", err)
+	}
+	if !strings.Contains(spec.Schema, "vega-lite/v5") {
+		t.Fatalf("unexpected schema %q", spec.Schema)
+	}
+	if len(spec.Data.Values) != 4 {
+		t.Fatalf("expected 4 data points, got %d", len(spec.Data.Values))
+	}
+	first := spec.Data.Values[1]
+	if first.Series != "Olivia" || first.Year != 2019 || first.Value == nil || *first.Value != 1 || first.Count == nil || *first.Count != 140 {
+		t.Fatalf("unexpected Olivia 2019 point: %+v", first)
+	}
+	if y := spec.Encoding.Y; y.Field != "value" || !y.Scale.Reverse {
+		t.Fatalf("expected a reversed rank axis, got %+v", y)
+	}
+	if spec.Encoding.Color.Field != "series" {
+		t.Fatalf("expected series colors, got %+v", spec.Encoding.Color)
+	}
+}
+
+func TestAppTrendPNG(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	pngPath := filepath.Join(t.TempDir(), "olivia.png")
+	args := []string{"trend", "--name", "Olivia", "--state", "CA", "--png", pngPath, "--png-width", "480", "--png-height", "300", "--png-scale", "2"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run trend png: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "PNG chart written to "+pngPath) {
+		t.Fatalf("expected png footer, got:\n%s", stdout.String())
+	}
+
+	file, err := os.Open(pngPath)
+	if err != nil {
+		t.Fatalf("open png: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 960 || bounds.Dy() != 600 {
+		t.Fatalf("expected 960x600 image, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	if err := app.Run([]string{"trend", "--name", "Olivia", "--png", pngPath, "--png-scale", "0"}); err == nil {
+		t.Fatalf("expected error for non-positive --png-scale")
+	}
+}
+
+func TestAppCheckRoster(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	rosterPath := filepath.Join(t.TempDir(), "kids.txt")
+	if err := os.WriteFile(rosterPath, []byte("# classroom\nEmma\nOlivya, Noah\n"), 0o644); err != nil {
+		t.Fatalf("write roster: %v", err)
+	}
+
+	args := []string{"check", "--roster", rosterPath, "--names", "Olivia,Liam", "--state", "CA", "--year", "2019", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run check: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+
+	if payload.Metadata["roster_size"] != "3" || payload.Metadata["flagged"] != "1" {
+		t.Fatalf("unexpected metadata: %+v", payload.Metadata)
+	}
+	if len(payload.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(payload.Rows))
+	}
+	if payload.Rows[0]["Name"] != "Olivia" || payload.Rows[0]["Conflicts"] != "Olivya (phonetic)" {
+		t.Fatalf("unexpected Olivia row: %+v", payload.Rows[0])
+	}
+	if payload.Rows[1]["Conflicts"] != "-" {
+		t.Fatalf("expected no conflicts for Liam, got %+v", payload.Rows[1])
+	}
+
+	if err := app.Run([]string{"check"}); err == nil {
+		t.Fatalf("expected error when --roster is missing")
+	}
+}
+
+func TestAppSearch(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"search", "--pattern", "*i*", "--year", "2019", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run search: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+
+	// 2019 national ranks: Olivia 200, Liam 160, Emma 90, Noah 70.
+	if len(payload.Rows) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", payload.Rows)
+	}
+	if payload.Rows[0]["Name"] != "Olivia" || payload.Rows[1]["Name"] != "Liam" || payload.Rows[1]["Rank"] != "2" {
+		t.Fatalf("unexpected matches: %+v", payload.Rows)
+	}
+
+	if err := app.Run([]string{"search", "--pattern", "O*", "--regex", "^O"}); err == nil {
+		t.Fatalf("expected error when combining --pattern and --regex")
+	}
+}
+
+func TestAppServeHandler(t *testing.T) {
+	app := cli.NewApp(sampleFS(), &bytes.Buffer{}, &bytes.Buffer{})
+	server := httptest.NewServer(app.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/rank?name=liam&year=2019&state=CA&top=1")
+	if err != nil {
+		t.Fatalf("GET /rank: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	var payload jsonOutput
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode /rank: %v", err)
+	}
+	if payload.Metadata["queried_name"] != "Liam" || payload.Metadata["queried_rank"] != "2" {
+		t.Fatalf("unexpected rank metadata: %+v", payload.Metadata)
+	}
+
+	for path, want := range map[string]int{
+		"/top?format=csv":                      http.StatusBadRequest,
+		"/trend":                               http.StatusBadRequest,
+		"/trend?name=Olivia&from=1900&to=1901": http.StatusNotFound,
+		"/generate?count=100000000":            http.StatusBadRequest,
+		"/generate?count=10000&seed=1":         http.StatusOK,
+		"/top?top=5000":                        http.StatusBadRequest,
+		"/top?page=1&page-size=100000":         http.StatusBadRequest,
+		"/trend?name=Olivia&forecast=1000000":  http.StatusBadRequest,
+		"/trend?auto-top=100000":               http.StatusBadRequest,
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("GET %s: expected status %d, got %d", path, want, resp.StatusCode)
+		}
+	}
+
+	// A dataset the server cannot read is its own failure, not the client's.
+	broken := httptest.NewServer(cli.NewApp(unreadableFS{sampleFS()}, io.Discard, io.Discard).Handler())
+	defer broken.Close()
+	resp, err = http.Get(broken.URL + "/top?state=CA")
+	if err != nil {
+		t.Fatalf("GET /top: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected status 500 for an unreadable dataset, got %d", resp.StatusCode)
+	}
+}
+
+// unreadableFS lists its files but fails to open any of them.
+type unreadableFS struct {
+	fstest.MapFS
+}
+
+func (f unreadableFS) Open(name string) (fs.File, error) {
+	if name == "." {
+		return f.MapFS.Open(name)
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func TestAppServeMetrics(t *testing.T) {
+	app := cli.NewApp(sampleFS(), &bytes.Buffer{}, &bytes.Buffer{})
+	app.Cache = cache.New(8)
+	app.Metrics = metrics.New(app.Cache)
+	server := httptest.NewServer(app.Handler())
+	defer server.Close()
+
+	for _, path := range []string{
+		"/top?state=CA&year=2019",
+		"/top?state=CA&year=2019",
+		"/top?format=csv",
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read /metrics: %v", err)
+	}
+	for _, want := range []string{
+		`names_http_requests_total{code="200",endpoint="/top"} 2`,
+		`names_http_requests_total{code="400",endpoint="/top"} 1`,
+		`names_http_request_duration_seconds_count{endpoint="/top"} 3`,
+		"names_dataset_load_duration_seconds_count 1",
+		"names_cache_hits_total 1",
+		"names_cache_misses_total 1",
+		"names_cache_capacity 8",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("expected %q in /metrics output:\n%s", want, body)
+		}
+	}
+}
+
+func TestAppServeOpenAPI(t *testing.T) {
+	app := cli.NewApp(sampleFS(), &bytes.Buffer{}, &bytes.Buffer{})
+	server := httptest.NewServer(app.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("GET /openapi.json: %v", err)
+	}
+	defer resp.Body.Close()
+	var spec struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]struct {
+			Get struct {
+				Parameters []struct {
+					Name        string         `json:"name"`
+					Description string         `json:"description"`
+					Schema      map[string]any `json:"schema"`
+				} `json:"parameters"`
+				Responses map[string]any `json:"responses"`
+			} `json:"get"`
+		} `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Fatalf("unexpected openapi version %q", spec.OpenAPI)
+	}
+	for _, path := range []string{"/top", "/rank", "/generate", "/trend"} {
+		op, ok := spec.Paths[path]
+		if !ok || len(op.Get.Parameters) == 0 || op.Get.Responses["200"] == nil || op.Get.Responses["404"] == nil {
+			t.Fatalf("expected %s to be described, got %+v", path, op)
+		}
+		for _, param := range op.Get.Parameters {
+			if param.Description == "" || param.Schema["type"] == nil {
+				t.Fatalf("%s: parameter %q lacks a description or type", path, param.Name)
+			}
+		}
+	}
+	if _, ok := spec.Paths["/metrics"]; ok {
+		t.Fatal("expected /metrics to be omitted when metrics are disabled")
+	}
+
+	stdout := &bytes.Buffer{}
+	app = cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"serve", "--openapi"}); err != nil {
+		t.Fatalf("Run serve --openapi: %v", err)
+	}
+	if !json.Valid(stdout.Bytes()) || !strings.Contains(stdout.String(), `"/metrics"`) {
+		t.Fatalf("expected the spec with /metrics on stdout, got:\n%s", stdout.String())
+	}
+}
+
+func TestAppServeGenerateStream(t *testing.T) {
+	app := cli.NewApp(sampleFS(), &bytes.Buffer{}, &bytes.Buffer{})
+	server := httptest.NewServer(app.Handler())
+	defer server.Close()
+
+	stream := func(query string) (int, []string) {
+		t.Helper()
+		resp, err := http.Get(server.URL + "/generate/stream?" + query)
+		if err != nil {
+			t.Fatalf("GET /generate/stream: %v", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		if resp.StatusCode == http.StatusOK && resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+		}
+		return resp.StatusCode, strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	}
+
+	status, events := stream("state=CA&year=2019&gender=F&seed=7&rate=1000&max-count=3")
+	if status != http.StatusOK || len(events) != 5 {
+		t.Fatalf("expected metadata, 3 names, and done, got %d: %q", status, events)
+	}
+	if !strings.HasPrefix(events[0], "event: metadata\ndata: ") || !strings.Contains(events[0], `"seed":"7"`) {
+		t.Fatalf("unexpected metadata event: %q", events[0])
+	}
+	for i, event := range events[1:4] {
+		prefix := fmt.Sprintf("id: %d\nevent: name\ndata: ", i+1)
+		data, ok := strings.CutPrefix(event, prefix)
+		if !ok {
+			t.Fatalf("unexpected name event: %q", event)
+		}
+		var name struct {
+			Pick int    `json:"pick"`
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(data), &name); err != nil || name.Pick != i+1 || (name.Name != "Olivia" && name.Name != "Emma") {
+			t.Fatalf("unexpected name data %q: %v", data, err)
+		}
+	}
+	if events[4] != "event: done\ndata: {\"count\":3}" {
+		t.Fatalf("unexpected done event: %q", events[4])
+	}
+
+	// The same seed replays the same names.
+	_, again := stream("state=CA&year=2019&gender=F&seed=7&rate=1000&max-count=3")
+	if strings.Join(again, "\n") != strings.Join(events, "\n") {
+		t.Fatalf("expected seeded streams to match:\n%q\n%q", events, again)
+	}
+
+	for query, want := range map[string]int{
+		"rate=0":                     http.StatusBadRequest,
+		"max-count=-1":               http.StatusBadRequest,
+		"pair=true":                  http.StatusBadRequest,
+		"year=1900&max-count=1":      http.StatusNotFound,
+		"starts-with=Zz&max-count=1": http.StatusNotFound,
+	} {
+		if status, _ := stream(query); status != want {
+			t.Fatalf("%s: expected status %d, got %d", query, want, status)
+		}
+	}
+}
+
+func TestAppServeShutdownEndsStreams(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	app := cli.NewApp(sampleFS(), &bytes.Buffer{}, &bytes.Buffer{})
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run([]string{"serve", "--addr", addr, "--grpc-addr", ""})
+	}()
+
+	var resp *http.Response
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		resp, err = http.Get("http://" + addr + "/generate/stream?state=CA&rate=1")
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GET /generate/stream: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer resp.Body.Close()
+	if _, err := bufio.NewReader(resp.Body).ReadString('\n'); err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("find process: %v", err)
+	}
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot interrupt the test process: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a clean shutdown with an open stream, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("serve did not shut down while a stream was open")
+	}
+}
+
+func TestAppServeDashboard(t *testing.T) {
+	app := cli.NewApp(sampleFS(), &bytes.Buffer{}, &bytes.Buffer{})
+	server := httptest.NewServer(app.Handler())
+	defer server.Close()
+
+	get := func(path string) (*http.Response, string) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		return resp, string(body)
+	}
+
+	resp, body := get("/")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `<option value="CA">CA</option>`) || !strings.Contains(body, `<option value="NY">NY</option>`) {
+		t.Fatalf("unexpected dashboard page (%d):\n%s", resp.StatusCode, body)
+	}
+	if resp, _ := get("/static/dashboard.js"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the dashboard script to be served, got %d", resp.StatusCode)
+	}
+	if resp, _ := get("/nope"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected unknown paths to 404, got %d", resp.StatusCode)
+	}
+
+	resp, body = get("/trend.svg?names=Olivia,Emma&state=CA&gender=F&metric=count")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/svg+xml" || !strings.Contains(body, "<svg") || !strings.Contains(body, "Olivia") {
+		t.Fatalf("unexpected trend chart (%d, %s):\n%s", resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	}
+	for path, want := range map[string]int{
+		"/trend.svg?state=CA":                   http.StatusBadRequest,
+		"/trend.svg?name=Olivia&metric=peak":    http.StatusBadRequest,
+		"/trend.svg?name=Olivia&width=100000":   http.StatusBadRequest,
+		"/trend.svg?name=Olivia&year=1900-1901": http.StatusNotFound,
+	} {
+		if resp, _ := get(path); resp.StatusCode != want {
+			t.Fatalf("GET %s: expected status %d, got %d", path, want, resp.StatusCode)
+		}
+	}
+}
+
+func TestAppNationalScope(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, stderr)
+	app.National = fstest.MapFS{
+		"yob2019.txt": {Data: []byte("Olivia,F,18451\nEmma,F,17102\nLiam,M,20502\n")},
+	}
+
+	args := []string{"top", "--scope", "national", "--year", "2019", "--gender", "F", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run top national: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["dataset"] != "national" {
+		t.Fatalf("expected national dataset metadata, got %+v", payload.Metadata)
+	}
+	if len(payload.Rows) != 2 || payload.Rows[0]["Name"] != "Olivia" || payload.Rows[0]["Count"] != "18451" {
+		t.Fatalf("unexpected national rows: %+v", payload.Rows)
+	}
+
+	if err := app.Run([]string{"generate", "--scope", "national", "--state", "CA"}); err == nil {
+		t.Fatalf("expected error when combining --scope national with -state")
+	}
+}
+
+func TestAppCompare(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"compare", "Olivia", "--state", "CA", "Emma", "--gender", "F", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run compare: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+
+	// CA 2019 females: Olivia 140 (2018: 80), Emma 90 (2018: 50).
+	if payload.Metadata["year"] != "2019" || len(payload.Rows) != 2 {
+		t.Fatalf("unexpected comparison: %+v", payload)
+	}
+	if row := payload.Rows[0]; row["Name"] != "Olivia" || row["YoY Change"] != "0.75" || row["vs Leader"] != "-" {
+		t.Fatalf("unexpected Olivia row: %+v", row)
+	}
+	if row := payload.Rows[1]; row["Prev Count"] != "50" || !strings.HasPrefix(row["vs Leader"], "-0.3571") {
+		t.Fatalf("unexpected Emma row: %+v", row)
+	}
+	if len(payload.Footer) != 1 || !strings.HasPrefix(payload.Footer[0], "Winner: Olivia") {
+		t.Fatalf("unexpected summary: %v", payload.Footer)
+	}
+
+	if err := app.Run([]string{"compare", "Olivia"}); err == nil {
+		t.Fatalf("expected error when comparing a single name")
+	}
+}
+
+func TestAppNeutral(t *testing.T) {
+	fs := fstest.MapFS{
+		"CA.TXT": {Data: []byte(
+			"CA,F,2019,Riley,45\n" +
+				"CA,M,2019,Riley,55\n" +
+				"CA,F,2019,Avery,80\n" +
+				"CA,M,2019,Avery,20\n" +
+				"CA,F,2019,Emma,300\n"),
+		},
+	}
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"neutral", "--year", "2019", "--min-count", "50", "--format", "json"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run neutral: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 1 {
+		t.Fatalf("expected only Riley within 40-60%%, got %+v", payload.Rows)
+	}
+	if row := payload.Rows[0]; row["Name"] != "Riley" || row["Total"] != "100" || row["Female Share"] != "0.45" {
+		t.Fatalf("unexpected neutral row: %+v", row)
+	}
+
+	if err := app.Run([]string{"neutral", "--balance", "0.7"}); err == nil {
+		t.Fatalf("expected error for --balance above 0.5")
+	}
+}
+
+func TestAppTopMarkdown(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"--state", "CA", "--year", "2019", "--gender", "F", "--format", "markdown"}); err != nil {
+		t.Fatalf("Run top markdown: %v", err)
+	}
+
+	want := "Top 2 names in CA for 2019 (F):\n\n" +
+		"| Rank | Name   | Count |  Share |\n" +
+		"| ---: | ------ | ----: | -----: |\n" +
+		"|    1 | Olivia |   140 | 60.87% |\n" +
+		"|    2 | Emma   |    90 | 39.13% |\n\n" +
+		"> - gender: F\n" +
+		"> - state: CA\n" +
+		"> - year: 2019\n"
+	if got := stdout.String(); got != want {
+		t.Fatalf("unexpected markdown output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAppStates(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"states", "noah", "--gender", "M", "--format", "json"}); err != nil {
+		t.Fatalf("Run states: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 1 || payload.Rows[0]["State"] != "CA" || payload.Rows[0]["Count"] != "70" || payload.Rows[0]["Rank"] != "2" {
+		t.Fatalf("unexpected rows: %+v", payload.Rows)
+	}
+	if payload.Metadata["name"] != "Noah" || payload.Metadata["absent"] != "1" {
+		t.Fatalf("unexpected metadata: %+v", payload.Metadata)
+	}
+	if len(payload.Footer) != 1 || payload.Footer[0] != "Not recorded in: NY" {
+		t.Fatalf("unexpected footer: %v", payload.Footer)
+	}
+
+	mapPath := filepath.Join(t.TempDir(), "noah.svg")
+	stdout.Reset()
+	if err := app.Run([]string{"states", "Noah", "--gender", "M", "--svg", mapPath}); err != nil {
+		t.Fatalf("Run states svg: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "SVG map written to "+mapPath) {
+		t.Fatalf("expected svg footer, got:\n%s", stdout.String())
+	}
+	svg, err := os.ReadFile(mapPath)
+	if err != nil {
+		t.Fatalf("read svg: %v", err)
+	}
+	if !strings.Contains(string(svg), "<title>CA: 28.00% (#2, 70)</title>") || !strings.Contains(string(svg), "<title>NY: not recorded</title>") {
+		t.Fatalf("unexpected svg map:\n%s", svg)
+	}
+
+	if err := app.Run([]string{"states"}); err == nil {
+		t.Fatalf("expected error when no name is given")
+	}
+	if err := app.Run([]string{"states", "Emma", "Olivia"}); err == nil {
+		t.Fatalf("expected error for more than one name")
+	}
+}
+
+func TestAppDatasetFlag(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ZZ.TXT"), []byte("ZZ,F,2020,Ada,50\nZZ,F,2020,Bea,30\n"), 0o644); err != nil {
+		t.Fatalf("write dataset: %v", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, stderr)
+
+	if err := app.Run([]string{"--dataset", dir, "top", "--state", "ZZ", "--format", "json"}); err != nil {
+		t.Fatalf("Run top with --dataset: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 2 || payload.Rows[0]["Name"] != "Ada" {
+		t.Fatalf("unexpected rows: %+v", payload.Rows)
+	}
+
+	// The flag is also accepted after the command and only lasts one run.
+	stdout.Reset()
+	if err := app.Run([]string{"top", "--dataset=" + dir, "--state", "ZZ"}); err != nil {
+		t.Fatalf("Run top with trailing --dataset: %v", err)
+	}
+	if err := app.Run([]string{"top", "--state", "ZZ"}); err == nil {
+		t.Fatalf("expected the embedded dataset to be restored after the run")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "QQ.TXT"), []byte("not a record\n"), 0o644); err != nil {
+		t.Fatalf("write bad file: %v", err)
+	}
+	if err := app.Run([]string{"--dataset", dir, "top"}); err == nil || !strings.Contains(err.Error(), "malformed line in QQ.TXT") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if err := app.Run([]string{"--dataset", filepath.Join(dir, "missing"), "top"}); err == nil {
+		t.Fatalf("expected error for a missing directory")
+	}
+}
+
+func TestAppTopGroupByDecade(t *testing.T) {
+	fs := fstest.MapFS{
+		"CA.TXT": {Data: []byte(
+			"CA,F,1985,Jessica,90\n" +
+				"CA,F,1989,Ashley,60\n" +
+				"CA,F,1989,Jessica,20\n" +
+				"CA,F,1992,Ashley,70\n"),
+		},
+	}
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"top", "--group-by", "decade", "--top", "2", "--format", "json"}); err != nil {
+		t.Fatalf("Run top group-by decade: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	expectedHeaders := []string{"Rank", "1980s Name", "1980s Count", "1990s Name", "1990s Count"}
+	if strings.Join(payload.Headers, "|") != strings.Join(expectedHeaders, "|") {
+		t.Fatalf("unexpected headers: %v", payload.Headers)
+	}
+	if len(payload.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(payload.Rows))
+	}
+	first, second := payload.Rows[0], payload.Rows[1]
+	if first["1980s Name"] != "Jessica" || first["1980s Count"] != "110" || first["1990s Name"] != "Ashley" {
+		t.Fatalf("unexpected first row: %+v", first)
+	}
+	if second["1980s Name"] != "Ashley" || second["1990s Name"] != "-" {
+		t.Fatalf("unexpected second row: %+v", second)
+	}
+	if payload.Metadata["by"] != "decade" || payload.Metadata["decades"] != "2" {
+		t.Fatalf("unexpected metadata: %+v", payload.Metadata)
+	}
+
+	if err := app.Run([]string{"top", "--by", "decade", "--name", "Ashley", "--year", "1989"}); err == nil {
+		t.Fatalf("expected error when combining --by decade with -name")
+	}
+}
+
+func TestAppPeak(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"peak", "emma", "--state", "CA", "--gender", "F", "--format", "json"}); err != nil {
+		t.Fatalf("Run peak: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 1 {
+		t.Fatalf("expected one row, got %+v", payload.Rows)
+	}
+	row := payload.Rows[0]
+	if row["Name"] != "Emma" || row["Peak Year"] != "2019" || row["Peak Count"] != "90" || row["Peak Rank"] != "2" || row["Since Peak"] != "0" {
+		t.Fatalf("unexpected peak row: %+v", row)
+	}
+	if payload.Metadata["latest_year"] != "2019" || payload.Metadata["state"] != "CA" {
+		t.Fatalf("unexpected metadata: %+v", payload.Metadata)
+	}
+	if len(payload.Footer) != 1 || !strings.Contains(payload.Footer[0], "Emma peaked in 2019 at #2") {
+		t.Fatalf("unexpected footer: %v", payload.Footer)
+	}
+
+	if err := app.Run([]string{"peak"}); err == nil {
+		t.Fatalf("expected error when no name is given")
+	}
+	if err := app.Run([]string{"peak", "Zelda"}); err == nil {
+		t.Fatalf("expected error for a missing name")
+	}
+}
+
+func TestAppMovers(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"movers", "--state", "NY", "--format", "json"}); err != nil {
+		t.Fatalf("Run movers: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["from"] != "2018" || payload.Metadata["to"] != "2019" {
+		t.Fatalf("expected the latest two years by default, got %v", payload.Metadata)
+	}
+
+	// NY 2018: Emma 45. NY 2019: Liam 65, Olivia 60.
+	var got []string
+	for _, row := range payload.Rows {
+		got = append(got, row["Movement"]+":"+row["Name"]+":"+row["From Rank"]+":"+row["To Rank"])
+	}
+	want := "New:Liam:-:1|New:Olivia:-:2|Dropped:Emma:1:-"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("unexpected movers:\n got %s\nwant %s", strings.Join(got, "|"), want)
+	}
+
+	if err := app.Run([]string{"movers", "--from", "2019", "--to", "2018"}); err == nil {
+		t.Fatalf("expected error when --from does not precede --to")
+	}
+}
+
+func TestAppLifecycle(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"lifecycle", "--state", "NY", "--gap", "1", "--min-count", "1", "--format", "json"}); err != nil {
+		t.Fatalf("Run lifecycle: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// NY 2018: Emma 45. NY 2019: Liam 65, Olivia 60.
+	if len(payload.Rows) != 1 || payload.Rows[0]["Name"] != "Emma" || payload.Rows[0]["Last Year"] != "2018" || payload.Rows[0]["Years Gone"] != "1" {
+		t.Fatalf("unexpected extinct rows: %+v", payload.Rows)
+	}
+	if payload.Metadata["mode"] != "extinct" || payload.Metadata["latest_year"] != "2019" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"lifecycle", "--mode", "comeback", "--min-count", "1", "--gap", "1", "--format", "json"}); err != nil {
+		t.Fatalf("Run lifecycle comeback: %v", err)
+	}
+	payload = jsonOutput{}
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 0 || payload.Metadata["rank_threshold"] != "100" {
+		t.Fatalf("expected no comebacks in two years of data, got %+v", payload)
+	}
+	if err := app.Run([]string{"lifecycle", "--mode", "comeback", "--rank-threshold", "0"}); err == nil || !strings.Contains(err.Error(), "--rank-threshold") {
+		t.Fatalf("expected an error for a zero rank threshold, got %v", err)
+	}
+
+	if err := app.Run([]string{"lifecycle", "--mode", "revival"}); err == nil {
+		t.Fatalf("expected error for an unsupported mode")
+	}
+}
+
+func TestAppDebuts(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"debuts", "--format", "json"}); err != nil {
+		t.Fatalf("Run debuts: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// Noah is the only name given in 2019 and not in 2018.
+	if payload.Metadata["year"] != "2019" || len(payload.Rows) != 1 || payload.Rows[0]["Name"] != "Noah" || payload.Rows[0]["Count"] != "70" || payload.Rows[0]["Last Seen"] != "-" {
+		t.Fatalf("unexpected debuts: %+v %+v", payload.Metadata, payload.Rows)
+	}
+
+	if err := app.Run([]string{"debuts", "--lookback", "-1"}); err == nil {
+		t.Fatalf("expected error for a negative lookback")
+	}
+	if err := app.Run([]string{"debuts", "--year", "1900"}); err == nil {
+		t.Fatalf("expected error for a year without data")
+	}
+}
+
+func TestAppAnomalies(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"anomalies", "--baseline", "1", "--min-count", "1", "--format", "json"}); err != nil {
+		t.Fatalf("Run anomalies: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// Olivia and Liam grew by 120 and 75 past their 2018 counts, Noah is
+	// new in 2019 with 70, and Emma shrank.
+	var names []string
+	for _, row := range payload.Rows {
+		names = append(names, row["Name"])
+	}
+	if strings.Join(names, ",") != "Olivia,Liam,Noah" {
+		t.Fatalf("unexpected anomalies: %v", names)
+	}
+	if row := payload.Rows[0]; row["Count"] != "200" || row["Baseline"] != "80" || row["Spike"] != "120" || !cellNear(row, "Z", 120/math.Sqrt(80)) {
+		t.Fatalf("unexpected Olivia row: %+v", row)
+	}
+	if payload.Metadata["year"] != "2019" || payload.Metadata["baseline"] != "2018" {
+		t.Fatalf("unexpected metadata: %+v", payload.Metadata)
+	}
+
+	if err := app.Run([]string{"anomalies", "--baseline", "0"}); err == nil {
+		t.Fatalf("expected error for an empty baseline")
+	}
+	if err := app.Run([]string{"anomalies", "--year", "2018"}); err == nil {
+		t.Fatalf("expected error for a year without baseline data")
+	}
+}
+
+func TestAppLetters(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"letters", "--state", "CA", "--gender", "F", "--plot", "--width", "20", "--format", "json"}); err != nil {
+		t.Fatalf("Run letters: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// CA girls 2018: Olivia 80, Emma 50. 2019: Olivia 140, Emma 90.
+	if strings.Join(payload.Headers, ",") != "Year,Births,O,E" || len(payload.Rows) != 2 {
+		t.Fatalf("unexpected table: %v %v", payload.Headers, payload.Rows)
+	}
+	if !cellNear(payload.Rows[0], "O", 80.0/130) || !cellNear(payload.Rows[1], "E", 90.0/230) {
+		t.Fatalf("unexpected shares: %v", payload.Rows)
+	}
+	if !strings.Contains(strings.Join(payload.Footer, "\n"), "Initial letter E rose the most") ||
+		!strings.Contains(strings.Join(payload.Footer, "\n"), "Legend: █ O, ▓ E, · other") {
+		t.Fatalf("unexpected footer: %q", payload.Footer)
+	}
+
+	if err := app.Run([]string{"letters", "--letters", "Q"}); err == nil {
+		t.Fatalf("expected error for a letter without names")
+	}
+	if err := app.Run([]string{"letters", "--letters", "AB"}); err == nil {
+		t.Fatalf("expected error for more than one letter")
+	}
+}
+
+func TestAppEndings(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"endings", "--state", "CA", "--length", "2", "--endings", "-ia,am", "--format", "json"}); err != nil {
+		t.Fatalf("Run endings: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// CA 2019: Olivia 140 and Liam 95 of 395 births.
+	if strings.Join(payload.Headers, ",") != "Year,Births,-ia,-am" || len(payload.Rows) != 2 || !cellNear(payload.Rows[1], "-ia", 140.0/395) {
+		t.Fatalf("unexpected table: %v %v", payload.Headers, payload.Rows)
+	}
+
+	if err := app.Run([]string{"endings", "--endings", "-ia"}); err == nil {
+		t.Fatalf("expected error for an ending longer than --length")
+	}
+	if err := app.Run([]string{"endings", "--length", "0"}); err == nil {
+		t.Fatalf("expected error for a zero --length")
+	}
+}
+
+func TestAppLengths(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"lengths", "--state", "CA", "--format", "json"}); err != nil {
+		t.Fatalf("Run lengths: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// CA 2018: Olivia 80, Liam 85, Emma 50, so (6*80 + 4*135) / 215.
+	if len(payload.Rows) != 2 || !cellNear(payload.Rows[0], "Mean", (6*80+4*135)/215.0) || payload.Rows[0]["Median"] != "4" {
+		t.Fatalf("unexpected rows: %v", payload.Rows)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"lengths", "--state", "CA", "--year", "2019", "--histogram", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run lengths --histogram: %v", err)
+	}
+	// CA 2019: Emma, Liam, and Noah take 255 births, Olivia 140.
+	want := "Letters,Births,Share,Histogram\n4,255,64.56%,██████████████████████████████\n5,0,0.00%,\n6,140,35.44%,████████████████\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected histogram:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
+
+func TestAppUnisex(t *testing.T) {
+	fs := fstest.MapFS{
+		"CA.TXT": {Data: []byte(
+			"CA,F,2018,Riley,20\n" +
+				"CA,M,2018,Riley,80\n" +
+				"CA,F,2019,Riley,60\n" +
+				"CA,M,2019,Riley,40\n" +
+				"CA,F,2019,Quinn,50\n" +
+				"CA,M,2019,Quinn,50\n" +
+				"CA,F,2019,Emma,300\n"),
+		},
+	}
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, &bytes.Buffer{})
+
+	if err := app.Run([]string{"unisex", "--year", "2019", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run unisex: %v", err)
+	}
+	want := "Rank,Name,Total,Female,Male,Unisex Index\n1,Quinn,100,50,50,1.000\n2,Riley,100,60,40,0.667\n3,Emma,300,300,0,0.000\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected ranking:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"unisex", "riley", "--format", "json"}); err != nil {
+		t.Fatalf("Run unisex riley: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 2 || payload.Rows[0]["Unisex Index"] != "0.25" || payload.Metadata["name"] != "Riley" {
+		t.Fatalf("unexpected history: %v %v", payload.Metadata, payload.Rows)
+	}
+	if len(payload.Footer) != 2 || payload.Footer[1] != "Its majority flipped in 2019: 60% female." {
+		t.Fatalf("unexpected footer: %q", payload.Footer)
+	}
+
+	if err := app.Run([]string{"unisex", "Rylee"}); err == nil {
+		t.Fatalf("expected error for a missing name")
+	}
+}
+
+func TestAppGender(t *testing.T) {
+	fs := fstest.MapFS{
+		"CA.TXT": {Data: []byte(
+			"CA,F,2017,Riley,10\n" +
+				"CA,M,2017,Riley,90\n" +
+				"CA,F,2018,Riley,50\n" +
+				"CA,M,2018,Riley,50\n" +
+				"CA,F,2019,Riley,70\n" +
+				"CA,M,2019,Riley,30\n"),
+		},
+	}
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, &bytes.Buffer{})
+
+	if err := app.Run([]string{"gender", "riley", "--format", "json"}); err != nil {
+		t.Fatalf("Run gender: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// The evenly split 2018 has no majority, so the crossover is 2019.
+	if payload.Metadata["crossover_year"] != "2019" || payload.Metadata["female"] != "130" || payload.Metadata["male"] != "170" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+	if len(payload.Rows) != 3 || payload.Rows[0]["Male Share"] != "0.9" {
+		t.Fatalf("unexpected rows: %v", payload.Rows)
+	}
+	if payload.Footer[0] != "Overall 130 girls and 170 boys were named Riley: 57% male." {
+		t.Fatalf("unexpected footer: %q", payload.Footer)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"gender", "Riley", "--year", "2017", "--format", "json"}); err != nil {
+		t.Fatalf("Run gender for 2017: %v", err)
+	}
+	payload = jsonOutput{}
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if _, ok := payload.Metadata["crossover_year"]; ok || len(payload.Rows) != 1 {
+		t.Fatalf("expected one year without a crossover, got %v %v", payload.Metadata, payload.Rows)
+	}
+
+	if err := app.Run([]string{"gender"}); err == nil {
+		t.Fatalf("expected error when no name is given")
+	}
+}
+
+func TestAppDivergence(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"divergence", "--year", "2019", "--format", "json"}); err != nil {
+		t.Fatalf("Run divergence: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// 2019 nationally: Olivia 200, Liam 160, Emma 90, Noah 70. NY has only
+	// Olivia 60 and Liam 65, so it is furthest from that mix.
+	if len(payload.Rows) != 2 || payload.Rows[0]["State"] != "NY" || !cellNear(payload.Rows[0], "Total Variation", 4.0/13) {
+		t.Fatalf("unexpected rows: %v", payload.Rows)
+	}
+	if payload.Rows[0]["Most Over-represented"] != "Liam (+21.23%)" || payload.Rows[1]["Most Over-represented"] != "Emma (+5.48%)" {
+		t.Fatalf("unexpected over-represented names: %v", payload.Rows)
+	}
+
+	if err := app.Run([]string{"divergence", "--metric", "kl"}); err == nil {
+		t.Fatalf("expected error for an unsupported metric")
+	}
+}
+
+func TestAppDistinctive(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"distinctive", "--state", "ny", "--year", "2019", "--min-count", "1", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run distinctive: %v", err)
+	}
+	// NY 2019: Liam 65 and Olivia 60 of 125. Nationally: Liam 160 and
+	// Olivia 200 of 520.
+	want := "Rank,Name,State Count,State Share,National Count,National Share,Lift\n" +
+		"1,Liam,65,52.000%,160,30.769%,1.69x\n" +
+		"2,Olivia,60,48.000%,200,38.462%,1.25x\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+
+	if err := app.Run([]string{"distinctive"}); err == nil {
+		t.Fatalf("expected error without --state")
+	}
+	if err := app.Run([]string{"distinctive", "--state", "ZZ"}); err == nil {
+		t.Fatalf("expected error for an unknown state")
+	}
+}
+
+func TestAppAge(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"age", "emma", "--gender", "F", "--format", "json"}); err != nil {
+		t.Fatalf("Run age: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// Emma: 2018 CA 50 + NY 45 = 95, 2019 CA 90.
+	if payload.Metadata["births"] != "185" || payload.Metadata["median_year"] != "2018" || payload.Metadata["q3_year"] != "2019" {
+		t.Fatalf("unexpected distribution metadata: %v", payload.Metadata)
+	}
+	if payload.Metadata["as_of"] != "2019" || payload.Metadata["median_age"] != "1" {
+		t.Fatalf("unexpected age metadata: %v", payload.Metadata)
+	}
+	if len(payload.Rows) != 1 || payload.Rows[0]["Decade"] != "2010s" || payload.Rows[0]["Births"] != "185" {
+		t.Fatalf("unexpected histogram rows: %+v", payload.Rows)
+	}
+
+	if err := app.Run([]string{"age"}); err == nil {
+		t.Fatalf("expected error when no name is given")
+	}
+}
+
+func TestAppConcentration(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"concentration", "--state", "CA", "--top", "1,2", "--plot", "--format", "json"}); err != nil {
+		t.Fatalf("Run concentration: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if strings.Join(payload.Headers, "|") != "Year|Births|Names|Top 1|Top 2" {
+		t.Fatalf("unexpected headers: %v", payload.Headers)
+	}
+	// CA 2019: Olivia 140, Liam 95, Emma 90, Noah 70 of 395.
+	row := payload.Rows[1]
+	if row["Year"] != "2019" || row["Names"] != "4" || !cellNear(row, "Top 1", 140.0/395) || !cellNear(row, "Top 2", 235.0/395) {
+		t.Fatalf("unexpected 2019 row: %+v", row)
+	}
+	if payload.Metadata["top"] != "1,2" || len(payload.Footer) == 0 || !strings.HasPrefix(payload.Footer[0], "The top 1 names' share") {
+		t.Fatalf("unexpected metadata or footer: %v %v", payload.Metadata, payload.Footer)
+	}
+
+	if err := app.Run([]string{"concentration", "--top", "0"}); err == nil {
+		t.Fatalf("expected error for a zero cutoff")
+	}
+}
+
+func TestAppConfigFile(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	dir := t.TempDir()
+	app.ConfigPath = filepath.Join(dir, "config.toml")
+	config := "# defaults\n" +
+		"state = \"NY\"  # home state\n" +
+		"format = 'json'\n" +
+		"\n" +
+		"[top]\n" +
+		"gender = \"M\"\n"
+	if err := os.WriteFile(app.ConfigPath, []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	if err := app.Run([]string{"top", "--year", "2019"}); err != nil {
+		t.Fatalf("Run top with config: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("expected config format json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["state"] != "NY" || payload.Metadata["gender"] != "M" || len(payload.Rows) != 1 || payload.Rows[0]["Name"] != "Liam" {
+		t.Fatalf("expected config defaults to apply, got %v %+v", payload.Metadata, payload.Rows)
+	}
+
+	// Flags override the config.
+	stdout.Reset()
+	if err := app.Run([]string{"top", "--year", "2019", "--state", "CA", "--gender", "F", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run top overriding config: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "Rank,Name,Count,Share\n1,Olivia,140,60.87%\n") {
+		t.Fatalf("expected flags to override the config, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"config", "top", "--format", "table"}); err != nil {
+		t.Fatalf("Run config: %v", err)
+	}
+	for _, want := range []string{"state   NY     all commands  2", "gender  M      top           6"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("expected %q in config output:\n%s", want, stdout.String())
+		}
+	}
+
+	if err := os.WriteFile(app.ConfigPath, []byte("[trend]\nbogus = 1\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := app.Run([]string{"trend", "--name", "Emma"}); err == nil || !strings.Contains(err.Error(), "config.toml:2: trend has no --bogus flag") {
+		t.Fatalf("expected unknown flag error with line number, got %v", err)
+	}
+	if err := os.WriteFile(app.ConfigPath, []byte("state = CA\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := app.Run([]string{"top"}); err == nil || !strings.Contains(err.Error(), "config.toml:1") {
+		t.Fatalf("expected parse error with line number, got %v", err)
+	}
+}
+
+func TestAppDatasetZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "namesbystate.zip")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create zip: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	w, err := zw.Create("TX.TXT")
+	if err != nil {
+		t.Fatalf("create zip entry: %v", err)
+	}
+	w.Write([]byte("TX,M,2020,Mateo,300\nTX,M,2020,Liam,250\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	out.Close()
+
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"--dataset", path, "top", "--state", "TX", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run top on zip dataset: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "Rank,Name,Count,Share\n1,Mateo,300,54.55%\n2,Liam,250,45.45%\n") {
+		t.Fatalf("unexpected top output from zip:\n%s", stdout.String())
+	}
+
+	// The zip only applies to the run that named it.
+	stdout.Reset()
+	if err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "--top", "1", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run top after zip run: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "1,Olivia,140") {
+		t.Fatalf("expected the original dataset after the zip run, got:\n%s", stdout.String())
+	}
+
+	notZip := filepath.Join(t.TempDir(), "names.txt")
+	os.WriteFile(notZip, []byte("TX,M,2020,Mateo,300\n"), 0o644)
+	if err := app.Run([]string{"--dataset", notZip, "top"}); err == nil || !strings.Contains(err.Error(), "not a directory or .zip") {
+		t.Fatalf("expected error for a plain file dataset, got %v", err)
+	}
+}
+
+func TestAppUpdateData(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, data := range map[string]string{
+		"CA.TXT":          "CA,F,2023,Olivia,120\nCA,F,2024,Olivia,130\n",
+		"NY.TXT":          "NY,M,2024,Liam,80\n",
+		"StateReadMe.pdf": "not a state file",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/namesbystate.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, stderr)
+
+	dir := filepath.Join(t.TempDir(), "data", "namesbystate")
+	if err := app.Run([]string{"update-data", "--url", server.URL + "/namesbystate.zip", "--dir", dir, "--format", "json"}); err != nil {
+		t.Fatalf("Run update-data: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["states"] != "2" || payload.Metadata["records"] != "3" || payload.Metadata["latest_year"] != "2024" || len(payload.Metadata["sha256"]) != 64 {
+		t.Fatalf("unexpected update metadata: %v", payload.Metadata)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "StateReadMe.pdf")); err == nil {
+		t.Fatalf("expected only state files to be unpacked")
+	}
+
+	// The unpacked directory works as a --dataset, replacing the embedded data.
+	stdout.Reset()
+	if err := app.Run([]string{"--dataset", dir, "top", "--state", "CA", "--year", "2024", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run top on updated data: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "Rank,Name,Count,Share\n1,Olivia,130,100.00%\n") {
+		t.Fatalf("unexpected top output on updated data:\n%s", stdout.String())
+	}
+
+	// A failed download leaves the existing data in place.
+	if err := app.Run([]string{"update-data", "--url", server.URL + "/missing.zip", "--dir", dir}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected a 404 download error, got %v", err)
+	}
+	if err := app.Run([]string{"update-data", "--url", server.URL + "/namesbystate.zip", "--dir", dir, "--sha256", strings.Repeat("0", 64)}); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "CA.TXT")); err != nil {
+		t.Fatalf("expected the earlier data to survive failed updates: %v", err)
+	}
+
+	// A directory update-data wrote is replaced, leaving any sibling alone.
+	sibling := dir + ".old"
+	if err := os.MkdirAll(sibling, 0o755); err != nil {
+		t.Fatalf("create sibling: %v", err)
+	}
+	if err := app.Run([]string{"update-data", "--url", server.URL + "/namesbystate.zip", "--dir", dir}); err != nil {
+		t.Fatalf("Run update-data over earlier data: %v", err)
+	}
+	if _, err := os.Stat(sibling); err != nil {
+		t.Fatalf("expected the sibling directory to survive: %v", err)
+	}
+
+	// Any other directory is refused and left untouched.
+	documents := filepath.Join(t.TempDir(), "Documents")
+	if err := os.MkdirAll(documents, 0o755); err != nil {
+		t.Fatalf("create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(documents, "thesis.txt"), []byte("keep me"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := app.Run([]string{"update-data", "--url", server.URL + "/namesbystate.zip", "--dir", documents}); err == nil || !strings.Contains(err.Error(), "refusing to replace") {
+		t.Fatalf("expected update-data to refuse an unrelated directory, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(documents, "thesis.txt")); err != nil || string(data) != "keep me" {
+		t.Fatalf("expected the unrelated directory to survive, got %q: %v", data, err)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"update-data", "--check", "--format", "json"}); err != nil {
+		t.Fatalf("Run update-data --check: %v", err)
+	}
+	payload = jsonOutput{}
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["latest_year"] != "2019" || payload.Metadata["stale"] != "true" {
+		t.Fatalf("expected the 2019 sample dataset to be stale, got %v", payload.Metadata)
+	}
+}
+
+func TestAppUpdateDataNational(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, data := range map[string]string{
+		"yob2023.txt":        "Olivia,F,15270\nLiam,M,20802\n",
+		"yob2024.txt":        "Olivia,F,14718\nEmma,F,13485\nLiam,M,22164\n",
+		"NationalReadMe.pdf": "not a national file",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	// A build without embedded national files cannot answer national
+	// queries until they are downloaded.
+	dir := filepath.Join(t.TempDir(), "names", "namesnational")
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, io.Discard)
+	app.National = cli.NationalDataset(fstest.MapFS{}, dir)
+	if err := app.Run([]string{"top", "--scope", "national"}); err == nil || !strings.Contains(err.Error(), "update-data --national") {
+		t.Fatalf("expected a missing national dataset error naming update-data --national, got %v", err)
+	}
+
+	if err := app.Run([]string{"update-data", "--national", "--url", server.URL + "/names.zip", "--dir", dir, "--format", "json"}); err != nil {
+		t.Fatalf("Run update-data --national: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["dataset"] != "national" || payload.Metadata["records"] != "5" || payload.Metadata["first_year"] != "2023" || payload.Metadata["latest_year"] != "2024" {
+		t.Fatalf("unexpected update metadata: %v", payload.Metadata)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "NationalReadMe.pdf")); err == nil {
+		t.Fatalf("expected only yobYYYY.txt files to be unpacked")
+	}
+
+	stdout.Reset()
+	app.National = cli.NationalDataset(fstest.MapFS{}, dir)
+	if err := app.Run([]string{"top", "--scope", "national", "--year", "2024", "--gender", "F", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run top on downloaded national data: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "Rank,Name,Count,Share\n1,Olivia,14718,") {
+		t.Fatalf("unexpected national top output:\n%s", stdout.String())
+	}
+
+	// Embedded national files take precedence over the download.
+	embedded := fstest.MapFS{"yob2019.txt": {Data: []byte("Olivia,F,18451\n")}}
+	if got := cli.NationalDataset(embedded, dir); got == nil {
+		t.Fatal("expected a national dataset")
+	} else if _, err := got.Open("yob2019.txt"); err != nil {
+		t.Fatalf("expected the embedded national files, got %v", err)
+	}
+
+	// A state directory is not replaced by national files.
+	states := t.TempDir()
+	os.WriteFile(filepath.Join(states, "CA.TXT"), []byte("CA,F,2019,Olivia,1\n"), 0o644)
+	if err := app.Run([]string{"update-data", "--national", "--url", server.URL + "/names.zip", "--dir", states}); err == nil || !strings.Contains(err.Error(), "refusing to replace") {
+		t.Fatalf("expected update-data --national to refuse a state directory, got %v", err)
+	}
+}
+
+func TestAppNationalScopeEmbedded(t *testing.T) {
+	if _, err := namesdata.NationalYears(namesnational.Files); err != nil {
+		t.Skipf("this build embeds no national files (%v); run names update-data --national or see data/namesnational/README.txt", err)
+	}
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(namesbystate.Files, stdout, io.Discard)
+	app.National = namesnational.Files
+	if err := app.Run([]string{"top", "--scope", "national", "--year", "2019", "--gender", "F", "--top", "1", "--format", "json"}); err != nil {
+		t.Fatalf("Run top national on the embedded data: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// The SSA national totals for 2019: Olivia was given to 18,451 girls.
+	if len(payload.Rows) != 1 || payload.Rows[0]["Name"] != "Olivia" || payload.Rows[0]["Count"] != "18451" {
+		t.Fatalf("unexpected national top name for 2019: %+v", payload.Rows)
+	}
+}
+
+func TestAppTableStyle(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+
+	if err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "--gender", "F", "--table-style", "rounded"}); err != nil {
+		t.Fatalf("Run top --table-style rounded: %v", err)
+	}
+	want := strings.Join([]string{
+		"╭──────┬────────┬───────┬────────╮",
+		"│ Rank │ Name   │ Count │  Share │",
+		"├──────┼────────┼───────┼────────┤",
+		"│    1 │ Olivia │   140 │ 60.87% │",
+		"│    2 │ Emma   │    90 │ 39.13% │",
+		"╰──────┴────────┴───────┴────────╯",
+	}, "\n")
+	if !strings.Contains(stdout.String(), want) {
+		t.Fatalf("expected a rounded table with right-aligned numbers, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "--gender", "F", "--table-style", "github"}); err != nil {
+		t.Fatalf("Run top --table-style github: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "| ---: | ------ | ----: | -----: |\n|    1 | Olivia |   140 | 60.87% |") {
+		t.Fatalf("expected a GitHub pipe table, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "--gender", "F", "-name", "Emma", "--table-style", "unicode", "--color", "always"}); err != nil {
+		t.Fatalf("Run top --table-style unicode --color always: %v", err)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "\x1b[1m│ Rank │ Name   │ Count │  Share │\x1b[0m") || !strings.Contains(out, "\x1b[1;7m│    2 │ Emma   │    90 │ 39.13% │\x1b[0m") || !strings.Contains(out, "┌") {
+		t.Fatalf("expected a bold header and highlighted Emma row, got:\n%q", out)
+	}
+
+	if err := app.Run([]string{"top", "--table-style", "fancy"}); err == nil || !strings.Contains(err.Error(), "unsupported table style") {
+		t.Fatalf("expected table style error, got %v", err)
+	}
+}
+
+func TestAppColorOutput(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "--gender", "F", "-name", "Emma", "--color", "always"}); err != nil {
+		t.Fatalf("Run top --color always: %v", err)
+	}
+	var highlighted string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if strings.HasPrefix(line, "\x1b[1;7m") {
+			highlighted = line
+		}
+	}
+	if !strings.Contains(highlighted, "Emma") || !strings.HasSuffix(highlighted, "\x1b[0m") {
+		t.Fatalf("expected Emma's row to be highlighted, got:\n%q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"trend", "-names", "Olivia,Emma", "--state", "CA", "--gender", "F", "--plot", "--color", "always"}); err != nil {
+		t.Fatalf("Run trend --color always: %v", err)
+	}
+	for _, want := range []string{"\x1b[31m█ Olivia\x1b[0m", "\x1b[32m▓ Emma\x1b[0m"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("expected colored legend entry %q, got:\n%s", want, stdout.String())
+		}
+	}
+
+	// A buffer is not a terminal, so auto leaves the output plain.
+	for _, mode := range []string{"never", "auto"} {
+		stdout.Reset()
+		if err := app.Run([]string{"trend", "-names", "Olivia,Emma", "--state", "CA", "--gender", "F", "--plot", "--color", mode}); err != nil {
+			t.Fatalf("Run trend --color %s: %v", mode, err)
+		}
+		if strings.Contains(stdout.String(), "\x1b[") {
+			t.Fatalf("expected no escape codes with --color %s, got:\n%q", mode, stdout.String())
+		}
+	}
+
+	// Colors never leak into machine-readable formats.
+	stdout.Reset()
+	if err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "-name", "Emma", "--format", "csv", "--color", "always"}); err != nil {
+		t.Fatalf("Run top csv --color always: %v", err)
+	}
+	if strings.Contains(stdout.String(), "\x1b[") {
+		t.Fatalf("expected plain CSV, got:\n%q", stdout.String())
+	}
+
+	if err := app.Run([]string{"top", "--color", "sometimes"}); err == nil || !strings.Contains(err.Error(), "unsupported color mode") {
+		t.Fatalf("expected color mode error, got %v", err)
+	}
+}
+
+func TestAppTopJSONL(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"--state", "CA", "--year", "2019", "--gender", "F", "--format", "jsonl"}); err != nil {
+		t.Fatalf("Run top jsonl: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a metadata line and two rows, got:\n%s", stdout.String())
+	}
+	var header struct {
+		SchemaVersion int               `json:"schema_version"`
+		Metadata      map[string]string `json:"metadata"`
+		Headers       []string          `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("decode metadata line: %v", err)
+	}
+	if header.SchemaVersion != 2 || header.Metadata["state"] != "CA" || strings.Join(header.Headers, ",") != "Rank,Name,Count,Share" {
+		t.Fatalf("unexpected metadata line: %s", lines[0])
+	}
+	if lines[1] != `{"Count":140,"Name":"Olivia","Rank":1,"Share":0.6086956521739131}` || lines[2] != `{"Count":90,"Name":"Emma","Rank":2,"Share":0.391304347826087}` {
+		t.Fatalf("unexpected rows:\n%s\n%s", lines[1], lines[2])
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"--state", "CA", "--year", "2019", "--gender", "F", "--top", "1", "--format", "ndjson", "--tidy", "--metadata-columns"}); err != nil {
+		t.Fatalf("Run top tidy ndjson: %v", err)
+	}
+	var row map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &row); err != nil {
+		t.Fatalf("expected a single row object, got %q: %v", stdout.String(), err)
+	}
+	if row["Name"] != "Olivia" || row["state"] != "CA" {
+		t.Fatalf("unexpected tidy row: %v", row)
+	}
+}
+
+func TestAppTopRankByShare(t *testing.T) {
+	fsys := fstest.MapFS{
+		"CA.TXT": {Data: []byte(
+			"CA,F,2000,Zoe,500\nCA,F,2000,Ava,300\nCA,F,2000,Mia,200\n" +
+				"CA,F,2001,Mia,90\nCA,F,2001,Zoe,10\n",
+		)},
+	}
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(fsys, stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"top", "--state", "CA", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run top: %v", err)
+	}
+	if want := "Rank,Name,Count,Share\n1,Zoe,510,46.36%\n2,Ava,300,27.27%\n3,Mia,290,26.36%\n"; stdout.String() != want {
+		t.Fatalf("unexpected count ranking:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"top", "--state", "CA", "--rank-by", "share", "--name", "Ava", "--year", "2000-2001", "--format", "json"}); err != nil {
+		t.Fatalf("Run top --rank-by share: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["rank_by"] != "share" || payload.Metadata["queried_rank"] != "3" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+	var got []string
+	for _, row := range payload.Rows {
+		got = append(got, row["Name"]+" "+row["Share"])
+	}
+	if strings.Join(got, ", ") != "Mia 0.55, Zoe 0.3, Ava 0.15" {
+		t.Fatalf("unexpected share ranking: %v", got)
+	}
+
+	if err := app.Run([]string{"top", "--rank-by", "births"}); err == nil || !strings.Contains(err.Error(), "--rank-by") {
+		t.Fatalf("expected --rank-by error, got %v", err)
+	}
+}
+
+func TestAppTopSeveralNames(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "-name", "noah,Olivia", "--format", "json"}); err != nil {
+		t.Fatalf("Run top -name: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["queried_name"] != "Noah, Olivia" || payload.Metadata["queried_rank"] != "4, 1" || payload.Metadata["queried_count"] != "70, 140" {
+		t.Fatalf("unexpected queried metadata: %+v", payload.Metadata)
+	}
+	if len(payload.Lines) < 2 || !strings.HasPrefix(payload.Lines[0], "Noah ranks #4") || !strings.HasPrefix(payload.Lines[1], "Olivia ranks #1") {
+		t.Fatalf("expected one rank line per name, got %v", payload.Lines)
+	}
+
+	err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "-name", "Olivia,Oliva"})
+	if err == nil || !strings.Contains(err.Error(), `name "Oliva" not found`) {
+		t.Fatalf("expected the missing name to be reported, got %v", err)
+	}
+}
+
+func TestAppTopNameSuggestions(t *testing.T) {
+	app := cli.NewApp(sampleFS(), &bytes.Buffer{}, &bytes.Buffer{})
+	err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "-name", "Oliva"})
+	if err == nil || !strings.Contains(err.Error(), `name "Oliva" not found for the provided filters (did you mean Olivia?)`) {
+		t.Fatalf("expected a did-you-mean error, got %v", err)
+	}
+}
+
+func TestAppHistory(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"history", "emma", "--state", "CA", "--gender", "F", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run history: %v", err)
+	}
+	want := "Year,Rank,Count,Share,Rank Change\n2018,2,50,38.462%,-\n2019,2,90,39.130%,0\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected history:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"history", "Emma", "--format", "json"}); err != nil {
+		t.Fatalf("Run national history: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["state"] != "NATIONAL" || payload.Metadata["first_year"] != "2018" || payload.Metadata["best_rank"] != "1" ||
+		payload.Metadata["longest_streak"] != "2" || payload.Metadata["streak_start"] != "2018" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+	if len(payload.Rows) != 2 || payload.Rows[1]["Rank Change"] != "-2" {
+		t.Fatalf("unexpected rows: %v", payload.Rows)
+	}
+
+	if err := app.Run([]string{"history"}); err == nil {
+		t.Fatal("expected an error without a name")
+	}
+	if err := app.Run([]string{"history", "Emmma", "--state", "CA"}); err == nil || !strings.Contains(err.Error(), "did you mean Emma") {
+		t.Fatalf("expected a did-you-mean error, got %v", err)
+	}
+}
+
+func TestAppRankStdin(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+	app.Stdin = strings.NewReader("# roster\nemma\nZelda, Olivia\n\n")
+	if err := app.Run([]string{"rank", "Liam", "--stdin", "--state", "CA", "--year", "2019", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run rank --stdin: %v", err)
+	}
+	want := "Name,Rank,Count,Share,Found\n" +
+		"Liam,2,95,24.051%,true\n" +
+		"Emma,3,90,22.785%,true\n" +
+		"Zelda,-,-,-,false\n" +
+		"Olivia,1,140,35.443%,true\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected rank output:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	app.Stdin = strings.NewReader("Zelda\nNoah\n")
+	if err := app.Run([]string{"rank", "--stdin", "--state", "CA", "--year", "2019", "--missing", "skip", "--format", "json"}); err != nil {
+		t.Fatalf("Run rank --missing skip: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if len(payload.Rows) != 1 || payload.Rows[0]["Name"] != "Noah" || payload.Metadata["missing"] != "1" {
+		t.Fatalf("unexpected skipped output: %+v", payload)
+	}
+	if !strings.Contains(strings.Join(payload.Footer, "\n"), "were skipped: Zelda") {
+		t.Fatalf("expected the footer to name the skipped name, got %v", payload.Footer)
+	}
+
+	if err := app.Run([]string{"rank"}); err == nil {
+		t.Fatal("expected an error without names")
+	}
+}
+
+func TestAppAlive(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+	if err := app.Run([]string{"alive", "Emma", "--as-of", "2100", "--format", "json"}); err != nil {
+		t.Fatalf("Run alive: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["births"] != "185" || payload.Metadata["as_of"] != "2100" || payload.Metadata["median_age"] != "82" {
+		t.Fatalf("unexpected metadata: %v", payload.Metadata)
+	}
+	alive, err := strconv.Atoi(payload.Metadata["alive"])
+	if err != nil || alive <= 0 || alive >= 185 {
+		t.Fatalf("expected some but not all of 185 births to survive 81 years, got %q", payload.Metadata["alive"])
+	}
+	if len(payload.Rows) != 1 || payload.Rows[0]["Decade"] != "2010s" || payload.Rows[0]["Ages"] != "81-82" {
+		t.Fatalf("unexpected rows: %v", payload.Rows)
+	}
+
+	// A custom life table where nobody survives past birth.
+	lifeTable := filepath.Join(t.TempDir(), "lifetable.csv")
+	if err := os.WriteFile(lifeTable, []byte("age,male,female\n0,100,100\n1,0,0\n"), 0o644); err != nil {
+		t.Fatalf("write life table: %v", err)
+	}
+	stdout.Reset()
+	if err := app.Run([]string{"alive", "Liam", "--state", "CA", "--life-table", lifeTable, "--format", "json"}); err != nil {
+		t.Fatalf("Run alive --life-table: %v", err)
+	}
+	payload = jsonOutput{}
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["alive"] != "95" || payload.Metadata["births"] != "180" {
+		t.Fatalf("expected only the latest year's births to survive, got %v", payload.Metadata)
+	}
+
+	if err := app.Run([]string{"alive"}); err == nil {
+		t.Fatal("expected an error without a name")
+	}
+}
+
+func TestAppClusters(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"clusters", "--year", "2019", "--gender", "F", "--k", "2", "--format", "json"}); err != nil {
+		t.Fatalf("Run clusters: %v", err)
+	}
+	var out jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Metadata["k"] != "2" || out.Metadata["states"] != "2" || out.Metadata["vocabulary"] != "2" {
+		t.Fatalf("unexpected metadata: %+v", out.Metadata)
+	}
+	if len(out.Rows) != 2 {
+		t.Fatalf("expected 2 clusters, got %+v", out.Rows)
+	}
+	// CA favors Emma relative to NY, where every girl is an Olivia.
+	if out.Rows[0]["States"] != "CA" || out.Rows[0]["Distinctive Names"] != "Emma" {
+		t.Fatalf("unexpected first cluster: %+v", out.Rows[0])
+	}
+	if out.Rows[1]["States"] != "NY" || out.Rows[1]["Distinctive Names"] != "Olivia" {
+		t.Fatalf("unexpected second cluster: %+v", out.Rows[1])
+	}
+
+	if err := app.Run([]string{"clusters", "--k", "3"}); err == nil {
+		t.Fatal("expected an error when k exceeds the states")
+	}
+}
+
+func TestAppDiversity(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"diversity", "--state", "CA", "--gender", "F", "--metric", "gini", "--plot", "--format", "json"}); err != nil {
+		t.Fatalf("Run diversity: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// CA 2019 girls: Olivia 140, Emma 90 of 230.
+	entropy := -(140.0/230*math.Log2(140.0/230) + 90.0/230*math.Log2(90.0/230))
+	row := payload.Rows[1]
+	if row["Year"] != "2019" || row["Names"] != "2" || !cellNear(row, "Entropy", entropy) || !cellNear(row, "Effective Names", math.Pow(2, entropy)) || !cellNear(row, "Gini", 25.0/230) {
+		t.Fatalf("unexpected 2019 row: %+v", row)
+	}
+	if payload.Metadata["metric"] != "gini" || !strings.HasPrefix(payload.Footer[0], "Gini fell from 0.115 in 2018 to 0.109 in 2019 (more diverse)") {
+		t.Fatalf("unexpected metadata or footer: %v %v", payload.Metadata, payload.Footer)
+	}
+	if !strings.Contains(strings.Join(payload.Footer, "\n"), "Plot (metric=gini)") {
+		t.Fatalf("expected a plot in the footer: %v", payload.Footer)
+	}
+
+	if err := app.Run([]string{"diversity", "--metric", "simpson"}); err == nil {
+		t.Fatalf("expected error for an unknown metric")
+	}
+}
+
+func TestAppSimilar(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"similar", "Liam", "--state", "CA", "--min-count", "0", "--method", "dtw", "--plot", "--format", "json"}); err != nil {
+		t.Fatalf("Run similar: %v", err)
+	}
+	var out jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Metadata["name"] != "Liam" || out.Metadata["method"] != "dtw" || out.Metadata["year"] != "2018-2019" {
+		t.Fatalf("unexpected metadata: %+v", out.Metadata)
+	}
+	// In CA every name but Noah peaks in 2018. Liam's share falls sharply in
+	// 2019, and Olivia's falls a little more than Emma's, so it is closest.
+	if len(out.Rows) != 3 || out.Rows[0]["Name"] != "Olivia" || out.Rows[0]["Peak Year"] != "2018" {
+		t.Fatalf("unexpected matches: %+v", out.Rows)
+	}
+	if !strings.Contains(strings.Join(out.Footer, "\n"), "Legend: █ Liam, ▓ Olivia") {
+		t.Fatalf("expected a plot in the footer: %v", out.Footer)
+	}
+
+	if err := app.Run([]string{"similar", "Liam", "--method", "euclid"}); err == nil {
+		t.Fatal("expected an error for an unknown method")
+	}
+}
+
+func TestAppAggregateCache(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, &bytes.Buffer{})
+	app.Cache = cache.New(8)
+
+	topName := func(args ...string) string {
+		t.Helper()
+		stdout.Reset()
+		if err := app.Run(append([]string{"top", "--state", "CA", "--year", "2019", "--gender", "F", "--format", "json"}, args...)); err != nil {
+			t.Fatalf("Run top: %v", err)
+		}
+		var out jsonOutput
+		if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		return out.Rows[0]["Name"]
+	}
+
+	if topName() != "Olivia" || topName() != "Olivia" {
+		t.Fatal("expected Olivia to top CA in 2019")
+	}
+	if stats := app.Cache.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 {
+		t.Fatalf("expected the second run to hit the cache, got %+v", stats)
+	}
+
+	// A dataset given with --dataset is never served from or stored in the
+	// cache.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "CA.TXT"), []byte("CA,F,2019,Ada,500\n"), 0o644); err != nil {
+		t.Fatalf("write dataset: %v", err)
+	}
+	stdout.Reset()
+	if err := app.Run([]string{"--dataset", dir, "top", "--state", "CA", "--year", "2019", "--gender", "F", "--format", "json"}); err != nil {
+		t.Fatalf("Run top --dataset: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Ada") {
+		t.Fatalf("expected the --dataset run to bypass the cache, got:\n%s", stdout.String())
+	}
+	if stats := app.Cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("expected the --dataset run to skip the cache, got %+v", stats)
+	}
+
+	// Swapping the dataset serves stale results until the cache is
+	// invalidated.
+	app.Dataset = os.DirFS(dir)
+	if topName() != "Olivia" {
+		t.Fatal("expected the cached aggregate before invalidation")
+	}
+	app.InvalidateCache()
+	if topName() != "Ada" {
+		t.Fatal("expected the new dataset after invalidation")
+	}
+}
+
+func TestAppGenerateReusesCachedSampler(t *testing.T) {
+	app := cli.NewApp(sampleFS(), &bytes.Buffer{}, &bytes.Buffer{})
+	app.Cache = cache.New(8)
+	key := cache.NewKey("CA", "2019", "F")
+
+	sampler := func(args ...string) *namesdata.NameSampler {
+		t.Helper()
+		if err := app.Run(append([]string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--seed", "1"}, args...)); err != nil {
+			t.Fatalf("Run generate %v: %v", args, err)
+		}
+		entry, ok := app.Cache.Get(key)
+		if !ok || entry.Sampler == nil {
+			t.Fatalf("expected a sampler cached with the aggregate, got %+v", entry)
+		}
+		return entry.Sampler
+	}
+
+	first := sampler()
+	if again := sampler(); again != first {
+		t.Fatal("expected the second run to reuse the cached sampler")
+	}
+	tempered := sampler("--temperature", "0.5")
+	if tempered == first || tempered.Temperature() != 0.5 {
+		t.Fatalf("expected a new sampler at temperature 0.5, got temperature %g", tempered.Temperature())
+	}
+	// Constraints narrow the pool, so the cached sampler is left alone.
+	if constrained := sampler("--starts-with", "E"); constrained != tempered {
+		t.Fatal("expected a constrained run to leave the cached sampler in place")
+	}
+}
+
+func TestAppTemplateOutput(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	args := []string{"top", "--state", "CA", "--year", "2019", "--gender", "F",
+		"--template", `{{padLeft 2 .Rank}}. {{upper .Name}} ({{.Count}}, {{percent .Share 1}}) {{meta "state"}}`}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run top --template: %v", err)
+	}
+	expected := " 1. OLIVIA (140, 60.9%) CA\n 2. EMMA (90, 39.1%) CA\n"
+	if stdout.String() != expected {
+		t.Fatalf("unexpected template output:\n%q\nwant:\n%q", stdout.String(), expected)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"history", "Emma", "--state", "CA", "--template", "{{.Year}}:{{.RankChange}}"}); err != nil {
+		t.Fatalf("Run history --template: %v", err)
+	}
+	if stdout.String() != "2018:-\n2019:0\n" {
+		t.Fatalf("unexpected history template output: %q", stdout.String())
+	}
+
+	if err := app.Run([]string{"top", "--template", "{{.Name"}); err == nil {
+		t.Fatal("expected an error for a malformed template")
+	}
+	if err := app.Run([]string{"top", "--year", "2019", "--template", "{{.Missing}}"}); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("expected an error for an unknown column, got %v", err)
+	}
+}
+
+func TestAppColumnsAndSort(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "--sort", "name", "--columns", "name,count", "--format", "tsv"}); err != nil {
+		t.Fatalf("Run top --sort name: %v", err)
+	}
+	expected := "Name\tCount\nEmma\t90\nLiam\t95\nNoah\t70\nOlivia\t140\n"
+	if stdout.String() != expected {
+		t.Fatalf("unexpected sorted output:\n%q\nwant:\n%q", stdout.String(), expected)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"top", "--state", "CA", "--year", "2019", "--sort", "rank", "--desc", "--columns", "Count,Rank", "--format", "tsv"}); err != nil {
+		t.Fatalf("Run top --sort rank --desc: %v", err)
+	}
+	expected = "Count\tRank\n70\t4\n90\t3\n95\t2\n140\t1\n"
+	if stdout.String() != expected {
+		t.Fatalf("unexpected descending output:\n%q\nwant:\n%q", stdout.String(), expected)
+	}
+
+	if err := app.Run([]string{"top", "--year", "2019", "--sort", "peak"}); err == nil || !strings.Contains(err.Error(), "--sort") {
+		t.Fatalf("expected an error for an unknown sort column, got %v", err)
+	}
+	if err := app.Run([]string{"top", "--year", "2019", "--columns", "Name,Births"}); err == nil || !strings.Contains(err.Error(), "--columns") {
+		t.Fatalf("expected an error for an unknown column, got %v", err)
+	}
+	if err := app.Run([]string{"top", "--desc"}); err == nil {
+		t.Fatal("expected an error for --desc without --sort")
+	}
+}
+
+func TestAppStructuredErrors(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	err := app.Run([]string{"history", "Emmma", "--state", "CA", "--format", "json"})
+	if code := app.HandleError(err); code != cli.ExitNotFound {
+		t.Fatalf("expected exit code %d for an unknown name, got %d (%v)", cli.ExitNotFound, code, err)
+	}
+	var payload struct {
+		Error struct {
+			Code        string   `json:"code"`
+			ExitCode    int      `json:"exit_code"`
+			Name        string   `json:"name"`
+			Suggestions []string `json:"suggestions"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("decode error JSON: %v\n%s", err, stdout.String())
+	}
+	if payload.Error.Code != "name_not_found" || payload.Error.ExitCode != cli.ExitNotFound || payload.Error.Name != "Emmma" ||
+		len(payload.Error.Suggestions) == 0 || payload.Error.Suggestions[0] != "Emma" {
+		t.Fatalf("unexpected error payload: %+v", payload.Error)
+	}
+
+	stdout.Reset()
+	err = app.Run([]string{"rank", "Olivia", "--state", "CA", "--year", "1900"})
+	if code := app.HandleError(err); code != cli.ExitNoMatches {
+		t.Fatalf("expected exit code %d for empty filters, got %d (%v)", cli.ExitNoMatches, code, err)
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), "no matching records") {
+		t.Fatalf("expected a plain message on stderr, got stdout %q stderr %q", stdout.String(), stderr.String())
+	}
+
+	if code := cli.ExitCode(app.Run([]string{"bogus"})); code != cli.ExitUsage {
+		t.Fatalf("expected exit code %d for an unknown command, got %d", cli.ExitUsage, code)
+	}
+	if code := cli.ExitCode(app.Run([]string{"top", "--format", "xml"})); code != cli.ExitUsage {
+		t.Fatalf("expected exit code %d for a bad format, got %d", cli.ExitUsage, code)
+	}
+	if code := cli.ExitCode(app.Run([]string{"top", "-h"})); code != cli.ExitOK {
+		t.Fatalf("expected exit code %d for -h, got %d", cli.ExitOK, code)
+	}
+}
+
+func TestAppServeGraphQL(t *testing.T) {
+	app := &cli.App{Dataset: sampleFS(), Stdout: io.Discard, Stderr: io.Discard}
+	server := httptest.NewServer(app.Handler())
+	defer server.Close()
+
+	query := `{ name(name: "Olivia", gender: F) { rank(state: "CA", year: 2019) { rank count } } top(state: "NY", year: 2019) { name } }`
+	resp, err := http.Post(server.URL+"/graphql", "application/json", strings.NewReader(fmt.Sprintf(`{"query": %q}`, query)))
+	if err != nil {
+		t.Fatalf("POST /graphql: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	want := `{"data":{"name":{"rank":{"count":140,"rank":1}},"top":[{"name":"Liam"},{"name":"Olivia"}]}}`
+	if strings.TrimSpace(string(body)) != want {
+		t.Fatalf("unexpected response:\n%s\nwant:\n%s", body, want)
+	}
+
+	resp, err = http.Get(server.URL + "/graphql")
+	if err != nil {
+		t.Fatalf("GET /graphql: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a query, got %d", resp.StatusCode)
+	}
+}
+
+func TestAppValidate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ZZ.TXT")
+	if err := os.WriteFile(path, []byte("ZZ,F,2019,Ada,50\nZZ,F,2020,Ada,40\n"), 0o644); err != nil {
+		t.Fatalf("write dataset: %v", err)
+	}
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, io.Discard)
+	if err := app.Run([]string{"--dataset", dir, "validate"}); err != nil {
+		t.Fatalf("Run validate: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Validated 2 records in 1 file:") || !strings.Contains(stdout.String(), "No problems found.") {
+		t.Fatalf("expected a clean report, got:\n%s", stdout.String())
+	}
+
+	// A malformed first line would fail --dataset's quick check, but
+	// validate reads past it to report every problem.
+	data := "ZZ,F,2020\nZZ,F,2020,Ada,50\nZZ,F,2020,Ada,40\nZZ,F,2020,Bea,2\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write dataset: %v", err)
+	}
+	stdout.Reset()
+	err := app.Run([]string{"--dataset", dir, "validate", "--format", "json"})
+	if cli.ExitCode(err) != cli.ExitInvalid {
+		t.Fatalf("expected exit code %d, got %v", cli.ExitInvalid, err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["problems"] != "3" || len(payload.Rows) != 3 {
+		t.Fatalf("unexpected report: %+v", payload)
+	}
+	if payload.Rows[1]["Line"] != "3" || payload.Rows[1]["Problem"] != "duplicate of line 2 (Ada, F, 2020)" {
+		t.Fatalf("unexpected duplicate row: %+v", payload.Rows[1])
+	}
+
+	// HandleError leaves the report as the only document on stdout.
+	stdout.Reset()
+	if code := app.HandleError(err); code != cli.ExitInvalid || stdout.Len() != 0 {
+		t.Fatalf("expected exit code %d and no extra output, got %d %q", cli.ExitInvalid, code, stdout.String())
+	}
+}
+
+func TestAppDatasetMerge(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "CA.TXT"), []byte("CA,F,2020,Ada,50\nCA,F,2019,Emma,60\n"), 0o644); err != nil {
+		t.Fatalf("write dataset: %v", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, io.Discard)
+	if err := app.Run([]string{"--dataset", "embedded," + dir, "top", "--state", "CA", "--year", "2019", "--gender", "F", "--format", "json"}); err != nil {
+		t.Fatalf("Run top with merged datasets: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	// Emma's 90 embedded births gain the delta's 60 and overtake Olivia.
+	if len(payload.Rows) != 2 || payload.Rows[0]["Name"] != "Emma" || payload.Rows[0]["Count"] != "150" {
+		t.Fatalf("unexpected rows: %+v", payload.Rows)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"--dataset", "embedded," + dir, "top", "--state", "NY", "--year", "2019", "--format", "tsv"}); err != nil {
+		t.Fatalf("Run top for a state only in the embedded dataset: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Liam") {
+		t.Fatalf("expected NY from the embedded dataset, got:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"--dataset", "embedded," + filepath.Join(dir, "missing"), "top"}); err == nil {
+		t.Fatal("expected error for a missing merge source")
+	}
+}
+
+func TestAppIncludeTerritories(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, stderr)
+	if err := app.Run([]string{"--include-territories", "top"}); err == nil || !strings.Contains(err.Error(), "territory dataset is not available") {
+		t.Fatalf("expected an unavailable territory dataset error, got %v", err)
+	}
+	// After the command name the flag reports the same error, not a bad flag.
+	err := app.Run([]string{"top", "--include-territories"})
+	if err == nil || !strings.Contains(err.Error(), "update-data --territories") || cli.ExitCode(err) != cli.ExitFailure {
+		t.Fatalf("expected a non-usage unavailable territory dataset error, got %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("expected no usage text, got:\n%s", stderr.String())
+	}
+
+	app.Territories = fstest.MapFS{
+		"PR.TXT": {Data: []byte("PR,F,2019,Sofia,300\nPR,F,2019,Olivia,20\n")},
+	}
+	if err := app.Run([]string{"--include-territories", "top", "--year", "2019", "--gender", "F", "--format", "json"}); err != nil {
+		t.Fatalf("Run top with territories: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["territories"] != "included" || payload.Rows[0]["Name"] != "Sofia" || payload.Rows[1]["Count"] != "220" {
+		t.Fatalf("expected territories in the national totals, got %+v", payload)
+	}
+
+	// The flag also works after the command, and the default leaves the
+	// territories out.
+	stdout.Reset()
+	if err := app.Run([]string{"top", "--state", "PR", "--include-territories", "--format", "tsv"}); err != nil {
+		t.Fatalf("Run top for PR: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Sofia") {
+		t.Fatalf("expected PR names, got:\n%s", stdout.String())
+	}
+	if err := app.Run([]string{"top", "--state", "PR"}); err == nil {
+		t.Fatal("expected PR to be unknown without --include-territories")
+	}
+
+	// Turning the territories back off leaves the run cacheable.
+	app.Cache = cache.New(8)
+	if err := app.Run([]string{"--include-territories", "top", "--include-territories=false", "--year", "2019"}); err != nil {
+		t.Fatalf("Run top with territories turned off: %v", err)
+	}
+	if size := app.Cache.Stats().Size; size != 1 {
+		t.Fatalf("expected the aggregate to be cached, got %d entries", size)
+	}
+}
+
+func TestAppUpdateDataTerritories(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, data := range map[string]string{
+		"PR.TXT":              "PR,F,2019,Sofia,300\nPR,M,2019,Sebastian,250\n",
+		"TR.TXT":              "TR,F,2019,Olivia,12\n",
+		"TerritoryReadMe.pdf": "not a territory file",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "names", "namesbyterritory")
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, io.Discard)
+	if err := app.Run([]string{"update-data", "--territories", "--url", server.URL + "/namesbyterritory.zip", "--dir", dir, "--format", "json"}); err != nil {
+		t.Fatalf("Run update-data --territories: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["territories"] != "2" || payload.Metadata["records"] != "3" {
+		t.Fatalf("unexpected update metadata: %v", payload.Metadata)
+	}
+
+	stdout.Reset()
+	app.Territories = cli.TerritoryDataset(fstest.MapFS{}, dir)
+	if err := app.Run([]string{"top", "--include-territories", "--state", "PR", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run top on downloaded territories: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "Rank,Name,Count,Share\n1,Sofia,300,") {
+		t.Fatalf("unexpected territory top output:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"update-data", "--territories", "--national"}); cli.ExitCode(err) != cli.ExitUsage {
+		t.Fatalf("expected a usage error combining --territories and --national, got %v", err)
+	}
+}
+
+func TestAppStats(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, io.Discard)
+	if err := app.Run([]string{"stats", "--format", "json"}); err != nil {
+		t.Fatalf("Run stats: %v", err)
+	}
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+	if payload.Metadata["first_year"] != "2018" || payload.Metadata["last_year"] != "2019" || payload.Metadata["states"] != "2" {
+		t.Fatalf("unexpected metadata: %+v", payload.Metadata)
+	}
+	if len(payload.Rows) != 2 || payload.Rows[0]["State"] != "CA" || payload.Rows[0]["Records"] != "8" || payload.Rows[1]["Births"] != "170" {
+		t.Fatalf("unexpected rows: %+v", payload.Rows)
+	}
+}
+
+func TestAppTrendSVGTheme(t *testing.T) {
+	dir := t.TempDir()
+	svgPath := filepath.Join(dir, "trend.svg")
+	app := cli.NewApp(sampleFS(), io.Discard, io.Discard)
+	if err := app.Run([]string{"trend", "-names", "Olivia,Emma", "-gender", "F", "--svg", svgPath, "--theme", "dark", "--palette", "#abc, #123456"}); err != nil {
+		t.Fatalf("Run trend with theme: %v", err)
+	}
+	data, err := os.ReadFile(svgPath)
+	if err != nil {
+		t.Fatalf("read svg: %v", err)
+	}
+	svg := string(data)
+	// The dark background and both custom series colors are used, in order.
+	if !strings.Contains(svg, `stop-color="#111827"`) || strings.Index(svg, `stroke="#aabbcc"`) > strings.Index(svg, `stroke="#123456"`) || !strings.Contains(svg, `stroke="#123456"`) {
+		t.Fatalf("expected dark theme with the custom palette, got:\n%s", svg)
+	}
+
+	if err := app.Run([]string{"trend", "-name", "Olivia", "--svg", svgPath, "--theme", "neon"}); err == nil || !strings.Contains(err.Error(), "unsupported theme") {
+		t.Fatalf("expected an unsupported theme error, got %v", err)
+	}
+	if err := app.Run([]string{"trend", "-name", "Olivia", "--svg", svgPath, "--palette", "red"}); err == nil || !strings.Contains(err.Error(), "invalid color") {
+		t.Fatalf("expected an invalid color error, got %v", err)
+	}
+}
+
+func TestAppTrendAnnotatePeaks(t *testing.T) {
+	dir := t.TempDir()
+	svgPath := filepath.Join(dir, "trend.svg")
+	app := cli.NewApp(sampleFS(), io.Discard, io.Discard)
+	if err := app.Run([]string{"trend", "-names", "Olivia,Emma", "-state", "CA", "-gender", "F", "--metric", "count", "--svg", svgPath, "--annotate-peaks"}); err != nil {
+		t.Fatalf("Run trend --annotate-peaks: %v", err)
+	}
+	data, err := os.ReadFile(svgPath)
+	if err != nil {
+		t.Fatalf("read svg: %v", err)
+	}
+	svg := string(data)
+	if strings.Count(svg, `class="peak"`) != 2 || !strings.Contains(svg, ">Olivia 2019: 140<") || !strings.Contains(svg, ">Emma 2019: 90<") {
+		t.Fatalf("expected a peak marker per series, got:\n%s", svg)
+	}
+
+	if err := app.Run([]string{"trend", "-names", "Olivia", "-state", "CA", "--svg", svgPath}); err != nil {
+		t.Fatalf("Run trend: %v", err)
+	}
+	if data, _ := os.ReadFile(svgPath); strings.Contains(string(data), `class="peak"`) {
+		t.Fatal("expected no peak markers without --annotate-peaks")
+	}
+}
+
+func TestAppTrendFacet(t *testing.T) {
+	dir := t.TempDir()
+	svgPath := filepath.Join(dir, "trend.svg")
+	app := cli.NewApp(sampleFS(), io.Discard, io.Discard)
+	run := func(extra ...string) string {
+		t.Helper()
+		args := append([]string{"trend", "-names", "Olivia,Emma", "-state", "CA", "-gender", "F", "--metric", "count", "--svg", svgPath, "--facet"}, extra...)
+		if err := app.Run(args); err != nil {
+			t.Fatalf("Run %v: %v", args, err)
+		}
+		data, err := os.ReadFile(svgPath)
+		if err != nil {
+			t.Fatalf("read svg: %v", err)
+		}
+		return string(data)
+	}
+
+	svg := run()
+	if !strings.Contains(svg, `font-size="13" font-weight="600" text-anchor="start" fill="#1f77b4">Olivia<`) ||
+		!strings.Contains(svg, `font-size="13" font-weight="600" text-anchor="start" fill="#ff7f0e">Emma<`) {
+		t.Fatalf("expected a labeled panel per name, got:\n%s", svg)
+	}
+	if strings.Contains(svg, `rx="10"`) {
+		t.Fatalf("expected no legend in a faceted chart, got:\n%s", svg)
+	}
+	// Each panel fits its own series: Olivia spans 80-140, Emma 50-90.
+	if strings.Count(svg, ">140<") != 1 || strings.Count(svg, ">50<") != 1 || !strings.Contains(svg, ">80<") {
+		t.Fatalf("expected per-panel value ranges, got:\n%s", svg)
+	}
+
+	svg = run("--facet-shared-y")
+	if strings.Count(svg, ">140<") != 2 || strings.Count(svg, ">50<") != 2 || strings.Contains(svg, ">80<") {
+		t.Fatalf("expected a shared value range, got:\n%s", svg)
+	}
+}
+
+func TestAppTrendPlotBraille(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, io.Discard)
+	if err := app.Run([]string{"trend", "-names", "Olivia,Emma", "-state", "CA", "-gender", "F", "--metric", "count", "--plot", "--plot-style", "braille", "--height", "2"}); err != nil {
+		t.Fatalf("Run trend --plot-style braille: %v", err)
+	}
+	out := stdout.String()
+	// Two years fill one cell's two dot columns. Over 8 dot rows Olivia
+	// climbs from row 5 to row 0 and Emma from row 7 to row 4, each joined
+	// by a vertical run in the second column.
+	if !strings.Contains(out, "Plot (metric=count)\n⢸\n⡺\n2018 2019\n") || !strings.Contains(out, "Legend: ⣿ Olivia, ⣿ Emma") {
+		t.Fatalf("unexpected braille plot:\n%s", out)
+	}
+
+	err := app.Run([]string{"trend", "-name", "Olivia", "--plot", "--plot-style", "dots"})
+	if err == nil || !strings.Contains(err.Error(), `unsupported plot style "dots"`) {
+		t.Fatalf("expected an unsupported plot style error, got %v", err)
+	}
+}
+
+func TestAppHeatmap(t *testing.T) {
+	dir := t.TempDir()
+	svgPath := filepath.Join(dir, "heatmap.svg")
+	stdout := &bytes.Buffer{}
+	app := cli.NewApp(sampleFS(), stdout, io.Discard)
+	if err := app.Run([]string{"heatmap", "--name", "olivia", "--gender", "F", "--svg", svgPath}); err != nil {
+		t.Fatalf("Run heatmap: %v", err)
+	}
+	out := stdout.String()
+	// NY records Olivia only in 2019, where she is every birth; in CA she
+	// peaks at 80 of 130 births in 2018.
+	for _, want := range []string{
+		"Olivia's share of births by state, 2018-2019 (F):",
+		"CA     220    2018       61.5385%",
+		"NY     60     2019       100.0000%",
+		"CA ▓▓\nNY  █\n   2018 2019\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	data, err := os.ReadFile(svgPath)
+	if err != nil {
+		t.Fatalf("read svg: %v", err)
+	}
+	if svg := string(data); !strings.Contains(svg, "<title>NY 2018: not recorded</title>") || !strings.Contains(svg, "<title>NY 2019: 100.00% (60)</title>") {
+		t.Fatalf("expected a cell per state and year, got:\n%s", svg)
+	}
+}
+
+func TestAppGenerateSynthetic(t *testing.T) {
+	run := func() jsonOutput {
+		t.Helper()
+		stdout := &bytes.Buffer{}
+		app := cli.NewApp(sampleFS(), stdout, io.Discard)
+		args := []string{"generate", "--state", "CA", "--synthetic", "--order", "2", "--count", "4", "--unique", "--seed", "11", "--format", "json"}
+		if err := app.Run(args); err != nil {
+			t.Fatalf("Run generate --synthetic: %v", err)
+		}
+		var out jsonOutput
+		if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+			t.Fatalf("decode json: %v", err)
+		}
+		return out
+	}
+
+	out := run()
+	if out.Metadata["synthetic"] != "true" || out.Metadata["markov_order"] != "2" || out.Metadata["training_names"] != "4" || len(out.Rows) != 4 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	seen := make(map[string]bool)
+	for _, row := range out.Rows {
+		name := row["Name"]
+		switch strings.ToUpper(name) {
+		case "OLIVIA", "EMMA", "LIAM", "NOAH":
+			t.Fatalf("expected only invented names, got %q", name)
+		}
+		if seen[name] {
+			t.Fatalf("expected distinct names with --unique, got %q twice", name)
+		}
+		seen[name] = true
+	}
+	if again := run(); again.Metadata["generated_name"] != out.Metadata["generated_name"] {
+		t.Fatalf("expected the same names from the same seed, got %q and %q", out.Metadata["generated_name"], again.Metadata["generated_name"])
+	}
+
+	app := cli.NewApp(sampleFS(), io.Discard, io.Discard)
+	if err := app.Run([]string{"generate", "--synthetic", "--pair"}); err == nil {
+		t.Fatal("expected --synthetic with --pair to fail")
+	}
+
+	// Trained on Aba and Ababab, the model also spells Abab and Ababa, but
+	// those are real names elsewhere in the dataset and never generated.
+	stdout := &bytes.Buffer{}
+	app = cli.NewApp(fstest.MapFS{
+		"CA.TXT": {Data: []byte("CA,F,2019,Aba,50\nCA,F,2019,Ababab,50\nCA,M,2019,Abab,10\n")},
+		"NY.TXT": {Data: []byte("NY,F,2018,Ababa,10\n")},
+	}, stdout, io.Discard)
+	if err := app.Run([]string{"generate", "--state", "CA", "--year", "2019", "--gender", "F", "--synthetic", "--order", "2", "--count", "20", "--seed", "7", "--format", "csv", "--tidy"}); err != nil {
+		t.Fatalf("Run generate --synthetic: %v", err)
+	}
+	for _, name := range []string{"Aba", "Abab", "Ababa", "Ababab"} {
+		if strings.Contains(stdout.String(), ","+name+",") {
+			t.Fatalf("expected %s, a name in the dataset, never to be generated, got:\n%s", name, stdout.String())
+		}
+	}
+}
+
+func TestAppTopTSV(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"--state", "CA", "--year", "2019", "--gender", "F", "--format", "tsv"}); err != nil {
+		t.Fatalf("Run top tsv: %v", err)
+	}
+
+	want := "Rank\tName\tCount\tShare\n1\tOlivia\t140\t60.87%\n2\tEmma\t90\t39.13%\n"
+	if got := stdout.String(); got != want {
+		t.Fatalf("unexpected tsv output:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestAppTopTidyCSV(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	sidecar := filepath.Join(t.TempDir(), "meta.json")
+	args := []string{"--state", "CA", "--year", "2019", "--gender", "F", "--format", "csv", "--tidy", "--metadata-columns", "--metadata-file", sidecar}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("Run top tidy csv: %v", err)
+	}
+
+	want := "Rank,Name,Count,Share,gender,state,year\n1,Olivia,140,60.87%,F,CA,2019\n2,Emma,90,39.13%,F,CA,2019\n"
+	if got := stdout.String(); got != want {
+		t.Fatalf("unexpected tidy csv output:\n%q\nwant:\n%q", got, want)
+	}
+
+	data, err := os.ReadFile(sidecar)
+	if err != nil {
+		t.Fatalf("read sidecar: %v", err)
+	}
+	var meta jsonOutput
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatalf("unmarshal sidecar: %v\n%s", err, data)
+	}
+	if meta.Metadata["state"] != "CA" || len(meta.Lines) == 0 {
+		t.Fatalf("unexpected sidecar contents: %s", data)
+	}
+}
+
+func TestAppJSONSchemaVersions(t *testing.T) {
+	fs := sampleFS()
+
+	decode := func(args ...string) map[string]any {
+		t.Helper()
+		stdout := &bytes.Buffer{}
+		app := cli.NewApp(fs, stdout, &bytes.Buffer{})
+		if err := app.Run(args); err != nil {
+			t.Fatalf("Run %v: %v", args, err)
+		}
+		var payload struct {
+			SchemaVersion int              `json:"schema_version"`
+			Rows          []map[string]any `json:"rows"`
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+			t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+		}
+		if len(payload.Rows) == 0 {
+			t.Fatalf("expected rows for %v", args)
+		}
+		return payload.Rows[0]
+	}
+
+	typed := decode("generate", "--state", "CA", "--year", "2019", "--gender", "F", "--seed", "3", "--format", "json")
+	if _, ok := typed["DatasetCount"].(float64); !ok {
+		t.Fatalf("expected numeric DatasetCount, got %T", typed["DatasetCount"])
+	}
+	chance, ok := typed["Chance"].(float64)
+	if !ok || chance <= 0 || chance > 1 {
+		t.Fatalf("expected fractional Chance, got %v", typed["Chance"])
+	}
+	if typed["Name"] == "" {
+		t.Fatalf("expected name string, got %v", typed["Name"])
+	}
+	// CA girls in 2019 number 230, and the chance keeps every digit.
+	if count := typed["DatasetCount"].(float64); chance != count/230 {
+		t.Fatalf("expected Chance %v, got %v", count/230, chance)
+	}
+
+	distinctive := decode("distinctive", "--state", "NY", "--year", "2019", "--min-count", "1", "--format", "json")
+	if _, ok := distinctive["Lift"].(float64); !ok {
+		t.Fatalf("expected numeric Lift, got %v", distinctive["Lift"])
+	}
+
+	legacy := decode("--state", "CA", "--year", "2019", "--format", "json", "--schema-version", "1")
+	if legacy["Count"] != "140" || legacy["Rank"] != "1" {
+		t.Fatalf("expected string cells in legacy schema, got %+v", legacy)
+	}
+
+	if err := cli.NewApp(fs, &bytes.Buffer{}, &bytes.Buffer{}).Run([]string{"--format", "json", "--schema-version", "9"}); err == nil {
+		t.Fatalf("expected error for unsupported schema version")
+	}
+}
+
+func TestAppTopByState(t *testing.T) {
+	fs := sampleFS()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := cli.NewApp(fs, stdout, stderr)
+
+	if err := app.Run([]string{"top", "--by", "state", "--year", "2018", "--top", "2", "--format", "json"}); err != nil {
+		t.Fatalf("Run top by state: %v", err)
+	}
+
+	var payload jsonOutput
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v\n%s", err, stdout.String())
+	}
+
+	if len(payload.Rows) != 2 {
+		t.Fatalf("expected one row per state, got %d", len(payload.Rows))
+	}
+	ca := payload.Rows[0]
+	if ca["State"] != "CA" || ca["#1 Name"] != "Liam" || ca["#1 Count"] != "85" || ca["#2 Name"] != "Olivia" {
+		t.Fatalf("unexpected CA row: %+v", ca)
+	}
+	ny := payload.Rows[1]
+	if ny["State"] != "NY" || ny["#1 Name"] != "Emma" || ny["#2 Name"] != "-" {
+		t.Fatalf("unexpected NY row: %+v", ny)
+	}
+
+	if err := app.Run([]string{"top", "--by", "state", "--state", "CA"}); err == nil {
+		t.Fatalf("expected error when combining --by state with -state")
+	}
+}
+
 func TestAppVersionCommand(t *testing.T) {
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
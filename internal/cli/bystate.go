@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// runTopByState renders each state's top names as one row per state, with a
+// name and count column pair for every position.
+func (a *App) runTopByState(filter yearFilter, gender string, topN int, output *outputOptions) error {
+	weight, err := recencyWeight("none", filter, 0)
+	if err != nil {
+		return err
+	}
+
+	states, err := namesdata.AggregateByState(a.Dataset, gender, weight)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(states, func(state namesdata.StateAggregate) bool { return len(state.Names) > 0 }) {
+		return namesdata.ErrNoMatches
+	}
+
+	metadata := map[string]string{
+		"by":  "state",
+		"top": fmt.Sprintf("%d", topN),
+	}
+	if desc := filter.String(); desc != "" {
+		metadata["year"] = desc
+	}
+	if trimmed := strings.TrimSpace(gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	title := fmt.Sprintf("Top %d names by state", topN)
+	if desc := filter.String(); desc != "" {
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	if trimmed := strings.TrimSpace(gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	headers := make([]string, 0, 1+topN*2)
+	headers = append(headers, "State")
+	for i := 1; i <= topN; i++ {
+		headers = append(headers, fmt.Sprintf("#%d Name", i), fmt.Sprintf("#%d Count", i))
+	}
+
+	rows := make([][]any, len(states))
+	for i, state := range states {
+		row := make([]any, 0, len(headers))
+		row = append(row, state.State)
+		for pos := 0; pos < topN; pos++ {
+			if pos < len(state.Names) {
+				entry := state.Names[pos]
+				row = append(row, entry.Name, entry.Count)
+			} else {
+				row = append(row, nil, nil)
+			}
+		}
+		rows[i] = row
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
+
+// runTopByDecade renders the top names of each decade side by side, with a
+// name and count column pair for every decade.
+func (a *App) runTopByDecade(scope, state string, filter yearFilter, gender string, topN int, output *outputOptions) error {
+	records, err := a.scopedRecords(scope, state)
+	if err != nil {
+		return err
+	}
+
+	decades := namesdata.GroupByDecade(filterRecordsByYear(records, filter), gender)
+
+	metadata := map[string]string{
+		"by":      "decade",
+		"top":     fmt.Sprintf("%d", topN),
+		"decades": fmt.Sprintf("%d", len(decades)),
+	}
+	displayLocation := "the United States"
+	if state != "" {
+		metadata["state"] = strings.ToUpper(state)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if desc := filter.String(); desc != "" {
+		metadata["year"] = desc
+	}
+	if trimmed := strings.TrimSpace(gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	if len(decades) == 0 {
+		return namesdata.ErrNoMatches
+	}
+
+	title := fmt.Sprintf("Top %d names by decade in %s", topN, displayLocation)
+	if desc := filter.String(); desc != "" {
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	if trimmed := strings.TrimSpace(gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	headers := make([]string, 0, 1+len(decades)*2)
+	headers = append(headers, "Rank")
+	for _, decade := range decades {
+		headers = append(headers, decade.Label()+" Name", decade.Label()+" Count")
+	}
+
+	rows := make([][]any, topN)
+	for pos := 0; pos < topN; pos++ {
+		row := make([]any, 0, len(headers))
+		row = append(row, pos+1)
+		for _, decade := range decades {
+			if pos < len(decade.Names) {
+				entry := decade.Names[pos]
+				row = append(row, entry.Name, entry.Count)
+			} else {
+				row = append(row, nil, nil)
+			}
+		}
+		rows[pos] = row
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
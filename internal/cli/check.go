@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	rosterPath := fs.String("roster", "", "file listing the existing names, one per line")
+	namesCSV := fs.String("names", "", "comma-separated candidate names (defaults to checking the roster against itself)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(*rosterPath) == "" {
+		return errors.New("check: --roster is required")
+	}
+
+	roster, err := readNameList(*rosterPath)
+	if err != nil {
+		return fmt.Errorf("check: %w", err)
+	}
+	if len(roster) == 0 {
+		return errors.New("check: roster is empty")
+	}
+
+	var candidates []string
+	if trimmed := strings.TrimSpace(*namesCSV); trimmed != "" {
+		candidates = splitNames(trimmed)
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	trimmedState := strings.TrimSpace(*state)
+	aggregated, total, err := a.cachedAggregate(scopeState, trimmedState, *gender, yearFilter)
+	if err != nil {
+		return err
+	}
+
+	checks := namesdata.CheckRoster(aggregated, total, roster, candidates)
+
+	metadata := map[string]string{
+		"roster_size": fmt.Sprintf("%d", len(roster)),
+	}
+	scope := "the United States"
+	if trimmedState != "" {
+		metadata["state"] = strings.ToUpper(trimmedState)
+		scope = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	title := fmt.Sprintf("Collision check against a roster of %d in %s", len(roster), scope)
+	if desc := yearFilter.String(); desc != "" {
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	rows := make([][]any, len(checks))
+	flagged := 0
+	for i, check := range checks {
+		conflicts := make([]string, len(check.Conflicts))
+		for j, conflict := range check.Conflicts {
+			conflicts[j] = fmt.Sprintf("%s (%s)", conflict.Name, conflict.Reason)
+		}
+		if len(conflicts) > 0 {
+			flagged++
+		}
+		var conflictText any
+		if len(conflicts) > 0 {
+			conflictText = strings.Join(conflicts, "; ")
+		}
+		rows[i] = []any{
+			check.Name,
+			check.Count,
+			percentCell(check.Share, 4),
+			percentCell(check.Collision, 2),
+			conflictText,
+		}
+	}
+	metadata["flagged"] = fmt.Sprintf("%d", flagged)
+
+	rpt := report{
+		Lines:    []string{title},
+		Metadata: metadata,
+		Headers:  []string{"Name", "Count", "Share", "Collision", "Conflicts"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
+
+// readNameList reads names from a file, one per line. Commas also separate
+// names, and blank lines or lines starting with # are ignored.
+func readNameList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return scanNameList(file, path)
+}
+
+// scanNameList reads names from r, one or more comma-separated per line,
+// skipping blank lines and # comments. source names r in errors.
+func scanNameList(r io.Reader, source string) ([]string, error) {
+	names := make([]string, 0, 32)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, splitNames(line)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", source, err)
+	}
+	return names, nil
+}
+
+// splitNames splits a comma-separated list, dropping empty entries.
+func splitNames(raw string) []string {
+	parts := strings.Split(raw, ",")
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			names = append(names, trimmed)
+		}
+	}
+	return names
+}
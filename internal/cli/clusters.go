@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runClusters(args []string) error {
+	fs := flag.NewFlagSet("clusters", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	k := fs.Int("k", 5, "number of clusters")
+	vocabulary := fs.Int("vocab", 100, "number of most popular names whose shares are compared")
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("clusters: unexpected argument %q", fs.Arg(0))
+	}
+	if *k < 1 {
+		return errors.New("clusters: --k must be at least 1")
+	}
+	if *vocabulary < 1 {
+		return errors.New("clusters: --vocab must be at least 1")
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	weight, err := recencyWeight("none", yearFilter, 0)
+	if err != nil {
+		return err
+	}
+	aggregates, err := namesdata.AggregateByState(a.Dataset, *gender, weight)
+	if err != nil {
+		return err
+	}
+	clustering, err := namesdata.ClusterStates(aggregates, *k, *vocabulary)
+	if err != nil {
+		return fmt.Errorf("clusters: %w", err)
+	}
+
+	clustered := 0
+	rows := make([][]any, len(clustering.Clusters))
+	for i, c := range clustering.Clusters {
+		clustered += len(c.States)
+		var distinctive any
+		if len(c.Distinctive) > 0 {
+			distinctive = strings.Join(c.Distinctive, ", ")
+		}
+		rows[i] = []any{
+			i + 1,
+			len(c.States),
+			strings.Join(c.States, ", "),
+			distinctive,
+		}
+	}
+
+	metadata := map[string]string{
+		"k":          fmt.Sprintf("%d", len(clustering.Clusters)),
+		"vocabulary": fmt.Sprintf("%d", len(clustering.Vocabulary)),
+		"states":     fmt.Sprintf("%d", clustered),
+		"inertia":    fmt.Sprintf("%.6f", clustering.Inertia),
+	}
+	title := fmt.Sprintf("%d clusters of states by naming preference", len(clustering.Clusters))
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+		title += fmt.Sprintf(" (%s)", metadata["gender"])
+	}
+	title += ":"
+
+	footer := []string{
+		fmt.Sprintf("Each state is compared on its share of births for the %d most popular names and grouped with k-means; distinctive names are more common in the cluster than across all states.",
+			len(clustering.Vocabulary)),
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Cluster", "Size", "States", "Distinctive Names"},
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parseColumnList splits a --columns value into its trimmed, non-empty
+// entries.
+func parseColumnList(raw string) []string {
+	var columns []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			columns = append(columns, part)
+		}
+	}
+	return columns
+}
+
+// columnIndex finds the header matching name case-insensitively, ignoring
+// spaces and punctuation, so "peak-year" and "PeakYear" both select
+// "Peak Year".
+func columnIndex(headers []string, name string) (int, error) {
+	want := strings.ToLower(templateKey(name))
+	for i, header := range headers {
+		if strings.ToLower(templateKey(header)) == want {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no column %q (available: %s)", name, strings.Join(headers, ", "))
+}
+
+// sortRows returns a copy of rpt with its rows ordered by the named column,
+// keeping highlighted rows highlighted. Numbers and percentages compare by
+// value and everything else as text; missing values always sort last.
+// The sort is stable, so rows with equal values keep the command's order.
+func sortRows(rpt report, column string, desc bool) (report, error) {
+	col, err := columnIndex(rpt.Headers, column)
+	if err != nil {
+		return rpt, fmt.Errorf("--sort: %w", err)
+	}
+
+	order := make([]int, len(rpt.Rows))
+	keys := make([]any, len(rpt.Rows))
+	for i, row := range rpt.Rows {
+		order[i] = i
+		if col < len(row) {
+			keys[i] = cellValue(row[col])
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := keys[order[i]], keys[order[j]]
+		if a == nil || b == nil {
+			return a != nil
+		}
+		cmp := compareCells(a, b)
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	moved := make(map[int]int, len(order))
+	rows := make([][]any, len(order))
+	for to, from := range order {
+		rows[to] = rpt.Rows[from]
+		moved[from] = to
+	}
+	highlight := make([]int, 0, len(rpt.Highlight))
+	for _, idx := range rpt.Highlight {
+		if to, ok := moved[idx]; ok {
+			highlight = append(highlight, to)
+		}
+	}
+	sort.Ints(highlight)
+
+	rpt.Rows = rows
+	rpt.Highlight = highlight
+	return rpt, nil
+}
+
+// compareCells orders two cell values: numbers numerically, then strings
+// case-insensitively, with numbers before text when the kinds differ.
+func compareCells(a, b any) int {
+	af, aNum := cellNumber(a)
+	bf, bNum := cellNumber(b)
+	switch {
+	case aNum && bNum:
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	as, bs := fmt.Sprint(a), fmt.Sprint(b)
+	if cmp := strings.Compare(strings.ToLower(as), strings.ToLower(bs)); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(as, bs)
+}
+
+// cellNumber reports the numeric value of a cell value.
+func cellNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// selectColumns returns a copy of rpt keeping only the named columns, in the
+// order given.
+func selectColumns(rpt report, columns []string) (report, error) {
+	indexes := make([]int, len(columns))
+	headers := make([]string, len(columns))
+	for i, name := range columns {
+		idx, err := columnIndex(rpt.Headers, name)
+		if err != nil {
+			return rpt, fmt.Errorf("--columns: %w", err)
+		}
+		indexes[i] = idx
+		headers[i] = rpt.Headers[idx]
+	}
+
+	rows := make([][]any, len(rpt.Rows))
+	for i, row := range rpt.Rows {
+		selected := make([]any, len(indexes))
+		for j, idx := range indexes {
+			selected[j] = blankCell
+			if idx < len(row) {
+				selected[j] = row[idx]
+			}
+		}
+		rows[i] = selected
+	}
+
+	rpt.Headers = headers
+	rpt.Rows = rows
+	return rpt, nil
+}
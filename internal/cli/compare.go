@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	namesCSV := fs.String("names", "", "comma-separated names to compare (names may also be given as arguments)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	year := fs.Int("year", 0, "year to compare (0 for the latest year in the dataset)")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	namesList := splitNames(*namesCSV)
+	for _, arg := range positional {
+		namesList = append(namesList, splitNames(arg)...)
+	}
+	if len(namesList) < 2 {
+		return errors.New("compare: at least two names are required")
+	}
+	if *year < 0 {
+		return errors.New("compare: --year must be positive")
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("compare: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+
+	compared, comparisons, err := namesdata.CompareNames(records, *gender, *year, namesList)
+	if err != nil {
+		return err
+	}
+
+	leader := 0
+	for i, c := range comparisons {
+		if c.Count > comparisons[leader].Count {
+			leader = i
+		}
+	}
+
+	metadata := map[string]string{
+		"year":  fmt.Sprintf("%d", compared),
+		"names": fmt.Sprintf("%d", len(comparisons)),
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	labels := make([]string, len(comparisons))
+	rows := make([][]any, len(comparisons))
+	for i, c := range comparisons {
+		labels[i] = c.Name
+		rows[i] = []any{
+			c.Name,
+			formatRankCell(c.Rank),
+			c.Count,
+			percentCell(c.Share, 4),
+			formatCountCell(c.PrevCount),
+			formatChangeCell(c.Change()),
+			nil,
+		}
+		if i != leader {
+			rows[i][6] = formatChangeCell(relativeDifference(c.Count, comparisons[leader].Count))
+		}
+	}
+
+	title := fmt.Sprintf("Comparison of %s in %s for %d", strings.Join(labels, ", "), displayLocation, compared)
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   []string{compareSummary(comparisons, leader)},
+		Metadata: metadata,
+		Headers:  []string{"Name", "Rank", "Count", "Share", "Prev Count", "YoY Change", "vs Leader"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
+
+// compareSummary names the most popular name and its margin over the
+// runner-up.
+func compareSummary(comparisons []namesdata.NameComparison, leader int) string {
+	top := comparisons[leader]
+	if top.Count == 0 {
+		return "None of the names appear for the selected filters."
+	}
+
+	runnerUp := -1
+	for i, c := range comparisons {
+		if i != leader && (runnerUp < 0 || c.Count > comparisons[runnerUp].Count) {
+			runnerUp = i
+		}
+	}
+	second := comparisons[runnerUp]
+
+	if second.Count == top.Count {
+		return fmt.Sprintf("Tie: %s and %s both have %d occurrences.", top.Name, second.Name, top.Count)
+	}
+	if second.Count == 0 {
+		return fmt.Sprintf("Winner: %s with %d occurrences; %s does not appear.", top.Name, top.Count, second.Name)
+	}
+	margin, _ := relativeDifference(top.Count, second.Count)
+	return fmt.Sprintf("Winner: %s with %d occurrences, %.1f%% more than %s (%d).", top.Name, top.Count, margin*100, second.Name, second.Count)
+}
+
+// relativeDifference returns (count-base)/base, and false when base is zero.
+func relativeDifference(count, base int) (float64, bool) {
+	if base == 0 {
+		return 0, false
+	}
+	return float64(count-base) / float64(base), true
+}
+
+func formatChangeCell(change float64, ok bool) any {
+	if !ok {
+		return nil
+	}
+	return formatted{value: change, text: fmt.Sprintf("%+.2f%%", change*100)}
+}
+
+func formatCountCell(count int) any {
+	if count == 0 {
+		return nil
+	}
+	return count
+}
+
+// parseInterspersed parses fs while allowing positional arguments between
+// flags, returning the positional arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, asUsageError(err)
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+	"github.com/curtiscovington/ssa-names/internal/visualize"
+)
+
+func (a *App) runConcentration(args []string) error {
+	fs := flag.NewFlagSet("concentration", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	topFlag := fs.String("top", "10,100,1000", "comma-separated name counts to measure the share of births for")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	year := fs.String("year", "", "single year or contiguous range to include, e.g. 1950-2020")
+	plot := fs.Bool("plot", false, "render an ASCII sparkline of the shares")
+	width := fs.Int("width", 80, "plot width when --plot is enabled")
+	height := fs.Int("height", 10, "plot height when --plot is enabled")
+	plotStyle := addPlotStyleFlag(fs)
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("concentration: unexpected argument %q", fs.Arg(0))
+	}
+
+	var cutoffs []int
+	var cutoffLabels []string
+	for _, part := range strings.Split(*topFlag, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 {
+			return fmt.Errorf("concentration: invalid --top value %q", part)
+		}
+		cutoffs = append(cutoffs, n)
+		cutoffLabels = append(cutoffLabels, strconv.Itoa(n))
+	}
+
+	span, err := parseYearSpan(*year)
+	if err != nil {
+		return fmt.Errorf("concentration: --year: %w", err)
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("concentration: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+	style, err := parsePlotStyle(*plotStyle)
+	if err != nil {
+		return fmt.Errorf("concentration: %w", err)
+	}
+
+	filter := namesdata.Filter{State: *state, From: span.From, To: span.To}
+	yearly, err := namesdata.Concentration(a.scopedStream(scope, filter), *gender, cutoffs)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]string{
+		"top":   strings.Join(cutoffLabels, ","),
+		"years": fmt.Sprintf("%d", len(yearly)),
+	}
+	if span != (namesdata.YearRange{}) {
+		metadata["year"] = formatYearSegment(yearly[0].Year, yearly[len(yearly)-1].Year)
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	headers := []string{"Year", "Births", "Names"}
+	for _, n := range cutoffs {
+		headers = append(headers, fmt.Sprintf("Top %d", n))
+	}
+
+	years := make([]int, len(yearly))
+	series := make([]namesdata.TrendSeries, len(cutoffs))
+	for ci, n := range cutoffs {
+		series[ci] = namesdata.TrendSeries{Name: fmt.Sprintf("Top %d", n), Points: make([]namesdata.TrendPoint, len(yearly))}
+	}
+	rows := make([][]any, len(yearly))
+	for i, c := range yearly {
+		years[i] = c.Year
+		row := []any{c.Year, c.Total, c.Names}
+		for ci := range cutoffs {
+			row = append(row, percentCell(c.Share(ci), 2))
+			series[ci].Points[i] = namesdata.TrendPoint{Year: c.Year, Count: c.TopCounts[ci], Present: true, Total: c.Total}
+		}
+		rows[i] = row
+	}
+
+	first, last := yearly[0], yearly[len(yearly)-1]
+	var footer []string
+	if len(yearly) > 1 {
+		for ci, n := range cutoffs {
+			direction := "rose"
+			if last.Share(ci) < first.Share(ci) {
+				direction = "fell"
+			}
+			footer = append(footer, fmt.Sprintf("The top %d names' share %s from %.2f%% in %d to %.2f%% in %d.", n, direction, first.Share(ci)*100, first.Year, last.Share(ci)*100, last.Year))
+		}
+	}
+	if *plot {
+		plotOutput, err := visualize.Sparkline(years, series, nil, "share", *width, *height, visualize.PlotOptions{Color: a.useColor(output), Style: style})
+		if err != nil {
+			return err
+		}
+		if len(footer) > 0 {
+			footer = append(footer, "")
+		}
+		footer = append(footer, strings.Split(strings.TrimRight(plotOutput, "\n"), "\n")...)
+	}
+
+	title := fmt.Sprintf("Share of births captured by the most popular names in %s", displayLocation)
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
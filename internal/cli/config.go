@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// configSections lists the tables a config file may contain, one per
+// command. A command's table overrides the top-level defaults for it.
+var configSections = []string{
+	"top", "generate", "trend", "export.ranks", "export.sqlite", "check", "search",
+	"compare", "neutral", "unisex", "gender", "states", "clusters", "divergence", "distinctive", "peak", "history", "similar", "rank", "movers", "age", "alive", "heatmap", "lifecycle", "debuts", "anomalies", "letters", "endings", "lengths", "concentration", "diversity", "serve",
+	"update-data", "validate", "stats",
+}
+
+// runConfigKeys are top-level keys that configure the run itself rather
+// than a command's flags.
+var runConfigKeys = map[string]bool{"dataset": true, "seed": true, "include-territories": true}
+
+// configEntry is one key = value line of the config file.
+type configEntry struct {
+	Section string
+	Key     string
+	Value   string
+	Line    int
+}
+
+// config holds flag defaults read from a TOML config file. Keys are flag
+// names without dashes. Top-level keys apply to every command that has the
+// flag and are ignored by the rest; keys in a [command] table apply only to
+// that command, must name one of its flags, and override top-level keys.
+type config struct {
+	Path    string
+	Entries []configEntry
+}
+
+// DefaultConfigPath returns $NAMES_CONFIG when it is set, and otherwise
+// names/config.toml under $XDG_CONFIG_HOME, falling back to ~/.config.
+func DefaultConfigPath() string {
+	if path := strings.TrimSpace(os.Getenv("NAMES_CONFIG")); path != "" {
+		return path
+	}
+	dir := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME"))
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "names", "config.toml")
+}
+
+// loadConfig reads the config file at path. A missing file, or an empty
+// path, yields a nil config and no error.
+func loadConfig(path string) (*config, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	defer file.Close()
+	return parseConfig(file, path)
+}
+
+// parseConfig parses the subset of TOML the config file needs: comments,
+// [command] tables, and key = value pairs whose values are strings,
+// numbers, or booleans.
+func parseConfig(r io.Reader, path string) (*config, error) {
+	cfg := &config{Path: path}
+	seen := make(map[string]int)
+	section := ""
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(stripConfigComment(scanner.Text()))
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if strings.HasPrefix(text, "[[") || !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("%s:%d: invalid table header %q", path, line, text)
+			}
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			if !knownConfigSection(section) {
+				return nil, fmt.Errorf("%s:%d: unknown command table [%s] (expected one of %s)", path, line, section, strings.Join(configSections, ", "))
+			}
+			continue
+		}
+
+		rawKey, rawValue, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = value, got %q", path, line, text)
+		}
+		key := strings.TrimSpace(rawKey)
+		if !validConfigKey(key) {
+			return nil, fmt.Errorf("%s:%d: invalid key %q", path, line, key)
+		}
+		value, err := parseConfigValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %w", path, line, key, err)
+		}
+
+		qualified := section + "." + key
+		if previous, ok := seen[qualified]; ok {
+			return nil, fmt.Errorf("%s:%d: %s is already set on line %d", path, line, key, previous)
+		}
+		seen[qualified] = line
+		cfg.Entries = append(cfg.Entries, configEntry{Section: section, Key: key, Value: value, Line: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return cfg, nil
+}
+
+// stripConfigComment drops a trailing # comment, ignoring # inside quoted
+// strings.
+func stripConfigComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func validConfigKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseConfigValue converts a TOML string, number, or boolean to the text
+// a flag would receive on the command line.
+func parseConfigValue(raw string) (string, error) {
+	switch {
+	case raw == "":
+		return "", errors.New("missing value")
+	case strings.HasPrefix(raw, `"`):
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", raw)
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") || strings.Contains(raw[1:len(raw)-1], "'") {
+			return "", fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw == "true" || raw == "false":
+		return raw, nil
+	}
+	number := strings.ReplaceAll(raw, "_", "")
+	if _, err := strconv.ParseFloat(number, 64); err != nil {
+		return "", fmt.Errorf("unsupported value %s (expected a string, number, or boolean)", raw)
+	}
+	return number, nil
+}
+
+func knownConfigSection(section string) bool {
+	for _, known := range configSections {
+		if section == known {
+			return true
+		}
+	}
+	return false
+}
+
+// configSection maps a flag set's name to its config table.
+func configSection(flagSetName string) string {
+	if flagSetName == "names" {
+		return "top"
+	}
+	return strings.ReplaceAll(flagSetName, " ", ".")
+}
+
+// effective returns the entries that apply to section, with the section's
+// own entries replacing top-level ones for the same key. An empty section
+// returns the top-level entries alone.
+func (c *config) effective(section string) []configEntry {
+	if c == nil {
+		return nil
+	}
+	overridden := make(map[string]bool)
+	for _, entry := range c.Entries {
+		if section != "" && entry.Section == section {
+			overridden[entry.Key] = true
+		}
+	}
+	var entries []configEntry
+	for _, entry := range c.Entries {
+		switch {
+		case entry.Section == "" && !overridden[entry.Key]:
+			entries = append(entries, entry)
+		case section != "" && entry.Section == section:
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// applyRunConfig applies the top-level dataset, seed, and
+// include-territories keys before any flags are parsed, so the flags still
+// override them.
+func (a *App) applyRunConfig() error {
+	for _, entry := range a.config.effective("") {
+		switch entry.Key {
+		case "dataset":
+			if err := a.useDataset(entry.Value); err != nil {
+				return fmt.Errorf("%s:%d: %w", a.config.Path, entry.Line, err)
+			}
+		case "seed":
+			seed, err := strconv.ParseInt(entry.Value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s:%d: invalid seed %q", a.config.Path, entry.Line, entry.Value)
+			}
+			a.seed = seed
+		case "include-territories":
+			enabled, err := strconv.ParseBool(entry.Value)
+			if err != nil {
+				return fmt.Errorf("%s:%d: invalid include-territories %q", a.config.Path, entry.Line, entry.Value)
+			}
+			if err := a.useTerritories(enabled); err != nil {
+				return fmt.Errorf("%s:%d: %w", a.config.Path, entry.Line, err)
+			}
+		}
+	}
+	return nil
+}
+
+// applyConfig sets fs's flags to the config file's defaults for its
+// command. The values become the flags' defaults, so fs.Visit still only
+// reports flags given on the command line, and parsing afterwards lets
+// those flags override the config.
+func (a *App) applyConfig(fs *flag.FlagSet) error {
+	section := configSection(fs.Name())
+	for _, entry := range a.config.effective(section) {
+		if entry.Section == "" && runConfigKeys[entry.Key] {
+			continue
+		}
+		f := fs.Lookup(entry.Key)
+		if f == nil {
+			if entry.Section == "" {
+				continue
+			}
+			return fmt.Errorf("%s:%d: %s has no --%s flag", a.config.Path, entry.Line, section, entry.Key)
+		}
+		if err := f.Value.Set(entry.Value); err != nil {
+			return fmt.Errorf("%s:%d: invalid value %q for --%s: %w", a.config.Path, entry.Line, entry.Value, entry.Key, err)
+		}
+		f.DefValue = entry.Value
+	}
+	return nil
+}
+
+// parseFlags applies the config file's defaults to fs and then parses args.
+func (a *App) parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := a.applyConfig(fs); err != nil {
+		return err
+	}
+	err := fs.Parse(args)
+	a.noteFormat(fs)
+	if err != nil {
+		return asUsageError(err)
+	}
+	return a.applyTerritoriesFlag()
+}
+
+// parseInterspersed is parseFlags for commands that take positional
+// arguments between their flags.
+func (a *App) parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	if err := a.applyConfig(fs); err != nil {
+		return nil, err
+	}
+	positional, err := parseInterspersed(fs, args)
+	a.noteFormat(fs)
+	if err != nil {
+		return nil, err
+	}
+	return positional, a.applyTerritoriesFlag()
+}
+
+func (a *App) runConfig(args []string) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return errors.New("config: provide at most one command")
+	}
+	section := ""
+	if len(positional) == 1 {
+		section = configSection(strings.TrimSpace(positional[0]))
+		if !knownConfigSection(section) {
+			return fmt.Errorf("config: unknown command %q", positional[0])
+		}
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	path := a.ConfigPath
+	if a.config != nil {
+		path = a.config.Path
+	}
+	metadata := map[string]string{"path": path}
+
+	var title string
+	switch {
+	case strings.TrimSpace(path) == "":
+		title = "No config file location is available; using built-in defaults."
+	case a.config == nil:
+		title = fmt.Sprintf("No config file at %s; using built-in defaults.", path)
+		metadata["exists"] = "false"
+	default:
+		title = fmt.Sprintf("Configuration from %s", path)
+		metadata["exists"] = "true"
+		if section != "" {
+			title += fmt.Sprintf(" for %s", section)
+		}
+		title += ":"
+	}
+	if section != "" {
+		metadata["command"] = section
+	}
+
+	entries := a.config.effective(section)
+	if section == "" && a.config != nil {
+		entries = a.config.Entries
+	}
+	rows := make([][]any, len(entries))
+	for i, entry := range entries {
+		appliesTo := "all commands"
+		if entry.Section != "" {
+			appliesTo = entry.Section
+		}
+		rows[i] = []any{entry.Key, entry.Value, appliesTo, entry.Line}
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Metadata: metadata,
+		Headers:  []string{"Key", "Value", "Applies To", "Line"},
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
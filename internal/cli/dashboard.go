@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+	"github.com/curtiscovington/ssa-names/internal/visualize"
+)
+
+// dashboardFiles holds the browser UI served at / by serve.
+//
+//go:embed web
+var dashboardFiles embed.FS
+
+var dashboardTemplate = template.Must(template.ParseFS(dashboardFiles, "web/index.html"))
+
+// trendSVGParams are the query parameters /trend.svg accepts.
+var trendSVGParams = []string{"name", "names", "state", "gender", "year", "metric", "split-gender", "width", "height", "theme", "palette", "annotate-peaks", "facet", "facet-shared-y", "scope"}
+
+// maxSVGSize bounds the width and height a /trend.svg client may ask for.
+const maxSVGSize = 4000
+
+// serveDashboard renders the dashboard page with the dataset's states.
+func (a *App) serveDashboard(w http.ResponseWriter, r *http.Request) {
+	states, err := namesdata.States(a.Dataset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	dashboardTemplate.Execute(w, struct {
+		States   []string
+		National bool
+		Version  string
+	}{States: states, National: a.National != nil, Version: strings.TrimSpace(Version)})
+}
+
+// dashboardAssets serves the dashboard's scripts and styles under /static/.
+func dashboardAssets() http.Handler {
+	static, err := fs.Sub(dashboardFiles, "web/static")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/static/", http.FileServerFS(static))
+}
+
+// serveTrendSVG answers GET /trend.svg with the chart trend --svg would
+// write for the same parameters.
+func (a *App) serveTrendSVG(w http.ResponseWriter, r *http.Request) {
+	args, err := queryArgs(r.URL.Query(), trendSVGParams)
+	if err != nil {
+		writeServeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fset := flag.NewFlagSet("trend.svg", flag.ContinueOnError)
+	fset.SetOutput(io.Discard)
+	name := fset.String("name", "", "name to track")
+	namesCSV := fset.String("names", "", "comma-separated list of names to track")
+	state := fset.String("state", "", "optional two-letter state abbreviation")
+	gender := fset.String("gender", "", "filter by gender")
+	yearRange := fset.String("year", "", "single year or contiguous range to include")
+	metric := fset.String("metric", "rank", "metric to chart: rank, count, or share")
+	splitGender := fset.Bool("split-gender", false, "track each name as separate M and F series")
+	width := fset.Int("width", 800, "SVG width in pixels")
+	height := fset.Int("height", 400, "SVG height in pixels")
+	themeName := fset.String("theme", "light", "color theme")
+	paletteCSV := fset.String("palette", "", "comma-separated series colors")
+	annotatePeaks := fset.Bool("annotate-peaks", false, "mark each series' peak")
+	facet := fset.Bool("facet", false, "one panel per name")
+	facetSharedY := fset.Bool("facet-shared-y", false, "same value range in every panel")
+	scopeFlag := addScopeFlag(fset)
+	if err := fset.Parse(args); err != nil {
+		writeServeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	theme, err := chartTheme(*themeName, *paletteCSV)
+	if err != nil {
+		writeServeError(w, http.StatusBadRequest, err)
+		return
+	}
+	opts := visualize.ChartOptions{Theme: theme, AnnotatePeaks: *annotatePeaks, Facet: *facet, SharedScale: *facetSharedY}
+	svg, err := a.trendSVG(*name, *namesCSV, *state, *gender, *yearRange, *metric, *scopeFlag, *splitGender, *width, *height, opts)
+	if err != nil {
+		writeServeError(w, serveStatus(err), err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	io.WriteString(w, svg)
+}
+
+// trendSVG validates the /trend.svg parameters and renders the chart.
+func (a *App) trendSVG(name, namesCSV, state, gender, yearRange, metric, scopeFlag string, splitGender bool, width, height int, opts visualize.ChartOptions) (string, error) {
+	namesList := splitNames(namesCSV)
+	if trimmed := strings.TrimSpace(name); trimmed != "" {
+		namesList = append([]string{trimmed}, namesList...)
+	}
+	if len(namesList) == 0 {
+		return "", errors.New("at least one name or names value is required")
+	}
+	metric = strings.ToLower(strings.TrimSpace(metric))
+	switch metric {
+	case "rank", "count", "share":
+	default:
+		return "", fmt.Errorf("unsupported metric %q (expected rank, count, or share)", metric)
+	}
+	if width < 1 || height < 1 || width > maxSVGSize || height > maxSVGSize {
+		return "", fmt.Errorf("width and height must be between 1 and %d", maxSVGSize)
+	}
+	if splitGender && strings.TrimSpace(gender) != "" {
+		return "", errors.New("split-gender cannot be combined with gender")
+	}
+	scope, err := parseScope(scopeFlag, state)
+	if err != nil {
+		return "", err
+	}
+	span, err := parseYearSpan(yearRange)
+	if err != nil {
+		return "", fmt.Errorf("year: %w", err)
+	}
+
+	records, err := a.scopedRecords(scope, state)
+	if err != nil {
+		return "", err
+	}
+	var (
+		years  []int
+		series []namesdata.TrendSeries
+		totals map[int]int
+	)
+	if splitGender {
+		years, series, totals, err = namesdata.TrendByGender(records, namesList, span)
+	} else {
+		years, series, totals, err = namesdata.Trend(records, gender, namesList, span)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	scopeParts := make([]string, 0, 3)
+	if g := strings.TrimSpace(gender); g != "" {
+		scopeParts = append(scopeParts, strings.ToUpper(g))
+	}
+	if trimmed := strings.TrimSpace(state); trimmed != "" {
+		scopeParts = append(scopeParts, strings.ToUpper(trimmed))
+	} else {
+		scopeParts = append(scopeParts, "National")
+	}
+	if span != (namesdata.YearRange{}) {
+		scopeParts = append(scopeParts, formatYearSegment(years[0], years[len(years)-1]))
+	}
+	return visualize.SVG(years, series, totals, metric, width, height, scopeParts, opts)
+}
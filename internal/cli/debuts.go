@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runDebuts(args []string) error {
+	fs := flag.NewFlagSet("debuts", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	year := fs.Int("year", 0, "year to list debuts for (defaults to the latest year in the dataset)")
+	lookback := fs.Int("lookback", 0, "also count names absent for this many years before --year (0 requires the name never appeared before)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	limit := fs.Int("limit", 20, "maximum number of names to list (0 for all)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("debuts: unexpected argument %q", fs.Arg(0))
+	}
+	if *year < 0 {
+		return errors.New("debuts: --year must be a positive year")
+	}
+	if *lookback < 0 {
+		return errors.New("debuts: --lookback must be 0 or greater")
+	}
+	if *limit < 0 {
+		return errors.New("debuts: --limit must be 0 or greater")
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("debuts: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+
+	target := *year
+	if target == 0 {
+		for _, r := range records {
+			target = max(target, r.Year)
+		}
+	}
+	debuts, err := namesdata.Debuts(records, *gender, target, *lookback)
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, d := range debuts {
+		total += d.Count
+	}
+
+	metadata := map[string]string{
+		"year":   fmt.Sprintf("%d", target),
+		"debuts": fmt.Sprintf("%d", len(debuts)),
+		"births": fmt.Sprintf("%d", total),
+	}
+	if *lookback > 0 {
+		metadata["lookback"] = fmt.Sprintf("%d", *lookback)
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	listed := debuts
+	if *limit > 0 && len(listed) > *limit {
+		listed = listed[:*limit]
+	}
+	rows := make([][]any, len(listed))
+	for i, d := range listed {
+		var previous any
+		if d.PreviousYear != 0 {
+			previous = d.PreviousYear
+		}
+		rows[i] = []any{d.Name, d.Count, d.Rank, previous}
+	}
+
+	title := fmt.Sprintf("Names debuting in %s in %d", displayLocation, target)
+	if *lookback > 0 {
+		title = fmt.Sprintf("Names in %s in %d not seen in the previous %d years", displayLocation, target, *lookback)
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	var footer []string
+	if len(debuts) == 0 {
+		footer = append(footer, "No names debuted that year.")
+	} else {
+		footer = append(footer, fmt.Sprintf("%d names debuted with %d births; the biggest was %s with %d.", len(debuts), total, debuts[0].Name, debuts[0].Count))
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Name", "Count", "Rank", "Last Seen"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
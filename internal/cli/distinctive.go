@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runDistinctive(args []string) error {
+	fs := flag.NewFlagSet("distinctive", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "two-letter state abbreviation to compare with the nation (required)")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	year := fs.String("year", "", "single year or contiguous range to include, e.g. 2015-2019")
+	minCount := fs.Int("min-count", 20, "minimum count in the state for a name to be listed")
+	topN := fs.Int("top", 20, "number of names to list (0 for all)")
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("distinctive: unexpected argument %q", fs.Arg(0))
+	}
+	code := strings.ToUpper(strings.TrimSpace(*state))
+	if code == "" {
+		return errors.New("distinctive: --state is required")
+	}
+	if *minCount < 1 {
+		return errors.New("distinctive: --min-count must be at least 1")
+	}
+	if *topN < 0 {
+		return errors.New("distinctive: --top must be 0 or greater")
+	}
+
+	span, err := parseYearSpan(*year)
+	if err != nil {
+		return fmt.Errorf("distinctive: --year: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	if err := namesdata.ValidateState(a.Dataset, code); err != nil {
+		return err
+	}
+	filter := namesdata.Filter{From: span.From, To: span.To}
+	names, err := namesdata.DistinctiveNames(namesdata.Records(a.Dataset, filter), code, *gender, *minCount)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]string{
+		"state":     code,
+		"min_count": fmt.Sprintf("%d", *minCount),
+		"matches":   fmt.Sprintf("%d", len(names)),
+	}
+	if span != (namesdata.YearRange{}) {
+		metadata["year"] = formatYearSegment(span.From, span.To)
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	shown := names
+	if *topN > 0 && len(shown) > *topN {
+		shown = shown[:*topN]
+	}
+	rows := make([][]any, len(shown))
+	for i, d := range shown {
+		rows[i] = []any{
+			i + 1,
+			d.Name,
+			d.StateCount,
+			percentCell(d.StateShare, 3),
+			d.NationalCount,
+			percentCell(d.NationalShare, 3),
+			formatted{value: d.Lift(), text: fmt.Sprintf("%.2fx", d.Lift())},
+		}
+	}
+
+	title := fmt.Sprintf("Names most distinctive of %s compared with the nation", code)
+	if span != (namesdata.YearRange{}) {
+		title += fmt.Sprintf(" for %s", formatYearSegment(span.From, span.To))
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	footer := []string{
+		fmt.Sprintf("Lift is a name's share of births in %s divided by its national share; names given fewer than %d times in %s are left out.", code, *minCount, code),
+	}
+	if len(shown) > 0 {
+		top := shown[0]
+		footer = append(footer, fmt.Sprintf("%s is %.1f times as common in %s as nationally.", top.Name, top.Lift(), code))
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Rank", "Name", "State Count", "State Share", "National Count", "National Share", "Lift"},
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
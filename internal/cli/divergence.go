@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runDivergence(args []string) error {
+	fs := flag.NewFlagSet("divergence", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	metric := fs.String("metric", "tvd", "score to rank states by: tvd (total variation distance) or js (Jensen-Shannon divergence)")
+	topN := fs.Int("top", 10, "number of states to list (0 for all)")
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("divergence: unexpected argument %q", fs.Arg(0))
+	}
+	metricName := strings.ToLower(strings.TrimSpace(*metric))
+	if metricName != "tvd" && metricName != "js" {
+		return fmt.Errorf("divergence: unsupported --metric %q (expected tvd or js)", *metric)
+	}
+	if *topN < 0 {
+		return errors.New("divergence: --top must be 0 or greater")
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	weight, err := recencyWeight("none", yearFilter, 0)
+	if err != nil {
+		return err
+	}
+	aggregates, err := namesdata.AggregateByState(a.Dataset, *gender, weight)
+	if err != nil {
+		return err
+	}
+	scores, err := namesdata.NationalDivergence(aggregates)
+	if err != nil {
+		return err
+	}
+	if metricName == "js" {
+		sort.SliceStable(scores, func(i, j int) bool {
+			return scores[i].JensenShannon > scores[j].JensenShannon
+		})
+	}
+
+	metadata := map[string]string{
+		"metric": metricName,
+		"states": fmt.Sprintf("%d", len(scores)),
+	}
+	title := "States whose names differ most from the national mix"
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+		title += fmt.Sprintf(" (%s)", metadata["gender"])
+	}
+	title += ":"
+
+	shown := scores
+	if *topN > 0 && len(shown) > *topN {
+		shown = shown[:*topN]
+	}
+	rows := make([][]any, len(shown))
+	for i, d := range shown {
+		var excess any
+		if d.Excess != "" {
+			excess = fmt.Sprintf("%s (+%.2f%%)", d.Excess, d.ExcessShare*100)
+		}
+		rows[i] = []any{
+			i + 1,
+			d.State,
+			d.Total,
+			decimalCell(d.TotalVariation, 4),
+			decimalCell(d.JensenShannon, 4),
+			excess,
+		}
+	}
+
+	footer := []string{
+		"Total variation is the share of a state's births that would need a different name to match the national mix; Jensen-Shannon divergence is in bits. Both run from 0 (identical) to 1.",
+	}
+	if len(scores) > 1 {
+		most, least := scores[0], scores[len(scores)-1]
+		footer = append(footer, fmt.Sprintf("%s is the most unusual state and %s the most typical.", most.State, least.State))
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Rank", "State", "Births", "Total Variation", "Jensen-Shannon", "Most Over-represented"},
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
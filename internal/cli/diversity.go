@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+	"github.com/curtiscovington/ssa-names/internal/visualize"
+)
+
+// diversityMetrics maps each --metric value to its column label and value.
+var diversityMetrics = map[string]struct {
+	label string
+	value func(namesdata.DiversityStats) float64
+}{
+	"entropy":    {"Entropy", func(d namesdata.DiversityStats) float64 { return d.Entropy }},
+	"effective":  {"Effective Names", namesdata.DiversityStats.EffectiveNames},
+	"herfindahl": {"Herfindahl", func(d namesdata.DiversityStats) float64 { return d.Herfindahl }},
+	"gini":       {"Gini", func(d namesdata.DiversityStats) float64 { return d.Gini }},
+}
+
+func (a *App) runDiversity(args []string) error {
+	fs := flag.NewFlagSet("diversity", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	year := fs.String("year", "", "single year or contiguous range to include, e.g. 1950-2020")
+	metric := fs.String("metric", "effective", "metric to plot and summarize: entropy, effective, herfindahl, or gini")
+	plot := fs.Bool("plot", false, "render an ASCII sparkline of the metric over time")
+	width := fs.Int("width", 80, "plot width when --plot is enabled")
+	height := fs.Int("height", 10, "plot height when --plot is enabled")
+	plotStyle := addPlotStyleFlag(fs)
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("diversity: unexpected argument %q", fs.Arg(0))
+	}
+
+	metricName := strings.ToLower(strings.TrimSpace(*metric))
+	selected, ok := diversityMetrics[metricName]
+	if !ok {
+		return fmt.Errorf("diversity: unsupported --metric %q (expected entropy, effective, herfindahl, or gini)", *metric)
+	}
+
+	span, err := parseYearSpan(*year)
+	if err != nil {
+		return fmt.Errorf("diversity: --year: %w", err)
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("diversity: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+	style, err := parsePlotStyle(*plotStyle)
+	if err != nil {
+		return fmt.Errorf("diversity: %w", err)
+	}
+
+	filter := namesdata.Filter{State: *state, From: span.From, To: span.To}
+	yearly, err := namesdata.DiversityByYear(a.scopedStream(scope, filter), *gender)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]string{
+		"metric": metricName,
+		"years":  fmt.Sprintf("%d", len(yearly)),
+	}
+	if span != (namesdata.YearRange{}) {
+		metadata["year"] = formatYearSegment(yearly[0].Year, yearly[len(yearly)-1].Year)
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	years := make([]int, len(yearly))
+	values := make([]float64, len(yearly))
+	rows := make([][]any, len(yearly))
+	for i, d := range yearly {
+		years[i] = d.Year
+		values[i] = selected.value(d)
+		rows[i] = []any{
+			d.Year,
+			d.Total,
+			d.Names,
+			decimalCell(d.Entropy, 3),
+			decimalCell(d.EffectiveNames(), 1),
+			decimalCell(d.Herfindahl, 5),
+			decimalCell(d.Gini, 3),
+		}
+	}
+
+	first, last := yearly[0], yearly[len(yearly)-1]
+	var footer []string
+	if len(yearly) > 1 {
+		from, to := selected.value(first), selected.value(last)
+		direction := "rose"
+		if to < from {
+			direction = "fell"
+		}
+		footer = append(footer, fmt.Sprintf("%s %s from %s in %d to %s in %d (%s).", selected.label, direction,
+			formatDiversity(metricName, from), first.Year, formatDiversity(metricName, to), last.Year, diversityTrend(metricName, from, to)))
+	}
+	footer = append(footer, "Entropy is in bits; Effective Names is the number of equally common names with the same entropy. Herfindahl and Gini rise as births concentrate on fewer names.")
+	if *plot {
+		series := []visualize.ValueSeries{{Label: selected.label, Values: values}}
+		plotOutput, err := visualize.SparklineValues(years, series, metricName, *width, *height, visualize.PlotOptions{Color: a.useColor(output), Style: style})
+		if err != nil {
+			return err
+		}
+		footer = append(footer, "")
+		footer = append(footer, strings.Split(strings.TrimRight(plotOutput, "\n"), "\n")...)
+	}
+
+	title := fmt.Sprintf("Naming diversity in %s", displayLocation)
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Year", "Births", "Names", "Entropy", "Effective Names", "Herfindahl", "Gini"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
+
+// formatDiversity formats a diversity metric value with the precision its
+// column uses.
+func formatDiversity(metric string, v float64) string {
+	switch metric {
+	case "effective":
+		return fmt.Sprintf("%.1f", v)
+	case "herfindahl":
+		return fmt.Sprintf("%.5f", v)
+	}
+	return fmt.Sprintf("%.3f", v)
+}
+
+// diversityTrend describes whether a change in metric means names became
+// more or less diverse.
+func diversityTrend(metric string, from, to float64) string {
+	if math.Abs(to-from) < 1e-12 {
+		return "unchanged diversity"
+	}
+	rising := to > from
+	if metric == "herfindahl" || metric == "gini" {
+		rising = !rising
+	}
+	if rising {
+		return "more diverse"
+	}
+	return "less diverse"
+}
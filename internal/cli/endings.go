@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runEndings(args []string) error {
+	fs := flag.NewFlagSet("endings", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	year := fs.String("year", "", "single year or contiguous range to include, e.g. 1950-2020")
+	length := fs.Int("length", 1, "number of final letters to group names by, e.g. 2 for -ay")
+	endingsCSV := fs.String("endings", "", "comma-separated endings to list, e.g. -n,-a (defaults to the --top most common)")
+	topN := fs.Int("top", 6, "number of most common endings to list when --endings is not set (0 for all)")
+	plot := fs.Bool("plot", false, "render a stacked bar chart of the endings' shares for each year")
+	width := fs.Int("width", 80, "chart width when --plot is enabled")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("endings: unexpected argument %q", fs.Arg(0))
+	}
+	if *length < 1 {
+		return errors.New("endings: --length must be at least 1")
+	}
+	if *topN < 0 {
+		return errors.New("endings: --top must be 0 or greater")
+	}
+	var endings []string
+	for _, ending := range strings.Split(*endingsCSV, ",") {
+		ending = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ending), "-"))
+		if ending == "" {
+			continue
+		}
+		if utf8.RuneCountInString(ending) != *length {
+			return fmt.Errorf("endings: --endings: %q is not %d letters long (set --length to match)", ending, *length)
+		}
+		endings = append(endings, "-"+ending)
+	}
+
+	span, err := parseYearSpan(*year)
+	if err != nil {
+		return fmt.Errorf("endings: --year: %w", err)
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("endings: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	filter := namesdata.Filter{State: *state, From: span.From, To: span.To}
+	groups, err := namesdata.GroupByYear(a.scopedStream(scope, filter), *gender, namesdata.FinalLetters(*length))
+	if err != nil {
+		return err
+	}
+
+	return a.renderGroupTrend(groupTrend{
+		command:  "endings",
+		noun:     "ending",
+		groups:   groups,
+		selected: endings,
+		top:      *topN,
+		state:    *state,
+		gender:   *gender,
+		scope:    scope,
+		span:     span,
+		plot:     *plot,
+		width:    *width,
+	}, output)
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// Exit codes returned by ExitCode, so wrappers can branch on the kind of
+// failure without parsing messages.
+const (
+	ExitOK        = 0
+	ExitFailure   = 1 // any other error, such as unreadable data
+	ExitUsage     = 2 // unknown command, bad flag, or invalid flag value
+	ExitNotFound  = 3 // the requested name is not in the data
+	ExitNoMatches = 4 // the filters matched no records
+	ExitInvalid   = 5 // validate found problems in the dataset
+)
+
+// usageError marks an error caused by how the command was invoked.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// asUsageError wraps a non-nil err as a usageError.
+func asUsageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &usageError{err: err}
+}
+
+// invalidDatasetError reports that validate found problems. The report
+// listing them is the command's output, so HandleError never replaces it.
+type invalidDatasetError struct {
+	problems int
+}
+
+func (e *invalidDatasetError) Error() string {
+	if e.problems == 1 {
+		return "validate: found 1 problem"
+	}
+	return fmt.Sprintf("validate: found %d problems", e.problems)
+}
+
+// errorKind names the category of err as reported in JSON errors.
+func errorKind(err error) string {
+	var notFound *namesdata.NameNotFoundError
+	var usage *usageError
+	var invalid *invalidDatasetError
+	switch {
+	case errors.As(err, &notFound):
+		return "name_not_found"
+	case errors.Is(err, namesdata.ErrNoMatches):
+		return "no_matches"
+	case errors.As(err, &usage):
+		return "usage"
+	case errors.As(err, &invalid):
+		return "invalid_dataset"
+	}
+	return "error"
+}
+
+// ExitCode maps an error returned by Run to the process exit code. A nil
+// error or a -h request exits 0.
+func ExitCode(err error) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	switch errorKind(err) {
+	case "name_not_found":
+		return ExitNotFound
+	case "no_matches":
+		return ExitNoMatches
+	case "usage":
+		return ExitUsage
+	case "invalid_dataset":
+		return ExitInvalid
+	}
+	return ExitFailure
+}
+
+// errorOutput is the JSON shape written for failures under --format json or
+// jsonl.
+type errorOutput struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code        string   `json:"code"`
+	ExitCode    int      `json:"exit_code"`
+	Message     string   `json:"message"`
+	Name        string   `json:"name,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// HandleError reports an error returned by Run and returns the exit code for
+// it. When the failed command asked for JSON or JSON Lines output, the error
+// is written to Stdout as a {"error": {...}} object in place of the
+// document; otherwise the message goes to Stderr.
+func (a *App) HandleError(err error) int {
+	code := ExitCode(err)
+	if code == ExitOK {
+		return code
+	}
+
+	var invalid *invalidDatasetError
+	if format, ferr := parseOutputFormat(a.errorFormat); ferr == nil && (format == formatJSON || format == formatJSONL) && !errors.As(err, &invalid) {
+		detail := errorDetail{Code: errorKind(err), ExitCode: code, Message: err.Error()}
+		var notFound *namesdata.NameNotFoundError
+		if errors.As(err, &notFound) {
+			detail.Name = notFound.Name
+			detail.Suggestions = notFound.Suggestions
+		}
+		data, merr := json.Marshal(errorOutput{Error: detail})
+		if merr == nil {
+			fmt.Fprintln(a.Stdout, string(data))
+			return code
+		}
+	}
+	fmt.Fprintf(a.Stderr, "names: %v\n", err)
+	return code
+}
+
+// noteFormat records the --format value fs was given so HandleError can
+// match it, even when parsing fails part way through.
+func (a *App) noteFormat(fs *flag.FlagSet) {
+	if f := fs.Lookup("format"); f != nil {
+		a.errorFormat = f.Value.String()
+	}
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+	"github.com/curtiscovington/ssa-names/internal/sqlexport"
+)
+
+func (a *App) runExport(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New("export: a target is required (ranks or sqlite)")
+	}
+
+	switch args[0] {
+	case "ranks":
+		return a.runExportRanks(args[1:])
+	case "sqlite":
+		return a.runExportSQLite(args[1:])
+	default:
+		return fmt.Errorf("export: unknown target %q", args[0])
+	}
+}
+
+func (a *App) runExportRanks(args []string) error {
+	fs := flag.NewFlagSet("export ranks", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	from := fs.Int("from", 0, "first year to include (0 for the earliest year)")
+	to := fs.Int("to", 0, "last year to include (0 for the latest year)")
+	minCount := fs.Int("min-count", 1000, "minimum total count across the selected years for a name to be exported")
+	layout := fs.String("layout", "wide", "table layout: wide (one column per year) or long (one row per name and year)")
+	output := addOutputFlags(fs, formatCSV)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if err := validateYearRange(*from, *to); err != nil {
+		return fmt.Errorf("export ranks: %w", err)
+	}
+
+	layoutValue := strings.ToLower(strings.TrimSpace(*layout))
+	if layoutValue != "wide" && layoutValue != "long" {
+		return fmt.Errorf("export ranks: unsupported layout %q (expected wide or long)", *layout)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.loadRecords(*state)
+	if err != nil {
+		return err
+	}
+
+	filtered := filterRecordsByYearRange(records, *from, *to)
+
+	matrix, err := namesdata.YearlyRanks(filtered, *gender, *minCount)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]string{
+		"layout":    layoutValue,
+		"min_count": fmt.Sprintf("%d", *minCount),
+		"names":     fmt.Sprintf("%d", len(matrix.Names)),
+		"year":      formatYearSegment(matrix.Years[0], matrix.Years[len(matrix.Years)-1]),
+	}
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	title := fmt.Sprintf("Yearly ranks for %d names (%s, %s)", len(matrix.Names), metadata["state"], metadata["year"])
+
+	var (
+		headers []string
+		rows    [][]any
+	)
+
+	if layoutValue == "wide" {
+		headers = make([]string, 0, len(matrix.Years)+1)
+		headers = append(headers, "Name")
+		for _, year := range matrix.Years {
+			headers = append(headers, fmt.Sprintf("%d", year))
+		}
+		rows = make([][]any, len(matrix.Names))
+		for i, name := range matrix.Names {
+			row := make([]any, 0, len(headers))
+			row = append(row, name)
+			for _, rank := range matrix.Ranks[i] {
+				row = append(row, formatRankCell(rank))
+			}
+			rows[i] = row
+		}
+	} else {
+		headers = []string{"Name", "Year", "Rank", "Count"}
+		rows = make([][]any, 0, len(matrix.Names)*len(matrix.Years))
+		for i, name := range matrix.Names {
+			for j, year := range matrix.Years {
+				rank := matrix.Ranks[i][j]
+				if rank == 0 {
+					continue
+				}
+				rows = append(rows, []any{
+					name,
+					year,
+					rank,
+					matrix.Counts[i][j],
+				})
+			}
+		}
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
+
+func (a *App) runExportSQLite(args []string) error {
+	fs := flag.NewFlagSet("export sqlite", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	out := fs.String("out", "names.db", "path of the SQLite database to write (replaced if it exists)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	year := fs.String("year", "", "specific year or range to export (comma-separated or range, 0 for all years)")
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	path := strings.TrimSpace(*out)
+	if path == "" {
+		return errors.New("export sqlite: --out is required")
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	metadata := map[string]string{
+		"out": path,
+	}
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+	}
+
+	// The bounds skip whole years early; Contains drops the gaps in a
+	// comma-separated list.
+	from, to := yearFilter.Bounds()
+	records := func(yield func(namesdata.Record, error) bool) {
+		for rec, err := range namesdata.Records(a.Dataset, namesdata.Filter{State: *state, From: from, To: to}) {
+			if err == nil && !yearFilter.Contains(rec.Year) {
+				continue
+			}
+			if !yield(rec, err) {
+				return
+			}
+		}
+	}
+
+	summary, err := sqlexport.Write(path, records, metadata)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(sqlexport.Tables))
+	for i, table := range sqlexport.Tables {
+		rows[i] = []any{table, summary.Rows[table]}
+	}
+
+	rpt := report{
+		Lines:    []string{fmt.Sprintf("Exported %d records to %s:", summary.Rows["records"], path)},
+		Metadata: metadata,
+		Headers:  []string{"Table", "Rows"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
+
+func formatRankCell(rank int) any {
+	if rank == 0 {
+		return nil
+	}
+	return rank
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runGender(args []string) error {
+	fs := flag.NewFlagSet("gender", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || strings.TrimSpace(positional[0]) == "" {
+		return errors.New("gender: exactly one name is required")
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("gender: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+	ratio, err := namesdata.NameGenderRatio(filterRecordsByYear(records, yearFilter), strings.TrimSpace(positional[0]))
+	if err != nil {
+		return err
+	}
+	overall := ratio.Overall
+
+	metadata := map[string]string{
+		"name":         ratio.Name,
+		"female":       fmt.Sprintf("%d", overall.Female),
+		"male":         fmt.Sprintf("%d", overall.Male),
+		"female_share": fmt.Sprintf("%.4f", overall.FemaleShare()),
+	}
+	if ratio.Crossover != 0 {
+		metadata["crossover_year"] = fmt.Sprintf("%d", ratio.Crossover)
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+	}
+
+	rows := make([][]any, len(ratio.Years))
+	for i, split := range ratio.Years {
+		rows[i] = []any{
+			split.Year,
+			split.Female,
+			split.Male,
+			percentCell(split.FemaleShare(), 2),
+			percentCell(1-split.FemaleShare(), 2),
+		}
+	}
+
+	footer := []string{
+		fmt.Sprintf("Overall %d girls and %d boys were named %s: %s.", overall.Female, overall.Male, ratio.Name, genderLean(overall)),
+	}
+	if split, ok := crossoverSplit(ratio); ok {
+		footer = append(footer, fmt.Sprintf("The dominant gender last changed in %d, when %s was %s.", split.Year, ratio.Name, genderLean(split)))
+	} else {
+		footer = append(footer, "The dominant gender never changed.")
+	}
+
+	title := fmt.Sprintf("Gender split of %s by year in %s", ratio.Name, displayLocation)
+	if desc := yearFilter.String(); desc != "" {
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	title += ":"
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Year", "Female", "Male", "Female Share", "Male Share"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
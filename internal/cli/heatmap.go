@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+	"github.com/curtiscovington/ssa-names/internal/visualize"
+)
+
+func (a *App) runHeatmap(args []string) error {
+	fs := flag.NewFlagSet("heatmap", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	nameFlag := fs.String("name", "", "name to chart (may also be given as an argument)")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	year := fs.String("year", "", "single year or contiguous range to include, e.g. 1950-2020")
+	width := fs.Int("width", 80, "terminal heatmap width in characters, including the state labels")
+	svgPath := fs.String("svg", "", "optional file path to write an SVG heatmap")
+	svgWidth := fs.Int("svg-width", 900, "SVG heatmap width in pixels")
+	svgHeight := fs.Int("svg-height", 720, "SVG heatmap height in pixels")
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(*nameFlag)
+	switch {
+	case name == "" && len(positional) == 1:
+		name = strings.TrimSpace(positional[0])
+	case len(positional) > 1 || (name != "" && len(positional) > 0):
+		return errors.New("heatmap: provide exactly one name")
+	}
+	if name == "" {
+		return errors.New("heatmap: a name is required")
+	}
+
+	span, err := parseYearSpan(*year)
+	if err != nil {
+		return fmt.Errorf("heatmap: --year: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	filter := namesdata.Filter{From: span.From, To: span.To}
+	grid, err := namesdata.NameByStateYear(namesdata.Records(a.Dataset, filter), *gender, name)
+	if err != nil {
+		return err
+	}
+
+	first, last := grid.Years[0], grid.Years[len(grid.Years)-1]
+	metadata := map[string]string{
+		"name":   grid.Name,
+		"states": fmt.Sprintf("%d", len(grid.States)),
+		"year":   formatYearSegment(first, last),
+	}
+	var scopeParts []string
+	title := fmt.Sprintf("%s's share of births by state, %s", grid.Name, metadata["year"])
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+		scopeParts = append(scopeParts, metadata["gender"])
+		title += fmt.Sprintf(" (%s)", metadata["gender"])
+	}
+	title += ":"
+
+	// The table summarizes each state; the heatmap below it shows the
+	// full grid.
+	headers := []string{"State", "Count", "Peak Year", "Peak Share"}
+	rows := make([][]any, len(grid.States))
+	for si, state := range grid.States {
+		count, peak := 0, -1
+		for yi := range grid.Years {
+			count += grid.Counts[si][yi]
+			if grid.Counts[si][yi] > 0 && (peak < 0 || grid.Share(si, yi) > grid.Share(si, peak)) {
+				peak = yi
+			}
+		}
+		row := []any{state, count, nil, nil}
+		if peak >= 0 {
+			row[2] = grid.Years[peak]
+			row[3] = percentCell(grid.Share(si, peak), 4)
+		}
+		rows[si] = row
+	}
+
+	heatmap, err := visualize.HeatmapText(grid, *width, a.useColor(output))
+	if err != nil {
+		return err
+	}
+	footer := strings.Split(heatmap, "\n")
+
+	if trimmed := strings.TrimSpace(*svgPath); trimmed != "" {
+		svgOutput, err := visualize.Heatmap(grid, scopeParts, *svgWidth, *svgHeight)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(trimmed, []byte(svgOutput), 0o644); err != nil {
+			return fmt.Errorf("write svg: %w", err)
+		}
+		footer = append(footer, "", fmt.Sprintf("SVG heatmap written to %s", trimmed))
+	}
+
+	return a.render(output, report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	})
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	yearRange := fs.String("year", "", "optional single year or contiguous range to include, e.g. 1990-2020")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || strings.TrimSpace(positional[0]) == "" {
+		return errors.New("history: exactly one name is required")
+	}
+	name := strings.TrimSpace(positional[0])
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	var span namesdata.YearRange
+	if trimmed := strings.TrimSpace(*yearRange); trimmed != "" {
+		if span, err = parseYearSpan(trimmed); err != nil {
+			return fmt.Errorf("history: --year: %w", err)
+		}
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+	years, series, _, err := namesdata.Trend(records, *gender, []string{name}, span)
+	if err != nil {
+		return err
+	}
+	history := series[0]
+
+	var (
+		present        int
+		best, bestYear int
+		rows           = make([][]any, len(years))
+	)
+	for i, point := range history.Points {
+		var rank, count, share, change any
+		if point.Present {
+			present++
+			if best == 0 || point.Rank < best {
+				best, bestYear = point.Rank, point.Year
+			}
+			rank, count = point.Rank, point.Count
+			if point.Total > 0 {
+				share = percentCell(float64(point.Count)/float64(point.Total), 3)
+			}
+			if i > 0 && history.Points[i-1].Present {
+				change = 0
+				if gained := history.Points[i-1].Rank - point.Rank; gained != 0 {
+					change = signedCell(gained)
+				}
+			}
+		}
+		rows[i] = []any{point.Year, rank, count, share, change}
+	}
+	if present == 0 {
+		aggregated, _ := namesdata.AggregateNames(records, 0, *gender)
+		return &namesdata.NameNotFoundError{Name: name, Suggestions: namesdata.ClosestNames(aggregated, name, 3)}
+	}
+	appearance, err := namesdata.Appearances(filterRecordsByYearRange(records, span.From, span.To), *gender, name)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]string{
+		"name":           history.Name,
+		"years_present":  fmt.Sprintf("%d", present),
+		"first_year":     fmt.Sprintf("%d", appearance.First),
+		"last_year":      fmt.Sprintf("%d", appearance.Last),
+		"longest_streak": fmt.Sprintf("%d", appearance.Streak()),
+		"streak_start":   fmt.Sprintf("%d", appearance.StreakStart),
+		"streak_end":     fmt.Sprintf("%d", appearance.StreakEnd),
+		"best_rank":      fmt.Sprintf("%d", best),
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+	if span != (namesdata.YearRange{}) {
+		metadata["year"] = formatYearSegment(years[0], years[len(years)-1])
+	}
+
+	title := fmt.Sprintf("History of %s in %s", history.Name, displayLocation)
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	footer := []string{
+		fmt.Sprintf("%s appears in %d of %d years, first in %d and last in %d; its best rank is #%d in %d.",
+			history.Name, present, len(years), appearance.First, appearance.Last, best, bestYear),
+		fmt.Sprintf("Longest unbroken run: %s.", formatYearSegment(appearance.StreakStart, appearance.StreakEnd)),
+		"Rank Change is the number of places gained since the previous year.",
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Year", "Rank", "Count", "Share", "Rank Change"},
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runLengths(args []string) error {
+	fs := flag.NewFlagSet("lengths", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	year := fs.String("year", "", "single year or contiguous range to include, e.g. 1950-2020")
+	histogram := fs.Bool("histogram", false, "list one row per name length across the selected years instead of one row per year")
+	width := fs.Int("width", 30, "width of the histogram bars in characters")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("lengths: unexpected argument %q", fs.Arg(0))
+	}
+
+	span, err := parseYearSpan(*year)
+	if err != nil {
+		return fmt.Errorf("lengths: --year: %w", err)
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("lengths: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	filter := namesdata.Filter{State: *state, From: span.From, To: span.To}
+	yearly, err := namesdata.NameLengthsByYear(a.scopedStream(scope, filter), *gender)
+	if err != nil {
+		return err
+	}
+	pooled := namesdata.PoolLengths(yearly)
+	first, last := yearly[0], yearly[len(yearly)-1]
+
+	metadata := map[string]string{
+		"years":  fmt.Sprintf("%d", len(yearly)),
+		"births": fmt.Sprintf("%d", pooled.Total),
+		"mean":   fmt.Sprintf("%.2f", pooled.Mean()),
+		"median": fmt.Sprintf("%d", pooled.Median()),
+	}
+	if span != (namesdata.YearRange{}) {
+		metadata["year"] = formatYearSegment(first.Year, last.Year)
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	var (
+		title   string
+		headers []string
+		rows    [][]any
+	)
+	if *histogram {
+		title = fmt.Sprintf("Name lengths in %s, %s", displayLocation, formatYearSegment(first.Year, last.Year))
+		headers = []string{"Letters", "Births", "Share", "Histogram"}
+		largest := 0
+		for _, count := range pooled.Counts {
+			largest = max(largest, count)
+		}
+		// Start at the shortest length given, keeping empty lengths in
+		// between so gaps stay visible.
+		shortest := 0
+		for shortest < len(pooled.Counts) && pooled.Counts[shortest] == 0 {
+			shortest++
+		}
+		for n := shortest; n < len(pooled.Counts); n++ {
+			count := pooled.Counts[n]
+			bar := int(float64(count) / float64(largest) * float64(*width))
+			if bar == 0 && count > 0 {
+				bar = 1
+			}
+			rows = append(rows, []any{
+				n,
+				count,
+				percentCell(float64(count)/float64(pooled.Total), 2),
+				strings.Repeat("█", bar),
+			})
+		}
+	} else {
+		title = fmt.Sprintf("Name lengths by year in %s", displayLocation)
+		headers = []string{"Year", "Births", "Mean", "Median"}
+		rows = make([][]any, len(yearly))
+		for i, d := range yearly {
+			rows[i] = []any{
+				d.Year,
+				d.Total,
+				decimalCell(d.Mean(), 2),
+				d.Median(),
+			}
+		}
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	footer := []string{
+		fmt.Sprintf("Across %s the mean name is %.2f letters long and the median %d.", formatYearSegment(first.Year, last.Year), pooled.Mean(), pooled.Median()),
+	}
+	if len(yearly) > 1 {
+		direction := "longer"
+		if last.Mean() < first.Mean() {
+			direction = "shorter"
+		}
+		footer = append(footer, fmt.Sprintf("Names got %s: the mean went from %.2f letters in %d to %.2f in %d.", direction, first.Mean(), first.Year, last.Mean(), last.Year))
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+	"github.com/curtiscovington/ssa-names/internal/visualize"
+)
+
+func (a *App) runLetters(args []string) error {
+	fs := flag.NewFlagSet("letters", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	year := fs.String("year", "", "single year or contiguous range to include, e.g. 1950-2020")
+	lettersCSV := fs.String("letters", "", "comma-separated initial letters to list (defaults to the --top most common)")
+	topN := fs.Int("top", 6, "number of most common letters to list when --letters is not set (0 for all)")
+	plot := fs.Bool("plot", false, "render a stacked bar chart of the letters' shares for each year")
+	width := fs.Int("width", 80, "chart width when --plot is enabled")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("letters: unexpected argument %q", fs.Arg(0))
+	}
+	if *topN < 0 {
+		return errors.New("letters: --top must be 0 or greater")
+	}
+	var letters []string
+	for _, letter := range strings.Split(*lettersCSV, ",") {
+		letter = strings.ToUpper(strings.TrimSpace(letter))
+		if letter == "" {
+			continue
+		}
+		if namesdata.InitialLetter(letter) != letter {
+			return fmt.Errorf("letters: --letters: %q is not a single letter", letter)
+		}
+		letters = append(letters, letter)
+	}
+
+	span, err := parseYearSpan(*year)
+	if err != nil {
+		return fmt.Errorf("letters: --year: %w", err)
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("letters: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	filter := namesdata.Filter{State: *state, From: span.From, To: span.To}
+	groups, err := namesdata.GroupByYear(a.scopedStream(scope, filter), *gender, namesdata.InitialLetter)
+	if err != nil {
+		return err
+	}
+
+	return a.renderGroupTrend(groupTrend{
+		command:  "letters",
+		noun:     "initial letter",
+		groups:   groups,
+		selected: letters,
+		top:      *topN,
+		state:    *state,
+		gender:   *gender,
+		scope:    scope,
+		span:     span,
+		plot:     *plot,
+		width:    *width,
+	}, output)
+}
+
+// groupTrend describes a report of how births split across the groups of a
+// namesdata.GroupByYear aggregation change over the years.
+type groupTrend struct {
+	command string
+	// noun names what a group key is, e.g. "initial letter".
+	noun   string
+	groups namesdata.YearGroups
+	// selected lists the keys to report; when empty the top largest groups
+	// are reported instead.
+	selected []string
+	top      int
+	state    string
+	gender   string
+	scope    string
+	span     namesdata.YearRange
+	plot     bool
+	width    int
+}
+
+// renderGroupTrend prints one row per year with each reported group's share
+// of births, a footer naming the groups that rose and fell the most, and an
+// optional stacked bar chart.
+func (a *App) renderGroupTrend(t groupTrend, output *outputOptions) error {
+	g := t.groups
+	var columns []int
+	if len(t.selected) > 0 {
+		for _, key := range t.selected {
+			k := g.Index(key)
+			if k < 0 {
+				return fmt.Errorf("%s: no names with %s %q", t.command, t.noun, key)
+			}
+			columns = append(columns, k)
+		}
+	} else {
+		columns = g.Largest(t.top)
+	}
+
+	keys := make([]string, len(columns))
+	for i, k := range columns {
+		keys[i] = g.Keys[k]
+	}
+	metadata := map[string]string{
+		"groups": fmt.Sprintf("%d", len(g.Keys)),
+		"keys":   strings.Join(keys, ","),
+		"years":  fmt.Sprintf("%d", len(g.Years)),
+	}
+	if t.span != (namesdata.YearRange{}) {
+		metadata["year"] = formatYearSegment(g.Years[0], g.Years[len(g.Years)-1])
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(t.state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if t.scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(t.gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	headers := []string{"Year", "Births"}
+	headers = append(headers, keys...)
+	rows := make([][]any, len(g.Years))
+	labels := make([]string, len(g.Years))
+	shares := make([][]float64, len(g.Years))
+	for y, year := range g.Years {
+		labels[y] = fmt.Sprintf("%d", year)
+		row := []any{year, g.Totals[y]}
+		shares[y] = make([]float64, len(columns))
+		for i, k := range columns {
+			shares[y][i] = g.Share(k, y)
+			row = append(row, percentCell(shares[y][i], 2))
+		}
+		rows[y] = row
+	}
+
+	var footer []string
+	if last := len(g.Years) - 1; last > 0 {
+		rise, fall := -1, -1
+		change := func(k int) float64 { return g.Share(k, last) - g.Share(k, 0) }
+		for k := range g.Keys {
+			if rise < 0 || change(k) > change(rise) {
+				rise = k
+			}
+			if fall < 0 || change(k) < change(fall) {
+				fall = k
+			}
+		}
+		for _, k := range []int{rise, fall} {
+			if change(k) == 0 {
+				continue
+			}
+			direction := "rose"
+			if change(k) < 0 {
+				direction = "fell"
+			}
+			footer = append(footer, fmt.Sprintf("%s%s %s %s the most, from %.2f%% of births in %d to %.2f%% in %d.",
+				strings.ToUpper(t.noun[:1]), t.noun[1:], g.Keys[k], direction, g.Share(k, 0)*100, g.Years[0], g.Share(k, last)*100, g.Years[last]))
+		}
+	}
+	if t.plot {
+		chart, err := visualize.StackedBars(labels, keys, shares, t.width, a.useColor(output))
+		if err != nil {
+			return err
+		}
+		if len(footer) > 0 {
+			footer = append(footer, "")
+		}
+		footer = append(footer, strings.Split(chart, "\n")...)
+	}
+
+	title := fmt.Sprintf("Share of births by %s in %s", t.noun, displayLocation)
+	if trimmed := strings.TrimSpace(t.gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
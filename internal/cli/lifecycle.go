@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runLifecycle(args []string) error {
+	fs := flag.NewFlagSet("lifecycle", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	mode := fs.String("mode", "extinct", "names to find: extinct (gone from the data) or comeback (returned to the top ranks)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	gap := fs.Int("gap", 20, "minimum number of years a name must be gone (extinct) or out of the top ranks (comeback)")
+	rankThreshold := fs.Int("rank-threshold", 100, "rank a comeback name must have held and returned to")
+	minCount := fs.Int("min-count", 1000, "minimum total count across all years for a name to be considered")
+	limit := fs.Int("limit", 20, "maximum number of names to list (0 for all)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("lifecycle: unexpected argument %q", fs.Arg(0))
+	}
+
+	modeName := strings.ToLower(strings.TrimSpace(*mode))
+	if modeName != "extinct" && modeName != "comeback" {
+		return fmt.Errorf("lifecycle: unsupported --mode %q (expected extinct or comeback)", *mode)
+	}
+	if *gap < 1 {
+		return errors.New("lifecycle: --gap must be at least 1")
+	}
+	if *rankThreshold < 1 {
+		return errors.New("lifecycle: --rank-threshold must be at least 1")
+	}
+	if *limit < 0 {
+		return errors.New("lifecycle: --limit must be 0 or greater")
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("lifecycle: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+	matrix, err := namesdata.YearlyRanks(records, *gender, *minCount)
+	if err != nil {
+		return err
+	}
+	latest := matrix.Years[len(matrix.Years)-1]
+
+	metadata := map[string]string{
+		"mode":        modeName,
+		"gap":         fmt.Sprintf("%d", *gap),
+		"min_count":   fmt.Sprintf("%d", *minCount),
+		"latest_year": fmt.Sprintf("%d", latest),
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	var (
+		title   string
+		headers []string
+		rows    [][]any
+		footer  []string
+	)
+	switch modeName {
+	case "extinct":
+		extinct := namesdata.ExtinctNames(matrix, *gap)
+		metadata["names"] = fmt.Sprintf("%d", len(extinct))
+		if *limit > 0 && len(extinct) > *limit {
+			extinct = extinct[:*limit]
+		}
+		title = fmt.Sprintf("Names in %s not seen in the %d years through %d", displayLocation, *gap, latest)
+		headers = []string{"Name", "First Year", "Last Year", "Years Gone", "Peak Year", "Peak Rank", "Peak Count"}
+		for _, e := range extinct {
+			rows = append(rows, []any{
+				e.Name,
+				e.FirstYear,
+				e.LastYear,
+				e.YearsGone,
+				e.PeakYear,
+				e.PeakRank,
+				e.PeakCount,
+			})
+		}
+		if len(extinct) == 0 {
+			footer = append(footer, "No names have been missing for that long.")
+		} else {
+			e := extinct[0]
+			footer = append(footer, fmt.Sprintf("%s peaked in %d at #%d with %d occurrences and was last seen in %d.", e.Name, e.PeakYear, e.PeakRank, e.PeakCount, e.LastYear))
+		}
+	case "comeback":
+		metadata["rank_threshold"] = fmt.Sprintf("%d", *rankThreshold)
+		comebacks := namesdata.Comebacks(matrix, *rankThreshold, *gap)
+		metadata["names"] = fmt.Sprintf("%d", len(comebacks))
+		if *limit > 0 && len(comebacks) > *limit {
+			comebacks = comebacks[:*limit]
+		}
+		title = fmt.Sprintf("Names in %s that returned to the top %d after %d or more years", displayLocation, *rankThreshold, *gap)
+		headers = []string{"Name", "Peak Year", "Peak Rank", "Drop Year", "Low Rank", "Return Year", "Return Rank", "Years Out", "Latest Rank"}
+		for _, c := range comebacks {
+			rows = append(rows, []any{
+				c.Name,
+				c.PeakYear,
+				c.PeakRank,
+				c.DropYear,
+				formatRankCell(c.LowRank),
+				c.ReturnYear,
+				c.ReturnRank,
+				c.YearsOut,
+				formatRankCell(c.LatestRank),
+			})
+		}
+		if len(comebacks) == 0 {
+			footer = append(footer, "No names left and returned to the top ranks.")
+		} else {
+			c := comebacks[0]
+			footer = append(footer, fmt.Sprintf("Longest absence: %s left the top %d in %d and returned at #%d in %d, %d years later.", c.Name, *rankThreshold, c.DropYear, c.ReturnRank, c.ReturnYear, c.YearsOut))
+		}
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
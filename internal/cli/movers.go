@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runMovers(args []string) error {
+	fs := flag.NewFlagSet("movers", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	fromFlag := fs.String("from", "", "earlier year or range, e.g. 2010 or 2000-2004 (defaults to the year before --to)")
+	toFlag := fs.String("to", "", "later year or range (defaults to the latest year in the dataset)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	topN := fs.Int("top", 10, "number of names to list in each group (0 for all)")
+	within := fs.Int("within", 1000, "only consider names ranked within this many places in either period (0 for every name)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("movers: unexpected argument %q", fs.Arg(0))
+	}
+	if *topN < 0 {
+		return errors.New("movers: --top must be 0 or greater")
+	}
+	if *within < 0 {
+		return errors.New("movers: --within must be 0 or greater")
+	}
+
+	from, err := parseYearSpan(*fromFlag)
+	if err != nil {
+		return fmt.Errorf("movers: --from: %w", err)
+	}
+	to, err := parseYearSpan(*toFlag)
+	if err != nil {
+		return fmt.Errorf("movers: --to: %w", err)
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("movers: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+
+	if to == (namesdata.YearRange{}) {
+		latest := 0
+		for _, r := range records {
+			latest = max(latest, r.Year)
+		}
+		to = namesdata.YearRange{From: latest, To: latest}
+	}
+	if from == (namesdata.YearRange{}) {
+		from = namesdata.YearRange{From: to.From - 1, To: to.From - 1}
+	}
+	if from.To >= to.From {
+		return errors.New("movers: --from must end before --to begins")
+	}
+
+	moves, err := namesdata.RankDiff(records, *gender, from, to)
+	if err != nil {
+		return err
+	}
+	movers := namesdata.SelectMovers(moves, *within, *topN)
+
+	fromLabel := formatYearSegment(from.From, from.To)
+	toLabel := formatYearSegment(to.From, to.To)
+	metadata := map[string]string{
+		"from":     fromLabel,
+		"to":       toLabel,
+		"gainers":  fmt.Sprintf("%d", len(movers.Gainers)),
+		"losers":   fmt.Sprintf("%d", len(movers.Losers)),
+		"entrants": fmt.Sprintf("%d", len(movers.Entrants)),
+		"dropouts": fmt.Sprintf("%d", len(movers.Dropouts)),
+	}
+	if *within > 0 {
+		metadata["within"] = fmt.Sprintf("%d", *within)
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	groups := []struct {
+		label string
+		moves []namesdata.RankMove
+	}{
+		{"Gainer", movers.Gainers},
+		{"Loser", movers.Losers},
+		{"New", movers.Entrants},
+		{"Dropped", movers.Dropouts},
+	}
+	var rows [][]any
+	for _, group := range groups {
+		for _, m := range group.moves {
+			var change any
+			if !m.Entrant() && !m.Dropout() {
+				change = signedCell(m.Change())
+			}
+			rows = append(rows, []any{
+				group.label,
+				m.Name,
+				formatRankCell(m.FromRank),
+				formatRankCell(m.ToRank),
+				change,
+				formatCountCell(m.FromCount),
+				formatCountCell(m.ToCount),
+			})
+		}
+	}
+
+	title := fmt.Sprintf("Biggest rank movers in %s from %s to %s", displayLocation, fromLabel, toLabel)
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	var footer []string
+	if len(rows) == 0 {
+		footer = append(footer, "No names changed rank between the two periods.")
+	} else if len(movers.Gainers) > 0 {
+		top := movers.Gainers[0]
+		footer = append(footer, fmt.Sprintf("Biggest climb: %s, from #%d to #%d (%+d places).", top.Name, top.FromRank, top.ToRank, top.Change()))
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Movement", "Name", "From Rank", "To Rank", "Change", "From Count", "To Count"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
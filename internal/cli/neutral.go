@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runNeutral(args []string) error {
+	fs := flag.NewFlagSet("neutral", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
+	balance := fs.Float64("balance", 0.4, "minimum share of the less common gender (0.4 keeps names between 40% and 60% female)")
+	minCount := fs.Int("min-count", 100, "minimum total count across both genders")
+	topN := fs.Int("top", 20, "number of names to display")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *balance < 0 || *balance > 0.5 {
+		return errors.New("neutral: --balance must be between 0 and 0.5")
+	}
+	if *topN < 1 {
+		return errors.New("neutral: --top must be 1 or greater")
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("neutral: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+
+	splits := namesdata.AggregateByName(filterRecordsByYear(records, yearFilter), 0)
+	neutral := namesdata.NeutralNames(splits, *balance, *minCount)
+
+	metadata := map[string]string{
+		"balance":   fmt.Sprintf("%.2f", *balance),
+		"min_count": fmt.Sprintf("%d", *minCount),
+		"matches":   fmt.Sprintf("%d", len(neutral)),
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+	}
+
+	headers := []string{"Rank", "Name", "Total", "Female", "Male", "Female Share"}
+	if len(neutral) == 0 {
+		return namesdata.ErrNoMatches
+	}
+
+	shown := neutral
+	if len(shown) > *topN {
+		shown = shown[:*topN]
+	}
+
+	title := fmt.Sprintf("Top %d gender-neutral names (%.0f%%–%.0f%% female) in %s", len(shown), *balance*100, (1-*balance)*100, displayLocation)
+	if desc := yearFilter.String(); desc != "" {
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	title += ":"
+
+	rows := make([][]any, len(shown))
+	for i, split := range shown {
+		rows[i] = []any{
+			i + 1,
+			split.Name,
+			split.Total(),
+			split.Female,
+			split.Male,
+			percentCell(split.FemaleShare(), 2),
+		}
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
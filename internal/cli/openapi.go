@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/visualize"
+)
+
+// serveParam describes a query parameter for the OpenAPI spec.
+type serveParam struct {
+	kind        string // JSON schema type: string, integer, number, or boolean
+	description string
+	enum        []string
+	defaultVal  any
+	// maximum, when positive, is the largest value serveCommand accepts,
+	// so a single request cannot ask for unbounded work.
+	maximum int
+}
+
+// serveParams documents the query parameters for the OpenAPI spec. Every
+// parameter listed in serveEndpoints, streamParams, and trendSVGParams needs
+// an entry.
+var serveParams = map[string]serveParam{
+	"state":          {kind: "string", description: "Two-letter state abbreviation, e.g. CA. Omit for all states."},
+	"year":           {kind: "string", description: "Year filter: a single year, a range such as 1990-2020, or a comma-separated list."},
+	"gender":         {kind: "string", description: "Filter by gender. Omit for both.", enum: []string{"M", "F"}},
+	"top":            {kind: "integer", description: "Number of names to list.", defaultVal: 10, maximum: maxServeTop},
+	"offset":         {kind: "integer", description: "Number of top names to skip before listing.", defaultVal: 0},
+	"page":           {kind: "integer", description: "1-based page of page-size names to list (0 lists from offset).", defaultVal: 0},
+	"page-size":      {kind: "integer", description: "Names per page with page (defaults to top).", defaultVal: 0, maximum: maxServeTop},
+	"by":             {kind: "string", description: "Optional grouping: one row per state, or one column pair per decade.", enum: []string{"state", "decade"}},
+	"scope":          {kind: "string", description: "Dataset to query: the per-state files or the exact SSA national totals.", enum: []string{scopeState, scopeNational}, defaultVal: scopeState},
+	"name":           {kind: "string", description: "Name to look up or track."},
+	"names":          {kind: "string", description: "Comma-separated names to track."},
+	"count":          {kind: "integer", description: "Number of names to generate.", defaultVal: 1, maximum: maxServeCount},
+	"unique":         {kind: "boolean", description: "Draw distinct names (sampling without replacement).", defaultVal: false},
+	"temperature":    {kind: "number", description: "Exponent applied to counts before sampling: below 1 favors rarer names, above 1 the most popular.", defaultVal: 1.0},
+	"pair":           {kind: "boolean", description: "Generate first and middle name pairs.", defaultVal: false},
+	"middle-year":    {kind: "string", description: "Year filter for middle names with pair (defaults to year)."},
+	"seed":           {kind: "integer", description: "RNG seed for reproducible draws; 0 picks one and reports it in the metadata."},
+	"recency":        {kind: "string", description: "Recency weighting across the selected years.", enum: []string{"none", "linear", "exponential"}, defaultVal: "none"},
+	"half-life":      {kind: "number", description: "Years for the weight to halve with recency=exponential.", defaultVal: defaultHalfLife},
+	"starts-with":    {kind: "string", description: "Only draw names starting with this prefix."},
+	"ends-with":      {kind: "string", description: "Only draw names ending with this suffix."},
+	"min-length":     {kind: "integer", description: "Only draw names with at least this many letters (0 for no minimum).", defaultVal: 0},
+	"max-length":     {kind: "integer", description: "Only draw names with at most this many letters (0 for no maximum).", defaultVal: 0},
+	"exclude":        {kind: "string", description: "Comma-separated names never to draw."},
+	"skip-top":       {kind: "integer", description: "Skip this many of the most popular names matching the filters before drawing.", defaultVal: 0},
+	"secure":         {kind: "boolean", description: "Draw from crypto/rand so picks cannot be predicted; cannot be combined with seed.", defaultVal: false},
+	"from":           {kind: "integer", description: "First year to include (0 for the earliest year).", defaultVal: 0},
+	"to":             {kind: "integer", description: "Last year to include (0 for the latest year).", defaultVal: 0},
+	"since":          {kind: "integer", description: "Alias for from."},
+	"until":          {kind: "integer", description: "Alias for to."},
+	"auto-top":       {kind: "integer", description: "Track the N most popular names over the period instead of name or names.", defaultVal: 0, maximum: maxServeAutoTop},
+	"split-gender":   {kind: "boolean", description: "Track each name as separate M and F series (cannot be combined with gender).", defaultVal: false},
+	"forecast":       {kind: "integer", description: "Project each series this many years past the last year.", defaultVal: 0, maximum: maxServeForecast},
+	"forecast-model": {kind: "string", description: "Forecast model.", enum: []string{"linear", "holt"}, defaultVal: "linear"},
+	"rate":           {kind: "number", description: "Names sent per second, at most 1000.", defaultVal: defaultStreamRate},
+	"max-count":      {kind: "integer", description: "Stop after this many names (0 streams until the client disconnects).", defaultVal: 0},
+	"metric":         {kind: "string", description: "Metric to chart.", enum: []string{"rank", "count", "share"}, defaultVal: "rank"},
+	"width":          {kind: "integer", description: "Chart width in pixels, at most 4000.", defaultVal: 800},
+	"height":         {kind: "integer", description: "Chart height in pixels, at most 4000.", defaultVal: 400},
+	"theme":          {kind: "string", description: "Chart color theme.", enum: visualize.ThemeNames(), defaultVal: "light"},
+	"palette":        {kind: "string", description: "Comma-separated #rgb or #rrggbb series colors, replacing the theme's."},
+	"annotate-peaks": {kind: "boolean", description: "Mark each series' peak with its name, year, and value.", defaultVal: false},
+	"facet":          {kind: "boolean", description: "Draw one small panel per name instead of a single chart.", defaultVal: false},
+	"facet-shared-y": {kind: "boolean", description: "Give every facet panel the same value range.", defaultVal: false},
+}
+
+// openAPISpec builds the OpenAPI 3 description of the HTTP API served by
+// Handler, including /metrics when withMetrics is set.
+func openAPISpec(withMetrics bool) map[string]any {
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}},
+			},
+		}
+	}
+
+	paths := make(map[string]any, len(serveEndpoints)+2)
+	for path, endpoint := range serveEndpoints {
+		rowSchema := map[string]any{"type": "object", "additionalProperties": true}
+		if len(endpoint.columns) > 0 {
+			properties := make(map[string]any, len(endpoint.columns))
+			for column, kind := range endpoint.columns {
+				properties[column] = map[string]any{"type": []string{kind, "null"}}
+			}
+			rowSchema["properties"] = properties
+		}
+		response := map[string]any{
+			"allOf": []any{
+				map[string]any{"$ref": "#/components/schemas/Report"},
+				map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"rows": map[string]any{"type": "array", "items": rowSchema},
+					},
+				},
+			},
+		}
+
+		operationID := strings.TrimPrefix(path, "/")
+		paths[path] = map[string]any{
+			"get": map[string]any{
+				"operationId": operationID,
+				"summary":     endpoint.summary,
+				"parameters":  openAPIParams(endpoint.params),
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "The report, in the same shape as the command's --format json output.",
+						"content": map[string]any{
+							"application/json": map[string]any{"schema": response},
+						},
+					},
+					"400": errorResponse("Invalid or unsupported parameters."),
+					"404": errorResponse("No data matches the query."),
+					"500": errorResponse("The dataset could not be read."),
+				},
+			},
+		}
+	}
+	paths["/generate/stream"] = map[string]any{
+		"get": map[string]any{
+			"operationId": "generateStream",
+			"summary":     "Weighted-random names streamed continuously as Server-Sent Events.",
+			"description": "A \"metadata\" event carries the filters and seed, then each \"name\" event carries one draw as {\"pick\", \"name\", \"count\", \"chance\"}. With max-count, a final \"done\" event ends the stream.",
+			"parameters":  openAPIParams(streamParams),
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "The event stream.",
+					"content":     map[string]any{"text/event-stream": map[string]any{"schema": map[string]any{"type": "string"}}},
+				},
+				"400": errorResponse("Invalid or unsupported parameters."),
+				"404": errorResponse("No names match the filters."),
+			},
+		},
+	}
+	paths["/trend.svg"] = map[string]any{
+		"get": map[string]any{
+			"operationId": "trendSVG",
+			"summary":     "An SVG chart of the yearly rank, count, or share of one or more names, as written by trend --svg.",
+			"parameters":  openAPIParams(trendSVGParams),
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "The chart.",
+					"content":     map[string]any{"image/svg+xml": map[string]any{"schema": map[string]any{"type": "string"}}},
+				},
+				"400": errorResponse("Invalid or unsupported parameters."),
+				"404": errorResponse("No data matches the query."),
+			},
+		},
+	}
+	graphQLResponses := map[string]any{
+		"200": map[string]any{
+			"description": "The GraphQL response; query errors are reported in its errors array.",
+			"content":     map[string]any{"application/json": map[string]any{}},
+		},
+		"400": map[string]any{
+			"description": "A missing or malformed query document.",
+			"content":     map[string]any{"application/json": map[string]any{}},
+		},
+	}
+	paths["/graphql"] = map[string]any{
+		"get": map[string]any{
+			"operationId": "graphqlGet",
+			"summary":     "Run a GraphQL query over names, years, states, and aggregates.",
+			"parameters": []any{
+				map[string]any{"name": "query", "in": "query", "required": true, "schema": map[string]any{"type": "string"}, "description": "The GraphQL query document."},
+				map[string]any{"name": "operationName", "in": "query", "schema": map[string]any{"type": "string"}, "description": "The operation to run when the document has several."},
+				map[string]any{"name": "variables", "in": "query", "schema": map[string]any{"type": "string"}, "description": "Query variables as a JSON object."},
+			},
+			"responses": graphQLResponses,
+		},
+		"post": map[string]any{
+			"operationId": "graphqlPost",
+			"summary":     "Run a GraphQL query sent as a JSON body.",
+			"requestBody": map[string]any{
+				"required": true,
+				"content": map[string]any{"application/json": map[string]any{"schema": map[string]any{
+					"type":     "object",
+					"required": []string{"query"},
+					"properties": map[string]any{
+						"query":         map[string]any{"type": "string"},
+						"operationName": map[string]any{"type": "string"},
+						"variables":     map[string]any{"type": "object"},
+					},
+				}}},
+			},
+			"responses": graphQLResponses,
+		},
+	}
+	paths["/openapi.json"] = map[string]any{
+		"get": map[string]any{
+			"operationId": "openapi",
+			"summary":     "This OpenAPI description.",
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "The OpenAPI 3 document.",
+					"content":     map[string]any{"application/json": map[string]any{}},
+				},
+			},
+		},
+	}
+	if withMetrics {
+		paths["/metrics"] = map[string]any{
+			"get": map[string]any{
+				"operationId": "metrics",
+				"summary":     "Prometheus metrics for monitoring the server.",
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "Metrics in the Prometheus text exposition format.",
+						"content":     map[string]any{"text/plain": map[string]any{"schema": map[string]any{"type": "string"}}},
+					},
+				},
+			},
+		}
+	}
+
+	stringList := map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "ssa-names",
+			"version":     strings.TrimSpace(Version),
+			"description": "Query SSA baby name data by state, year, and gender. Each endpoint runs the matching CLI command with the query parameters as its flags.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"Report": map[string]any{
+					"type":     "object",
+					"required": []string{"schema_version", "metadata", "headers", "lines", "rows", "footer"},
+					"properties": map[string]any{
+						"schema_version": map[string]any{"type": "integer", "const": currentSchemaVersion},
+						"metadata": map[string]any{
+							"type":                 "object",
+							"description":          "Filters and context for the report, such as state, year, and gender.",
+							"additionalProperties": map[string]any{"type": "string"},
+						},
+						"headers": stringList,
+						"lines":   stringList,
+						"rows": map[string]any{
+							"type":        "array",
+							"description": "One object per row keyed by header. Counts and ranks are numbers, percentages are fractions, and missing values are null.",
+							"items":       map[string]any{"type": "object"},
+						},
+						"footer": stringList,
+					},
+				},
+				"Error": map[string]any{
+					"type":       "object",
+					"required":   []string{"error"},
+					"properties": map[string]any{"error": map[string]any{"type": "string"}},
+				},
+			},
+		},
+	}
+}
+
+// openAPIParams describes the named query parameters.
+func openAPIParams(names []string) []map[string]any {
+	params := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		param := serveParams[name]
+		schema := map[string]any{"type": param.kind}
+		if len(param.enum) > 0 {
+			schema["enum"] = param.enum
+		}
+		if param.defaultVal != nil {
+			schema["default"] = param.defaultVal
+		}
+		if param.maximum > 0 {
+			schema["maximum"] = param.maximum
+		}
+		params = append(params, map[string]any{
+			"name":        name,
+			"in":          "query",
+			"required":    false,
+			"description": param.description,
+			"schema":      schema,
+		})
+	}
+	return params
+}
+
+// marshalOpenAPI renders the spec as indented JSON.
+func marshalOpenAPI(withMetrics bool) ([]byte, error) {
+	return json.MarshalIndent(openAPISpec(withMetrics), "", "  ")
+}
+
+// serveOpenAPI answers GET /openapi.json.
+func (a *App) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	data, err := marshalOpenAPI(a.Metrics != nil)
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runPeak(args []string) error {
+	fs := flag.NewFlagSet("peak", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	namesCSV := fs.String("names", "", "comma-separated names to report (names may also be given as arguments)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	namesList := splitNames(*namesCSV)
+	for _, arg := range positional {
+		namesList = append(namesList, splitNames(arg)...)
+	}
+	if len(namesList) == 0 {
+		return errors.New("peak: at least one name is required")
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("peak: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+
+	peaks, err := namesdata.PeakMany(records, *gender, namesList)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]string{
+		"names":       fmt.Sprintf("%d", len(peaks)),
+		"latest_year": fmt.Sprintf("%d", peaks[0].LatestYear),
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	labels := make([]string, len(peaks))
+	rows := make([][]any, len(peaks))
+	footer := make([]string, len(peaks))
+	for i, p := range peaks {
+		labels[i] = p.Name
+		rows[i] = []any{
+			p.Name,
+			p.Year,
+			p.Count,
+			p.Rank,
+			formatCountCell(p.LatestCount),
+			formatRankCell(p.LatestRank),
+			formatChangeCell(relativeDifference(p.LatestCount, p.Count)),
+		}
+		footer[i] = peakSummary(p)
+	}
+
+	title := fmt.Sprintf("Peak year for %s in %s", strings.Join(labels, ", "), displayLocation)
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Name", "Peak Year", "Peak Count", "Peak Rank", "Latest Count", "Latest Rank", "Since Peak"},
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
+
+// peakSummary describes where a name stands relative to its peak.
+func peakSummary(p namesdata.NamePeak) string {
+	peak := fmt.Sprintf("%s peaked in %d at #%d with %d occurrences", p.Name, p.Year, p.Rank, p.Count)
+	switch {
+	case p.Year == p.LatestYear:
+		return peak + ", the latest year in the data."
+	case p.LatestCount == 0:
+		return fmt.Sprintf("%s; it does not appear in %d.", peak, p.LatestYear)
+	default:
+		return fmt.Sprintf("%s; in %d it ranks #%d with %d, down %.1f%%.", peak, p.LatestYear, p.LatestRank, p.LatestCount, p.Decline()*100)
+	}
+}
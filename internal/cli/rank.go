@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// maxListedMissing caps how many missing names the rank footer spells out,
+// so a large roster does not bury the table.
+const maxListedMissing = 10
+
+func (a *App) runRank(args []string) error {
+	fs := flag.NewFlagSet("rank", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	namesCSV := fs.String("names", "", "comma-separated names to rank (names may also be given as arguments)")
+	fromStdin := fs.Bool("stdin", false, "read names from standard input, one or more comma-separated per line")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	missing := fs.String("missing", "flag", "names not in the data: flag (keep them, with Found false) or skip (drop them)")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	namesList := splitNames(*namesCSV)
+	for _, arg := range positional {
+		namesList = append(namesList, splitNames(arg)...)
+	}
+	if *fromStdin {
+		var stdin io.Reader = os.Stdin
+		if a.Stdin != nil {
+			stdin = a.Stdin
+		}
+		piped, err := scanNameList(stdin, "stdin")
+		if err != nil {
+			return fmt.Errorf("rank: %w", err)
+		}
+		namesList = append(namesList, piped...)
+	}
+	if len(namesList) == 0 {
+		return errors.New("rank: at least one name is required (as arguments, --names, or --stdin)")
+	}
+
+	skipMissing := false
+	switch strings.ToLower(strings.TrimSpace(*missing)) {
+	case "flag":
+	case "skip":
+		skipMissing = true
+	default:
+		return fmt.Errorf("rank: unsupported --missing %q (expected flag or skip)", *missing)
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("rank: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	trimmedState := strings.TrimSpace(*state)
+	records, err := a.scopedRecords(scope, trimmedState)
+	if err != nil {
+		return err
+	}
+	aggregated, ranks := namesdata.AggregateNames(filterRecordsByYear(records, yearFilter), 0, *gender)
+	found, err := namesdata.RankManyFromAggregate(aggregated, ranks, namesList)
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, entry := range aggregated {
+		total += entry.Count
+	}
+
+	headers := []string{"Name", "Rank", "Count", "Share"}
+	if !skipMissing {
+		headers = append(headers, "Found")
+	}
+	rows := make([][]any, 0, len(namesList))
+	var notFound []string
+	for _, r := range found {
+		if !r.Found() {
+			notFound = append(notFound, r.Query)
+			if !skipMissing {
+				rows = append(rows, []any{r.Query, nil, nil, nil, false})
+			}
+			continue
+		}
+		row := []any{
+			r.Entry.Name,
+			r.Rank,
+			r.Entry.Count,
+			percentCell(float64(r.Entry.Count)/float64(total), 3),
+		}
+		if !skipMissing {
+			row = append(row, true)
+		}
+		rows = append(rows, row)
+	}
+
+	metadata := map[string]string{
+		"names":   fmt.Sprintf("%d", len(namesList)),
+		"found":   fmt.Sprintf("%d", len(namesList)-len(notFound)),
+		"missing": fmt.Sprintf("%d", len(notFound)),
+	}
+	displayLocation := "the United States"
+	if trimmedState != "" {
+		metadata["state"] = strings.ToUpper(trimmedState)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	title := fmt.Sprintf("Ranks of %d names in %s", len(namesList), displayLocation)
+	if desc := yearFilter.String(); desc != "" {
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	var footer []string
+	if len(notFound) > 0 {
+		verb := "are flagged"
+		if skipMissing {
+			verb = "were skipped"
+		}
+		listed := strings.Join(notFound[:min(len(notFound), maxListedMissing)], ", ")
+		if extra := len(notFound) - maxListedMissing; extra > 0 {
+			listed += fmt.Sprintf(", and %d more", extra)
+		}
+		footer = append(footer, fmt.Sprintf("%d of %d names are not in the data for these filters and %s: %s.",
+			len(notFound), len(namesList), verb, listed))
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
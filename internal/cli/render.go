@@ -3,29 +3,184 @@ package cli
 import (
 	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"os"
 	"sort"
 	"strings"
-	"text/tabwriter"
+	"unicode/utf8"
 )
 
 type outputFormat string
 
 const (
-	formatTable outputFormat = "table"
-	formatJSON  outputFormat = "json"
-	formatCSV   outputFormat = "csv"
+	formatTable    outputFormat = "table"
+	formatJSON     outputFormat = "json"
+	formatCSV      outputFormat = "csv"
+	formatTSV      outputFormat = "tsv"
+	formatMarkdown outputFormat = "markdown"
+	formatJSONL    outputFormat = "jsonl"
 )
 
+// JSON schema versions. Version 1 renders every row cell as a string; version
+// 2 renders numbers, percentages, and booleans as typed JSON values.
+const (
+	legacySchemaVersion  = 1
+	currentSchemaVersion = 2
+)
+
+// formatUsage is the help text shared by every command's --format flag.
+const formatUsage = "output format: table, json, jsonl, csv, tsv, or markdown"
+
 func parseOutputFormat(raw string) (outputFormat, error) {
 	value := strings.ToLower(strings.TrimSpace(raw))
 	switch outputFormat(value) {
-	case formatTable, formatJSON, formatCSV:
+	case formatTable, formatJSON, formatJSONL, formatCSV, formatTSV, formatMarkdown:
 		return outputFormat(value), nil
+	case "md":
+		return formatMarkdown, nil
+	case "ndjson":
+		return formatJSONL, nil
 	default:
-		return "", fmt.Errorf("unsupported format %q (expected table, json, or csv)", raw)
+		return "", fmt.Errorf("unsupported format %q (expected table, json, jsonl, csv, tsv, or markdown)", raw)
+	}
+}
+
+// outputOptions holds the flags shared by every command that control how a
+// report is written.
+type outputOptions struct {
+	Format outputFormat
+	// Tidy drops the # comment preamble from CSV output.
+	Tidy bool
+	// MetadataColumns appends each metadata field as a column on every row.
+	MetadataColumns bool
+	// MetadataFile, when set, receives the title, footer, and metadata as JSON.
+	MetadataFile string
+	// SchemaVersion selects the JSON row layout.
+	SchemaVersion int
+	// Color controls ANSI colors in table output.
+	Color colorMode
+	// TableStyle selects the borders and alignment of table output.
+	TableStyle tableStyle
+	// Template, when set, replaces the format with one line per row
+	// rendered through text/template.
+	Template string
+	// Columns, when set, keeps only these columns, in this order.
+	Columns []string
+	// Sort names the column rows are ordered by, descending when Desc is set.
+	Sort string
+	Desc bool
+
+	rawFormat  string
+	rawColumns string
+	rawColor   string
+	rawStyle   string
+	// colorize is resolved from Color and the output stream when rendering.
+	colorize bool
+}
+
+// colorMode selects when table output uses ANSI colors.
+type colorMode string
+
+const (
+	colorAuto   colorMode = "auto"
+	colorAlways colorMode = "always"
+	colorNever  colorMode = "never"
+)
+
+// ANSI escape sequences for table output.
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiHighlight = "\x1b[1;7m"
+)
+
+func parseColorMode(raw string) (colorMode, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	switch colorMode(value) {
+	case colorAuto, colorAlways, colorNever:
+		return colorMode(value), nil
+	default:
+		return "", fmt.Errorf("unsupported color mode %q (expected auto, always, or never)", raw)
+	}
+}
+
+// enabled reports whether output written to w should be colored: always,
+// never, or, for auto, only when w is a terminal and NO_COLOR is unset.
+func (m colorMode) enabled(w io.Writer) bool {
+	switch m {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// addOutputFlags registers the output flags on fs using defaultFormat for
+// --format.
+func addOutputFlags(fs *flag.FlagSet, defaultFormat outputFormat) *outputOptions {
+	opts := &outputOptions{}
+	fs.StringVar(&opts.rawFormat, "format", string(defaultFormat), formatUsage)
+	fs.BoolVar(&opts.Tidy, "tidy", false, "emit CSV as a bare header and rows without # comment lines, and JSON Lines without the metadata line")
+	fs.BoolVar(&opts.MetadataColumns, "metadata-columns", false, "append each metadata field as a column on every row")
+	fs.StringVar(&opts.MetadataFile, "metadata-file", "", "optional path for a JSON sidecar holding the title, footer, and metadata")
+	fs.IntVar(&opts.SchemaVersion, "schema-version", currentSchemaVersion, "JSON schema version: 2 for typed rows, 1 for the legacy all-string rows")
+	fs.StringVar(&opts.rawColor, "color", string(colorAuto), "color table output: auto (when stdout is a terminal), always, or never")
+	fs.StringVar(&opts.rawStyle, "table-style", string(tableStylePlain), "table output style: plain, github, rounded, or unicode (bordered styles right-align numeric columns)")
+	fs.StringVar(&opts.Template, "template", "", "Go text/template rendered once per row instead of --format, e.g. '{{.Name}} ({{.Count}})'")
+	fs.StringVar(&opts.rawColumns, "columns", "", "comma-separated columns to keep, in order, e.g. Name,Count")
+	fs.StringVar(&opts.Sort, "sort", "", "column to order rows by, e.g. count, name, or rank")
+	fs.BoolVar(&opts.Desc, "desc", false, "sort in descending order (with --sort)")
+	return opts
+}
+
+// resolve validates the parsed flag values, reporting problems as usage
+// errors.
+func (o *outputOptions) resolve() error {
+	format, err := parseOutputFormat(o.rawFormat)
+	if err != nil {
+		return asUsageError(err)
+	}
+	o.Format = format
+	o.MetadataFile = strings.TrimSpace(o.MetadataFile)
+	if o.SchemaVersion != legacySchemaVersion && o.SchemaVersion != currentSchemaVersion {
+		return asUsageError(fmt.Errorf("unsupported schema version %d (expected %d or %d)", o.SchemaVersion, legacySchemaVersion, currentSchemaVersion))
+	}
+	color, err := parseColorMode(o.rawColor)
+	if err != nil {
+		return asUsageError(err)
+	}
+	o.Color = color
+	style, err := parseTableStyle(o.rawStyle)
+	if err != nil {
+		return asUsageError(err)
 	}
+	o.TableStyle = style
+	if o.Template != "" {
+		if _, err := parseRowTemplate(o.Template, nil); err != nil {
+			return asUsageError(err)
+		}
+	}
+	o.Columns = parseColumnList(o.rawColumns)
+	if strings.TrimSpace(o.rawColumns) != "" && len(o.Columns) == 0 {
+		return asUsageError(errors.New("--columns: no column names given"))
+	}
+	o.Sort = strings.TrimSpace(o.Sort)
+	if o.Desc && o.Sort == "" {
+		return asUsageError(errors.New("--desc requires --sort"))
+	}
+	return nil
 }
 
 // report holds reusable rendering data for all output formats.
@@ -34,11 +189,67 @@ type report struct {
 	Footer   []string
 	Metadata map[string]string
 	Headers  []string
-	Rows     [][]string
+	// Rows holds each row's cells as typed values; see cellText for the
+	// kinds a cell may hold.
+	Rows [][]any
+	// Highlight lists the indexes of rows to emphasize in colored tables.
+	Highlight []int
 }
 
-func renderReport(w io.Writer, format outputFormat, rpt report) error {
-	switch format {
+// writeTable aligns the headers and rows into columns drawn in style. When
+// colorize is set, the header is bold and highlighted rows are shown in
+// reverse video; the escape codes wrap whole lines after alignment so they
+// never skew widths.
+func writeTable(w io.Writer, rpt report, style tableStyle, colorize bool) error {
+	layout, err := layoutTable(rpt, style)
+	if err != nil {
+		return err
+	}
+
+	highlighted := make(map[int]bool, len(rpt.Highlight))
+	if colorize {
+		for _, idx := range rpt.Highlight {
+			highlighted[layout.firstRow+idx] = true
+		}
+	}
+	for i, line := range layout.lines {
+		switch {
+		case !colorize || line == "":
+		case i == layout.header:
+			line = ansiBold + line + ansiReset
+		case highlighted[i]:
+			line = ansiHighlight + line + ansiReset
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderReport(w io.Writer, opts outputOptions, rpt report) error {
+	if opts.MetadataColumns {
+		rpt = withMetadataColumns(rpt)
+	}
+	if opts.Sort != "" {
+		sorted, err := sortRows(rpt, opts.Sort, opts.Desc)
+		if err != nil {
+			return err
+		}
+		rpt = sorted
+	}
+	if len(opts.Columns) > 0 {
+		selected, err := selectColumns(rpt, opts.Columns)
+		if err != nil {
+			return err
+		}
+		rpt = selected
+	}
+	if opts.Template != "" {
+		return writeTemplate(w, opts.Template, rpt)
+	}
+
+	switch opts.Format {
 	case formatTable:
 		for _, line := range rpt.Lines {
 			fmt.Fprintln(w, line)
@@ -47,14 +258,7 @@ func renderReport(w io.Writer, format outputFormat, rpt report) error {
 			fmt.Fprintln(w)
 		}
 
-		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-		if len(rpt.Headers) > 0 {
-			fmt.Fprintln(tw, strings.Join(rpt.Headers, "\t"))
-		}
-		for _, row := range rpt.Rows {
-			fmt.Fprintln(tw, strings.Join(row, "\t"))
-		}
-		if err := tw.Flush(); err != nil {
+		if err := writeTable(w, rpt, opts.TableStyle, opts.colorize); err != nil {
 			return err
 		}
 
@@ -69,25 +273,18 @@ func renderReport(w io.Writer, format outputFormat, rpt report) error {
 		return nil
 
 	case formatJSON:
-		rows := make([]map[string]string, len(rpt.Rows))
+		rows := make([]map[string]any, len(rpt.Rows))
 		for i, row := range rpt.Rows {
-			entry := make(map[string]string, len(rpt.Headers))
-			for j, header := range rpt.Headers {
-				if j < len(row) {
-					entry[header] = row[j]
-				} else {
-					entry[header] = ""
-				}
-			}
-			rows[i] = entry
+			rows[i] = jsonRow(opts, rpt.Headers, row)
 		}
 
 		payload := map[string]any{
-			"metadata": rpt.Metadata,
-			"headers":  rpt.Headers,
-			"lines":    rpt.Lines,
-			"rows":     rows,
-			"footer":   rpt.Footer,
+			"schema_version": opts.SchemaVersion,
+			"metadata":       rpt.Metadata,
+			"headers":        rpt.Headers,
+			"lines":          rpt.Lines,
+			"rows":           rows,
+			"footer":         rpt.Footer,
 		}
 
 		data, err := json.MarshalIndent(payload, "", "  ")
@@ -97,52 +294,393 @@ func renderReport(w io.Writer, format outputFormat, rpt report) error {
 		_, err = fmt.Fprintln(w, string(data))
 		return err
 
+	case formatJSONL:
+		return writeJSONL(w, opts, rpt)
+
 	case formatCSV:
-		for _, line := range rpt.Lines {
-			if strings.TrimSpace(line) == "" {
-				continue
-			}
-			if _, err := fmt.Fprintf(w, "# %s\n", line); err != nil {
+		if !opts.Tidy {
+			if err := writeCSVPreamble(w, rpt); err != nil {
 				return err
 			}
 		}
 
-		for _, line := range rpt.Footer {
-			if strings.TrimSpace(line) == "" {
-				continue
+		writer := csv.NewWriter(w)
+		if len(rpt.Headers) > 0 {
+			if err := writer.Write(rpt.Headers); err != nil {
+				return err
 			}
-			if _, err := fmt.Fprintf(w, "# %s\n", line); err != nil {
+		}
+		for _, row := range rpt.textRows() {
+			if err := writer.Write(row); err != nil {
 				return err
 			}
 		}
+		writer.Flush()
+		return writer.Error()
 
-		if len(rpt.Metadata) > 0 {
-			keys := make([]string, 0, len(rpt.Metadata))
-			for k := range rpt.Metadata {
-				keys = append(keys, k)
+	case formatTSV:
+		// TSV carries only the header and rows so it can be fed straight
+		// into cut, awk, or a spreadsheet paste.
+		if len(rpt.Headers) > 0 {
+			if _, err := fmt.Fprintln(w, joinTSV(rpt.Headers)); err != nil {
+				return err
 			}
-			sort.Strings(keys)
-			for _, key := range keys {
-				if _, err := fmt.Fprintf(w, "# %s: %s\n", key, rpt.Metadata[key]); err != nil {
-					return err
-				}
+		}
+		for _, row := range rpt.textRows() {
+			if _, err := fmt.Fprintln(w, joinTSV(row)); err != nil {
+				return err
 			}
 		}
+		return nil
 
-		writer := csv.NewWriter(w)
-		if len(rpt.Headers) > 0 {
-			if err := writer.Write(rpt.Headers); err != nil {
-				return err
+	case formatMarkdown:
+		return writeMarkdown(w, rpt)
+	}
+
+	return fmt.Errorf("unknown format %q", opts.Format)
+}
+
+// jsonRow maps each header to its cell in row: the cell's value, or its
+// text when the legacy schema version is selected.
+func jsonRow(opts outputOptions, headers []string, row []any) map[string]any {
+	entry := make(map[string]any, len(headers))
+	for j, header := range headers {
+		var cell any = blankCell
+		if j < len(row) {
+			cell = row[j]
+		}
+		if opts.SchemaVersion == legacySchemaVersion {
+			entry[header] = cellText(cell)
+		} else {
+			entry[header] = cellValue(cell)
+		}
+	}
+	return entry
+}
+
+// writeJSONL renders the report as JSON Lines: a first line holding the
+// schema version, title, footer, headers, and metadata, then one object per
+// row. Each line is written as soon as it is encoded. With --tidy the first
+// line is dropped so every line is a row.
+func writeJSONL(w io.Writer, opts outputOptions, rpt report) error {
+	enc := json.NewEncoder(w)
+	if !opts.Tidy {
+		header := map[string]any{
+			"schema_version": opts.SchemaVersion,
+			"metadata":       rpt.Metadata,
+			"headers":        rpt.Headers,
+			"lines":          rpt.Lines,
+			"footer":         rpt.Footer,
+		}
+		if err := enc.Encode(header); err != nil {
+			return err
+		}
+	}
+	for _, row := range rpt.Rows {
+		if err := enc.Encode(jsonRow(opts, rpt.Headers, row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown renders the report as GitHub-flavored Markdown: title lines
+// as paragraphs, the rows as a pipe table with numeric columns
+// right-aligned, the footer below it, and the metadata as a blockquote list.
+func writeMarkdown(w io.Writer, rpt report) error {
+	var blocks []string
+
+	var title []string
+	for _, line := range rpt.Lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		title = append(title, markdownReplacer.Replace(line))
+	}
+	if len(title) > 0 {
+		blocks = append(blocks, strings.Join(title, "  \n"))
+	}
+
+	if len(rpt.Headers) > 0 {
+		blocks = append(blocks, markdownTable(rpt.Headers, rpt.textRows()))
+	}
+
+	var footer []string
+	for _, line := range rpt.Footer {
+		if strings.TrimSpace(line) == "" {
+			if len(footer) > 0 {
+				blocks = append(blocks, strings.Join(footer, "  \n"))
+				footer = nil
 			}
+			continue
 		}
-		for _, row := range rpt.Rows {
-			if err := writer.Write(row); err != nil {
+		footer = append(footer, markdownReplacer.Replace(line))
+	}
+	if len(footer) > 0 {
+		blocks = append(blocks, strings.Join(footer, "  \n"))
+	}
+
+	if len(rpt.Metadata) > 0 {
+		keys := make([]string, 0, len(rpt.Metadata))
+		for k := range rpt.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = fmt.Sprintf("> - %s: %s", key, markdownReplacer.Replace(rpt.Metadata[key]))
+		}
+		blocks = append(blocks, strings.Join(items, "\n"))
+	}
+
+	if len(blocks) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintln(w, strings.Join(blocks, "\n\n"))
+	return err
+}
+
+// markdownTable renders headers and rows as a padded pipe table. A column
+// is right-aligned when every non-empty cell in it is a number or percentage.
+func markdownTable(headers []string, rows [][]string) string {
+	cell := func(row []string, i int) string {
+		if i < len(row) {
+			return markdownCellReplacer.Replace(row[i])
+		}
+		return ""
+	}
+
+	widths := make([]int, len(headers))
+	numeric := make([]bool, len(headers))
+	for i, header := range headers {
+		widths[i] = max(utf8.RuneCountInString(markdownCellReplacer.Replace(header)), 3)
+		numeric[i] = len(rows) > 0
+		for _, row := range rows {
+			value := cell(row, i)
+			widths[i] = max(widths[i], utf8.RuneCountInString(value))
+			if !numericCell(value) {
+				numeric[i] = false
+			}
+		}
+	}
+
+	pad := func(value string, i int) string {
+		gap := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value))
+		if numeric[i] {
+			return gap + value
+		}
+		return value + gap
+	}
+
+	var b strings.Builder
+	b.WriteString("|")
+	for i, header := range headers {
+		b.WriteString(" " + pad(markdownCellReplacer.Replace(header), i) + " |")
+	}
+	b.WriteString("\n|")
+	for i := range headers {
+		if numeric[i] {
+			b.WriteString(" " + strings.Repeat("-", widths[i]-1) + ": |")
+		} else {
+			b.WriteString(" " + strings.Repeat("-", widths[i]) + " |")
+		}
+	}
+	for _, row := range rows {
+		b.WriteString("\n|")
+		for i := range headers {
+			b.WriteString(" " + pad(cell(row, i), i) + " |")
+		}
+	}
+	return b.String()
+}
+
+var (
+	markdownReplacer     = strings.NewReplacer("\\", "\\\\", "*", "\\*", "_", "\\_", "`", "\\`", "<", "&lt;")
+	markdownCellReplacer = strings.NewReplacer("|", "\\|", "\r", " ", "\n", " ")
+)
+
+// formatted is a cell whose text is not simply its value printed, such as a
+// fraction shown as a percentage. Typed output uses value.
+type formatted struct {
+	value any
+	text  string
+}
+
+func (f formatted) String() string { return f.text }
+
+// blankCell is an empty cell with no value.
+var blankCell = formatted{}
+
+// percentCell shows fraction as a percentage with digits decimals, keeping
+// the unrounded fraction as its value.
+func percentCell(fraction float64, digits int) formatted {
+	return formatted{value: fraction, text: fmt.Sprintf("%.*f%%", digits, fraction*100)}
+}
+
+// decimalCell shows v with digits decimals, keeping the unrounded v as its
+// value.
+func decimalCell(v float64, digits int) formatted {
+	return formatted{value: v, text: fmt.Sprintf("%.*f", digits, v)}
+}
+
+// signedCell shows n with its sign, as in "+3".
+func signedCell(n int) formatted {
+	return formatted{value: n, text: fmt.Sprintf("%+d", n)}
+}
+
+// cellText renders a cell for display. A cell is a string, an integer, a
+// bool, a formatted value, or nil for a missing value, shown as "-".
+func cellText(cell any) string {
+	if cell == nil {
+		return "-"
+	}
+	return fmt.Sprint(cell)
+}
+
+// cellValue returns the value a cell stands for in typed output: nil for a
+// missing or blank cell, the unrounded number behind a formatted one, and
+// the cell itself otherwise.
+func cellValue(cell any) any {
+	if f, ok := cell.(formatted); ok {
+		return f.value
+	}
+	return cell
+}
+
+// textRows returns the report's rows as display text.
+func (r report) textRows() [][]string {
+	rows := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		text := make([]string, len(row))
+		for j, cell := range row {
+			text[j] = cellText(cell)
+		}
+		rows[i] = text
+	}
+	return rows
+}
+
+// isNumeric reports whether s is a plain decimal number such as "-12", "+4",
+// or "3.25". It deliberately rejects forms like "NaN" or "Inf" that
+// strconv.ParseFloat would accept, since those are also valid names.
+func isNumeric(s string) bool {
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
+	}
+	digits, dots := 0, 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.':
+			dots++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && dots <= 1 && !strings.HasPrefix(s, ".") && !strings.HasSuffix(s, ".")
+}
+
+// writeCSVPreamble writes the title, footer, and metadata as # comment lines.
+func writeCSVPreamble(w io.Writer, rpt report) error {
+	for _, line := range rpt.Lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "# %s\n", line); err != nil {
+			return err
+		}
+	}
+
+	for _, line := range rpt.Footer {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "# %s\n", line); err != nil {
+			return err
+		}
+	}
+
+	if len(rpt.Metadata) > 0 {
+		keys := make([]string, 0, len(rpt.Metadata))
+		for k := range rpt.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, err := fmt.Fprintf(w, "# %s: %s\n", key, rpt.Metadata[key]); err != nil {
 				return err
 			}
 		}
-		writer.Flush()
-		return writer.Error()
 	}
 
-	return fmt.Errorf("unknown format %q", format)
+	return nil
+}
+
+// withMetadataColumns returns a copy of rpt with each metadata field, in key
+// order, appended as an extra column on every row.
+func withMetadataColumns(rpt report) report {
+	if len(rpt.Metadata) == 0 {
+		return rpt
+	}
+
+	keys := make([]string, 0, len(rpt.Metadata))
+	for k := range rpt.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	headers := make([]string, 0, len(rpt.Headers)+len(keys))
+	headers = append(headers, rpt.Headers...)
+	headers = append(headers, keys...)
+
+	rows := make([][]any, len(rpt.Rows))
+	for i, row := range rpt.Rows {
+		extended := make([]any, 0, len(headers))
+		extended = append(extended, row...)
+		for len(extended) < len(rpt.Headers) {
+			extended = append(extended, blankCell)
+		}
+		for _, key := range keys {
+			extended = append(extended, rpt.Metadata[key])
+		}
+		rows[i] = extended
+	}
+
+	rpt.Headers = headers
+	rpt.Rows = rows
+	return rpt
+}
+
+// writeMetadataSidecar writes the report's title lines, footer, and metadata
+// to path as JSON, complementing tidy CSV or TSV output on stdout.
+func writeMetadataSidecar(path string, rpt report) error {
+	payload := map[string]any{
+		"metadata": rpt.Metadata,
+		"lines":    rpt.Lines,
+		"footer":   rpt.Footer,
+	}
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write metadata file: %w", err)
+	}
+	return nil
+}
+
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// joinTSV joins cells with tabs, replacing any embedded tabs or line breaks
+// with spaces so each row stays on a single line.
+func joinTSV(cells []string) string {
+	cleaned := make([]string, len(cells))
+	for i, cell := range cells {
+		cleaned[i] = tsvReplacer.Replace(cell)
+	}
+	return strings.Join(cleaned, "\t")
 }
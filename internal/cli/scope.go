@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/curtiscovington/ssa-names/internal/cache"
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+const (
+	scopeState    = "state"
+	scopeNational = "national"
+)
+
+func addScopeFlag(fs *flag.FlagSet) *string {
+	return fs.String("scope", scopeState, "dataset to query: state (per-state files) or national (exact SSA national totals, download-only: run names update-data --national first)")
+}
+
+// parseScope normalizes a --scope value. The national dataset has no
+// per-state breakdown, so it cannot be combined with a state filter.
+func parseScope(raw, state string) (string, error) {
+	scope := strings.ToLower(strings.TrimSpace(raw))
+	switch scope {
+	case "", scopeState:
+		return scopeState, nil
+	case scopeNational:
+		if strings.TrimSpace(state) != "" {
+			return "", errors.New("--scope national cannot be combined with -state")
+		}
+		return scopeNational, nil
+	default:
+		return "", fmt.Errorf("unsupported scope %q (expected state or national)", raw)
+	}
+}
+
+// scopedRecords loads records from the dataset selected by scope.
+func (a *App) scopedRecords(scope, state string) ([]namesdata.Record, error) {
+	if scope != scopeNational {
+		return a.loadRecords(state)
+	}
+	if a.National == nil {
+		return nil, errNationalUnavailable
+	}
+	records, err := namesdata.LoadNationalRecords(a.National)
+	return records, nationalError(err)
+}
+
+// scopedStream streams the records matching filter from the dataset
+// selected by scope.
+func (a *App) scopedStream(scope string, filter namesdata.Filter) iter.Seq2[namesdata.Record, error] {
+	if scope != scopeNational {
+		return namesdata.Records(a.Dataset, filter)
+	}
+	if a.National == nil {
+		return func(yield func(namesdata.Record, error) bool) {
+			yield(namesdata.Record{}, errNationalUnavailable)
+		}
+	}
+	return func(yield func(namesdata.Record, error) bool) {
+		for rec, err := range namesdata.NationalRecords(a.National, filter) {
+			if !yield(rec, nationalError(err)) {
+				return
+			}
+		}
+	}
+}
+
+// yearFilteredStream streams the records of the dataset selected by scope
+// that match state, gender, and years.
+func (a *App) yearFilteredStream(scope, state, gender string, years yearFilter) iter.Seq2[namesdata.Record, error] {
+	filter := namesdata.Filter{State: state, Gender: gender}
+	filter.From, filter.To = years.Bounds()
+	return func(yield func(namesdata.Record, error) bool) {
+		for rec, err := range a.scopedStream(scope, filter) {
+			if err == nil && !years.Contains(rec.Year) {
+				continue
+			}
+			if !yield(rec, err) {
+				return
+			}
+		}
+	}
+}
+
+// scopedAggregate streams weighted name totals from the dataset selected by
+// scope.
+func (a *App) scopedAggregate(scope, state, gender string, weight namesdata.YearWeight) ([]namesdata.NameCount, int, error) {
+	if scope != scopeNational {
+		return namesdata.AggregateFromFSWeighted(a.Dataset, state, gender, weight)
+	}
+	if a.National == nil {
+		return nil, 0, errNationalUnavailable
+	}
+	aggregated, total, err := namesdata.AggregateNationalWeighted(a.National, gender, weight)
+	return aggregated, total, nationalError(err)
+}
+
+// cachedAggregate returns the unweighted name totals for the years in filter
+// from the dataset selected by scope. With a.Cache set, results are kept
+// across runs keyed by scope, state, years, and gender; runs that swap the
+// dataset with --dataset or add the territories bypass the cache. Callers
+// must not modify the returned slice.
+func (a *App) cachedAggregate(scope, state, gender string, filter yearFilter) ([]namesdata.NameCount, int, error) {
+	entry, err := a.cachedEntry(scope, state, gender, filter)
+	return entry.Aggregated, entry.Total, err
+}
+
+// cachedEntry is cachedAggregate returning the whole cache entry, including
+// the sampler generate keeps with the aggregate.
+func (a *App) cachedEntry(scope, state, gender string, filter yearFilter) (cache.Entry, error) {
+	load := func() (cache.Entry, error) {
+		if a.Metrics != nil {
+			defer func(start time.Time) { a.Metrics.ObserveLoad(time.Since(start)) }(time.Now())
+		}
+		weight, err := recencyWeight("none", filter, 0)
+		if err != nil {
+			return cache.Entry{}, err
+		}
+		aggregated, total, err := a.scopedAggregate(scope, state, gender, weight)
+		if err != nil {
+			return cache.Entry{}, err
+		}
+		return cache.Entry{Aggregated: aggregated, Total: total}, nil
+	}
+
+	c := a.sharedCache()
+	if c == nil {
+		return load()
+	}
+	return c.GetOrLoad(aggregateKey(scope, state, gender, filter), load)
+}
+
+// aggregateKey is the cache key of the aggregate for scope, state, gender,
+// and filter. The national dataset has no states, so its entries use the
+// national state code to stay apart from all-state aggregates.
+func aggregateKey(scope, state, gender string, filter yearFilter) cache.Key {
+	if scope == scopeNational {
+		state = namesdata.NationalState
+	}
+	return cache.NewKey(state, filter.String(), gender)
+}
+
+// sharedCache returns a.Cache, or nil when the run swaps the dataset with
+// --dataset or adds the territories, whose aggregates must not be mixed with
+// the default dataset's.
+func (a *App) sharedCache() *cache.Cache {
+	if a.datasetOverridden || a.withTerritories {
+		return nil
+	}
+	return a.Cache
+}
+
+var errNationalUnavailable = errors.New("the national dataset is not available in this build; run names update-data --national to download it")
+
+func nationalError(err error) error {
+	if errors.Is(err, namesdata.ErrNoNationalData) {
+		return fmt.Errorf("%w; run names update-data --national to download them", err)
+	}
+	return err
+}
+
+// NationalDataset returns the national files to query: embedded when it
+// holds any yobYYYY.txt files, and otherwise dir, where update-data
+// --national unpacks them, once that exists. Builds shipped without the SSA
+// national files can so download them instead of being rebuilt.
+func NationalDataset(embedded fs.FS, dir string) fs.FS {
+	if embedded != nil {
+		if _, err := namesdata.NationalYears(embedded); err == nil {
+			return embedded
+		}
+	}
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return os.DirFS(dir)
+	}
+	return embedded
+}
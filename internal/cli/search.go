@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	pattern := fs.String("pattern", "", "case-insensitive wildcard pattern (* matches any run, ? one character)")
+	regex := fs.String("regex", "", "regular expression to match names against")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	limit := fs.Int("limit", 0, "maximum number of matches to display (0 for all)")
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	hasPattern := strings.TrimSpace(*pattern) != ""
+	hasRegex := strings.TrimSpace(*regex) != ""
+	if hasPattern == hasRegex {
+		return errors.New("search: exactly one of --pattern or --regex is required")
+	}
+	if *limit < 0 {
+		return errors.New("search: --limit must be 0 or greater")
+	}
+
+	var (
+		re    *regexp.Regexp
+		query string
+		err   error
+	)
+	if hasPattern {
+		query = strings.TrimSpace(*pattern)
+		re, err = namesdata.CompileWildcard(query)
+	} else {
+		query = *regex
+		re, err = namesdata.CompileRegex(query)
+	}
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	trimmedState := strings.TrimSpace(*state)
+	records, err := a.loadRecords(trimmedState)
+	if err != nil {
+		return err
+	}
+
+	aggregated, _ := namesdata.AggregateNames(filterRecordsByYear(records, yearFilter), 0, *gender)
+	matches := namesdata.SearchNames(aggregated, re)
+
+	metadata := map[string]string{
+		"query":   query,
+		"matches": fmt.Sprintf("%d", len(matches)),
+	}
+	displayLocation := "the United States"
+	if trimmedState != "" {
+		metadata["state"] = strings.ToUpper(trimmedState)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	headers := []string{"Rank", "Name", "Count"}
+	if len(matches) == 0 {
+		return namesdata.ErrNoMatches
+	}
+
+	shown := matches
+	if *limit > 0 && len(shown) > *limit {
+		shown = shown[:*limit]
+	}
+
+	title := fmt.Sprintf("Names matching %q in %s", query, displayLocation)
+	if desc := yearFilter.String(); desc != "" {
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		title += fmt.Sprintf(" (%s)", strings.ToUpper(trimmed))
+	}
+	title += ":"
+
+	rows := make([][]any, len(shown))
+	for i, match := range shown {
+		rows[i] = []any{
+			match.Rank,
+			match.Name,
+			match.Count,
+		}
+	}
+
+	footer := []string(nil)
+	if len(shown) < len(matches) {
+		footer = []string{fmt.Sprintf("Showing %d of %d matches.", len(shown), len(matches))}
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
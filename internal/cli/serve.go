@@ -0,0 +1,317 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	namesv1 "github.com/curtiscovington/ssa-names/api/proto/names/v1"
+	"github.com/curtiscovington/ssa-names/internal/cache"
+	"github.com/curtiscovington/ssa-names/internal/graphqlserver"
+	"github.com/curtiscovington/ssa-names/internal/grpcserver"
+	"github.com/curtiscovington/ssa-names/internal/metrics"
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// defaultServeCacheSize is how many aggregates serve keeps by default.
+const defaultServeCacheSize = 128
+
+// Bounds on the HTTP parameters that size a response, matching the gRPC
+// server's limit on generated names.
+const (
+	maxServeCount    = 10000
+	maxServeTop      = 1000
+	maxServeAutoTop  = 100
+	maxServeForecast = 100
+)
+
+// serveEndpoint maps an HTTP endpoint onto a CLI command. Query parameters
+// are passed through as flags of the same name, limited to params. summary
+// and columns (the JSON type of each fixed row column) feed the OpenAPI spec.
+type serveEndpoint struct {
+	command []string
+	params  []string
+	summary string
+	columns map[string]string
+}
+
+var serveEndpoints = map[string]serveEndpoint{
+	"/top": {
+		command: []string{"top"},
+		params:  []string{"state", "year", "gender", "top", "offset", "page", "page-size", "by", "scope"},
+		summary: "The most popular names.",
+		columns: map[string]string{"Rank": "integer", "Name": "string", "Count": "integer", "Share": "number"},
+	},
+	"/rank": {
+		command: []string{"top"},
+		params:  []string{"name", "state", "year", "gender", "top", "scope"},
+		summary: "The rank of one name; the rank is in metadata.queried_rank.",
+		columns: map[string]string{"Rank": "integer", "Name": "string", "Count": "integer", "Share": "number"},
+	},
+	"/generate": {
+		command: []string{"generate"},
+		params:  []string{"state", "year", "gender", "count", "unique", "temperature", "pair", "middle-year", "seed", "recency", "half-life", "starts-with", "ends-with", "min-length", "max-length", "exclude", "skip-top", "secure", "scope"},
+		summary: "Random names drawn in proportion to their popularity.",
+		columns: map[string]string{"Pick": "integer", "Name": "string", "DatasetCount": "integer", "Chance": "number"},
+	},
+	"/trend": {
+		command: []string{"trend"},
+		params:  []string{"name", "names", "state", "gender", "from", "to", "since", "until", "year", "auto-top", "split-gender", "forecast", "forecast-model", "scope"},
+		summary: "Yearly rank and count of one or more names; each name adds \"<Name> Rank\" and \"<Name> Count\" columns.",
+		columns: map[string]string{"Year": "integer"},
+	},
+}
+
+func (a *App) runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+
+	addr := fs.String("addr", ":8080", "address to listen on for HTTP (empty to disable)")
+	grpcAddr := fs.String("grpc-addr", "", "address to listen on for gRPC, e.g. :9090 (empty to disable)")
+	cacheSize := fs.Int("cache-size", defaultServeCacheSize, "number of name aggregates to keep in memory across requests (0 to disable)")
+	metricsEnabled := fs.Bool("metrics", true, "expose Prometheus metrics at /metrics")
+	openAPI := fs.Bool("openapi", false, "print the OpenAPI 3 spec for the HTTP API and exit")
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *openAPI {
+		data, err := marshalOpenAPI(*metricsEnabled)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(a.Stdout, string(data))
+		return err
+	}
+	if *addr == "" && *grpcAddr == "" {
+		return errors.New("serve: --addr and --grpc-addr cannot both be empty")
+	}
+	if *cacheSize < 0 {
+		return errors.New("serve: --cache-size must be 0 or greater")
+	}
+	if a.Cache == nil && *cacheSize > 0 {
+		a.Cache = cache.New(*cacheSize)
+	}
+	if a.Metrics == nil && *metricsEnabled {
+		a.Metrics = metrics.New(a.Cache)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 2)
+
+	var server *http.Server
+	if *addr != "" {
+		server = newHTTPServer(*addr, a.Handler())
+		go func() {
+			errCh <- server.ListenAndServe()
+		}()
+		fmt.Fprintf(a.Stderr, "Serving on %s\n", *addr)
+	}
+
+	var grpcServer *grpc.Server
+	if *grpcAddr != "" {
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			if server != nil {
+				server.Close()
+			}
+			return fmt.Errorf("serve: %w", err)
+		}
+		grpcServer = grpc.NewServer()
+		namesv1.RegisterNamesServiceServer(grpcServer, grpcserver.New(a.Dataset, a.National))
+		go func() {
+			errCh <- grpcServer.Serve(listener)
+		}()
+		fmt.Fprintf(a.Stderr, "Serving gRPC on %s\n", listener.Addr())
+	}
+
+	var serveErr error
+	select {
+	case err := <-errCh:
+		serveErr = fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	if server != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && serveErr == nil {
+			serveErr = err
+		}
+	}
+	return serveErr
+}
+
+// newHTTPServer returns the server serve runs handler on. Shutdown does not
+// cancel in-flight requests, so request contexts derive from a base context
+// that is cancelled once shutdown starts; otherwise an open
+// /generate/stream without max-count would hold shutdown until it times out.
+func newHTTPServer(addr string, handler http.Handler) *http.Server {
+	base, cancel := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return base },
+	}
+	server.RegisterOnShutdown(cancel)
+	return server
+}
+
+// Handler returns an http.Handler exposing the query commands as JSON
+// endpoints. Each response has the same shape as the command's --format json
+// output; errors are returned as {"error": "..."}. / serves a browser
+// dashboard over the same endpoints, /graphql answers GraphQL queries, and
+// /openapi.json describes the API.
+// When a.Metrics is set, every endpoint is counted and timed and /metrics
+// serves the results.
+func (a *App) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for path, endpoint := range serveEndpoints {
+		var handler http.Handler = a.serveCommand(endpoint)
+		if a.Metrics != nil {
+			handler = a.Metrics.Instrument(path, handler)
+		}
+		mux.Handle("GET "+path, handler)
+	}
+	for path, handler := range map[string]http.HandlerFunc{
+		"/generate/stream": a.serveGenerateStream,
+		"/trend.svg":       a.serveTrendSVG,
+	} {
+		var h http.Handler = handler
+		if a.Metrics != nil {
+			h = a.Metrics.Instrument(path, h)
+		}
+		mux.Handle("GET "+path, h)
+	}
+	gql, err := graphqlserver.New(a.Dataset, a.National, a.sharedCache())
+	if err != nil {
+		// The schema is fixed at compile time, so this is a programming error.
+		panic(err)
+	}
+	var graphqlHandler http.Handler = gql
+	if a.Metrics != nil {
+		graphqlHandler = a.Metrics.Instrument("/graphql", graphqlHandler)
+	}
+	mux.Handle("GET /graphql", graphqlHandler)
+	mux.Handle("POST /graphql", graphqlHandler)
+	mux.HandleFunc("GET /{$}", a.serveDashboard)
+	mux.Handle("GET /static/", dashboardAssets())
+	mux.HandleFunc("GET /openapi.json", a.serveOpenAPI)
+	if a.Metrics != nil {
+		mux.Handle("GET /metrics", a.Metrics.Handler())
+	}
+	return mux
+}
+
+// checkServeLimits rejects a query whose integer parameters exceed their
+// maximum in serveParams. Values that are not integers are left for the
+// command's own flag parsing to report.
+func checkServeLimits(query url.Values) error {
+	for key, values := range query {
+		limit := serveParams[key].maximum
+		if limit <= 0 {
+			continue
+		}
+		for _, value := range values {
+			if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n > limit {
+				return fmt.Errorf("%s must be at most %d", key, limit)
+			}
+		}
+	}
+	return nil
+}
+
+// serveStatus maps an error returned by a command to an HTTP status: 404
+// when nothing matched, 500 when the server's own data could not be read,
+// and 400 for anything wrong with the request.
+func serveStatus(err error) int {
+	var pathErr *fs.PathError
+	switch {
+	case ExitCode(err) == ExitNotFound, ExitCode(err) == ExitNoMatches:
+		return http.StatusNotFound
+	case errors.As(err, &pathErr), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, namesdata.ErrNoNationalData), errors.Is(err, errNationalUnavailable):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
+// queryArgs turns query parameters into --key=value flags in key order,
+// rejecting any parameter not in params.
+func queryArgs(query url.Values, params []string) ([]string, error) {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if !slices.Contains(params, key) {
+			return nil, fmt.Errorf("unsupported parameter %q (expected one of %s)", key, strings.Join(params, ", "))
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var args []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			args = append(args, fmt.Sprintf("--%s=%s", key, value))
+		}
+	}
+	return args, nil
+}
+
+func (a *App) serveCommand(endpoint serveEndpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flags, err := queryArgs(r.URL.Query(), endpoint.params)
+		if err == nil {
+			err = checkServeLimits(r.URL.Query())
+		}
+		if err != nil {
+			writeServeError(w, http.StatusBadRequest, err)
+			return
+		}
+		args := append(append([]string{}, endpoint.command...), flags...)
+		args = append(args, "--format=json")
+
+		// Each request runs on its own App so concurrent requests never
+		// share flag state or RNGs.
+		var stdout bytes.Buffer
+		// The aggregate cache and metrics are safe for concurrent use and
+		// are shared.
+		app := &App{Dataset: a.Dataset, National: a.National, Cache: a.Cache, Metrics: a.Metrics, Stdout: &stdout, Stderr: io.Discard}
+		if err := app.Run(args); err != nil {
+			writeServeError(w, serveStatus(err), err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(stdout.Bytes())
+	}
+}
+
+func writeServeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+	"github.com/curtiscovington/ssa-names/internal/visualize"
+)
+
+// similarPlotted caps how many matches --plot draws next to the query name.
+const similarPlotted = 3
+
+func (a *App) runSimilar(args []string) error {
+	fs := flag.NewFlagSet("similar", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	nameFlag := fs.String("name", "", "name whose popularity curve to match (may also be given as an argument)")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	year := fs.String("year", "", "single year or contiguous range the curves cover, e.g. 1900-1980")
+	method := fs.String("method", "cosine", "curve comparison: cosine (same years) or dtw (allows shifts of a few years)")
+	limit := fs.Int("top", 10, "number of similar names to list")
+	minCount := fs.Int("min-count", 1000, "skip names with fewer total births than this")
+	plot := fs.Bool("plot", false, "render an ASCII sparkline of the name and its closest matches")
+	width := fs.Int("width", 80, "plot width when --plot is enabled")
+	height := fs.Int("height", 10, "plot height when --plot is enabled")
+	plotStyle := addPlotStyleFlag(fs)
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(*nameFlag)
+	switch {
+	case name == "" && len(positional) == 1:
+		name = strings.TrimSpace(positional[0])
+	case len(positional) > 1 || (name != "" && len(positional) > 0):
+		return errors.New("similar: provide exactly one name")
+	}
+	if name == "" {
+		return errors.New("similar: a name is required")
+	}
+	if *limit < 1 {
+		return errors.New("similar: --top must be at least 1")
+	}
+	if *minCount < 0 {
+		return errors.New("similar: --min-count must be 0 or greater")
+	}
+	trajectoryMethod, err := namesdata.ParseTrajectoryMethod(*method)
+	if err != nil {
+		return fmt.Errorf("similar: %w", err)
+	}
+
+	span, err := parseYearSpan(*year)
+	if err != nil {
+		return fmt.Errorf("similar: --year: %w", err)
+	}
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("similar: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+	style, err := parsePlotStyle(*plotStyle)
+	if err != nil {
+		return fmt.Errorf("similar: %w", err)
+	}
+
+	filter := namesdata.Filter{State: *state, From: span.From, To: span.To}
+	idx, err := namesdata.BuildTrajectoryIndex(a.scopedStream(scope, filter), *gender)
+	if err != nil {
+		return err
+	}
+	matches, err := idx.Similar(name, trajectoryMethod, *limit, *minCount)
+	if err != nil {
+		return err
+	}
+	target, _ := idx.Lookup(name)
+
+	years := idx.Years
+	metadata := map[string]string{
+		"name":      target.Name,
+		"method":    string(trajectoryMethod),
+		"peak_year": fmt.Sprintf("%d", target.PeakYear),
+		"year":      formatYearSegment(years[0], years[len(years)-1]),
+		"min_count": fmt.Sprintf("%d", *minCount),
+	}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+	}
+
+	rows := make([][]any, len(matches))
+	for i, m := range matches {
+		rows[i] = []any{
+			i + 1,
+			m.Name,
+			decimalCell(m.Distance, 4),
+			m.PeakYear,
+			m.Total,
+		}
+	}
+
+	title := fmt.Sprintf("Names with a popularity curve like %s in %s, %s", target.Name, displayLocation, metadata["year"])
+	if g, ok := metadata["gender"]; ok {
+		title += fmt.Sprintf(" (%s)", g)
+	}
+	title += ":"
+
+	var footer []string
+	if len(matches) == 0 {
+		footer = append(footer, fmt.Sprintf("No other names have at least %d births for these filters.", *minCount))
+	} else {
+		comparison := "year by year (cosine distance)"
+		if trajectoryMethod == namesdata.TrajectoryDTW {
+			comparison = fmt.Sprintf("allowing shifts of up to %d years (dynamic time warping)", namesdata.DTWWindow)
+		}
+		footer = append(footer, fmt.Sprintf("%s peaked in %d. Curves are each name's share of births scaled to its peak, compared %s; lower distances are more alike.",
+			target.Name, target.PeakYear, comparison))
+	}
+	if *plot {
+		series := []visualize.ValueSeries{{Label: target.Name, Values: target.Curve}}
+		for _, m := range matches[:min(similarPlotted, len(matches))] {
+			series = append(series, visualize.ValueSeries{Label: m.Name, Values: m.Curve})
+		}
+		plotOutput, err := visualize.SparklineValues(years, series, "share of peak", *width, *height, visualize.PlotOptions{Color: a.useColor(output), Style: style})
+		if err != nil {
+			return err
+		}
+		footer = append(footer, "")
+		footer = append(footer, strings.Split(strings.TrimRight(plotOutput, "\n"), "\n")...)
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Position", "Name", "Distance", "Peak Year", "Total"},
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
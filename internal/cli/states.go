@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+	"github.com/curtiscovington/ssa-names/internal/visualize"
+)
+
+func (a *App) runStates(args []string) error {
+	fs := flag.NewFlagSet("states", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	nameFlag := fs.String("name", "", "name to locate (may also be given as an argument)")
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
+	gender := fs.String("gender", "", "filter by gender (M, F, or leave empty for both)")
+	topN := fs.Int("top", 5, "number of states to show from each end of the ranking (0 for every state)")
+	svgPath := fs.String("svg", "", "optional file path to write an SVG map of the name's share by state")
+	svgWidth := fs.Int("svg-width", 720, "SVG map width in pixels")
+	svgHeight := fs.Int("svg-height", 520, "SVG map height in pixels")
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(*nameFlag)
+	switch {
+	case name == "" && len(positional) == 1:
+		name = strings.TrimSpace(positional[0])
+	case len(positional) > 1 || (name != "" && len(positional) > 0):
+		return errors.New("states: provide exactly one name")
+	}
+	if name == "" {
+		return errors.New("states: a name is required")
+	}
+	if *topN < 0 {
+		return errors.New("states: --top must be 0 or greater")
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	weight, err := recencyWeight("none", yearFilter, 0)
+	if err != nil {
+		return err
+	}
+
+	aggregates, err := namesdata.AggregateByState(a.Dataset, *gender, weight)
+	if err != nil {
+		return err
+	}
+
+	shares, absent, err := namesdata.NameByState(aggregates, name)
+	if err != nil {
+		return err
+	}
+
+	displayName := shares[0].Name
+	metadata := map[string]string{
+		"name":   displayName,
+		"states": fmt.Sprintf("%d", len(shares)),
+		"absent": fmt.Sprintf("%d", len(absent)),
+	}
+	title := fmt.Sprintf("Where %s is most popular", displayName)
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	if trimmed := strings.TrimSpace(*gender); trimmed != "" {
+		metadata["gender"] = strings.ToUpper(trimmed)
+		title += fmt.Sprintf(" (%s)", metadata["gender"])
+	}
+	title += ":"
+
+	// Show the top and bottom states, or every state when the two ends
+	// would overlap.
+	positions := make([]int, 0, len(shares))
+	if *topN == 0 || len(shares) <= *topN*2 {
+		for i := range shares {
+			positions = append(positions, i)
+		}
+	} else {
+		metadata["top"] = fmt.Sprintf("%d", *topN)
+		for i := 0; i < *topN; i++ {
+			positions = append(positions, i)
+		}
+		for i := len(shares) - *topN; i < len(shares); i++ {
+			positions = append(positions, i)
+		}
+	}
+
+	headers := []string{"Position", "State", "Rank", "Count", "Share"}
+	rows := make([][]any, len(positions))
+	for i, pos := range positions {
+		share := shares[pos]
+		rows[i] = []any{
+			pos + 1,
+			share.State,
+			share.Rank,
+			share.Count,
+			percentCell(share.Share(), 4),
+		}
+	}
+
+	var footer []string
+	if len(absent) > 0 {
+		footer = append(footer, fmt.Sprintf("Not recorded in: %s", strings.Join(absent, ", ")))
+	}
+
+	if trimmed := strings.TrimSpace(*svgPath); trimmed != "" {
+		var scopeParts []string
+		if desc := yearFilter.String(); desc != "" {
+			scopeParts = append(scopeParts, desc)
+		}
+		if g, ok := metadata["gender"]; ok {
+			scopeParts = append(scopeParts, g)
+		}
+		svgOutput, err := visualize.Choropleth(displayName, shares, scopeParts, *svgWidth, *svgHeight)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(trimmed, []byte(svgOutput), 0o644); err != nil {
+			return fmt.Errorf("write svg: %w", err)
+		}
+		if len(footer) > 0 {
+			footer = append(footer, "")
+		}
+		footer = append(footer, fmt.Sprintf("SVG map written to %s", trimmed))
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
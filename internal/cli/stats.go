@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	info, err := namesdata.Describe(a.Dataset)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+
+	rows := make([][]any, len(info.States))
+	for i, state := range info.States {
+		rows[i] = []any{
+			state.State,
+			state.FirstYear,
+			state.LastYear,
+			state.Records,
+			state.Births,
+		}
+	}
+
+	rpt := report{
+		Lines: []string{fmt.Sprintf("Dataset covering %s across %d states:", formatYearSegment(info.FirstYear, info.LastYear), len(info.States))},
+		Footer: []string{
+			fmt.Sprintf("Total: %d records, %d births.", info.Records, info.Births),
+		},
+		Metadata: map[string]string{
+			"first_year": fmt.Sprintf("%d", info.FirstYear),
+			"last_year":  fmt.Sprintf("%d", info.LastYear),
+			"states":     fmt.Sprintf("%d", len(info.States)),
+			"records":    fmt.Sprintf("%d", info.Records),
+			"births":     fmt.Sprintf("%d", info.Births),
+		},
+		Headers: []string{"State", "First Year", "Last Year", "Records", "Births"},
+		Rows:    rows,
+	}
+	return a.render(output, rpt)
+}
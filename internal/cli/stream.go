@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// streamParams are the query parameters /generate/stream accepts.
+var streamParams = []string{"state", "year", "gender", "seed", "recency", "half-life", "starts-with", "ends-with", "min-length", "max-length", "exclude", "scope", "rate", "max-count"}
+
+const (
+	// defaultStreamRate is how many names per second /generate/stream sends
+	// when rate is not given.
+	defaultStreamRate = 1.0
+	// maxStreamRate bounds the rate a client may ask for.
+	maxStreamRate = 1000.0
+)
+
+// streamedName is the data of each "name" event.
+type streamedName struct {
+	Pick   int     `json:"pick"`
+	Name   string  `json:"name"`
+	Count  int     `json:"count"`
+	Chance float64 `json:"chance"`
+}
+
+// serveGenerateStream answers GET /generate/stream with Server-Sent Events:
+// a "metadata" event describing the filters and seed, then one "name" event
+// per weighted-random draw at the requested rate, and a final "done" event
+// once max-count names have been sent. Without max-count the stream runs
+// until the client disconnects. Invalid parameters get a 400 and filters
+// matching no names a 404, as on the other endpoints.
+func (a *App) serveGenerateStream(w http.ResponseWriter, r *http.Request) {
+	args, err := queryArgs(r.URL.Query(), streamParams)
+	if err != nil {
+		writeServeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Each stream draws from its own App so seeds and RNGs are never shared.
+	app := &App{Dataset: a.Dataset, National: a.National, Cache: a.Cache, Metrics: a.Metrics, Stdout: io.Discard, Stderr: io.Discard}
+	fs := flag.NewFlagSet("generate/stream", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Int64Var(&app.seed, "seed", 0, "RNG seed for a reproducible stream")
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	year := fs.String("year", "", "specific year or range to filter on")
+	gender := fs.String("gender", "", "filter by gender")
+	recency := fs.String("recency", "none", "recency weighting across the selected years: none, linear, or exponential")
+	halfLife := fs.Float64("half-life", defaultHalfLife, "years for the weight to halve with recency=exponential")
+	startsWith := fs.String("starts-with", "", "only draw names starting with this prefix")
+	endsWith := fs.String("ends-with", "", "only draw names ending with this suffix")
+	minLength := fs.Int("min-length", 0, "only draw names with at least this many letters")
+	maxLength := fs.Int("max-length", 0, "only draw names with at most this many letters")
+	exclude := fs.String("exclude", "", "comma-separated names never to draw")
+	scopeFlag := addScopeFlag(fs)
+	rate := fs.Float64("rate", defaultStreamRate, "names per second")
+	maxCount := fs.Int("max-count", 0, "stop after this many names (0 streams until the client disconnects)")
+	if err := fs.Parse(args); err != nil {
+		writeServeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if *rate <= 0 || *rate > maxStreamRate {
+		writeServeError(w, http.StatusBadRequest, fmt.Errorf("rate must be above 0 and at most %g names per second", maxStreamRate))
+		return
+	}
+	if *maxCount < 0 {
+		writeServeError(w, http.StatusBadRequest, errors.New("max-count must be 0 or greater"))
+		return
+	}
+
+	pool, metadata, err := app.streamPool(*state, *year, *gender, *recency, *halfLife, *scopeFlag, namesdata.NameConstraints{
+		StartsWith: *startsWith,
+		EndsWith:   *endsWith,
+		MinLength:  *minLength,
+		MaxLength:  *maxLength,
+		Exclude:    splitNames(*exclude),
+	})
+	if err != nil {
+		writeServeError(w, serveStatus(err), err)
+		return
+	}
+	sampler, err := namesdata.NewNameSamplerWithStrategy(pool, namesdata.SamplerAuto, *maxCount)
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	rng := app.random()
+	metadata["seed"] = fmt.Sprintf("%d", app.seed)
+	metadata["rate"] = fmt.Sprintf("%g", *rate)
+	if *maxCount > 0 {
+		metadata["max_count"] = fmt.Sprintf("%d", *maxCount)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher := http.NewResponseController(w)
+	send := func(event string, id int, data any) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if id > 0 {
+			if _, err := fmt.Fprintf(w, "id: %d\n", id); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+			return err
+		}
+		return flusher.Flush()
+	}
+
+	if err := send("metadata", 0, metadata); err != nil {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+	defer ticker.Stop()
+	for pick := 1; *maxCount == 0 || pick <= *maxCount; pick++ {
+		if pick > 1 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+			}
+		}
+		entry, err := sampler.Pick(rng)
+		if err != nil {
+			return
+		}
+		name := streamedName{Pick: pick, Name: entry.Name, Count: entry.Count, Chance: sampler.Chance(entry)}
+		if err := send("name", pick, name); err != nil {
+			return
+		}
+	}
+	send("done", 0, map[string]int{"count": *maxCount})
+}
+
+// streamPool aggregates the names a stream draws from, applying constraints,
+// and returns them with the stream's metadata.
+func (a *App) streamPool(state, year, gender, recency string, halfLife float64, scopeFlag string, constraints namesdata.NameConstraints) ([]namesdata.NameCount, map[string]string, error) {
+	filters, err := parseDrawFilters(state, year, gender, recency, halfLife, scopeFlag)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := constraints.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := filters.metadata()
+	if !constraints.IsZero() {
+		metadata["constraints"] = constraints.String()
+	}
+	entry, err := a.drawAggregate(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	aggregated, total := entry.Aggregated, entry.Total
+	if !constraints.IsZero() {
+		aggregated, total = constraints.Select(aggregated)
+		if len(aggregated) == 0 {
+			return nil, nil, fmt.Errorf("%w: no names match the constraints (%s)", namesdata.ErrNoMatches, constraints)
+		}
+	}
+	metadata["total_occurrences"] = fmt.Sprintf("%d", total)
+	return aggregated, metadata, nil
+}
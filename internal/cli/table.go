@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// tableStyle selects how --format table draws the headers and rows.
+type tableStyle string
+
+const (
+	// tableStylePlain aligns columns with spaces and no borders.
+	tableStylePlain tableStyle = "plain"
+	// tableStyleGitHub draws a pipe table like --format markdown.
+	tableStyleGitHub tableStyle = "github"
+	// tableStyleRounded and tableStyleUnicode frame the table with box
+	// drawing characters, with rounded or square corners.
+	tableStyleRounded tableStyle = "rounded"
+	tableStyleUnicode tableStyle = "unicode"
+)
+
+func parseTableStyle(raw string) (tableStyle, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	switch tableStyle(value) {
+	case tableStylePlain, tableStyleGitHub, tableStyleRounded, tableStyleUnicode:
+		return tableStyle(value), nil
+	default:
+		return "", fmt.Errorf("unsupported table style %q (expected plain, github, rounded, or unicode)", raw)
+	}
+}
+
+// boxBorders are the characters framing a bordered table: the horizontal
+// and vertical rules, then the left, inner, and right junctions of the top
+// rule, the rule under the header, and the bottom rule.
+type boxBorders struct {
+	horizontal, vertical string
+	top, middle, bottom  [3]string
+}
+
+var (
+	roundedBorders = boxBorders{
+		horizontal: "─", vertical: "│",
+		top:    [3]string{"╭", "┬", "╮"},
+		middle: [3]string{"├", "┼", "┤"},
+		bottom: [3]string{"╰", "┴", "╯"},
+	}
+	unicodeBorders = boxBorders{
+		horizontal: "─", vertical: "│",
+		top:    [3]string{"┌", "┬", "┐"},
+		middle: [3]string{"├", "┼", "┤"},
+		bottom: [3]string{"└", "┴", "┘"},
+	}
+)
+
+// tableLayout is a table drawn as lines. header is the index of the header
+// line, or -1 without headers, and firstRow the index of the first row;
+// every row takes one line, so row i is at firstRow+i.
+type tableLayout struct {
+	lines    []string
+	header   int
+	firstRow int
+}
+
+// layoutTable draws the headers and rows of rpt in style. Every style but
+// plain right-aligns the columns whose cells are all numbers or
+// percentages.
+func layoutTable(rpt report, style tableStyle) (tableLayout, error) {
+	if len(rpt.Headers) == 0 || style == tableStylePlain || style == "" {
+		return plainTable(rpt)
+	}
+	if style == tableStyleGitHub {
+		lines := strings.Split(markdownTable(rpt.Headers, rpt.textRows()), "\n")
+		return tableLayout{lines: lines, header: 0, firstRow: 2}, nil
+	}
+	borders := roundedBorders
+	if style == tableStyleUnicode {
+		borders = unicodeBorders
+	}
+	return boxTable(rpt.Headers, rpt.textRows(), borders), nil
+}
+
+// plainTable aligns the columns with tabwriter.
+func plainTable(rpt report) (tableLayout, error) {
+	var buf strings.Builder
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	if len(rpt.Headers) > 0 {
+		fmt.Fprintln(tw, strings.Join(rpt.Headers, "\t"))
+	}
+	for _, row := range rpt.textRows() {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return tableLayout{}, err
+	}
+
+	layout := tableLayout{header: -1}
+	if len(rpt.Headers) > 0 {
+		layout.header, layout.firstRow = 0, 1
+	}
+	if buf.Len() > 0 {
+		layout.lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	}
+	return layout, nil
+}
+
+// boxTable frames headers and rows with borders, a rule under the header,
+// and one space of padding inside every cell.
+func boxTable(headers []string, rows [][]string, b boxBorders) tableLayout {
+	widths := make([]int, len(headers))
+	numeric := make([]bool, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+		numeric[i] = len(rows) > 0
+		for _, row := range rows {
+			value := cellAt(row, i)
+			widths[i] = max(widths[i], utf8.RuneCountInString(value))
+			if !numericCell(value) {
+				numeric[i] = false
+			}
+		}
+	}
+
+	rule := func(junctions [3]string) string {
+		segments := make([]string, len(widths))
+		for i, width := range widths {
+			segments[i] = strings.Repeat(b.horizontal, width+2)
+		}
+		return junctions[0] + strings.Join(segments, junctions[1]) + junctions[2]
+	}
+	line := func(cells []string) string {
+		var sb strings.Builder
+		sb.WriteString(b.vertical)
+		for i, width := range widths {
+			value := cellAt(cells, i)
+			gap := strings.Repeat(" ", width-utf8.RuneCountInString(value))
+			if numeric[i] {
+				value = gap + value
+			} else {
+				value += gap
+			}
+			sb.WriteString(" " + value + " " + b.vertical)
+		}
+		return sb.String()
+	}
+
+	lines := make([]string, 0, len(rows)+4)
+	lines = append(lines, rule(b.top), line(headers), rule(b.middle))
+	for _, row := range rows {
+		lines = append(lines, line(row))
+	}
+	lines = append(lines, rule(b.bottom))
+	return tableLayout{lines: lines, header: 1, firstRow: 3}
+}
+
+// cellAt returns row[i], or an empty cell when the row is short.
+func cellAt(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+// numericCell reports whether a cell fits in a right-aligned column: a
+// number, a percentage, or blank or "-" for a missing value.
+func numericCell(value string) bool {
+	trimmed := strings.TrimSuffix(strings.TrimSpace(value), "%")
+	return trimmed == "" || trimmed == "-" || isNumeric(trimmed)
+}
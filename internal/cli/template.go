@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// templateFuncs returns the functions available to --template, with meta
+// looking up keys in metadata.
+func templateFuncs(metadata map[string]string) template.FuncMap {
+	return template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"title": func(s string) string {
+			lower := []rune(strings.ToLower(s))
+			if len(lower) > 0 {
+				lower[0] = unicode.ToUpper(lower[0])
+			}
+			return string(lower)
+		},
+		"trim": strings.TrimSpace,
+		// percent formats a fraction such as a Share cell (0.3913) as a
+		// percentage with the given decimals, two by default.
+		"percent": func(v any, decimals ...int) (string, error) {
+			f, ok := v.(float64)
+			if !ok {
+				i, isInt := v.(int64)
+				if !isInt {
+					return "", fmt.Errorf("percent: %v is not a number", v)
+				}
+				f = float64(i)
+			}
+			places := 2
+			if len(decimals) > 0 {
+				places = decimals[0]
+			}
+			return fmt.Sprintf("%.*f%%", places, f*100), nil
+		},
+		// pad left-aligns v in width columns, and padLeft right-aligns it.
+		"pad":     func(width int, v any) string { return fmt.Sprintf("%-*v", width, v) },
+		"padLeft": func(width int, v any) string { return fmt.Sprintf("%*v", width, v) },
+		"join":    strings.Join,
+		"meta":    func(key string) string { return metadata[key] },
+	}
+}
+
+// parseRowTemplate parses a --template value with the functions bound to
+// metadata.
+func parseRowTemplate(text string, metadata map[string]string) (*template.Template, error) {
+	tmpl, err := template.New("row").Option("missingkey=error").Funcs(templateFuncs(metadata)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("--template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate renders each row through the --template text, writing a
+// newline after every row that does not already end in one. Each row is a
+// map from header to its cell's value, so {{.Count}} is a number and
+// {{.Share}} a fraction; headers with spaces or punctuation are also
+// available with those characters removed ({{.PeakYear}} for "Peak Year").
+// Missing and blank values are given as their text. The title, footer, and metadata are not printed, but
+// metadata is reachable with {{meta "state"}}.
+func writeTemplate(w io.Writer, text string, rpt report) error {
+	tmpl, err := parseRowTemplate(text, rpt.Metadata)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for i, row := range rpt.Rows {
+		data := make(map[string]any, len(rpt.Headers)*2)
+		for j, header := range rpt.Headers {
+			var cell any = blankCell
+			if j < len(row) {
+				cell = row[j]
+			}
+			value := cellValue(cell)
+			if value == nil {
+				value = cellText(cell)
+			}
+			data[header] = value
+			if key := templateKey(header); key != header {
+				if _, taken := data[key]; !taken {
+					data[key] = value
+				}
+			}
+		}
+
+		buf.Reset()
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("--template: row %d: %w", i+1, err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateKey strips everything but letters, digits, and underscores from a
+// header so it can be used as a template field name.
+func templateKey(header string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, header)
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runUnisex(args []string) error {
+	fs := flag.NewFlagSet("unisex", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	state := fs.String("state", "", "optional two-letter state abbreviation")
+	year := fs.String("year", "", "specific year or range to filter on (comma-separated or range, 0 for all years)")
+	minCount := fs.Int("min-count", 100, "minimum total count across both genders to rank a name")
+	topN := fs.Int("top", 20, "number of names to display when no name is given")
+	scopeFlag := addScopeFlag(fs)
+	output := addOutputFlags(fs, formatTable)
+
+	positional, err := a.parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return errors.New("unisex: at most one name is allowed")
+	}
+	if *topN < 1 {
+		return errors.New("unisex: --top must be 1 or greater")
+	}
+
+	yearFilter, err := parseYearFilter(*year)
+	if err != nil {
+		return err
+	}
+
+	scope, err := parseScope(*scopeFlag, *state)
+	if err != nil {
+		return fmt.Errorf("unisex: %w", err)
+	}
+
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	records, err := a.scopedRecords(scope, *state)
+	if err != nil {
+		return err
+	}
+	records = filterRecordsByYear(records, yearFilter)
+
+	metadata := map[string]string{}
+	displayLocation := "the United States"
+	if trimmed := strings.TrimSpace(*state); trimmed != "" {
+		metadata["state"] = strings.ToUpper(trimmed)
+		displayLocation = metadata["state"]
+	} else {
+		metadata["state"] = "NATIONAL"
+	}
+	if scope == scopeNational {
+		metadata["dataset"] = scopeNational
+	}
+	if desc := yearFilter.String(); desc != "" {
+		metadata["year"] = desc
+	}
+
+	if len(positional) == 1 {
+		return a.renderUnisexHistory(output, records, strings.TrimSpace(positional[0]), displayLocation, metadata)
+	}
+
+	ranked := namesdata.RankUnisex(namesdata.AggregateByName(records, 0), *minCount)
+	metadata["min_count"] = fmt.Sprintf("%d", *minCount)
+	metadata["matches"] = fmt.Sprintf("%d", len(ranked))
+
+	headers := []string{"Rank", "Name", "Total", "Female", "Male", "Unisex Index"}
+	if len(ranked) == 0 {
+		return namesdata.ErrNoMatches
+	}
+
+	shown := ranked
+	if len(shown) > *topN {
+		shown = shown[:*topN]
+	}
+	title := fmt.Sprintf("Top %d names by unisex index in %s", len(shown), displayLocation)
+	if desc := yearFilter.String(); desc != "" {
+		title += fmt.Sprintf(" for %s", desc)
+	}
+	title += ":"
+
+	rows := make([][]any, len(shown))
+	for i, split := range shown {
+		rows[i] = []any{
+			i + 1,
+			split.Name,
+			split.Total(),
+			split.Female,
+			split.Male,
+			decimalCell(split.UnisexIndex(), 3),
+		}
+	}
+
+	rpt := report{
+		Lines:    []string{title},
+		Footer:   []string{"The unisex index is min(female, male) / max(female, male): 1 when a name is given equally to girls and boys."},
+		Metadata: metadata,
+		Headers:  headers,
+		Rows:     rows,
+	}
+
+	return a.render(output, rpt)
+}
+
+// renderUnisexHistory prints name's unisex index for every year it appears
+// in records.
+func (a *App) renderUnisexHistory(output *outputOptions, records []namesdata.Record, name, displayLocation string, metadata map[string]string) error {
+	ratio, err := namesdata.NameGenderRatio(records, name)
+	if err != nil {
+		return err
+	}
+	history := ratio.Years
+
+	rows := make([][]any, len(history))
+	balanced := history[0]
+	for i, split := range history {
+		if split.UnisexIndex() > balanced.UnisexIndex() {
+			balanced = split
+		}
+		rows[i] = []any{
+			split.Year,
+			split.Female,
+			split.Male,
+			percentCell(split.FemaleShare(), 2),
+			decimalCell(split.UnisexIndex(), 3),
+		}
+	}
+	latest := history[len(history)-1]
+	metadata["name"] = latest.Name
+	metadata["years_present"] = fmt.Sprintf("%d", len(history))
+
+	footer := []string{
+		fmt.Sprintf("%s was most balanced in %d, with an index of %.3f; in %d it is %.3f and %s.",
+			latest.Name, balanced.Year, balanced.UnisexIndex(), latest.Year, latest.UnisexIndex(), genderLean(latest)),
+	}
+	if split, ok := crossoverSplit(ratio); ok {
+		footer = append(footer, fmt.Sprintf("Its majority flipped in %d: %s.", split.Year, genderLean(split)))
+	}
+
+	rpt := report{
+		Lines:    []string{fmt.Sprintf("Unisex index of %s by year in %s:", latest.Name, displayLocation)},
+		Footer:   footer,
+		Metadata: metadata,
+		Headers:  []string{"Year", "Female", "Male", "Female Share", "Unisex Index"},
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
+
+// genderLean describes which gender a split leans toward.
+func genderLean(split namesdata.GenderSplit) string {
+	share := split.FemaleShare()
+	switch {
+	case split.Female == split.Male:
+		return "evenly split"
+	case share > 0.5:
+		return fmt.Sprintf("%.0f%% female", share*100)
+	default:
+		return fmt.Sprintf("%.0f%% male", (1-share)*100)
+	}
+}
+
+// crossoverSplit returns the split of the ratio's crossover year, if any.
+func crossoverSplit(ratio namesdata.GenderRatio) (namesdata.GenderSplit, bool) {
+	for _, split := range ratio.Years {
+		if ratio.Crossover != 0 && split.Year == ratio.Crossover {
+			return split, true
+		}
+	}
+	return namesdata.GenderSplit{}, false
+}
@@ -0,0 +1,449 @@
+package cli
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// ssaStateDataURL is the official zip of per-state name files.
+const ssaStateDataURL = "https://www.ssa.gov/oact/babynames/state/namesbystate.zip"
+
+// ssaNationalDataURL is the official zip of national yobYYYY.txt files.
+const ssaNationalDataURL = "https://www.ssa.gov/oact/babynames/names.zip"
+
+// ssaTerritoryDataURL is the official zip of the PR.TXT and TR.TXT
+// territory files.
+const ssaTerritoryDataURL = "https://www.ssa.gov/oact/babynames/territory/namesbyterritory.zip"
+
+// maxStateFileSize bounds each extracted data file, guarding against zip
+// bombs. The largest SSA file is well under 50 MB.
+const maxStateFileSize = 512 << 20
+
+var (
+	stateFileNamePattern     = regexp.MustCompile(`^[A-Z]{2}\.TXT$`)
+	nationalFileNamePattern  = regexp.MustCompile(`^yob[0-9]{4}\.txt$`)
+	territoryFileNamePattern = regexp.MustCompile(`^(PR|TR)\.TXT$`)
+)
+
+// dataDirMarker is written into every directory update-data unpacks, so a
+// later run can tell the directory is its own to replace.
+const dataDirMarker = ".names-update-data"
+
+// dataKind is one of the SSA downloads update-data installs.
+type dataKind struct {
+	label string
+	// flag is the update-data flag that selects the kind.
+	flag string
+	url  string
+	dir  func() string
+	// fold normalizes an archive entry's name before it is matched
+	// against files and written out.
+	fold  func(string) string
+	files *regexp.Regexp
+}
+
+var (
+	stateData = dataKind{
+		label: "names-by-state",
+		url:   ssaStateDataURL,
+		dir:   DefaultDataDir,
+		fold:  strings.ToUpper,
+		files: stateFileNamePattern,
+	}
+	nationalData = dataKind{
+		label: "national names",
+		flag:  "--national",
+		url:   ssaNationalDataURL,
+		dir:   DefaultNationalDataDir,
+		fold:  strings.ToLower,
+		files: nationalFileNamePattern,
+	}
+	territoryData = dataKind{
+		label: "names-by-territory",
+		flag:  "--territories",
+		url:   ssaTerritoryDataURL,
+		dir:   DefaultTerritoryDataDir,
+		fold:  strings.ToUpper,
+		files: territoryFileNamePattern,
+	}
+)
+
+// DefaultDataDir returns where update-data unpacks the SSA files by default:
+// names/namesbystate under $XDG_DATA_HOME, falling back to ~/.local/share.
+func DefaultDataDir() string {
+	return defaultDataDir("namesbystate")
+}
+
+// DefaultNationalDataDir returns where update-data --national unpacks the
+// SSA national files by default: names/namesnational alongside
+// DefaultDataDir.
+func DefaultNationalDataDir() string {
+	return defaultDataDir("namesnational")
+}
+
+// DefaultTerritoryDataDir returns where update-data --territories unpacks
+// the SSA territory files by default: names/namesbyterritory alongside
+// DefaultDataDir.
+func DefaultTerritoryDataDir() string {
+	return defaultDataDir("namesbyterritory")
+}
+
+func defaultDataDir(name string) string {
+	dir := strings.TrimSpace(os.Getenv("XDG_DATA_HOME"))
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return name
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "names", name)
+}
+
+func (a *App) runUpdateData(args []string) error {
+	fs := flag.NewFlagSet("update-data", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	url := fs.String("url", "", "URL of the SSA zip (default the official names-by-state zip, or the national or territory zip with --national or --territories)")
+	dir := fs.String("dir", "", fmt.Sprintf("directory to unpack the files into, replaced on success (default %s, or its namesnational or namesbyterritory sibling)", DefaultDataDir()))
+	national := fs.Bool("national", false, "download the SSA national yobYYYY.txt files queried by --scope national")
+	territories := fs.Bool("territories", false, "download the SSA territory files queried by --include-territories")
+	checksum := fs.String("sha256", "", "optional expected SHA-256 of the zip, in hex")
+	check := fs.Bool("check", false, "report whether the current dataset is stale instead of downloading")
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("update-data: unexpected argument %q", fs.Arg(0))
+	}
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	kind := stateData
+	switch {
+	case *national && *territories:
+		return asUsageError(errors.New("update-data: --national and --territories cannot be combined"))
+	case *national:
+		kind = nationalData
+	case *territories:
+		kind = territoryData
+	}
+	if *check {
+		return a.checkDataFreshness(output, kind, time.Now())
+	}
+
+	source := strings.TrimSpace(*url)
+	if source == "" {
+		source = kind.url
+	}
+	target := strings.TrimSpace(*dir)
+	if target == "" {
+		target = kind.dir()
+	}
+	want := strings.ToLower(strings.TrimSpace(*checksum))
+	if want != "" {
+		if decoded, err := hex.DecodeString(want); err != nil || len(decoded) != sha256.Size {
+			return fmt.Errorf("update-data: --sha256 must be %d hex characters", 2*sha256.Size)
+		}
+	}
+
+	parent := filepath.Dir(target)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return fmt.Errorf("update-data: %w", err)
+	}
+
+	fmt.Fprintf(a.Stderr, "Downloading %s\n", source)
+	archive, digest, err := downloadToTemp(source, parent)
+	if err != nil {
+		return fmt.Errorf("update-data: %w", err)
+	}
+	defer os.Remove(archive)
+	if want != "" && digest != want {
+		return fmt.Errorf("update-data: checksum mismatch: got %s, want %s", digest, want)
+	}
+
+	staging, err := os.MkdirTemp(parent, ".names-data-*")
+	if err != nil {
+		return fmt.Errorf("update-data: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := unpackDataFiles(archive, staging, kind); err != nil {
+		return fmt.Errorf("update-data: %w", err)
+	}
+	summary, err := summarizeData(os.DirFS(staging), kind)
+	if err != nil {
+		return fmt.Errorf("update-data: downloaded data failed verification: %w", err)
+	}
+	if err := replaceDir(staging, target, kind); err != nil {
+		return fmt.Errorf("update-data: %w", err)
+	}
+	// The App may be querying the directory just replaced.
+	a.InvalidateCache()
+
+	metadata := map[string]string{
+		"dir":         target,
+		"url":         source,
+		"sha256":      digest,
+		"records":     fmt.Sprintf("%d", summary.Records),
+		"first_year":  fmt.Sprintf("%d", summary.FirstYear),
+		"latest_year": fmt.Sprintf("%d", summary.LastYear),
+	}
+	rows := [][]any{
+		{"Records", summary.Records},
+		{"Years", formatYearSegment(summary.FirstYear, summary.LastYear)},
+		{"SHA-256", digest},
+	}
+	footer := fmt.Sprintf("Query it with --dataset %s, e.g. names --dataset %s top.", target, target)
+	switch kind.flag {
+	case nationalData.flag:
+		metadata["dataset"] = scopeNational
+		footer = fmt.Sprintf("Builds without embedded national files query %s with --scope national.", DefaultNationalDataDir())
+	case territoryData.flag:
+		metadata["territories"] = fmt.Sprintf("%d", len(summary.States))
+		rows = append([][]any{{"Territories", len(summary.States)}}, rows...)
+		footer = fmt.Sprintf("Builds without embedded territory files add %s with --include-territories.", DefaultTerritoryDataDir())
+	default:
+		metadata["states"] = fmt.Sprintf("%d", len(summary.States))
+		rows = append([][]any{{"States", len(summary.States)}}, rows...)
+	}
+	rpt := report{
+		Lines:    []string{fmt.Sprintf("Updated SSA %s data in %s:", kind.label, target)},
+		Footer:   []string{footer},
+		Metadata: metadata,
+		Headers:  []string{"Field", "Value"},
+		Rows:     rows,
+	}
+	return a.render(output, rpt)
+}
+
+// checkDataFreshness compares the latest year in the current dataset of
+// kind with the latest year the SSA should have published by now.
+func (a *App) checkDataFreshness(output *outputOptions, kind dataKind, now time.Time) error {
+	fsys := a.Dataset
+	switch kind.flag {
+	case nationalData.flag:
+		if a.National == nil {
+			return errNationalUnavailable
+		}
+		fsys = a.National
+	case territoryData.flag:
+		if a.Territories == nil {
+			return errTerritoriesUnavailable
+		}
+		fsys = a.Territories
+	}
+	summary, err := summarizeData(fsys, kind)
+	if err != nil {
+		return fmt.Errorf("update-data: %w", err)
+	}
+	expected := expectedLatestYear(now)
+	stale := summary.LastYear < expected
+
+	title := fmt.Sprintf("The dataset is up to date: it covers %d, the latest year published.", summary.LastYear)
+	var footer []string
+	if stale {
+		title = fmt.Sprintf("The dataset is stale: it ends in %d, but data through %d should be published.", summary.LastYear, expected)
+		command := strings.TrimSpace("names update-data " + kind.flag)
+		footer = append(footer, fmt.Sprintf("Run %s to download the latest files.", command))
+	}
+
+	rpt := report{
+		Lines:  []string{title},
+		Footer: footer,
+		Metadata: map[string]string{
+			"latest_year":   fmt.Sprintf("%d", summary.LastYear),
+			"expected_year": fmt.Sprintf("%d", expected),
+			"stale":         fmt.Sprintf("%t", stale),
+		},
+		Headers: []string{"Field", "Value"},
+		Rows: [][]any{
+			{"Latest Year", summary.LastYear},
+			{"Expected Year", expected},
+			{"Stale", stale},
+		},
+	}
+	return a.render(output, rpt)
+}
+
+// expectedLatestYear is the latest year of data the SSA has published as of
+// now. Each year's names are released in May of the following year, so
+// until June the previous release is assumed to be the latest.
+func expectedLatestYear(now time.Time) int {
+	if now.Month() >= time.June {
+		return now.Year() - 1
+	}
+	return now.Year() - 2
+}
+
+// downloadToTemp saves url to a temporary file in dir and returns its path
+// and hex SHA-256.
+func downloadToTemp(url, dir string) (string, string, error) {
+	client := &http.Client{Timeout: 10 * time.Minute}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+
+	file, err := os.CreateTemp(dir, ".namesbystate-*.zip")
+	if err != nil {
+		return "", "", err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), resp.Body); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", "", fmt.Errorf("download %s: %w", url, err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", "", err
+	}
+	return file.Name(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// unpackDataFiles extracts the files of kind at the top level of the zip at
+// path into dir, ignoring everything else in the archive.
+func unpackDataFiles(path, dir string, kind dataKind) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("open zip: %w", err)
+	}
+	defer archive.Close()
+
+	extracted := 0
+	for _, entry := range archive.File {
+		name := kind.fold(entry.Name)
+		if !kind.files.MatchString(name) {
+			continue
+		}
+		if entry.UncompressedSize64 > maxStateFileSize {
+			return fmt.Errorf("%s is larger than %d bytes", entry.Name, maxStateFileSize)
+		}
+		if err := extractFile(entry, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+		extracted++
+	}
+	if extracted == 0 {
+		return fmt.Errorf("zip contains no %s files", kind.label)
+	}
+	return nil
+}
+
+func extractFile(entry *zip.File, dest string) error {
+	src, err := entry.Open()
+	if err != nil {
+		return fmt.Errorf("extract %s: %w", entry.Name, err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	written, err := io.Copy(dst, io.LimitReader(src, maxStateFileSize+1))
+	if err == nil && written > maxStateFileSize {
+		err = fmt.Errorf("larger than %d bytes", maxStateFileSize)
+	}
+	if err != nil {
+		dst.Close()
+		return fmt.Errorf("extract %s: %w", entry.Name, err)
+	}
+	return dst.Close()
+}
+
+// replaceDir moves staging into place at target. An existing target is
+// replaced only when it is empty, was written by update-data, or holds
+// nothing but files of kind, so pointing --dir at an unrelated directory
+// fails instead of deleting it. The old directory is moved into a fresh
+// temporary directory first and removed only once the new one is in place.
+func replaceDir(staging, target string, kind dataKind) error {
+	if err := os.WriteFile(filepath.Join(staging, dataDirMarker), nil, 0o644); err != nil {
+		return err
+	}
+	info, err := os.Stat(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Rename(staging, target)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s exists and is not a directory", target)
+	}
+	if err := checkReplaceableDir(target, kind); err != nil {
+		return err
+	}
+
+	holding, err := os.MkdirTemp(filepath.Dir(target), ".namesbystate-old-*")
+	if err != nil {
+		return err
+	}
+	backup := filepath.Join(holding, filepath.Base(target))
+	if err := os.Rename(target, backup); err != nil {
+		os.Remove(holding)
+		return err
+	}
+	if err := os.Rename(staging, target); err != nil {
+		os.Rename(backup, target)
+		os.Remove(holding)
+		return err
+	}
+	return os.RemoveAll(holding)
+}
+
+// checkReplaceableDir returns an error unless dir is empty, carries the
+// update-data marker, or contains only files of kind.
+func checkReplaceableDir(dir string, kind dataKind) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == dataDirMarker {
+			return nil
+		}
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !kind.files.MatchString(kind.fold(entry.Name())) {
+			return fmt.Errorf("refusing to replace %s: it holds %s, which is not a %s file (choose an empty or new --dir)", dir, entry.Name(), kind.label)
+		}
+	}
+	return nil
+}
+
+// summarizeData checks that fsys is a dataset of kind and describes it.
+// Both describers parse every record, so they also verify that each file is
+// well formed.
+func summarizeData(fsys fs.FS, kind dataKind) (namesdata.DatasetInfo, error) {
+	if kind.flag == nationalData.flag {
+		return namesdata.DescribeNational(fsys)
+	}
+	if err := namesdata.ValidateDataset(fsys); err != nil {
+		return namesdata.DatasetInfo{}, err
+	}
+	return namesdata.Describe(fsys)
+}
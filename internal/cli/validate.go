@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func (a *App) runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.registerGlobalFlags(fs)
+
+	output := addOutputFlags(fs, formatTable)
+
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := output.resolve(); err != nil {
+		return err
+	}
+
+	check, err := namesdata.CheckDataset(a.Dataset)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	rows := make([][]any, len(check.Problems))
+	for i, problem := range check.Problems {
+		rows[i] = []any{problem.File, problem.Line, problem.Message}
+	}
+
+	files := "files"
+	if check.Files == 1 {
+		files = "file"
+	}
+	title := fmt.Sprintf("Validated %d records in %d %s:", check.Records, check.Files, files)
+	footer := "No problems found."
+	switch len(check.Problems) {
+	case 0:
+	case 1:
+		footer = "1 problem found."
+	default:
+		footer = fmt.Sprintf("%d problems found.", len(check.Problems))
+	}
+
+	rpt := report{
+		Lines:  []string{title},
+		Footer: []string{footer},
+		Metadata: map[string]string{
+			"files":    fmt.Sprintf("%d", check.Files),
+			"records":  fmt.Sprintf("%d", check.Records),
+			"problems": fmt.Sprintf("%d", len(check.Problems)),
+		},
+		Headers: []string{"File", "Line", "Problem"},
+		Rows:    rows,
+	}
+	if err := a.render(output, rpt); err != nil {
+		return err
+	}
+	if len(check.Problems) > 0 {
+		return &invalidDatasetError{problems: len(check.Problems)}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// The dashboard uses the server's HTTP API like any other client: /top for
+// the table and /trend.svg for the chart.
+(function () {
+  "use strict";
+
+  const filters = document.getElementById("filters");
+  const trend = document.getElementById("trend");
+  const table = document.getElementById("top");
+  const title = document.getElementById("title");
+  const footer = document.getElementById("footer");
+  const errorBox = document.getElementById("error");
+  const chart = document.getElementById("chart");
+
+  // params collects the non-empty fields of form as query parameters.
+  function params(form, only) {
+    const query = new URLSearchParams();
+    for (const [key, value] of new FormData(form)) {
+      if ((!only || only.includes(key)) && String(value).trim() !== "") {
+        query.set(key, String(value).trim());
+      }
+    }
+    return query;
+  }
+
+  function showError(message) {
+    errorBox.textContent = message;
+    errorBox.hidden = !message;
+  }
+
+  function formatCell(header, value) {
+    if (value === null || value === undefined) {
+      return "-";
+    }
+    if (typeof value === "number" && /share|chance/i.test(header)) {
+      return (value * 100).toFixed(2) + "%";
+    }
+    if (typeof value === "number") {
+      return value.toLocaleString();
+    }
+    return String(value);
+  }
+
+  function renderTable(report) {
+    title.textContent = (report.lines && report.lines[0]) || "Top names";
+    const head = table.tHead;
+    const body = table.tBodies[0];
+    head.replaceChildren();
+    body.replaceChildren();
+
+    const headerRow = head.insertRow();
+    for (const header of report.headers) {
+      const th = document.createElement("th");
+      th.textContent = header;
+      headerRow.appendChild(th);
+    }
+    for (const row of report.rows) {
+      const tr = body.insertRow();
+      if (row.Name) {
+        tr.className = "name";
+        tr.title = "Chart " + row.Name;
+        tr.addEventListener("click", () => {
+          trend.elements.names.value = row.Name;
+          drawChart();
+        });
+      }
+      for (const header of report.headers) {
+        const td = tr.insertCell();
+        td.textContent = formatCell(header, row[header]);
+        if (typeof row[header] === "number") {
+          td.className = "number";
+        }
+      }
+    }
+    footer.textContent = (report.footer || []).join(" ");
+  }
+
+  async function loadTop() {
+    showError("");
+    const response = await fetch("/top?" + params(filters));
+    const payload = await response.json();
+    if (!response.ok) {
+      showError(payload.error);
+      return;
+    }
+    renderTable(payload);
+  }
+
+  function drawChart() {
+    const query = params(filters, ["state", "year", "gender", "scope"]);
+    for (const [key, value] of params(trend)) {
+      query.set(key, value);
+    }
+    // A single year would chart one point, so the chart always spans
+    // every year.
+    query.delete("year");
+    if (!query.get("names")) {
+      chart.hidden = true;
+      return;
+    }
+    const img = chart.querySelector("img");
+    img.onerror = async () => {
+      chart.hidden = true;
+      const response = await fetch(img.src);
+      const payload = await response.json().catch(() => ({ error: response.statusText }));
+      showError(payload.error);
+    };
+    img.onload = () => {
+      showError("");
+      chart.hidden = false;
+    };
+    img.src = "/trend.svg?" + query;
+  }
+
+  filters.addEventListener("submit", (event) => {
+    event.preventDefault();
+    loadTop().catch((err) => showError(err.message));
+    drawChart();
+  });
+  trend.addEventListener("submit", (event) => {
+    event.preventDefault();
+    drawChart();
+  });
+
+  loadTop().catch((err) => showError(err.message));
+})();
@@ -0,0 +1,161 @@
+// Package cluster groups numeric vectors with k-means. Initialization is
+// deterministic, so the same input always yields the same clusters without
+// needing a seed.
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxIterations bounds Lloyd's algorithm; it usually settles long before.
+const maxIterations = 100
+
+// Result is a k-means partition of the input points.
+type Result struct {
+	// Assignments[i] is the cluster of the i-th point.
+	Assignments []int
+	// Centroids[c] is the mean of the points in cluster c.
+	Centroids [][]float64
+	// Inertia is the sum of squared distances from each point to its
+	// centroid; lower means tighter clusters.
+	Inertia float64
+}
+
+// Sizes returns the number of points in each cluster.
+func (r Result) Sizes() []int {
+	sizes := make([]int, len(r.Centroids))
+	for _, c := range r.Assignments {
+		sizes[c]++
+	}
+	return sizes
+}
+
+// KMeans partitions points into k clusters. The first centroid is the point
+// nearest the overall mean and each further one is the point farthest from
+// the centroids chosen so far, after which Lloyd's algorithm runs until the
+// assignments stop changing. Every point must have the same dimension.
+func KMeans(points [][]float64, k int) (Result, error) {
+	if len(points) == 0 {
+		return Result{}, errors.New("no points to cluster")
+	}
+	if k < 1 {
+		return Result{}, errors.New("k must be at least 1")
+	}
+	if k > len(points) {
+		return Result{}, fmt.Errorf("k (%d) exceeds the number of points (%d)", k, len(points))
+	}
+	dim := len(points[0])
+	for i, p := range points {
+		if len(p) != dim {
+			return Result{}, fmt.Errorf("point %d has %d dimensions, want %d", i, len(p), dim)
+		}
+	}
+
+	centroids := initialCentroids(points, k)
+	assignments := make([]int, len(points))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+	for iter := 0; iter < maxIterations; iter++ {
+		changed := false
+		for i, p := range points {
+			if c := nearest(p, centroids); c != assignments[i] {
+				assignments[i] = c
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		centroids = means(points, assignments, k, dim)
+		// An emptied cluster takes over the point worst served by its
+		// current centroid, so exactly k clusters survive.
+		for c, centroid := range centroids {
+			if centroid != nil {
+				continue
+			}
+			worst, worstDist := -1, -1.0
+			for i, p := range points {
+				own := centroids[assignments[i]]
+				if own == nil {
+					continue
+				}
+				if d := squaredDistance(p, own); d > worstDist {
+					worst, worstDist = i, d
+				}
+			}
+			centroids[c] = append([]float64(nil), points[worst]...)
+		}
+	}
+
+	result := Result{Assignments: assignments, Centroids: centroids}
+	for i, p := range points {
+		result.Inertia += squaredDistance(p, centroids[assignments[i]])
+	}
+	return result, nil
+}
+
+// initialCentroids seeds k centroids by farthest-first traversal starting
+// from the most typical point.
+func initialCentroids(points [][]float64, k int) [][]float64 {
+	mean := means(points, make([]int, len(points)), 1, len(points[0]))[0]
+	first := nearest(mean, points)
+	centroids := [][]float64{append([]float64(nil), points[first]...)}
+
+	for len(centroids) < k {
+		next, nextDist := -1, -1.0
+		for i, p := range points {
+			if d := squaredDistance(p, centroids[nearest(p, centroids)]); d > nextDist {
+				next, nextDist = i, d
+			}
+		}
+		centroids = append(centroids, append([]float64(nil), points[next]...))
+	}
+	return centroids
+}
+
+// nearest returns the index of the candidate closest to p, preferring the
+// earliest on ties.
+func nearest(p []float64, candidates [][]float64) int {
+	best, bestDist := 0, math.Inf(1)
+	for i, c := range candidates {
+		if d := squaredDistance(p, c); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// means averages the points in each of k clusters. Clusters without points
+// get a nil centroid.
+func means(points [][]float64, assignments []int, k, dim int) [][]float64 {
+	sums := make([][]float64, k)
+	counts := make([]int, k)
+	for i, p := range points {
+		c := assignments[i]
+		if sums[c] == nil {
+			sums[c] = make([]float64, dim)
+		}
+		for d, v := range p {
+			sums[c][d] += v
+		}
+		counts[c]++
+	}
+	for c, sum := range sums {
+		for d := range sum {
+			sum[d] /= float64(counts[c])
+		}
+	}
+	return sums
+}
+
+func squaredDistance(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		diff := a[i] - b[i]
+		sum += diff * diff
+	}
+	return sum
+}
@@ -0,0 +1,54 @@
+package cluster_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/cluster"
+)
+
+func TestKMeans(t *testing.T) {
+	points := [][]float64{
+		{0, 0}, {0.2, 0.1}, {0.1, 0.3},
+		{5, 5}, {5.2, 4.9},
+		{10, 0}, {9.8, 0.2}, {10.1, -0.1},
+	}
+	result, err := cluster.KMeans(points, 3)
+	if err != nil {
+		t.Fatalf("KMeans: %v", err)
+	}
+
+	groups := [][]int{{0, 1, 2}, {3, 4}, {5, 6, 7}}
+	seen := map[int]bool{}
+	for _, group := range groups {
+		c := result.Assignments[group[0]]
+		if seen[c] {
+			t.Fatalf("groups share cluster %d: %v", c, result.Assignments)
+		}
+		seen[c] = true
+		for _, i := range group[1:] {
+			if result.Assignments[i] != c {
+				t.Fatalf("expected points %v together, got %v", group, result.Assignments)
+			}
+		}
+	}
+	if sizes := result.Sizes(); sizes[result.Assignments[3]] != 2 {
+		t.Fatalf("unexpected sizes %v", sizes)
+	}
+	if result.Inertia <= 0 || result.Inertia > 1 {
+		t.Fatalf("expected tight clusters, got inertia %v", result.Inertia)
+	}
+
+	again, _ := cluster.KMeans(points, 3)
+	for i := range points {
+		if again.Assignments[i] != result.Assignments[i] {
+			t.Fatalf("expected deterministic assignments, got %v and %v", result.Assignments, again.Assignments)
+		}
+	}
+
+	if _, err := cluster.KMeans(points, 9); err == nil {
+		t.Fatal("expected an error when k exceeds the points")
+	}
+	if _, err := cluster.KMeans([][]float64{{1}, {1, 2}}, 1); err == nil {
+		t.Fatal("expected an error for ragged points")
+	}
+}
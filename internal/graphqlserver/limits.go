@@ -0,0 +1,129 @@
+package graphqlserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// maxQueryCost bounds the cost of a query: one per selected field, plus
+	// scanCost for each field that reads the dataset. Aliases and fragment
+	// spreads count every time they repeat a field.
+	maxQueryCost = 150
+	// scanCost is the extra cost of a field that reads the dataset.
+	scanCost = 10
+)
+
+// scanFields are the fields, as Type.field, whose resolvers read the
+// dataset.
+var scanFields = map[string]bool{
+	"Query.aggregate": true,
+	"Query.top":       true,
+	"Query.years":     true,
+	"Name.rank":       true,
+	"Name.trend":      true,
+	"Name.states":     true,
+}
+
+// fieldTypes maps each object type in schema to the named type of each of
+// its fields, so a query can be walked without executing it.
+func fieldTypes(schema graphql.Schema) map[string]map[string]string {
+	types := make(map[string]map[string]string)
+	for name, typ := range schema.TypeMap() {
+		object, ok := typ.(*graphql.Object)
+		if !ok || strings.HasPrefix(name, "__") {
+			continue
+		}
+		fields := make(map[string]string)
+		for fieldName, field := range object.Fields() {
+			fields[fieldName] = graphql.GetNamed(field.Type).String()
+		}
+		types[name] = fields
+	}
+	return types
+}
+
+// checkCost rejects a query costing more than maxQueryCost. Operations and
+// fragments it cannot resolve are left for the validator to report.
+func (s *Server) checkCost(doc *ast.Document, operationName string) error {
+	var (
+		operation *ast.OperationDefinition
+		count     int
+	)
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			count++
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		}
+	}
+	if operation == nil || (operationName == "" && count > 1) || operation.Operation != ast.OperationTypeQuery {
+		return nil
+	}
+
+	w := costWalker{types: s.fieldTypes, fragments: fragments, spreading: make(map[string]bool)}
+	return w.walk(operation.SelectionSet, "Query")
+}
+
+// costWalker totals the cost of a query's selections.
+type costWalker struct {
+	types     map[string]map[string]string
+	fragments map[string]*ast.FragmentDefinition
+	spreading map[string]bool
+	cost      int
+}
+
+// walk adds the cost of set, selected on typeName. Selections on fields the
+// schema lacks are not followed.
+func (w *costWalker) walk(set *ast.SelectionSet, typeName string) error {
+	if set == nil {
+		return nil
+	}
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			name := selection.Name.Value
+			// Introspection reads only the schema.
+			if strings.HasPrefix(name, "__") {
+				continue
+			}
+			w.cost++
+			if scanFields[typeName+"."+name] {
+				w.cost += scanCost
+			}
+			if w.cost > maxQueryCost {
+				return fmt.Errorf("query is too complex: it costs more than %d (each field costs 1, and each field that reads the dataset %d more)", maxQueryCost, scanCost)
+			}
+			if child, ok := w.types[typeName][name]; ok {
+				if err := w.walk(selection.SelectionSet, child); err != nil {
+					return err
+				}
+			}
+		case *ast.InlineFragment:
+			if err := w.walk(selection.SelectionSet, typeName); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := w.fragments[name]
+			if !ok || w.spreading[name] {
+				continue
+			}
+			w.spreading[name] = true
+			err := w.walk(fragment.SelectionSet, typeName)
+			delete(w.spreading, name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,547 @@
+// Package graphqlserver serves a GraphQL API over the name dataset, so a
+// client can fetch a name's rank, trend, and state breakdown in a single
+// request. Resolvers are backed by the namesdata aggregations.
+package graphqlserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/curtiscovington/ssa-names/internal/cache"
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// defaultTopLimit is the number of names top returns when limit is not
+// given, matching the top command's default.
+const defaultTopLimit = 10
+
+// maxRequestBytes bounds the size of a POSTed query document.
+const maxRequestBytes = 1 << 20
+
+// Scopes accepted by the scope argument.
+const (
+	scopeState    = "STATE"
+	scopeNational = "NATIONAL"
+)
+
+// Server answers GraphQL queries over a names-by-state dataset and,
+// optionally, the national dataset.
+type Server struct {
+	dataset    fs.FS
+	national   fs.FS
+	aggregates *cache.Cache
+	schema     graphql.Schema
+	fieldTypes map[string]map[string]string
+}
+
+// New returns a Server over dataset. national may be nil, in which case
+// queries with scope NATIONAL fail. aggregates, when not nil, caches name
+// totals across requests; pass the cache the other endpoints share so a
+// query and a command with the same filters scan the dataset once.
+func New(dataset, national fs.FS, aggregates *cache.Cache) (*Server, error) {
+	s := &Server{dataset: dataset, national: national, aggregates: aggregates}
+	schema, err := s.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	s.fieldTypes = fieldTypes(schema)
+	return s, nil
+}
+
+// nameRef is the source of the Name type: a name and the gender its
+// fields are computed for.
+type nameRef struct {
+	Name   string
+	Gender string
+}
+
+// aggregateRef is the source of the Aggregate type.
+type aggregateRef struct {
+	Names []namesdata.NameCount
+	Total int
+}
+
+// nameCount is the source of the NameCount type.
+type nameCount struct {
+	Rank  int     `json:"rank"`
+	Name  string  `json:"name"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// trendPoint is the source of the TrendPoint type.
+type trendPoint struct {
+	Year    int     `json:"year"`
+	Rank    *int    `json:"rank"`
+	Count   int     `json:"count"`
+	Share   float64 `json:"share"`
+	Present bool    `json:"present"`
+}
+
+// stateShare is the source of the StateShare type.
+type stateShare struct {
+	State string  `json:"state"`
+	Rank  int     `json:"rank"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+func (s *Server) buildSchema() (graphql.Schema, error) {
+	scope := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Scope",
+		Description: "The dataset to query.",
+		Values: graphql.EnumValueConfigMap{
+			scopeState:    {Value: scopeState, Description: "The per-state files (the default)."},
+			scopeNational: {Value: scopeNational, Description: "The exact SSA national totals."},
+		},
+	})
+	gender := graphql.NewEnum(graphql.EnumConfig{
+		Name: "Gender",
+		Values: graphql.EnumValueConfigMap{
+			"F": {Value: "F"},
+			"M": {Value: "M"},
+		},
+	})
+
+	nameCountType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "NameCount",
+		Description: "A name's rank and count within the filters.",
+		Fields: graphql.Fields{
+			"rank":  {Type: graphql.NewNonNull(graphql.Int), Description: "1-based rank by count."},
+			"name":  {Type: graphql.NewNonNull(graphql.String)},
+			"count": {Type: graphql.NewNonNull(graphql.Int)},
+			"share": {Type: graphql.NewNonNull(graphql.Float), Description: "Fraction of all matching births."},
+		},
+	})
+	trendPointType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TrendPoint",
+		Description: "A name's rank and count in one year.",
+		Fields: graphql.Fields{
+			"year":    {Type: graphql.NewNonNull(graphql.Int)},
+			"rank":    {Type: graphql.Int, Description: "Null in years the name does not appear."},
+			"count":   {Type: graphql.NewNonNull(graphql.Int)},
+			"share":   {Type: graphql.NewNonNull(graphql.Float), Description: "Fraction of that year's births."},
+			"present": {Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+	stateShareType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "StateShare",
+		Description: "A name's rank and share within one state.",
+		Fields: graphql.Fields{
+			"state": {Type: graphql.NewNonNull(graphql.String)},
+			"rank":  {Type: graphql.NewNonNull(graphql.Int)},
+			"count": {Type: graphql.NewNonNull(graphql.Int)},
+			"share": {Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+	yearType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Year",
+		Description: "Birth totals for one year.",
+		Fields: graphql.Fields{
+			"year":   {Type: graphql.NewNonNull(graphql.Int)},
+			"births": {Type: graphql.NewNonNull(graphql.Int)},
+			"names":  {Type: graphql.NewNonNull(graphql.Int), Description: "Distinct names recorded."},
+		},
+	})
+
+	filterArgs := func(withYear bool) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"state": {Type: graphql.String, Description: "Two-letter state abbreviation; omit for all states."},
+			"scope": {Type: scope, DefaultValue: scopeState},
+		}
+		if withYear {
+			args["year"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0, Description: "A single year; 0 for all years."}
+		}
+		return args
+	}
+	withGender := func(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args["gender"] = &graphql.ArgumentConfig{Type: gender, Description: "Omit for both."}
+		return args
+	}
+	topField := &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(nameCountType))),
+		Description: "The most popular names, most popular first.",
+		Args: graphql.FieldConfigArgument{
+			"limit": {Type: graphql.Int, DefaultValue: defaultTopLimit},
+		},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			agg := p.Source.(aggregateRef)
+			limit := p.Args["limit"].(int)
+			if limit < 0 {
+				return nil, errors.New("limit must be 0 or greater")
+			}
+			if limit == 0 {
+				limit = len(agg.Names)
+			}
+			limit = min(limit, len(agg.Names))
+			out := make([]nameCount, limit)
+			for i, entry := range agg.Names[:limit] {
+				out[i] = nameCount{Rank: i + 1, Name: entry.Name, Count: entry.Count, Share: share(entry.Count, agg.Total)}
+			}
+			return out, nil
+		},
+	}
+	aggregateType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Aggregate",
+		Description: "Name totals for a set of filters.",
+		Fields: graphql.Fields{
+			"total": {Type: graphql.NewNonNull(graphql.Int), Description: "Matching births.",
+				Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(aggregateRef).Total, nil }},
+			"distinctNames": {Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (any, error) { return len(p.Source.(aggregateRef).Names), nil }},
+			"top": topField,
+		},
+	})
+
+	nameType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Name",
+		Description: "One name, with fields computed for the gender it was looked up with.",
+		Fields: graphql.Fields{
+			"name": {Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(nameRef).Name, nil }},
+			"gender": {Type: gender,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if g := p.Source.(nameRef).Gender; g != "" {
+						return g, nil
+					}
+					return nil, nil
+				}},
+			"rank": {
+				Type:        nameCountType,
+				Description: "The name's rank for the filters, or null when it does not appear.",
+				Args:        filterArgs(true),
+				Resolve:     s.resolveRank,
+			},
+			"trend": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(trendPointType))),
+				Description: "The name's rank and count in every year of the span.",
+				Args: func() graphql.FieldConfigArgument {
+					args := filterArgs(false)
+					args["from"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0, Description: "First year; 0 for the earliest."}
+					args["to"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0, Description: "Last year; 0 for the latest."}
+					return args
+				}(),
+				Resolve: s.resolveTrend,
+			},
+			"states": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(stateShareType))),
+				Description: "The name's share in each state where it appears, highest share first.",
+				Args: graphql.FieldConfigArgument{
+					"year": {Type: graphql.Int, DefaultValue: 0, Description: "A single year; 0 for all years."},
+				},
+				Resolve: s.resolveStates,
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"name": {
+				Type:        graphql.NewNonNull(nameType),
+				Description: "Look up one name.",
+				Args: graphql.FieldConfigArgument{
+					"name":   {Type: graphql.NewNonNull(graphql.String)},
+					"gender": {Type: gender, Description: "Omit for both."},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					name := strings.TrimSpace(p.Args["name"].(string))
+					if name == "" {
+						return nil, errors.New("name is required")
+					}
+					g, _ := p.Args["gender"].(string)
+					return nameRef{Name: name, Gender: g}, nil
+				},
+			},
+			"aggregate": {
+				Type:        graphql.NewNonNull(aggregateType),
+				Description: "Name totals for the filters.",
+				Args:        withGender(filterArgs(true)),
+				Resolve:     s.resolveAggregate,
+			},
+			"top": {
+				Type:        topField.Type,
+				Description: "The most popular names for the filters; shorthand for aggregate { top }.",
+				Args: func() graphql.FieldConfigArgument {
+					args := withGender(filterArgs(true))
+					args["limit"] = topField.Args["limit"]
+					return args
+				}(),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					agg, err := s.resolveAggregate(p)
+					if err != nil {
+						return nil, err
+					}
+					p.Source = agg
+					return topField.Resolve(p)
+				},
+			},
+			"states": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Description: "The state codes in the dataset.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return namesdata.States(s.dataset)
+				},
+			},
+			"years": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(yearType))),
+				Description: "Births and distinct names in each year, oldest first.",
+				Args:        withGender(filterArgs(false)),
+				Resolve:     s.resolveYears,
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func (s *Server) resolveAggregate(p graphql.ResolveParams) (any, error) {
+	state, _ := p.Args["state"].(string)
+	gender, _ := p.Args["gender"].(string)
+	aggregated, total, err := s.aggregate(p.Context, p.Args["scope"].(string), state, p.Args["year"].(int), gender)
+	if err != nil {
+		return nil, err
+	}
+	return aggregateRef{Names: aggregated, Total: total}, nil
+}
+
+func (s *Server) resolveRank(p graphql.ResolveParams) (any, error) {
+	ref := p.Source.(nameRef)
+	state, _ := p.Args["state"].(string)
+	aggregated, total, err := s.aggregate(p.Context, p.Args["scope"].(string), state, p.Args["year"].(int), ref.Gender)
+	if errors.Is(err, namesdata.ErrNoMatches) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range aggregated {
+		if strings.EqualFold(entry.Name, ref.Name) {
+			return nameCount{Rank: i + 1, Name: entry.Name, Count: entry.Count, Share: share(entry.Count, total)}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) resolveTrend(p graphql.ResolveParams) (any, error) {
+	ref := p.Source.(nameRef)
+	state, _ := p.Args["state"].(string)
+	span := namesdata.YearRange{From: p.Args["from"].(int), To: p.Args["to"].(int)}
+	if span.From < 0 || span.To < 0 || (span.To != 0 && span.From > span.To) {
+		return nil, errors.New("from must not be after to")
+	}
+	records, err := s.stream(p.Context, p.Args["scope"].(string), state, namesdata.Filter{State: state, From: span.From, To: span.To})
+	if err != nil {
+		return nil, err
+	}
+	_, series, _, err := namesdata.TrendSeq(records, ref.Gender, []string{ref.Name}, span)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]trendPoint, len(series[0].Points))
+	for i, pt := range series[0].Points {
+		points[i] = trendPoint{Year: pt.Year, Count: pt.Count, Share: share(pt.Count, pt.Total), Present: pt.Present}
+		if pt.Present {
+			rank := pt.Rank
+			points[i].Rank = &rank
+		}
+	}
+	return points, nil
+}
+
+func (s *Server) resolveStates(p graphql.ResolveParams) (any, error) {
+	ref := p.Source.(nameRef)
+	year := p.Args["year"].(int)
+	if year < 0 {
+		return nil, errors.New("year must be 0 or greater")
+	}
+	aggregates, err := namesdata.AggregateByState(s.dataset, ref.Gender, yearWeight(year))
+	if err != nil {
+		return nil, err
+	}
+	shares, _, err := namesdata.NameByState(aggregates, ref.Name)
+	var notFound *namesdata.NameNotFoundError
+	if errors.Is(err, namesdata.ErrNoMatches) || errors.As(err, &notFound) {
+		return []stateShare{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := make([]stateShare, len(shares))
+	for i, entry := range shares {
+		out[i] = stateShare{State: entry.State, Rank: entry.Rank, Count: entry.Count, Share: entry.Share()}
+	}
+	return out, nil
+}
+
+func (s *Server) resolveYears(p graphql.ResolveParams) (any, error) {
+	state, _ := p.Args["state"].(string)
+	gender, _ := p.Args["gender"].(string)
+	records, err := s.stream(p.Context, p.Args["scope"].(string), state, namesdata.Filter{State: state})
+	if err != nil {
+		return nil, err
+	}
+	stats, err := namesdata.DiversityByYear(records, gender)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]map[string]int, len(stats))
+	for i, year := range stats {
+		out[i] = map[string]int{"year": year.Year, "births": year.Total, "names": year.Names}
+	}
+	return out, nil
+}
+
+// aggregate totals the names matching the filters in the dataset scope
+// selects, through s.aggregates when it is set. Entries are keyed as the
+// CLI keys them, so the two share results. Callers must not modify the
+// returned slice.
+func (s *Server) aggregate(ctx context.Context, scope, state string, year int, gender string) ([]namesdata.NameCount, int, error) {
+	if year < 0 {
+		return nil, 0, errors.New("year must be 0 or greater")
+	}
+	if err := s.checkScope(scope, state); err != nil {
+		return nil, 0, err
+	}
+	load := func() (cache.Entry, error) {
+		var (
+			aggregated []namesdata.NameCount
+			total      int
+			err        error
+		)
+		if scope == scopeNational {
+			aggregated, total, err = namesdata.AggregateNationalWeightedContext(ctx, s.national, gender, yearWeight(year))
+		} else {
+			aggregated, total, err = namesdata.AggregateFromFSWeightedContext(ctx, s.dataset, state, gender, yearWeight(year))
+		}
+		return cache.Entry{Aggregated: aggregated, Total: total}, err
+	}
+	if s.aggregates == nil {
+		entry, err := load()
+		return entry.Aggregated, entry.Total, err
+	}
+	years := ""
+	if year != 0 {
+		years = fmt.Sprintf("%d", year)
+	}
+	if scope == scopeNational {
+		state = namesdata.NationalState
+	}
+	entry, err := s.aggregates.GetOrLoad(cache.NewKey(state, years, gender), load)
+	return entry.Aggregated, entry.Total, err
+}
+
+// stream returns the records matching filter from the dataset scope selects.
+func (s *Server) stream(ctx context.Context, scope, state string, filter namesdata.Filter) (iter.Seq2[namesdata.Record, error], error) {
+	if err := s.checkScope(scope, state); err != nil {
+		return nil, err
+	}
+	if scope == scopeNational {
+		return namesdata.NationalRecordsContext(ctx, s.national, filter), nil
+	}
+	return namesdata.RecordsContext(ctx, s.dataset, filter), nil
+}
+
+// checkScope reports whether the dataset scope selects is available.
+func (s *Server) checkScope(scope, state string) error {
+	if scope != scopeNational {
+		return nil
+	}
+	if strings.TrimSpace(state) != "" {
+		return errors.New("scope NATIONAL cannot be combined with a state")
+	}
+	if s.national == nil {
+		return errors.New("the national dataset is not available on this server")
+	}
+	return nil
+}
+
+// yearWeight counts only year, or every year when year is 0.
+func yearWeight(year int) namesdata.YearWeight {
+	return func(y int) float64 {
+		if year != 0 && y != year {
+			return 0
+		}
+		return 1
+	}
+}
+
+func share(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+// request is a GraphQL request body.
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// ServeHTTP answers GraphQL requests: a POST with a JSON body holding query,
+// operationName, and variables, or a GET with the same as URL parameters
+// (variables JSON-encoded). The response is always the standard
+// {"data", "errors"} document.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if vars := query.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("variables: %w", err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use GET or POST"))
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("query is required"))
+		return
+	}
+	// Syntax errors are left for graphql.Do to report in the usual form.
+	if doc, err := parser.Parse(parser.ParseParams{Source: req.Query}); err == nil {
+		if err := s.checkCost(doc, req.OperationName); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeError answers a request that never reached the executor.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"errors": []map[string]string{{"message": err.Error()}}})
+}
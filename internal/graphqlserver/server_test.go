@@ -0,0 +1,204 @@
+package graphqlserver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/curtiscovington/ssa-names/internal/cache"
+	"github.com/curtiscovington/ssa-names/internal/graphqlserver"
+)
+
+func sampleFS() fstest.MapFS {
+	return fstest.MapFS{
+		"CA.TXT": {Data: []byte(
+			"CA,F,2019,Olivia,100\n" +
+				"CA,F,2019,Emma,90\n" +
+				"CA,M,2019,Liam,95\n" +
+				"CA,F,2018,Olivia,80\n" +
+				"CA,F,2018,Emma,50\n",
+		)},
+		"NY.TXT": {Data: []byte(
+			"NY,F,2019,Olivia,60\n" +
+				"NY,F,2018,Emma,45\n",
+		)},
+	}
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func post(t *testing.T, server *httptest.Server, query string, variables map[string]any) response {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatalf("encode request: %v", err)
+	}
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	defer resp.Body.Close()
+	var out response
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return out
+}
+
+func TestGraphQL(t *testing.T) {
+	srv, err := graphqlserver.New(sampleFS(), nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	out := post(t, server, `query($name: String!) {
+		name(name: $name, gender: F) {
+			name
+			rank(year: 2019) { rank count share }
+			trend(state: "CA") { year rank count }
+			states(year: 2019) { state rank count share }
+		}
+		top(state: "CA", year: 2019, limit: 2) { rank name count }
+		aggregate(gender: F) { total distinctNames }
+		states
+		years(state: "NY") { year births names }
+	}`, map[string]any{"name": "olivia"})
+	if len(out.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", out.Errors)
+	}
+
+	var data struct {
+		Name struct {
+			Name string
+			Rank struct {
+				Rank  int
+				Count int
+				Share float64
+			}
+			Trend []struct {
+				Year  int
+				Rank  *int
+				Count int
+			}
+			States []struct {
+				State string
+				Rank  int
+				Share float64
+			}
+		}
+		Top []struct {
+			Rank  int
+			Name  string
+			Count int
+		}
+		Aggregate struct {
+			Total         int
+			DistinctNames int
+		}
+		States []string
+		Years  []struct {
+			Year   int
+			Births int
+			Names  int
+		}
+	}
+	if err := json.Unmarshal(out.Data, &data); err != nil {
+		t.Fatalf("decode data: %v\n%s", err, out.Data)
+	}
+
+	// 2019 females: Olivia 160 of 250.
+	if data.Name.Rank.Rank != 1 || data.Name.Rank.Count != 160 || data.Name.Rank.Share != 0.64 {
+		t.Fatalf("unexpected rank: %+v", data.Name.Rank)
+	}
+	if len(data.Name.Trend) != 2 || data.Name.Trend[0].Year != 2018 || data.Name.Trend[0].Count != 80 || *data.Name.Trend[0].Rank != 1 {
+		t.Fatalf("unexpected trend: %+v", data.Name.Trend)
+	}
+	// Olivia is all of NY's 2019 births and 100 of CA's 190.
+	if len(data.Name.States) != 2 || data.Name.States[0].State != "NY" || data.Name.States[0].Share != 1 || data.Name.States[1].Rank != 1 {
+		t.Fatalf("unexpected states: %+v", data.Name.States)
+	}
+	if len(data.Top) != 2 || data.Top[0].Name != "Olivia" || data.Top[1].Name != "Liam" || data.Top[1].Rank != 2 {
+		t.Fatalf("unexpected top: %+v", data.Top)
+	}
+	if data.Aggregate.Total != 425 || data.Aggregate.DistinctNames != 2 {
+		t.Fatalf("unexpected aggregate: %+v", data.Aggregate)
+	}
+	if len(data.States) != 2 || data.States[0] != "CA" {
+		t.Fatalf("unexpected states list: %v", data.States)
+	}
+	if len(data.Years) != 2 || data.Years[1].Year != 2019 || data.Years[1].Births != 60 || data.Years[1].Names != 1 {
+		t.Fatalf("unexpected years: %+v", data.Years)
+	}
+
+	// Absent names have a null rank rather than an error.
+	out = post(t, server, `{ name(name: "Zelda") { rank { rank } states { state } } }`, nil)
+	if len(out.Errors) > 0 || string(out.Data) != `{"name":{"rank":null,"states":[]}}` {
+		t.Fatalf("unexpected absent-name result: %s %+v", out.Data, out.Errors)
+	}
+
+	out = post(t, server, `{ top(scope: NATIONAL) { name } }`, nil)
+	if len(out.Errors) == 0 {
+		t.Fatal("expected an error for the unavailable national dataset")
+	}
+
+	resp, err := http.Get(server.URL + "?query=" + url.QueryEscape(`{ states }`))
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected GET queries to work, got %d", resp.StatusCode)
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	aggregates := cache.New(4)
+	srv, err := graphqlserver.New(sampleFS(), nil, aggregates)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	// Repeated aggregates are answered from the shared cache.
+	for range 2 {
+		if out := post(t, server, `{ top(year: 2019) { name } name(name: "Emma") { rank(year: 2019) { rank } } }`, nil); len(out.Errors) > 0 {
+			t.Fatalf("unexpected errors: %+v", out.Errors)
+		}
+	}
+	if stats := aggregates.Stats(); stats.Misses != 1 || stats.Hits != 3 {
+		t.Fatalf("expected one scan shared by the other lookups, got %+v", stats)
+	}
+
+	var aliases strings.Builder
+	for i := range 20 {
+		fmt.Fprintf(&aliases, "y%d: rank(year: %d) { rank } ", i, 2000+i)
+	}
+	spread := `query {
+		a: name(name: "Olivia") { ...scans }
+		b: name(name: "Emma") { ...scans }
+		c: name(name: "Liam") { ...scans }
+		d: name(name: "Noah") { ...scans }
+		e: name(name: "Ava") { ...scans }
+	}
+	fragment scans on Name { rank { rank } trend { year } states { state } }`
+	for _, query := range []string{`{ name(name: "Olivia") { ` + aliases.String() + `} }`, spread} {
+		out := post(t, server, query, nil)
+		if len(out.Errors) != 1 || !strings.Contains(out.Errors[0].Message, "too complex") {
+			t.Fatalf("expected the query to be rejected as too complex, got %s %+v", out.Data, out.Errors)
+		}
+	}
+}
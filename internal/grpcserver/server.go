@@ -0,0 +1,289 @@
+// Package grpcserver implements the names.v1.NamesService gRPC service
+// defined in api/proto/names/v1 on top of the namesdata package.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"iter"
+	"math/rand"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	namesv1 "github.com/curtiscovington/ssa-names/api/proto/names/v1"
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// defaultTopLimit is the number of names Top returns when the request
+// leaves limit at zero, matching the top command's default.
+const defaultTopLimit = 10
+
+// maxGenerateCount bounds the names a single Generate call streams.
+const maxGenerateCount = 10000
+
+// Server serves NamesService from a names-by-state dataset and, optionally,
+// the national dataset.
+type Server struct {
+	namesv1.UnimplementedNamesServiceServer
+
+	dataset  fs.FS
+	national fs.FS
+}
+
+// New returns a Server over dataset. national may be nil, in which case
+// requests with SCOPE_NATIONAL fail with FailedPrecondition.
+func New(dataset, national fs.FS) *Server {
+	return &Server{dataset: dataset, national: national}
+}
+
+// Top returns the most popular names for the filters.
+func (s *Server) Top(ctx context.Context, req *namesv1.TopRequest) (*namesv1.TopResponse, error) {
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must be 0 or greater")
+	}
+	aggregated, total, err := s.aggregate(ctx, req.GetFilters())
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultTopLimit
+	}
+	limit = min(limit, len(aggregated))
+
+	resp := &namesv1.TopResponse{Total: int64(total), Names: make([]*namesv1.NameCount, limit)}
+	for i, entry := range aggregated[:limit] {
+		resp.Names[i] = nameCount(i+1, entry, total)
+	}
+	return resp, nil
+}
+
+// Rank returns one name's rank and count for the filters.
+func (s *Server) Rank(ctx context.Context, req *namesv1.RankRequest) (*namesv1.RankResponse, error) {
+	name := strings.TrimSpace(req.GetName())
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	aggregated, total, err := s.aggregate(ctx, req.GetFilters())
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range aggregated {
+		if strings.EqualFold(entry.Name, name) {
+			return &namesv1.RankResponse{Name: nameCount(i+1, entry, total), Total: int64(total)}, nil
+		}
+	}
+	return nil, statusError(&namesdata.NameNotFoundError{Name: name, Suggestions: namesdata.ClosestNames(aggregated, name, 3)})
+}
+
+// Trend returns the yearly rank and count of each requested name.
+func (s *Server) Trend(ctx context.Context, req *namesv1.TrendRequest) (*namesv1.TrendResponse, error) {
+	var names []string
+	for _, name := range req.GetNames() {
+		if trimmed := strings.TrimSpace(name); trimmed != "" {
+			names = append(names, trimmed)
+		}
+	}
+	if len(names) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one name is required")
+	}
+	if req.GetSplitGender() && strings.TrimSpace(req.GetGender()) != "" {
+		return nil, status.Error(codes.InvalidArgument, "split_gender cannot be combined with gender")
+	}
+	span := namesdata.YearRange{From: int(req.GetFrom()), To: int(req.GetTo())}
+	if span.From < 0 || span.To < 0 || (span.To != 0 && span.From > span.To) {
+		return nil, status.Error(codes.InvalidArgument, "from must not be after to")
+	}
+
+	records, err := s.stream(ctx, req.GetScope(), req.GetState(), namesdata.Filter{State: req.GetState(), From: span.From, To: span.To})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		years  []int
+		series []namesdata.TrendSeries
+	)
+	if req.GetSplitGender() {
+		years, series, _, err = namesdata.TrendByGenderSeq(records, names, span)
+	} else {
+		years, series, _, err = namesdata.TrendSeq(records, req.GetGender(), names, span)
+	}
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &namesv1.TrendResponse{Years: make([]int32, len(years))}
+	for i, year := range years {
+		resp.Years[i] = int32(year)
+	}
+	for _, entry := range series {
+		out := &namesv1.TrendSeries{Name: entry.Name, Gender: entry.Gender, Points: make([]*namesv1.TrendPoint, len(entry.Points))}
+		for i, p := range entry.Points {
+			out.Points[i] = &namesv1.TrendPoint{
+				Year:    int32(p.Year),
+				Rank:    int32(p.Rank),
+				Count:   int64(p.Count),
+				Present: p.Present,
+				Total:   int64(p.Total),
+			}
+		}
+		resp.Series = append(resp.Series, out)
+	}
+	return resp, nil
+}
+
+// Generate streams names drawn at random, weighted by popularity.
+func (s *Server) Generate(req *namesv1.GenerateRequest, stream namesv1.NamesService_GenerateServer) error {
+	count := int(req.GetCount())
+	switch {
+	case count < 0:
+		return status.Error(codes.InvalidArgument, "count must be 0 or greater")
+	case count == 0:
+		count = 1
+	case count > maxGenerateCount:
+		return status.Errorf(codes.InvalidArgument, "count must be at most %d", maxGenerateCount)
+	}
+
+	ctx := stream.Context()
+	aggregated, total, err := s.aggregate(ctx, req.GetFilters())
+	if err != nil {
+		return err
+	}
+	sampler, err := namesdata.NewNameSamplerWithStrategy(aggregated, namesdata.SamplerAuto, count)
+	if err != nil {
+		return statusError(err)
+	}
+
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = req.GetSeed()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	send := func(entry namesdata.NameCount) error {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		return stream.Send(&namesv1.GeneratedName{Name: entry.Name, Count: int64(entry.Count), Share: share(entry.Count, total)})
+	}
+	if req.GetUnique() {
+		picks, err := sampler.PickUnique(count, rng)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		for _, entry := range picks {
+			if err := send(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for range count {
+		entry, err := sampler.Pick(rng)
+		if err != nil {
+			return statusError(err)
+		}
+		if err := send(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aggregate totals the names matching filters in the dataset they select.
+func (s *Server) aggregate(ctx context.Context, filters *namesv1.Filters) ([]namesdata.NameCount, int, error) {
+	year := int(filters.GetYear())
+	if year < 0 {
+		return nil, 0, status.Error(codes.InvalidArgument, "year must be 0 or greater")
+	}
+	if err := s.checkScope(filters.GetScope(), filters.GetState()); err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		aggregated []namesdata.NameCount
+		total      int
+		err        error
+	)
+	if filters.GetScope() == namesv1.Scope_SCOPE_NATIONAL {
+		aggregated, total, err = namesdata.AggregateNationalWeightedContext(ctx, s.national, filters.GetGender(), func(y int) float64 {
+			if year != 0 && y != year {
+				return 0
+			}
+			return 1
+		})
+	} else {
+		aggregated, total, err = namesdata.AggregateFromFSContext(ctx, s.dataset, filters.GetState(), year, filters.GetGender())
+	}
+	if err != nil {
+		return nil, 0, statusError(err)
+	}
+	return aggregated, total, nil
+}
+
+// checkScope validates scope and reports whether its dataset is available.
+func (s *Server) checkScope(scope namesv1.Scope, state string) error {
+	switch scope {
+	case namesv1.Scope_SCOPE_UNSPECIFIED, namesv1.Scope_SCOPE_STATE:
+		return nil
+	case namesv1.Scope_SCOPE_NATIONAL:
+		if strings.TrimSpace(state) != "" {
+			return status.Error(codes.InvalidArgument, "SCOPE_NATIONAL cannot be combined with a state")
+		}
+		if s.national == nil {
+			return status.Error(codes.FailedPrecondition, "the national dataset is not available on this server")
+		}
+		return nil
+	}
+	return status.Errorf(codes.InvalidArgument, "unsupported scope %v", scope)
+}
+
+// stream returns the records matching filter from the dataset scope selects.
+func (s *Server) stream(ctx context.Context, scope namesv1.Scope, state string, filter namesdata.Filter) (iter.Seq2[namesdata.Record, error], error) {
+	if err := s.checkScope(scope, state); err != nil {
+		return nil, err
+	}
+	if scope == namesv1.Scope_SCOPE_NATIONAL {
+		return namesdata.NationalRecordsContext(ctx, s.national, filter), nil
+	}
+	return namesdata.RecordsContext(ctx, s.dataset, filter), nil
+}
+
+// statusError converts a namesdata error into a gRPC status error. Unknown
+// states are the caller's mistake and map to InvalidArgument; a missing name
+// maps to NotFound like an empty result.
+func statusError(err error) error {
+	var (
+		unknownState *namesdata.UnknownStateError
+		notFound     *namesdata.NameNotFoundError
+	)
+	switch {
+	case errors.As(err, &unknownState):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &notFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, namesdata.ErrNoMatches):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, namesdata.ErrNoNationalData):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func nameCount(rank int, entry namesdata.NameCount, total int) *namesv1.NameCount {
+	return &namesv1.NameCount{Rank: int32(rank), Name: entry.Name, Count: int64(entry.Count), Share: share(entry.Count, total)}
+}
+
+func share(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
@@ -0,0 +1,152 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"testing/fstest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	namesv1 "github.com/curtiscovington/ssa-names/api/proto/names/v1"
+	"github.com/curtiscovington/ssa-names/internal/grpcserver"
+)
+
+func sampleFS() fstest.MapFS {
+	return fstest.MapFS{
+		"CA.TXT": {Data: []byte(
+			"CA,F,2019,Olivia,100\n" +
+				"CA,F,2019,Emma,90\n" +
+				"CA,M,2019,Liam,95\n" +
+				"CA,F,2018,Olivia,80\n" +
+				"CA,F,2018,Emma,50\n",
+		)},
+		"NY.TXT": {Data: []byte(
+			"NY,F,2019,Olivia,60\n" +
+				"NY,F,2018,Emma,45\n",
+		)},
+	}
+}
+
+func newClient(t *testing.T) namesv1.NamesServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	namesv1.RegisterNamesServiceServer(server, grpcserver.New(sampleFS(), nil))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return namesv1.NewNamesServiceClient(conn)
+}
+
+func TestNamesService(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+
+	top, err := client.Top(ctx, &namesv1.TopRequest{Filters: &namesv1.Filters{Year: 2019, Gender: "F"}, Limit: 1})
+	if err != nil {
+		t.Fatalf("Top: %v", err)
+	}
+	if top.GetTotal() != 250 || len(top.GetNames()) != 1 {
+		t.Fatalf("unexpected top response: %v", top)
+	}
+	if got := top.GetNames()[0]; got.GetName() != "Olivia" || got.GetCount() != 160 || got.GetRank() != 1 || got.GetShare() != 0.64 {
+		t.Fatalf("unexpected top name: %v", got)
+	}
+
+	rank, err := client.Rank(ctx, &namesv1.RankRequest{Filters: &namesv1.Filters{State: "CA", Year: 2018}, Name: "emma"})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+	if rank.GetName().GetRank() != 2 || rank.GetName().GetCount() != 50 || rank.GetTotal() != 130 {
+		t.Fatalf("unexpected rank response: %v", rank)
+	}
+
+	trend, err := client.Trend(ctx, &namesv1.TrendRequest{Names: []string{"Emma"}, State: "CA", Gender: "F"})
+	if err != nil {
+		t.Fatalf("Trend: %v", err)
+	}
+	if len(trend.GetYears()) != 2 || trend.GetYears()[0] != 2018 || len(trend.GetSeries()) != 1 {
+		t.Fatalf("unexpected trend response: %v", trend)
+	}
+	if point := trend.GetSeries()[0].GetPoints()[1]; point.GetYear() != 2019 || point.GetRank() != 2 || point.GetCount() != 90 || !point.GetPresent() {
+		t.Fatalf("unexpected 2019 trend point: %v", point)
+	}
+
+	stream, err := client.Generate(ctx, &namesv1.GenerateRequest{Filters: &namesv1.Filters{State: "CA", Year: 2019}, Count: 3, Unique: true, Seed: proto.Int64(7)})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	seen := make(map[string]bool)
+	for {
+		name, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Generate recv: %v", err)
+		}
+		if seen[name.GetName()] {
+			t.Fatalf("expected unique names, got %s twice", name.GetName())
+		}
+		seen[name.GetName()] = true
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected 3 streamed names, got %v", seen)
+	}
+
+	for _, tc := range []struct {
+		call func() error
+		code codes.Code
+	}{
+		{func() error {
+			_, err := client.Rank(ctx, &namesv1.RankRequest{Filters: &namesv1.Filters{State: "NY"}, Name: "Liam"})
+			return err
+		}, codes.NotFound},
+		{func() error {
+			_, err := client.Top(ctx, &namesv1.TopRequest{Filters: &namesv1.Filters{Year: 1900}})
+			return err
+		}, codes.NotFound},
+		{func() error {
+			_, err := client.Trend(ctx, &namesv1.TrendRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{func() error {
+			_, err := client.Top(ctx, &namesv1.TopRequest{Filters: &namesv1.Filters{Scope: namesv1.Scope_SCOPE_NATIONAL}})
+			return err
+		}, codes.FailedPrecondition},
+		{func() error {
+			_, err := client.Top(ctx, &namesv1.TopRequest{Filters: &namesv1.Filters{State: "ZZ"}})
+			return err
+		}, codes.InvalidArgument},
+		{func() error {
+			_, err := client.Trend(ctx, &namesv1.TrendRequest{Names: []string{"Olivia"}, State: "ZZ"})
+			return err
+		}, codes.InvalidArgument},
+		{func() error {
+			_, err := client.Rank(ctx, &namesv1.RankRequest{Filters: &namesv1.Filters{State: "CA"}, Name: "Olivai"})
+			return err
+		}, codes.NotFound},
+	} {
+		if got := status.Code(tc.call()); got != tc.code {
+			t.Fatalf("expected %v, got %v", tc.code, got)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+// Package index implements a compact binary encoding of the names dataset.
+// It stores one block per state holding the name counts for every year and
+// gender, with names interned in a shared table, so loading a dataset skips
+// the text parsing and most of the allocations of the raw files.
+//
+// The index is built at generate time (see cmd/names-index) and stored next
+// to the raw files as FileName. Loaders that find no index fall back to
+// parsing the text files.
+package index
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// FileName is the name of the index file within a dataset directory.
+const FileName = "names.idx"
+
+const magic = "SSAIDX1\n"
+
+// ErrCorrupt is returned when index data cannot be decoded.
+var ErrCorrupt = errors.New("index: corrupt data")
+
+// Record mirrors a dataset row. Counts for the same state, year, gender, and
+// name are summed when the index is built.
+type Record struct {
+	State  string
+	Gender string
+	Year   int
+	Name   string
+	Count  int
+}
+
+type groupKey struct {
+	year   int
+	gender string
+}
+
+// Builder accumulates records and encodes them as an index.
+type Builder struct {
+	names  map[string]int
+	order  []string
+	states map[string]map[groupKey]map[int]int
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		names:  make(map[string]int),
+		states: make(map[string]map[groupKey]map[int]int),
+	}
+}
+
+// Add records a row in the index.
+func (b *Builder) Add(r Record) {
+	id, ok := b.names[r.Name]
+	if !ok {
+		id = len(b.order)
+		b.names[r.Name] = id
+		b.order = append(b.order, r.Name)
+	}
+
+	state := strings.ToUpper(r.State)
+	groups, ok := b.states[state]
+	if !ok {
+		groups = make(map[groupKey]map[int]int)
+		b.states[state] = groups
+	}
+	key := groupKey{year: r.Year, gender: r.Gender}
+	counts, ok := groups[key]
+	if !ok {
+		counts = make(map[int]int)
+		groups[key] = counts
+	}
+	counts[id] += r.Count
+}
+
+// WriteTo encodes the index. The layout is the magic string, the name table,
+// a directory of states with the byte length of each state's block, and then
+// the blocks. Every integer is a uvarint. Within a block, groups are sorted by
+// year then gender and entries by descending count then name.
+func (b *Builder) WriteTo(w io.Writer) (int64, error) {
+	states := make([]string, 0, len(b.states))
+	for state := range b.states {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	blocks := make([][]byte, len(states))
+	for i, state := range states {
+		blocks[i] = b.encodeState(b.states[state])
+	}
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	cw.writeString(magic)
+	cw.writeUvarint(uint64(len(b.order)))
+	for _, name := range b.order {
+		cw.writeUvarint(uint64(len(name)))
+		cw.writeString(name)
+	}
+	cw.writeUvarint(uint64(len(states)))
+	for i, state := range states {
+		cw.writeUvarint(uint64(len(state)))
+		cw.writeString(state)
+		cw.writeUvarint(uint64(len(blocks[i])))
+	}
+	for _, block := range blocks {
+		cw.write(block)
+	}
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func (b *Builder) encodeState(groups map[groupKey]map[int]int) []byte {
+	keys := make([]groupKey, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].year != keys[j].year {
+			return keys[i].year < keys[j].year
+		}
+		return keys[i].gender < keys[j].gender
+	})
+
+	buf := binary.AppendUvarint(nil, uint64(len(keys)))
+	for _, key := range keys {
+		counts := groups[key]
+		ids := make([]int, 0, len(counts))
+		for id := range counts {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if counts[ids[i]] != counts[ids[j]] {
+				return counts[ids[i]] > counts[ids[j]]
+			}
+			return b.order[ids[i]] < b.order[ids[j]]
+		})
+
+		buf = binary.AppendUvarint(buf, uint64(key.year))
+		buf = binary.AppendUvarint(buf, uint64(len(key.gender)))
+		buf = append(buf, key.gender...)
+		buf = binary.AppendUvarint(buf, uint64(len(ids)))
+		for _, id := range ids {
+			buf = binary.AppendUvarint(buf, uint64(id))
+			buf = binary.AppendUvarint(buf, uint64(counts[id]))
+		}
+	}
+	return buf
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (c *countingWriter) write(p []byte) {
+	if c.err != nil {
+		return
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+}
+
+func (c *countingWriter) writeString(s string) {
+	if c.err != nil {
+		return
+	}
+	n, err := c.w.WriteString(s)
+	c.n += int64(n)
+	c.err = err
+}
+
+func (c *countingWriter) writeUvarint(v uint64) {
+	n := binary.PutUvarint(c.tmp[:], v)
+	c.write(c.tmp[:n])
+}
+
+// Index is a decoded index ready for reading.
+type Index struct {
+	names  []string
+	states []string
+	blocks map[string][]byte
+}
+
+// Parse decodes index data. The returned Index references data, which must
+// not be modified afterwards.
+func Parse(data []byte) (*Index, error) {
+	if len(data) < len(magic) || string(data[:len(magic)]) != magic {
+		return nil, fmt.Errorf("%w: missing header", ErrCorrupt)
+	}
+	d := decoder{data: data, pos: len(magic)}
+
+	nameCount := d.uvarint()
+	if d.err == nil && nameCount > uint64(len(data)) {
+		d.err = ErrCorrupt
+	}
+	names := make([]string, 0, nameCount)
+	for i := uint64(0); i < nameCount && d.err == nil; i++ {
+		names = append(names, d.string())
+	}
+
+	stateCount := d.uvarint()
+	if d.err == nil && stateCount > uint64(len(data)) {
+		d.err = ErrCorrupt
+	}
+	states := make([]string, 0, stateCount)
+	sizes := make([]int, 0, stateCount)
+	for i := uint64(0); i < stateCount && d.err == nil; i++ {
+		states = append(states, d.string())
+		sizes = append(sizes, int(d.uvarint()))
+	}
+
+	blocks := make(map[string][]byte, len(states))
+	for i, state := range states {
+		if d.err != nil {
+			break
+		}
+		blocks[state] = d.bytes(sizes[i])
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, d.err)
+	}
+
+	return &Index{names: names, states: states, blocks: blocks}, nil
+}
+
+// States returns the sorted state codes in the index.
+func (x *Index) States() []string {
+	return append([]string(nil), x.states...)
+}
+
+// Walk calls fn for every record of state, or of all states in order when
+// state is empty. Within a state, records are ordered by year then gender.
+func (x *Index) Walk(state string, fn func(Record) error) error {
+	if state == "" {
+		for _, code := range x.states {
+			if err := x.walkState(code, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	code := strings.ToUpper(state)
+	if _, ok := x.blocks[code]; !ok {
+		return fmt.Errorf("index: unknown state %q", state)
+	}
+	return x.walkState(code, fn)
+}
+
+func (x *Index) walkState(state string, fn func(Record) error) error {
+	d := decoder{data: x.blocks[state]}
+	groups := d.uvarint()
+	for g := uint64(0); g < groups && d.err == nil; g++ {
+		year := int(d.uvarint())
+		gender := d.string()
+		entries := d.uvarint()
+		for e := uint64(0); e < entries && d.err == nil; e++ {
+			id := d.uvarint()
+			count := d.uvarint()
+			if d.err != nil {
+				break
+			}
+			if id >= uint64(len(x.names)) {
+				return fmt.Errorf("%w: name id %d out of range", ErrCorrupt, id)
+			}
+			record := Record{State: state, Gender: gender, Year: year, Name: x.names[id], Count: int(count)}
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+	}
+	if d.err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, d.err)
+	}
+	return nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	d.pos += n
+	return v
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.data)-d.pos {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes(int(d.uvarint())))
+}
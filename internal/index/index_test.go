@@ -0,0 +1,69 @@
+package index_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/index"
+)
+
+func TestIndexRoundTrip(t *testing.T) {
+	builder := index.NewBuilder()
+	for _, r := range []index.Record{
+		{State: "NY", Gender: "F", Year: 2019, Name: "Olivia", Count: 60},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Olivia", Count: 100},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Olivia", Count: 40},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Emma", Count: 90},
+		{State: "CA", Gender: "M", Year: 2018, Name: "Liam", Count: 85},
+	} {
+		builder.Add(r)
+	}
+
+	var buf bytes.Buffer
+	n, err := builder.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("WriteTo reported %d bytes, wrote %d", n, buf.Len())
+	}
+
+	idx, err := index.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if states := idx.States(); len(states) != 2 || states[0] != "CA" || states[1] != "NY" {
+		t.Fatalf("unexpected states: %v", states)
+	}
+
+	var got []index.Record
+	if err := idx.Walk("ca", func(r index.Record) error {
+		got = append(got, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	want := []index.Record{
+		{State: "CA", Gender: "M", Year: 2018, Name: "Liam", Count: 85},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Olivia", Count: 140},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Emma", Count: 90},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d records, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("record %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	all := 0
+	if err := idx.Walk("", func(index.Record) error { all++; return nil }); err != nil || all != 4 {
+		t.Fatalf("expected 4 records across states, got %d (%v)", all, err)
+	}
+
+	if _, err := index.Parse(buf.Bytes()[:buf.Len()-3]); !errors.Is(err, index.ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt for truncated data, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+// Package metrics collects Prometheus metrics for the serve command:
+// request counts and latencies per endpoint, the time spent loading
+// aggregates from the dataset, and the aggregate cache counters.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/curtiscovington/ssa-names/internal/cache"
+)
+
+const namespace = "names"
+
+// Metrics holds the collectors for one server. It is safe for concurrent
+// use.
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	loads    prometheus.Histogram
+}
+
+// New registers the serve metrics, along with the Go runtime and process
+// collectors, on a fresh registry. When c is non-nil its hit, miss, and
+// eviction counters and its size are exported too.
+func New(c *cache.Cache) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by endpoint and status code.",
+		}, []string{"endpoint", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to serve an HTTP request, by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		loads: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "dataset_load_duration_seconds",
+			Help:      "Time to read and aggregate records from the dataset when the cache cannot answer.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.latency,
+		m.loads,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if c != nil {
+		m.registry.MustRegister(cacheCollector{cache: c})
+	}
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Instrument wraps next so each request is counted and timed under
+// endpoint.
+func (m *Metrics) Instrument(endpoint string, next http.Handler) http.Handler {
+	latency := m.latency.WithLabelValues(endpoint)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		latency.Observe(time.Since(start).Seconds())
+		m.requests.WithLabelValues(endpoint, strconv.Itoa(rec.status)).Inc()
+	})
+}
+
+// ObserveLoad records the time taken by one dataset load.
+func (m *Metrics) ObserveLoad(d time.Duration) {
+	m.loads.Observe(d.Seconds())
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, so
+// streaming handlers can still flush.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+var (
+	cacheHitsDesc = prometheus.NewDesc(namespace+"_cache_hits_total",
+		"Aggregate cache lookups answered from memory.", nil, nil)
+	cacheMissesDesc = prometheus.NewDesc(namespace+"_cache_misses_total",
+		"Aggregate cache lookups that had to load from the dataset.", nil, nil)
+	cacheEvictionsDesc = prometheus.NewDesc(namespace+"_cache_evictions_total",
+		"Aggregates dropped from the cache to make room.", nil, nil)
+	cacheSizeDesc = prometheus.NewDesc(namespace+"_cache_entries",
+		"Aggregates currently held in the cache.", nil, nil)
+	cacheCapacityDesc = prometheus.NewDesc(namespace+"_cache_capacity",
+		"Most aggregates the cache holds.", nil, nil)
+)
+
+// cacheCollector exports a snapshot of the cache's counters on each scrape.
+type cacheCollector struct {
+	cache *cache.Cache
+}
+
+func (c cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheEvictionsDesc
+	ch <- cacheSizeDesc
+	ch <- cacheCapacityDesc
+}
+
+func (c cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.cache.Stats()
+	ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(cacheEvictionsDesc, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(cacheSizeDesc, prometheus.GaugeValue, float64(stats.Size))
+	ch <- prometheus.MustNewConstMetric(cacheCapacityDesc, prometheus.GaugeValue, float64(stats.Capacity))
+}
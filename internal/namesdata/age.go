@@ -0,0 +1,111 @@
+package namesdata
+
+import (
+	"errors"
+	"iter"
+	"math"
+	"sort"
+	"strings"
+)
+
+// YearDistribution is how a name's births are spread across years: an
+// estimate of when people with the name were born, and so of how old they
+// are today.
+type YearDistribution struct {
+	Name string
+	// Years is sorted ascending; Counts[i] is the births recorded in
+	// Years[i]. Years without births are omitted.
+	Years  []int
+	Counts []int
+	Total  int
+	// LatestYear is the latest year in the scanned records, for any name.
+	// Ages are measured from it.
+	LatestYear int
+}
+
+// Quantile returns the earliest year by which at least q (0 to 1) of the
+// name's births had occurred.
+func (d YearDistribution) Quantile(q float64) int {
+	if len(d.Years) == 0 {
+		return 0
+	}
+	target := int(math.Ceil(q * float64(d.Total)))
+	running := 0
+	for i, count := range d.Counts {
+		running += count
+		if running >= target {
+			return d.Years[i]
+		}
+	}
+	return d.Years[len(d.Years)-1]
+}
+
+// Median returns the median birth year.
+func (d YearDistribution) Median() int {
+	return d.Quantile(0.5)
+}
+
+// Peak returns the year with the most births, the earliest on ties.
+func (d YearDistribution) Peak() int {
+	best := 0
+	for i, count := range d.Counts {
+		if count > d.Counts[best] {
+			best = i
+		}
+	}
+	if len(d.Years) == 0 {
+		return 0
+	}
+	return d.Years[best]
+}
+
+// Age returns how old someone born in year is as of LatestYear.
+func (d YearDistribution) Age(year int) int {
+	return d.LatestYear - year
+}
+
+// BirthYears builds the distribution of birth years for name
+// (case-insensitive) from records, keeping only the gender given ("M",
+// "F", or empty for both). Every record is scanned so LatestYear reflects
+// the whole dataset rather than the name's last appearance.
+func BirthYears(records iter.Seq2[Record, error], gender, name string) (YearDistribution, error) {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if key == "" {
+		return YearDistribution{}, errors.New("name is required")
+	}
+	gender = strings.ToUpper(strings.TrimSpace(gender))
+
+	dist := YearDistribution{Name: strings.TrimSpace(name)}
+	byYear := make(map[int]int)
+	for rec, err := range records {
+		if err != nil {
+			return YearDistribution{}, err
+		}
+		dist.LatestYear = max(dist.LatestYear, rec.Year)
+		if rec.Count <= 0 || strings.ToUpper(rec.Name) != key {
+			continue
+		}
+		if gender != "" && strings.ToUpper(rec.Gender) != gender {
+			continue
+		}
+		if dist.Total == 0 {
+			dist.Name = rec.Name
+		}
+		byYear[rec.Year] += rec.Count
+		dist.Total += rec.Count
+	}
+	if dist.Total == 0 {
+		return YearDistribution{}, ErrNoMatches
+	}
+
+	dist.Years = make([]int, 0, len(byYear))
+	for year := range byYear {
+		dist.Years = append(dist.Years, year)
+	}
+	sort.Ints(dist.Years)
+	dist.Counts = make([]int, len(dist.Years))
+	for i, year := range dist.Years {
+		dist.Counts[i] = byYear[year]
+	}
+	return dist, nil
+}
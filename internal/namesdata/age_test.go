@@ -0,0 +1,42 @@
+package namesdata_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestBirthYears(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 1920, Name: "Gertrude", Count: 10},
+		{State: "CA", Gender: "F", Year: 1921, Name: "gertrude", Count: 40},
+		{State: "NY", Gender: "F", Year: 1921, Name: "Gertrude", Count: 10},
+		{State: "CA", Gender: "F", Year: 1930, Name: "Gertrude", Count: 20},
+		{State: "CA", Gender: "F", Year: 1950, Name: "Gertrude", Count: 20},
+		{State: "CA", Gender: "M", Year: 1900, Name: "Gertrude", Count: 99},
+		{State: "CA", Gender: "F", Year: 2020, Name: "Ava", Count: 5},
+	}
+
+	dist, err := namesdata.BirthYears(namesdata.SliceRecords(records), "F", "GERTRUDE")
+	if err != nil {
+		t.Fatalf("BirthYears: %v", err)
+	}
+	if dist.Name != "Gertrude" || dist.Total != 100 || dist.LatestYear != 2020 {
+		t.Fatalf("unexpected distribution: %+v", dist)
+	}
+	if len(dist.Years) != 4 || dist.Counts[1] != 50 {
+		t.Fatalf("expected 1921 births to be combined, got %v %v", dist.Years, dist.Counts)
+	}
+	// Cumulative: 1920 10, 1921 60, 1930 80, 1950 100.
+	if q1, median, q3 := dist.Quantile(0.25), dist.Median(), dist.Quantile(0.75); q1 != 1921 || median != 1921 || q3 != 1930 {
+		t.Fatalf("unexpected quartiles %d %d %d", q1, median, q3)
+	}
+	if dist.Peak() != 1921 || dist.Age(1921) != 99 {
+		t.Fatalf("unexpected peak %d or age %d", dist.Peak(), dist.Age(1921))
+	}
+
+	if _, err := namesdata.BirthYears(namesdata.SliceRecords(records), "M", "Ava"); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+}
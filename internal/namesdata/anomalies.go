@@ -0,0 +1,117 @@
+package namesdata
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Anomaly is a name whose count in one year jumped well above its trailing
+// baseline.
+type Anomaly struct {
+	Name  string
+	Count int
+	// Mean and StdDev describe the name's yearly counts over the baseline
+	// years, counting years it was absent as zero.
+	Mean   float64
+	StdDev float64
+	// Z is how many standard deviations Count is above Mean. The standard
+	// deviation is floored at the square root of the mean (and at 1), the
+	// noise expected of a count that size, so a name that was steady or
+	// absent does not score an enormous Z for a handful of births. Z only
+	// decides whether a name is listed: a debut's floor is 1, so its Z is
+	// its whole count and says little about how it compares with a spike
+	// over a real baseline.
+	Z float64
+}
+
+// Spike returns how many more births the name had than its baseline mean.
+func (a Anomaly) Spike() float64 {
+	return float64(a.Count) - a.Mean
+}
+
+// Anomalies compares every name's count in year against its counts over
+// the baseline years before it and returns the names given at least
+// minCount times in year whose Z is at least minZ, largest Spike first. Only
+// baseline years that appear in records count, so a year near the start of
+// the data has a shorter baseline; ErrNoMatches is returned when year or
+// every baseline year is missing. gender can be "M", "F", or empty for all.
+func Anomalies(records []Record, gender string, year, baseline int, minZ float64, minCount int) ([]Anomaly, error) {
+	if baseline < 1 {
+		return nil, fmt.Errorf("baseline must be at least 1 year, got %d", baseline)
+	}
+	gender = strings.ToUpper(strings.TrimSpace(gender))
+	first := year - baseline
+
+	// counts holds each name's count for first..year, indexed from first.
+	counts := make(map[string][]int)
+	names := make(map[string]string)
+	present := make([]bool, baseline+1)
+	for _, r := range records {
+		if r.Year < first || r.Year > year {
+			continue
+		}
+		if gender != "" && strings.ToUpper(r.Gender) != gender {
+			continue
+		}
+		key := strings.ToUpper(r.Name)
+		yearly, ok := counts[key]
+		if !ok {
+			yearly = make([]int, baseline+1)
+			counts[key] = yearly
+			names[key] = r.Name
+		}
+		yearly[r.Year-first] += r.Count
+		present[r.Year-first] = true
+	}
+
+	var baselineYears []int
+	for i, ok := range present[:baseline] {
+		if ok {
+			baselineYears = append(baselineYears, i)
+		}
+	}
+	if !present[baseline] || len(baselineYears) == 0 {
+		return nil, ErrNoMatches
+	}
+
+	var result []Anomaly
+	for key, yearly := range counts {
+		count := yearly[baseline]
+		if count < minCount || count == 0 {
+			continue
+		}
+		mean := 0.0
+		for _, i := range baselineYears {
+			mean += float64(yearly[i])
+		}
+		mean /= float64(len(baselineYears))
+		variance := 0.0
+		for _, i := range baselineYears {
+			d := float64(yearly[i]) - mean
+			variance += d * d
+		}
+		stdDev := math.Sqrt(variance / float64(len(baselineYears)))
+
+		z := (float64(count) - mean) / max(stdDev, math.Sqrt(mean), 1)
+		if z < minZ {
+			continue
+		}
+		result = append(result, Anomaly{Name: names[key], Count: count, Mean: mean, StdDev: stdDev, Z: z})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if si, sj := result[i].Spike(), result[j].Spike(); si != sj {
+			return si > sj
+		}
+		if result[i].Z != result[j].Z {
+			return result[i].Z > result[j].Z
+		}
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestAnomalies(t *testing.T) {
+	var records []namesdata.Record
+	for year := 2008; year <= 2012; year++ {
+		records = append(records,
+			namesdata.Record{State: "CA", Gender: "F", Year: year, Name: "Emma", Count: 100},
+			namesdata.Record{State: "CA", Gender: "F", Year: year, Name: "Arya", Count: 10},
+		)
+	}
+	records = append(records,
+		namesdata.Record{State: "CA", Gender: "F", Year: 2013, Name: "Emma", Count: 105},
+		namesdata.Record{State: "CA", Gender: "F", Year: 2013, Name: "Arya", Count: 30},
+		namesdata.Record{State: "CA", Gender: "F", Year: 2013, Name: "Khaleesi", Count: 40},
+		namesdata.Record{State: "TX", Gender: "F", Year: 2013, Name: "khaleesi", Count: 10},
+		namesdata.Record{State: "CA", Gender: "M", Year: 2013, Name: "Arya", Count: 500},
+	)
+
+	anomalies, err := namesdata.Anomalies(records, "F", 2013, 5, 3, 5)
+	if err != nil {
+		t.Fatalf("Anomalies: %v", err)
+	}
+	if len(anomalies) != 2 {
+		t.Fatalf("expected Khaleesi and Arya, got %+v", anomalies)
+	}
+	if a := anomalies[0]; a.Name != "Khaleesi" || a.Count != 50 || a.Mean != 0 || a.Z != 50 || a.Spike() != 50 {
+		t.Fatalf("unexpected first anomaly: %+v", a)
+	}
+	// Arya was a steady 10 a year, so her deviation is floored at sqrt(10).
+	if a := anomalies[1]; a.Name != "Arya" || a.StdDev != 0 || math.Abs(a.Z-20/math.Sqrt(10)) > 1e-9 {
+		t.Fatalf("unexpected second anomaly: %+v", a)
+	}
+
+	if got, err := namesdata.Anomalies(records, "F", 2013, 5, 3, 45); err != nil || len(got) != 1 || got[0].Name != "Khaleesi" {
+		t.Fatalf("expected --min-count to drop Arya, got %+v (%v)", got, err)
+	}
+
+	if _, err := namesdata.Anomalies(records, "F", 2008, 3, 3, 1); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches without baseline years, got %v", err)
+	}
+	if _, err := namesdata.Anomalies(records, "F", 2020, 3, 3, 1); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches for a year without data, got %v", err)
+	}
+	if _, err := namesdata.Anomalies(records, "F", 2013, 0, 3, 1); err == nil {
+		t.Fatal("expected an error for an empty baseline")
+	}
+}
+
+func TestAnomaliesRanksSpikesAboveDebuts(t *testing.T) {
+	var records []namesdata.Record
+	for year := 2008; year <= 2012; year++ {
+		records = append(records, namesdata.Record{State: "CA", Gender: "M", Year: year, Name: "Jayceon", Count: 100})
+	}
+	records = append(records,
+		namesdata.Record{State: "CA", Gender: "M", Year: 2013, Name: "Jayceon", Count: 400},
+		namesdata.Record{State: "CA", Gender: "M", Year: 2013, Name: "Jaceon", Count: 40},
+	)
+
+	anomalies, err := namesdata.Anomalies(records, "M", 2013, 5, 3, 5)
+	if err != nil {
+		t.Fatalf("Anomalies: %v", err)
+	}
+	// Jaceon's debut scores a Z of 40 against Jayceon's 30, but Jayceon
+	// gained 300 births to Jaceon's 40.
+	if len(anomalies) != 2 || anomalies[0].Name != "Jayceon" || anomalies[1].Name != "Jaceon" {
+		t.Fatalf("expected Jayceon's spike before Jaceon's debut, got %+v", anomalies)
+	}
+	if anomalies[0].Z >= anomalies[1].Z {
+		t.Fatalf("expected the debut to have the larger Z, got %+v", anomalies)
+	}
+}
@@ -0,0 +1,87 @@
+package namesdata
+
+import (
+	"errors"
+	"strings"
+)
+
+// Appearance summarizes the years a name is recorded in.
+type Appearance struct {
+	Name string
+	// First and Last are the first and last years the name appears in.
+	First int
+	Last  int
+	// Years counts the years the name appears in.
+	Years int
+	// StreakStart and StreakEnd bound the longest run of consecutive years
+	// the name appears in, the earliest run when several tie.
+	StreakStart int
+	StreakEnd   int
+}
+
+// Streak returns the length in years of the longest consecutive run.
+func (a Appearance) Streak() int {
+	if a.StreakEnd == 0 {
+		return 0
+	}
+	return a.StreakEnd - a.StreakStart + 1
+}
+
+// Appearance returns the years name is recorded in. ok is false when the
+// name is not indexed.
+func (x *FirstSeenIndex) Appearance(name string) (Appearance, bool) {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	years := x.years[key]
+	if len(years) == 0 {
+		return Appearance{}, false
+	}
+
+	a := Appearance{
+		Name:        x.names[key],
+		First:       years[0],
+		Last:        years[len(years)-1],
+		Years:       len(years),
+		StreakStart: years[0],
+		StreakEnd:   years[0],
+	}
+	start := years[0]
+	for i := 1; i < len(years); i++ {
+		if years[i] != years[i-1]+1 {
+			start = years[i]
+		}
+		if years[i]-start > a.StreakEnd-a.StreakStart {
+			a.StreakStart, a.StreakEnd = start, years[i]
+		}
+	}
+	return a, true
+}
+
+// Appearances reports the years name (case-insensitive) is recorded in,
+// counting records of the gender filter ("M", "F", or empty for both). The
+// records determine the scope, such as one state or the whole country. A
+// *NameNotFoundError is returned when the name never appears.
+func Appearances(records []Record, gender, name string) (Appearance, error) {
+	if strings.TrimSpace(name) == "" {
+		return Appearance{}, errors.New("name is required")
+	}
+	a, ok := NewFirstSeenIndex(records, gender).Appearance(name)
+	if !ok {
+		aggregated, _ := AggregateNames(records, 0, gender)
+		return Appearance{}, newNameNotFoundError(name, aggregated)
+	}
+	return a, nil
+}
+
+// FirstAppearance returns the first year name is recorded in. See
+// Appearances.
+func FirstAppearance(records []Record, gender, name string) (int, error) {
+	a, err := Appearances(records, gender, name)
+	return a.First, err
+}
+
+// LastAppearance returns the last year name is recorded in. See
+// Appearances.
+func LastAppearance(records []Record, gender, name string) (int, error) {
+	a, err := Appearances(records, gender, name)
+	return a.Last, err
+}
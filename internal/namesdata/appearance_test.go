@@ -0,0 +1,37 @@
+package namesdata_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestAppearances(t *testing.T) {
+	var records []namesdata.Record
+	for _, year := range []int{1950, 1951, 1960, 1961, 1962, 1970, 1971, 1972} {
+		records = append(records, namesdata.Record{State: "CA", Gender: "F", Year: year, Name: "Linda", Count: 10})
+	}
+	records = append(records, namesdata.Record{State: "CA", Gender: "M", Year: 1940, Name: "Linda", Count: 5})
+
+	a, err := namesdata.Appearances(records, "F", "linda")
+	if err != nil {
+		t.Fatalf("Appearances: %v", err)
+	}
+	// Two three-year runs tie; the earlier one wins.
+	if a.Name != "Linda" || a.First != 1950 || a.Last != 1972 || a.Years != 8 || a.StreakStart != 1960 || a.StreakEnd != 1962 || a.Streak() != 3 {
+		t.Fatalf("unexpected appearance: %+v", a)
+	}
+
+	if first, err := namesdata.FirstAppearance(records, "", "Linda"); err != nil || first != 1940 {
+		t.Fatalf("FirstAppearance = %d, %v", first, err)
+	}
+	if last, err := namesdata.LastAppearance(records, "M", "Linda"); err != nil || last != 1940 {
+		t.Fatalf("LastAppearance = %d, %v", last, err)
+	}
+
+	var notFound *namesdata.NameNotFoundError
+	if _, err := namesdata.Appearances(records, "F", "Lynda"); !errors.As(err, &notFound) || len(notFound.Suggestions) != 1 || notFound.Suggestions[0] != "Linda" {
+		t.Fatalf("expected a not-found error suggesting Linda, got %v", err)
+	}
+}
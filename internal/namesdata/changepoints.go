@@ -0,0 +1,113 @@
+package namesdata
+
+import (
+	"math"
+	"sort"
+)
+
+// ChangepointMinSegment is the fewest observed years allowed on either side
+// of a changepoint, so a single unusual year is not mistaken for a shift.
+const ChangepointMinSegment = 3
+
+// DefaultChangepointShift is the relative shift Changepoints callers use
+// unless they have a reason to pick another: the share on one side must be
+// at most half of the share on the other, i.e. it doubled or halved.
+const DefaultChangepointShift = 0.5
+
+// Changepoint is a year where a series' share of births moved to a new
+// level.
+type Changepoint struct {
+	// Year is the first year of the new level.
+	Year int
+	// Before and After are the mean shares of births over the segments on
+	// either side of Year.
+	Before float64
+	After  float64
+}
+
+// Rising reports whether the share went up at the changepoint.
+func (c Changepoint) Rising() bool {
+	return c.After > c.Before
+}
+
+// Changepoints finds the years where the series' share of births shifted
+// to a new level, by binary segmentation: each stretch of years is split
+// where the difference between the mean shares on either side explains the
+// most variance, and the split is kept when that explains at least half of
+// the stretch's variance and the smaller mean is no more than 1-minShift of
+// the larger. Kept splits are searched again on both sides. Forecast points
+// and years without a birth total are skipped, and years the name is absent
+// count as a share of zero. Changepoints are returned in year order.
+func (s TrendSeries) Changepoints(minShift float64) []Changepoint {
+	var (
+		years  []int
+		shares []float64
+	)
+	for _, point := range s.Points {
+		if point.Forecast || point.Total <= 0 {
+			continue
+		}
+		share := 0.0
+		if point.Present {
+			share = float64(point.Count) / float64(point.Total)
+		}
+		years = append(years, point.Year)
+		shares = append(shares, share)
+	}
+
+	// Prefix sums of the shares and their squares give each segment's mean
+	// and sum of squared deviations in constant time.
+	sum := make([]float64, len(shares)+1)
+	sumSq := make([]float64, len(shares)+1)
+	for i, v := range shares {
+		sum[i+1] = sum[i] + v
+		sumSq[i+1] = sumSq[i] + v*v
+	}
+	mean := func(lo, hi int) float64 {
+		return (sum[hi] - sum[lo]) / float64(hi-lo)
+	}
+	sse := func(lo, hi int) float64 {
+		total := sum[hi] - sum[lo]
+		return sumSq[hi] - sumSq[lo] - total*total/float64(hi-lo)
+	}
+
+	var breaks []int
+	var split func(lo, hi int)
+	split = func(lo, hi int) {
+		if hi-lo < 2*ChangepointMinSegment {
+			return
+		}
+		whole := sse(lo, hi)
+		if whole <= 0 {
+			return
+		}
+		best, bestSSE := -1, math.Inf(1)
+		for k := lo + ChangepointMinSegment; k <= hi-ChangepointMinSegment; k++ {
+			if v := sse(lo, k) + sse(k, hi); v < bestSSE {
+				best, bestSSE = k, v
+			}
+		}
+		before, after := mean(lo, best), mean(best, hi)
+		larger := math.Max(before, after)
+		if bestSSE > whole/2 || math.Abs(after-before) < minShift*larger {
+			return
+		}
+		breaks = append(breaks, best)
+		split(lo, best)
+		split(best, hi)
+	}
+	split(0, len(shares))
+	if len(breaks) == 0 {
+		return nil
+	}
+
+	// Describe each changepoint by the final segments around it rather than
+	// the wider stretches it was found in.
+	sort.Ints(breaks)
+	bounds := append(append([]int{0}, breaks...), len(shares))
+	result := make([]Changepoint, len(breaks))
+	for i, b := range breaks {
+		result[i] = Changepoint{Year: years[b], Before: mean(bounds[i], b), After: mean(b, bounds[i+2])}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package namesdata_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func changepointSeries(counts []int) namesdata.TrendSeries {
+	series := namesdata.TrendSeries{Name: "Khaleesi"}
+	for i, count := range counts {
+		series.Points = append(series.Points, namesdata.TrendPoint{Year: 2005 + i, Count: count, Present: count > 0, Total: 1000})
+	}
+	return series
+}
+
+func TestTrendSeriesChangepoints(t *testing.T) {
+	// Absent for four years, then a jump to about 10% that later fades.
+	series := changepointSeries([]int{0, 0, 0, 0, 100, 110, 95, 105, 40, 45, 38})
+	got := series.Changepoints(namesdata.DefaultChangepointShift)
+	if len(got) != 2 {
+		t.Fatalf("expected two changepoints, got %+v", got)
+	}
+	if got[0].Year != 2009 || !got[0].Rising() || got[0].Before != 0 {
+		t.Fatalf("unexpected first changepoint: %+v", got[0])
+	}
+	if math.Abs(got[0].After-0.1025) > 1e-9 {
+		t.Fatalf("expected the new level to average 10.25%%, got %v", got[0].After)
+	}
+	if got[1].Year != 2013 || got[1].Rising() {
+		t.Fatalf("unexpected second changepoint: %+v", got[1])
+	}
+}
+
+func TestTrendSeriesChangepointsIgnoresSmallShifts(t *testing.T) {
+	steady := changepointSeries([]int{100, 104, 98, 101, 97, 103, 99, 102})
+	if got := steady.Changepoints(namesdata.DefaultChangepointShift); len(got) != 0 {
+		t.Fatalf("expected no changepoints in a steady series, got %+v", got)
+	}
+
+	// A single spike year is shorter than the minimum segment.
+	spike := changepointSeries([]int{100, 100, 100, 400, 100, 100, 100})
+	if got := spike.Changepoints(namesdata.DefaultChangepointShift); len(got) != 0 {
+		t.Fatalf("expected a one-year spike not to count, got %+v", got)
+	}
+
+	short := changepointSeries([]int{0, 0, 100, 100})
+	if got := short.Changepoints(namesdata.DefaultChangepointShift); len(got) != 0 {
+		t.Fatalf("expected too short a series to have no changepoints, got %+v", got)
+	}
+}
+
+func TestTrendSeriesChangepointsSkipsForecasts(t *testing.T) {
+	series := changepointSeries([]int{10, 10, 10, 10, 10, 10})
+	for i := range 4 {
+		series.Points = append(series.Points, namesdata.TrendPoint{Year: 2011 + i, Count: 500, Present: true, Total: 1000, Forecast: true})
+	}
+	if got := series.Changepoints(namesdata.DefaultChangepointShift); len(got) != 0 {
+		t.Fatalf("expected forecast years to be ignored, got %+v", got)
+	}
+}
@@ -0,0 +1,136 @@
+package namesdata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/cluster"
+)
+
+// distinctivePerCluster caps how many characteristic names each StateCluster
+// lists.
+const distinctivePerCluster = 5
+
+// StateCluster is a group of states with similar naming preferences.
+type StateCluster struct {
+	// States is sorted by state code.
+	States []string
+	// Distinctive lists the vocabulary names whose average share in the
+	// cluster most exceeds their average share across every clustered
+	// state, most distinctive first.
+	Distinctive []string
+}
+
+// StateClustering is the result of ClusterStates.
+type StateClustering struct {
+	// Clusters is ordered by descending size, ties broken by first state.
+	Clusters []StateCluster
+	// Vocabulary is the names whose shares formed each state's vector.
+	Vocabulary []string
+	// Inertia is the k-means within-cluster sum of squared share
+	// differences.
+	Inertia float64
+}
+
+// ClusterStates groups states by naming preference. Each state with data
+// becomes a vector of its shares of the vocabulary names, the names with the
+// highest average share across states, and the vectors are split into k
+// clusters with k-means. States without data are ignored.
+func ClusterStates(states []StateAggregate, k, vocabulary int) (StateClustering, error) {
+	if vocabulary < 1 {
+		return StateClustering{}, errors.New("vocabulary must be at least 1")
+	}
+
+	var withData []StateAggregate
+	for _, state := range states {
+		if state.Total > 0 {
+			withData = append(withData, state)
+		}
+	}
+	if len(withData) == 0 {
+		return StateClustering{}, ErrNoMatches
+	}
+	if k < 1 || k > len(withData) {
+		return StateClustering{}, fmt.Errorf("k must be between 1 and the %d states with data", len(withData))
+	}
+
+	// Each state's shares keyed by upper-case name, plus the running mean
+	// share used to pick the vocabulary.
+	shares := make([]map[string]float64, len(withData))
+	meanShare := make(map[string]float64)
+	display := make(map[string]string)
+	for i, state := range withData {
+		shares[i] = make(map[string]float64, len(state.Names))
+		for _, entry := range state.Names {
+			key := strings.ToUpper(entry.Name)
+			share := float64(entry.Count) / float64(state.Total)
+			shares[i][key] += share
+			meanShare[key] += share / float64(len(withData))
+			if _, ok := display[key]; !ok {
+				display[key] = entry.Name
+			}
+		}
+	}
+	vocab := make([]string, 0, len(meanShare))
+	for key := range meanShare {
+		vocab = append(vocab, key)
+	}
+	sort.Slice(vocab, func(i, j int) bool {
+		if meanShare[vocab[i]] != meanShare[vocab[j]] {
+			return meanShare[vocab[i]] > meanShare[vocab[j]]
+		}
+		return vocab[i] < vocab[j]
+	})
+	vocab = vocab[:min(vocabulary, len(vocab))]
+
+	points := make([][]float64, len(withData))
+	for i := range withData {
+		points[i] = make([]float64, len(vocab))
+		for d, key := range vocab {
+			points[i][d] = shares[i][key]
+		}
+	}
+	result, err := cluster.KMeans(points, k)
+	if err != nil {
+		return StateClustering{}, err
+	}
+
+	clustering := StateClustering{Inertia: result.Inertia}
+	for _, key := range vocab {
+		clustering.Vocabulary = append(clustering.Vocabulary, display[key])
+	}
+	for c, centroid := range result.Centroids {
+		var members []string
+		for i, assigned := range result.Assignments {
+			if assigned == c {
+				members = append(members, withData[i].State)
+			}
+		}
+		sort.Strings(members)
+
+		order := make([]int, len(vocab))
+		for d := range order {
+			order[d] = d
+		}
+		lift := func(d int) float64 { return centroid[d] - meanShare[vocab[d]] }
+		sort.SliceStable(order, func(i, j int) bool { return lift(order[i]) > lift(order[j]) })
+		var distinctive []string
+		for _, d := range order[:min(distinctivePerCluster, len(order))] {
+			if lift(d) <= 0 {
+				break
+			}
+			distinctive = append(distinctive, display[vocab[d]])
+		}
+		clustering.Clusters = append(clustering.Clusters, StateCluster{States: members, Distinctive: distinctive})
+	}
+	sort.SliceStable(clustering.Clusters, func(i, j int) bool {
+		a, b := clustering.Clusters[i], clustering.Clusters[j]
+		if len(a.States) != len(b.States) {
+			return len(a.States) > len(b.States)
+		}
+		return a.States[0] < b.States[0]
+	})
+	return clustering, nil
+}
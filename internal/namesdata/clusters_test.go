@@ -0,0 +1,49 @@
+package namesdata_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestClusterStates(t *testing.T) {
+	state := func(code string, counts ...int) namesdata.StateAggregate {
+		names := []string{"Olivia", "Emma", "Ava"}
+		agg := namesdata.StateAggregate{State: code}
+		for i, count := range counts {
+			agg.Names = append(agg.Names, namesdata.NameCount{Name: names[i], Count: count})
+			agg.Total += count
+		}
+		return agg
+	}
+	states := []namesdata.StateAggregate{
+		state("CA", 70, 20, 10),
+		state("MA", 10, 75, 15),
+		state("NY", 65, 25, 10),
+		state("TX", 15, 70, 15),
+		{State: "WY"},
+	}
+
+	clustering, err := namesdata.ClusterStates(states, 2, 2)
+	if err != nil {
+		t.Fatalf("ClusterStates: %v", err)
+	}
+	if strings.Join(clustering.Vocabulary, ",") != "Emma,Olivia" {
+		t.Fatalf("unexpected vocabulary: %v", clustering.Vocabulary)
+	}
+	if len(clustering.Clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %+v", clustering.Clusters)
+	}
+	first, second := clustering.Clusters[0], clustering.Clusters[1]
+	if strings.Join(first.States, ",") != "CA,NY" || strings.Join(second.States, ",") != "MA,TX" {
+		t.Fatalf("unexpected clusters: %+v", clustering.Clusters)
+	}
+	if len(first.Distinctive) != 1 || first.Distinctive[0] != "Olivia" || second.Distinctive[0] != "Emma" {
+		t.Fatalf("unexpected distinctive names: %+v", clustering.Clusters)
+	}
+
+	if _, err := namesdata.ClusterStates(states, 5, 2); err == nil {
+		t.Fatal("expected an error when k exceeds the states with data")
+	}
+}
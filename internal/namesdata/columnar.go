@@ -0,0 +1,169 @@
+package namesdata
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"iter"
+	"math"
+	"strings"
+)
+
+// stringPool interns strings so repeated values share a single copy. Each
+// string is cloned on first sight, which also detaches it from the line it
+// was sliced from.
+type stringPool struct {
+	ids    map[string]uint32
+	values []string
+}
+
+// intern returns the pooled copy of s and its ID.
+func (p *stringPool) intern(s string) (string, uint32) {
+	if id, ok := p.ids[s]; ok {
+		return p.values[id], id
+	}
+	if p.ids == nil {
+		p.ids = make(map[string]uint32)
+	}
+	s = strings.Clone(s)
+	id := uint32(len(p.values))
+	p.ids[s] = id
+	p.values = append(p.values, s)
+	return s, id
+}
+
+// internRecords wraps fn so every record it receives carries pooled state,
+// gender, and name strings. Loaded slices then hold one copy of each
+// distinct string instead of a reference to every source line.
+func internRecords(fn func(Record) error) func(Record) error {
+	var pool stringPool
+	return func(r Record) error {
+		r.State, _ = pool.intern(r.State)
+		r.Gender, _ = pool.intern(r.Gender)
+		r.Name, _ = pool.intern(r.Name)
+		return fn(r)
+	}
+}
+
+// RecordTable holds records column by column: each distinct name, state,
+// and gender is stored once and referenced by ID, and years and counts are
+// kept in compact parallel slices. A table takes a fraction of the memory of
+// the equivalent []Record, which suits callers holding the full dataset. The
+// zero value is an empty table ready for Append.
+type RecordTable struct {
+	names  stringPool
+	labels stringPool // states and genders
+
+	nameIDs  []uint32
+	stateIDs []uint16
+	genders  []uint16
+	years    []uint16
+	counts   []uint32
+}
+
+// Append adds r to the end of the table. Years must fit in 0-65535 and counts
+// in 0-4294967295.
+func (t *RecordTable) Append(r Record) error {
+	if r.Year < 0 || r.Year > math.MaxUint16 {
+		return fmt.Errorf("record table: year %d out of range", r.Year)
+	}
+	if r.Count < 0 || r.Count > math.MaxUint32 {
+		return fmt.Errorf("record table: count %d out of range", r.Count)
+	}
+	_, state := t.labels.intern(r.State)
+	_, gender := t.labels.intern(r.Gender)
+	if max(state, gender) > math.MaxUint16 {
+		return fmt.Errorf("record table: too many distinct states and genders")
+	}
+	_, name := t.names.intern(r.Name)
+
+	t.nameIDs = append(t.nameIDs, name)
+	t.stateIDs = append(t.stateIDs, uint16(state))
+	t.genders = append(t.genders, uint16(gender))
+	t.years = append(t.years, uint16(r.Year))
+	t.counts = append(t.counts, uint32(r.Count))
+	return nil
+}
+
+// Len returns the number of records in the table.
+func (t *RecordTable) Len() int {
+	return len(t.nameIDs)
+}
+
+// Names returns the number of distinct names in the table.
+func (t *RecordTable) Names() int {
+	return len(t.names.values)
+}
+
+// At returns the i-th record. Its strings are shared with the table.
+func (t *RecordTable) At(i int) Record {
+	return Record{
+		State:  t.labels.values[t.stateIDs[i]],
+		Gender: t.labels.values[t.genders[i]],
+		Year:   int(t.years[i]),
+		Name:   t.names.values[t.nameIDs[i]],
+		Count:  int(t.counts[i]),
+	}
+}
+
+// Stream yields the table's records that match filter, in order, for use
+// with the streaming analyses such as Concentration and DiversityByYear.
+func (t *RecordTable) Stream(filter Filter) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for i := range t.nameIDs {
+			rec := t.At(i)
+			if !filter.Match(rec) {
+				continue
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Records expands the table into a slice for the APIs that take []Record.
+func (t *RecordTable) Records() []Record {
+	records := make([]Record, t.Len())
+	for i := range records {
+		records[i] = t.At(i)
+	}
+	return records
+}
+
+// LoadRecordTable loads one state's records (or every state's when state is
+// empty) into a RecordTable.
+func LoadRecordTable(fsys fs.FS, state string) (*RecordTable, error) {
+	return LoadRecordTableContext(context.Background(), fsys, state)
+}
+
+// LoadRecordTableContext is LoadRecordTable, stopping with ctx's error once
+// ctx is done.
+func LoadRecordTableContext(ctx context.Context, fsys fs.FS, state string) (*RecordTable, error) {
+	table := &RecordTable{}
+	walk := walkContext(ctx, func(fn func(Record) error) error {
+		return walkRecords(fsys, state, fn)
+	})
+	if err := walk(table.Append); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// LoadNationalTable is LoadRecordTable for the national dataset.
+func LoadNationalTable(fsys fs.FS) (*RecordTable, error) {
+	return LoadNationalTableContext(context.Background(), fsys)
+}
+
+// LoadNationalTableContext is LoadNationalTable, stopping with ctx's error
+// once ctx is done.
+func LoadNationalTableContext(ctx context.Context, fsys fs.FS) (*RecordTable, error) {
+	table := &RecordTable{}
+	walk := walkContext(ctx, func(fn func(Record) error) error {
+		return walkNationalRecords(fsys, fn)
+	})
+	if err := walk(table.Append); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
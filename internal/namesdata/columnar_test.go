@@ -0,0 +1,59 @@
+package namesdata_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestRecordTable(t *testing.T) {
+	fs := sampleFS()
+	records, err := namesdata.LoadAllRecords(fs)
+	if err != nil {
+		t.Fatalf("LoadAllRecords: %v", err)
+	}
+	table, err := namesdata.LoadRecordTable(fs, "")
+	if err != nil {
+		t.Fatalf("LoadRecordTable: %v", err)
+	}
+
+	if table.Len() != len(records) || table.Names() != 4 {
+		t.Fatalf("expected %d records of 4 names, got %d of %d", len(records), table.Len(), table.Names())
+	}
+	for i, rec := range records {
+		if got := table.At(i); got != rec {
+			t.Fatalf("record %d: expected %+v, got %+v", i, rec, got)
+		}
+	}
+
+	total := 0
+	for rec, err := range table.Stream(namesdata.Filter{State: "NY", Gender: "F"}) {
+		if err != nil {
+			t.Fatalf("Stream: %v", err)
+		}
+		total += rec.Count
+	}
+	if total != 105 {
+		t.Fatalf("expected 105 NY girls, got %d", total)
+	}
+
+	// Loaded records share one copy of each repeated name.
+	var olivias []string
+	for _, rec := range records {
+		if rec.Name == "Olivia" {
+			olivias = append(olivias, rec.Name)
+		}
+	}
+	if len(olivias) < 2 || unsafe.StringData(olivias[0]) != unsafe.StringData(olivias[1]) {
+		t.Fatalf("expected interned names, got %d Olivias", len(olivias))
+	}
+
+	var empty namesdata.RecordTable
+	if err := empty.Append(namesdata.Record{Name: "Ada", Year: 70000}); err == nil {
+		t.Fatal("expected an error for an out-of-range year")
+	}
+	if err := empty.Append(namesdata.Record{State: "CA", Gender: "F", Year: 1900, Name: "Ada", Count: 7}); err != nil || empty.Len() != 1 {
+		t.Fatalf("Append: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package namesdata
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NameComparison describes one name's standing in a given year alongside the
+// previous year. Rank and PrevRank are zero when the name is absent.
+type NameComparison struct {
+	Name      string
+	Rank      int
+	Count     int
+	Share     float64
+	PrevRank  int
+	PrevCount int
+}
+
+// Change returns the relative change in count from the previous year, and
+// false when there is no previous-year count to compare against.
+func (c NameComparison) Change() (float64, bool) {
+	if c.PrevCount == 0 {
+		return 0, false
+	}
+	return float64(c.Count-c.PrevCount) / float64(c.PrevCount), true
+}
+
+// CompareNames reports each requested name's rank, count, and share for year
+// together with its previous-year figures. When year is 0 the latest year in
+// the records is used; the year actually compared is returned. Names are
+// matched case-insensitively and returned in the order requested.
+func CompareNames(records []Record, gender string, year int, names []string) (int, []NameComparison, error) {
+	requested, err := trendRequests(names)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(requested) < 2 {
+		return 0, nil, errors.New("at least two distinct names are required")
+	}
+
+	yearly := AggregateByYear(records, gender)
+	if len(yearly) == 0 {
+		return 0, nil, ErrNoMatches
+	}
+
+	current, previous := -1, -1
+	if year == 0 {
+		current = len(yearly) - 1
+	} else {
+		for i, agg := range yearly {
+			if agg.Year == year {
+				current = i
+				break
+			}
+		}
+		if current < 0 {
+			return 0, nil, fmt.Errorf("%w: no data for %d", ErrNoMatches, year)
+		}
+	}
+	if current > 0 && yearly[current-1].Year == yearly[current].Year-1 {
+		previous = current - 1
+	}
+
+	target := yearly[current]
+	comparisons := make([]NameComparison, len(requested))
+	for i, req := range requested {
+		comparison := NameComparison{Name: req.Input}
+		if rank, ok := target.Ranks[req.Key]; ok {
+			entry := target.Names[rank-1]
+			comparison.Name = entry.Name
+			comparison.Rank = rank
+			comparison.Count = entry.Count
+			if target.Total > 0 {
+				comparison.Share = float64(entry.Count) / float64(target.Total)
+			}
+		}
+		if previous >= 0 {
+			prior := yearly[previous]
+			if rank, ok := prior.Ranks[req.Key]; ok {
+				comparison.PrevRank = rank
+				comparison.PrevCount = prior.Names[rank-1].Count
+				if comparison.Rank == 0 {
+					comparison.Name = prior.Names[rank-1].Name
+				}
+			}
+		}
+		comparisons[i] = comparison
+	}
+
+	return target.Year, comparisons, nil
+}
@@ -0,0 +1,44 @@
+package namesdata_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestCompareNames(t *testing.T) {
+	records, err := namesdata.LoadStateRecords(sampleFS(), "CA")
+	if err != nil {
+		t.Fatalf("LoadStateRecords: %v", err)
+	}
+
+	year, comparisons, err := namesdata.CompareNames(records, "F", 0, []string{"emma", "Olivia", "Zoe"})
+	if err != nil {
+		t.Fatalf("CompareNames: %v", err)
+	}
+	if year != 2019 || len(comparisons) != 3 {
+		t.Fatalf("unexpected year %d or comparisons %+v", year, comparisons)
+	}
+
+	emma := comparisons[0]
+	if emma.Name != "Emma" || emma.Rank != 2 || emma.Count != 90 || emma.PrevCount != 50 {
+		t.Fatalf("unexpected Emma comparison: %+v", emma)
+	}
+	if change, ok := emma.Change(); !ok || math.Abs(change-0.8) > 1e-9 {
+		t.Fatalf("expected Emma to grow 80%%, got %v (%v)", change, ok)
+	}
+	if math.Abs(comparisons[1].Share-140.0/230.0) > 1e-9 {
+		t.Fatalf("unexpected Olivia share: %v", comparisons[1].Share)
+	}
+	if zoe := comparisons[2]; zoe.Rank != 0 || zoe.Count != 0 {
+		t.Fatalf("expected Zoe to be absent, got %+v", zoe)
+	}
+
+	if _, _, err := namesdata.CompareNames(records, "F", 0, []string{"Emma", "emma"}); err == nil {
+		t.Fatalf("expected error for a single distinct name")
+	}
+	if _, _, err := namesdata.CompareNames(records, "F", 1990, []string{"Emma", "Olivia"}); err == nil {
+		t.Fatalf("expected error for a year without data")
+	}
+}
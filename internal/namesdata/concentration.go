@@ -0,0 +1,67 @@
+package namesdata
+
+import (
+	"errors"
+	"iter"
+)
+
+// YearConcentration records how much of one year's births went to the most
+// popular names.
+type YearConcentration struct {
+	Year  int
+	Total int
+	// Names is the number of distinct names given that year.
+	Names int
+	// TopCounts[i] is the births captured by the top cutoffs[i] names, for
+	// the cutoffs passed to Concentration.
+	TopCounts []int
+}
+
+// Share returns the fraction of the year's births captured by the top names
+// for the i-th cutoff.
+func (c YearConcentration) Share(i int) float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return float64(c.TopCounts[i]) / float64(c.Total)
+}
+
+// Concentration measures, for every year in records, the share of births
+// captured by the top N names for each N in cutoffs. Falling shares mean
+// parents are spreading across more names. A cutoff larger than the number
+// of names in a year captures every birth. gender can be "M", "F", or empty
+// for all.
+func Concentration(records iter.Seq2[Record, error], gender string, cutoffs []int) ([]YearConcentration, error) {
+	if len(cutoffs) == 0 {
+		return nil, errors.New("at least one cutoff is required")
+	}
+	for _, n := range cutoffs {
+		if n < 1 {
+			return nil, errors.New("cutoffs must be at least 1")
+		}
+	}
+
+	acc := newYearAccumulator(gender)
+	for rec, err := range records {
+		if err != nil {
+			return nil, err
+		}
+		acc.add(rec)
+	}
+	yearly := acc.result()
+	if len(yearly) == 0 {
+		return nil, ErrNoMatches
+	}
+
+	result := make([]YearConcentration, len(yearly))
+	for i, agg := range yearly {
+		c := YearConcentration{Year: agg.Year, Total: agg.Total, Names: len(agg.Names), TopCounts: make([]int, len(cutoffs))}
+		for ci, n := range cutoffs {
+			for _, entry := range agg.Names[:min(n, len(agg.Names))] {
+				c.TopCounts[ci] += entry.Count
+			}
+		}
+		result[i] = c
+	}
+	return result, nil
+}
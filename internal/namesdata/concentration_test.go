@@ -0,0 +1,42 @@
+package namesdata_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestConcentration(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 2000, Name: "Ava", Count: 60},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Mia", Count: 30},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Zoe", Count: 10},
+		{State: "CA", Gender: "M", Year: 2000, Name: "Liam", Count: 500},
+		{State: "CA", Gender: "F", Year: 2001, Name: "Ava", Count: 25},
+		{State: "NY", Gender: "F", Year: 2001, Name: "Mia", Count: 25},
+	}
+
+	yearly, err := namesdata.Concentration(namesdata.SliceRecords(records), "F", []int{1, 5})
+	if err != nil {
+		t.Fatalf("Concentration: %v", err)
+	}
+	if len(yearly) != 2 {
+		t.Fatalf("expected 2 years, got %+v", yearly)
+	}
+
+	first := yearly[0]
+	if first.Year != 2000 || first.Total != 100 || first.Names != 3 || first.TopCounts[0] != 60 {
+		t.Fatalf("unexpected 2000 concentration: %+v", first)
+	}
+	if math.Abs(first.Share(0)-0.6) > 1e-9 || first.Share(1) != 1 {
+		t.Fatalf("unexpected 2000 shares: %v %v", first.Share(0), first.Share(1))
+	}
+	if second := yearly[1]; second.Share(0) != 0.5 {
+		t.Fatalf("expected the top name to hold half of 2001, got %+v", second)
+	}
+
+	if _, err := namesdata.Concentration(namesdata.SliceRecords(records), "F", nil); err == nil {
+		t.Fatalf("expected error without cutoffs")
+	}
+}
@@ -0,0 +1,81 @@
+package namesdata
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// rngPool hands out independently seeded random sources so concurrent
+// picks neither share nor lock one.
+var rngPool = sync.Pool{
+	New: func() any {
+		return rand.New(rand.NewSource(nextSeed()))
+	},
+}
+
+var (
+	seedMu sync.Mutex
+	seeder = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// nextSeed returns a fresh seed for a pooled source. Seeds come from one
+// guarded generator rather than the clock, so sources created in the same
+// instant still differ.
+func nextSeed() int64 {
+	seedMu.Lock()
+	defer seedMu.Unlock()
+	return seeder.Int63()
+}
+
+// PickConcurrent is Pick using a random source from an internal pool, so a
+// single sampler can be shared by many goroutines, such as the handlers of
+// an HTTP server, without a caller-managed *rand.Rand. The draws cannot be
+// reproduced; share a NewLockedRand with Pick for seeded concurrent use.
+func (s *NameSampler) PickConcurrent() (NameCount, error) {
+	rng := rngPool.Get().(*rand.Rand)
+	defer rngPool.Put(rng)
+	return s.Pick(rng)
+}
+
+// PickUniqueConcurrent is PickUnique using a pooled random source, like
+// PickConcurrent.
+func (s *NameSampler) PickUniqueConcurrent(n int) ([]NameCount, error) {
+	rng := rngPool.Get().(*rand.Rand)
+	defer rngPool.Put(rng)
+	return s.PickUnique(n, rng)
+}
+
+// NewLockedRand returns a *rand.Rand seeded with seed whose source is
+// guarded by a mutex, so it can be passed to Pick and PickUnique from
+// several goroutines at once. Draws from one seed are reproducible only
+// when the goroutines take turns in the same order; contention makes it
+// slower than PickConcurrent. Its Read method is not safe for concurrent
+// use.
+func NewLockedRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+// lockedSource is a rand.Source64 safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
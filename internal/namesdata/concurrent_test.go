@@ -0,0 +1,77 @@
+package namesdata_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestNameSamplerConcurrentPicks(t *testing.T) {
+	sampler, err := namesdata.NewNameSampler([]namesdata.NameCount{
+		{Name: "Olivia", Count: 140},
+		{Name: "Emma", Count: 90},
+		{Name: "Zoe", Count: 0},
+	})
+	if err != nil {
+		t.Fatalf("NewNameSampler: %v", err)
+	}
+
+	const goroutines, draws = 8, 500
+	shared := namesdata.NewLockedRand(7)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		counts = make(map[string]int)
+	)
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make(map[string]int)
+			for range draws {
+				var pick namesdata.NameCount
+				var err error
+				if g%2 == 0 {
+					pick, err = sampler.PickConcurrent()
+				} else {
+					pick, err = sampler.Pick(shared)
+				}
+				if err != nil {
+					t.Errorf("pick: %v", err)
+					return
+				}
+				local[pick.Name]++
+			}
+			unique, err := sampler.PickUniqueConcurrent(2)
+			if err != nil || len(unique) != 2 || unique[0].Name == unique[1].Name {
+				t.Errorf("unexpected unique picks %+v: %v", unique, err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for name, n := range local {
+				counts[name] += n
+			}
+		}()
+	}
+	wg.Wait()
+
+	if counts["Zoe"] != 0 {
+		t.Fatalf("expected a zero-count name never to be drawn, got %v", counts)
+	}
+	trials := goroutines * draws
+	lower, upper := binomialConfidenceBounds(trials, 140.0/230.0, 0.999)
+	if olivia := counts["Olivia"]; olivia < lower || olivia > upper {
+		t.Fatalf("Olivia drawn %d of %d times, outside [%d, %d]", olivia, trials, lower, upper)
+	}
+}
+
+func TestNewLockedRandIsSeeded(t *testing.T) {
+	a, b := namesdata.NewLockedRand(3), rand.New(rand.NewSource(3))
+	for range 5 {
+		if x, y := a.Int63(), b.Int63(); x != y {
+			t.Fatalf("expected the locked source to follow its seed, got %d want %d", x, y)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package namesdata
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// NameConstraints restricts which names a sampler may draw. Zero-valued
+// fields impose no constraint, and string comparisons ignore case.
+type NameConstraints struct {
+	StartsWith string
+	EndsWith   string
+	// MinLength and MaxLength bound the name's length in letters.
+	MinLength int
+	MaxLength int
+	// Exclude lists names that are never drawn.
+	Exclude []string
+}
+
+// IsZero reports whether c imposes no constraint.
+func (c NameConstraints) IsZero() bool {
+	return strings.TrimSpace(c.StartsWith) == "" && strings.TrimSpace(c.EndsWith) == "" &&
+		c.MinLength == 0 && c.MaxLength == 0 && len(c.Exclude) == 0
+}
+
+// Validate reports constraints that can never match.
+func (c NameConstraints) Validate() error {
+	if c.MinLength < 0 || c.MaxLength < 0 {
+		return fmt.Errorf("name lengths must be 0 or greater")
+	}
+	if c.MaxLength > 0 && c.MinLength > c.MaxLength {
+		return fmt.Errorf("minimum length %d is greater than maximum length %d", c.MinLength, c.MaxLength)
+	}
+	return nil
+}
+
+// Match reports whether name satisfies every constraint.
+func (c NameConstraints) Match(name string) bool {
+	if !c.matchShape(name) {
+		return false
+	}
+	for _, excluded := range c.Exclude {
+		if strings.EqualFold(strings.TrimSpace(excluded), name) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchShape reports whether name satisfies every constraint but Exclude.
+func (c NameConstraints) matchShape(name string) bool {
+	upper := strings.ToUpper(name)
+	if prefix := strings.ToUpper(strings.TrimSpace(c.StartsWith)); !strings.HasPrefix(upper, prefix) {
+		return false
+	}
+	if suffix := strings.ToUpper(strings.TrimSpace(c.EndsWith)); !strings.HasSuffix(upper, suffix) {
+		return false
+	}
+	length := utf8.RuneCountInString(name)
+	return length >= c.MinLength && (c.MaxLength == 0 || length <= c.MaxLength)
+}
+
+// Select returns the entries of aggregated whose names satisfy c, in their
+// original order, together with the total of their counts. Excluded names
+// are looked up in a set, so long exclusion lists stay cheap.
+func (c NameConstraints) Select(aggregated []NameCount) ([]NameCount, int) {
+	excluded := make(map[string]bool, len(c.Exclude))
+	for _, name := range c.Exclude {
+		excluded[strings.ToUpper(strings.TrimSpace(name))] = true
+	}
+	selected := make([]NameCount, 0, len(aggregated))
+	total := 0
+	for _, entry := range aggregated {
+		if c.matchShape(entry.Name) && !excluded[strings.ToUpper(entry.Name)] {
+			selected = append(selected, entry)
+			total += entry.Count
+		}
+	}
+	return selected, total
+}
+
+// SkipTop returns aggregated without its n most popular names, together
+// with the total of the remaining counts. aggregated must be sorted most
+// popular first, as AggregateNames returns it, so the names removed are
+// those ranked 1 through n; ties at rank n are broken by that order. The
+// result shares aggregated's backing array.
+func SkipTop(aggregated []NameCount, n int) ([]NameCount, int) {
+	rest := aggregated[min(max(n, 0), len(aggregated)):]
+	total := 0
+	for _, entry := range rest {
+		total += entry.Count
+	}
+	return rest, total
+}
+
+// String describes the constraints for titles and metadata, e.g.
+// "starting with A, at most 6 letters, excluding Olivia, Emma".
+func (c NameConstraints) String() string {
+	var parts []string
+	if prefix := strings.TrimSpace(c.StartsWith); prefix != "" {
+		parts = append(parts, fmt.Sprintf("starting with %s", prefix))
+	}
+	if suffix := strings.TrimSpace(c.EndsWith); suffix != "" {
+		parts = append(parts, fmt.Sprintf("ending in %s", suffix))
+	}
+	switch {
+	case c.MinLength > 0 && c.MaxLength > 0 && c.MinLength == c.MaxLength:
+		parts = append(parts, fmt.Sprintf("%d letters", c.MinLength))
+	case c.MinLength > 0 && c.MaxLength > 0:
+		parts = append(parts, fmt.Sprintf("%d-%d letters", c.MinLength, c.MaxLength))
+	case c.MinLength > 0:
+		parts = append(parts, fmt.Sprintf("at least %d letters", c.MinLength))
+	case c.MaxLength > 0:
+		parts = append(parts, fmt.Sprintf("at most %d letters", c.MaxLength))
+	}
+	if len(c.Exclude) > 0 {
+		parts = append(parts, fmt.Sprintf("excluding %s", strings.Join(c.Exclude, ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NewConstrainedNameSampler builds a sampler over the names in aggregated
+// that satisfy constraints. Names are filtered before the sampling tables
+// are built, so each pick is weighted among the matching names only. It
+// returns ErrNoMatches when no name matches.
+func NewConstrainedNameSampler(aggregated []NameCount, constraints NameConstraints, strategy SamplerStrategy, expectedDraws int) (*NameSampler, error) {
+	if err := constraints.Validate(); err != nil {
+		return nil, err
+	}
+	selected, _ := constraints.Select(aggregated)
+	return NewNameSamplerWithStrategy(selected, strategy, expectedDraws)
+}
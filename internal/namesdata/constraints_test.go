@@ -0,0 +1,74 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestNameConstraints(t *testing.T) {
+	aggregated := []namesdata.NameCount{
+		{Name: "Olivia", Count: 500},
+		{Name: "Amelia", Count: 300},
+		{Name: "Ava", Count: 200},
+		{Name: "Anna", Count: 100},
+		{Name: "Zoe", Count: 50},
+	}
+
+	constraints := namesdata.NameConstraints{StartsWith: "a", EndsWith: "A", MaxLength: 4, Exclude: []string{"ANNA"}}
+	selected, total := constraints.Select(aggregated)
+	if len(selected) != 1 || selected[0].Name != "Ava" || total != 200 {
+		t.Fatalf("unexpected selection: %+v (total %d)", selected, total)
+	}
+	if got := constraints.String(); got != "starting with a, ending in A, at most 4 letters, excluding ANNA" {
+		t.Fatalf("unexpected description %q", got)
+	}
+
+	sampler, err := namesdata.NewConstrainedNameSampler(aggregated, namesdata.NameConstraints{MinLength: 6}, namesdata.SamplerAuto, 0)
+	if err != nil {
+		t.Fatalf("NewConstrainedNameSampler: %v", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	counts := make(map[string]int)
+	for range 8000 {
+		pick, err := sampler.Pick(rng)
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		counts[pick.Name]++
+	}
+	if len(counts) != 2 || counts["Olivia"] < 4600 || counts["Olivia"] > 5400 {
+		t.Fatalf("expected Olivia and Amelia drawn 5:3, got %v", counts)
+	}
+
+	if _, err := namesdata.NewConstrainedNameSampler(aggregated, namesdata.NameConstraints{StartsWith: "Q"}, namesdata.SamplerAuto, 0); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+	if err := (namesdata.NameConstraints{MinLength: 5, MaxLength: 3}).Validate(); err == nil {
+		t.Fatalf("expected error for an empty length range")
+	}
+	if !(namesdata.NameConstraints{}).IsZero() || constraints.IsZero() {
+		t.Fatalf("unexpected IsZero results")
+	}
+}
+
+func TestSkipTop(t *testing.T) {
+	aggregated := []namesdata.NameCount{
+		{Name: "Olivia", Count: 500},
+		{Name: "Amelia", Count: 300},
+		{Name: "Ava", Count: 200},
+	}
+
+	rest, total := namesdata.SkipTop(aggregated, 2)
+	if len(rest) != 1 || rest[0].Name != "Ava" || total != 200 {
+		t.Fatalf("unexpected names after skipping 2: %+v (total %d)", rest, total)
+	}
+	if rest, total := namesdata.SkipTop(aggregated, 0); len(rest) != 3 || total != 1000 {
+		t.Fatalf("expected nothing skipped for 0, got %+v (total %d)", rest, total)
+	}
+	if rest, total := namesdata.SkipTop(aggregated, 5); len(rest) != 0 || total != 0 {
+		t.Fatalf("expected every name skipped, got %+v (total %d)", rest, total)
+	}
+}
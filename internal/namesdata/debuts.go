@@ -0,0 +1,107 @@
+package namesdata
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FirstSeenIndex records every year in which each name appears so a name's
+// first appearance, or its latest one before a given year, can be looked up
+// without rescanning the records. Lookups are case-insensitive.
+type FirstSeenIndex struct {
+	// years maps upper-cased names to the years they appear in, ascending.
+	years map[string][]int
+	// names maps upper-cased names to the first spelling indexed.
+	names map[string]string
+}
+
+// NewFirstSeenIndex indexes the years of every name in records. gender can
+// be "M", "F", or empty for all.
+func NewFirstSeenIndex(records []Record, gender string) *FirstSeenIndex {
+	gender = strings.ToUpper(strings.TrimSpace(gender))
+
+	seen := make(map[string]map[int]bool)
+	names := make(map[string]string)
+	for _, r := range records {
+		if gender != "" && strings.ToUpper(r.Gender) != gender {
+			continue
+		}
+		key := strings.ToUpper(r.Name)
+		years, ok := seen[key]
+		if !ok {
+			years = make(map[int]bool)
+			seen[key] = years
+			names[key] = r.Name
+		}
+		years[r.Year] = true
+	}
+
+	idx := &FirstSeenIndex{years: make(map[string][]int, len(seen)), names: names}
+	for key, years := range seen {
+		sorted := make([]int, 0, len(years))
+		for year := range years {
+			sorted = append(sorted, year)
+		}
+		slices.Sort(sorted)
+		idx.years[key] = sorted
+	}
+	return idx
+}
+
+// FirstSeen returns the first year name appears in. ok is false when the
+// name is not indexed.
+func (x *FirstSeenIndex) FirstSeen(name string) (year int, ok bool) {
+	years := x.years[strings.ToUpper(strings.TrimSpace(name))]
+	if len(years) == 0 {
+		return 0, false
+	}
+	return years[0], true
+}
+
+// LastSeenBefore returns the latest year before year that name appears in.
+// ok is false when the name does not appear before year.
+func (x *FirstSeenIndex) LastSeenBefore(name string, year int) (int, bool) {
+	years := x.years[strings.ToUpper(strings.TrimSpace(name))]
+	i, _ := slices.BinarySearch(years, year)
+	if i == 0 {
+		return 0, false
+	}
+	return years[i-1], true
+}
+
+// Debut is a name that appears in a year after being absent beforehand.
+type Debut struct {
+	Name  string
+	Count int
+	// Rank is the name's rank among every name in the debut year.
+	Rank int
+	// PreviousYear is the latest earlier year the name appeared in, or 0
+	// for a name never recorded before.
+	PreviousYear int
+}
+
+// Debuts lists the names recorded in year that do not appear in any earlier
+// year, most given first. With lookback > 0, names absent only from the
+// lookback years before year also count, so a name last seen long ago is
+// reported as a re-debut. gender can be "M", "F", or empty for all.
+func Debuts(records []Record, gender string, year, lookback int) ([]Debut, error) {
+	if lookback < 0 {
+		return nil, fmt.Errorf("lookback must be 0 or greater, got %d", lookback)
+	}
+	aggregated, _ := AggregateNames(records, year, gender)
+	if len(aggregated) == 0 {
+		return nil, ErrNoMatches
+	}
+	idx := NewFirstSeenIndex(records, gender)
+
+	var debuts []Debut
+	for i, entry := range aggregated {
+		prev, ok := idx.LastSeenBefore(entry.Name, year)
+		if ok && (lookback == 0 || year-prev <= lookback) {
+			continue
+		}
+		debuts = append(debuts, Debut{Name: entry.Name, Count: entry.Count, Rank: i + 1, PreviousYear: prev})
+	}
+	return debuts, nil
+}
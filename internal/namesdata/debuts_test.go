@@ -0,0 +1,57 @@
+package namesdata_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestDebuts(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 1990, Name: "Ruth", Count: 40},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Ava", Count: 10},
+		{State: "CA", Gender: "F", Year: 2010, Name: "Ava", Count: 50},
+		{State: "CA", Gender: "F", Year: 2010, Name: "Khaleesi", Count: 20},
+		{State: "TX", Gender: "F", Year: 2010, Name: "khaleesi", Count: 5},
+		{State: "CA", Gender: "F", Year: 2010, Name: "Ruth", Count: 30},
+		{State: "CA", Gender: "M", Year: 2000, Name: "Ruth", Count: 5},
+	}
+
+	idx := namesdata.NewFirstSeenIndex(records, "F")
+	if year, ok := idx.FirstSeen("ava"); !ok || year != 2000 {
+		t.Fatalf("FirstSeen(ava) = %d, %v", year, ok)
+	}
+	if year, ok := idx.LastSeenBefore("Ruth", 2010); !ok || year != 1990 {
+		t.Fatalf("LastSeenBefore(Ruth, 2010) = %d, %v", year, ok)
+	}
+	if _, ok := idx.LastSeenBefore("Ava", 2000); ok {
+		t.Fatalf("expected no appearance of Ava before 2000")
+	}
+
+	debuts, err := namesdata.Debuts(records, "F", 2010, 0)
+	if err != nil {
+		t.Fatalf("Debuts: %v", err)
+	}
+	if len(debuts) != 1 || debuts[0].Name != "Khaleesi" || debuts[0].Count != 25 || debuts[0].Rank != 3 || debuts[0].PreviousYear != 0 {
+		t.Fatalf("unexpected debuts: %+v", debuts)
+	}
+
+	// Ruth was last given to girls in 1990, outside a 10-year lookback.
+	debuts, err = namesdata.Debuts(records, "F", 2010, 10)
+	if err != nil {
+		t.Fatalf("Debuts with lookback: %v", err)
+	}
+	if len(debuts) != 2 || debuts[0].Name != "Ruth" || debuts[0].PreviousYear != 1990 || debuts[1].Name != "Khaleesi" {
+		t.Fatalf("unexpected debuts with lookback: %+v", debuts)
+	}
+
+	// Counting both genders, Ruth appears in 2000.
+	if debuts, _ := namesdata.Debuts(records, "", 2010, 10); len(debuts) != 1 {
+		t.Fatalf("unexpected debuts for both genders: %+v", debuts)
+	}
+
+	if _, err := namesdata.Debuts(records, "F", 2020, 0); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+}
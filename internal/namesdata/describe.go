@@ -0,0 +1,102 @@
+package namesdata
+
+import (
+	"context"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// DatasetInfo describes what a state dataset covers.
+type DatasetInfo struct {
+	FirstYear int
+	LastYear  int
+	Records   int
+	Births    int
+	States    []StateInfo
+}
+
+// StateInfo describes one state's file in a dataset.
+type StateInfo struct {
+	State     string
+	FirstYear int
+	LastYear  int
+	Records   int
+	Births    int
+}
+
+// StateCodes returns the codes of the states in info, in order.
+func (info DatasetInfo) StateCodes() []string {
+	codes := make([]string, len(info.States))
+	for i, state := range info.States {
+		codes[i] = state.State
+	}
+	return codes
+}
+
+// Describe scans every record in fsys, using its index when it has one, and
+// reports the years covered, the states present, and how many records and
+// births each holds. States are sorted by code. A dataset without records
+// is an error.
+func Describe(fsys fs.FS) (DatasetInfo, error) {
+	return DescribeContext(context.Background(), fsys)
+}
+
+// DescribeContext is Describe with cancellation; it stops with ctx.Err()
+// once ctx is done.
+func DescribeContext(ctx context.Context, fsys fs.FS) (DatasetInfo, error) {
+	var info DatasetInfo
+	byState := make(map[string]*StateInfo)
+	for rec, err := range RecordsContext(ctx, fsys, Filter{}) {
+		if err != nil {
+			return DatasetInfo{}, err
+		}
+		code := strings.ToUpper(rec.State)
+		state, ok := byState[code]
+		if !ok {
+			state = &StateInfo{State: code, FirstYear: rec.Year, LastYear: rec.Year}
+			byState[code] = state
+		}
+		state.Records++
+		state.Births += rec.Count
+		state.FirstYear = min(state.FirstYear, rec.Year)
+		state.LastYear = max(state.LastYear, rec.Year)
+	}
+	if len(byState) == 0 {
+		return DatasetInfo{}, ErrNoMatches
+	}
+
+	for _, state := range byState {
+		if len(info.States) == 0 || state.FirstYear < info.FirstYear {
+			info.FirstYear = state.FirstYear
+		}
+		info.LastYear = max(info.LastYear, state.LastYear)
+		info.Records += state.Records
+		info.Births += state.Births
+		info.States = append(info.States, *state)
+	}
+	sort.Slice(info.States, func(i, j int) bool { return info.States[i].State < info.States[j].State })
+	return info, nil
+}
+
+// DescribeNational is Describe for the national dataset, which has no
+// states: it reports the years covered and how many records and births the
+// yobYYYY.txt files hold.
+func DescribeNational(fsys fs.FS) (DatasetInfo, error) {
+	var info DatasetInfo
+	for rec, err := range NationalRecords(fsys, Filter{}) {
+		if err != nil {
+			return DatasetInfo{}, err
+		}
+		if info.Records == 0 || rec.Year < info.FirstYear {
+			info.FirstYear = rec.Year
+		}
+		info.LastYear = max(info.LastYear, rec.Year)
+		info.Records++
+		info.Births += rec.Count
+	}
+	if info.Records == 0 {
+		return DatasetInfo{}, ErrNoMatches
+	}
+	return info, nil
+}
@@ -0,0 +1,30 @@
+package namesdata_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestDescribe(t *testing.T) {
+	info, err := namesdata.Describe(sampleFS())
+	if err != nil {
+		t.Fatalf("Describe: %v", err)
+	}
+	if info.FirstYear != 2018 || info.LastYear != 2019 || info.Records != 11 || info.Births != 780 {
+		t.Fatalf("unexpected totals: %+v", info)
+	}
+	if strings.Join(info.StateCodes(), ",") != "CA,NY" {
+		t.Fatalf("unexpected states: %v", info.StateCodes())
+	}
+	ny := info.States[1]
+	if ny.Records != 3 || ny.Births != 170 || ny.FirstYear != 2018 || ny.LastYear != 2019 {
+		t.Fatalf("unexpected NY info: %+v", ny)
+	}
+
+	if _, err := namesdata.Describe(fstest.MapFS{"ZZ.TXT": {Data: []byte("\n")}}); err == nil {
+		t.Fatal("expected an error for a dataset without records")
+	}
+}
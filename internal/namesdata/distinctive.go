@@ -0,0 +1,94 @@
+package namesdata
+
+import (
+	"errors"
+	"iter"
+	"sort"
+	"strings"
+)
+
+// DistinctiveName compares a name's share of births in one state with its
+// share nationally.
+type DistinctiveName struct {
+	Name          string
+	StateCount    int
+	StateShare    float64
+	NationalCount int
+	NationalShare float64
+}
+
+// Lift returns how many times more common the name is in the state than
+// nationally.
+func (d DistinctiveName) Lift() float64 {
+	if d.NationalShare == 0 {
+		return 0
+	}
+	return d.StateShare / d.NationalShare
+}
+
+// DistinctiveNames totals state and the nation, the sum of every state in
+// records, in a single pass and returns the names given at least minCount
+// times in state ordered by Lift, most distinctive first, ties broken by
+// state count and then name. Records should cover every state so the
+// national shares are meaningful. gender can be "M", "F", or empty for
+// all. ErrNoMatches is returned when state has no matching records.
+func DistinctiveNames(records iter.Seq2[Record, error], state, gender string, minCount int) ([]DistinctiveName, error) {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	if state == "" {
+		return nil, errors.New("state is required")
+	}
+	gender = strings.ToUpper(strings.TrimSpace(gender))
+
+	stateCounts := make(map[string]*NameCount)
+	nationalCounts := make(map[string]int)
+	stateTotal, nationalTotal := 0, 0
+	for rec, err := range records {
+		if err != nil {
+			return nil, err
+		}
+		if gender != "" && strings.ToUpper(rec.Gender) != gender {
+			continue
+		}
+		key := strings.ToUpper(rec.Name)
+		nationalCounts[key] += rec.Count
+		nationalTotal += rec.Count
+		if strings.ToUpper(rec.State) != state {
+			continue
+		}
+		entry, ok := stateCounts[key]
+		if !ok {
+			entry = &NameCount{Name: rec.Name}
+			stateCounts[key] = entry
+		}
+		entry.Count += rec.Count
+		stateTotal += rec.Count
+	}
+	if stateTotal == 0 {
+		return nil, ErrNoMatches
+	}
+
+	var result []DistinctiveName
+	for key, entry := range stateCounts {
+		if entry.Count < minCount {
+			continue
+		}
+		result = append(result, DistinctiveName{
+			Name:          entry.Name,
+			StateCount:    entry.Count,
+			StateShare:    float64(entry.Count) / float64(stateTotal),
+			NationalCount: nationalCounts[key],
+			NationalShare: float64(nationalCounts[key]) / float64(nationalTotal),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Lift() != b.Lift() {
+			return a.Lift() > b.Lift()
+		}
+		if a.StateCount != b.StateCount {
+			return a.StateCount > b.StateCount
+		}
+		return a.Name < b.Name
+	})
+	return result, nil
+}
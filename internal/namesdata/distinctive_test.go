@@ -0,0 +1,37 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestDistinctiveNames(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "UT", Gender: "F", Year: 2019, Name: "Brynlee", Count: 30},
+		{State: "UT", Gender: "F", Year: 2019, Name: "Emma", Count: 60},
+		{State: "UT", Gender: "F", Year: 2019, Name: "Rare", Count: 10},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Brynlee", Count: 10},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Emma", Count: 290},
+		{State: "CA", Gender: "M", Year: 2019, Name: "Liam", Count: 500},
+	}
+
+	names, err := namesdata.DistinctiveNames(namesdata.SliceRecords(records), "ut", "F", 20)
+	if err != nil {
+		t.Fatalf("DistinctiveNames: %v", err)
+	}
+	// Girls nationally: 400 births, Brynlee 40 (10%) and Emma 350 (87.5%).
+	// In Utah Brynlee has 30% and Emma 60%.
+	if len(names) != 2 || names[0].Name != "Brynlee" || names[1].Name != "Emma" {
+		t.Fatalf("unexpected names: %+v", names)
+	}
+	if b := names[0]; b.StateCount != 30 || b.NationalCount != 40 || math.Abs(b.Lift()-3) > 1e-9 {
+		t.Fatalf("unexpected Brynlee: %+v (lift %v)", b, b.Lift())
+	}
+
+	if _, err := namesdata.DistinctiveNames(namesdata.SliceRecords(records), "NY", "", 0); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package namesdata
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// StateDivergence measures how far one state's name distribution is from
+// the national one.
+type StateDivergence struct {
+	State string
+	Total int
+	// TotalVariation is half the sum of the absolute differences between
+	// the state's and the nation's share of every name: the fraction of the
+	// state's births that would need a different name to match the nation.
+	// It runs from 0 for identical distributions to 1.
+	TotalVariation float64
+	// JensenShannon is the Jensen-Shannon divergence between the two
+	// distributions in bits, from 0 to 1. Unlike TotalVariation it weighs
+	// differences in rare names more heavily relative to their size.
+	JensenShannon float64
+	// Excess is the name whose state share most exceeds its national
+	// share, and ExcessShare that difference.
+	Excess      string
+	ExcessShare float64
+}
+
+// NationalDivergence compares every state with data against the national
+// distribution, taken as the sum of all the states, and returns the states
+// ordered by TotalVariation, most unusual first, with ties broken by state
+// code. ErrNoMatches is returned when no state has any data.
+func NationalDivergence(states []StateAggregate) ([]StateDivergence, error) {
+	national := make(map[string]int)
+	display := make(map[string]string)
+	total := 0
+	for _, state := range states {
+		for _, entry := range state.Names {
+			key := strings.ToUpper(entry.Name)
+			national[key] += entry.Count
+			if _, ok := display[key]; !ok {
+				display[key] = entry.Name
+			}
+		}
+		total += state.Total
+	}
+	if total == 0 {
+		return nil, ErrNoMatches
+	}
+	// Sum in a fixed order so the scores do not depend on map iteration.
+	keys := make([]string, 0, len(national))
+	for key := range national {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []StateDivergence
+	for _, state := range states {
+		if state.Total == 0 {
+			continue
+		}
+		counts := make(map[string]int, len(state.Names))
+		for _, entry := range state.Names {
+			counts[strings.ToUpper(entry.Name)] += entry.Count
+		}
+
+		d := StateDivergence{State: state.State, Total: state.Total}
+		for _, key := range keys {
+			p := float64(counts[key]) / float64(state.Total)
+			q := float64(national[key]) / float64(total)
+			d.TotalVariation += math.Abs(p-q) / 2
+			m := (p + q) / 2
+			if p > 0 {
+				d.JensenShannon += p * math.Log2(p/m) / 2
+			}
+			if q > 0 {
+				d.JensenShannon += q * math.Log2(q/m) / 2
+			}
+			if excess := p - q; excess > d.ExcessShare {
+				d.Excess, d.ExcessShare = display[key], excess
+			}
+		}
+		result = append(result, d)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalVariation != result[j].TotalVariation {
+			return result[i].TotalVariation > result[j].TotalVariation
+		}
+		return result[i].State < result[j].State
+	})
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestNationalDivergence(t *testing.T) {
+	states := []namesdata.StateAggregate{
+		{State: "CA", Names: []namesdata.NameCount{{Name: "Emma", Count: 50}, {Name: "Ava", Count: 50}}, Total: 100},
+		{State: "NY", Names: []namesdata.NameCount{{Name: "Emma", Count: 50}, {Name: "Ava", Count: 50}}, Total: 100},
+		{State: "UT", Names: []namesdata.NameCount{{Name: "Brynlee", Count: 100}}, Total: 100},
+		{State: "WY"},
+	}
+
+	scores, err := namesdata.NationalDivergence(states)
+	if err != nil {
+		t.Fatalf("NationalDivergence: %v", err)
+	}
+	if len(scores) != 3 || scores[0].State != "UT" || scores[1].State != "CA" || scores[2].State != "NY" {
+		t.Fatalf("unexpected order: %+v", scores)
+	}
+	// Nationally Emma and Ava each take 1/3 and Brynlee 1/3.
+	ut, ca := scores[0], scores[1]
+	if math.Abs(ut.TotalVariation-2.0/3) > 1e-9 || ut.Excess != "Brynlee" || math.Abs(ut.ExcessShare-2.0/3) > 1e-9 {
+		t.Fatalf("unexpected UT score: %+v", ut)
+	}
+	if math.Abs(ca.TotalVariation-1.0/3) > 1e-9 || ca.Excess != "Ava" {
+		t.Fatalf("unexpected CA score: %+v", ca)
+	}
+	if ut.JensenShannon <= ca.JensenShannon || ut.JensenShannon > 1 || ca.JensenShannon <= 0 {
+		t.Fatalf("unexpected Jensen-Shannon divergences: UT %v, CA %v", ut.JensenShannon, ca.JensenShannon)
+	}
+
+	if _, err := namesdata.NationalDivergence(states[3:]); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package namesdata
+
+import (
+	"iter"
+	"math"
+	"sort"
+)
+
+// DiversityStats summarizes how evenly births are spread across names.
+type DiversityStats struct {
+	// Year is the year measured, or 0 when the stats pool every year.
+	Year  int
+	Total int
+	// Names is the number of distinct names.
+	Names int
+	// Entropy is the Shannon entropy of the name distribution in bits.
+	// It grows as births spread across more names more evenly.
+	Entropy float64
+	// Herfindahl is the sum of squared name shares: the chance that two
+	// babies picked at random share a name. It grows with concentration.
+	Herfindahl float64
+	// Gini is the Gini coefficient of the counts across names, from 0 when
+	// every name is equally common toward 1 when a few names take almost
+	// every birth.
+	Gini float64
+}
+
+// EffectiveNames returns the number of equally common names that would give
+// the same entropy (the exponential of the Shannon entropy).
+func (d DiversityStats) EffectiveNames() float64 {
+	return math.Exp2(d.Entropy)
+}
+
+// Diversity measures the spread of births across names in the records. year
+// == 0 pools all years and gender can be "M", "F", or empty for all.
+// ErrNoMatches is returned when no records match.
+func Diversity(records []Record, year int, gender string) (DiversityStats, error) {
+	aggregated, _ := AggregateNames(records, year, gender)
+	total := 0
+	for _, entry := range aggregated {
+		total += entry.Count
+	}
+	if total == 0 {
+		return DiversityStats{}, ErrNoMatches
+	}
+	stats := measureDiversity(aggregated, total)
+	stats.Year = year
+	return stats, nil
+}
+
+// DiversityByYear measures the spread of births across names separately for
+// every year in records, in chronological order. gender can be "M", "F", or
+// empty for all.
+func DiversityByYear(records iter.Seq2[Record, error], gender string) ([]DiversityStats, error) {
+	acc := newYearAccumulator(gender)
+	for rec, err := range records {
+		if err != nil {
+			return nil, err
+		}
+		acc.add(rec)
+	}
+	yearly := acc.result()
+	if len(yearly) == 0 {
+		return nil, ErrNoMatches
+	}
+
+	result := make([]DiversityStats, len(yearly))
+	for i, agg := range yearly {
+		result[i] = measureDiversity(agg.Names, agg.Total)
+		result[i].Year = agg.Year
+	}
+	return result, nil
+}
+
+// measureDiversity computes the stats for names, whose counts sum to total.
+func measureDiversity(names []NameCount, total int) DiversityStats {
+	stats := DiversityStats{Total: total, Names: len(names)}
+	counts := make([]int, 0, len(names))
+	for _, entry := range names {
+		if entry.Count <= 0 {
+			continue
+		}
+		p := float64(entry.Count) / float64(total)
+		stats.Entropy -= p * math.Log2(p)
+		stats.Herfindahl += p * p
+		counts = append(counts, entry.Count)
+	}
+
+	// With counts ascending, G = sum((2i - n - 1) * x_i) / (n * sum(x)) for
+	// 1-based i.
+	sort.Ints(counts)
+	n := float64(len(counts))
+	weighted := 0.0
+	for i, count := range counts {
+		weighted += (2*float64(i+1) - n - 1) * float64(count)
+	}
+	if len(counts) > 0 {
+		stats.Gini = weighted / (n * float64(total))
+	}
+	return stats
+}
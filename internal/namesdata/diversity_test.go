@@ -0,0 +1,48 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestDiversity(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 2000, Name: "Ava", Count: 60},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Mia", Count: 30},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Zoe", Count: 10},
+		{State: "CA", Gender: "M", Year: 2000, Name: "Liam", Count: 500},
+		{State: "CA", Gender: "F", Year: 2001, Name: "Ava", Count: 25},
+		{State: "NY", Gender: "F", Year: 2001, Name: "Mia", Count: 25},
+	}
+
+	stats, err := namesdata.Diversity(records, 2000, "F")
+	if err != nil {
+		t.Fatalf("Diversity: %v", err)
+	}
+	entropy := -(0.6*math.Log2(0.6) + 0.3*math.Log2(0.3) + 0.1*math.Log2(0.1))
+	if stats.Year != 2000 || stats.Total != 100 || stats.Names != 3 || math.Abs(stats.Entropy-entropy) > 1e-9 {
+		t.Fatalf("unexpected 2000 diversity: %+v", stats)
+	}
+	if math.Abs(stats.Herfindahl-0.46) > 1e-9 || math.Abs(stats.Gini-1.0/3) > 1e-9 {
+		t.Fatalf("unexpected 2000 concentration: herfindahl %v, gini %v", stats.Herfindahl, stats.Gini)
+	}
+
+	yearly, err := namesdata.DiversityByYear(namesdata.SliceRecords(records), "F")
+	if err != nil {
+		t.Fatalf("DiversityByYear: %v", err)
+	}
+	if len(yearly) != 2 || yearly[0] != stats {
+		t.Fatalf("unexpected yearly diversity: %+v", yearly)
+	}
+	// Two equally common names: one bit, no inequality.
+	if even := yearly[1]; even.Entropy != 1 || even.EffectiveNames() != 2 || even.Herfindahl != 0.5 || even.Gini != 0 {
+		t.Fatalf("unexpected 2001 diversity: %+v", even)
+	}
+
+	if _, err := namesdata.Diversity(records, 1990, ""); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package namesdata
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ForecastModel selects how Forecast projects a series past its last year.
+type ForecastModel string
+
+const (
+	// ForecastLinear fits a least-squares line through the observed years.
+	ForecastLinear ForecastModel = "linear"
+	// ForecastHolt applies Holt's double exponential smoothing, which
+	// weights recent years more heavily than a straight-line fit.
+	ForecastHolt ForecastModel = "holt"
+)
+
+// Smoothing factors for ForecastHolt: holtAlpha for the level and holtBeta
+// for the trend.
+const (
+	holtAlpha = 0.5
+	holtBeta  = 0.3
+)
+
+// ParseForecastModel converts a model name, case-insensitively, into a
+// ForecastModel. An empty name selects ForecastLinear.
+func ParseForecastModel(raw string) (ForecastModel, error) {
+	switch ForecastModel(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", ForecastLinear:
+		return ForecastLinear, nil
+	case ForecastHolt:
+		return ForecastHolt, nil
+	}
+	return "", fmt.Errorf("unsupported forecast model %q (expected linear or holt)", raw)
+}
+
+// Forecast extends a trend by horizon years past its last year. Each series'
+// ranks and counts, and the yearly birth totals, are projected independently
+// from their observed years with model; the projected points have Forecast
+// set. A series with fewer than two observed years cannot be projected and
+// gets absent points. Projected ranks are at least 1, and a series whose
+// projected count falls to zero is absent for that year.
+func Forecast(years []int, series []TrendSeries, totals map[int]int, horizon int, model ForecastModel) ([]int, []TrendSeries, map[int]int, error) {
+	if horizon <= 0 {
+		return nil, nil, nil, errors.New("forecast horizon must be positive")
+	}
+	if model != ForecastLinear && model != ForecastHolt {
+		return nil, nil, nil, fmt.Errorf("unsupported forecast model %q", model)
+	}
+	if len(years) == 0 {
+		return nil, nil, nil, ErrNoMatches
+	}
+
+	last := years[len(years)-1]
+	future := make([]int, horizon)
+	for i := range future {
+		future[i] = last + i + 1
+	}
+
+	extendedTotals := make(map[int]int, len(totals)+horizon)
+	var totalYears []int
+	var totalValues []float64
+	for _, year := range years {
+		if total := totals[year]; total > 0 {
+			extendedTotals[year] = total
+			totalYears = append(totalYears, year)
+			totalValues = append(totalValues, float64(total))
+		}
+	}
+	projectedTotals := projectSeries(totalYears, totalValues, future, model)
+	for i, year := range future {
+		if projectedTotals != nil {
+			extendedTotals[year] = max(0, int(math.Round(projectedTotals[i])))
+		}
+	}
+
+	extended := make([]TrendSeries, len(series))
+	for si, s := range series {
+		var observed []int
+		var ranks, counts, pointTotals []float64
+		for _, point := range s.Points {
+			if !point.Present || point.Forecast {
+				continue
+			}
+			observed = append(observed, point.Year)
+			ranks = append(ranks, float64(point.Rank))
+			counts = append(counts, float64(point.Count))
+			pointTotals = append(pointTotals, float64(point.Total))
+		}
+		projectedRanks := projectSeries(observed, ranks, future, model)
+		projectedCounts := projectSeries(observed, counts, future, model)
+		projectedPointTotals := projectSeries(observed, pointTotals, future, model)
+
+		points := make([]TrendPoint, 0, len(s.Points)+horizon)
+		points = append(points, s.Points...)
+		for i, year := range future {
+			point := TrendPoint{Year: year, Forecast: true}
+			if projectedCounts != nil {
+				point.Count = int(math.Round(projectedCounts[i]))
+				point.Rank = max(1, int(math.Round(projectedRanks[i])))
+				point.Total = max(0, int(math.Round(projectedPointTotals[i])))
+				point.Present = point.Count > 0
+				if !point.Present {
+					point.Count, point.Rank, point.Total = 0, 0, 0
+				}
+			}
+			points = append(points, point)
+		}
+		s.Points = points
+		extended[si] = s
+	}
+
+	allYears := make([]int, 0, len(years)+horizon)
+	allYears = append(allYears, years...)
+	allYears = append(allYears, future...)
+	return allYears, extended, extendedTotals, nil
+}
+
+// projectSeries projects the observations ys, taken in years xs (ascending),
+// to each of targets. It returns nil when there are fewer than two
+// observations.
+func projectSeries(xs []int, ys []float64, targets []int, model ForecastModel) []float64 {
+	if len(xs) < 2 {
+		return nil
+	}
+	projected := make([]float64, len(targets))
+	switch model {
+	case ForecastHolt:
+		// Gaps between observed years are treated as single steps, so the
+		// smoothed trend is per observation rather than per calendar year.
+		level, trend := ys[0], ys[1]-ys[0]
+		for _, y := range ys[1:] {
+			previous := level
+			level = holtAlpha*y + (1-holtAlpha)*(level+trend)
+			trend = holtBeta*(level-previous) + (1-holtBeta)*trend
+		}
+		lastYear := xs[len(xs)-1]
+		for i, target := range targets {
+			projected[i] = level + float64(target-lastYear)*trend
+		}
+	default:
+		var meanX, meanY float64
+		for i := range xs {
+			meanX += float64(xs[i])
+			meanY += ys[i]
+		}
+		meanX /= float64(len(xs))
+		meanY /= float64(len(xs))
+		var covariance, variance float64
+		for i := range xs {
+			dx := float64(xs[i]) - meanX
+			covariance += dx * (ys[i] - meanY)
+			variance += dx * dx
+		}
+		slope := 0.0
+		if variance > 0 {
+			slope = covariance / variance
+		}
+		for i, target := range targets {
+			projected[i] = meanY + slope*(float64(target)-meanX)
+		}
+	}
+	return projected
+}
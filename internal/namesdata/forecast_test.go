@@ -0,0 +1,72 @@
+package namesdata_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestForecast(t *testing.T) {
+	years := []int{2000, 2001, 2002}
+	series := []namesdata.TrendSeries{
+		{Name: "Ava", Points: []namesdata.TrendPoint{
+			{Year: 2000, Rank: 5, Count: 100, Present: true, Total: 1000},
+			{Year: 2001, Rank: 4, Count: 120, Present: true, Total: 1000},
+			{Year: 2002, Rank: 3, Count: 140, Present: true, Total: 1000},
+		}},
+		{Name: "Zoe", Points: []namesdata.TrendPoint{
+			{Year: 2000, Rank: 9, Count: 30, Present: true, Total: 1000},
+			{Year: 2001, Rank: 12, Count: 10, Present: true, Total: 1000},
+			{Year: 2002},
+		}},
+		{Name: "Mia", Points: []namesdata.TrendPoint{
+			{Year: 2000},
+			{Year: 2001},
+			{Year: 2002, Rank: 7, Count: 50, Present: true, Total: 1000},
+		}},
+	}
+	totals := map[int]int{2000: 1000, 2001: 1000, 2002: 1000}
+
+	extendedYears, extended, extendedTotals, err := namesdata.Forecast(years, series, totals, 2, namesdata.ForecastLinear)
+	if err != nil {
+		t.Fatalf("Forecast: %v", err)
+	}
+	if len(extendedYears) != 5 || extendedYears[4] != 2004 || extendedTotals[2004] != 1000 {
+		t.Fatalf("unexpected extended years or totals: %v %v", extendedYears, extendedTotals)
+	}
+
+	ava := extended[0].Points
+	if got := ava[3]; !got.Forecast || !got.Present || got.Count != 160 || got.Rank != 2 {
+		t.Fatalf("unexpected 2003 forecast for Ava: %+v", got)
+	}
+	if got := ava[4]; got.Count != 180 || got.Rank != 1 {
+		t.Fatalf("expected Ava's forecast rank to stop at 1, got %+v", got)
+	}
+	if ava[2].Forecast {
+		t.Fatalf("observed points must not be marked as forecast")
+	}
+	if got := extended[1].Points[3]; !got.Forecast || got.Present {
+		t.Fatalf("expected Zoe's falling count to leave her absent, got %+v", got)
+	}
+	if got := extended[2].Points[3]; !got.Forecast || got.Present {
+		t.Fatalf("expected no forecast from a single observation, got %+v", got)
+	}
+	if len(series[0].Points) != 3 {
+		t.Fatalf("Forecast must not modify its input series")
+	}
+
+	_, holt, _, err := namesdata.Forecast(years, series, totals, 1, namesdata.ForecastHolt)
+	if err != nil {
+		t.Fatalf("Forecast holt: %v", err)
+	}
+	if got := holt[0].Points[3]; got.Count != 160 {
+		t.Fatalf("expected Holt to follow a perfectly linear series, got %+v", got)
+	}
+
+	if _, _, _, err := namesdata.Forecast(years, series, totals, 0, namesdata.ForecastLinear); err == nil {
+		t.Fatalf("expected error for a zero horizon")
+	}
+	if _, err := namesdata.ParseForecastModel("arima"); err == nil {
+		t.Fatalf("expected error for an unknown model")
+	}
+}
@@ -0,0 +1,149 @@
+package namesdata
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyFunc derives a grouping key from a name, such as its first letter.
+// Records whose name maps to "" still count toward the year's total but
+// belong to no group.
+type KeyFunc func(name string) string
+
+// InitialLetter is a KeyFunc grouping names by their upper-cased first
+// letter.
+func InitialLetter(name string) string {
+	r, _ := utf8.DecodeRuneInString(strings.TrimSpace(name))
+	if r == utf8.RuneError || !unicode.IsLetter(r) {
+		return ""
+	}
+	return string(unicode.ToUpper(r))
+}
+
+// FinalLetters returns a KeyFunc grouping names by their last n letters,
+// lower-cased after a dash, such as "-ayden" for n = 5. Names shorter than
+// n letters belong to no group.
+func FinalLetters(n int) KeyFunc {
+	return func(name string) string {
+		runes := []rune(strings.TrimSpace(name))
+		if n < 1 || len(runes) < n {
+			return ""
+		}
+		return "-" + strings.ToLower(string(runes[len(runes)-n:]))
+	}
+}
+
+// YearGroups holds every year's births split into groups by a derived key.
+// Counts rows follow Keys and columns follow Years.
+type YearGroups struct {
+	Years []int
+	// Keys is sorted alphabetically.
+	Keys   []string
+	Counts [][]int
+	// Totals holds each year's births across every name.
+	Totals []int
+}
+
+// Share returns group k's fraction of the births in year column y.
+func (g YearGroups) Share(k, y int) float64 {
+	if g.Totals[y] == 0 {
+		return 0
+	}
+	return float64(g.Counts[k][y]) / float64(g.Totals[y])
+}
+
+// Total returns group k's births across every year.
+func (g YearGroups) Total(k int) int {
+	total := 0
+	for _, count := range g.Counts[k] {
+		total += count
+	}
+	return total
+}
+
+// Largest returns the indexes of the n groups with the most births across
+// every year, largest first, or of every group when n is 0 or exceeds the
+// number of groups.
+func (g YearGroups) Largest(n int) []int {
+	order := make([]int, len(g.Keys))
+	totals := make([]int, len(g.Keys))
+	for k := range g.Keys {
+		order[k] = k
+		totals[k] = g.Total(k)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(totals[b], totals[a])
+	})
+	if n > 0 && n < len(order) {
+		order = order[:n]
+	}
+	return order
+}
+
+// Index returns the position of key in Keys, or -1 when it is absent.
+func (g YearGroups) Index(key string) int {
+	i, ok := slices.BinarySearch(g.Keys, key)
+	if !ok {
+		return -1
+	}
+	return i
+}
+
+// GroupByYear sums the births in records by year and by the key derived
+// from each name, in a single pass. gender can be "M", "F", or empty for
+// all. ErrNoMatches is returned when no records match.
+func GroupByYear(records iter.Seq2[Record, error], gender string, key KeyFunc) (YearGroups, error) {
+	gender = strings.ToUpper(strings.TrimSpace(gender))
+
+	counts := make(map[string]map[int]int)
+	totals := make(map[int]int)
+	for rec, err := range records {
+		if err != nil {
+			return YearGroups{}, err
+		}
+		if gender != "" && strings.ToUpper(rec.Gender) != gender {
+			continue
+		}
+		totals[rec.Year] += rec.Count
+		k := key(rec.Name)
+		if k == "" {
+			continue
+		}
+		byYear, ok := counts[k]
+		if !ok {
+			byYear = make(map[int]int)
+			counts[k] = byYear
+		}
+		byYear[rec.Year] += rec.Count
+	}
+	if len(totals) == 0 {
+		return YearGroups{}, ErrNoMatches
+	}
+
+	groups := YearGroups{Keys: make([]string, 0, len(counts))}
+	for year := range totals {
+		groups.Years = append(groups.Years, year)
+	}
+	slices.Sort(groups.Years)
+	for k := range counts {
+		groups.Keys = append(groups.Keys, k)
+	}
+	slices.Sort(groups.Keys)
+
+	groups.Totals = make([]int, len(groups.Years))
+	for y, year := range groups.Years {
+		groups.Totals[y] = totals[year]
+	}
+	groups.Counts = make([][]int, len(groups.Keys))
+	for k, key := range groups.Keys {
+		groups.Counts[k] = make([]int, len(groups.Years))
+		for y, year := range groups.Years {
+			groups.Counts[k][y] = counts[key][year]
+		}
+	}
+	return groups, nil
+}
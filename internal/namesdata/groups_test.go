@@ -0,0 +1,50 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestGroupByYear(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 2000, Name: "Ava", Count: 30},
+		{State: "CA", Gender: "F", Year: 2000, Name: "amy", Count: 10},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Mia", Count: 60},
+		{State: "CA", Gender: "F", Year: 2001, Name: "Mia", Count: 20},
+		{State: "CA", Gender: "F", Year: 2001, Name: "Zoe", Count: 20},
+		{State: "CA", Gender: "M", Year: 2001, Name: "Adam", Count: 50},
+	}
+
+	groups, err := namesdata.GroupByYear(namesdata.SliceRecords(records), "F", namesdata.InitialLetter)
+	if err != nil {
+		t.Fatalf("GroupByYear: %v", err)
+	}
+	if len(groups.Years) != 2 || len(groups.Keys) != 3 || groups.Keys[0] != "A" || groups.Keys[2] != "Z" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	if a := groups.Index("A"); groups.Counts[a][0] != 40 || groups.Counts[a][1] != 0 || math.Abs(groups.Share(a, 0)-0.4) > 1e-9 {
+		t.Fatalf("unexpected A counts: %v", groups.Counts[a])
+	}
+	if groups.Totals[1] != 40 || groups.Index("Q") != -1 {
+		t.Fatalf("unexpected totals or index: %v", groups.Totals)
+	}
+	// M has 80 births, A 40, Z 20.
+	if largest := groups.Largest(2); len(largest) != 2 || groups.Keys[largest[0]] != "M" || groups.Keys[largest[1]] != "A" {
+		t.Fatalf("unexpected largest groups: %v", largest)
+	}
+
+	if _, err := namesdata.GroupByYear(namesdata.SliceRecords(records), "X", namesdata.InitialLetter); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+
+	ending := namesdata.FinalLetters(3)
+	if got := ending("Brayden"); got != "-den" {
+		t.Fatalf("FinalLetters(3)(Brayden) = %q", got)
+	}
+	if got := ending("Al"); got != "" {
+		t.Fatalf("expected no ending for a short name, got %q", got)
+	}
+}
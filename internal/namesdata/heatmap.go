@@ -0,0 +1,126 @@
+package namesdata
+
+import (
+	"errors"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// StateYearShares is one name's popularity in every state and year of a
+// dataset: the grid behind a state-by-year heatmap.
+type StateYearShares struct {
+	Name string
+	// States are sorted by code; Years run without gaps from the first to
+	// the last year with data.
+	States []string
+	Years  []int
+	// Counts and Totals are indexed [state][year] in the order of States
+	// and Years. Totals holds every matching birth in the cell, the share
+	// denominator, and is zero where a state has no data for the year.
+	Counts [][]int
+	Totals [][]int
+}
+
+// Share returns the name's fraction of births in States[state] during
+// Years[year], or 0 when the cell has no births.
+func (s StateYearShares) Share(state, year int) float64 {
+	if s.Totals[state][year] == 0 {
+		return 0
+	}
+	return float64(s.Counts[state][year]) / float64(s.Totals[state][year])
+}
+
+// MaxShare returns the largest share in the grid.
+func (s StateYearShares) MaxShare() float64 {
+	highest := 0.0
+	for si := range s.States {
+		for yi := range s.Years {
+			highest = max(highest, s.Share(si, yi))
+		}
+	}
+	return highest
+}
+
+// NameByStateYear tallies name (case-insensitive) against every birth in
+// each state and year of records for the gender filter ("M", "F", or empty
+// for both). ErrNoMatches is returned when records hold no births and a
+// not-found error when the name never appears.
+func NameByStateYear(records iter.Seq2[Record, error], gender, name string) (StateYearShares, error) {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if key == "" {
+		return StateYearShares{}, errors.New("name is required")
+	}
+	gender = strings.ToUpper(strings.TrimSpace(gender))
+
+	type cell struct {
+		state string
+		year  int
+	}
+	counts := make(map[cell]int)
+	totals := make(map[cell]int)
+	seen := make(map[string]*NameCount)
+	display := ""
+	for rec, err := range records {
+		if err != nil {
+			return StateYearShares{}, err
+		}
+		if gender != "" && !strings.EqualFold(rec.Gender, gender) {
+			continue
+		}
+		c := cell{state: strings.ToUpper(rec.State), year: rec.Year}
+		totals[c] += rec.Count
+		upper := strings.ToUpper(rec.Name)
+		if upper == key {
+			counts[c] += rec.Count
+			if display == "" {
+				display = rec.Name
+			}
+			continue
+		}
+		if entry, ok := seen[upper]; ok {
+			entry.Count += rec.Count
+		} else {
+			seen[upper] = &NameCount{Name: rec.Name, Count: rec.Count}
+		}
+	}
+	if len(totals) == 0 {
+		return StateYearShares{}, ErrNoMatches
+	}
+	if display == "" {
+		candidates := make([]NameCount, 0, len(seen))
+		for _, entry := range seen {
+			candidates = append(candidates, *entry)
+		}
+		sortNameCounts(candidates)
+		return StateYearShares{}, newNameNotFoundError(strings.TrimSpace(name), candidates)
+	}
+
+	stateSet := make(map[string]bool)
+	first, last := 0, 0
+	for c := range totals {
+		stateSet[c.state] = true
+		if first == 0 || c.year < first {
+			first = c.year
+		}
+		last = max(last, c.year)
+	}
+	states := slices.Sorted(maps.Keys(stateSet))
+
+	grid := StateYearShares{Name: display, States: states}
+	for year := first; year <= last; year++ {
+		grid.Years = append(grid.Years, year)
+	}
+	grid.Counts = make([][]int, len(states))
+	grid.Totals = make([][]int, len(states))
+	for si, state := range states {
+		grid.Counts[si] = make([]int, len(grid.Years))
+		grid.Totals[si] = make([]int, len(grid.Years))
+		for yi, year := range grid.Years {
+			grid.Counts[si][yi] = counts[cell{state, year}]
+			grid.Totals[si][yi] = totals[cell{state, year}]
+		}
+	}
+	return grid, nil
+}
@@ -0,0 +1,44 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestNameByStateYear(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "NY", Gender: "F", Year: 2000, Name: "Olivia", Count: 30},
+		{State: "NY", Gender: "F", Year: 2000, Name: "Emma", Count: 70},
+		{State: "NY", Gender: "M", Year: 2000, Name: "Liam", Count: 100},
+		{State: "CA", Gender: "F", Year: 2002, Name: "olivia", Count: 50},
+		{State: "CA", Gender: "F", Year: 2002, Name: "Emma", Count: 150},
+	}
+
+	grid, err := namesdata.NameByStateYear(namesdata.SliceRecords(records), "F", "OLIVIA")
+	if err != nil {
+		t.Fatalf("NameByStateYear: %v", err)
+	}
+	if grid.Name != "Olivia" || len(grid.States) != 2 || grid.States[0] != "CA" || grid.States[1] != "NY" {
+		t.Fatalf("unexpected states: %+v", grid)
+	}
+	if len(grid.Years) != 3 || grid.Years[0] != 2000 || grid.Years[2] != 2002 {
+		t.Fatalf("expected the years 2000-2002 without gaps, got %v", grid.Years)
+	}
+	if grid.Counts[1][0] != 30 || grid.Totals[1][0] != 100 || grid.Totals[0][1] != 0 {
+		t.Fatalf("unexpected counts %v and totals %v", grid.Counts, grid.Totals)
+	}
+	if math.Abs(grid.Share(0, 2)-0.25) > 1e-9 || grid.Share(0, 0) != 0 || math.Abs(grid.MaxShare()-0.3) > 1e-9 {
+		t.Fatalf("unexpected shares: CA 2002 %v, max %v", grid.Share(0, 2), grid.MaxShare())
+	}
+
+	var notFound *namesdata.NameNotFoundError
+	if _, err := namesdata.NameByStateYear(namesdata.SliceRecords(records), "F", "Emmma"); !errors.As(err, &notFound) || len(notFound.Suggestions) == 0 || notFound.Suggestions[0] != "Emma" {
+		t.Fatalf("expected a not-found error suggesting Emma, got %v", err)
+	}
+	if _, err := namesdata.NameByStateYear(namesdata.SliceRecords(nil), "F", "Olivia"); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+}
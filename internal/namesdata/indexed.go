@@ -0,0 +1,37 @@
+package namesdata
+
+import (
+	"io/fs"
+
+	"github.com/curtiscovington/ssa-names/internal/index"
+)
+
+// IndexedFS is a dataset filesystem that carries a precomputed index, such
+// as the embedded dataset built with go generate. Index should parse the
+// index once and return the same result on every call.
+//
+// Loaders use an index only through this interface: a names.idx file found
+// in an arbitrary dataset directory is ignored, since nothing ties it to the
+// text files beside it.
+type IndexedFS interface {
+	fs.FS
+	Index() (*index.Index, error)
+}
+
+// openIndex returns the dataset's precomputed index, or nil when the dataset
+// has none and the text files must be parsed.
+func openIndex(fsys fs.FS) (*index.Index, error) {
+	indexed, ok := fsys.(IndexedFS)
+	if !ok {
+		return nil, nil
+	}
+	return indexed.Index()
+}
+
+// walkIndex streams records from idx for state, or for all states when state
+// is empty.
+func walkIndex(idx *index.Index, state string, fn func(Record) error) error {
+	return idx.Walk(state, func(r index.Record) error {
+		return fn(Record(r))
+	})
+}
@@ -0,0 +1,105 @@
+package namesdata
+
+import (
+	"iter"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// LengthDistribution is how births are spread across name lengths, counted
+// in letters.
+type LengthDistribution struct {
+	// Year is the year measured, or 0 when the distribution pools years.
+	Year  int
+	Total int
+	// Counts[n] is the births given names n letters long.
+	Counts []int
+}
+
+// Mean returns the count-weighted mean name length.
+func (d LengthDistribution) Mean() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	sum := 0
+	for n, count := range d.Counts {
+		sum += n * count
+	}
+	return float64(sum) / float64(d.Total)
+}
+
+// Median returns the shortest length that at least half of the births'
+// names are no longer than.
+func (d LengthDistribution) Median() int {
+	running := 0
+	for n, count := range d.Counts {
+		running += count
+		if d.Total > 0 && running*2 >= d.Total {
+			return n
+		}
+	}
+	return 0
+}
+
+// add merges other's counts into d.
+func (d *LengthDistribution) add(other LengthDistribution) {
+	if len(other.Counts) > len(d.Counts) {
+		d.Counts = append(d.Counts, make([]int, len(other.Counts)-len(d.Counts))...)
+	}
+	for n, count := range other.Counts {
+		d.Counts[n] += count
+	}
+	d.Total += other.Total
+}
+
+// PoolLengths combines distributions, such as those of several years, into
+// one with Year 0.
+func PoolLengths(dists []LengthDistribution) LengthDistribution {
+	var pooled LengthDistribution
+	for _, d := range dists {
+		pooled.add(d)
+	}
+	return pooled
+}
+
+// NameLengthsByYear measures, for every year in records, how the births are
+// spread across name lengths. gender can be "M", "F", or empty for all. The
+// result is ordered chronologically, and ErrNoMatches is returned when no
+// records match.
+func NameLengthsByYear(records iter.Seq2[Record, error], gender string) ([]LengthDistribution, error) {
+	gender = strings.ToUpper(strings.TrimSpace(gender))
+
+	byYear := make(map[int]*LengthDistribution)
+	for rec, err := range records {
+		if err != nil {
+			return nil, err
+		}
+		if rec.Count <= 0 || (gender != "" && strings.ToUpper(rec.Gender) != gender) {
+			continue
+		}
+		d, ok := byYear[rec.Year]
+		if !ok {
+			d = &LengthDistribution{Year: rec.Year}
+			byYear[rec.Year] = d
+		}
+		n := utf8.RuneCountInString(strings.TrimSpace(rec.Name))
+		if n >= len(d.Counts) {
+			d.Counts = append(d.Counts, make([]int, n+1-len(d.Counts))...)
+		}
+		d.Counts[n] += rec.Count
+		d.Total += rec.Count
+	}
+	if len(byYear) == 0 {
+		return nil, ErrNoMatches
+	}
+
+	result := make([]LengthDistribution, 0, len(byYear))
+	for _, d := range byYear {
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Year < result[j].Year
+	})
+	return result, nil
+}
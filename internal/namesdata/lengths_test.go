@@ -0,0 +1,45 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestNameLengthsByYear(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 1950, Name: "Elizabeth", Count: 30},
+		{State: "CA", Gender: "F", Year: 1950, Name: "Mary", Count: 10},
+		{State: "CA", Gender: "F", Year: 2020, Name: "Mia", Count: 30},
+		{State: "CA", Gender: "F", Year: 2020, Name: "Ava", Count: 10},
+		{State: "CA", Gender: "F", Year: 2020, Name: "Olivia", Count: 20},
+		{State: "CA", Gender: "M", Year: 2020, Name: "Christopher", Count: 50},
+	}
+
+	yearly, err := namesdata.NameLengthsByYear(namesdata.SliceRecords(records), "F")
+	if err != nil {
+		t.Fatalf("NameLengthsByYear: %v", err)
+	}
+	if len(yearly) != 2 || yearly[0].Year != 1950 || yearly[1].Total != 60 {
+		t.Fatalf("unexpected distributions: %+v", yearly)
+	}
+	// 1950: 30 births of 9 letters and 10 of 4.
+	if mean := yearly[0].Mean(); math.Abs(mean-7.75) > 1e-9 || yearly[0].Median() != 9 {
+		t.Fatalf("unexpected 1950 mean %v or median %d", mean, yearly[0].Median())
+	}
+	// 2020: 40 births of 3 letters and 20 of 6.
+	if mean := yearly[1].Mean(); math.Abs(mean-4) > 1e-9 || yearly[1].Median() != 3 {
+		t.Fatalf("unexpected 2020 mean %v or median %d", mean, yearly[1].Median())
+	}
+
+	pooled := namesdata.PoolLengths(yearly)
+	if pooled.Year != 0 || pooled.Total != 100 || pooled.Counts[3] != 40 || pooled.Counts[9] != 30 || pooled.Median() != 4 {
+		t.Fatalf("unexpected pooled distribution: %+v", pooled)
+	}
+
+	if _, err := namesdata.NameLengthsByYear(namesdata.SliceRecords(records), "X"); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package namesdata
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ExtinctName is a name that has been missing from the data for at least
+// the requested number of years up to the latest year.
+type ExtinctName struct {
+	Name      string
+	FirstYear int
+	LastYear  int
+	// PeakYear, PeakRank, and PeakCount describe the year the name was
+	// given most often.
+	PeakYear  int
+	PeakRank  int
+	PeakCount int
+	// YearsGone counts the years since LastYear through the latest year.
+	YearsGone int
+}
+
+// ExtinctNames lists the names in m last recorded at least gap years before
+// m's latest year, most popular at their peak first.
+func ExtinctNames(m RankMatrix, gap int) []ExtinctName {
+	if len(m.Years) == 0 {
+		return nil
+	}
+	latest := m.Years[len(m.Years)-1]
+
+	var extinct []ExtinctName
+	for row, name := range m.Names {
+		e := ExtinctName{Name: name}
+		for col, rank := range m.Ranks[row] {
+			if rank == 0 {
+				continue
+			}
+			if e.FirstYear == 0 {
+				e.FirstYear = m.Years[col]
+			}
+			e.LastYear = m.Years[col]
+			if count := m.Counts[row][col]; count > e.PeakCount {
+				e.PeakYear, e.PeakRank, e.PeakCount = m.Years[col], rank, count
+			}
+		}
+		e.YearsGone = latest - e.LastYear
+		if e.LastYear != 0 && e.YearsGone >= gap {
+			extinct = append(extinct, e)
+		}
+	}
+
+	slices.SortFunc(extinct, func(a, b ExtinctName) int {
+		return cmp.Or(cmp.Compare(b.PeakCount, a.PeakCount), cmp.Compare(a.Name, b.Name))
+	})
+	return extinct
+}
+
+// Comeback is a name that ranked within the top K, fell out of it for at
+// least the requested number of years, and then returned.
+type Comeback struct {
+	Name string
+	// PeakYear and PeakRank are the name's best rank before it fell out.
+	PeakYear int
+	PeakRank int
+	// DropYear is the first year out of the top K and ReturnYear the first
+	// year back, so YearsOut is their difference.
+	DropYear   int
+	ReturnYear int
+	ReturnRank int
+	YearsOut   int
+	// LowRank is the worst rank while out of the top K, or 0 when the name
+	// was missing from the data in some of those years.
+	LowRank int
+	// LatestRank is the rank in the latest year, 0 when absent.
+	LatestRank int
+}
+
+// Comebacks lists the names in m that left the top ranks of m for at least
+// gap years and re-entered them, longest absence first. A name that came
+// back more than once is reported for its most recent return.
+func Comebacks(m RankMatrix, top, gap int) []Comeback {
+	inTop := func(rank int) bool { return rank > 0 && rank <= top }
+
+	var comebacks []Comeback
+	for row, name := range m.Names {
+		ranks := m.Ranks[row]
+		var found *Comeback
+		// bestCol tracks the best top-K rank seen before the current
+		// stretch out of the top K, and dropCol where that stretch began.
+		bestCol, dropCol := -1, -1
+		for col, rank := range ranks {
+			switch {
+			case inTop(rank) && dropCol >= 0 && bestCol >= 0 && m.Years[col]-m.Years[dropCol] >= gap:
+				c := Comeback{
+					Name:       name,
+					PeakYear:   m.Years[bestCol],
+					PeakRank:   ranks[bestCol],
+					DropYear:   m.Years[dropCol],
+					ReturnYear: m.Years[col],
+					ReturnRank: rank,
+					YearsOut:   m.Years[col] - m.Years[dropCol],
+				}
+				for _, out := range ranks[dropCol:col] {
+					if out == 0 {
+						c.LowRank = 0
+						break
+					}
+					c.LowRank = max(c.LowRank, out)
+				}
+				found = &c
+				bestCol, dropCol = col, -1
+			case inTop(rank):
+				if bestCol < 0 || rank < ranks[bestCol] {
+					bestCol = col
+				}
+				dropCol = -1
+			case bestCol >= 0 && dropCol < 0:
+				dropCol = col
+			}
+		}
+		if found != nil {
+			found.LatestRank = ranks[len(ranks)-1]
+			comebacks = append(comebacks, *found)
+		}
+	}
+
+	slices.SortFunc(comebacks, func(a, b Comeback) int {
+		return cmp.Or(cmp.Compare(b.YearsOut, a.YearsOut), cmp.Compare(a.Name, b.Name))
+	})
+	return comebacks
+}
@@ -0,0 +1,59 @@
+package namesdata_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestExtinctNamesAndComebacks(t *testing.T) {
+	m := namesdata.RankMatrix{
+		Years: []int{2000, 2001, 2002, 2003, 2004, 2005},
+		Names: []string{"Ava", "Ruth", "Mabel", "Eleanor", "Ida"},
+		Ranks: [][]int{
+			{1, 1, 1, 1, 1, 1},
+			{2, 2, 0, 0, 0, 0},
+			{3, 0, 0, 0, 0, 0},
+			{4, 3, 0, 5, 4, 2},
+			{5, 6, 2, 7, 8, 3},
+		},
+		Counts: [][]int{
+			{90, 90, 90, 90, 90, 90},
+			{50, 60, 0, 0, 0, 0},
+			{40, 0, 0, 0, 0, 0},
+			{30, 40, 0, 10, 20, 50},
+			{20, 10, 60, 5, 4, 40},
+		},
+	}
+
+	extinct := namesdata.ExtinctNames(m, 4)
+	if len(extinct) != 2 {
+		t.Fatalf("expected Ruth and Mabel, got %+v", extinct)
+	}
+	if ruth := extinct[0]; ruth.Name != "Ruth" || ruth.FirstYear != 2000 || ruth.LastYear != 2001 || ruth.YearsGone != 4 || ruth.PeakYear != 2001 || ruth.PeakRank != 2 {
+		t.Fatalf("unexpected Ruth: %+v", ruth)
+	}
+	if mabel := extinct[1]; mabel.Name != "Mabel" || mabel.YearsGone != 5 {
+		t.Fatalf("unexpected Mabel: %+v", mabel)
+	}
+
+	// Within the top 3: Eleanor is out 2002-2004 and missing in 2002; Ida
+	// first enters at #2 in 2002, then is out 2003-2004.
+	comebacks := namesdata.Comebacks(m, 3, 2)
+	if len(comebacks) != 2 {
+		t.Fatalf("expected Eleanor and Ida, got %+v", comebacks)
+	}
+	eleanor := comebacks[0]
+	if eleanor.Name != "Eleanor" || eleanor.PeakYear != 2001 || eleanor.PeakRank != 3 || eleanor.DropYear != 2002 ||
+		eleanor.ReturnYear != 2005 || eleanor.ReturnRank != 2 || eleanor.YearsOut != 3 || eleanor.LowRank != 0 || eleanor.LatestRank != 2 {
+		t.Fatalf("unexpected Eleanor: %+v", eleanor)
+	}
+	ida := comebacks[1]
+	if ida.Name != "Ida" || ida.PeakYear != 2002 || ida.PeakRank != 2 || ida.DropYear != 2003 || ida.YearsOut != 2 || ida.LowRank != 8 {
+		t.Fatalf("unexpected Ida: %+v", ida)
+	}
+
+	if got := namesdata.Comebacks(m, 3, 4); len(got) != 0 {
+		t.Fatalf("expected no comebacks with a 4-year gap, got %+v", got)
+	}
+}
@@ -0,0 +1,158 @@
+package namesdata
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The orders a MarkovModel accepts. Order 2 invents more freely; order 3
+// stays closer to real spelling.
+const (
+	MinMarkovOrder = 2
+	MaxMarkovOrder = 3
+)
+
+const (
+	// markovStart pads the context before a name's first letter and
+	// markovEnd marks where a name stops.
+	markovStart = '^'
+	markovEnd   = '$'
+	// markovMaxLength is the longest name Sample will produce.
+	markovMaxLength = 15
+	// markovAttempts bounds how many candidates Sample draws before giving
+	// up on finding a new name.
+	markovAttempts = 1000
+)
+
+// MarkovModel is a character-level Markov chain trained on names: the next
+// letter of a name is drawn according to how often it follows the previous
+// Order() letters in the training names. Sampling it invents names that
+// look like the training set but, by default, are not in it.
+type MarkovModel struct {
+	order  int
+	counts map[string]map[byte]float64
+	known  map[string]struct{}
+	chains map[string]markovChain
+}
+
+// markovChain holds the letters that may follow one context, with their
+// cumulative weights for sampling.
+type markovChain struct {
+	next       []byte
+	cumulative []float64
+}
+
+// NewMarkovModel returns an untrained model that conditions each letter on
+// the order letters before it.
+func NewMarkovModel(order int) (*MarkovModel, error) {
+	if order < MinMarkovOrder || order > MaxMarkovOrder {
+		return nil, fmt.Errorf("markov order must be between %d and %d", MinMarkovOrder, MaxMarkovOrder)
+	}
+	return &MarkovModel{
+		order:  order,
+		counts: make(map[string]map[byte]float64),
+		known:  make(map[string]struct{}),
+	}, nil
+}
+
+// Order returns the number of preceding letters each letter depends on.
+func (m *MarkovModel) Order() int {
+	return m.order
+}
+
+// Train adds names to the model, weighting each by the square root of its
+// sampling weight so popular names shape the chain without drowning out the rest.
+// It may be called more than once to combine several pools.
+func (m *MarkovModel) Train(names []NameCount) {
+	for _, entry := range names {
+		name := strings.ToUpper(strings.TrimSpace(entry.Name))
+		if name == "" || entry.SamplingWeight() <= 0 {
+			continue
+		}
+		m.known[name] = struct{}{}
+		weight := math.Sqrt(entry.SamplingWeight())
+		context := strings.Repeat(string(markovStart), m.order)
+		for _, next := range []byte(name + string(markovEnd)) {
+			if m.counts[context] == nil {
+				m.counts[context] = make(map[byte]float64)
+			}
+			m.counts[context][next] += weight
+			context = context[1:] + string(next)
+		}
+	}
+
+	// Letters are ordered so a seeded source always yields the same names.
+	m.chains = make(map[string]markovChain, len(m.counts))
+	for context, followers := range m.counts {
+		next := make([]byte, 0, len(followers))
+		for b := range followers {
+			next = append(next, b)
+		}
+		slices.Sort(next)
+		chain := markovChain{next: next, cumulative: make([]float64, len(next))}
+		total := 0.0
+		for i, b := range next {
+			total += followers[b]
+			chain.cumulative[i] = total
+		}
+		m.chains[context] = chain
+	}
+}
+
+// Known reports whether name (case-insensitive) was in the training data.
+func (m *MarkovModel) Known(name string) bool {
+	_, ok := m.known[strings.ToUpper(strings.TrimSpace(name))]
+	return ok
+}
+
+// Sample generates a name of at least two letters that was not in the
+// training data, capitalized like the SSA data.
+func (m *MarkovModel) Sample(r *rand.Rand) (string, error) {
+	return m.SampleFunc(r, nil)
+}
+
+// SampleFunc is like Sample but also rejects names for which accept, when
+// non-nil, returns false. It gives up with an error when no acceptable new
+// name turns up after many attempts.
+func (m *MarkovModel) SampleFunc(r *rand.Rand, accept func(name string) bool) (string, error) {
+	if r == nil {
+		return "", errors.New("random source is required")
+	}
+	if len(m.chains) == 0 {
+		return "", errors.New("markov model has not been trained")
+	}
+	for range markovAttempts {
+		name, ok := m.walk(r)
+		if !ok || len(name) < 2 || m.Known(name) {
+			continue
+		}
+		name = name[:1] + strings.ToLower(name[1:])
+		if accept == nil || accept(name) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no new name found in %d attempts", markovAttempts)
+}
+
+// walk follows the chain from the start of a name, returning false when it
+// runs past markovMaxLength letters.
+func (m *MarkovModel) walk(r *rand.Rand) (string, bool) {
+	context := strings.Repeat(string(markovStart), m.order)
+	var name []byte
+	for len(name) <= markovMaxLength {
+		chain := m.chains[context]
+		pick := r.Float64() * chain.cumulative[len(chain.cumulative)-1]
+		next := chain.next[sort.SearchFloat64s(chain.cumulative, pick)]
+		if next == markovEnd {
+			return string(name), true
+		}
+		name = append(name, next)
+		context = context[1:] + string(next)
+	}
+	return "", false
+}
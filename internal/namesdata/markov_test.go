@@ -0,0 +1,62 @@
+package namesdata_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestMarkovModel(t *testing.T) {
+	if _, err := namesdata.NewMarkovModel(1); err == nil {
+		t.Fatal("expected an error for order 1")
+	}
+	model, err := namesdata.NewMarkovModel(2)
+	if err != nil {
+		t.Fatalf("NewMarkovModel: %v", err)
+	}
+	if _, err := model.Sample(rand.New(rand.NewSource(1))); err == nil {
+		t.Fatal("expected an error sampling an untrained model")
+	}
+
+	model.Train([]namesdata.NameCount{
+		{Name: "Olivia", Count: 400},
+		{Name: "Amelia", Count: 300},
+		{Name: "Liam", Count: 200},
+		{Name: "Mila", Count: 100},
+	})
+	if !model.Known("OLIVIA") || model.Known("Olia") {
+		t.Fatal("expected only training names to be known")
+	}
+
+	sample := func(seed int64) []string {
+		r := rand.New(rand.NewSource(seed))
+		var names []string
+		for range 20 {
+			name, err := model.Sample(r)
+			if err != nil {
+				t.Fatalf("Sample: %v", err)
+			}
+			names = append(names, name)
+		}
+		return names
+	}
+	names := sample(7)
+	for _, name := range names {
+		if model.Known(name) || len(name) < 2 || name != strings.ToUpper(name[:1])+strings.ToLower(name[1:]) {
+			t.Fatalf("expected a new capitalized name, got %q", name)
+		}
+	}
+	if again := sample(7); strings.Join(again, ",") != strings.Join(names, ",") {
+		t.Fatalf("expected the same names from the same seed, got %v and %v", names, again)
+	}
+
+	name, err := model.SampleFunc(rand.New(rand.NewSource(3)), func(name string) bool { return strings.HasPrefix(name, "A") })
+	if err != nil || !strings.HasPrefix(name, "A") {
+		t.Fatalf("expected a name starting with A, got %q (%v)", name, err)
+	}
+	if _, err := model.SampleFunc(rand.New(rand.NewSource(3)), func(string) bool { return false }); err == nil {
+		t.Fatal("expected an error when no name is acceptable")
+	}
+}
@@ -0,0 +1,251 @@
+package namesdata
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Merge layers several state datasets into one. Each state file in the
+// result holds the records of that state from every source, with counts
+// summed where two sources share a (gender, year, name) key, so a newer
+// year's delta files can be combined with an existing snapshot. A state
+// found in only one source is served straight from it; the others are
+// merged in memory the first time they are opened and kept for reuse.
+//
+// The result exposes only the state .TXT files, never a precomputed
+// index, since an index describes a single source. Errors reading a
+// source surface when the merged dataset is read.
+func Merge(fsys ...fs.FS) fs.FS {
+	return &mergedFS{sources: fsys, merged: make(map[string][]byte)}
+}
+
+// mergedFS is the fs.FS returned by Merge.
+type mergedFS struct {
+	sources []fs.FS
+
+	once sync.Once
+	// files maps each state code to the file backing it in every source
+	// that has it, or listErr is set if a source cannot be listed.
+	files   map[string][]mergeSource
+	names   []string
+	listErr error
+
+	mu     sync.Mutex
+	merged map[string][]byte
+}
+
+// mergeSource is one source's file for a state.
+type mergeSource struct {
+	fsys fs.FS
+	name string
+}
+
+func (m *mergedFS) list() error {
+	m.once.Do(func() {
+		m.files = make(map[string][]mergeSource)
+		for _, src := range m.sources {
+			states, files, err := stateFiles(src)
+			if err != nil {
+				m.listErr = err
+				return
+			}
+			for _, state := range states {
+				m.files[state] = append(m.files[state], mergeSource{fsys: src, name: files[state]})
+			}
+		}
+		for state := range m.files {
+			m.names = append(m.names, state+".TXT")
+		}
+		slices.Sort(m.names)
+	})
+	return m.listErr
+}
+
+// Open implements fs.FS.
+func (m *mergedFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := m.list(); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if name == "." {
+		return &mergedDir{fsys: m, entries: m.entries()}, nil
+	}
+
+	code, ok := strings.CutSuffix(strings.ToUpper(name), ".TXT")
+	sources := m.files[code]
+	if !ok || len(sources) == 0 || strings.Contains(name, "/") {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if len(sources) == 1 {
+		return sources[0].fsys.Open(sources[0].name)
+	}
+
+	data, err := m.mergeState(code, sources)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &mergedFile{Reader: bytes.NewReader(data), info: mergedInfo{name: code + ".TXT", size: int64(len(data))}}, nil
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (m *mergedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	if err := m.list(); err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return m.entries(), nil
+}
+
+func (m *mergedFS) entries() []fs.DirEntry {
+	entries := make([]fs.DirEntry, len(m.names))
+	for i, name := range m.names {
+		entries[i] = mergedEntry{fsys: m, name: name}
+	}
+	return entries
+}
+
+// mergeState sums the records for code across sources and formats them
+// in SSA order: gender, then year, then descending count.
+func (m *mergedFS) mergeState(code string, sources []mergeSource) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if data, ok := m.merged[code]; ok {
+		return data, nil
+	}
+
+	type key struct {
+		gender string
+		year   int
+		name   string
+	}
+	totals := make(map[key]*Record)
+	for _, src := range sources {
+		err := readRecordsFromFile(src.fsys, src.name, func(r Record) error {
+			k := key{gender: strings.ToUpper(r.Gender), year: r.Year, name: strings.ToUpper(r.Name)}
+			if entry, ok := totals[k]; ok {
+				entry.Count += r.Count
+				return nil
+			}
+			r.State, r.Gender = code, k.gender
+			totals[k] = &r
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	records := make([]*Record, 0, len(totals))
+	for _, r := range totals {
+		records = append(records, r)
+	}
+	slices.SortFunc(records, func(a, b *Record) int {
+		return cmp.Or(
+			cmp.Compare(a.Gender, b.Gender),
+			cmp.Compare(a.Year, b.Year),
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		buf.WriteString(r.State)
+		buf.WriteByte(',')
+		buf.WriteString(r.Gender)
+		buf.WriteByte(',')
+		buf.WriteString(strconv.Itoa(r.Year))
+		buf.WriteByte(',')
+		buf.WriteString(r.Name)
+		buf.WriteByte(',')
+		buf.WriteString(strconv.Itoa(r.Count))
+		buf.WriteByte('\n')
+	}
+	data := buf.Bytes()
+	m.merged[code] = data
+	return data, nil
+}
+
+// mergedFile is a state file merged in memory.
+type mergedFile struct {
+	*bytes.Reader
+	info mergedInfo
+}
+
+func (f *mergedFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *mergedFile) Close() error               { return nil }
+
+// mergedDir is the root directory of a merged dataset.
+type mergedDir struct {
+	fsys    *mergedFS
+	entries []fs.DirEntry
+}
+
+func (d *mergedDir) Stat() (fs.FileInfo, error) { return mergedInfo{name: ".", dir: true}, nil }
+func (d *mergedDir) Close() error               { return nil }
+
+func (d *mergedDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: fmt.Errorf("is a directory")}
+}
+
+func (d *mergedDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// mergedEntry lists a state file; its size is only known once the file is
+// opened.
+type mergedEntry struct {
+	fsys *mergedFS
+	name string
+}
+
+func (e mergedEntry) Name() string      { return e.name }
+func (e mergedEntry) IsDir() bool       { return false }
+func (e mergedEntry) Type() fs.FileMode { return 0 }
+
+func (e mergedEntry) Info() (fs.FileInfo, error) {
+	return fs.Stat(e.fsys, e.name)
+}
+
+// mergedInfo describes a merged file or the root directory.
+type mergedInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i mergedInfo) Name() string       { return i.name }
+func (i mergedInfo) Size() int64        { return i.size }
+func (i mergedInfo) ModTime() time.Time { return time.Time{} }
+func (i mergedInfo) IsDir() bool        { return i.dir }
+func (i mergedInfo) Sys() any           { return nil }
+
+func (i mergedInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
@@ -0,0 +1,61 @@
+package namesdata_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestMerge(t *testing.T) {
+	delta := fstest.MapFS{
+		"ca.txt": {Data: []byte("CA,F,2020,Olivia,120\nCA,F,2019,emma,10\nCA,M,2020,Liam,99\n")},
+		"TX.TXT": {Data: []byte("TX,F,2020,Mia,70\n")},
+	}
+	merged := namesdata.Merge(sampleFS(), delta)
+
+	states, err := namesdata.States(merged)
+	if err != nil {
+		t.Fatalf("States: %v", err)
+	}
+	if strings.Join(states, ",") != "CA,NY,TX" {
+		t.Fatalf("unexpected states: %v", states)
+	}
+
+	// CA is in both sources, so it is merged and rewritten in SSA order.
+	data, err := fs.ReadFile(merged, "CA.TXT")
+	if err != nil {
+		t.Fatalf("read CA.TXT: %v", err)
+	}
+	want := "CA,F,2018,Olivia,80\n" +
+		"CA,F,2018,Emma,50\n" +
+		"CA,F,2019,Olivia,140\n" +
+		"CA,F,2019,Emma,100\n" +
+		"CA,F,2020,Olivia,120\n" +
+		"CA,M,2018,Liam,85\n" +
+		"CA,M,2019,Liam,95\n" +
+		"CA,M,2019,Noah,70\n" +
+		"CA,M,2020,Liam,99\n"
+	if string(data) != want {
+		t.Fatalf("unexpected merged file:\n%s\nwant:\n%s", data, want)
+	}
+
+	names, total, err := namesdata.AggregateFromFS(merged, "", 2020, "F")
+	if err != nil {
+		t.Fatalf("AggregateFromFS: %v", err)
+	}
+	if total != 190 || len(names) != 2 || names[0].Name != "Olivia" {
+		t.Fatalf("unexpected 2020 aggregate: %d %+v", total, names)
+	}
+
+	if err := fstest.TestFS(merged, "CA.TXT", "NY.TXT", "TX.TXT"); err != nil {
+		t.Fatalf("TestFS: %v", err)
+	}
+
+	bad := namesdata.Merge(sampleFS(), fstest.MapFS{"CA.TXT": {Data: []byte("oops\n")}})
+	if _, err := fs.ReadFile(bad, "CA.TXT"); err == nil || !strings.Contains(err.Error(), "malformed line") {
+		t.Fatalf("expected a malformed line error, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package namesdata
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// RankMove describes how a name's rank changed between two periods. FromRank
+// is zero for a name absent from the first period (a new entrant) and ToRank
+// is zero for a name absent from the second (a dropout).
+type RankMove struct {
+	Name      string
+	FromRank  int
+	FromCount int
+	ToRank    int
+	ToCount   int
+}
+
+// Change returns the number of places the name climbed, negative when it
+// fell. It is zero for entrants and dropouts.
+func (m RankMove) Change() int {
+	if m.FromRank == 0 || m.ToRank == 0 {
+		return 0
+	}
+	return m.FromRank - m.ToRank
+}
+
+// Entrant reports whether the name only appears in the second period.
+func (m RankMove) Entrant() bool {
+	return m.FromRank == 0 && m.ToRank > 0
+}
+
+// Dropout reports whether the name only appears in the first period.
+func (m RankMove) Dropout() bool {
+	return m.FromRank > 0 && m.ToRank == 0
+}
+
+// RankDiff ranks names by their total count within each period and returns
+// one RankMove for every name present in either, ordered by the second
+// period's rank with dropouts last by their first-period rank. Both periods
+// must be bounded; gender can be "M", "F", or empty for all.
+func RankDiff(records []Record, gender string, from, to YearRange) ([]RankMove, error) {
+	for _, span := range []YearRange{from, to} {
+		if err := span.validate(); err != nil {
+			return nil, err
+		}
+		if span.From == 0 || span.To == 0 {
+			return nil, errors.New("rank periods must have both a first and last year")
+		}
+	}
+
+	before, beforeRanks := aggregateSpan(records, gender, from)
+	after, afterRanks := aggregateSpan(records, gender, to)
+	if len(before) == 0 && len(after) == 0 {
+		return nil, ErrNoMatches
+	}
+
+	moves := make([]RankMove, 0, len(after)+len(before))
+	for i, entry := range after {
+		move := RankMove{Name: entry.Name, ToRank: i + 1, ToCount: entry.Count}
+		if rank, ok := beforeRanks[strings.ToUpper(entry.Name)]; ok {
+			move.FromRank = rank
+			move.FromCount = before[rank-1].Count
+		}
+		moves = append(moves, move)
+	}
+	for i, entry := range before {
+		if _, ok := afterRanks[strings.ToUpper(entry.Name)]; ok {
+			continue
+		}
+		moves = append(moves, RankMove{Name: entry.Name, FromRank: i + 1, FromCount: entry.Count})
+	}
+	return moves, nil
+}
+
+// aggregateSpan is AggregateNames over every year in span.
+func aggregateSpan(records []Record, gender string, span YearRange) ([]NameCount, map[string]int) {
+	inSpan := make([]Record, 0, len(records))
+	for _, r := range records {
+		if span.Contains(r.Year) {
+			inSpan = append(inSpan, r)
+		}
+	}
+	return AggregateNames(inSpan, 0, gender)
+}
+
+// Movers groups rank moves into the biggest gainers and losers, new
+// entrants, and dropouts.
+type Movers struct {
+	Gainers  []RankMove
+	Losers   []RankMove
+	Entrants []RankMove
+	Dropouts []RankMove
+}
+
+// SelectMovers picks up to limit moves for each group (0 for no limit). Only
+// names ranked within the top `within` of either period are considered
+// (0 for every name), which keeps rare names, whose ranks swing by
+// thousands of places, from crowding out popular ones. Gainers and losers
+// are ordered by the size of the move, entrants by their new rank, and
+// dropouts by their old rank.
+func SelectMovers(moves []RankMove, within, limit int) Movers {
+	inScope := func(rank int) bool {
+		return rank > 0 && (within == 0 || rank <= within)
+	}
+
+	var result Movers
+	for _, m := range moves {
+		if !inScope(m.FromRank) && !inScope(m.ToRank) {
+			continue
+		}
+		switch {
+		case m.Entrant():
+			result.Entrants = append(result.Entrants, m)
+		case m.Dropout():
+			result.Dropouts = append(result.Dropouts, m)
+		case m.Change() > 0:
+			result.Gainers = append(result.Gainers, m)
+		case m.Change() < 0:
+			result.Losers = append(result.Losers, m)
+		}
+	}
+
+	sort.SliceStable(result.Gainers, func(i, j int) bool {
+		a, b := result.Gainers[i], result.Gainers[j]
+		if a.Change() != b.Change() {
+			return a.Change() > b.Change()
+		}
+		return a.ToRank < b.ToRank
+	})
+	sort.SliceStable(result.Losers, func(i, j int) bool {
+		a, b := result.Losers[i], result.Losers[j]
+		if a.Change() != b.Change() {
+			return a.Change() < b.Change()
+		}
+		return a.FromRank < b.FromRank
+	})
+	sort.SliceStable(result.Entrants, func(i, j int) bool {
+		return result.Entrants[i].ToRank < result.Entrants[j].ToRank
+	})
+	sort.SliceStable(result.Dropouts, func(i, j int) bool {
+		return result.Dropouts[i].FromRank < result.Dropouts[j].FromRank
+	})
+
+	if limit > 0 {
+		result.Gainers = truncateMoves(result.Gainers, limit)
+		result.Losers = truncateMoves(result.Losers, limit)
+		result.Entrants = truncateMoves(result.Entrants, limit)
+		result.Dropouts = truncateMoves(result.Dropouts, limit)
+	}
+	return result
+}
+
+func truncateMoves(moves []RankMove, limit int) []RankMove {
+	if len(moves) > limit {
+		return moves[:limit]
+	}
+	return moves
+}
@@ -0,0 +1,59 @@
+package namesdata_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestRankDiffAndSelectMovers(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 2000, Name: "Ava", Count: 10},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Mia", Count: 30},
+		{State: "CA", Gender: "F", Year: 2001, Name: "Mia", Count: 20},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Ruth", Count: 40},
+		{State: "CA", Gender: "F", Year: 2010, Name: "Ava", Count: 50},
+		{State: "CA", Gender: "F", Year: 2010, Name: "Mia", Count: 20},
+		{State: "CA", Gender: "F", Year: 2010, Name: "Luna", Count: 30},
+	}
+
+	moves, err := namesdata.RankDiff(records, "F", namesdata.YearRange{From: 2000, To: 2001}, namesdata.YearRange{From: 2010, To: 2010})
+	if err != nil {
+		t.Fatalf("RankDiff: %v", err)
+	}
+	// 2000-2001: Mia 50, Ruth 40, Ava 10. 2010: Ava 50, Luna 30, Mia 20.
+	if len(moves) != 4 {
+		t.Fatalf("expected 4 moves, got %+v", moves)
+	}
+	if ava := moves[0]; ava.Name != "Ava" || ava.FromRank != 3 || ava.ToRank != 1 || ava.Change() != 2 {
+		t.Fatalf("unexpected Ava move: %+v", ava)
+	}
+	if ruth := moves[3]; !ruth.Dropout() || ruth.FromCount != 40 || ruth.Change() != 0 {
+		t.Fatalf("unexpected Ruth move: %+v", ruth)
+	}
+
+	movers := namesdata.SelectMovers(moves, 0, 0)
+	if len(movers.Gainers) != 1 || movers.Gainers[0].Name != "Ava" {
+		t.Fatalf("unexpected gainers: %+v", movers.Gainers)
+	}
+	if len(movers.Losers) != 1 || movers.Losers[0].Name != "Mia" || movers.Losers[0].Change() != -2 {
+		t.Fatalf("unexpected losers: %+v", movers.Losers)
+	}
+	if len(movers.Entrants) != 1 || movers.Entrants[0].Name != "Luna" || !movers.Entrants[0].Entrant() {
+		t.Fatalf("unexpected entrants: %+v", movers.Entrants)
+	}
+	if len(movers.Dropouts) != 1 || movers.Dropouts[0].Name != "Ruth" {
+		t.Fatalf("unexpected dropouts: %+v", movers.Dropouts)
+	}
+
+	// Within the top 1, Ava (3 -> 1) and Mia (1 -> 3) remain; Luna and Ruth
+	// never ranked first.
+	narrow := namesdata.SelectMovers(moves, 1, 0)
+	if len(narrow.Gainers) != 1 || len(narrow.Losers) != 1 || len(narrow.Entrants) != 0 || len(narrow.Dropouts) != 0 {
+		t.Fatalf("unexpected movers within the top 1: %+v", narrow)
+	}
+
+	if _, err := namesdata.RankDiff(records, "F", namesdata.YearRange{}, namesdata.YearRange{From: 2010, To: 2010}); err == nil {
+		t.Fatalf("expected error for an unbounded period")
+	}
+}
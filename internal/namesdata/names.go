@@ -2,9 +2,14 @@ package namesdata
 
 import (
 	"bufio"
+	"container/heap"
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"iter"
+	"math"
+	"math/bits"
 	"math/rand"
 	"sort"
 	"strconv"
@@ -12,6 +17,9 @@ import (
 	"time"
 )
 
+// ErrNoMatches is returned when no records satisfy the provided filters.
+var ErrNoMatches = errors.New("no matching records for the provided filters")
+
 // Record represents a single row from the names by state dataset.
 type Record struct {
 	State  string
@@ -25,6 +33,18 @@ type Record struct {
 type NameCount struct {
 	Name  string
 	Count int
+	// Weight is the name's sampling weight when it differs from Count, as
+	// in a recency-weighted aggregate. Zero means Count.
+	Weight float64 `json:",omitempty"`
+}
+
+// SamplingWeight returns the weight samplers draw the name with: Weight
+// when set, and Count otherwise.
+func (n NameCount) SamplingWeight() float64 {
+	if n.Weight != 0 {
+		return n.Weight
+	}
+	return float64(n.Count)
 }
 
 // TrendPoint captures the rank and count for a name in a specific year.
@@ -33,65 +53,89 @@ type TrendPoint struct {
 	Rank    int
 	Count   int
 	Present bool
+	// Total is the number of births that year in the population the rank
+	// was computed over, used as the denominator for shares.
+	Total int
+	// Forecast marks a point projected by Forecast rather than observed.
+	Forecast bool
 }
 
 // TrendSeries contains a chronologically ordered slice of TrendPoints for a name.
 type TrendSeries struct {
-	Name   string
+	Name string
+	// Gender is set when the series covers a single gender split out of a
+	// mixed-gender trend, and is empty otherwise.
+	Gender string
 	Points []TrendPoint
 }
 
+// Label returns the series name, suffixed with its gender when the series was
+// split by gender (e.g. "Riley (F)").
+func (s TrendSeries) Label() string {
+	if s.Gender == "" {
+		return s.Name
+	}
+	return fmt.Sprintf("%s (%s)", s.Name, s.Gender)
+}
+
 // LoadStateRecords loads all records for the given state abbreviation (e.g. "CA")
 // from the provided filesystem.
 func LoadStateRecords(fsys fs.FS, state string) ([]Record, error) {
-	if state == "" {
+	return LoadStateRecordsContext(context.Background(), fsys, state)
+}
+
+// LoadStateRecordsContext is LoadStateRecords, stopping with ctx's error
+// once ctx is done.
+func LoadStateRecordsContext(ctx context.Context, fsys fs.FS, state string) ([]Record, error) {
+	if strings.TrimSpace(state) == "" {
 		return nil, errors.New("state is required")
 	}
 
-	fileName := strings.ToUpper(state) + ".TXT"
-	return loadRecordsFromFile(fsys, fileName)
-}
-
-// LoadAllRecords loads every state's records from the filesystem.
-func LoadAllRecords(fsys fs.FS) ([]Record, error) {
-	entries, err := fs.ReadDir(fsys, ".")
+	fileName, err := stateFileName(fsys, state)
 	if err != nil {
-		return nil, fmt.Errorf("read dataset directory: %w", err)
+		return nil, err
 	}
 
-	records := make([]Record, 0, 1024)
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		if !strings.HasSuffix(strings.ToUpper(name), ".TXT") {
-			continue
-		}
-
-		if err := readRecordsFromFile(fsys, name, func(r Record) error {
-			records = append(records, r)
-			return nil
-		}); err != nil {
-			return nil, err
-		}
+	idx, err := openIndex(fsys)
+	if err != nil {
+		return nil, err
 	}
-
-	if len(records) == 0 {
-		return nil, errors.New("no records found in dataset")
+	if idx != nil {
+		return collectRecords(walkContext(ctx, func(fn func(Record) error) error {
+			return walkIndex(idx, strings.TrimSpace(state), fn)
+		}))
 	}
+	return collectRecords(walkContext(ctx, func(fn func(Record) error) error {
+		return readRecordsFromFile(fsys, fileName, fn)
+	}))
+}
 
-	return records, nil
+// LoadAllRecords loads every state's records from the filesystem.
+func LoadAllRecords(fsys fs.FS) ([]Record, error) {
+	return LoadAllRecordsContext(context.Background(), fsys)
 }
 
-func loadRecordsFromFile(fsys fs.FS, fileName string) ([]Record, error) {
+// LoadAllRecordsContext is LoadAllRecords, stopping with ctx's error once
+// ctx is done.
+func LoadAllRecordsContext(ctx context.Context, fsys fs.FS) ([]Record, error) {
+	return collectRecords(walkContext(ctx, func(fn func(Record) error) error {
+		return walkRecords(fsys, "", fn)
+	}))
+}
+
+// collectRecords gathers every record produced by walk into a slice,
+// interning their strings.
+func collectRecords(walk func(func(Record) error) error) ([]Record, error) {
 	records := make([]Record, 0, 1024)
-	if err := readRecordsFromFile(fsys, fileName, func(r Record) error {
+	if err := walk(internRecords(func(r Record) error {
 		records = append(records, r)
 		return nil
-	}); err != nil {
+	})); err != nil {
 		return nil, err
 	}
+	if len(records) == 0 {
+		return nil, errors.New("no records found in dataset")
+	}
 
 	return records, nil
 }
@@ -112,27 +156,9 @@ func readRecordsFromFile(fsys fs.FS, fileName string, fn func(Record) error) err
 			continue
 		}
 
-		parts := strings.Split(line, ",")
-		if len(parts) != 5 {
-			return fmt.Errorf("malformed line in %s: %q", fileName, line)
-		}
-
-		year, err := strconv.Atoi(parts[2])
+		record, err := parseRecordLine(fileName, line)
 		if err != nil {
-			return fmt.Errorf("parse year %q in %s: %w", parts[2], fileName, err)
-		}
-
-		count, err := strconv.Atoi(parts[4])
-		if err != nil {
-			return fmt.Errorf("parse count %q in %s: %w", parts[4], fileName, err)
-		}
-
-		record := Record{
-			State:  parts[0],
-			Gender: parts[1],
-			Year:   year,
-			Name:   parts[3],
-			Count:  count,
+			return err
 		}
 
 		if err := fn(record); err != nil {
@@ -152,6 +178,32 @@ func readRecordsFromFile(fsys fs.FS, fileName string, fn func(Record) error) err
 	return nil
 }
 
+// parseRecordLine parses one "STATE,G,YEAR,Name,Count" line from fileName.
+func parseRecordLine(fileName, line string) (Record, error) {
+	parts := strings.Split(line, ",")
+	if len(parts) != 5 {
+		return Record{}, fmt.Errorf("malformed line in %s: %q", fileName, line)
+	}
+
+	year, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return Record{}, fmt.Errorf("parse year %q in %s: %w", parts[2], fileName, err)
+	}
+
+	count, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return Record{}, fmt.Errorf("parse count %q in %s: %w", parts[4], fileName, err)
+	}
+
+	return Record{
+		State:  parts[0],
+		Gender: parts[1],
+		Year:   year,
+		Name:   parts[3],
+		Count:  count,
+	}, nil
+}
+
 // AggregateNames filters the provided records and returns a sorted list of
 // totals along with a lookup map for 1-based rank by name (case-insensitive).
 // year == 0 means all years. gender can be "M", "F", or empty for all.
@@ -181,12 +233,7 @@ func AggregateNames(records []Record, year int, gender string) ([]NameCount, map
 		aggregated = append(aggregated, *entry)
 	}
 
-	sort.Slice(aggregated, func(i, j int) bool {
-		if aggregated[i].Count == aggregated[j].Count {
-			return aggregated[i].Name < aggregated[j].Name
-		}
-		return aggregated[i].Count > aggregated[j].Count
-	})
+	sortNameCounts(aggregated)
 
 	ranks := make(map[string]int, len(aggregated))
 	for idx, entry := range aggregated {
@@ -217,25 +264,75 @@ func Rank(records []Record, year int, gender, name string) (int, NameCount, erro
 
 // RankFromAggregate is a helper that returns rank information from precomputed
 // aggregates. aggregated should be in descending order of popularity, and
-// ranks must contain 1-based positions keyed by upper-cased name.
+// ranks must contain 1-based positions keyed by upper-cased name. A name that
+// is missing yields a *NameNotFoundError suggesting the closest names.
 func RankFromAggregate(aggregated []NameCount, ranks map[string]int, name string) (int, NameCount, error) {
 	if strings.TrimSpace(name) == "" {
 		return 0, NameCount{}, errors.New("name is required")
 	}
 
 	if len(aggregated) == 0 {
-		return 0, NameCount{}, errors.New("no matching records for the provided filters")
+		return 0, NameCount{}, ErrNoMatches
 	}
 
 	target := strings.ToUpper(name)
 	rank, ok := ranks[target]
 	if !ok {
-		return 0, NameCount{}, fmt.Errorf("name %q not found for the provided filters", name)
+		return 0, NameCount{}, newNameNotFoundError(name, aggregated)
 	}
 
 	return rank, aggregated[rank-1], nil
 }
 
+// NameRank is the result of looking up one name with RankMany. Rank is 0
+// and Entry empty when the name is not in the data.
+type NameRank struct {
+	// Query is the name as it was asked for.
+	Query string
+	Rank  int
+	Entry NameCount
+}
+
+// Found reports whether the queried name is in the data.
+func (r NameRank) Found() bool {
+	return r.Rank > 0
+}
+
+// RankMany computes the 1-based ranks of several names within the provided
+// filters from a single aggregation, returning one NameRank per name in the
+// order given.
+func RankMany(records []Record, year int, gender string, names []string) ([]NameRank, error) {
+	aggregated, ranks := AggregateNames(records, year, gender)
+	return RankManyFromAggregate(aggregated, ranks, names)
+}
+
+// RankManyFromAggregate is RankFromAggregate for several names. Names that
+// are missing are returned unfound rather than as an error, so one typo
+// does not lose the rest; pass them to RankFromAggregate for suggestions.
+func RankManyFromAggregate(aggregated []NameCount, ranks map[string]int, names []string) ([]NameRank, error) {
+	if len(names) == 0 {
+		return nil, errors.New("at least one name is required")
+	}
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return nil, errors.New("name is required")
+		}
+	}
+	if len(aggregated) == 0 {
+		return nil, ErrNoMatches
+	}
+
+	result := make([]NameRank, len(names))
+	for i, name := range names {
+		result[i] = NameRank{Query: name}
+		if rank, ok := ranks[strings.ToUpper(strings.TrimSpace(name))]; ok {
+			result[i].Rank = rank
+			result[i].Entry = aggregated[rank-1]
+		}
+	}
+	return result, nil
+}
+
 // RandomName selects a name from the filtered records using the aggregated
 // counts as weights for a probability distribution. When r is nil a new
 // time-seeded RNG is used.
@@ -246,9 +343,11 @@ func RandomName(records []Record, year int, gender string, r *rand.Rand) (NameCo
 
 // RandomNameFromAggregate returns a weighted random name from the aggregated
 // list. The probability of each name is proportional to its Count value.
+// Being a single draw, it walks the cumulative distribution directly rather
+// than building any sampler tables.
 func RandomNameFromAggregate(aggregated []NameCount, r *rand.Rand) (NameCount, error) {
 	if len(aggregated) == 0 {
-		return NameCount{}, errors.New("no matching records for the provided filters")
+		return NameCount{}, ErrNoMatches
 	}
 
 	total := 0
@@ -262,36 +361,202 @@ func RandomNameFromAggregate(aggregated []NameCount, r *rand.Rand) (NameCount, e
 	return RandomNameFromAggregateWithTotal(aggregated, total, r)
 }
 
+// SamplerStrategy selects how a NameSampler turns weights into picks.
+type SamplerStrategy int
+
+const (
+	// SamplerAuto picks the strategy with the lower total cost for the
+	// expected number of draws.
+	SamplerAuto SamplerStrategy = iota
+	// SamplerAlias builds a Walker alias table: slower to construct, but
+	// every pick is O(1). Best when the sampler is reused many times.
+	SamplerAlias
+	// SamplerCDF builds a prefix-sum array and binary searches it per pick:
+	// cheap to construct, O(log n) per pick. Best for one-off or few draws.
+	SamplerCDF
+)
+
+// String returns the strategy name.
+func (s SamplerStrategy) String() string {
+	switch s {
+	case SamplerAlias:
+		return "alias"
+	case SamplerCDF:
+		return "cdf"
+	default:
+		return "auto"
+	}
+}
+
 // NameSampler precomputes probability tables for repeated random selections.
 type NameSampler struct {
 	entries []NameCount
 	prob    []float64
 	alias   []int
+	cdf     []int
 	total   int
+	// weights replace the counts as sampling weights in a tempered sampler,
+	// with weightCDF and weightTotal in place of cdf and total; see
+	// NewTemperedNameSampler.
+	weights     []float64
+	weightCDF   []float64
+	weightTotal float64
+	temperature float64
+	maxWeight   float64
 }
 
-// NewNameSampler builds a sampler from aggregated name counts.
+// NewNameSampler builds a sampler from aggregated name counts. The number of
+// draws is unknown, so the sampler is assumed to be reused and uses an alias
+// table; see NewNameSamplerWithStrategy when the draw count is known.
 func NewNameSampler(aggregated []NameCount) (*NameSampler, error) {
+	return NewNameSamplerWithStrategy(aggregated, SamplerAuto, 0)
+}
+
+// NewNameSamplerWithStrategy builds a sampler using the given strategy. With
+// SamplerAuto, expectedDraws decides between the two: a cumulative
+// distribution is used when the binary searches for that many draws cost
+// less than building the alias table, and an alias table otherwise. An
+// expectedDraws of zero means unknown and selects the alias table.
+func NewNameSamplerWithStrategy(aggregated []NameCount, strategy SamplerStrategy, expectedDraws int) (*NameSampler, error) {
+	return NewTemperedNameSampler(aggregated, 1, strategy, expectedDraws)
+}
+
+// NewTemperedNameSampler builds a sampler that draws each name in
+// proportion to its count raised to temperature rather than to the count
+// itself. A temperature below 1 flattens the distribution toward rarer
+// names, 0 makes every name with a positive count equally likely, and
+// above 1 concentrates the draws on the most popular names. A temperature
+// of 1 is NewNameSamplerWithStrategy.
+func NewTemperedNameSampler(aggregated []NameCount, temperature float64, strategy SamplerStrategy, expectedDraws int) (*NameSampler, error) {
+	if temperature < 0 || math.IsNaN(temperature) || math.IsInf(temperature, 0) {
+		return nil, fmt.Errorf("temperature must be a finite number 0 or greater, got %g", temperature)
+	}
 	if len(aggregated) == 0 {
-		return nil, errors.New("no matching records for the provided filters")
+		return nil, ErrNoMatches
 	}
 
 	entries := make([]NameCount, len(aggregated))
 	copy(entries, aggregated)
 
 	total := 0
+	weighted := false
 	for _, entry := range aggregated {
-		if entry.Count < 0 {
+		if entry.Count < 0 || entry.Weight < 0 {
 			return nil, fmt.Errorf("negative count for %q", entry.Name)
 		}
 		total += entry.Count
+		weighted = weighted || entry.Weight != 0
 	}
 
 	if total == 0 {
 		return nil, errors.New("no probability mass available")
 	}
 
-	n := len(aggregated)
+	if strategy == SamplerAuto {
+		strategy = chooseSamplerStrategy(len(entries), expectedDraws)
+	}
+
+	sampler := &NameSampler{entries: entries, total: total, temperature: 1}
+	if temperature == 1 && !weighted {
+		if strategy == SamplerCDF {
+			sampler.cdf = buildCDF(entries)
+		} else {
+			weights := make([]float64, len(entries))
+			for i, entry := range entries {
+				weights[i] = float64(entry.Count)
+			}
+			sampler.prob, sampler.alias = buildAliasTable(weights, float64(total))
+		}
+		return sampler, nil
+	}
+
+	// Weights are scaled by the largest before raising them to the
+	// temperature, so high temperatures cannot overflow.
+	sampler.temperature = temperature
+	for _, entry := range entries {
+		sampler.maxWeight = max(sampler.maxWeight, entry.SamplingWeight())
+	}
+	sampler.weights = make([]float64, len(entries))
+	for i, entry := range entries {
+		sampler.weights[i] = sampler.temperedWeight(entry)
+		sampler.weightTotal += sampler.weights[i]
+	}
+	if sampler.weightTotal == 0 {
+		return nil, errors.New("no probability mass available")
+	}
+	if strategy == SamplerCDF {
+		sampler.weightCDF = make([]float64, len(entries))
+		running := 0.0
+		for i, weight := range sampler.weights {
+			running += weight
+			sampler.weightCDF[i] = running
+		}
+	} else {
+		sampler.prob, sampler.alias = buildAliasTable(sampler.weights, sampler.weightTotal)
+	}
+	return sampler, nil
+}
+
+// temperedWeight returns the sampling weight of entry in a sampler with
+// float weights. A zero weight stays zero at every temperature.
+func (s *NameSampler) temperedWeight(entry NameCount) float64 {
+	weight := entry.SamplingWeight()
+	if weight <= 0 {
+		return 0
+	}
+	return math.Pow(weight/s.maxWeight, s.temperature)
+}
+
+// Temperature reports the exponent applied to counts, 1 for an untempered
+// sampler.
+func (s *NameSampler) Temperature() float64 {
+	if s == nil {
+		return 1
+	}
+	return s.temperature
+}
+
+// Chance returns the probability that a single Pick draws entry, one of the
+// sampler's names, judged by its sampling weight.
+func (s *NameSampler) Chance(entry NameCount) float64 {
+	if s == nil || len(s.entries) == 0 {
+		return 0
+	}
+	if s.weights == nil {
+		return float64(entry.Count) / float64(s.total)
+	}
+	return s.temperedWeight(entry) / s.weightTotal
+}
+
+// chooseSamplerStrategy compares the O(draws·log n) search cost of a
+// cumulative distribution with the O(n) extra setup of an alias table.
+func chooseSamplerStrategy(n, expectedDraws int) SamplerStrategy {
+	if expectedDraws > 0 && expectedDraws*bits.Len(uint(n)) <= n {
+		return SamplerCDF
+	}
+	return SamplerAlias
+}
+
+// Strategy reports which strategy the sampler was built with.
+func (s *NameSampler) Strategy() SamplerStrategy {
+	if s != nil && (s.cdf != nil || s.weightCDF != nil) {
+		return SamplerCDF
+	}
+	return SamplerAlias
+}
+
+func buildCDF(entries []NameCount) []int {
+	cdf := make([]int, len(entries))
+	running := 0
+	for i, entry := range entries {
+		running += entry.Count
+		cdf[i] = running
+	}
+	return cdf
+}
+
+func buildAliasTable(weights []float64, total float64) ([]float64, []int) {
+	n := len(weights)
 	prob := make([]float64, n)
 	alias := make([]int, n)
 	scaled := make([]float64, n)
@@ -299,9 +564,9 @@ func NewNameSampler(aggregated []NameCount) (*NameSampler, error) {
 	small := make([]int, 0, n)
 	large := make([]int, 0, n)
 
-	for i, entry := range aggregated {
+	for i, weight := range weights {
 		alias[i] = i
-		scaled[i] = float64(entry.Count) * float64(n) / float64(total)
+		scaled[i] = weight * float64(n) / total
 		if scaled[i] < 1.0 {
 			small = append(small, i)
 		} else {
@@ -333,13 +598,13 @@ func NewNameSampler(aggregated []NameCount) (*NameSampler, error) {
 		prob[idx] = 1
 	}
 
-	return &NameSampler{entries: entries, prob: prob, alias: alias, total: total}, nil
+	return prob, alias
 }
 
 // Pick returns a random NameCount using the sampler's precomputed weights.
 func (s *NameSampler) Pick(r *rand.Rand) (NameCount, error) {
 	if s == nil || len(s.entries) == 0 {
-		return NameCount{}, errors.New("no matching records for the provided filters")
+		return NameCount{}, ErrNoMatches
 	}
 
 	rng := r
@@ -347,6 +612,17 @@ func (s *NameSampler) Pick(r *rand.Rand) (NameCount, error) {
 		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
 	}
 
+	if s.cdf != nil {
+		pick := rng.Intn(s.total)
+		idx := sort.Search(len(s.cdf), func(i int) bool { return s.cdf[i] > pick })
+		return s.entries[idx], nil
+	}
+	if s.weightCDF != nil {
+		pick := rng.Float64() * s.weightTotal
+		idx := sort.Search(len(s.weightCDF), func(i int) bool { return s.weightCDF[i] > pick })
+		return s.entries[min(idx, len(s.entries)-1)], nil
+	}
+
 	idx := rng.Intn(len(s.entries))
 	if len(s.prob) == 0 || len(s.alias) == 0 {
 		return s.entries[idx], nil
@@ -359,11 +635,97 @@ func (s *NameSampler) Pick(r *rand.Rand) (NameCount, error) {
 	return s.entries[s.alias[idx]], nil
 }
 
+// PickUnique draws n distinct names, each draw weighted by count (or by
+// tempered weight) among the names not yet drawn, and returns them in draw
+// order. It assigns every name an exponential key -ln(U)/weight and keeps
+// the n smallest (Efraimidis-Spirakis), so it needs a single pass and no
+// retries however skewed the weights are. Names with a zero count are never
+// drawn. An error is returned
+// when fewer than n names can be drawn.
+func (s *NameSampler) PickUnique(n int, r *rand.Rand) ([]NameCount, error) {
+	if s == nil || len(s.entries) == 0 {
+		return nil, ErrNoMatches
+	}
+	if n < 0 {
+		return nil, errors.New("number of names must not be negative")
+	}
+
+	rng := r
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	available := 0
+	for i := range s.entries {
+		if s.weight(i) > 0 {
+			available++
+		}
+	}
+	if n > available {
+		return nil, fmt.Errorf("cannot draw %d distinct names: only %d match", n, available)
+	}
+	if n == 0 {
+		return []NameCount{}, nil
+	}
+
+	// keep is a max-heap on key holding the n smallest keys seen so far.
+	keep := make(keyedHeap, 0, n)
+	for i := range s.entries {
+		weight := s.weight(i)
+		if weight <= 0 {
+			continue
+		}
+		key := rng.ExpFloat64() / weight
+		if len(keep) < n {
+			heap.Push(&keep, keyedIndex{key: key, index: i})
+			continue
+		}
+		if key < keep[0].key {
+			keep[0] = keyedIndex{key: key, index: i}
+			heap.Fix(&keep, 0)
+		}
+	}
+
+	sort.Slice(keep, func(i, j int) bool { return keep[i].key < keep[j].key })
+	picks := make([]NameCount, len(keep))
+	for i, k := range keep {
+		picks[i] = s.entries[k.index]
+	}
+	return picks, nil
+}
+
+// weight returns the sampling weight of the entry at index i.
+func (s *NameSampler) weight(i int) float64 {
+	if s.weights != nil {
+		return s.weights[i]
+	}
+	return float64(s.entries[i].Count)
+}
+
+type keyedIndex struct {
+	key   float64
+	index int
+}
+
+// keyedHeap is a max-heap of keyedIndex ordered by key.
+type keyedHeap []keyedIndex
+
+func (h keyedHeap) Len() int           { return len(h) }
+func (h keyedHeap) Less(i, j int) bool { return h[i].key > h[j].key }
+func (h keyedHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *keyedHeap) Push(x any)        { *h = append(*h, x.(keyedIndex)) }
+func (h *keyedHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
 // RandomNameFromAggregateWithTotal selects a random name using the provided
 // total count, avoiding recomputing the sum when it is already known.
 func RandomNameFromAggregateWithTotal(aggregated []NameCount, total int, r *rand.Rand) (NameCount, error) {
 	if len(aggregated) == 0 {
-		return NameCount{}, errors.New("no matching records for the provided filters")
+		return NameCount{}, ErrNoMatches
 	}
 
 	if total <= 0 {
@@ -389,8 +751,24 @@ func RandomNameFromAggregateWithTotal(aggregated []NameCount, total int, r *rand
 
 func walkRecords(fsys fs.FS, state string, fn func(Record) error) error {
 	state = strings.TrimSpace(state)
+	var fileName string
+	if state != "" {
+		var err error
+		fileName, err = stateFileName(fsys, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	idx, err := openIndex(fsys)
+	if err != nil {
+		return err
+	}
+	if idx != nil {
+		return walkIndex(idx, state, fn)
+	}
+
 	if state != "" {
-		fileName := strings.ToUpper(state) + ".TXT"
 		return readRecordsFromFile(fsys, fileName, fn)
 	}
 
@@ -425,7 +803,10 @@ func walkRecords(fsys fs.FS, state string, fn func(Record) error) error {
 
 // RandomNameFromFS selects a random name directly from the dataset without
 // materializing all records. It returns the aggregated count for the selected
-// name and the total matches for the provided filters.
+// name and the total matches for the provided filters. The dataset is read
+// once: each matching record replaces the candidate with probability
+// count/total-so-far, which picks names in proportion to their aggregated
+// counts, while a running count per name supplies the chosen name's total.
 func RandomNameFromFS(fsys fs.FS, state string, year int, gender string, r *rand.Rand) (NameCount, int, error) {
 	genderFilter := strings.ToUpper(strings.TrimSpace(gender))
 	rng := r
@@ -436,6 +817,7 @@ func RandomNameFromFS(fsys fs.FS, state string, year int, gender string, r *rand
 	total := 0
 	var candidate string
 	chosen := false
+	counts := make(map[string]int)
 
 	err := walkRecords(fsys, state, func(rec Record) error {
 		if year != 0 && rec.Year != year {
@@ -452,6 +834,7 @@ func RandomNameFromFS(fsys fs.FS, state string, year int, gender string, r *rand
 		if total <= 0 {
 			return nil
 		}
+		counts[strings.ToUpper(rec.Name)] += rec.Count
 
 		if !chosen {
 			candidate = rec.Name
@@ -469,92 +852,339 @@ func RandomNameFromFS(fsys fs.FS, state string, year int, gender string, r *rand
 	}
 
 	if !chosen || total == 0 {
-		return NameCount{}, 0, errors.New("no matching records for the provided filters")
+		return NameCount{}, 0, ErrNoMatches
 	}
 
-	aggCount := 0
-	upperCandidate := strings.ToUpper(candidate)
-	err = walkRecords(fsys, state, func(rec Record) error {
-		if year != 0 && rec.Year != year {
-			return nil
-		}
-		if genderFilter != "" && strings.ToUpper(rec.Gender) != genderFilter {
-			return nil
-		}
-		if strings.ToUpper(rec.Name) != upperCandidate {
-			return nil
-		}
-		aggCount += rec.Count
-		return nil
-	})
-	if err != nil {
-		return NameCount{}, 0, err
-	}
+	return NameCount{Name: candidate, Count: counts[strings.ToUpper(candidate)]}, total, nil
+}
+
+// YearWeight returns the multiplier applied to counts recorded in the given
+// year. Years with a weight of zero or less are skipped entirely.
+type YearWeight func(year int) float64
 
-	return NameCount{Name: candidate, Count: aggCount}, total, nil
+// HalfLifeWeight is a YearWeight that decays exponentially into the past:
+// latest weighs 1 and the weight halves every halfLife years before it, so
+// recent years dominate without older ones being ignored.
+func HalfLifeWeight(latest int, halfLife float64) YearWeight {
+	return func(year int) float64 {
+		return math.Exp2(-float64(latest-year) / halfLife)
+	}
 }
 
 // AggregateFromFS builds name totals directly from the dataset without
 // materializing every record. It returns the aggregated slice sorted by
 // descending count along with the total occurrences that matched the filters.
 func AggregateFromFS(fsys fs.FS, state string, year int, gender string) ([]NameCount, int, error) {
-	genderFilter := strings.ToUpper(strings.TrimSpace(gender))
+	return AggregateFromFSContext(context.Background(), fsys, state, year, gender)
+}
+
+// AggregateFromFSContext is AggregateFromFS, stopping with ctx's error once
+// ctx is done.
+func AggregateFromFSContext(ctx context.Context, fsys fs.FS, state string, year int, gender string) ([]NameCount, int, error) {
+	return AggregateFromFSWeightedContext(ctx, fsys, state, gender, func(y int) float64 {
+		if year != 0 && y != year {
+			return 0
+		}
+		return 1
+	})
+}
 
+// AggregateFromFSWeighted builds name totals like AggregateFromFS over the
+// years weight(year) is positive for. Count and the returned total are the
+// real births in those years; a name's Weight is its births scaled by
+// weight(year), set when it differs from Count, and entries are ordered by
+// SamplingWeight. A nil weight includes every year unscaled.
+func AggregateFromFSWeighted(fsys fs.FS, state, gender string, weight YearWeight) ([]NameCount, int, error) {
+	return AggregateFromFSWeightedContext(context.Background(), fsys, state, gender, weight)
+}
+
+// AggregateFromFSWeightedContext is AggregateFromFSWeighted, stopping with
+// ctx's error once ctx is done.
+func AggregateFromFSWeightedContext(ctx context.Context, fsys fs.FS, state, gender string, weight YearWeight) ([]NameCount, int, error) {
+	return aggregateWeighted(RecordsContext(ctx, fsys, Filter{State: state, Gender: gender}), weight)
+}
+
+// aggregateWeighted implements the weighted aggregation over any record
+// stream.
+func aggregateWeighted(records iter.Seq2[Record, error], weight YearWeight) ([]NameCount, int, error) {
 	counts := make(map[string]int)
+	weights := make(map[string]float64)
 	display := make(map[string]string)
-	total := 0
 
-	err := walkRecords(fsys, state, func(rec Record) error {
-		if year != 0 && rec.Year != year {
-			return nil
-		}
-		if genderFilter != "" && strings.ToUpper(rec.Gender) != genderFilter {
-			return nil
+	for rec, err := range records {
+		if err != nil {
+			return nil, 0, err
 		}
 		if rec.Count <= 0 {
-			return nil
+			continue
+		}
+
+		w := 1.0
+		if weight != nil {
+			w = weight(rec.Year)
+		}
+		if w <= 0 {
+			continue
 		}
 
 		key := strings.ToUpper(rec.Name)
 		counts[key] += rec.Count
+		weights[key] += float64(rec.Count) * w
 		if _, ok := display[key]; !ok {
 			display[key] = rec.Name
 		}
-		total += rec.Count
-		return nil
-	})
-	if err != nil {
-		return nil, 0, err
+	}
+
+	total := 0
+	aggregated := make([]NameCount, 0, len(counts))
+	for key, count := range counts {
+		entry := NameCount{Name: display[key], Count: count}
+		if weight := weights[key]; weight != float64(count) {
+			entry.Weight = weight
+		}
+		aggregated = append(aggregated, entry)
+		total += count
 	}
 
 	if total == 0 {
-		return nil, 0, errors.New("no matching records for the provided filters")
+		return nil, 0, ErrNoMatches
 	}
 
-	aggregated := make([]NameCount, 0, len(counts))
-	for key, count := range counts {
-		aggregated = append(aggregated, NameCount{Name: display[key], Count: count})
+	sortNameCounts(aggregated)
+
+	return aggregated, total, nil
+}
+
+// YearAggregate holds the ranked name totals for a single year.
+type YearAggregate struct {
+	Year int
+	// Names is sorted by descending count, ties broken by name.
+	Names []NameCount
+	// Ranks maps upper-cased names to their 1-based position in Names.
+	Ranks map[string]int
+	Total int
+}
+
+// AggregateByYear groups the records by year in a single pass and ranks the
+// names within each year. gender can be "M", "F", or empty for all. The result
+// is ordered chronologically.
+func AggregateByYear(records []Record, gender string) []YearAggregate {
+	acc := newYearAccumulator(gender)
+	for _, r := range records {
+		acc.add(r)
+	}
+	return acc.result()
+}
+
+// yearAccumulator builds per-year name totals one record at a time.
+type yearAccumulator struct {
+	gender string
+	yearly map[int]map[string]*NameCount
+	totals map[int]int
+}
+
+func newYearAccumulator(gender string) *yearAccumulator {
+	return &yearAccumulator{
+		gender: strings.ToUpper(strings.TrimSpace(gender)),
+		yearly: make(map[int]map[string]*NameCount),
+		totals: make(map[int]int),
 	}
+}
 
-	sort.Slice(aggregated, func(i, j int) bool {
-		if aggregated[i].Count == aggregated[j].Count {
-			return aggregated[i].Name < aggregated[j].Name
+// add counts r unless it falls outside the accumulator's gender.
+func (acc *yearAccumulator) add(r Record) {
+	if acc.gender != "" && strings.ToUpper(r.Gender) != acc.gender {
+		return
+	}
+	yearMap, ok := acc.yearly[r.Year]
+	if !ok {
+		yearMap = make(map[string]*NameCount)
+		acc.yearly[r.Year] = yearMap
+	}
+	key := strings.ToUpper(r.Name)
+	entry, ok := yearMap[key]
+	if !ok {
+		entry = &NameCount{Name: r.Name}
+		yearMap[key] = entry
+	}
+	entry.Count += r.Count
+	acc.totals[r.Year] += r.Count
+}
+
+// result returns the ranked aggregates in chronological order.
+func (acc *yearAccumulator) result() []YearAggregate {
+	result := make([]YearAggregate, 0, len(acc.yearly))
+	for year, yearMap := range acc.yearly {
+		entries := make([]NameCount, 0, len(yearMap))
+		for _, entry := range yearMap {
+			entries = append(entries, *entry)
 		}
-		return aggregated[i].Count > aggregated[j].Count
+		sortNameCounts(entries)
+		ranks := make(map[string]int, len(entries))
+		for idx, entry := range entries {
+			ranks[strings.ToUpper(entry.Name)] = idx + 1
+		}
+		result = append(result, YearAggregate{Year: year, Names: entries, Ranks: ranks, Total: acc.totals[year]})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Year < result[j].Year
 	})
 
-	return aggregated, total, nil
+	return result
+}
+
+// sortNameCounts orders entries by descending count, breaking ties by name.
+func sortNameCounts(entries []NameCount) {
+	sort.Slice(entries, func(i, j int) bool {
+		wi, wj := entries[i].SamplingWeight(), entries[j].SamplingWeight()
+		if wi == wj {
+			return entries[i].Name < entries[j].Name
+		}
+		return wi > wj
+	})
+}
+
+// YearRange bounds a query to an inclusive span of years. A zero bound is
+// open-ended, so the zero value covers every year.
+type YearRange struct {
+	From, To int
+}
+
+// Contains reports whether year falls within the range.
+func (r YearRange) Contains(year int) bool {
+	return (r.From == 0 || year >= r.From) && (r.To == 0 || year <= r.To)
+}
+
+// validate rejects negative or inverted bounds.
+func (r YearRange) validate() error {
+	if r.From < 0 || r.To < 0 {
+		return errors.New("year range bounds must be positive")
+	}
+	if r.From != 0 && r.To != 0 && r.To < r.From {
+		return fmt.Errorf("invalid year range %d-%d", r.From, r.To)
+	}
+	return nil
 }
 
 // Trend aggregates yearly rank and count information for the provided names.
-// If gender is empty, all genders are included.
-func Trend(records []Record, gender string, names []string) ([]int, []TrendSeries, map[int]int, error) {
-	gender = strings.ToUpper(strings.TrimSpace(gender))
+// If gender is empty, all genders are included. Only years within span are
+// reported; the zero YearRange covers the full dataset.
+func Trend(records []Record, gender string, names []string, span YearRange) ([]int, []TrendSeries, map[int]int, error) {
+	return TrendSeq(SliceRecords(records), gender, names, span)
+}
 
-	requested := make([]struct {
-		Key   string
-		Input string
-	}, 0, len(names))
+// TrendSeq is Trend over a record stream, such as one returned by Records,
+// so the dataset never has to be loaded in full.
+func TrendSeq(records iter.Seq2[Record, error], gender string, names []string, span YearRange) ([]int, []TrendSeries, map[int]int, error) {
+	requested, err := trendRequests(names)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := span.validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	acc := newYearAccumulator(gender)
+	for rec, err := range records {
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !span.Contains(rec.Year) {
+			continue
+		}
+		acc.add(rec)
+	}
+
+	yearly := acc.result()
+	if len(yearly) == 0 {
+		return nil, nil, nil, ErrNoMatches
+	}
+
+	years, totals, displayNames := trendIndex(yearly)
+	lookup := make(map[int]YearAggregate, len(yearly))
+	for _, agg := range yearly {
+		lookup[agg.Year] = agg
+	}
+
+	series := make([]TrendSeries, 0, len(requested))
+	for _, req := range requested {
+		series = append(series, buildTrendSeries(req, displayNames, years, lookup, ""))
+	}
+
+	return years, series, totals, nil
+}
+
+// TrendByGender is like Trend with no gender filter, except each name yields
+// two series, F then M, instead of one merged series. Ranks and point totals
+// are computed within each gender, while the returned totals cover both.
+func TrendByGender(records []Record, names []string, span YearRange) ([]int, []TrendSeries, map[int]int, error) {
+	return TrendByGenderSeq(SliceRecords(records), names, span)
+}
+
+// TrendByGenderSeq is TrendByGender over a record stream. The stream is
+// consumed in a single pass.
+func TrendByGenderSeq(records iter.Seq2[Record, error], names []string, span YearRange) ([]int, []TrendSeries, map[int]int, error) {
+	requested, err := trendRequests(names)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := span.validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	genders := []string{"F", "M"}
+	combinedAcc := newYearAccumulator("")
+	genderAccs := make([]*yearAccumulator, len(genders))
+	for i, gender := range genders {
+		genderAccs[i] = newYearAccumulator(gender)
+	}
+	for rec, err := range records {
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !span.Contains(rec.Year) {
+			continue
+		}
+		combinedAcc.add(rec)
+		for _, acc := range genderAccs {
+			acc.add(rec)
+		}
+	}
+
+	combined := combinedAcc.result()
+	if len(combined) == 0 {
+		return nil, nil, nil, ErrNoMatches
+	}
+	years, totals, displayNames := trendIndex(combined)
+
+	lookups := make(map[string]map[int]YearAggregate, len(genders))
+	for i, gender := range genders {
+		lookup := make(map[int]YearAggregate)
+		for _, agg := range genderAccs[i].result() {
+			lookup[agg.Year] = agg
+		}
+		lookups[gender] = lookup
+	}
+
+	series := make([]TrendSeries, 0, len(requested)*len(genders))
+	for _, req := range requested {
+		for _, gender := range genders {
+			series = append(series, buildTrendSeries(req, displayNames, years, lookups[gender], gender))
+		}
+	}
+
+	return years, series, totals, nil
+}
+
+type trendRequest struct {
+	Key   string
+	Input string
+}
+
+// trendRequests normalizes and de-duplicates the requested names.
+func trendRequests(names []string) ([]trendRequest, error) {
+	requested := make([]trendRequest, 0, len(names))
 	seen := make(map[string]bool)
 	for _, raw := range names {
 		trimmed := strings.TrimSpace(raw)
@@ -566,102 +1196,112 @@ func Trend(records []Record, gender string, names []string) ([]int, []TrendSerie
 			continue
 		}
 		seen[key] = true
-		requested = append(requested, struct {
-			Key   string
-			Input string
-		}{Key: key, Input: trimmed})
+		requested = append(requested, trendRequest{Key: key, Input: trimmed})
 	}
 
 	if len(requested) == 0 {
-		return nil, nil, nil, errors.New("at least one name is required")
+		return nil, errors.New("at least one name is required")
+	}
+	return requested, nil
+}
+
+// trendIndex extracts the chronological years, per-year totals, and the
+// first-seen display spelling of every name.
+func trendIndex(yearly []YearAggregate) ([]int, map[int]int, map[string]string) {
+	years := make([]int, len(yearly))
+	totals := make(map[int]int, len(yearly))
+	displayNames := make(map[string]string)
+	for i, agg := range yearly {
+		years[i] = agg.Year
+		totals[agg.Year] = agg.Total
+		for _, entry := range agg.Names {
+			key := strings.ToUpper(entry.Name)
+			if _, ok := displayNames[key]; !ok {
+				displayNames[key] = entry.Name
+			}
+		}
+	}
+	return years, totals, displayNames
+}
+
+func buildTrendSeries(req trendRequest, displayNames map[string]string, years []int, lookup map[int]YearAggregate, gender string) TrendSeries {
+	display := displayNames[req.Key]
+	if display == "" {
+		display = req.Input
 	}
 
-	type aggregate struct {
-		Name  string
-		Count int
+	points := make([]TrendPoint, 0, len(years))
+	for _, year := range years {
+		point := TrendPoint{Year: year}
+		if agg, ok := lookup[year]; ok {
+			point.Total = agg.Total
+			if rank, ok := agg.Ranks[req.Key]; ok {
+				point.Present = true
+				point.Count = agg.Names[rank-1].Count
+				point.Rank = rank
+			}
+		}
+		points = append(points, point)
 	}
 
-	yearly := make(map[int]map[string]*aggregate)
+	return TrendSeries{Name: display, Gender: gender, Points: points}
+}
+
+// DecadeAggregate holds the ranked name totals for one decade.
+type DecadeAggregate struct {
+	// Decade is the first year of the decade, e.g. 1980 for the 1980s.
+	Decade int
+	// Names is sorted by descending count, ties broken by name.
+	Names []NameCount
+	Total int
+}
+
+// Label returns the decade in the form "1980s".
+func (d DecadeAggregate) Label() string {
+	return fmt.Sprintf("%ds", d.Decade)
+}
+
+// GroupByDecade totals the records per decade, applying the gender filter
+// ("M", "F", or empty for both). The result is in chronological order and
+// only covers decades with matching records.
+func GroupByDecade(records []Record, gender string) []DecadeAggregate {
+	gender = strings.ToUpper(strings.TrimSpace(gender))
+
+	decades := make(map[int]map[string]*NameCount)
 	totals := make(map[int]int)
 	for _, r := range records {
 		if gender != "" && strings.ToUpper(r.Gender) != gender {
 			continue
 		}
-		yearMap, ok := yearly[r.Year]
+		decade := r.Year - r.Year%10
+		names, ok := decades[decade]
 		if !ok {
-			yearMap = make(map[string]*aggregate)
-			yearly[r.Year] = yearMap
+			names = make(map[string]*NameCount)
+			decades[decade] = names
 		}
 		key := strings.ToUpper(r.Name)
-		agg, ok := yearMap[key]
+		entry, ok := names[key]
 		if !ok {
-			agg = &aggregate{Name: r.Name}
-			yearMap[key] = agg
-		}
-		agg.Count += r.Count
-		totals[r.Year] += r.Count
-	}
-
-	if len(yearly) == 0 {
-		return nil, nil, nil, errors.New("no matching records for the provided filters")
-	}
-
-	years := make([]int, 0, len(yearly))
-	for year := range yearly {
-		years = append(years, year)
-	}
-	sort.Ints(years)
-
-	// Build rank lookups per year.
-	yearRanks := make(map[int]map[string]int, len(years))
-	for _, year := range years {
-		yearMap := yearly[year]
-		entries := make([]NameCount, 0, len(yearMap))
-		for _, agg := range yearMap {
-			entries = append(entries, NameCount{Name: agg.Name, Count: agg.Count})
-		}
-		sort.Slice(entries, func(i, j int) bool {
-			if entries[i].Count == entries[j].Count {
-				return entries[i].Name < entries[j].Name
-			}
-			return entries[i].Count > entries[j].Count
-		})
-		ranks := make(map[string]int, len(entries))
-		for idx, entry := range entries {
-			ranks[strings.ToUpper(entry.Name)] = idx + 1
+			entry = &NameCount{Name: r.Name}
+			names[key] = entry
 		}
-		yearRanks[year] = ranks
+		entry.Count += r.Count
+		totals[decade] += r.Count
 	}
 
-	displayNames := make(map[string]string)
-	for _, yearMap := range yearly {
-		for key, agg := range yearMap {
-			if _, ok := displayNames[key]; !ok {
-				displayNames[key] = agg.Name
-			}
+	result := make([]DecadeAggregate, 0, len(decades))
+	for decade, names := range decades {
+		entries := make([]NameCount, 0, len(names))
+		for _, entry := range names {
+			entries = append(entries, *entry)
 		}
+		sortNameCounts(entries)
+		result = append(result, DecadeAggregate{Decade: decade, Names: entries, Total: totals[decade]})
 	}
 
-	series := make([]TrendSeries, 0, len(requested))
-	for _, req := range requested {
-		display := displayNames[req.Key]
-		if display == "" {
-			display = req.Input
-		}
-		points := make([]TrendPoint, 0, len(years))
-		for _, year := range years {
-			stats := yearly[year]
-			rankLookup := yearRanks[year]
-			point := TrendPoint{Year: year}
-			if agg, ok := stats[req.Key]; ok {
-				point.Present = true
-				point.Count = agg.Count
-				point.Rank = rankLookup[req.Key]
-			}
-			points = append(points, point)
-		}
-		series = append(series, TrendSeries{Name: display, Points: points})
-	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Decade < result[j].Decade
+	})
 
-	return years, series, totals, nil
+	return result
 }
@@ -1,7 +1,9 @@
 package namesdata_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -12,6 +14,7 @@ import (
 	"gonum.org/v1/gonum/stat/distuv"
 
 	"github.com/curtiscovington/ssa-names/data/namesbystate"
+	"github.com/curtiscovington/ssa-names/internal/index"
 	"github.com/curtiscovington/ssa-names/internal/namesdata"
 )
 
@@ -105,6 +108,85 @@ func TestAggregateNamesAndRank(t *testing.T) {
 	if overallRank != 2 {
 		t.Fatalf("expected Liam rank 2 overall, got %d", overallRank)
 	}
+
+	many, err := namesdata.RankMany(records, 0, "", []string{"liam", "Zelda", "Olivia"})
+	if err != nil {
+		t.Fatalf("RankMany: %v", err)
+	}
+	if len(many) != 3 || many[0].Rank != 2 || many[0].Entry.Count != 180 || many[0].Query != "liam" {
+		t.Fatalf("unexpected RankMany result: %+v", many)
+	}
+	if many[1].Found() || many[1].Query != "Zelda" || !many[2].Found() || many[2].Rank != 1 {
+		t.Fatalf("unexpected RankMany result: %+v", many)
+	}
+	if _, err := namesdata.RankMany(records, 0, "", []string{"Liam", " "}); err == nil {
+		t.Fatal("expected an error for a blank name")
+	}
+	if _, err := namesdata.RankMany(records, 1900, "", []string{"Liam"}); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches for an empty year, got %v", err)
+	}
+}
+
+// indexedMapFS serves a prebuilt index alongside the text files.
+type indexedMapFS struct {
+	fstest.MapFS
+	idx *index.Index
+}
+
+func (f indexedMapFS) Index() (*index.Index, error) {
+	return f.idx, nil
+}
+
+func TestLoadRecordsFromIndex(t *testing.T) {
+	text := sampleFS()
+	builder := index.NewBuilder()
+	records, err := namesdata.LoadAllRecords(text)
+	if err != nil {
+		t.Fatalf("LoadAllRecords: %v", err)
+	}
+	for _, r := range records {
+		builder.Add(index.Record(r))
+	}
+	// Make the index disagree with the text files so the test can tell
+	// which source was read.
+	builder.Add(index.Record{State: "CA", Gender: "F", Year: 2019, Name: "Ava", Count: 500})
+
+	var buf bytes.Buffer
+	if _, err := builder.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	idx, err := index.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// A names.idx sitting in a plain directory is not trusted.
+	loose := sampleFS()
+	loose[index.FileName] = &fstest.MapFile{Data: buf.Bytes()}
+	aggregated, total, err := namesdata.AggregateFromFS(loose, "CA", 2019, "F")
+	if err != nil {
+		t.Fatalf("AggregateFromFS loose index: %v", err)
+	}
+	if total != 230 || aggregated[0].Name != "Olivia" {
+		t.Fatalf("expected the text files to be read, got %+v (total %d)", aggregated, total)
+	}
+
+	indexed := indexedMapFS{MapFS: sampleFS(), idx: idx}
+	aggregated, total, err = namesdata.AggregateFromFS(indexed, "CA", 2019, "F")
+	if err != nil {
+		t.Fatalf("AggregateFromFS indexed: %v", err)
+	}
+	if total != 730 || aggregated[0].Name != "Ava" || aggregated[1].Count != 140 {
+		t.Fatalf("expected indexed aggregate, got %+v (total %d)", aggregated, total)
+	}
+
+	stateRecords, err := namesdata.LoadStateRecords(indexed, "ny")
+	if err != nil {
+		t.Fatalf("LoadStateRecords indexed: %v", err)
+	}
+	if len(stateRecords) != 3 {
+		t.Fatalf("expected 3 NY records from the index, got %+v", stateRecords)
+	}
 }
 
 func TestTrendAggregates(t *testing.T) {
@@ -114,7 +196,7 @@ func TestTrendAggregates(t *testing.T) {
 		t.Fatalf("LoadStateRecords: %v", err)
 	}
 
-	years, series, totals, err := namesdata.Trend(records, "", []string{"Olivia", "Liam"})
+	years, series, totals, err := namesdata.Trend(records, "", []string{"Olivia", "Liam"}, namesdata.YearRange{})
 	if err != nil {
 		t.Fatalf("Trend: %v", err)
 	}
@@ -152,7 +234,7 @@ func TestTrendGenderFilter(t *testing.T) {
 		t.Fatalf("LoadStateRecords: %v", err)
 	}
 
-	_, series, totals, err := namesdata.Trend(records, "F", []string{"Olivia", "Emma"})
+	_, series, totals, err := namesdata.Trend(records, "F", []string{"Olivia", "Emma"}, namesdata.YearRange{})
 	if err != nil {
 		t.Fatalf("Trend: %v", err)
 	}
@@ -173,6 +255,69 @@ func TestTrendGenderFilter(t *testing.T) {
 	}
 }
 
+func TestTrendYearRange(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 2018, Name: "Ava", Count: 10},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Ava", Count: 20},
+		{State: "CA", Gender: "F", Year: 2020, Name: "Mia", Count: 30},
+		{State: "CA", Gender: "F", Year: 2021, Name: "Ava", Count: 40},
+	}
+
+	years, series, totals, err := namesdata.Trend(records, "F", []string{"Ava"}, namesdata.YearRange{From: 2019, To: 2020})
+	if err != nil {
+		t.Fatalf("Trend: %v", err)
+	}
+	if len(years) != 2 || years[0] != 2019 || years[1] != 2020 {
+		t.Fatalf("expected years 2019-2020, got %v", years)
+	}
+	if _, ok := totals[2021]; ok {
+		t.Fatalf("expected totals limited to the range, got %v", totals)
+	}
+	if points := series[0].Points; !points[0].Present || points[0].Count != 20 || points[1].Present {
+		t.Fatalf("unexpected Ava points: %+v", points)
+	}
+
+	if _, _, _, err := namesdata.Trend(records, "F", []string{"Ava"}, namesdata.YearRange{From: 2030}); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches outside the data, got %v", err)
+	}
+	if _, _, _, err := namesdata.Trend(records, "F", []string{"Ava"}, namesdata.YearRange{From: 2020, To: 2019}); err == nil {
+		t.Fatalf("expected error for an inverted range")
+	}
+}
+
+func TestTrendByGender(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 2019, Name: "Riley", Count: 30},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Emma", Count: 70},
+		{State: "CA", Gender: "M", Year: 2019, Name: "Riley", Count: 60},
+		{State: "CA", Gender: "M", Year: 2019, Name: "Liam", Count: 40},
+		{State: "CA", Gender: "M", Year: 2020, Name: "Riley", Count: 50},
+	}
+
+	years, series, totals, err := namesdata.TrendByGender(records, []string{"riley"}, namesdata.YearRange{})
+	if err != nil {
+		t.Fatalf("TrendByGender: %v", err)
+	}
+	if len(years) != 2 || totals[2019] != 200 {
+		t.Fatalf("unexpected years %v or totals %v", years, totals)
+	}
+	if len(series) != 2 || series[0].Label() != "Riley (F)" || series[1].Label() != "Riley (M)" {
+		t.Fatalf("expected F and M series for Riley, got %+v", series)
+	}
+
+	female2019 := series[0].Points[0]
+	if !female2019.Present || female2019.Rank != 2 || female2019.Count != 30 || female2019.Total != 100 {
+		t.Fatalf("unexpected female 2019 point: %+v", female2019)
+	}
+	if series[0].Points[1].Present {
+		t.Fatalf("expected no female Riley in 2020, got %+v", series[0].Points[1])
+	}
+	male2020 := series[1].Points[1]
+	if !male2020.Present || male2020.Rank != 1 || male2020.Count != 50 || male2020.Total != 50 {
+		t.Fatalf("unexpected male 2020 point: %+v", male2020)
+	}
+}
+
 func TestRandomNameFromAggregateDeterministic(t *testing.T) {
 	aggregated := []namesdata.NameCount{{Name: "Olivia", Count: 140}, {Name: "Emma", Count: 90}}
 	trials := 5000
@@ -243,6 +388,55 @@ func TestAggregateFromFSMatchesRecords(t *testing.T) {
 	}
 }
 
+func TestAggregateFromFSWeighted(t *testing.T) {
+	fs := sampleFS()
+
+	aggregated, total, err := namesdata.AggregateFromFSWeighted(fs, "CA", "F", func(year int) float64 {
+		if year == 2018 {
+			return 0.5
+		}
+		return 1
+	})
+	if err != nil {
+		t.Fatalf("AggregateFromFSWeighted: %v", err)
+	}
+
+	// Counts stay real births, with the weights beside them: Olivia
+	// 140 + 80*0.5 = 180, Emma 90 + 50*0.5 = 115.
+	if len(aggregated) != 2 || aggregated[0] != (namesdata.NameCount{Name: "Olivia", Count: 220, Weight: 180}) {
+		t.Fatalf("unexpected weighted aggregate: %+v", aggregated)
+	}
+	if aggregated[1] != (namesdata.NameCount{Name: "Emma", Count: 140, Weight: 115}) {
+		t.Fatalf("unexpected second weighted entry: %+v", aggregated[1])
+	}
+	if total != 360 {
+		t.Fatalf("expected 360 births, got %d", total)
+	}
+
+	// Tiny weights are kept rather than rounded away.
+	tiny, total, err := namesdata.AggregateFromFSWeighted(fs, "CA", "F", func(int) float64 { return 0.001 })
+	if err != nil || len(tiny) != 2 || total != 360 || tiny[1].Count != 140 || tiny[1].Weight < 0.139 || tiny[1].Weight > 0.141 {
+		t.Fatalf("unexpected tiny-weight aggregate %+v, %d: %v", tiny, total, err)
+	}
+	sampler, err := namesdata.NewNameSampler(tiny)
+	if err != nil || math.Abs(sampler.Chance(tiny[1])-140.0/360) > 1e-9 {
+		t.Fatalf("expected Emma's chance to follow its weight, got %v: %v", sampler.Chance(tiny[1]), err)
+	}
+
+	if _, _, err := namesdata.AggregateFromFSWeighted(fs, "CA", "F", func(int) float64 { return 0 }); err == nil {
+		t.Fatalf("expected error when every year is weighted out")
+	}
+}
+
+func TestHalfLifeWeight(t *testing.T) {
+	weight := namesdata.HalfLifeWeight(2020, 10)
+	for year, want := range map[int]float64{2020: 1, 2010: 0.5, 2000: 0.25, 2015: math.Sqrt(0.5)} {
+		if got := weight(year); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("weight(%d) = %v, want %v", year, got, want)
+		}
+	}
+}
+
 func TestRandomNameMatchesAggregate(t *testing.T) {
 	fs := sampleFS()
 	records, err := namesdata.LoadStateRecords(fs, "CA")
@@ -328,6 +522,56 @@ func TestNameSamplerPick(t *testing.T) {
 	}
 }
 
+func TestNameSamplerStrategies(t *testing.T) {
+	aggregated, total, err := namesdata.AggregateFromFS(namesbystate.Files, "CA", 2019, "F")
+	if err != nil {
+		t.Fatalf("AggregateFromFS real data: %v", err)
+	}
+
+	few, err := namesdata.NewNameSamplerWithStrategy(aggregated, namesdata.SamplerAuto, 3)
+	if err != nil {
+		t.Fatalf("NewNameSamplerWithStrategy few: %v", err)
+	}
+	if few.Strategy() != namesdata.SamplerCDF {
+		t.Fatalf("expected cdf strategy for few draws, got %s", few.Strategy())
+	}
+	many, err := namesdata.NewNameSamplerWithStrategy(aggregated, namesdata.SamplerAuto, 1_000_000)
+	if err != nil {
+		t.Fatalf("NewNameSamplerWithStrategy many: %v", err)
+	}
+	if many.Strategy() != namesdata.SamplerAlias {
+		t.Fatalf("expected alias strategy for many draws, got %s", many.Strategy())
+	}
+
+	oliviaCount := 0
+	for _, entry := range aggregated {
+		if entry.Name == "Olivia" {
+			oliviaCount = entry.Count
+		}
+	}
+
+	cdf, err := namesdata.NewNameSamplerWithStrategy(aggregated, namesdata.SamplerCDF, 0)
+	if err != nil {
+		t.Fatalf("NewNameSamplerWithStrategy cdf: %v", err)
+	}
+	trials := 6000
+	lower, upper := binomialConfidenceBounds(trials, float64(oliviaCount)/float64(total), 0.99)
+	rng := rand.New(rand.NewSource(31))
+	oliviaDraws := 0
+	for i := 0; i < trials; i++ {
+		entry, err := cdf.Pick(rng)
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		if entry.Name == "Olivia" {
+			oliviaDraws++
+		}
+	}
+	if oliviaDraws < lower || oliviaDraws > upper {
+		t.Fatalf("cdf sampler drew Olivia %d times out of %d, outside 99%% interval [%d, %d]", oliviaDraws, trials, lower, upper)
+	}
+}
+
 func TestNameSamplerErrors(t *testing.T) {
 	if _, err := namesdata.NewNameSampler(nil); err == nil {
 		t.Fatalf("expected error for nil aggregate")
@@ -339,6 +583,160 @@ func TestNameSamplerErrors(t *testing.T) {
 	}
 }
 
+func TestNameSamplerPickUnique(t *testing.T) {
+	sampler, err := namesdata.NewNameSampler([]namesdata.NameCount{
+		{Name: "Heavy", Count: 1000},
+		{Name: "Mid", Count: 100},
+		{Name: "Light", Count: 10},
+		{Name: "Zero", Count: 0},
+	})
+	if err != nil {
+		t.Fatalf("NewNameSampler: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	heavyFirst := 0
+	const trials = 2000
+	for i := 0; i < trials; i++ {
+		picks, err := sampler.PickUnique(3, rng)
+		if err != nil {
+			t.Fatalf("PickUnique: %v", err)
+		}
+		seen := make(map[string]bool)
+		for _, pick := range picks {
+			if seen[pick.Name] || pick.Name == "Zero" {
+				t.Fatalf("unexpected draw %+v", picks)
+			}
+			seen[pick.Name] = true
+		}
+		if picks[0].Name == "Heavy" {
+			heavyFirst++
+		}
+	}
+	// Heavy should lead about 1000/1110 ≈ 90% of the time.
+	if ratio := float64(heavyFirst) / trials; ratio < 0.87 || ratio > 0.93 {
+		t.Fatalf("expected Heavy first about 90%% of the time, got %.3f", ratio)
+	}
+
+	if _, err := sampler.PickUnique(4, rng); err == nil {
+		t.Fatalf("expected error when asking for more names than have weight")
+	}
+	if picks, err := sampler.PickUnique(0, rng); err != nil || len(picks) != 0 {
+		t.Fatalf("expected no picks for n=0, got %+v %v", picks, err)
+	}
+}
+
+func TestTemperedNameSampler(t *testing.T) {
+	aggregated := []namesdata.NameCount{
+		{Name: "Heavy", Count: 900},
+		{Name: "Light", Count: 100},
+		{Name: "Zero", Count: 0},
+	}
+
+	for _, strategy := range []namesdata.SamplerStrategy{namesdata.SamplerAlias, namesdata.SamplerCDF} {
+		for _, tc := range []struct {
+			temperature float64
+			heavy       float64
+		}{
+			{temperature: 1, heavy: 0.9},
+			{temperature: 0.5, heavy: 0.75},
+			{temperature: 0, heavy: 0.5},
+			{temperature: 2, heavy: 81.0 / 82.0},
+		} {
+			sampler, err := namesdata.NewTemperedNameSampler(aggregated, tc.temperature, strategy, 0)
+			if err != nil {
+				t.Fatalf("NewTemperedNameSampler(%g, %s): %v", tc.temperature, strategy, err)
+			}
+			if sampler.Strategy() != strategy || sampler.Temperature() != tc.temperature {
+				t.Fatalf("expected %s sampler at %g, got %s at %g", strategy, tc.temperature, sampler.Strategy(), sampler.Temperature())
+			}
+			if chance := sampler.Chance(aggregated[0]); math.Abs(chance-tc.heavy) > 1e-9 {
+				t.Fatalf("expected Heavy chance %.4f at %g, got %.4f", tc.heavy, tc.temperature, chance)
+			}
+			if chance := sampler.Chance(aggregated[2]); chance != 0 {
+				t.Fatalf("expected a zero count to have no chance at %g, got %g", tc.temperature, chance)
+			}
+
+			const trials = 4000
+			lower, upper := binomialConfidenceBounds(trials, tc.heavy, 0.999)
+			rng := rand.New(rand.NewSource(17))
+			heavy := 0
+			for range trials {
+				pick, err := sampler.Pick(rng)
+				if err != nil {
+					t.Fatalf("Pick: %v", err)
+				}
+				if pick.Name == "Zero" {
+					t.Fatalf("drew a zero-count name at %g with %s", tc.temperature, strategy)
+				}
+				if pick.Name == "Heavy" {
+					heavy++
+				}
+			}
+			if heavy < lower || heavy > upper {
+				t.Fatalf("%s sampler at %g drew Heavy %d of %d times, outside [%d, %d]", strategy, tc.temperature, heavy, trials, lower, upper)
+			}
+
+			if _, err := sampler.PickUnique(3, rng); err == nil {
+				t.Fatalf("expected the zero-count name to stay undrawable at %g", tc.temperature)
+			}
+		}
+	}
+
+	if _, err := namesdata.NewTemperedNameSampler(aggregated, -1, namesdata.SamplerAuto, 0); err == nil {
+		t.Fatalf("expected error for a negative temperature")
+	}
+}
+
+func TestPairSampler(t *testing.T) {
+	// Ava dominates the pool, so most middle draws must fall back.
+	sampler, err := namesdata.NewNameSampler([]namesdata.NameCount{
+		{Name: "Ava", Count: 1_000_000},
+		{Name: "Mia", Count: 1},
+	})
+	if err != nil {
+		t.Fatalf("NewNameSampler: %v", err)
+	}
+	pairs, err := namesdata.NewPairSampler(sampler, sampler)
+	if err != nil {
+		t.Fatalf("NewPairSampler: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		pair, err := pairs.Pick(rng)
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		if pair.First.Name == pair.Middle.Name {
+			t.Fatalf("expected distinct names, got %s", pair)
+		}
+	}
+
+	unique, err := pairs.PickUnique(2, rng)
+	if err != nil {
+		t.Fatalf("PickUnique: %v", err)
+	}
+	if len(unique) != 2 || unique[0].First.Name == unique[1].First.Name {
+		t.Fatalf("expected distinct first names, got %+v", unique)
+	}
+
+	only, err := namesdata.NewNameSampler([]namesdata.NameCount{{Name: "ava", Count: 5}})
+	if err != nil {
+		t.Fatalf("NewNameSampler: %v", err)
+	}
+	sameOnly, err := namesdata.NewPairSampler(only, only)
+	if err != nil {
+		t.Fatalf("NewPairSampler: %v", err)
+	}
+	if _, err := sameOnly.Pick(rng); err == nil {
+		t.Fatalf("expected error when no middle name differs from the first")
+	}
+	if _, err := namesdata.NewPairSampler(sampler, nil); err == nil {
+		t.Fatalf("expected error for a missing middle sampler")
+	}
+}
+
 func TestNameSamplerRealDataset(t *testing.T) {
 	aggregated, total, err := namesdata.AggregateFromFS(namesbystate.Files, "CA", 2019, "F")
 	if err != nil {
@@ -403,7 +801,8 @@ func TestRandomNameFromFS(t *testing.T) {
 		t.Fatalf("expected total 230 for CA 2019 F, got %d", total)
 	}
 
-	if entry.Name == "" || entry.Count <= 0 {
+	// Olivia's count is spread over two CA records.
+	if want := map[string]int{"Olivia": 140, "Emma": 90}[entry.Name]; entry.Count != want {
 		t.Fatalf("unexpected entry: %+v", entry)
 	}
 
@@ -675,3 +1074,26 @@ func ExampleAggregateNames() {
 	fmt.Println(string(data))
 	// Output: [{"Name":"Olivia","Count":140},{"Name":"Emma","Count":90}]
 }
+
+func TestGroupByDecade(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 1985, Name: "Jessica", Count: 90},
+		{State: "CA", Gender: "F", Year: 1989, Name: "Ashley", Count: 60},
+		{State: "CA", Gender: "M", Year: 1989, Name: "Michael", Count: 200},
+		{State: "CA", Gender: "F", Year: 1990, Name: "ashley", Count: 70},
+	}
+
+	decades := namesdata.GroupByDecade(records, "f")
+	if len(decades) != 2 {
+		t.Fatalf("expected 2 decades, got %+v", decades)
+	}
+	if decades[0].Decade != 1980 || decades[0].Label() != "1980s" || decades[0].Total != 150 {
+		t.Fatalf("unexpected 1980s aggregate: %+v", decades[0])
+	}
+	if decades[0].Names[0].Name != "Jessica" || decades[0].Names[1].Name != "Ashley" {
+		t.Fatalf("unexpected 1980s ranking: %+v", decades[0].Names)
+	}
+	if decades[1].Decade != 1990 || len(decades[1].Names) != 1 || decades[1].Names[0].Count != 70 {
+		t.Fatalf("unexpected 1990s aggregate: %+v", decades[1])
+	}
+}
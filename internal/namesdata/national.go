@@ -0,0 +1,161 @@
+package namesdata
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NationalState is the State of records loaded from the national dataset.
+const NationalState = "US"
+
+// ErrNoNationalData is returned when a dataset holds no yobYYYY.txt files.
+var ErrNoNationalData = errors.New("no national yobYYYY.txt files found in dataset")
+
+// LoadNationalRecords loads the SSA national dataset, one yobYYYY.txt file per
+// year with lines of the form "NAME,GENDER,COUNT". Unlike the state files it
+// has no per-state suppression of rare names, so totals are exact.
+func LoadNationalRecords(fsys fs.FS) ([]Record, error) {
+	return LoadNationalRecordsContext(context.Background(), fsys)
+}
+
+// LoadNationalRecordsContext is LoadNationalRecords, stopping with ctx's
+// error once ctx is done.
+func LoadNationalRecordsContext(ctx context.Context, fsys fs.FS) ([]Record, error) {
+	records := make([]Record, 0, 1024)
+	walk := walkContext(ctx, func(fn func(Record) error) error {
+		return walkNationalRecords(fsys, fn)
+	})
+	if err := walk(internRecords(func(r Record) error {
+		records = append(records, r)
+		return nil
+	})); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// AggregateNationalWeighted is AggregateFromFSWeighted for the national
+// dataset.
+func AggregateNationalWeighted(fsys fs.FS, gender string, weight YearWeight) ([]NameCount, int, error) {
+	return AggregateNationalWeightedContext(context.Background(), fsys, gender, weight)
+}
+
+// AggregateNationalWeightedContext is AggregateNationalWeighted, stopping
+// with ctx's error once ctx is done.
+func AggregateNationalWeightedContext(ctx context.Context, fsys fs.FS, gender string, weight YearWeight) ([]NameCount, int, error) {
+	return aggregateWeighted(NationalRecordsContext(ctx, fsys, Filter{Gender: gender}), weight)
+}
+
+// NationalYears returns the sorted years covered by the national dataset.
+func NationalYears(fsys fs.FS) ([]int, error) {
+	files, err := nationalFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+	years := make([]int, len(files))
+	for i, file := range files {
+		years[i] = file.year
+	}
+	return years, nil
+}
+
+type nationalFile struct {
+	year int
+	name string
+}
+
+// nationalFiles lists the yobYYYY.txt files in the dataset in year order.
+func nationalFiles(fsys fs.FS) ([]nationalFile, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read dataset directory: %w", err)
+	}
+
+	files := make([]nationalFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		lower := strings.ToLower(entry.Name())
+		digits, ok := strings.CutPrefix(lower, "yob")
+		if !ok {
+			continue
+		}
+		digits, ok = strings.CutSuffix(digits, ".txt")
+		if !ok || len(digits) != 4 {
+			continue
+		}
+		year, err := strconv.Atoi(digits)
+		if err != nil {
+			continue
+		}
+		files = append(files, nationalFile{year: year, name: entry.Name()})
+	}
+
+	if len(files) == 0 {
+		return nil, ErrNoNationalData
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].year < files[j].year })
+	return files, nil
+}
+
+func walkNationalRecords(fsys fs.FS, fn func(Record) error) error {
+	files, err := nationalFiles(fsys)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := readNationalFile(fsys, file, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readNationalFile(fsys fs.FS, file nationalFile, fn func(Record) error) error {
+	f, err := fsys.Open(file.name)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", file.name, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, ",")
+		if len(parts) != 3 {
+			return fmt.Errorf("malformed line in %s: %q", file.name, line)
+		}
+
+		count, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return fmt.Errorf("parse count %q in %s: %w", parts[2], file.name, err)
+		}
+
+		record := Record{
+			State:  NationalState,
+			Gender: parts[1],
+			Year:   file.year,
+			Name:   parts[0],
+			Count:  count,
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan %s: %w", file.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package namesdata_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func nationalFS() fstest.MapFS {
+	return fstest.MapFS{
+		"yob2018.txt": {Data: []byte("Emma,F,300\nOlivia,F,280\nLiam,M,310\n")},
+		"yob2019.txt": {Data: []byte("Olivia,F,320\nEmma,F,290\nLiam,M,330\nNoah,M,5\n")},
+		"README.txt":  {Data: []byte("not a data file\n")},
+	}
+}
+
+func TestLoadNationalRecords(t *testing.T) {
+	records, err := namesdata.LoadNationalRecords(nationalFS())
+	if err != nil {
+		t.Fatalf("LoadNationalRecords: %v", err)
+	}
+	if len(records) != 7 {
+		t.Fatalf("expected 7 records, got %d", len(records))
+	}
+	first := records[0]
+	if first.State != namesdata.NationalState || first.Year != 2018 || first.Name != "Emma" || first.Count != 300 {
+		t.Fatalf("unexpected first record: %+v", first)
+	}
+
+	years, err := namesdata.NationalYears(nationalFS())
+	if err != nil || len(years) != 2 || years[1] != 2019 {
+		t.Fatalf("unexpected national years %v: %v", years, err)
+	}
+
+	aggregated, total, err := namesdata.AggregateNationalWeighted(nationalFS(), "F", nil)
+	if err != nil {
+		t.Fatalf("AggregateNationalWeighted: %v", err)
+	}
+	if total != 1190 || aggregated[0].Name != "Olivia" || aggregated[0].Count != 600 {
+		t.Fatalf("unexpected national aggregate %+v (total %d)", aggregated, total)
+	}
+
+	if _, err := namesdata.LoadNationalRecords(fstest.MapFS{"README.txt": {Data: []byte("x")}}); !errors.Is(err, namesdata.ErrNoNationalData) {
+		t.Fatalf("expected ErrNoNationalData, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package namesdata
+
+import (
+	"sort"
+	"strings"
+)
+
+// GenderSplit holds a name's counts for each gender.
+type GenderSplit struct {
+	Name string
+	// Year is the year counted, or 0 when the counts pool several years.
+	Year   int
+	Female int
+	Male   int
+}
+
+// Total returns the name's count across both genders.
+func (g GenderSplit) Total() int {
+	return g.Female + g.Male
+}
+
+// FemaleShare returns the fraction of the name's occurrences that are
+// female, or 0 when the name has no occurrences.
+func (g GenderSplit) FemaleShare() float64 {
+	total := g.Total()
+	if total == 0 {
+		return 0
+	}
+	return float64(g.Female) / float64(total)
+}
+
+// AggregateByName totals the records per name across genders, keeping the
+// female and male counts apart. year == 0 means all years. The result is
+// sorted by total count descending, ties broken by name.
+func AggregateByName(records []Record, year int) []GenderSplit {
+	splits := make(map[string]*GenderSplit)
+	order := make([]string, 0)
+	for _, rec := range records {
+		if year != 0 && rec.Year != year {
+			continue
+		}
+		key := strings.ToUpper(rec.Name)
+		split, ok := splits[key]
+		if !ok {
+			split = &GenderSplit{Name: rec.Name, Year: year}
+			splits[key] = split
+			order = append(order, key)
+		}
+		switch strings.ToUpper(rec.Gender) {
+		case "F":
+			split.Female += rec.Count
+		case "M":
+			split.Male += rec.Count
+		}
+	}
+
+	result := make([]GenderSplit, 0, len(order))
+	for _, key := range order {
+		result = append(result, *splits[key])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total() != result[j].Total() {
+			return result[i].Total() > result[j].Total()
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// NeutralNames keeps the splits whose female share lies within
+// [balance, 1-balance] and whose total is at least minCount, preserving
+// order. A balance of 0.4 selects names between 40% and 60% female.
+func NeutralNames(splits []GenderSplit, balance float64, minCount int) []GenderSplit {
+	lower, upper := balance, 1-balance
+	if lower > upper {
+		lower, upper = upper, lower
+	}
+
+	neutral := make([]GenderSplit, 0)
+	for _, split := range splits {
+		if split.Total() < minCount || split.Total() == 0 {
+			continue
+		}
+		share := split.FemaleShare()
+		if share >= lower && share <= upper {
+			neutral = append(neutral, split)
+		}
+	}
+	return neutral
+}
@@ -0,0 +1,39 @@
+package namesdata_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestNeutralNames(t *testing.T) {
+	records := []namesdata.Record{
+		{Gender: "F", Year: 2019, Name: "Riley", Count: 45},
+		{Gender: "M", Year: 2019, Name: "Riley", Count: 55},
+		{Gender: "F", Year: 2019, Name: "Avery", Count: 70},
+		{Gender: "M", Year: 2019, Name: "AVERY", Count: 30},
+		{Gender: "F", Year: 2019, Name: "Emma", Count: 300},
+		{Gender: "M", Year: 2019, Name: "Emma", Count: 2},
+		{Gender: "F", Year: 2019, Name: "Quinn", Count: 5},
+		{Gender: "M", Year: 2019, Name: "Quinn", Count: 5},
+		{Gender: "M", Year: 2018, Name: "Avery", Count: 40},
+	}
+
+	splits := namesdata.AggregateByName(records, 2019)
+	if len(splits) != 4 || splits[0].Name != "Emma" || splits[0].Total() != 302 {
+		t.Fatalf("unexpected splits: %+v", splits)
+	}
+	if avery := splits[1]; avery.Name != "Avery" || avery.Female != 70 || avery.Male != 30 {
+		t.Fatalf("expected Avery split merged across case, got %+v", avery)
+	}
+
+	neutral := namesdata.NeutralNames(splits, 0.4, 20)
+	if len(neutral) != 1 || neutral[0].Name != "Riley" || neutral[0].FemaleShare() != 0.45 {
+		t.Fatalf("unexpected 40-60%% names: %+v", neutral)
+	}
+
+	wide := namesdata.NeutralNames(splits, 0.3, 0)
+	if len(wide) != 3 || wide[0].Name != "Avery" || wide[2].Name != "Quinn" {
+		t.Fatalf("unexpected 30-70%% names: %+v", wide)
+	}
+}
@@ -0,0 +1,108 @@
+package namesdata
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// maxMiddleAttempts bounds the redraws PairSampler makes when the middle
+// name matches the first before falling back to a draw without replacement.
+const maxMiddleAttempts = 32
+
+// NamePair is a first and middle name drawn together.
+type NamePair struct {
+	First  NameCount
+	Middle NameCount
+}
+
+// String returns the pair as "First Middle".
+func (p NamePair) String() string {
+	return p.First.Name + " " + p.Middle.Name
+}
+
+// PairSampler draws first and middle names jointly. Each position has its
+// own sampler, so the middle name can come from different filters (such as
+// an older year range) than the first, and a middle name is never the same
+// as the first it is paired with.
+type PairSampler struct {
+	first  *NameSampler
+	middle *NameSampler
+}
+
+// NewPairSampler pairs a first-name sampler with a middle-name sampler. The
+// same sampler may be passed for both positions.
+func NewPairSampler(first, middle *NameSampler) (*PairSampler, error) {
+	if first == nil || len(first.entries) == 0 || middle == nil || len(middle.entries) == 0 {
+		return nil, ErrNoMatches
+	}
+	return &PairSampler{first: first, middle: middle}, nil
+}
+
+// Pick draws a first name, then a middle name weighted by count among the
+// middle names other than the first.
+func (p *PairSampler) Pick(r *rand.Rand) (NamePair, error) {
+	rng := pairRNG(r)
+	first, err := p.first.Pick(rng)
+	if err != nil {
+		return NamePair{}, err
+	}
+	middle, err := p.middleFor(first, rng)
+	if err != nil {
+		return NamePair{}, err
+	}
+	return NamePair{First: first, Middle: middle}, nil
+}
+
+// PickUnique draws n pairs with distinct first names, each paired with its
+// own middle name. Middle names may repeat across pairs.
+func (p *PairSampler) PickUnique(n int, r *rand.Rand) ([]NamePair, error) {
+	rng := pairRNG(r)
+	firsts, err := p.first.PickUnique(n, rng)
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]NamePair, len(firsts))
+	for i, first := range firsts {
+		middle, err := p.middleFor(first, rng)
+		if err != nil {
+			return nil, err
+		}
+		pairs[i] = NamePair{First: first, Middle: middle}
+	}
+	return pairs, nil
+}
+
+// middleFor draws a middle name that differs from first. Redrawing is cheap
+// unless first dominates the middle pool, so after maxMiddleAttempts it
+// falls back to a single pass over the pool.
+func (p *PairSampler) middleFor(first NameCount, rng *rand.Rand) (NameCount, error) {
+	for attempt := 0; attempt < maxMiddleAttempts; attempt++ {
+		middle, err := p.middle.Pick(rng)
+		if err != nil {
+			return NameCount{}, err
+		}
+		if !strings.EqualFold(middle.Name, first.Name) {
+			return middle, nil
+		}
+	}
+
+	// Taking the first of two draws without replacement that is not first
+	// follows the same conditional weights.
+	picks, err := p.middle.PickUnique(2, rng)
+	if err != nil {
+		return NameCount{}, fmt.Errorf("no middle name differs from %q", first.Name)
+	}
+	if strings.EqualFold(picks[0].Name, first.Name) {
+		return picks[1], nil
+	}
+	return picks[0], nil
+}
+
+func pairRNG(r *rand.Rand) *rand.Rand {
+	if r != nil {
+		return r
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
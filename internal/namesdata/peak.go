@@ -0,0 +1,99 @@
+package namesdata
+
+import (
+	"errors"
+	"strings"
+)
+
+// NamePeak describes the year a name was given most often, together with its
+// standing in the latest year of the data. LatestRank and LatestCount are
+// zero when the name is absent from the latest year.
+type NamePeak struct {
+	Name        string
+	Year        int
+	Count       int
+	Rank        int
+	LatestYear  int
+	LatestCount int
+	LatestRank  int
+}
+
+// Decline returns how far the latest count has fallen from the peak, as a
+// fraction of the peak: 0 when the name is at its peak and 1 when it has
+// disappeared.
+func (p NamePeak) Decline() float64 {
+	if p.Count == 0 {
+		return 0
+	}
+	return float64(p.Count-p.LatestCount) / float64(p.Count)
+}
+
+// Peak finds the year with the highest count for name (case-insensitive),
+// ranking names within each year for the gender filter ("M", "F", or empty
+// for both). Ties go to the earliest year.
+func Peak(records []Record, gender, name string) (NamePeak, error) {
+	peaks, err := PeakMany(records, gender, []string{name})
+	if err != nil {
+		return NamePeak{}, err
+	}
+	return peaks[0], nil
+}
+
+// PeakMany is Peak for several names, aggregating the records by year once
+// and returning the peaks in the order the names were given.
+func PeakMany(records []Record, gender string, names []string) ([]NamePeak, error) {
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return nil, errors.New("name is required")
+		}
+	}
+
+	yearly := AggregateByYear(records, gender)
+	if len(yearly) == 0 {
+		return nil, ErrNoMatches
+	}
+
+	peaks := make([]NamePeak, len(names))
+	for i, name := range names {
+		peak, ok := peakIn(yearly, strings.ToUpper(strings.TrimSpace(name)))
+		if !ok {
+			var candidates []NameCount
+			for _, agg := range yearly {
+				candidates = append(candidates, agg.Names...)
+			}
+			return nil, newNameNotFoundError(name, candidates)
+		}
+		peaks[i] = peak
+	}
+	return peaks, nil
+}
+
+// peakIn finds the peak of the upper-cased name key in yearly, reporting
+// false when the name never appears.
+func peakIn(yearly []YearAggregate, key string) (NamePeak, bool) {
+	var peak NamePeak
+	for _, agg := range yearly {
+		rank, ok := agg.Ranks[key]
+		if !ok {
+			continue
+		}
+		entry := agg.Names[rank-1]
+		if entry.Count > peak.Count {
+			peak.Name = entry.Name
+			peak.Year = agg.Year
+			peak.Count = entry.Count
+			peak.Rank = rank
+		}
+	}
+	if peak.Count == 0 {
+		return NamePeak{}, false
+	}
+
+	latest := yearly[len(yearly)-1]
+	peak.LatestYear = latest.Year
+	if rank, ok := latest.Ranks[key]; ok {
+		peak.LatestRank = rank
+		peak.LatestCount = latest.Names[rank-1].Count
+	}
+	return peak, true
+}
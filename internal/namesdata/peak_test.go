@@ -0,0 +1,59 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestPeak(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 2000, Name: "Ashley", Count: 120},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Emily", Count: 80},
+		{State: "CA", Gender: "F", Year: 2001, Name: "Ashley", Count: 120},
+		{State: "CA", Gender: "F", Year: 2001, Name: "Emily", Count: 150},
+		{State: "CA", Gender: "F", Year: 2002, Name: "Ashley", Count: 30},
+		{State: "CA", Gender: "F", Year: 2002, Name: "Emily", Count: 60},
+		{State: "CA", Gender: "M", Year: 2002, Name: "Ashley", Count: 500},
+	}
+
+	peak, err := namesdata.Peak(records, "F", "ashley")
+	if err != nil {
+		t.Fatalf("Peak: %v", err)
+	}
+	if peak.Name != "Ashley" || peak.Year != 2000 || peak.Count != 120 || peak.Rank != 1 {
+		t.Fatalf("unexpected peak: %+v", peak)
+	}
+	if peak.LatestYear != 2002 || peak.LatestCount != 30 || peak.LatestRank != 2 {
+		t.Fatalf("unexpected latest standing: %+v", peak)
+	}
+	if math.Abs(peak.Decline()-0.75) > 1e-9 {
+		t.Fatalf("expected a 75%% decline, got %v", peak.Decline())
+	}
+
+	both, err := namesdata.Peak(records, "", "Ashley")
+	if err != nil {
+		t.Fatalf("Peak both genders: %v", err)
+	}
+	if both.Year != 2002 || both.Count != 530 || both.Decline() != 0 {
+		t.Fatalf("unexpected combined peak: %+v", both)
+	}
+
+	if _, err := namesdata.Peak(records, "F", "Zelda"); err == nil {
+		t.Fatalf("expected error for a missing name")
+	}
+
+	peaks, err := namesdata.PeakMany(records, "F", []string{"Emily", "ashley"})
+	if err != nil {
+		t.Fatalf("PeakMany: %v", err)
+	}
+	if len(peaks) != 2 || peaks[0].Name != "Emily" || peaks[0].Year != 2001 || peaks[1] != peak {
+		t.Fatalf("unexpected peaks: %+v", peaks)
+	}
+	var notFound *namesdata.NameNotFoundError
+	if _, err := namesdata.PeakMany(records, "F", []string{"Emily", "Zelda"}); !errors.As(err, &notFound) || notFound.Name != "Zelda" {
+		t.Fatalf("expected a NameNotFoundError for Zelda, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package namesdata
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Soundex returns the American Soundex code for name (a letter followed by
+// three digits), or an empty string when name contains no letters. Names with
+// the same code sound alike when spoken.
+func Soundex(name string) string {
+	letters := make([]rune, 0, len(name))
+	for _, r := range strings.ToUpper(name) {
+		if r >= 'A' && r <= 'Z' {
+			letters = append(letters, r)
+		}
+	}
+	if len(letters) == 0 {
+		return ""
+	}
+
+	code := []byte{byte(letters[0])}
+	last := soundexDigit(letters[0])
+	for _, r := range letters[1:] {
+		digit := soundexDigit(r)
+		switch {
+		case r == 'H' || r == 'W':
+			// H and W do not separate letters with the same code.
+			continue
+		case digit == 0:
+			last = 0
+			continue
+		case digit != last:
+			code = append(code, '0'+digit)
+			if len(code) == 4 {
+				return string(code)
+			}
+		}
+		last = digit
+	}
+
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+func soundexDigit(r rune) byte {
+	switch r {
+	case 'B', 'F', 'P', 'V':
+		return 1
+	case 'C', 'G', 'J', 'K', 'Q', 'S', 'X', 'Z':
+		return 2
+	case 'D', 'T':
+		return 3
+	case 'L':
+		return 4
+	case 'M', 'N':
+		return 5
+	case 'R':
+		return 6
+	default:
+		return 0
+	}
+}
+
+// EditDistance returns the case-insensitive Levenshtein distance between a and
+// b: the minimum number of single-letter insertions, deletions, or
+// substitutions needed to turn one into the other.
+func EditDistance(a, b string) int {
+	ra := []rune(strings.ToUpper(a))
+	rb := []rune(strings.ToUpper(b))
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if unicode.ToUpper(ra[i-1]) == unicode.ToUpper(rb[j-1]) {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
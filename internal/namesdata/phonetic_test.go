@@ -0,0 +1,41 @@
+package namesdata_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestSoundex(t *testing.T) {
+	cases := map[string]string{
+		"Robert":   "R163",
+		"Rupert":   "R163",
+		"Ashcraft": "A261",
+		"Tymczak":  "T522",
+		"Pfister":  "P236",
+		"Lee":      "L000",
+		"":         "",
+	}
+	for input, want := range cases {
+		if got := namesdata.Soundex(input); got != want {
+			t.Errorf("Soundex(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"Olivia", "olivia", 0},
+		{"Oliva", "Olivia", 1},
+		{"Kaden", "Jayden", 2},
+		{"", "Emma", 4},
+	}
+	for _, tc := range cases {
+		if got := namesdata.EditDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("EditDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package namesdata
+
+import (
+	"errors"
+	"strings"
+)
+
+// RankMatrix holds the rank and count of every qualifying name in every year.
+// Rows follow Names and columns follow Years; a rank of 0 means the name did
+// not appear that year.
+type RankMatrix struct {
+	Years  []int
+	Names  []string
+	Ranks  [][]int
+	Counts [][]int
+}
+
+// YearlyRanks builds a RankMatrix from the records using a single per-year
+// aggregation. Ranks are computed against every name in a year, but only names
+// whose total count across all years is at least minCount are included. Names
+// are ordered by descending total. gender can be "M", "F", or empty for all.
+func YearlyRanks(records []Record, gender string, minCount int) (RankMatrix, error) {
+	yearly := AggregateByYear(records, gender)
+	if len(yearly) == 0 {
+		return RankMatrix{}, ErrNoMatches
+	}
+
+	totals := make(map[string]*NameCount)
+	for _, agg := range yearly {
+		for _, entry := range agg.Names {
+			key := strings.ToUpper(entry.Name)
+			total, ok := totals[key]
+			if !ok {
+				total = &NameCount{Name: entry.Name}
+				totals[key] = total
+			}
+			total.Count += entry.Count
+		}
+	}
+
+	selected := make([]NameCount, 0, len(totals))
+	for _, total := range totals {
+		if total.Count >= minCount {
+			selected = append(selected, *total)
+		}
+	}
+	if len(selected) == 0 {
+		return RankMatrix{}, errors.New("no names meet the minimum count")
+	}
+	sortNameCounts(selected)
+
+	matrix := RankMatrix{
+		Years:  make([]int, len(yearly)),
+		Names:  make([]string, len(selected)),
+		Ranks:  make([][]int, len(selected)),
+		Counts: make([][]int, len(selected)),
+	}
+	for i, agg := range yearly {
+		matrix.Years[i] = agg.Year
+	}
+
+	for row, entry := range selected {
+		key := strings.ToUpper(entry.Name)
+		matrix.Names[row] = entry.Name
+		matrix.Ranks[row] = make([]int, len(yearly))
+		matrix.Counts[row] = make([]int, len(yearly))
+		for col, agg := range yearly {
+			if rank, ok := agg.Ranks[key]; ok {
+				matrix.Ranks[row][col] = rank
+				matrix.Counts[row][col] = agg.Names[rank-1].Count
+			}
+		}
+	}
+
+	return matrix, nil
+}
@@ -0,0 +1,41 @@
+package namesdata_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestYearlyRanks(t *testing.T) {
+	fs := sampleFS()
+	records, err := namesdata.LoadAllRecords(fs)
+	if err != nil {
+		t.Fatalf("LoadAllRecords: %v", err)
+	}
+
+	matrix, err := namesdata.YearlyRanks(records, "F", 200)
+	if err != nil {
+		t.Fatalf("YearlyRanks: %v", err)
+	}
+
+	if len(matrix.Years) != 2 || matrix.Years[0] != 2018 || matrix.Years[1] != 2019 {
+		t.Fatalf("unexpected years: %v", matrix.Years)
+	}
+
+	// Olivia totals 280 and Emma 185, so only Olivia clears the threshold.
+	if len(matrix.Names) != 1 || matrix.Names[0] != "Olivia" {
+		t.Fatalf("unexpected names: %v", matrix.Names)
+	}
+
+	// Emma leads 2018 (95 vs 80) while Olivia leads 2019.
+	if matrix.Ranks[0][0] != 2 || matrix.Ranks[0][1] != 1 {
+		t.Fatalf("unexpected Olivia ranks: %v", matrix.Ranks[0])
+	}
+	if matrix.Counts[0][1] != 200 {
+		t.Fatalf("expected Olivia 2019 count 200, got %d", matrix.Counts[0][1])
+	}
+
+	if _, err := namesdata.YearlyRanks(records, "F", 1000); err == nil {
+		t.Fatalf("expected error when no names meet the threshold")
+	}
+}
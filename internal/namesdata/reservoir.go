@@ -0,0 +1,128 @@
+package namesdata
+
+import (
+	"container/heap"
+	"fmt"
+	"io/fs"
+	"iter"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SampleKFromFS draws k distinct names from the dataset in fsys in a
+// single streaming pass, each draw weighted by aggregated count among the
+// names not yet drawn, exactly as NameSampler.PickUnique would. state may
+// be empty for every state, year 0 covers all years, and gender can be
+// "M", "F", or empty for both. It returns the picks in draw order with
+// their aggregated counts, and the total count of the matching records.
+// When r is nil a new time-seeded RNG is used.
+func SampleKFromFS(fsys fs.FS, state string, year int, gender string, k int, r *rand.Rand) ([]NameCount, int, error) {
+	return SampleK(Records(fsys, Filter{State: state, Gender: gender, From: year, To: year}), k, r)
+}
+
+// SampleK is SampleKFromFS over a record stream. Every record gets an
+// exponential key -ln(U)/count, and a name's key is the smallest of its
+// records' keys, which is distributed as if drawn once against the name's
+// aggregated count. A heap keeps the k names with the smallest keys, so
+// the records never have to be aggregated and sorted, though a running
+// count per name is kept so the picks can report their totals. records is
+// ranged over once, so a one-shot stream works. An error is returned when
+// fewer than k names match.
+func SampleK(records iter.Seq2[Record, error], k int, r *rand.Rand) ([]NameCount, int, error) {
+	if k < 0 {
+		return nil, 0, fmt.Errorf("number of names must not be negative, got %d", k)
+	}
+	rng := r
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	total := 0
+	counts := make(map[string]int)
+	keep := &nameKeyHeap{pos: make(map[string]int, k)}
+	for rec, err := range records {
+		if err != nil {
+			return nil, 0, err
+		}
+		if rec.Count <= 0 {
+			continue
+		}
+		upper := strings.ToUpper(rec.Name)
+		total += rec.Count
+		counts[upper] += rec.Count
+		if k == 0 {
+			continue
+		}
+
+		key := rng.ExpFloat64() / float64(rec.Count)
+		if i, ok := keep.pos[upper]; ok {
+			if key < keep.items[i].key {
+				keep.items[i].key = key
+				heap.Fix(keep, i)
+			}
+			continue
+		}
+		// A name outside the heap whose key cannot beat the largest kept
+		// key could only matter through a smaller key from a later record,
+		// which is checked then.
+		if len(keep.items) < k {
+			heap.Push(keep, nameKey{key: key, upper: upper, name: rec.Name})
+			continue
+		}
+		if key < keep.items[0].key {
+			delete(keep.pos, keep.items[0].upper)
+			keep.items[0] = nameKey{key: key, upper: upper, name: rec.Name}
+			keep.pos[upper] = 0
+			heap.Fix(keep, 0)
+		}
+	}
+
+	if total == 0 {
+		return nil, 0, ErrNoMatches
+	}
+	if k > len(counts) {
+		return nil, 0, fmt.Errorf("cannot draw %d distinct names: only %d match", k, len(counts))
+	}
+
+	items := keep.items
+	sort.Slice(items, func(i, j int) bool { return items[i].key < items[j].key })
+	picks := make([]NameCount, len(items))
+	for i, item := range items {
+		picks[i] = NameCount{Name: item.name, Count: counts[item.upper]}
+	}
+	return picks, total, nil
+}
+
+type nameKey struct {
+	key   float64
+	upper string
+	name  string
+}
+
+// nameKeyHeap is a max-heap of nameKey ordered by key that tracks each
+// name's position, so a kept name's key can be lowered in place.
+type nameKeyHeap struct {
+	items []nameKey
+	pos   map[string]int
+}
+
+func (h *nameKeyHeap) Len() int           { return len(h.items) }
+func (h *nameKeyHeap) Less(i, j int) bool { return h.items[i].key > h.items[j].key }
+func (h *nameKeyHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.pos[h.items[i].upper] = i
+	h.pos[h.items[j].upper] = j
+}
+func (h *nameKeyHeap) Push(x any) {
+	item := x.(nameKey)
+	h.pos[item.upper] = len(h.items)
+	h.items = append(h.items, item)
+}
+func (h *nameKeyHeap) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.pos, item.upper)
+	return item
+}
@@ -0,0 +1,83 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestSampleKFromFS(t *testing.T) {
+	fs := sampleFS()
+
+	picks, total, err := namesdata.SampleKFromFS(fs, "", 2019, "", 4, rand.New(rand.NewSource(5)))
+	if err != nil {
+		t.Fatalf("SampleKFromFS: %v", err)
+	}
+	if total != 520 || len(picks) != 4 {
+		t.Fatalf("expected all four 2019 names out of 520 births, got %d %+v", total, picks)
+	}
+	// Olivia's 200 births are spread over three records in two files.
+	want := map[string]int{"Olivia": 200, "Liam": 160, "Emma": 90, "Noah": 70}
+	for _, pick := range picks {
+		if want[pick.Name] != pick.Count {
+			t.Fatalf("unexpected pick %+v", pick)
+		}
+		delete(want, pick.Name)
+	}
+	if len(want) != 0 {
+		t.Fatalf("expected distinct names, missing %v", want)
+	}
+
+	if _, _, err := namesdata.SampleKFromFS(fs, "", 2019, "", 5, nil); err == nil {
+		t.Fatal("expected an error when k exceeds the distinct names")
+	}
+	if _, _, err := namesdata.SampleKFromFS(fs, "CA", 1900, "", 1, nil); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches, got %v", err)
+	}
+	if picks, _, err := namesdata.SampleKFromFS(fs, "", 0, "", 0, nil); err != nil || len(picks) != 0 {
+		t.Fatalf("expected no picks for k=0, got %+v (%v)", picks, err)
+	}
+}
+
+func TestSampleKSinglePass(t *testing.T) {
+	fs := sampleFS()
+	stream := namesdata.Records(fs, namesdata.Filter{From: 2019, To: 2019})
+	ranged := 0
+	once := func(yield func(namesdata.Record, error) bool) {
+		if ranged++; ranged > 1 {
+			t.Fatal("SampleK ranged over the records more than once")
+		}
+		stream(yield)
+	}
+
+	picks, total, err := namesdata.SampleK(once, 2, rand.New(rand.NewSource(3)))
+	if err != nil || total != 520 || len(picks) != 2 || picks[0].Count == 0 || picks[1].Count == 0 {
+		t.Fatalf("unexpected picks %+v of %d (%v)", picks, total, err)
+	}
+}
+
+func TestSampleKFromFSWeights(t *testing.T) {
+	fs := sampleFS()
+	rng := rand.New(rand.NewSource(42))
+
+	// The first pick should follow the aggregated counts even though
+	// Olivia's births are split across records.
+	trials := 4000
+	lower, upper := binomialConfidenceBounds(trials, 200.0/520.0, 0.99)
+	first := make(map[string]int)
+	for range trials {
+		picks, _, err := namesdata.SampleKFromFS(fs, "", 2019, "", 2, rng)
+		if err != nil {
+			t.Fatalf("SampleKFromFS: %v", err)
+		}
+		if picks[0].Name == picks[1].Name {
+			t.Fatalf("expected distinct picks, got %+v", picks)
+		}
+		first[picks[0].Name]++
+	}
+	if olivia := first["Olivia"]; olivia < lower || olivia > upper {
+		t.Fatalf("Olivia drawn first %d of %d times, outside 99%% interval [%d, %d]", olivia, trials, lower, upper)
+	}
+}
@@ -0,0 +1,102 @@
+package namesdata
+
+import (
+	"math"
+	"strings"
+)
+
+// Roster conflict reasons reported by CheckRoster.
+const (
+	ConflictExact    = "exact"
+	ConflictPhonetic = "phonetic"
+	ConflictSimilar  = "similar"
+)
+
+// RosterConflict describes a roster name that clashes with a candidate.
+type RosterConflict struct {
+	Name   string
+	Reason string
+}
+
+// RosterCheck reports how likely a candidate name is to collide within a
+// group and which existing roster names it clashes with.
+type RosterCheck struct {
+	Name  string
+	Count int
+	Share float64
+	// Collision is the probability that at least one of the other group
+	// members, drawn from the same population, shares the candidate's name.
+	Collision float64
+	Conflicts []RosterConflict
+}
+
+// CollisionProbability returns the chance that at least one of groupSize
+// independent draws lands on a name with the given population share.
+func CollisionProbability(share float64, groupSize int) float64 {
+	if share <= 0 || groupSize <= 0 {
+		return 0
+	}
+	if share >= 1 {
+		return 1
+	}
+	return 1 - math.Pow(1-share, float64(groupSize))
+}
+
+// CheckRoster evaluates each candidate against an existing roster. Shares come
+// from aggregated and total (as returned by AggregateFromFS), the group size is
+// the number of roster names, and conflicts flag exact matches (ignoring
+// case), names with the same Soundex code, and near-duplicates within one
+// edit. A nil candidates checks every roster member against the rest of the
+// roster, skipping only the entry it came from.
+func CheckRoster(aggregated []NameCount, total int, roster, candidates []string) []RosterCheck {
+	counts := make(map[string]NameCount, len(aggregated))
+	for _, entry := range aggregated {
+		counts[strings.ToUpper(entry.Name)] = entry
+	}
+
+	fromRoster := candidates == nil
+	if fromRoster {
+		candidates = roster
+	}
+
+	checks := make([]RosterCheck, 0, len(candidates))
+	for i, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+
+		check := RosterCheck{Name: candidate}
+		if entry, ok := counts[strings.ToUpper(candidate)]; ok {
+			check.Name = entry.Name
+			check.Count = entry.Count
+			if total > 0 {
+				check.Share = float64(entry.Count) / float64(total)
+			}
+		}
+
+		groupSize := 0
+		code := Soundex(candidate)
+		for j, member := range roster {
+			member = strings.TrimSpace(member)
+			if member == "" || (fromRoster && j == i) {
+				continue
+			}
+			groupSize++
+
+			switch {
+			case strings.EqualFold(member, candidate):
+				check.Conflicts = append(check.Conflicts, RosterConflict{Name: member, Reason: ConflictExact})
+			case code != "" && Soundex(member) == code:
+				check.Conflicts = append(check.Conflicts, RosterConflict{Name: member, Reason: ConflictPhonetic})
+			case EditDistance(member, candidate) <= 1:
+				check.Conflicts = append(check.Conflicts, RosterConflict{Name: member, Reason: ConflictSimilar})
+			}
+		}
+
+		check.Collision = CollisionProbability(check.Share, groupSize)
+		checks = append(checks, check)
+	}
+
+	return checks
+}
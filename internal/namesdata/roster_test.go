@@ -0,0 +1,61 @@
+package namesdata_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestCheckRoster(t *testing.T) {
+	aggregated := []namesdata.NameCount{{Name: "Olivia", Count: 140}, {Name: "Emma", Count: 90}, {Name: "Emmy", Count: 10}}
+	roster := []string{"Emma", "Olivya", "Noah"}
+
+	checks := namesdata.CheckRoster(aggregated, 240, roster, []string{"olivia", "Emmy", "Emma"})
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 checks, got %d", len(checks))
+	}
+
+	olivia := checks[0]
+	if olivia.Name != "Olivia" || olivia.Count != 140 {
+		t.Fatalf("unexpected Olivia check: %+v", olivia)
+	}
+	wantCollision := 1 - math.Pow(1-140.0/240.0, 3)
+	if math.Abs(olivia.Collision-wantCollision) > 1e-9 {
+		t.Fatalf("Olivia collision = %f, want %f", olivia.Collision, wantCollision)
+	}
+	if len(olivia.Conflicts) != 1 || olivia.Conflicts[0].Name != "Olivya" || olivia.Conflicts[0].Reason != namesdata.ConflictPhonetic {
+		t.Fatalf("unexpected Olivia conflicts: %+v", olivia.Conflicts)
+	}
+
+	emmy := checks[1]
+	if len(emmy.Conflicts) != 1 || emmy.Conflicts[0].Name != "Emma" {
+		t.Fatalf("expected Emmy to clash with Emma, got %+v", emmy.Conflicts)
+	}
+
+	// An explicit candidate is compared against the whole roster, so Emma
+	// clashes with the roster's own Emma.
+	emma := checks[2]
+	if len(emma.Conflicts) != 1 || emma.Conflicts[0].Name != "Emma" || emma.Conflicts[0].Reason != namesdata.ConflictExact {
+		t.Fatalf("expected Emma to match the roster exactly, got %+v", emma.Conflicts)
+	}
+	if want := namesdata.CollisionProbability(90.0/240.0, 3); math.Abs(emma.Collision-want) > 1e-9 {
+		t.Fatalf("Emma collision = %f, want %f", emma.Collision, want)
+	}
+
+	// Without candidates each roster member is compared against the others
+	// only, and exact matches ignore case.
+	self := namesdata.CheckRoster(aggregated, 240, []string{"Emma", "Noah", "emma"}, nil)
+	if len(self) != 3 {
+		t.Fatalf("expected 3 roster checks, got %d", len(self))
+	}
+	if len(self[0].Conflicts) != 1 || self[0].Conflicts[0].Name != "emma" || self[0].Conflicts[0].Reason != namesdata.ConflictExact {
+		t.Fatalf("expected Emma to match emma exactly, got %+v", self[0].Conflicts)
+	}
+	if len(self[1].Conflicts) != 0 {
+		t.Fatalf("expected no conflicts for Noah, got %+v", self[1].Conflicts)
+	}
+	if want := namesdata.CollisionProbability(90.0/240.0, 2); math.Abs(self[2].Collision-want) > 1e-9 {
+		t.Fatalf("emma collision = %f, want %f", self[2].Collision, want)
+	}
+}
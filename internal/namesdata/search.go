@@ -0,0 +1,70 @@
+package namesdata
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NameMatch is a name that matched a search pattern, with its rank and count
+// among all names for the same filters.
+type NameMatch struct {
+	Rank  int
+	Name  string
+	Count int
+}
+
+// CompileWildcard converts a shell-style wildcard pattern into a
+// case-insensitive regular expression that must match the whole name. `*`
+// matches any run of characters and `?` matches exactly one; everything else
+// is literal.
+func CompileWildcard(pattern string) (*regexp.Regexp, error) {
+	trimmed := strings.TrimSpace(pattern)
+	if trimmed == "" {
+		return nil, errors.New("empty wildcard pattern")
+	}
+
+	var builder strings.Builder
+	builder.WriteString("(?i)^")
+	for _, r := range trimmed {
+		switch r {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	builder.WriteString("$")
+
+	return regexp.Compile(builder.String())
+}
+
+// CompileRegex compiles a regular expression for matching names. Unlike
+// CompileWildcard the expression is used as given, so it is case-sensitive
+// and unanchored unless it says otherwise.
+func CompileRegex(expr string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, errors.New("empty regular expression")
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return re, nil
+}
+
+// SearchNames returns the entries of a sorted aggregate whose names match re,
+// in rank order. Ranks are positions in the full aggregate, so they agree
+// with the top command for the same filters.
+func SearchNames(aggregated []NameCount, re *regexp.Regexp) []NameMatch {
+	matches := make([]NameMatch, 0)
+	for i, entry := range aggregated {
+		if re.MatchString(entry.Name) {
+			matches = append(matches, NameMatch{Rank: i + 1, Name: entry.Name, Count: entry.Count})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,47 @@
+package namesdata_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestSearchNames(t *testing.T) {
+	aggregated := []namesdata.NameCount{
+		{Name: "Olivia", Count: 140},
+		{Name: "Kaitlyn", Count: 95},
+		{Name: "Emma", Count: 90},
+		{Name: "Ophelia", Count: 20},
+		{Name: "Katelyn", Count: 10},
+	}
+
+	wildcard, err := namesdata.CompileWildcard("o*ia")
+	if err != nil {
+		t.Fatalf("CompileWildcard: %v", err)
+	}
+	matches := namesdata.SearchNames(aggregated, wildcard)
+	if len(matches) != 2 || matches[0].Name != "Olivia" || matches[1].Name != "Ophelia" || matches[1].Rank != 4 {
+		t.Fatalf("unexpected wildcard matches: %+v", matches)
+	}
+
+	single, err := namesdata.CompileWildcard("Emm?")
+	if err != nil {
+		t.Fatalf("CompileWildcard: %v", err)
+	}
+	if matches := namesdata.SearchNames(aggregated, single); len(matches) != 1 || matches[0].Rank != 3 {
+		t.Fatalf("unexpected single-character matches: %+v", matches)
+	}
+
+	re, err := namesdata.CompileRegex("^K.*lyn$")
+	if err != nil {
+		t.Fatalf("CompileRegex: %v", err)
+	}
+	matches = namesdata.SearchNames(aggregated, re)
+	if len(matches) != 2 || matches[0].Name != "Kaitlyn" || matches[1].Count != 10 {
+		t.Fatalf("unexpected regex matches: %+v", matches)
+	}
+
+	if _, err := namesdata.CompileRegex("("); err == nil {
+		t.Fatalf("expected error for invalid regex")
+	}
+}
@@ -0,0 +1,35 @@
+package namesdata
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+)
+
+// NewSecureRand returns a *rand.Rand that draws from crypto/rand instead of
+// a seeded generator, for picks that must not be predictable from earlier
+// ones, such as user-facing pseudonyms. Every sampler accepts it in place
+// of a seeded *rand.Rand; its draws cannot be reproduced. It is safe for
+// concurrent use except for its Read method, and it panics if the system's
+// secure random source fails.
+func NewSecureRand() *rand.Rand {
+	return rand.New(cryptoSource{})
+}
+
+// cryptoSource is a rand.Source64 reading from crypto/rand. Seed is a
+// no-op.
+type cryptoSource struct{}
+
+func (s cryptoSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic("namesdata: crypto/rand failed: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+func (cryptoSource) Seed(int64) {}
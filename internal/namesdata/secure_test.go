@@ -0,0 +1,48 @@
+package namesdata_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestNewSecureRand(t *testing.T) {
+	sampler, err := namesdata.NewNameSampler([]namesdata.NameCount{
+		{Name: "Olivia", Count: 140},
+		{Name: "Emma", Count: 90},
+		{Name: "Zoe", Count: 0},
+	})
+	if err != nil {
+		t.Fatalf("NewNameSampler: %v", err)
+	}
+
+	rng := namesdata.NewSecureRand()
+	const trials = 4000
+	olivia := 0
+	for range trials {
+		pick, err := sampler.Pick(rng)
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		if pick.Name == "Zoe" {
+			t.Fatalf("drew a zero-count name")
+		}
+		if pick.Name == "Olivia" {
+			olivia++
+		}
+	}
+	lower, upper := binomialConfidenceBounds(trials, 140.0/230.0, 0.999)
+	if olivia < lower || olivia > upper {
+		t.Fatalf("Olivia drawn %d of %d times, outside [%d, %d]", olivia, trials, lower, upper)
+	}
+
+	picks, err := sampler.PickUnique(2, rng)
+	if err != nil || len(picks) != 2 || picks[0].Name == picks[1].Name {
+		t.Fatalf("unexpected unique picks %+v: %v", picks, err)
+	}
+	for range 100 {
+		if v := rng.Int63(); v < 0 {
+			t.Fatalf("Int63 returned a negative value %d", v)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package namesdata
+
+import (
+	"sort"
+	"strings"
+)
+
+// NameShare is a name's total count together with its average yearly share
+// of births.
+type NameShare struct {
+	NameCount
+	// Share is the mean, over every year in the records, of the name's
+	// fraction of that year's births. Years without the name count as zero.
+	Share float64
+}
+
+// AggregateNamesByShare is AggregateNames ranked by average yearly share
+// instead of total count, so years with more births do not dominate a
+// multi-year ranking. gender can be "M", "F", or empty for all. Ties are
+// broken by count, then name. The returned ranks are 1-based positions keyed
+// by upper-cased name, as RankFromAggregate expects.
+func AggregateNamesByShare(records []Record, gender string) ([]NameShare, map[string]int) {
+	yearly := AggregateByYear(records, gender)
+	entries := make(map[string]*NameShare)
+	for _, agg := range yearly {
+		if agg.Total == 0 {
+			continue
+		}
+		for _, entry := range agg.Names {
+			key := strings.ToUpper(entry.Name)
+			share, ok := entries[key]
+			if !ok {
+				share = &NameShare{NameCount: NameCount{Name: entry.Name}}
+				entries[key] = share
+			}
+			share.Count += entry.Count
+			share.Share += float64(entry.Count) / float64(agg.Total)
+		}
+	}
+
+	shares := make([]NameShare, 0, len(entries))
+	for _, entry := range entries {
+		entry.Share /= float64(len(yearly))
+		shares = append(shares, *entry)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Share != shares[j].Share {
+			return shares[i].Share > shares[j].Share
+		}
+		if shares[i].Count != shares[j].Count {
+			return shares[i].Count > shares[j].Count
+		}
+		return shares[i].Name < shares[j].Name
+	})
+
+	ranks := make(map[string]int, len(shares))
+	for idx, entry := range shares {
+		ranks[strings.ToUpper(entry.Name)] = idx + 1
+	}
+	return shares, ranks
+}
@@ -0,0 +1,49 @@
+package namesdata_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestAggregateNamesByShare(t *testing.T) {
+	records := []namesdata.Record{
+		// A big year where Ava leads on count alone.
+		{State: "CA", Gender: "F", Year: 2000, Name: "Ava", Count: 300},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Mia", Count: 200},
+		{State: "CA", Gender: "F", Year: 2000, Name: "Zoe", Count: 500},
+		// A small year dominated by Mia.
+		{State: "CA", Gender: "F", Year: 2001, Name: "Mia", Count: 90},
+		{State: "CA", Gender: "F", Year: 2001, Name: "Zoe", Count: 10},
+		{State: "CA", Gender: "M", Year: 2001, Name: "Leo", Count: 1000},
+	}
+
+	shares, ranks := namesdata.AggregateNamesByShare(records, "F")
+	if len(shares) != 3 {
+		t.Fatalf("expected 3 names, got %+v", shares)
+	}
+	want := []struct {
+		name  string
+		count int
+		share float64
+	}{
+		{"Mia", 290, (0.2 + 0.9) / 2},
+		{"Zoe", 510, (0.5 + 0.1) / 2},
+		{"Ava", 300, 0.3 / 2},
+	}
+	for i, w := range want {
+		got := shares[i]
+		if got.Name != w.name || got.Count != w.count || math.Abs(got.Share-w.share) > 1e-9 {
+			t.Fatalf("position %d: got %+v, want %s %d %.3f", i, got, w.name, w.count, w.share)
+		}
+	}
+	if ranks["MIA"] != 1 || ranks["AVA"] != 3 {
+		t.Fatalf("unexpected ranks: %v", ranks)
+	}
+
+	// By count alone, Zoe would lead.
+	if top := namesdata.TopNames(records, 0, "F", 1); top[0].Name != "Zoe" {
+		t.Fatalf("expected Zoe to lead by count, got %v", top)
+	}
+}
@@ -0,0 +1,85 @@
+package namesdata
+
+import (
+	"errors"
+	"math"
+)
+
+// Smooth replaces each observed year of a trend with the centered moving
+// average of the window years around it, which must be a positive odd
+// number; near either end the window is cut short to the years available.
+// Counts average with absent years as zero, and a point is present when
+// the name appears anywhere in its window, with the mean of the ranks it
+// had there. The yearly totals are averaged the same way, so shares stay
+// counts over totals. Forecast points are neither averaged nor changed, so
+// smooth before forecasting to project the smoothed trend. A window of 1
+// returns copies of series and totals.
+func Smooth(years []int, series []TrendSeries, totals map[int]int, window int) ([]TrendSeries, map[int]int, error) {
+	if window < 1 || window%2 == 0 {
+		return nil, nil, errors.New("smoothing window must be a positive odd number")
+	}
+	half := window / 2
+
+	var observed []int
+	for i, year := range years {
+		if !isForecastYear(series, i) {
+			observed = append(observed, year)
+		}
+	}
+
+	smoothedTotals := make(map[int]int, len(totals))
+	for year, total := range totals {
+		smoothedTotals[year] = total
+	}
+	for i, year := range observed {
+		lo, hi := max(0, i-half), min(len(observed), i+half+1)
+		sum := 0
+		for _, y := range observed[lo:hi] {
+			sum += totals[y]
+		}
+		smoothedTotals[year] = int(math.Round(float64(sum) / float64(hi-lo)))
+	}
+
+	smoothed := make([]TrendSeries, len(series))
+	for si, s := range series {
+		var idx []int
+		for i, point := range s.Points {
+			if !point.Forecast {
+				idx = append(idx, i)
+			}
+		}
+		points := make([]TrendPoint, len(s.Points))
+		copy(points, s.Points)
+		for j, i := range idx {
+			var count, rankSum, ranked int
+			lo, hi := max(0, j-half), min(len(idx), j+half+1)
+			for _, k := range idx[lo:hi] {
+				if p := s.Points[k]; p.Present {
+					count += p.Count
+					rankSum += p.Rank
+					ranked++
+				}
+			}
+			point := TrendPoint{Year: s.Points[i].Year, Total: smoothedTotals[s.Points[i].Year]}
+			if ranked > 0 {
+				point.Present = true
+				point.Count = int(math.Round(float64(count) / float64(hi-lo)))
+				point.Rank = int(math.Round(float64(rankSum) / float64(ranked)))
+			}
+			points[i] = point
+		}
+		smoothed[si] = TrendSeries{Name: s.Name, Gender: s.Gender, Points: points}
+	}
+	return smoothed, smoothedTotals, nil
+}
+
+// isForecastYear reports whether the points at index i of the series are
+// forecast rather than observed.
+func isForecastYear(series []TrendSeries, i int) bool {
+	for _, s := range series {
+		if i < len(s.Points) && s.Points[i].Forecast {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package namesdata_test
+
+import (
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestSmooth(t *testing.T) {
+	years := []int{2000, 2001, 2002, 2003, 2004}
+	totals := map[int]int{2000: 100, 2001: 200, 2002: 300, 2003: 400, 2004: 500}
+	series := []namesdata.TrendSeries{{Name: "Ava", Points: []namesdata.TrendPoint{
+		{Year: 2000, Rank: 2, Count: 10, Present: true, Total: 100},
+		{Year: 2001, Rank: 4, Count: 20, Present: true, Total: 200},
+		{Year: 2002},
+		{Year: 2003},
+		{Year: 2004},
+	}}}
+
+	smoothed, smoothedTotals, err := namesdata.Smooth(years, series, totals, 3)
+	if err != nil {
+		t.Fatalf("Smooth: %v", err)
+	}
+	if smoothedTotals[2000] != 150 || smoothedTotals[2002] != 300 || smoothedTotals[2004] != 450 {
+		t.Fatalf("unexpected smoothed totals: %v", smoothedTotals)
+	}
+	if totals[2000] != 100 {
+		t.Fatalf("expected the input totals to be left alone, got %v", totals)
+	}
+
+	points := smoothed[0].Points
+	if p := points[0]; !p.Present || p.Count != 15 || p.Rank != 3 || p.Total != 150 {
+		t.Fatalf("unexpected 2000 point: %+v", p)
+	}
+	// 2002's window holds 2001 only, so the absent years pull the count down.
+	if p := points[2]; !p.Present || p.Count != 7 || p.Rank != 4 {
+		t.Fatalf("unexpected 2002 point: %+v", p)
+	}
+	if p := points[3]; p.Present || p.Year != 2003 {
+		t.Fatalf("expected 2003 to stay absent, got %+v", p)
+	}
+	if series[0].Points[2].Present {
+		t.Fatal("expected the input series to be left alone")
+	}
+
+	unchanged, _, err := namesdata.Smooth(years, series, totals, 1)
+	if err != nil || unchanged[0].Points[1] != series[0].Points[1] {
+		t.Fatalf("expected a window of 1 to keep the values, got %+v (%v)", unchanged, err)
+	}
+
+	for _, window := range []int{0, 4, -3} {
+		if _, _, err := namesdata.Smooth(years, series, totals, window); err == nil {
+			t.Fatalf("expected an error for window %d", window)
+		}
+	}
+}
+
+func TestSmoothSkipsForecasts(t *testing.T) {
+	years := []int{2000, 2001, 2002}
+	totals := map[int]int{2000: 100, 2001: 100, 2002: 1000}
+	series := []namesdata.TrendSeries{{Name: "Ava", Points: []namesdata.TrendPoint{
+		{Year: 2000, Rank: 1, Count: 10, Present: true, Total: 100},
+		{Year: 2001, Rank: 1, Count: 20, Present: true, Total: 100},
+		{Year: 2002, Rank: 1, Count: 90, Present: true, Total: 1000, Forecast: true},
+	}}}
+
+	smoothed, smoothedTotals, err := namesdata.Smooth(years, series, totals, 3)
+	if err != nil {
+		t.Fatalf("Smooth: %v", err)
+	}
+	if p := smoothed[0].Points[1]; p.Count != 15 || p.Total != 100 {
+		t.Fatalf("expected the forecast year to stay out of 2001's window, got %+v", p)
+	}
+	if p := smoothed[0].Points[2]; p != series[0].Points[2] || smoothedTotals[2002] != 1000 {
+		t.Fatalf("expected the forecast point to be unchanged, got %+v", p)
+	}
+}
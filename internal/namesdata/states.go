@@ -0,0 +1,260 @@
+package namesdata
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// UnknownStateError reports a state code with no matching dataset file.
+type UnknownStateError struct {
+	State       string
+	Valid       []string
+	Suggestions []string
+}
+
+func (e *UnknownStateError) Error() string {
+	msg := fmt.Sprintf("unknown state %q", e.State)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(e.Suggestions, ", "))
+	}
+	if len(e.Valid) > 0 {
+		msg += fmt.Sprintf("; valid codes: %s", strings.Join(e.Valid, ", "))
+	}
+	return msg
+}
+
+// States returns the sorted state codes available in the dataset, derived
+// from its .TXT file names.
+func States(fsys fs.FS) ([]string, error) {
+	states, _, err := stateFiles(fsys)
+	return states, err
+}
+
+// stateFiles returns the sorted state codes in the dataset along with the
+// file name backing each code.
+func stateFiles(fsys fs.FS) ([]string, map[string]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, nil, fmt.Errorf("read dataset directory: %w", err)
+	}
+
+	states := make([]string, 0, len(entries))
+	files := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		code, ok := strings.CutSuffix(strings.ToUpper(entry.Name()), ".TXT")
+		if !ok || code == "" {
+			continue
+		}
+		if _, dup := files[code]; !dup {
+			states = append(states, code)
+		}
+		files[code] = entry.Name()
+	}
+	sort.Strings(states)
+	return states, files, nil
+}
+
+// ValidateDataset checks that fsys looks like a state dataset: it must hold
+// at least one .TXT file, and the first record of every such file must parse.
+// Files are not read in full, so later malformed lines still surface when the
+// dataset is queried.
+func ValidateDataset(fsys fs.FS) error {
+	states, files, err := stateFiles(fsys)
+	if err != nil {
+		return err
+	}
+	if len(states) == 0 {
+		return errors.New("no state .TXT files found")
+	}
+
+	for _, state := range states {
+		if err := checkFirstRecord(fsys, files[state]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFirstRecord parses the first non-blank line of fileName.
+func checkFirstRecord(fsys fs.FS, fileName string) error {
+	file, err := fsys.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", fileName, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		_, err := parseRecordLine(fileName, line)
+		return err
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan %s: %w", fileName, err)
+	}
+	return fmt.Errorf("no records found in %s", fileName)
+}
+
+// ValidateState checks that state names a dataset file, returning an
+// *UnknownStateError listing the valid codes and closest matches otherwise.
+func ValidateState(fsys fs.FS, state string) error {
+	_, err := stateFileName(fsys, state)
+	return err
+}
+
+// stateFileName resolves a state code to its dataset file name.
+func stateFileName(fsys fs.FS, state string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(state))
+	states, files, err := stateFiles(fsys)
+	if err != nil {
+		return "", err
+	}
+
+	if fileName, ok := files[code]; ok {
+		return fileName, nil
+	}
+
+	return "", &UnknownStateError{State: state, Valid: states, Suggestions: closestStates(code, states)}
+}
+
+// closestStates returns up to three codes within one edit of code.
+func closestStates(code string, states []string) []string {
+	if code == "" {
+		return nil
+	}
+	suggestions := make([]string, 0, 3)
+	for _, candidate := range states {
+		if EditDistance(code, candidate) <= 1 {
+			suggestions = append(suggestions, candidate)
+			if len(suggestions) == 3 {
+				break
+			}
+		}
+	}
+	return suggestions
+}
+
+// StateAggregate holds the ranked name totals for a single state.
+type StateAggregate struct {
+	State string
+	// Names is sorted by descending count, ties broken by name.
+	Names []NameCount
+	Total int
+}
+
+// AggregateByState aggregates every state in the dataset separately, applying
+// the gender filter and per-year weight as AggregateFromFSWeighted does. States
+// with no matching records are included with an empty Names slice. The result
+// is ordered by state code.
+func AggregateByState(fsys fs.FS, gender string, weight YearWeight) ([]StateAggregate, error) {
+	states, err := States(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, errors.New("no records found in dataset")
+	}
+
+	result := make([]StateAggregate, 0, len(states))
+	for _, state := range states {
+		aggregated, total, err := AggregateFromFSWeighted(fsys, state, gender, weight)
+		if err != nil && !errors.Is(err, ErrNoMatches) {
+			return nil, err
+		}
+		result = append(result, StateAggregate{State: state, Names: aggregated, Total: total})
+	}
+
+	return result, nil
+}
+
+// StateShare holds a single name's popularity within one state.
+type StateShare struct {
+	State string
+	Name  string
+	// Rank is the name's 1-based rank within the state.
+	Rank  int
+	Count int
+	// Total is every matching occurrence in the state, the share denominator.
+	Total int
+}
+
+// Share returns the name's fraction of the state's occurrences, or 0 when
+// the state has none.
+func (s StateShare) Share() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Count) / float64(s.Total)
+}
+
+// NameByState locates name (case-insensitive) in each state's aggregate and
+// returns the states where it appears, sorted by share descending with ties
+// broken by state code. The second result lists, in input order, the states
+// with data where the name does not appear. ErrNoMatches is returned when no
+// state has any data and a not-found error when the name appears nowhere.
+func NameByState(states []StateAggregate, name string) ([]StateShare, []string, error) {
+	target := strings.ToUpper(strings.TrimSpace(name))
+	if target == "" {
+		return nil, nil, errors.New("name is required")
+	}
+
+	shares := make([]StateShare, 0, len(states))
+	absent := make([]string, 0)
+	withData := 0
+	for _, state := range states {
+		if state.Total == 0 {
+			continue
+		}
+		withData++
+
+		found := false
+		for idx, entry := range state.Names {
+			if strings.ToUpper(entry.Name) != target {
+				continue
+			}
+			shares = append(shares, StateShare{
+				State: state.State,
+				Name:  entry.Name,
+				Rank:  idx + 1,
+				Count: entry.Count,
+				Total: state.Total,
+			})
+			found = true
+			break
+		}
+		if !found {
+			absent = append(absent, state.State)
+		}
+	}
+
+	if withData == 0 {
+		return nil, nil, ErrNoMatches
+	}
+	if len(shares) == 0 {
+		var candidates []NameCount
+		for _, state := range states {
+			candidates = append(candidates, state.Names...)
+		}
+		return nil, nil, newNameNotFoundError(strings.TrimSpace(name), candidates)
+	}
+
+	sort.SliceStable(shares, func(i, j int) bool {
+		si, sj := shares[i].Share(), shares[j].Share()
+		if si != sj {
+			return si > sj
+		}
+		return shares[i].State < shares[j].State
+	})
+
+	return shares, absent, nil
+}
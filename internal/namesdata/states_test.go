@@ -0,0 +1,117 @@
+package namesdata_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestStates(t *testing.T) {
+	states, err := namesdata.States(sampleFS())
+	if err != nil {
+		t.Fatalf("States: %v", err)
+	}
+	if strings.Join(states, ",") != "CA,NY" {
+		t.Fatalf("unexpected states: %v", states)
+	}
+}
+
+func TestValidateState(t *testing.T) {
+	fs := sampleFS()
+
+	if err := namesdata.ValidateState(fs, " ca "); err != nil {
+		t.Fatalf("expected CA to be valid, got %v", err)
+	}
+
+	err := namesdata.ValidateState(fs, "CX")
+	var unknown *namesdata.UnknownStateError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expected UnknownStateError, got %v", err)
+	}
+	if len(unknown.Suggestions) != 1 || unknown.Suggestions[0] != "CA" {
+		t.Fatalf("unexpected suggestions: %v", unknown.Suggestions)
+	}
+	if !strings.Contains(err.Error(), "did you mean CA?") || !strings.Contains(err.Error(), "valid codes: CA, NY") {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+
+	if _, err := namesdata.LoadStateRecords(fs, "ZZ"); !errors.As(err, &unknown) {
+		t.Fatalf("expected LoadStateRecords to report an unknown state, got %v", err)
+	}
+	if _, _, err := namesdata.AggregateFromFS(fs, "ZZ", 0, ""); !errors.As(err, &unknown) {
+		t.Fatalf("expected AggregateFromFS to report an unknown state, got %v", err)
+	}
+}
+
+func TestAggregateByState(t *testing.T) {
+	states, err := namesdata.AggregateByState(sampleFS(), "M", nil)
+	if err != nil {
+		t.Fatalf("AggregateByState: %v", err)
+	}
+	if len(states) != 2 || states[0].State != "CA" || states[1].State != "NY" {
+		t.Fatalf("unexpected states: %+v", states)
+	}
+	if states[0].Total != 250 || states[0].Names[0].Name != "Liam" || states[0].Names[0].Count != 180 {
+		t.Fatalf("unexpected CA aggregate: %+v", states[0])
+	}
+	if states[1].Total != 65 {
+		t.Fatalf("unexpected NY total: %d", states[1].Total)
+	}
+}
+
+func TestNameByState(t *testing.T) {
+	states, err := namesdata.AggregateByState(sampleFS(), "F", nil)
+	if err != nil {
+		t.Fatalf("AggregateByState: %v", err)
+	}
+
+	shares, absent, err := namesdata.NameByState(states, "emma")
+	if err != nil {
+		t.Fatalf("NameByState: %v", err)
+	}
+	if len(shares) != 2 || len(absent) != 0 {
+		t.Fatalf("unexpected result: %+v absent=%v", shares, absent)
+	}
+	// NY: 45 of 105 female births; CA: 140 of 360.
+	if shares[0].State != "NY" || shares[0].Rank != 2 || shares[0].Count != 45 || shares[0].Total != 105 {
+		t.Fatalf("unexpected NY share: %+v", shares[0])
+	}
+	if shares[1].State != "CA" || shares[1].Rank != 2 || shares[1].Count != 140 {
+		t.Fatalf("unexpected CA share: %+v", shares[1])
+	}
+	if shares[0].Share() <= shares[1].Share() {
+		t.Fatalf("expected shares in descending order: %+v", shares)
+	}
+
+	male, err := namesdata.AggregateByState(sampleFS(), "M", nil)
+	if err != nil {
+		t.Fatalf("AggregateByState: %v", err)
+	}
+	shares, absent, err = namesdata.NameByState(male, "Noah")
+	if err != nil {
+		t.Fatalf("NameByState Noah: %v", err)
+	}
+	if len(shares) != 1 || shares[0].State != "CA" || strings.Join(absent, ",") != "NY" {
+		t.Fatalf("unexpected Noah result: %+v absent=%v", shares, absent)
+	}
+
+	if _, _, err := namesdata.NameByState(male, "Zelda"); err == nil {
+		t.Fatalf("expected error for missing name")
+	}
+}
+
+func TestValidateDataset(t *testing.T) {
+	if err := namesdata.ValidateDataset(sampleFS()); err != nil {
+		t.Fatalf("ValidateDataset: %v", err)
+	}
+	if err := namesdata.ValidateDataset(fstest.MapFS{"README.md": {Data: []byte("hi")}}); err == nil {
+		t.Fatalf("expected error for a dataset without state files")
+	}
+	bad := fstest.MapFS{"CA.TXT": {Data: []byte("\nCA,F,year,Olivia,5\n")}}
+	if err := namesdata.ValidateDataset(bad); err == nil || !strings.Contains(err.Error(), "parse year") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
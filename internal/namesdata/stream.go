@@ -0,0 +1,132 @@
+package namesdata
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"iter"
+	"strings"
+)
+
+// errStopIteration unwinds a dataset walk when an iterator's consumer stops
+// early.
+var errStopIteration = errors.New("stop iteration")
+
+// Filter selects records while streaming. Zero-valued fields match every
+// record.
+type Filter struct {
+	// State limits the stream to one state's file. For the national dataset
+	// it must be empty.
+	State string
+	// Gender is "M", "F", or empty for both.
+	Gender string
+	// From and To are inclusive year bounds; zero leaves a bound open.
+	From, To int
+	// Name matches a single name, case-insensitively.
+	Name string
+}
+
+// Match reports whether rec satisfies every field of the filter.
+func (f Filter) Match(rec Record) bool {
+	if state := strings.TrimSpace(f.State); state != "" && !strings.EqualFold(rec.State, state) {
+		return false
+	}
+	if gender := strings.TrimSpace(f.Gender); gender != "" && !strings.EqualFold(rec.Gender, gender) {
+		return false
+	}
+	if f.From != 0 && rec.Year < f.From {
+		return false
+	}
+	if f.To != 0 && rec.Year > f.To {
+		return false
+	}
+	if name := strings.TrimSpace(f.Name); name != "" && !strings.EqualFold(rec.Name, name) {
+		return false
+	}
+	return true
+}
+
+// Records streams the state dataset's records that match filter, reading one
+// file (or index block) at a time so the full dataset is never held in
+// memory. A read or parse error is yielded once with a zero Record and ends
+// the stream. The sequence can be ranged over more than once; each pass
+// re-reads the dataset.
+func Records(fsys fs.FS, filter Filter) iter.Seq2[Record, error] {
+	return RecordsContext(context.Background(), fsys, filter)
+}
+
+// RecordsContext is Records, yielding ctx's error and ending the stream once
+// ctx is done.
+func RecordsContext(ctx context.Context, fsys fs.FS, filter Filter) iter.Seq2[Record, error] {
+	return streamRecords(walkContext(ctx, func(fn func(Record) error) error {
+		return walkRecords(fsys, filter.State, fn)
+	}), filter)
+}
+
+// NationalRecords is Records for the national dataset.
+func NationalRecords(fsys fs.FS, filter Filter) iter.Seq2[Record, error] {
+	return NationalRecordsContext(context.Background(), fsys, filter)
+}
+
+// NationalRecordsContext is RecordsContext for the national dataset.
+func NationalRecordsContext(ctx context.Context, fsys fs.FS, filter Filter) iter.Seq2[Record, error] {
+	return streamRecords(walkContext(ctx, func(fn func(Record) error) error {
+		return walkNationalRecords(fsys, fn)
+	}), filter)
+}
+
+// SliceRecords adapts already loaded records to the streaming API.
+func SliceRecords(records []Record) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for _, rec := range records {
+			if !yield(rec, nil) {
+				return
+			}
+		}
+	}
+}
+
+// contextCheckInterval is how many records a context-aware walk passes
+// between cancellation checks, keeping the check off the per-line path.
+const contextCheckInterval = 4096
+
+// walkContext wraps walk so it stops with ctx's error once ctx is done. The
+// context is checked before the walk starts and then every
+// contextCheckInterval records, which bounds the work done after
+// cancellation to a fraction of one file.
+func walkContext(ctx context.Context, walk func(func(Record) error) error) func(func(Record) error) error {
+	return func(fn func(Record) error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		seen := 0
+		return walk(func(rec Record) error {
+			seen++
+			if seen%contextCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			return fn(rec)
+		})
+	}
+}
+
+// streamRecords turns a callback-style walk into an iterator, applying
+// filter to every record.
+func streamRecords(walk func(func(Record) error) error, filter Filter) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		err := walk(func(rec Record) error {
+			if !filter.Match(rec) {
+				return nil
+			}
+			if !yield(rec, nil) {
+				return errStopIteration
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(Record{}, err)
+		}
+	}
+}
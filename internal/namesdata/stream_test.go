@@ -0,0 +1,133 @@
+package namesdata_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/curtiscovington/ssa-names/data/namesbystate"
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestRecordsFilter(t *testing.T) {
+	fs := sampleFS()
+
+	total := 0
+	for rec, err := range namesdata.Records(fs, namesdata.Filter{Gender: "f", From: 2019, Name: "olivia"}) {
+		if err != nil {
+			t.Fatalf("Records: %v", err)
+		}
+		if rec.Name != "Olivia" || rec.Year != 2019 {
+			t.Fatalf("unexpected record: %+v", rec)
+		}
+		total += rec.Count
+	}
+	if total != 200 {
+		t.Fatalf("expected 200 Olivias in 2019, got %d", total)
+	}
+
+	seen := 0
+	for _, err := range namesdata.Records(fs, namesdata.Filter{State: "CA"}) {
+		if err != nil {
+			t.Fatalf("Records: %v", err)
+		}
+		seen++
+		if seen == 3 {
+			break
+		}
+	}
+	if seen != 3 {
+		t.Fatalf("expected to stop after 3 records, got %d", seen)
+	}
+
+	bad := fstest.MapFS{"CA.TXT": {Data: []byte("CA,F,2019,Olivia,5\nbroken\n")}}
+	var got error
+	count := 0
+	for _, err := range namesdata.Records(bad, namesdata.Filter{}) {
+		if err != nil {
+			got = err
+			break
+		}
+		count++
+	}
+	if got == nil || count != 1 {
+		t.Fatalf("expected one record then an error, got %d records and %v", count, got)
+	}
+
+	for _, err := range namesdata.Records(fs, namesdata.Filter{State: "ZZ"}) {
+		var unknown *namesdata.UnknownStateError
+		if !errors.As(err, &unknown) {
+			t.Fatalf("expected UnknownStateError, got %v", err)
+		}
+	}
+}
+
+func TestTrendSeqMatchesTrend(t *testing.T) {
+	fs := sampleFS()
+	records, err := namesdata.LoadAllRecords(fs)
+	if err != nil {
+		t.Fatalf("LoadAllRecords: %v", err)
+	}
+
+	years, series, totals, err := namesdata.Trend(records, "F", []string{"Emma"}, namesdata.YearRange{})
+	if err != nil {
+		t.Fatalf("Trend: %v", err)
+	}
+	streamYears, streamSeries, streamTotals, err := namesdata.TrendSeq(namesdata.Records(fs, namesdata.Filter{}), "F", []string{"Emma"}, namesdata.YearRange{})
+	if err != nil {
+		t.Fatalf("TrendSeq: %v", err)
+	}
+
+	if len(years) != len(streamYears) || totals[2019] != streamTotals[2019] {
+		t.Fatalf("mismatched years or totals: %v %v / %v %v", years, totals, streamYears, streamTotals)
+	}
+	for i, point := range series[0].Points {
+		if streamSeries[0].Points[i] != point {
+			t.Fatalf("point %d differs: %+v vs %+v", i, point, streamSeries[0].Points[i])
+		}
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	fs := sampleFS()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := namesdata.LoadAllRecordsContext(cancelled, fs); !errors.Is(err, context.Canceled) {
+		t.Fatalf("LoadAllRecordsContext: expected context.Canceled, got %v", err)
+	}
+	if _, err := namesdata.LoadStateRecordsContext(cancelled, fs, "CA"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("LoadStateRecordsContext: expected context.Canceled, got %v", err)
+	}
+	if _, _, err := namesdata.AggregateFromFSContext(cancelled, fs, "", 2019, "F"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("AggregateFromFSContext: expected context.Canceled, got %v", err)
+	}
+
+	// Cancelling mid-scan ends the stream within one check interval.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	seen := 0
+	var streamErr error
+	for _, err := range namesdata.RecordsContext(ctx, namesbystate.Files, namesdata.Filter{}) {
+		if err != nil {
+			streamErr = err
+			break
+		}
+		seen++
+		if seen == 1 {
+			cancel()
+		}
+	}
+	if !errors.Is(streamErr, context.Canceled) {
+		t.Fatalf("expected the stream to end with context.Canceled, got %v after %d records", streamErr, seen)
+	}
+	if seen > 10_000 {
+		t.Fatalf("expected the stream to stop soon after cancellation, read %d records", seen)
+	}
+
+	records, err := namesdata.LoadAllRecordsContext(context.Background(), fs)
+	if err != nil || len(records) != 11 {
+		t.Fatalf("LoadAllRecordsContext: %d records, %v", len(records), err)
+	}
+}
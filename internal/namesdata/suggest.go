@@ -0,0 +1,74 @@
+package namesdata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuggestions is how many names a NameNotFoundError suggests.
+const maxSuggestions = 3
+
+// NameNotFoundError reports a name absent from the data for a query,
+// together with the closest names that are present.
+type NameNotFoundError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *NameNotFoundError) Error() string {
+	msg := fmt.Sprintf("name %q not found for the provided filters", e.Name)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(e.Suggestions, ", "))
+	}
+	return msg
+}
+
+// newNameNotFoundError builds a NameNotFoundError suggesting the names in
+// candidates closest to name.
+func newNameNotFoundError(name string, candidates []NameCount) *NameNotFoundError {
+	return &NameNotFoundError{Name: name, Suggestions: ClosestNames(candidates, name, maxSuggestions)}
+}
+
+// ClosestNames returns up to limit distinct names from aggregated that are
+// within a small edit distance of name: one edit for names of up to four
+// letters, two for longer ones. Closer names come first, and names at the
+// same distance keep their order in aggregated, so with a ranked aggregate
+// the more popular spelling wins. The name itself is never suggested.
+func ClosestNames(aggregated []NameCount, name string, limit int) []string {
+	target := strings.TrimSpace(name)
+	if target == "" || limit <= 0 {
+		return nil
+	}
+	maxDistance := 2
+	if utf8.RuneCountInString(target) <= 4 {
+		maxDistance = 1
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var matches []candidate
+	seen := map[string]bool{strings.ToUpper(target): true}
+	for _, entry := range aggregated {
+		key := strings.ToUpper(entry.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if d := EditDistance(target, entry.Name); d <= maxDistance {
+			matches = append(matches, candidate{name: entry.Name, distance: d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	suggestions := make([]string, 0, min(limit, len(matches)))
+	for _, match := range matches[:min(limit, len(matches))] {
+		suggestions = append(suggestions, match.name)
+	}
+	return suggestions
+}
@@ -0,0 +1,41 @@
+package namesdata_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestClosestNames(t *testing.T) {
+	aggregated := []namesdata.NameCount{
+		{Name: "Olivia", Count: 500},
+		{Name: "Oliver", Count: 400},
+		{Name: "Olive", Count: 300},
+		{Name: "Liv", Count: 200},
+		{Name: "Olivea", Count: 100},
+		{Name: "OLIVIA", Count: 50},
+	}
+
+	got := namesdata.ClosestNames(aggregated, "Oliva", 3)
+	if want := "Olivia, Olive, Olivea"; strings.Join(got, ", ") != want {
+		t.Fatalf("ClosestNames(Oliva) = %v, want %s", got, want)
+	}
+	if got := namesdata.ClosestNames(aggregated, "Lev", 3); strings.Join(got, ", ") != "Liv" {
+		t.Fatalf("ClosestNames(Lev) = %v, want [Liv]", got)
+	}
+	if got := namesdata.ClosestNames(aggregated, "Bartholomew", 3); len(got) != 0 {
+		t.Fatalf("expected no suggestions, got %v", got)
+	}
+
+	ranks := map[string]int{"OLIVIA": 1, "OLIVER": 2, "OLIVE": 3, "LIV": 4, "OLIVEA": 5}
+	_, _, err := namesdata.RankFromAggregate(aggregated, ranks, "Oliva")
+	var notFound *namesdata.NameNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected a NameNotFoundError, got %v", err)
+	}
+	if want := `name "Oliva" not found for the provided filters (did you mean Olivia, Olive, Olivea?)`; err.Error() != want {
+		t.Fatalf("unexpected error:\n%s\nwant:\n%s", err, want)
+	}
+}
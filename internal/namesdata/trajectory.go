@@ -0,0 +1,211 @@
+package namesdata
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"sort"
+	"strings"
+)
+
+// TrajectoryMethod selects how Similar compares two trajectories.
+type TrajectoryMethod string
+
+const (
+	// TrajectoryCosine compares the curves year by year; its distance is one
+	// minus their cosine similarity, so names rising and falling in the same
+	// years score close to zero.
+	TrajectoryCosine TrajectoryMethod = "cosine"
+	// TrajectoryDTW uses dynamic time warping over the peak-normalized
+	// curves, so a name with the same rise and fall a few years earlier or
+	// later still matches closely.
+	TrajectoryDTW TrajectoryMethod = "dtw"
+)
+
+// DTWWindow is the most years dynamic time warping may shift one curve
+// against the other.
+const DTWWindow = 10
+
+// ParseTrajectoryMethod validates a method name, case-insensitively.
+func ParseTrajectoryMethod(s string) (TrajectoryMethod, error) {
+	switch method := TrajectoryMethod(strings.ToLower(strings.TrimSpace(s))); method {
+	case TrajectoryCosine, TrajectoryDTW:
+		return method, nil
+	}
+	return "", fmt.Errorf("unsupported trajectory method %q (expected cosine or dtw)", s)
+}
+
+// Trajectory is one name's popularity curve.
+type Trajectory struct {
+	Name  string
+	Total int
+	// Curve holds the name's share of births in each of the index's years,
+	// scaled so the peak year is 1.
+	Curve []float64
+	// PeakYear is the year of the highest share.
+	PeakYear int
+}
+
+// TrajectoryIndex holds the popularity curve of every name over a common
+// span of years, for finding names whose popularity rose and fell alike.
+type TrajectoryIndex struct {
+	// Years is the chronological span every curve covers.
+	Years []int
+	// entries is sorted by descending total, ties broken by name.
+	entries []Trajectory
+	norms   []float64
+	lookup  map[string]int
+}
+
+// BuildTrajectoryIndex computes each name's share of births per year in
+// records. gender can be "M", "F", or empty for all. ErrNoMatches is
+// returned when no records match.
+func BuildTrajectoryIndex(records iter.Seq2[Record, error], gender string) (*TrajectoryIndex, error) {
+	acc := newYearAccumulator(gender)
+	for rec, err := range records {
+		if err != nil {
+			return nil, err
+		}
+		acc.add(rec)
+	}
+	yearly := acc.result()
+	if len(yearly) == 0 {
+		return nil, ErrNoMatches
+	}
+
+	idx := &TrajectoryIndex{Years: make([]int, len(yearly)), lookup: make(map[string]int)}
+	for i, agg := range yearly {
+		idx.Years[i] = agg.Year
+		for _, entry := range agg.Names {
+			key := strings.ToUpper(entry.Name)
+			pos, ok := idx.lookup[key]
+			if !ok {
+				pos = len(idx.entries)
+				idx.lookup[key] = pos
+				idx.entries = append(idx.entries, Trajectory{Name: entry.Name, Curve: make([]float64, len(yearly))})
+			}
+			idx.entries[pos].Total += entry.Count
+			idx.entries[pos].Curve[i] = float64(entry.Count) / float64(agg.Total)
+		}
+	}
+
+	sort.Slice(idx.entries, func(i, j int) bool {
+		if idx.entries[i].Total != idx.entries[j].Total {
+			return idx.entries[i].Total > idx.entries[j].Total
+		}
+		return idx.entries[i].Name < idx.entries[j].Name
+	})
+	idx.norms = make([]float64, len(idx.entries))
+	for pos := range idx.entries {
+		t := &idx.entries[pos]
+		idx.lookup[strings.ToUpper(t.Name)] = pos
+
+		peak := 0
+		for i, share := range t.Curve {
+			if share > t.Curve[peak] {
+				peak = i
+			}
+		}
+		t.PeakYear = idx.Years[peak]
+		scale := t.Curve[peak]
+		sum := 0.0
+		for i := range t.Curve {
+			t.Curve[i] /= scale
+			sum += t.Curve[i] * t.Curve[i]
+		}
+		idx.norms[pos] = math.Sqrt(sum)
+	}
+	return idx, nil
+}
+
+// Len returns the number of names in the index.
+func (idx *TrajectoryIndex) Len() int {
+	return len(idx.entries)
+}
+
+// Lookup returns the trajectory of name, case-insensitively.
+func (idx *TrajectoryIndex) Lookup(name string) (Trajectory, bool) {
+	pos, ok := idx.lookup[strings.ToUpper(strings.TrimSpace(name))]
+	if !ok {
+		return Trajectory{}, false
+	}
+	return idx.entries[pos], true
+}
+
+// SimilarTrajectory is a name whose curve resembles the query's.
+type SimilarTrajectory struct {
+	Trajectory
+	// Distance is the method's dissimilarity; lower is more alike.
+	Distance float64
+}
+
+// Similar returns up to limit names whose curves are closest to name's under
+// method, nearest first with ties broken by total births. Names with fewer
+// than minTotal births are skipped, as their curves are mostly noise. A
+// NameNotFoundError is returned when name is not in the index.
+func (idx *TrajectoryIndex) Similar(name string, method TrajectoryMethod, limit, minTotal int) ([]SimilarTrajectory, error) {
+	if limit < 1 {
+		return nil, errors.New("limit must be at least 1")
+	}
+	pos, ok := idx.lookup[strings.ToUpper(strings.TrimSpace(name))]
+	if !ok {
+		candidates := make([]NameCount, len(idx.entries))
+		for i, t := range idx.entries {
+			candidates[i] = NameCount{Name: t.Name, Count: t.Total}
+		}
+		return nil, newNameNotFoundError(strings.TrimSpace(name), candidates)
+	}
+	target := idx.entries[pos]
+
+	var matches []SimilarTrajectory
+	for i, candidate := range idx.entries {
+		if i == pos || candidate.Total < minTotal {
+			continue
+		}
+		var distance float64
+		switch method {
+		case TrajectoryCosine:
+			dot := 0.0
+			for y, v := range target.Curve {
+				dot += v * candidate.Curve[y]
+			}
+			distance = 1 - dot/(idx.norms[pos]*idx.norms[i])
+		case TrajectoryDTW:
+			distance = dtwDistance(target.Curve, candidate.Curve, DTWWindow)
+		default:
+			return nil, fmt.Errorf("unsupported trajectory method %q", method)
+		}
+		matches = append(matches, SimilarTrajectory{Trajectory: candidate, Distance: max(distance, 0)})
+	}
+
+	// entries is already ordered by total, so a stable sort keeps the more
+	// popular name first among equal distances.
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Distance < matches[j].Distance })
+	return matches[:min(limit, len(matches))], nil
+}
+
+// dtwDistance is the dynamic time warping distance between equal-length
+// curves a and b with steps shifted at most window positions, averaged over
+// the curve length.
+func dtwDistance(a, b []float64, window int) float64 {
+	n := len(a)
+	inf := math.Inf(1)
+	prev := make([]float64, n+1)
+	curr := make([]float64, n+1)
+	for j := range prev {
+		prev[j] = inf
+	}
+	prev[0] = 0
+	for i := 1; i <= n; i++ {
+		for j := range curr {
+			curr[j] = inf
+		}
+		for j := max(1, i-window); j <= min(n, i+window); j++ {
+			best := min(prev[j], curr[j-1], prev[j-1])
+			curr[j] = math.Abs(a[i-1]-b[j-1]) + best
+		}
+		prev, curr = curr, prev
+	}
+	return prev[n] / float64(n)
+}
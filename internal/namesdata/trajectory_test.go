@@ -0,0 +1,68 @@
+package namesdata_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestTrajectorySimilar(t *testing.T) {
+	curves := map[string][]int{
+		"Mildred": {10, 50, 100, 50, 10},
+		"Edna":    {20, 100, 200, 100, 20},
+		"Gladys":  {10, 10, 50, 100, 50},
+		"Ava":     {100, 50, 10, 5, 1},
+		"Zoe":     {1, 5, 10, 50, 100},
+		"Rare":    {0, 1, 2, 1, 0},
+	}
+	var records []namesdata.Record
+	for name, counts := range curves {
+		for i, count := range counts {
+			if count > 0 {
+				records = append(records, namesdata.Record{State: "CA", Gender: "F", Year: 2000 + i, Name: name, Count: count})
+			}
+		}
+	}
+
+	idx, err := namesdata.BuildTrajectoryIndex(namesdata.SliceRecords(records), "F")
+	if err != nil {
+		t.Fatalf("BuildTrajectoryIndex: %v", err)
+	}
+	if idx.Len() != 6 || len(idx.Years) != 5 {
+		t.Fatalf("unexpected index: %d names over %v", idx.Len(), idx.Years)
+	}
+	mildred, ok := idx.Lookup("mildred")
+	if !ok || mildred.PeakYear != 2002 || mildred.Total != 220 || mildred.Curve[2] != 1 {
+		t.Fatalf("unexpected Mildred trajectory: %+v", mildred)
+	}
+
+	cosine, err := idx.Similar("Mildred", namesdata.TrajectoryCosine, 2, 10)
+	if err != nil {
+		t.Fatalf("Similar cosine: %v", err)
+	}
+	if len(cosine) != 2 || cosine[0].Name != "Edna" || cosine[0].Distance > 1e-9 || cosine[1].Name != "Gladys" {
+		t.Fatalf("unexpected cosine matches: %+v", cosine)
+	}
+
+	dtw, err := idx.Similar("Mildred", namesdata.TrajectoryDTW, 10, 10)
+	if err != nil {
+		t.Fatalf("Similar dtw: %v", err)
+	}
+	if len(dtw) != 4 || dtw[0].Name != "Edna" || dtw[1].Name != "Gladys" {
+		t.Fatalf("unexpected dtw matches: %+v", dtw)
+	}
+	for _, match := range dtw {
+		if match.Name == "Rare" {
+			t.Fatalf("expected names under the minimum total to be skipped: %+v", dtw)
+		}
+	}
+
+	var notFound *namesdata.NameNotFoundError
+	if _, err := idx.Similar("Mildrid", namesdata.TrajectoryCosine, 5, 0); !errors.As(err, &notFound) || len(notFound.Suggestions) == 0 {
+		t.Fatalf("expected a NameNotFoundError with suggestions, got %v", err)
+	}
+	if _, err := namesdata.ParseTrajectoryMethod("euclid"); err == nil {
+		t.Fatal("expected an error for an unknown method")
+	}
+}
@@ -0,0 +1,128 @@
+package namesdata
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// UnisexIndex returns min(Female, Male) / max(Female, Male): 1 for a name
+// given equally to girls and boys, falling toward 0 as one gender
+// dominates. It is 0 when the name has no occurrences.
+func (g GenderSplit) UnisexIndex() float64 {
+	high := max(g.Female, g.Male)
+	if high == 0 {
+		return 0
+	}
+	return float64(min(g.Female, g.Male)) / float64(high)
+}
+
+// RankUnisex keeps the splits whose total is at least minCount and sorts
+// them by UnisexIndex, most balanced first, breaking ties by total count
+// and then by name.
+func RankUnisex(splits []GenderSplit, minCount int) []GenderSplit {
+	ranked := make([]GenderSplit, 0)
+	for _, split := range splits {
+		if split.Total() > 0 && split.Total() >= minCount {
+			ranked = append(ranked, split)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.UnisexIndex() != b.UnisexIndex() {
+			return a.UnisexIndex() > b.UnisexIndex()
+		}
+		if a.Total() != b.Total() {
+			return a.Total() > b.Total()
+		}
+		return a.Name < b.Name
+	})
+	return ranked
+}
+
+// GenderSplitsByYear returns name's (case-insensitive) split for every year
+// in records it appears in, in chronological order, tracing how its gender
+// association changes over time. A *NameNotFoundError is returned when the
+// name never appears.
+func GenderSplitsByYear(records []Record, name string) ([]GenderSplit, error) {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if key == "" {
+		return nil, errors.New("name is required")
+	}
+
+	byYear := make(map[int]*GenderSplit)
+	for _, rec := range records {
+		if strings.ToUpper(rec.Name) != key {
+			continue
+		}
+		split, ok := byYear[rec.Year]
+		if !ok {
+			split = &GenderSplit{Name: rec.Name, Year: rec.Year}
+			byYear[rec.Year] = split
+		}
+		switch strings.ToUpper(rec.Gender) {
+		case "F":
+			split.Female += rec.Count
+		case "M":
+			split.Male += rec.Count
+		}
+	}
+	if len(byYear) == 0 {
+		aggregated, _ := AggregateNames(records, 0, "")
+		return nil, newNameNotFoundError(name, aggregated)
+	}
+
+	splits := make([]GenderSplit, 0, len(byYear))
+	for _, split := range byYear {
+		splits = append(splits, *split)
+	}
+	sort.Slice(splits, func(i, j int) bool {
+		return splits[i].Year < splits[j].Year
+	})
+	return splits, nil
+}
+
+// GenderRatio is a name's female and male counts in every year it appears
+// and overall.
+type GenderRatio struct {
+	Name string
+	// Years holds the split for each year the name appears in, in
+	// chronological order.
+	Years   []GenderSplit
+	Overall GenderSplit
+	// Crossover is the most recent year the name's majority gender changed
+	// from the previous year with a majority, or 0 when it never changed.
+	// Evenly split years have no majority.
+	Crossover int
+}
+
+// NameGenderRatio aggregates name (case-insensitive) across both genders for
+// every year in records, as GenderSplitsByYear does, and totals the years.
+func NameGenderRatio(records []Record, name string) (GenderRatio, error) {
+	years, err := GenderSplitsByYear(records, name)
+	if err != nil {
+		return GenderRatio{}, err
+	}
+
+	ratio := GenderRatio{Name: years[len(years)-1].Name, Years: years}
+	ratio.Overall.Name = ratio.Name
+	majority := 0
+	for _, split := range years {
+		ratio.Overall.Female += split.Female
+		ratio.Overall.Male += split.Male
+		current := 0
+		switch {
+		case split.Female > split.Male:
+			current = 1
+		case split.Male > split.Female:
+			current = -1
+		default:
+			continue
+		}
+		if majority != 0 && current != majority {
+			ratio.Crossover = split.Year
+		}
+		majority = current
+	}
+	return ratio, nil
+}
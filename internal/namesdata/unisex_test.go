@@ -0,0 +1,63 @@
+package namesdata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestUnisexIndex(t *testing.T) {
+	records := []namesdata.Record{
+		{Gender: "M", Year: 1990, Name: "Riley", Count: 80},
+		{Gender: "F", Year: 1990, Name: "Riley", Count: 20},
+		{Gender: "F", Year: 2019, Name: "Riley", Count: 60},
+		{Gender: "M", Year: 2019, Name: "RILEY", Count: 40},
+		{Gender: "F", Year: 2019, Name: "Quinn", Count: 5},
+		{Gender: "M", Year: 2019, Name: "Quinn", Count: 5},
+		{Gender: "F", Year: 2019, Name: "Emma", Count: 300},
+	}
+
+	splits := namesdata.AggregateByName(records, 2019)
+	if splits[0].Year != 2019 {
+		t.Fatalf("expected splits for 2019, got %+v", splits[0])
+	}
+	ranked := namesdata.RankUnisex(splits, 10)
+	if len(ranked) != 3 || ranked[0].Name != "Quinn" || ranked[1].Name != "Riley" || ranked[2].Name != "Emma" {
+		t.Fatalf("unexpected ranking: %+v", ranked)
+	}
+	if idx := ranked[1].UnisexIndex(); math.Abs(idx-40.0/60.0) > 1e-9 {
+		t.Fatalf("unexpected Riley index %v", idx)
+	}
+	if ranked[2].UnisexIndex() != 0 || (namesdata.GenderSplit{}).UnisexIndex() != 0 {
+		t.Fatalf("expected a zero index for single-gender and empty splits")
+	}
+	if got := namesdata.RankUnisex(splits, 11); len(got) != 2 {
+		t.Fatalf("expected Quinn to fall below the minimum count, got %+v", got)
+	}
+
+	history, err := namesdata.GenderSplitsByYear(records, "riley")
+	if err != nil {
+		t.Fatalf("GenderSplitsByYear: %v", err)
+	}
+	if len(history) != 2 || history[0].Year != 1990 || history[0].FemaleShare() != 0.2 || history[1].Female != 60 || history[1].Male != 40 {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	ratio, err := namesdata.NameGenderRatio(records, "Riley")
+	if err != nil {
+		t.Fatalf("NameGenderRatio: %v", err)
+	}
+	if ratio.Name != "Riley" || len(ratio.Years) != 2 || ratio.Overall.Female != 80 || ratio.Overall.Male != 120 || ratio.Crossover != 2019 {
+		t.Fatalf("unexpected ratio: %+v", ratio)
+	}
+	if ratio, _ := namesdata.NameGenderRatio(records, "Quinn"); ratio.Crossover != 0 {
+		t.Fatalf("expected no crossover for an evenly split name, got %+v", ratio)
+	}
+
+	var notFound *namesdata.NameNotFoundError
+	if _, err := namesdata.GenderSplitsByYear(records, "Rylee"); !errors.As(err, &notFound) {
+		t.Fatalf("expected NameNotFoundError, got %v", err)
+	}
+}
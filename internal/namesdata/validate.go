@@ -0,0 +1,123 @@
+package namesdata
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// MinPublishedCount is the smallest count SSA publishes; rarer names are
+// withheld for privacy.
+const MinPublishedCount = 5
+
+// DatasetProblem is one issue CheckDataset found in a state file.
+type DatasetProblem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// DatasetCheck is the result of CheckDataset.
+type DatasetCheck struct {
+	Files    int
+	Records  int
+	Problems []DatasetProblem
+}
+
+// CheckDataset reads every state file in fsys in full and reports each line
+// that breaks the SSA state file format: a field count other than five, a
+// state code that differs from the file name, a gender other than F or M, a
+// non-numeric year or count, a count below MinPublishedCount, years out of
+// ascending order within a gender, and repeated (name, gender, year) rows.
+// Unlike ValidateDataset it does not stop at the first problem. The error is
+// non-nil only when the dataset cannot be read.
+func CheckDataset(fsys fs.FS) (DatasetCheck, error) {
+	states, files, err := stateFiles(fsys)
+	if err != nil {
+		return DatasetCheck{}, err
+	}
+	if len(states) == 0 {
+		return DatasetCheck{}, errors.New("no state .TXT files found")
+	}
+
+	var check DatasetCheck
+	for _, state := range states {
+		if err := checkStateFile(fsys, state, files[state], &check); err != nil {
+			return DatasetCheck{}, err
+		}
+		check.Files++
+	}
+	return check, nil
+}
+
+// checkStateFile appends the problems in fileName, which should hold
+// records for state, to check.
+func checkStateFile(fsys fs.FS, state, fileName string, check *DatasetCheck) error {
+	file, err := fsys.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", fileName, err)
+	}
+	defer file.Close()
+
+	lastYear := make(map[string]int)
+	seen := make(map[string]int)
+	problem := func(line int, format string, args ...any) {
+		check.Problems = append(check.Problems, DatasetProblem{File: fileName, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		check.Records++
+
+		parts := strings.Split(line, ",")
+		if len(parts) != 5 {
+			problem(lineNo, "expected 5 comma-separated fields, found %d", len(parts))
+			continue
+		}
+		recState, gender, name := parts[0], strings.ToUpper(parts[1]), parts[3]
+		if !strings.EqualFold(recState, state) {
+			problem(lineNo, "state %q does not match the file name", recState)
+		}
+		if gender != "F" && gender != "M" {
+			problem(lineNo, "gender %q is not F or M", parts[1])
+		}
+		if strings.TrimSpace(name) == "" {
+			problem(lineNo, "name is empty")
+		}
+		if count, err := strconv.Atoi(parts[4]); err != nil {
+			problem(lineNo, "count %q is not a number", parts[4])
+		} else if count < MinPublishedCount {
+			problem(lineNo, "count %d is below the SSA minimum of %d", count, MinPublishedCount)
+		}
+		year, err := strconv.Atoi(parts[2])
+		if err != nil {
+			problem(lineNo, "year %q is not a number", parts[2])
+			continue
+		}
+
+		if last, ok := lastYear[gender]; ok && year < last {
+			problem(lineNo, "year %d follows %d; years must ascend within each gender", year, last)
+		} else {
+			lastYear[gender] = year
+		}
+		key := fmt.Sprintf("%s,%d,%s", gender, year, strings.ToUpper(name))
+		if first, ok := seen[key]; ok {
+			problem(lineNo, "duplicate of line %d (%s, %s, %d)", first, name, gender, year)
+		} else {
+			seen[key] = lineNo
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan %s: %w", fileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package namesdata_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestCheckDataset(t *testing.T) {
+	clean := fstest.MapFS{
+		"CA.TXT": {Data: []byte("CA,F,2018,Emma,50\nCA,F,2019,Emma,90\nCA,M,2018,Liam,85\n")},
+		"NY.TXT": {Data: []byte("NY,F,2019,Olivia,60\n")},
+	}
+	check, err := namesdata.CheckDataset(clean)
+	if err != nil {
+		t.Fatalf("CheckDataset: %v", err)
+	}
+	if check.Files != 2 || check.Records != 4 || len(check.Problems) != 0 {
+		t.Fatalf("expected a clean dataset, got %+v", check)
+	}
+
+	fsys := fstest.MapFS{
+		"ZZ.TXT": {Data: []byte(
+			"ZZ,F,2019,Ada,50\n" +
+				"ZZ,F,2019,Bea\n" +
+				"QQ,F,2019,Cy,20\n" +
+				"ZZ,X,2019,Di,20\n" +
+				"\n" +
+				"ZZ,F,2019,Eve,3\n" +
+				"ZZ,F,2018,Flo,10\n" +
+				"ZZ,M,2017,Gus,10\n" +
+				"ZZ,F,2019,ada,12\n" +
+				"ZZ,F,twenty,Hal,x\n",
+		)},
+	}
+	check, err = namesdata.CheckDataset(fsys)
+	if err != nil {
+		t.Fatalf("CheckDataset: %v", err)
+	}
+	if check.Files != 1 || check.Records != 9 {
+		t.Fatalf("unexpected totals: %+v", check)
+	}
+	want := []namesdata.DatasetProblem{
+		{File: "ZZ.TXT", Line: 2, Message: "expected 5 comma-separated fields, found 4"},
+		{File: "ZZ.TXT", Line: 3, Message: `state "QQ" does not match the file name`},
+		{File: "ZZ.TXT", Line: 4, Message: `gender "X" is not F or M`},
+		{File: "ZZ.TXT", Line: 6, Message: "count 3 is below the SSA minimum of 5"},
+		{File: "ZZ.TXT", Line: 7, Message: "year 2018 follows 2019; years must ascend within each gender"},
+		{File: "ZZ.TXT", Line: 9, Message: "duplicate of line 1 (ada, F, 2019)"},
+		{File: "ZZ.TXT", Line: 10, Message: `count "x" is not a number`},
+		{File: "ZZ.TXT", Line: 10, Message: `year "twenty" is not a number`},
+	}
+	if len(check.Problems) != len(want) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(want), len(check.Problems), check.Problems)
+	}
+	for i, problem := range check.Problems {
+		if problem != want[i] {
+			t.Fatalf("problem %d: got %+v, want %+v", i, problem, want[i])
+		}
+	}
+
+	if _, err := namesdata.CheckDataset(fstest.MapFS{}); err == nil {
+		t.Fatal("expected an error for a dataset without state files")
+	}
+}
@@ -0,0 +1,58 @@
+package namesdata
+
+import "math"
+
+// VolatilityWindow is the number of year-over-year rank changes covered by
+// each value of RollingVolatility.
+const VolatilityWindow = 5
+
+// Volatility measures how much the series' rank fluctuates: the standard
+// deviation of its year-over-year rank changes. Only changes between
+// adjacent observed points where the name is present in both years count,
+// so forecast points are ignored. ok is false when the series has fewer
+// than two such changes. Stable classics score low, fads score high.
+func (s TrendSeries) Volatility() (float64, bool) {
+	return rankChangeStdDev(s.Points, 1, len(s.Points)-1)
+}
+
+// RollingVolatility returns, for each point, the Volatility of the rank
+// changes in the VolatilityWindow years ending at that point. Values are NaN
+// where the window holds fewer than two changes.
+func (s TrendSeries) RollingVolatility() []float64 {
+	rolling := make([]float64, len(s.Points))
+	for i := range s.Points {
+		v, ok := rankChangeStdDev(s.Points, max(1, i-VolatilityWindow+1), i)
+		if !ok || s.Points[i].Forecast {
+			v = math.NaN()
+		}
+		rolling[i] = v
+	}
+	return rolling
+}
+
+// rankChangeStdDev returns the population standard deviation of the rank
+// changes into points[first..last].
+func rankChangeStdDev(points []TrendPoint, first, last int) (float64, bool) {
+	var changes []float64
+	for i := max(first, 1); i <= last && i < len(points); i++ {
+		prev, cur := points[i-1], points[i]
+		if !prev.Present || !cur.Present || prev.Forecast || cur.Forecast {
+			continue
+		}
+		changes = append(changes, float64(cur.Rank-prev.Rank))
+	}
+	if len(changes) < 2 {
+		return 0, false
+	}
+
+	mean := 0.0
+	for _, c := range changes {
+		mean += c
+	}
+	mean /= float64(len(changes))
+	variance := 0.0
+	for _, c := range changes {
+		variance += (c - mean) * (c - mean)
+	}
+	return math.Sqrt(variance / float64(len(changes))), true
+}
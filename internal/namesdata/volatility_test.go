@@ -0,0 +1,51 @@
+package namesdata_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func TestTrendSeriesVolatility(t *testing.T) {
+	ranks := []int{1, 2, 1, 2, 0, 10, 1}
+	series := namesdata.TrendSeries{Name: "Ava"}
+	for i, rank := range ranks {
+		series.Points = append(series.Points, namesdata.TrendPoint{Year: 2000 + i, Rank: rank, Present: rank > 0})
+	}
+
+	// Changes +1, -1, +1 and then -9 after the gap in 2004, with mean -2.
+	v, ok := series.Volatility()
+	if !ok {
+		t.Fatalf("expected a volatility for %v", ranks)
+	}
+	if want := math.Sqrt((9 + 1 + 9 + 49) / 4.0); math.Abs(v-want) > 1e-9 {
+		t.Fatalf("unexpected volatility: got %v want %v", v, want)
+	}
+
+	rolling := series.RollingVolatility()
+	if len(rolling) != len(ranks) {
+		t.Fatalf("expected %d rolling values, got %d", len(ranks), len(rolling))
+	}
+	if !math.IsNaN(rolling[0]) || !math.IsNaN(rolling[1]) {
+		t.Fatalf("expected no volatility before two changes, got %v", rolling[:2])
+	}
+	if math.Abs(rolling[2]-1) > 1e-9 {
+		t.Fatalf("expected rolling volatility 1 in 2002, got %v", rolling[2])
+	}
+
+	stable := namesdata.TrendSeries{Name: "Mary", Points: []namesdata.TrendPoint{
+		{Year: 2000, Rank: 3, Present: true},
+		{Year: 2001, Rank: 3, Present: true},
+		{Year: 2002, Rank: 3, Present: true},
+		{Year: 2003, Rank: 1, Forecast: true, Present: true},
+	}}
+	if v, ok := stable.Volatility(); !ok || v != 0 {
+		t.Fatalf("expected a steady rank to have zero volatility, got %v (ok=%t)", v, ok)
+	}
+
+	short := namesdata.TrendSeries{Name: "Zoe", Points: stable.Points[:2]}
+	if _, ok := short.Volatility(); ok {
+		t.Fatal("expected no volatility from a single rank change")
+	}
+}
@@ -0,0 +1,71 @@
+package namesdata
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// OpenZip opens a zip archive of state files, such as the SSA's
+// namesbystate.zip, as a dataset file system. Files are decompressed as they
+// are read, so nothing is unpacked to disk. The state files may sit at the
+// top of the archive or inside a single top-level directory. The returned
+// closer releases the archive once the dataset is no longer needed.
+func OpenZip(path string) (fs.FS, io.Closer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("open zip %s: %w", path, err)
+	}
+
+	fsys, err := zipDatasetRoot(archive)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("zip %s: %w", path, err)
+	}
+	return fsys, file, nil
+}
+
+// zipDatasetRoot returns the directory of archive holding the state files:
+// the root, or its only subdirectory when the root has no state files.
+func zipDatasetRoot(archive *zip.Reader) (fs.FS, error) {
+	states, _, err := stateFiles(archive)
+	if err != nil {
+		return nil, err
+	}
+	if len(states) > 0 {
+		return archive, nil
+	}
+
+	entries, err := fs.ReadDir(archive, ".")
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	if len(dirs) == 1 {
+		sub, err := fs.Sub(archive, dirs[0])
+		if err != nil {
+			return nil, err
+		}
+		if states, _, err := stateFiles(sub); err == nil && len(states) > 0 {
+			return sub, nil
+		}
+	}
+	return nil, fmt.Errorf("no state .TXT files found")
+}
@@ -0,0 +1,67 @@
+package namesdata_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+func writeZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "names.zip")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create zip: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	out.Close()
+	return path
+}
+
+func TestOpenZip(t *testing.T) {
+	for _, prefix := range []string{"", "namesbystate/"} {
+		path := writeZip(t, map[string]string{
+			prefix + "CA.TXT":          "CA,F,2019,Olivia,140\nCA,F,2019,Emma,90\n",
+			prefix + "NY.TXT":          "NY,F,2019,Olivia,60\n",
+			prefix + "StateReadMe.pdf": "readme",
+		})
+		fsys, closer, err := namesdata.OpenZip(path)
+		if err != nil {
+			t.Fatalf("OpenZip with prefix %q: %v", prefix, err)
+		}
+
+		states, err := namesdata.States(fsys)
+		if err != nil || len(states) != 2 || states[0] != "CA" {
+			t.Fatalf("unexpected states %v: %v", states, err)
+		}
+		records, err := namesdata.LoadStateRecords(fsys, "CA")
+		if err != nil || len(records) != 2 || records[0].Count != 140 {
+			t.Fatalf("unexpected CA records %+v: %v", records, err)
+		}
+		if err := closer.Close(); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+	}
+
+	if _, _, err := namesdata.OpenZip(writeZip(t, map[string]string{"README.md": "no data"})); err == nil {
+		t.Fatalf("expected error for a zip without state files")
+	}
+	notZip := filepath.Join(t.TempDir(), "names.zip")
+	os.WriteFile(notZip, []byte("CA,F,2019,Olivia,140\n"), 0o644)
+	if _, _, err := namesdata.OpenZip(notZip); err == nil {
+		t.Fatalf("expected error for a file that is not a zip")
+	}
+}
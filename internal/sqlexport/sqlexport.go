@@ -0,0 +1,210 @@
+// Package sqlexport writes the names dataset into a SQLite database so it can
+// be explored with ad-hoc SQL. Alongside the raw records it stores
+// precomputed aggregate tables that mirror the CLI's rankings.
+//
+// Schema:
+//
+//	records       (state, gender, year, name, count)          one row per dataset line
+//	name_totals   (name, gender, total, years, first_year, last_year)
+//	yearly_ranks  (year, gender, name, count, rank, share)    ranks across the exported states
+//	state_totals  (state, year, gender, births)
+//	metadata      (key, value)
+package sqlexport
+
+import (
+	"database/sql"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+
+	// Registers the pure-Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// Tables lists the tables Write creates, in creation order.
+var Tables = []string{"records", "name_totals", "yearly_ranks", "state_totals", "metadata"}
+
+const schema = `
+CREATE TABLE records (
+	state  TEXT    NOT NULL,
+	gender TEXT    NOT NULL,
+	year   INTEGER NOT NULL,
+	name   TEXT    NOT NULL,
+	count  INTEGER NOT NULL
+);
+CREATE TABLE name_totals (
+	name       TEXT    NOT NULL,
+	gender     TEXT    NOT NULL,
+	total      INTEGER NOT NULL,
+	years      INTEGER NOT NULL,
+	first_year INTEGER NOT NULL,
+	last_year  INTEGER NOT NULL,
+	PRIMARY KEY (name, gender)
+);
+CREATE TABLE yearly_ranks (
+	year   INTEGER NOT NULL,
+	gender TEXT    NOT NULL,
+	name   TEXT    NOT NULL,
+	count  INTEGER NOT NULL,
+	rank   INTEGER NOT NULL,
+	share  REAL    NOT NULL,
+	PRIMARY KEY (year, gender, name)
+);
+CREATE TABLE state_totals (
+	state  TEXT    NOT NULL,
+	year   INTEGER NOT NULL,
+	gender TEXT    NOT NULL,
+	births INTEGER NOT NULL,
+	PRIMARY KEY (state, year, gender)
+);
+CREATE TABLE metadata (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// The aggregate tables are derived from records once it is loaded. Ranks are
+// ordinal within a year and gender, ties broken by name, matching the CLI.
+const aggregates = `
+INSERT INTO name_totals
+	SELECT name, gender, SUM(count), COUNT(DISTINCT year), MIN(year), MAX(year)
+	FROM records GROUP BY name, gender;
+INSERT INTO state_totals
+	SELECT state, year, gender, SUM(count)
+	FROM records GROUP BY state, year, gender;
+INSERT INTO yearly_ranks
+	SELECT year, gender, name, total,
+		ROW_NUMBER() OVER (PARTITION BY year, gender ORDER BY total DESC, name),
+		CAST(total AS REAL) / SUM(total) OVER (PARTITION BY year, gender)
+	FROM (SELECT year, gender, name, SUM(count) AS total FROM records GROUP BY year, gender, name);
+CREATE INDEX records_name ON records (name, gender, year);
+CREATE INDEX records_state_year ON records (state, year, gender);
+CREATE INDEX yearly_ranks_name ON yearly_ranks (name, gender);
+`
+
+// Summary reports what Write stored.
+type Summary struct {
+	// Rows maps each table name to its row count.
+	Rows map[string]int
+}
+
+// Write creates a SQLite database at path, replacing any existing file, and
+// fills it from records. metadata is stored as key/value rows describing the
+// export. The database is built in a temporary file beside path and renamed
+// over it only once complete, so a record stream error, or a stream with no
+// records (namesdata.ErrNoMatches), leaves any existing file untouched.
+func Write(path string, records iter.Seq2[namesdata.Record, error], metadata map[string]string) (Summary, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return Summary{}, fmt.Errorf("sqlexport: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return Summary{}, fmt.Errorf("sqlexport: %w", err)
+	}
+
+	summary, err := write(tmpPath, records, metadata)
+	if err == nil {
+		err = replace(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return Summary{}, err
+	}
+	return summary, nil
+}
+
+// replace renames the finished database at tmpPath over path. CreateTemp
+// makes files readable only by their owner, so the database first takes the
+// permissions of the file it replaces, or 0644 for a new file.
+func replace(tmpPath, path string) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("sqlexport: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("sqlexport: %w", err)
+	}
+	return nil
+}
+
+// write fills the database at path, closing it before returning.
+func write(path string, records iter.Seq2[namesdata.Record, error], metadata map[string]string) (summary Summary, err error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return Summary{}, fmt.Errorf("sqlexport: %w", err)
+	}
+	defer func() {
+		if cerr := db.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("sqlexport: %w", cerr)
+		}
+	}()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return Summary{}, fmt.Errorf("sqlexport: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(schema); err != nil {
+		return Summary{}, fmt.Errorf("sqlexport: create schema: %w", err)
+	}
+
+	insert, err := tx.Prepare("INSERT INTO records (state, gender, year, name, count) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		return Summary{}, fmt.Errorf("sqlexport: %w", err)
+	}
+	defer insert.Close()
+
+	inserted := 0
+	for rec, err := range records {
+		if err != nil {
+			return Summary{}, err
+		}
+		inserted++
+		if _, err := insert.Exec(rec.State, rec.Gender, rec.Year, rec.Name, rec.Count); err != nil {
+			return Summary{}, fmt.Errorf("sqlexport: insert record: %w", err)
+		}
+	}
+
+	if inserted == 0 {
+		return Summary{}, namesdata.ErrNoMatches
+	}
+
+	if _, err := tx.Exec(aggregates); err != nil {
+		return Summary{}, fmt.Errorf("sqlexport: build aggregates: %w", err)
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := tx.Exec("INSERT INTO metadata (key, value) VALUES (?, ?)", key, metadata[key]); err != nil {
+			return Summary{}, fmt.Errorf("sqlexport: insert metadata: %w", err)
+		}
+	}
+
+	summary = Summary{Rows: make(map[string]int, len(Tables))}
+	for _, table := range Tables {
+		var count int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			return Summary{}, fmt.Errorf("sqlexport: count %s: %w", table, err)
+		}
+		summary.Rows[table] = count
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Summary{}, fmt.Errorf("sqlexport: %w", err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,92 @@
+package sqlexport_test
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+	"github.com/curtiscovington/ssa-names/internal/sqlexport"
+)
+
+func TestWrite(t *testing.T) {
+	records := []namesdata.Record{
+		{State: "CA", Gender: "F", Year: 2019, Name: "Olivia", Count: 140},
+		{State: "CA", Gender: "F", Year: 2019, Name: "Emma", Count: 90},
+		{State: "NY", Gender: "F", Year: 2019, Name: "Emma", Count: 60},
+		{State: "NY", Gender: "M", Year: 2018, Name: "Liam", Count: 65},
+	}
+
+	path := filepath.Join(t.TempDir(), "names.db")
+	summary, err := sqlexport.Write(path, namesdata.SliceRecords(records), map[string]string{"state": "NATIONAL"})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if summary.Rows["records"] != 4 || summary.Rows["name_totals"] != 3 || summary.Rows["yearly_ranks"] != 3 || summary.Rows["state_totals"] != 3 {
+		t.Fatalf("unexpected summary: %+v", summary.Rows)
+	}
+
+	if info, err := os.Stat(path); err != nil {
+		t.Fatalf("stat: %v", err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0o644 {
+		t.Fatalf("expected a new database to be world-readable, got %v", info.Mode())
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	var name string
+	var count, rank int
+	var share float64
+	row := db.QueryRow("SELECT name, count, rank, share FROM yearly_ranks WHERE year = 2019 AND gender = 'F' ORDER BY rank LIMIT 1")
+	if err := row.Scan(&name, &count, &rank, &share); err != nil {
+		t.Fatalf("query yearly_ranks: %v", err)
+	}
+	if name != "Emma" || count != 150 || rank != 1 || share < 0.517 || share > 0.518 {
+		t.Fatalf("unexpected top 2019 name: %s %d #%d %.4f", name, count, rank, share)
+	}
+
+	var value string
+	if err := db.QueryRow("SELECT value FROM metadata WHERE key = 'state'").Scan(&value); err != nil || value != "NATIONAL" {
+		t.Fatalf("unexpected metadata %q: %v", value, err)
+	}
+
+	// A failed export leaves the existing database and no temporary file.
+	if _, err := sqlexport.Write(path, namesdata.SliceRecords(nil), nil); !errors.Is(err, namesdata.ErrNoMatches) {
+		t.Fatalf("expected ErrNoMatches for an empty export, got %v", err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM records").Scan(&count); err != nil || count != 4 {
+		t.Fatalf("expected the earlier database to survive a failed export, got %d rows: %v", count, err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected only the database in its directory, got %v: %v", entries, err)
+	}
+
+	// A successful export replaces it, keeping its permissions.
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if _, err := sqlexport.Write(path, namesdata.SliceRecords(records[:1]), nil); err != nil {
+		t.Fatalf("Write over existing: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatalf("stat: %v", err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0o640 {
+		t.Fatalf("expected the replaced database to keep mode 0640, got %v", info.Mode())
+	}
+	replaced, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer replaced.Close()
+	if err := replaced.QueryRow("SELECT COUNT(*) FROM records").Scan(&count); err != nil || count != 1 {
+		t.Fatalf("expected the replaced database to hold 1 record, got %d: %v", count, err)
+	}
+}
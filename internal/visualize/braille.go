@@ -0,0 +1,115 @@
+package visualize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// brailleDots maps a dot's column (0 or 1) and row (0 to 3) within a
+// character cell to its bit in the Unicode Braille Patterns block, which
+// starts at U+2800.
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// plotBraille draws plotSparkline's PlotStyleBraille output: each character
+// cell holds 2x4 dots, and consecutive observed points of a series are
+// joined by a vertical run of dots so steep changes stay visible. Forecast
+// points are drawn but not joined. Series are told apart only by color, so
+// without it overlapping series read as one.
+func plotBraille(years []int, series []ValueSeries, forecastYears [][]bool, metric string, width, height int, color bool) (string, error) {
+	dotColumns := max(1, min(2*width, len(years)))
+	dotRows := 4 * height
+	columns := (dotColumns + 1) / 2
+
+	yearIndices := sampleYears(len(years), dotColumns)
+	values, forecast, hasForecast := sampleSeries(series, forecastYears, yearIndices)
+	minVal, maxVal := valueRange(values)
+	if math.IsNaN(minVal) {
+		return "", errors.New("plot: no data available for the selected metric")
+	}
+	if math.Abs(maxVal-minVal) < 1e-9 {
+		maxVal = minVal + 1
+	}
+
+	// owner is the series drawing each cell, -1 for none, or -2 when
+	// several series share it.
+	cells := make([][]rune, height)
+	owner := make([][]int, height)
+	for r := range cells {
+		cells[r] = make([]rune, columns)
+		owner[r] = make([]int, columns)
+		for c := range owner[r] {
+			owner[r][c] = -1
+		}
+	}
+	setDot := func(x, y, si int) {
+		r, c := y/4, x/2
+		cells[r][c] |= brailleDots[x%2][y%4]
+		switch owner[r][c] {
+		case -1:
+			owner[r][c] = si
+		case si:
+		default:
+			owner[r][c] = -2
+		}
+	}
+
+	for si, seriesValues := range values {
+		prevRow := -1
+		for x, v := range seriesValues {
+			if math.IsNaN(v) {
+				prevRow = -1
+				continue
+			}
+			normalized := (v - minVal) / (maxVal - minVal)
+			row := (dotRows - 1) - int(math.Round(normalized*float64(dotRows-1)))
+			row = max(0, min(row, dotRows-1))
+			setDot(x, row, si)
+			if prevRow >= 0 && !forecast[si][x] {
+				for y := min(prevRow, row) + 1; y < max(prevRow, row); y++ {
+					setDot(x, y, si)
+				}
+			}
+			prevRow = row
+			if forecast[si][x] {
+				prevRow = -1
+			}
+		}
+	}
+
+	grid := make([][]rune, height)
+	for r := range cells {
+		grid[r] = make([]rune, columns)
+		for c, dots := range cells[r] {
+			grid[r][c] = ' '
+			if dots != 0 {
+				grid[r][c] = 0x2800 + dots
+			}
+			if owner[r][c] < 0 {
+				owner[r][c] = -1
+			}
+		}
+	}
+
+	var builder strings.Builder
+	builder.Grow(height*(columns+1)*3 + 64)
+
+	builder.WriteString(fmt.Sprintf("Plot (metric=%s)\n", metric))
+	writePlotGrid(&builder, grid, owner, color)
+
+	legend := make([]string, len(series))
+	for i, s := range series {
+		legend[i] = "⣿ " + s.Label
+	}
+	forecastNote := ""
+	if hasForecast {
+		forecastNote = "forecast points unjoined"
+	}
+	writePlotFooter(&builder, years, yearIndices, columns, legend, forecastNote, metric, color)
+
+	return builder.String(), nil
+}
@@ -0,0 +1,477 @@
+package visualize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// trendChart is the laid-out trend chart shared by the SVG and PNG
+// backends. Coordinates are in output pixels before any PNG scaling.
+type trendChart struct {
+	Theme  Theme
+	Width  int
+	Height int
+	Lines  []chartLine
+	Texts  []chartText
+	Series []chartSeries
+	// Legend is zero when the chart has no legend.
+	Legend  chartRect
+	Entries []legendEntry
+	// Annotations mark points of interest above the series.
+	Annotations []chartAnnotation
+}
+
+// ChartOptions controls the styling of the SVG and PNG trend charts. The
+// zero value draws a plain chart in DefaultTheme.
+type ChartOptions struct {
+	Theme Theme
+	// AnnotatePeaks marks each series' highest observed point with its
+	// name, year, and value. For rank charts that is the best rank.
+	AnnotatePeaks bool
+	// Changepoints holds, for each series in order, the years to mark where
+	// its share moved to a new level. Each is drawn as a ringed marker
+	// labeled with its year.
+	Changepoints [][]namesdata.Changepoint
+	// Facet draws one small chart per series in a grid instead of a single
+	// shared chart. SharedScale gives every panel the same value range so
+	// panels compare directly; otherwise each panel fits its own series.
+	Facet       bool
+	SharedScale bool
+}
+
+// theme returns the options' theme, or DefaultTheme when none is set.
+func (o ChartOptions) theme() Theme {
+	if o.Theme.Name == "" && len(o.Theme.Palette) == 0 {
+		return DefaultTheme
+	}
+	return o.Theme
+}
+
+// chartAnnotation is a ringed marker on a series point with an optional
+// label.
+type chartAnnotation struct {
+	At    chartPoint
+	Color string
+	Label chartText
+}
+
+type chartPoint struct {
+	X, Y float64
+}
+
+type lineStyle int
+
+const (
+	lineGrid lineStyle = iota
+	lineAxis
+	// lineForecast is the dashed divider where forecast years begin.
+	lineForecast
+)
+
+type chartLine struct {
+	From, To chartPoint
+	Style    lineStyle
+}
+
+type textAnchor string
+
+const (
+	anchorStart  textAnchor = "start"
+	anchorMiddle textAnchor = "middle"
+	anchorEnd    textAnchor = "end"
+)
+
+type chartText struct {
+	At     chartPoint
+	Text   string
+	Anchor textAnchor
+	// Size is the font size in pixels; zero means the default of 12.
+	Size  float64
+	Bold  bool
+	Color string
+}
+
+// chartSeries is one name's line, split into runs at years where the name
+// is absent.
+type chartSeries struct {
+	Color string
+	Runs  [][]chartPoint
+	// ForecastRuns are the dashed runs through projected years. Each starts
+	// at the last observed point before it so the line stays connected.
+	ForecastRuns [][]chartPoint
+	// Projected holds the projected points themselves, drawn hollow.
+	Projected []chartPoint
+}
+
+type chartRect struct {
+	X, Y, W, H float64
+	Radius     float64
+	Fill       string
+	Stroke     string
+}
+
+type legendEntry struct {
+	Swatch chartRect
+	Label  chartText
+}
+
+// layoutChart lays out the trend data as a single chart or, when
+// opts.Facet is set, as small multiples.
+func layoutChart(prefix string, years []int, series []namesdata.TrendSeries, totals map[int]int, metric string, width, height int, scope []string, opts ChartOptions) (*trendChart, error) {
+	if opts.Facet {
+		return layoutFacets(prefix, years, series, totals, metric, width, height, scope, opts)
+	}
+	return layoutTrend(prefix, years, series, totals, metric, width, height, scope, opts)
+}
+
+// layoutTrend computes the chart geometry for the provided trend data,
+// styled by opts. prefix names the output format in error messages.
+func layoutTrend(prefix string, years []int, series []namesdata.TrendSeries, totals map[int]int, metric string, width, height int, scope []string, opts ChartOptions) (*trendChart, error) {
+	theme := opts.theme()
+	if err := checkChartArgs(prefix, years, width, height, theme); err != nil {
+		return nil, err
+	}
+
+	values := metricValues(series, totals, metric, len(years))
+	minVal, maxVal := valueRange(values)
+	if math.IsNaN(minVal) {
+		return nil, fmt.Errorf("%s: no data available for the selected metric", prefix)
+	}
+
+	if math.Abs(maxVal-minVal) < 1e-9 {
+		maxVal = minVal + 1
+	}
+
+	paddingTop := 80.0
+	paddingLeft := 80.0
+	paddingRight := 80.0
+	paddingBottom := 120.0
+
+	plotWidth := float64(width) - paddingLeft - paddingRight
+	plotHeight := float64(height) - paddingTop - paddingBottom
+	if plotWidth <= 0 || plotHeight <= 0 {
+		return nil, errors.New(prefix + ": insufficient space for plot")
+	}
+
+	xCoords := make([]float64, len(years))
+	if len(years) == 1 {
+		xCoords[0] = paddingLeft + plotWidth/2
+	} else {
+		step := plotWidth / float64(len(years)-1)
+		for i := range years {
+			xCoords[i] = paddingLeft + float64(i)*step
+		}
+	}
+
+	yForValue := func(v float64) float64 {
+		normalized := (v - minVal) / (maxVal - minVal)
+		return paddingTop + (1-normalized)*plotHeight
+	}
+
+	chart := &trendChart{Theme: theme, Width: width, Height: height}
+	line := func(x1, y1, x2, y2 float64, style lineStyle) {
+		chart.Lines = append(chart.Lines, chartLine{From: chartPoint{x1, y1}, To: chartPoint{x2, y2}, Style: style})
+	}
+	text := func(x, y float64, s string, anchor textAnchor, color string) {
+		chart.Texts = append(chart.Texts, chartText{At: chartPoint{x, y}, Text: s, Anchor: anchor, Color: color})
+	}
+
+	title := chartTitle(metric, scope)
+	titleY := paddingTop - 36
+	subtitleY := titleY + 18
+	chart.Texts = append(chart.Texts, chartText{At: chartPoint{paddingLeft, titleY}, Text: title, Anchor: anchorStart, Size: 20, Bold: true})
+	text(paddingLeft, subtitleY, fmt.Sprintf("%d–%d", years[0], years[len(years)-1]), anchorStart, theme.Subtle)
+	if metric == "rank" {
+		text(paddingLeft+plotWidth, subtitleY, "Lower rank = higher popularity", anchorEnd, theme.Subtle)
+	}
+
+	horizontalLines := 5
+	for i := 0; i <= horizontalLines; i++ {
+		ratio := float64(i) / float64(horizontalLines)
+		y := paddingTop + plotHeight*ratio
+		line(paddingLeft, y, paddingLeft+plotWidth, y, lineGrid)
+		if i != 0 && i != horizontalLines {
+			value := maxVal - (maxVal-minVal)*ratio
+			text(paddingLeft-10, y+4, formatMetricLabel(value, metric), anchorEnd, theme.AxisLabel)
+		}
+	}
+
+	xAxisY := paddingTop + plotHeight
+	line(paddingLeft, xAxisY, paddingLeft+plotWidth, xAxisY, lineAxis)
+	line(paddingLeft, paddingTop, paddingLeft, xAxisY, lineAxis)
+
+	text(paddingLeft-10, paddingTop+4, formatMetricLabel(maxVal, metric), anchorEnd, "")
+	text(paddingLeft-10, xAxisY+16, formatMetricLabel(minVal, metric), anchorEnd, "")
+
+	tickCount := 6
+	if tickCount > len(years) {
+		tickCount = len(years)
+	}
+	tickStep := int(math.Max(1, math.Round(float64(len(years))/float64(tickCount))))
+	labelIndexes := make(map[int]struct{})
+	for i := 0; i < len(years); i += tickStep {
+		labelIndexes[i] = struct{}{}
+	}
+	labelIndexes[0] = struct{}{}
+	labelIndexes[len(years)-1] = struct{}{}
+	labelIndexes[len(years)/2] = struct{}{}
+
+	sortedIndexes := make([]int, 0, len(labelIndexes))
+	for idx := range labelIndexes {
+		sortedIndexes = append(sortedIndexes, idx)
+	}
+	sort.Ints(sortedIndexes)
+
+	for _, idx := range sortedIndexes {
+		x := xCoords[idx]
+		line(x, paddingTop, x, xAxisY, lineGrid)
+		line(x, xAxisY, x, xAxisY+6, lineAxis)
+		text(x, xAxisY+24, fmt.Sprintf("%d", years[idx]), anchorMiddle, "")
+	}
+
+	firstForecast := -1
+	for _, s := range series {
+		for idx, point := range s.Points {
+			if point.Forecast && (firstForecast < 0 || idx < firstForecast) {
+				firstForecast = idx
+			}
+		}
+	}
+	if firstForecast >= 0 {
+		x := xCoords[firstForecast]
+		if firstForecast > 0 {
+			x = (xCoords[firstForecast-1] + x) / 2
+		}
+		line(x, paddingTop, x, xAxisY, lineForecast)
+		text(x+6, paddingTop+14, "Forecast", anchorStart, theme.Subtle)
+	}
+
+	for si, seriesValues := range values {
+		chart.Series = append(chart.Series, traceSeries(theme.seriesColor(si), seriesValues, series[si].Points, xCoords, yForValue))
+	}
+
+	if opts.AnnotatePeaks {
+		plot := chartRect{X: paddingLeft, Y: paddingTop, W: plotWidth, H: plotHeight}
+		for si, seriesValues := range values {
+			if a, ok := peakAnnotation(series[si], seriesValues, years, xCoords, yForValue, metric, theme.seriesColor(si), plot); ok {
+				chart.Annotations = append(chart.Annotations, a)
+			}
+		}
+	}
+
+	for si, points := range opts.Changepoints {
+		if si >= len(series) {
+			break
+		}
+		for _, cp := range points {
+			if a, ok := changepointAnnotation(cp, values[si], years, xCoords, yForValue, theme.seriesColor(si)); ok {
+				chart.Annotations = append(chart.Annotations, a)
+			}
+		}
+	}
+
+	legendEntryWidth := 150.0
+	entriesPerRow := int(math.Max(1, math.Floor(plotWidth/legendEntryWidth)))
+	legendRows := int(math.Ceil(float64(len(series)) / float64(entriesPerRow)))
+	legendWidth := math.Min(plotWidth, float64(entriesPerRow)*legendEntryWidth)
+	legendHeight := float64(legendRows)*22 + 12
+	legendX := paddingLeft + (plotWidth-legendWidth)/2
+	legendY := paddingTop + plotHeight + 32
+
+	chart.Legend = chartRect{X: legendX, Y: legendY, W: legendWidth, H: legendHeight, Radius: 10, Fill: theme.LegendFill, Stroke: theme.LegendStroke}
+
+	for si, s := range series {
+		row := si / entriesPerRow
+		col := si % entriesPerRow
+		entryX := legendX + float64(col)*legendEntryWidth + 20
+		entryY := legendY + float64(row)*24 + 20
+		chart.Entries = append(chart.Entries, legendEntry{
+			Swatch: chartRect{X: entryX - 18, Y: entryY - 10, W: 14, H: 14, Radius: 4, Fill: theme.seriesColor(si)},
+			Label:  chartText{At: chartPoint{entryX, entryY + 1}, Text: s.Label(), Anchor: anchorStart},
+		})
+	}
+
+	return chart, nil
+}
+
+// checkChartArgs reports the layout inputs no chart can be drawn from.
+func checkChartArgs(prefix string, years []int, width, height int, theme Theme) error {
+	if len(years) == 0 {
+		return fmt.Errorf("%s: no data available", prefix)
+	}
+	if width <= 0 {
+		return fmt.Errorf("%s: width must be positive", prefix)
+	}
+	if height <= 0 {
+		return fmt.Errorf("%s: height must be positive", prefix)
+	}
+	if len(theme.Palette) == 0 {
+		return fmt.Errorf("%s: theme has no palette", prefix)
+	}
+	return nil
+}
+
+// chartTitle returns the heading of a trend chart.
+func chartTitle(metric string, scope []string) string {
+	if len(scope) > 0 {
+		return fmt.Sprintf("Trend (%s, %s)", metric, strings.Join(scope, ", "))
+	}
+	return fmt.Sprintf("Trend (%s)", metric)
+}
+
+// metricValues returns each series' value for metric in every year, NaN
+// where the name is absent. Ranks are negated so that higher is better for
+// every metric.
+func metricValues(series []namesdata.TrendSeries, totals map[int]int, metric string, years int) [][]float64 {
+	values := make([][]float64, len(series))
+	for si, s := range series {
+		values[si] = make([]float64, years)
+		var rolling []float64
+		if metric == "volatility" {
+			rolling = s.RollingVolatility()
+		}
+		for idx, point := range s.Points {
+			if !point.Present {
+				values[si][idx] = math.NaN()
+				continue
+			}
+			switch metric {
+			case "volatility":
+				values[si][idx] = rolling[idx]
+			case "rank":
+				values[si][idx] = -float64(point.Rank)
+			case "count":
+				values[si][idx] = float64(point.Count)
+			case "share":
+				total := pointTotal(point, totals)
+				if total == 0 {
+					values[si][idx] = math.NaN()
+					continue
+				}
+				values[si][idx] = float64(point.Count) / float64(total)
+			}
+		}
+	}
+	return values
+}
+
+// valueRange returns the smallest and largest values, or NaN for both when
+// every value is NaN.
+func valueRange(values [][]float64) (float64, float64) {
+	minVal := math.Inf(1)
+	maxVal := math.Inf(-1)
+	for _, row := range values {
+		for _, v := range row {
+			if !math.IsNaN(v) {
+				minVal = math.Min(minVal, v)
+				maxVal = math.Max(maxVal, v)
+			}
+		}
+	}
+	if math.IsInf(minVal, 1) {
+		return math.NaN(), math.NaN()
+	}
+	return minVal, maxVal
+}
+
+// traceSeries places one series' values on the chart, splitting the line
+// into runs at absent years and into dashed runs through forecast years.
+func traceSeries(color string, values []float64, points []namesdata.TrendPoint, xCoords []float64, yForValue func(float64) float64) chartSeries {
+	cs := chartSeries{Color: color}
+	var run, projected []chartPoint
+	flush := func() {
+		if len(run) > 0 {
+			cs.Runs = append(cs.Runs, run)
+			run = nil
+		}
+		if len(projected) > 0 {
+			cs.ForecastRuns = append(cs.ForecastRuns, projected)
+			projected = nil
+		}
+	}
+	for idx, v := range values {
+		if math.IsNaN(v) {
+			flush()
+			continue
+		}
+		p := chartPoint{xCoords[idx], yForValue(v)}
+		if !points[idx].Forecast {
+			run = append(run, p)
+			continue
+		}
+		if len(run) > 0 {
+			anchor := run[len(run)-1]
+			flush()
+			projected = []chartPoint{anchor}
+		}
+		projected = append(projected, p)
+		cs.Projected = append(cs.Projected, p)
+	}
+	flush()
+	return cs
+}
+
+// changepointAnnotation marks the series point in cp's year, labeled below
+// with the year. ok is false when
+// the year is not charted or has no value.
+func changepointAnnotation(cp namesdata.Changepoint, values []float64, years []int, xCoords []float64, yForValue func(float64) float64, color string) (chartAnnotation, bool) {
+	idx := sort.SearchInts(years, cp.Year)
+	if idx == len(years) || years[idx] != cp.Year || math.IsNaN(values[idx]) {
+		return chartAnnotation{}, false
+	}
+	at := chartPoint{xCoords[idx], yForValue(values[idx])}
+	label := chartText{
+		At:     chartPoint{at.X, at.Y + 20},
+		Text:   fmt.Sprintf("%d", cp.Year),
+		Anchor: anchorMiddle,
+		Size:   10,
+		Color:  color,
+	}
+	return chartAnnotation{At: at, Color: color, Label: label}, true
+}
+
+// peakAnnotation marks the highest observed value of s, labeled with its
+// name, year, and value and kept inside plot. ok is false when s has no
+// observed values.
+func peakAnnotation(s namesdata.TrendSeries, values []float64, years []int, xCoords []float64, yForValue func(float64) float64, metric, color string, plot chartRect) (chartAnnotation, bool) {
+	peak := -1
+	for idx, v := range values {
+		if math.IsNaN(v) || s.Points[idx].Forecast {
+			continue
+		}
+		if peak < 0 || v > values[peak] {
+			peak = idx
+		}
+	}
+	if peak < 0 {
+		return chartAnnotation{}, false
+	}
+	at := chartPoint{xCoords[peak], yForValue(values[peak])}
+	label := chartText{
+		At:     chartPoint{at.X, at.Y - 10},
+		Text:   fmt.Sprintf("%s %d: %s", s.Label(), years[peak], formatMetricLabel(values[peak], metric)),
+		Anchor: anchorMiddle,
+		Size:   11,
+		Bold:   true,
+		Color:  color,
+	}
+	// Keep the label inside the plot: below the point when it sits at the
+	// top, and flush with the edge near either side.
+	if label.At.Y < plot.Y+4 {
+		label.At.Y = at.Y + 20
+	}
+	switch {
+	case at.X < plot.X+60:
+		label.Anchor = anchorStart
+		label.At.X = at.X - 4
+	case at.X > plot.X+plot.W-60:
+		label.Anchor = anchorEnd
+		label.At.X = at.X + 4
+	}
+	return chartAnnotation{At: at, Color: color, Label: label}, true
+}
@@ -0,0 +1,172 @@
+package visualize
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// stateTiles places each state on a 12×8 tile grid that approximates its
+// position on the map, as {column, row}.
+var stateTiles = map[string][2]int{
+	"AK": {0, 0}, "ME": {11, 0},
+	"WI": {6, 1}, "VT": {10, 1}, "NH": {11, 1},
+	"WA": {1, 2}, "ID": {2, 2}, "MT": {3, 2}, "ND": {4, 2}, "MN": {5, 2}, "IL": {6, 2}, "MI": {7, 2}, "NY": {9, 2}, "MA": {10, 2},
+	"OR": {1, 3}, "NV": {2, 3}, "WY": {3, 3}, "SD": {4, 3}, "IA": {5, 3}, "IN": {6, 3}, "OH": {7, 3}, "PA": {8, 3}, "NJ": {9, 3}, "CT": {10, 3}, "RI": {11, 3},
+	"CA": {1, 4}, "UT": {2, 4}, "CO": {3, 4}, "NE": {4, 4}, "MO": {5, 4}, "KY": {6, 4}, "WV": {7, 4}, "VA": {8, 4}, "MD": {9, 4}, "DE": {10, 4},
+	"AZ": {2, 5}, "NM": {3, 5}, "KS": {4, 5}, "AR": {5, 5}, "TN": {6, 5}, "NC": {7, 5}, "SC": {8, 5}, "DC": {9, 5},
+	"OK": {4, 6}, "LA": {5, 6}, "MS": {6, 6}, "AL": {7, 6}, "GA": {8, 6},
+	"HI": {0, 7}, "TX": {4, 7}, "FL": {9, 7},
+}
+
+const (
+	tileColumns = 12
+	tileRows    = 8
+
+	colorMapLow     = "#e3eef9"
+	colorMapHigh    = "#08306b"
+	colorMapMissing = "#e4e7eb"
+)
+
+// Choropleth renders an SVG tile-grid map of the United States with one
+// square per state, shaded by the name's share of that state's births.
+// States without a share (absent from the data or never recorded for the
+// name) are drawn in gray. scope parts such as the year and gender are
+// appended to the title.
+func Choropleth(name string, shares []namesdata.StateShare, scope []string, width, height int) (string, error) {
+	if len(shares) == 0 {
+		return "", errors.New("map: no data available")
+	}
+	if width <= 0 {
+		return "", errors.New("map: width must be positive")
+	}
+	if height <= 0 {
+		return "", errors.New("map: height must be positive")
+	}
+
+	const (
+		marginX = 24.0
+		top     = 72.0
+		bottom  = 64.0
+		gap     = 4.0
+	)
+	cell := math.Min((float64(width)-2*marginX)/tileColumns, (float64(height)-top-bottom)/tileRows)
+	if cell < 12 {
+		return "", errors.New("map: insufficient space for the tile grid")
+	}
+	gridWidth := cell * tileColumns
+	originX := (float64(width) - gridWidth) / 2
+
+	byState := make(map[string]namesdata.StateShare, len(shares))
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, share := range shares {
+		byState[strings.ToUpper(share.State)] = share
+		low = math.Min(low, share.Share())
+		high = math.Max(high, share.Share())
+	}
+	span := high - low
+
+	var builder strings.Builder
+	builder.Grow(len(stateTiles)*256 + 2048)
+
+	builder.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	builder.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height))
+	builder.WriteString("  <defs>\n")
+	builder.WriteString("    <linearGradient id=\"shareScale\" x1=\"0\" y1=\"0\" x2=\"1\" y2=\"0\">\n")
+	builder.WriteString(fmt.Sprintf("      <stop offset=\"0%%\" stop-color=\"%s\"/>\n", colorMapLow))
+	builder.WriteString(fmt.Sprintf("      <stop offset=\"100%%\" stop-color=\"%s\"/>\n", colorMapHigh))
+	builder.WriteString("    </linearGradient>\n")
+	builder.WriteString("  </defs>\n")
+	builder.WriteString("  <style>\n")
+	builder.WriteString(fmt.Sprintf("    text { font-family: 'Helvetica Neue', Helvetica, Arial, sans-serif; fill: %s; font-size: 12px; }\n", DefaultTheme.Text))
+	builder.WriteString("  </style>\n")
+	builder.WriteString(fmt.Sprintf("  <rect x=\"0\" y=\"0\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", width, height, DefaultTheme.Background))
+
+	title := fmt.Sprintf("%s by state", name)
+	if len(scope) > 0 {
+		title += fmt.Sprintf(" (%s)", strings.Join(scope, ", "))
+	}
+	writeSVGText(&builder, chartText{At: chartPoint{X: originX, Y: 32}, Text: title, Size: 18, Bold: true})
+	writeSVGText(&builder, chartText{At: chartPoint{X: originX, Y: 52}, Text: "Share of births in each state", Color: DefaultTheme.Subtle})
+
+	// Iterate in grid order so the output is deterministic.
+	for row := 0; row < tileRows; row++ {
+		for col := 0; col < tileColumns; col++ {
+			state := tileAt(col, row)
+			if state == "" {
+				continue
+			}
+			x := originX + float64(col)*cell + gap/2
+			y := top + float64(row)*cell + gap/2
+			size := cell - gap
+
+			fill := colorMapMissing
+			labelColor := DefaultTheme.Subtle
+			tooltip := fmt.Sprintf("%s: not recorded", state)
+			detail := "-"
+			if share, ok := byState[state]; ok {
+				t := 1.0
+				if span > 0 {
+					t = (share.Share() - low) / span
+				}
+				fill = mixColor(colorMapLow, colorMapHigh, t)
+				labelColor = DefaultTheme.Text
+				if t > 0.5 {
+					labelColor = DefaultTheme.Background
+				}
+				detail = fmt.Sprintf("%.2f%%", share.Share()*100)
+				tooltip = fmt.Sprintf("%s: %s (#%d, %d)", state, detail, share.Rank, share.Count)
+			}
+
+			builder.WriteString(fmt.Sprintf("  <g>\n    <title>%s</title>\n", html.EscapeString(tooltip)))
+			builder.WriteString(fmt.Sprintf("    <rect x=\"%0.1f\" y=\"%0.1f\" width=\"%0.1f\" height=\"%0.1f\" rx=\"3\" fill=\"%s\"/>\n", x, y, size, size, fill))
+			// Tiles large enough for two lines also show the share.
+			showDetail := size >= 36
+			labelY := y + size/2 + 4
+			if showDetail {
+				labelY = y + size/2 - 2
+			}
+			builder.WriteString(fmt.Sprintf("    <text x=\"%0.1f\" y=\"%0.1f\" text-anchor=\"middle\" font-weight=\"600\" fill=\"%s\">%s</text>\n", x+size/2, labelY, labelColor, state))
+			if showDetail {
+				builder.WriteString(fmt.Sprintf("    <text x=\"%0.1f\" y=\"%0.1f\" text-anchor=\"middle\" font-size=\"%g\" fill=\"%s\">%s</text>\n", x+size/2, labelY+14, math.Min(11, math.Round(size/4.5)), labelColor, detail))
+			}
+			builder.WriteString("  </g>\n")
+		}
+	}
+
+	legendY := top + cell*tileRows + 20
+	legendWidth := math.Min(240, gridWidth/2)
+	builder.WriteString(fmt.Sprintf("  <rect x=\"%0.1f\" y=\"%0.1f\" width=\"%0.1f\" height=\"10\" rx=\"2\" fill=\"url(#shareScale)\"/>\n", originX, legendY, legendWidth))
+	writeSVGText(&builder, chartText{At: chartPoint{X: originX, Y: legendY + 26}, Text: fmt.Sprintf("%.2f%%", low*100), Color: DefaultTheme.AxisLabel})
+	writeSVGText(&builder, chartText{At: chartPoint{X: originX + legendWidth, Y: legendY + 26}, Text: fmt.Sprintf("%.2f%%", high*100), Anchor: anchorEnd, Color: DefaultTheme.AxisLabel})
+
+	missingX := originX + legendWidth + 32
+	builder.WriteString(fmt.Sprintf("  <rect x=\"%0.1f\" y=\"%0.1f\" width=\"10\" height=\"10\" rx=\"2\" fill=\"%s\"/>\n", missingX, legendY, colorMapMissing))
+	writeSVGText(&builder, chartText{At: chartPoint{X: missingX + 16, Y: legendY + 9}, Text: "Not recorded", Color: DefaultTheme.AxisLabel})
+
+	builder.WriteString("</svg>\n")
+
+	return builder.String(), nil
+}
+
+// tileAt returns the state placed at the grid position, or "" when the
+// position is empty.
+func tileAt(col, row int) string {
+	for state, pos := range stateTiles {
+		if pos[0] == col && pos[1] == row {
+			return state
+		}
+	}
+	return ""
+}
+
+// mixColor linearly interpolates between two #rrggbb colors.
+func mixColor(from, to string, t float64) string {
+	t = math.Max(0, math.Min(1, t))
+	a, b := parseHexColor(from), parseHexColor(to)
+	return fmt.Sprintf("#%02x%02x%02x", lerp8(a.R, b.R, t), lerp8(a.G, b.G, t), lerp8(a.B, b.B, t))
+}
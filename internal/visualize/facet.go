@@ -0,0 +1,154 @@
+package visualize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// layoutFacets lays out one small chart per series in a grid, so that many
+// names stay readable. Each panel is labeled with its name and carries its
+// own axes; with opts.SharedScale every panel uses the value range of all
+// series, otherwise each fits its own. The chart has no legend.
+func layoutFacets(prefix string, years []int, series []namesdata.TrendSeries, totals map[int]int, metric string, width, height int, scope []string, opts ChartOptions) (*trendChart, error) {
+	theme := opts.theme()
+	if err := checkChartArgs(prefix, years, width, height, theme); err != nil {
+		return nil, err
+	}
+
+	values := metricValues(series, totals, metric, len(years))
+	sharedMin, sharedMax := valueRange(values)
+	if math.IsNaN(sharedMin) {
+		return nil, fmt.Errorf("%s: no data available for the selected metric", prefix)
+	}
+
+	const (
+		paddingTop  = 80.0
+		paddingSide = 24.0
+		paddingEnd  = 24.0
+		gutter      = 20.0
+		// The plot inside each panel leaves room for the name above, the
+		// value labels to the left, and the year labels below.
+		insetTop    = 22.0
+		insetLeft   = 52.0
+		insetRight  = 8.0
+		insetBottom = 20.0
+	)
+
+	// Aim for panels about twice as wide as they are tall.
+	n := len(series)
+	cols := int(math.Ceil(math.Sqrt(float64(n) * float64(width) / (2 * float64(height)))))
+	cols = max(1, min(cols, n))
+	rows := (n + cols - 1) / cols
+
+	panelWidth := (float64(width) - 2*paddingSide - gutter*float64(cols-1)) / float64(cols)
+	panelHeight := (float64(height) - paddingTop - paddingEnd - gutter*float64(rows-1)) / float64(rows)
+	plotWidth := panelWidth - insetLeft - insetRight
+	plotHeight := panelHeight - insetTop - insetBottom
+	if plotWidth < 20 || plotHeight < 20 {
+		return nil, errors.New(prefix + ": insufficient space for plot")
+	}
+
+	chart := &trendChart{Theme: theme, Width: width, Height: height}
+	line := func(x1, y1, x2, y2 float64, style lineStyle) {
+		chart.Lines = append(chart.Lines, chartLine{From: chartPoint{x1, y1}, To: chartPoint{x2, y2}, Style: style})
+	}
+	text := func(x, y float64, s string, anchor textAnchor, size float64, color string) {
+		chart.Texts = append(chart.Texts, chartText{At: chartPoint{x, y}, Text: s, Anchor: anchor, Size: size, Color: color})
+	}
+
+	titleY := paddingTop - 36
+	subtitleY := titleY + 18
+	chart.Texts = append(chart.Texts, chartText{At: chartPoint{paddingSide, titleY}, Text: chartTitle(metric, scope), Anchor: anchorStart, Size: 20, Bold: true})
+	subtitle := fmt.Sprintf("%d–%d, one panel per name", years[0], years[len(years)-1])
+	if opts.SharedScale {
+		subtitle += ", shared scale"
+	}
+	text(paddingSide, subtitleY, subtitle, anchorStart, 0, theme.Subtle)
+	if metric == "rank" {
+		text(float64(width)-paddingSide, subtitleY, "Lower rank = higher popularity", anchorEnd, 0, theme.Subtle)
+	}
+
+	for si, s := range series {
+		color := theme.seriesColor(si)
+		panelX := paddingSide + float64(si%cols)*(panelWidth+gutter)
+		panelY := paddingTop + float64(si/cols)*(panelHeight+gutter)
+		plotX := panelX + insetLeft
+		plotY := panelY + insetTop
+		xAxisY := plotY + plotHeight
+
+		chart.Texts = append(chart.Texts, chartText{At: chartPoint{panelX, panelY + 12}, Text: s.Label(), Anchor: anchorStart, Size: 13, Bold: true, Color: color})
+
+		xCoords := make([]float64, len(years))
+		for i := range years {
+			if len(years) == 1 {
+				xCoords[i] = plotX + plotWidth/2
+			} else {
+				xCoords[i] = plotX + float64(i)*plotWidth/float64(len(years)-1)
+			}
+		}
+
+		line(plotX, plotY, plotX+plotWidth, plotY, lineGrid)
+		line(plotX, plotY+plotHeight/2, plotX+plotWidth, plotY+plotHeight/2, lineGrid)
+		line(plotX, xAxisY, plotX+plotWidth, xAxisY, lineAxis)
+		line(plotX, plotY, plotX, xAxisY, lineAxis)
+		text(plotX, xAxisY+14, fmt.Sprintf("%d", years[0]), anchorStart, 10, theme.AxisLabel)
+		if len(years) > 1 {
+			text(plotX+plotWidth, xAxisY+14, fmt.Sprintf("%d", years[len(years)-1]), anchorEnd, 10, theme.AxisLabel)
+		}
+
+		minVal, maxVal := sharedMin, sharedMax
+		if !opts.SharedScale {
+			minVal, maxVal = valueRange(values[si : si+1])
+		}
+		if math.IsNaN(minVal) {
+			text(plotX+plotWidth/2, plotY+plotHeight/2+4, "no data", anchorMiddle, 0, theme.Subtle)
+			continue
+		}
+		if math.Abs(maxVal-minVal) < 1e-9 {
+			maxVal = minVal + 1
+		}
+		text(plotX-6, plotY+4, formatMetricLabel(maxVal, metric), anchorEnd, 10, theme.AxisLabel)
+		text(plotX-6, xAxisY, formatMetricLabel(minVal, metric), anchorEnd, 10, theme.AxisLabel)
+
+		yForValue := func(v float64) float64 {
+			return plotY + (1-(v-minVal)/(maxVal-minVal))*plotHeight
+		}
+		chart.Series = append(chart.Series, traceSeries(color, values[si], s.Points, xCoords, yForValue))
+
+		if opts.AnnotatePeaks {
+			plot := chartRect{X: plotX, Y: plotY, W: plotWidth, H: plotHeight}
+			if a, ok := peakAnnotation(s, values[si], years, xCoords, yForValue, metric, color, plot); ok {
+				// The panel is too small for a label beside the marker, so
+				// the peak is described in the panel's heading instead.
+				// It is left out when it would run into the name.
+				peak := "peak " + strings.TrimPrefix(a.Label.Text, s.Label()+" ")
+				a.Label = chartText{}
+				if approxTextWidth(s.Label(), 13)+approxTextWidth(peak, 11)+12 <= panelWidth-insetRight {
+					a.Label = chartText{At: chartPoint{plotX + plotWidth, panelY + 12}, Text: peak, Anchor: anchorEnd, Size: 11, Color: color}
+				}
+				chart.Annotations = append(chart.Annotations, a)
+			}
+		}
+		if si < len(opts.Changepoints) {
+			// Changepoint markers go unlabeled for the same reason.
+			for _, cp := range opts.Changepoints[si] {
+				if a, ok := changepointAnnotation(cp, values[si], years, xCoords, yForValue, color); ok {
+					a.Label = chartText{}
+					chart.Annotations = append(chart.Annotations, a)
+				}
+			}
+		}
+	}
+
+	return chart, nil
+}
+
+// approxTextWidth estimates the rendered width of s at the given font size.
+func approxTextWidth(s string, size float64) float64 {
+	return float64(utf8.RuneCountInString(s)) * size * 0.6
+}
@@ -0,0 +1,198 @@
+package visualize
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// heatmapShades are the terminal heatmap glyphs from the faintest nonzero
+// share to the highest.
+var heatmapShades = []rune{'░', '▒', '▓', '█'}
+
+// Heatmap renders an SVG grid of a name's share of births with one row per
+// state and one column per year, shaded on the same scale as Choropleth.
+// Cells where a state has no births are drawn in gray. grid must record
+// the name at least once, as NameByStateYear guarantees. scope parts such as
+// the gender are appended to the title.
+func Heatmap(grid namesdata.StateYearShares, scope []string, width, height int) (string, error) {
+	if len(grid.States) == 0 || len(grid.Years) == 0 {
+		return "", errors.New("heatmap: no data available")
+	}
+	if width <= 0 {
+		return "", errors.New("heatmap: width must be positive")
+	}
+	if height <= 0 {
+		return "", errors.New("heatmap: height must be positive")
+	}
+
+	const (
+		left   = 44.0
+		right  = 24.0
+		top    = 72.0
+		bottom = 72.0
+	)
+	cellWidth := (float64(width) - left - right) / float64(len(grid.Years))
+	cellHeight := (float64(height) - top - bottom) / float64(len(grid.States))
+	if cellWidth < 1 || cellHeight < 4 {
+		return "", errors.New("heatmap: insufficient space for the grid")
+	}
+	high := grid.MaxShare()
+
+	var builder strings.Builder
+	builder.Grow(len(grid.States)*len(grid.Years)*128 + 2048)
+
+	builder.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	builder.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height))
+	builder.WriteString("  <defs>\n")
+	builder.WriteString("    <linearGradient id=\"shareScale\" x1=\"0\" y1=\"0\" x2=\"1\" y2=\"0\">\n")
+	builder.WriteString(fmt.Sprintf("      <stop offset=\"0%%\" stop-color=\"%s\"/>\n", colorMapLow))
+	builder.WriteString(fmt.Sprintf("      <stop offset=\"100%%\" stop-color=\"%s\"/>\n", colorMapHigh))
+	builder.WriteString("    </linearGradient>\n")
+	builder.WriteString("  </defs>\n")
+	builder.WriteString("  <style>\n")
+	builder.WriteString(fmt.Sprintf("    text { font-family: 'Helvetica Neue', Helvetica, Arial, sans-serif; fill: %s; font-size: 12px; }\n", DefaultTheme.Text))
+	builder.WriteString("  </style>\n")
+	builder.WriteString(fmt.Sprintf("  <rect x=\"0\" y=\"0\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", width, height, DefaultTheme.Background))
+
+	title := fmt.Sprintf("%s by state and year", grid.Name)
+	if len(scope) > 0 {
+		title += fmt.Sprintf(" (%s)", strings.Join(scope, ", "))
+	}
+	writeSVGText(&builder, chartText{At: chartPoint{X: left, Y: 32}, Text: title, Size: 18, Bold: true})
+	writeSVGText(&builder, chartText{At: chartPoint{X: left, Y: 52}, Text: "Share of births in each state", Color: DefaultTheme.Subtle})
+
+	labelSize := math.Min(11, math.Floor(cellHeight))
+	for si, state := range grid.States {
+		y := top + float64(si)*cellHeight
+		writeSVGText(&builder, chartText{At: chartPoint{X: left - 6, Y: y + cellHeight/2 + labelSize/3}, Text: state, Anchor: anchorEnd, Size: labelSize, Color: DefaultTheme.AxisLabel})
+		for yi, year := range grid.Years {
+			fill := colorMapMissing
+			tooltip := fmt.Sprintf("%s %d: no births", state, year)
+			switch {
+			case grid.Counts[si][yi] == 0 && grid.Totals[si][yi] > 0:
+				fill = colorMapLow
+				tooltip = fmt.Sprintf("%s %d: not recorded", state, year)
+			case grid.Totals[si][yi] > 0:
+				share := grid.Share(si, yi)
+				fill = mixColor(colorMapLow, colorMapHigh, share/high)
+				tooltip = fmt.Sprintf("%s %d: %.2f%% (%d)", state, year, share*100, grid.Counts[si][yi])
+			}
+			// Cells overlap by a fraction of a pixel so no seams show.
+			builder.WriteString(fmt.Sprintf("  <rect x=\"%0.2f\" y=\"%0.2f\" width=\"%0.2f\" height=\"%0.2f\" fill=\"%s\"><title>%s</title></rect>\n",
+				left+float64(yi)*cellWidth, y, cellWidth+0.3, cellHeight+0.3, fill, html.EscapeString(tooltip)))
+		}
+	}
+
+	// Label decades, or coarser steps when decades would crowd together.
+	axisY := top + float64(len(grid.States))*cellHeight
+	step := 10
+	for cellWidth*float64(step) < 36 {
+		step *= 2
+	}
+	for yi, year := range grid.Years {
+		if year%step != 0 {
+			continue
+		}
+		x := left + (float64(yi)+0.5)*cellWidth
+		writeSVGText(&builder, chartText{At: chartPoint{X: x, Y: axisY + 16}, Text: fmt.Sprintf("%d", year), Anchor: anchorMiddle, Size: 11, Color: DefaultTheme.AxisLabel})
+	}
+
+	legendY := axisY + 32
+	legendWidth := math.Min(240, (float64(width)-left-right)/2)
+	builder.WriteString(fmt.Sprintf("  <rect x=\"%0.1f\" y=\"%0.1f\" width=\"%0.1f\" height=\"10\" rx=\"2\" fill=\"url(#shareScale)\"/>\n", left, legendY, legendWidth))
+	writeSVGText(&builder, chartText{At: chartPoint{X: left, Y: legendY + 26}, Text: "0%", Color: DefaultTheme.AxisLabel})
+	writeSVGText(&builder, chartText{At: chartPoint{X: left + legendWidth, Y: legendY + 26}, Text: fmt.Sprintf("%.2f%%", high*100), Anchor: anchorEnd, Color: DefaultTheme.AxisLabel})
+
+	missingX := left + legendWidth + 32
+	builder.WriteString(fmt.Sprintf("  <rect x=\"%0.1f\" y=\"%0.1f\" width=\"10\" height=\"10\" rx=\"2\" fill=\"%s\"/>\n", missingX, legendY, colorMapMissing))
+	writeSVGText(&builder, chartText{At: chartPoint{X: missingX + 16, Y: legendY + 9}, Text: "No births", Color: DefaultTheme.AxisLabel})
+
+	builder.WriteString("</svg>\n")
+
+	return builder.String(), nil
+}
+
+// HeatmapText renders the heatmap for a terminal: one line per state with
+// the share in each column drawn as a shade from ░ to █, where a column
+// covers consecutive years when there are more years than fit in width
+// characters. Blank cells mean the name was not recorded. When color is
+// set, each cell is also tinted along the SVG color scale with 24-bit ANSI
+// escape codes.
+func HeatmapText(grid namesdata.StateYearShares, width int, color bool) (string, error) {
+	if len(grid.States) == 0 || len(grid.Years) == 0 {
+		return "", errors.New("heatmap: no data available")
+	}
+	const labelWidth = 3
+	columns := min(width-labelWidth, len(grid.Years))
+	if columns < 1 {
+		return "", errors.New("heatmap: width must leave room for at least one column")
+	}
+
+	// Each column spans years [bounds[c], bounds[c+1]); its share is the
+	// name's births over every birth in the span.
+	bounds := make([]int, columns+1)
+	for c := range bounds {
+		bounds[c] = c * len(grid.Years) / columns
+	}
+	shares := make([][]float64, len(grid.States))
+	high := 0.0
+	for si := range grid.States {
+		shares[si] = make([]float64, columns)
+		for c := range columns {
+			count, total := 0, 0
+			for yi := bounds[c]; yi < bounds[c+1]; yi++ {
+				count += grid.Counts[si][yi]
+				total += grid.Totals[si][yi]
+			}
+			if total > 0 {
+				shares[si][c] = float64(count) / float64(total)
+				high = math.Max(high, shares[si][c])
+			}
+		}
+	}
+
+	shade := func(share float64) int {
+		if share <= 0 || high <= 0 {
+			return -1
+		}
+		return min(len(heatmapShades)-1, int(math.Ceil(share/high*float64(len(heatmapShades))))-1)
+	}
+
+	var builder strings.Builder
+	for si, state := range grid.States {
+		builder.WriteString(fmt.Sprintf("%-*s", labelWidth, state))
+		for _, share := range shares[si] {
+			level := shade(share)
+			if level < 0 {
+				builder.WriteByte(' ')
+				continue
+			}
+			glyph := string(heatmapShades[level])
+			if color {
+				rgb := parseHexColor(mixColor(colorMapLow, colorMapHigh, share/high))
+				glyph = fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s\x1b[0m", rgb.R, rgb.G, rgb.B, glyph)
+			}
+			builder.WriteString(glyph)
+		}
+		builder.WriteByte('\n')
+	}
+
+	startLabel := fmt.Sprintf("%d", grid.Years[0])
+	endLabel := fmt.Sprintf("%d", grid.Years[len(grid.Years)-1])
+	builder.WriteString(strings.Repeat(" ", labelWidth) + startLabel)
+	builder.WriteString(strings.Repeat(" ", max(1, columns-len(startLabel)-len(endLabel))))
+	builder.WriteString(endLabel + "\n")
+
+	legend := make([]string, len(heatmapShades))
+	for i, glyph := range heatmapShades {
+		legend[i] = fmt.Sprintf("%c up to %.2f%%", glyph, high*100*float64(i+1)/float64(len(heatmapShades)))
+	}
+	builder.WriteString("Legend: " + strings.Join(legend, ", "))
+
+	return builder.String(), nil
+}
@@ -0,0 +1,301 @@
+package visualize
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// PNG renders the same chart as SVG into a PNG image written to w. The image
+// is width×height pixels multiplied by scale, so a scale of 2 produces a
+// sharper chart for high-density displays with identical proportions.
+func PNG(w io.Writer, years []int, series []namesdata.TrendSeries, totals map[int]int, metric string, width, height, scale int, scope []string, opts ChartOptions) error {
+	if scale < 1 {
+		return errors.New("png: scale must be at least 1")
+	}
+	chart, err := layoutChart("png", years, series, totals, metric, width, height, scope, opts)
+	if err != nil {
+		return err
+	}
+
+	theme := chart.Theme
+	r := newRaster(width, height, scale, theme)
+
+	for _, l := range chart.Lines {
+		c := parseHexColor(theme.Grid)
+		if l.Style != lineGrid {
+			c = parseHexColor(theme.Axis)
+		}
+		// Center hairlines on pixels so they render crisp rather than as
+		// two half-covered rows.
+		if l.Style == lineForecast {
+			r.dashed(r.snap(l.From), r.snap(l.To), float64(scale), 4*float64(scale), c)
+			continue
+		}
+		r.segment(r.snap(l.From), r.snap(l.To), float64(scale), c)
+	}
+
+	for _, t := range chart.Texts {
+		r.text(t)
+	}
+
+	for _, s := range chart.Series {
+		c := parseHexColor(s.Color)
+		for _, run := range s.Runs {
+			for i := 1; i < len(run); i++ {
+				r.segment(r.point(run[i-1]), r.point(run[i]), 2*float64(scale), c)
+			}
+			for _, p := range run {
+				r.disc(r.point(p), 2.5*float64(scale), c)
+			}
+		}
+		for _, run := range s.ForecastRuns {
+			for i := 1; i < len(run); i++ {
+				r.dashed(r.point(run[i-1]), r.point(run[i]), 2*float64(scale), 5*float64(scale), c)
+			}
+		}
+		background := parseHexColor(theme.Background)
+		for _, p := range s.Projected {
+			r.disc(r.point(p), 3*float64(scale), c)
+			r.disc(r.point(p), 1.5*float64(scale), background)
+		}
+	}
+
+	background := parseHexColor(theme.Background)
+	for _, a := range chart.Annotations {
+		c := parseHexColor(a.Color)
+		r.disc(r.point(a.At), 6*float64(scale), c)
+		r.disc(r.point(a.At), 4*float64(scale), background)
+		r.disc(r.point(a.At), 2.5*float64(scale), c)
+		if a.Label.Text != "" {
+			r.text(a.Label)
+		}
+	}
+
+	if chart.Legend.W > 0 {
+		r.roundedRect(chart.Legend)
+	}
+	for _, entry := range chart.Entries {
+		r.roundedRect(entry.Swatch)
+		r.text(entry.Label)
+	}
+
+	return png.Encode(w, r.img)
+}
+
+// raster draws anti-aliased chart primitives onto an opaque RGBA image.
+type raster struct {
+	img   *image.RGBA
+	scale float64
+	// textColor is the color of text without its own.
+	textColor color.RGBA
+}
+
+func newRaster(width, height, scale int, theme Theme) *raster {
+	img := image.NewRGBA(image.Rect(0, 0, width*scale, height*scale))
+	top := parseHexColor(theme.BackgroundTop)
+	bottom := parseHexColor(theme.Background)
+	rows := img.Bounds().Dy()
+	for y := 0; y < rows; y++ {
+		t := float64(y) / math.Max(1, float64(rows-1))
+		c := color.RGBA{
+			R: lerp8(top.R, bottom.R, t),
+			G: lerp8(top.G, bottom.G, t),
+			B: lerp8(top.B, bottom.B, t),
+			A: 255,
+		}
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return &raster{img: img, scale: float64(scale), textColor: parseHexColor(theme.Text)}
+}
+
+func (r *raster) point(p chartPoint) chartPoint {
+	return chartPoint{p.X * r.scale, p.Y * r.scale}
+}
+
+func (r *raster) snap(p chartPoint) chartPoint {
+	scaled := r.point(p)
+	return chartPoint{math.Floor(scaled.X) + 0.5, math.Floor(scaled.Y) + 0.5}
+}
+
+// blend composites c over the pixel at (x, y) with the given coverage.
+func (r *raster) blend(x, y int, c color.RGBA, coverage float64) {
+	if coverage <= 0 || !(image.Point{x, y}).In(r.img.Rect) {
+		return
+	}
+	if coverage > 1 {
+		coverage = 1
+	}
+	dst := r.img.RGBAAt(x, y)
+	r.img.SetRGBA(x, y, color.RGBA{
+		R: lerp8(dst.R, c.R, coverage),
+		G: lerp8(dst.G, c.G, coverage),
+		B: lerp8(dst.B, c.B, coverage),
+		A: 255,
+	})
+}
+
+// segment draws a line from a to b with round caps, in device pixels.
+func (r *raster) segment(a, b chartPoint, width float64, c color.RGBA) {
+	half := width / 2
+	minX := int(math.Floor(math.Min(a.X, b.X) - half - 1))
+	maxX := int(math.Ceil(math.Max(a.X, b.X) + half + 1))
+	minY := int(math.Floor(math.Min(a.Y, b.Y) - half - 1))
+	maxY := int(math.Ceil(math.Max(a.Y, b.Y) + half + 1))
+
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSq := dx*dx + dy*dy
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			t := 0.0
+			if lengthSq > 0 {
+				t = math.Max(0, math.Min(1, ((px-a.X)*dx+(py-a.Y)*dy)/lengthSq))
+			}
+			d := math.Hypot(px-(a.X+t*dx), py-(a.Y+t*dy))
+			r.blend(x, y, c, half+0.5-d)
+		}
+	}
+}
+
+// dashed draws a line from a to b as alternating dashes and gaps of the
+// given length, in device pixels.
+func (r *raster) dashed(a, b chartPoint, width, dash float64, c color.RGBA) {
+	length := math.Hypot(b.X-a.X, b.Y-a.Y)
+	if length == 0 || dash <= 0 {
+		return
+	}
+	at := func(d float64) chartPoint {
+		t := d / length
+		return chartPoint{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+	}
+	for start := 0.0; start < length; start += 2 * dash {
+		r.segment(at(start), at(math.Min(start+dash, length)), width, c)
+	}
+}
+
+// disc fills a circle, in device pixels.
+func (r *raster) disc(center chartPoint, radius float64, c color.RGBA) {
+	r.segment(center, center, 2*radius, c)
+}
+
+// roundedRect fills and strokes a rectangle given in chart coordinates.
+func (r *raster) roundedRect(rect chartRect) {
+	x0, y0 := rect.X*r.scale, rect.Y*r.scale
+	w, h := rect.W*r.scale, rect.H*r.scale
+	radius := math.Min(rect.Radius*r.scale, math.Min(w, h)/2)
+	cx, cy := x0+w/2, y0+h/2
+
+	var fill, stroke color.RGBA
+	if rect.Fill != "" {
+		fill = parseHexColor(rect.Fill)
+	}
+	if rect.Stroke != "" {
+		stroke = parseHexColor(rect.Stroke)
+	}
+
+	for y := int(math.Floor(y0)) - 1; y <= int(math.Ceil(y0+h)); y++ {
+		for x := int(math.Floor(x0)) - 1; x <= int(math.Ceil(x0+w)); x++ {
+			qx := math.Abs(float64(x)+0.5-cx) - (w/2 - radius)
+			qy := math.Abs(float64(y)+0.5-cy) - (h/2 - radius)
+			d := math.Hypot(math.Max(qx, 0), math.Max(qy, 0)) + math.Min(math.Max(qx, qy), 0) - radius
+			if rect.Fill != "" {
+				r.blend(x, y, fill, 0.5-d)
+			}
+			if rect.Stroke != "" {
+				r.blend(x, y, stroke, r.scale/2+0.5-math.Abs(d))
+			}
+		}
+	}
+}
+
+// text draws a label with the built-in bitmap font, magnified to
+// approximate the requested size.
+func (r *raster) text(t chartText) {
+	face := basicfont.Face7x13
+	size := t.Size
+	if size == 0 {
+		size = 12
+	}
+	factor := int(math.Max(1, math.Round(size/12*r.scale)))
+
+	label := strings.Map(func(ch rune) rune {
+		if _, ok := face.GlyphAdvance(ch); !ok {
+			switch ch {
+			case '–', '—':
+				return '-'
+			}
+			return '?'
+		}
+		return ch
+	}, t.Text)
+
+	advance := font.MeasureString(face, label).Ceil()
+	if advance == 0 {
+		return
+	}
+	bold := 0
+	if t.Bold {
+		bold = 1
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, advance+bold, face.Height))
+	drawer := font.Drawer{Dst: mask, Src: image.Opaque, Face: face}
+	for offset := 0; offset <= bold; offset++ {
+		drawer.Dot = fixed.P(offset, face.Ascent)
+		drawer.DrawString(label)
+	}
+
+	width := mask.Rect.Dx() * factor
+	x := int(math.Round(t.At.X * r.scale))
+	switch t.Anchor {
+	case anchorMiddle:
+		x -= width / 2
+	case anchorEnd:
+		x -= width
+	}
+	y := int(math.Round(t.At.Y*r.scale)) - face.Ascent*factor
+
+	c := r.textColor
+	if t.Color != "" {
+		c = parseHexColor(t.Color)
+	}
+	for my := 0; my < mask.Rect.Dy(); my++ {
+		for mx := 0; mx < mask.Rect.Dx(); mx++ {
+			coverage := float64(mask.AlphaAt(mx, my).A) / 255
+			if coverage == 0 {
+				continue
+			}
+			for fy := 0; fy < factor; fy++ {
+				for fx := 0; fx < factor; fx++ {
+					r.blend(x+mx*factor+fx, y+my*factor+fy, c, coverage)
+				}
+			}
+		}
+	}
+}
+
+// parseHexColor parses a #rrggbb color, returning black when malformed.
+func parseHexColor(s string) color.RGBA {
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil || len(s) != 7 {
+		return color.RGBA{A: 255}
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+}
+
+func lerp8(a, b uint8, t float64) uint8 {
+	return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+}
@@ -0,0 +1,67 @@
+package visualize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// stackGlyphs fill the segments of successive keys in a stacked bar.
+var stackGlyphs = []rune{'█', '▓', '▒', '░', '●', '◆', '▲', '■', '✦', '✚', '✖'}
+
+// stackRestGlyph fills the part of a stacked bar not covered by any key.
+const stackRestGlyph = '·'
+
+// StackedBars renders one horizontal bar per row, where a full bar of width
+// characters is 100%: shares[r][k] is the fraction of row r taken by keys[k],
+// drawn as that key's segment in order, and whatever the keys leave
+// uncovered is dotted. Segment edges are rounded on the running total, so a
+// row's segments always add up to its covered share. When color is set,
+// each key's segment and legend entry are wrapped in a distinct ANSI color.
+func StackedBars(rows []string, keys []string, shares [][]float64, width int, color bool) (string, error) {
+	if len(rows) == 0 || len(keys) == 0 {
+		return "", errors.New("stacked bars: no data available")
+	}
+	labelWidth := 0
+	for _, label := range rows {
+		labelWidth = max(labelWidth, len(label))
+	}
+	columns := width - labelWidth - 1
+	if columns < 1 {
+		return "", errors.New("stacked bars: width must leave room for the bars")
+	}
+
+	var builder strings.Builder
+	for r, label := range rows {
+		builder.WriteString(fmt.Sprintf("%-*s ", labelWidth, label))
+		cumulative, drawn := 0.0, 0
+		for k := range keys {
+			cumulative += math.Max(shares[r][k], 0)
+			end := min(columns, int(math.Round(cumulative*float64(columns))))
+			if end <= drawn {
+				continue
+			}
+			segment := strings.Repeat(string(stackGlyphs[k%len(stackGlyphs)]), end-drawn)
+			if color {
+				segment = colorize(segment, k)
+			}
+			builder.WriteString(segment)
+			drawn = end
+		}
+		builder.WriteString(strings.Repeat(string(stackRestGlyph), columns-drawn))
+		builder.WriteByte('\n')
+	}
+
+	legend := make([]string, len(keys))
+	for k, key := range keys {
+		legend[k] = fmt.Sprintf("%c %s", stackGlyphs[k%len(stackGlyphs)], key)
+		if color {
+			legend[k] = colorize(legend[k], k)
+		}
+	}
+	legend = append(legend, fmt.Sprintf("%c other", stackRestGlyph))
+	builder.WriteString("Legend: " + strings.Join(legend, ", "))
+
+	return builder.String(), nil
+}
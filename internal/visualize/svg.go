@@ -0,0 +1,124 @@
+package visualize
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// SVG builds an SVG chart for the provided trend data, styled by opts.
+func SVG(years []int, series []namesdata.TrendSeries, totals map[int]int, metric string, width, height int, scope []string, opts ChartOptions) (string, error) {
+	chart, err := layoutChart("svg", years, series, totals, metric, width, height, scope, opts)
+	if err != nil {
+		return "", err
+	}
+	theme := chart.Theme
+
+	var builder strings.Builder
+	builder.Grow(width*height/2 + 1024)
+
+	builder.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	builder.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height))
+	builder.WriteString("  <defs>\n")
+	builder.WriteString("    <linearGradient id=\"backgroundGradient\" x1=\"0\" y1=\"0\" x2=\"0\" y2=\"1\">\n")
+	builder.WriteString(fmt.Sprintf("      <stop offset=\"0%%\" stop-color=\"%s\"/>\n", theme.BackgroundTop))
+	builder.WriteString(fmt.Sprintf("      <stop offset=\"100%%\" stop-color=\"%s\"/>\n", theme.Background))
+	builder.WriteString("    </linearGradient>\n")
+	builder.WriteString("  </defs>\n")
+	builder.WriteString("  <style>\n")
+	builder.WriteString(fmt.Sprintf("    text { font-family: 'Helvetica Neue', Helvetica, Arial, sans-serif; fill: %s; font-size: 12px; }\n", theme.Text))
+	builder.WriteString(fmt.Sprintf("    .axis { stroke: %s; stroke-width: 1; }\n", theme.Axis))
+	builder.WriteString(fmt.Sprintf("    .grid { stroke: %s; stroke-width: 1; }\n", theme.Grid))
+	builder.WriteString(fmt.Sprintf("    .forecast { stroke: %s; stroke-width: 1; stroke-dasharray: 4 4; }\n", theme.Axis))
+	builder.WriteString("  </style>\n")
+
+	builder.WriteString(fmt.Sprintf("  <rect x=\"0\" y=\"0\" width=\"%d\" height=\"%d\" fill=\"url(#backgroundGradient)\"/>\n", width, height))
+
+	for _, l := range chart.Lines {
+		class := "grid"
+		switch l.Style {
+		case lineAxis:
+			class = "axis"
+		case lineForecast:
+			class = "forecast"
+		}
+		builder.WriteString(fmt.Sprintf("  <line class=\"%s\" x1=\"%0.1f\" y1=\"%0.1f\" x2=\"%0.1f\" y2=\"%0.1f\"/>\n", class, l.From.X, l.From.Y, l.To.X, l.To.Y))
+	}
+
+	for _, t := range chart.Texts {
+		writeSVGText(&builder, t)
+	}
+
+	for _, s := range chart.Series {
+		var path strings.Builder
+		for _, run := range s.Runs {
+			for i, p := range run {
+				command := "L"
+				if i == 0 {
+					command = "M"
+				}
+				path.WriteString(fmt.Sprintf("%s %0.2f %0.2f ", command, p.X, p.Y))
+			}
+		}
+		builder.WriteString(fmt.Sprintf("  <path d=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\" stroke-linejoin=\"round\" stroke-linecap=\"round\"/>\n", strings.TrimSpace(path.String()), s.Color))
+		for _, run := range s.Runs {
+			for _, p := range run {
+				builder.WriteString(fmt.Sprintf("    <circle cx=\"%0.2f\" cy=\"%0.2f\" r=\"2.5\" fill=\"%s\"/>\n", p.X, p.Y, s.Color))
+			}
+		}
+		for _, run := range s.ForecastRuns {
+			var path strings.Builder
+			for i, p := range run {
+				command := "L"
+				if i == 0 {
+					command = "M"
+				}
+				path.WriteString(fmt.Sprintf("%s %0.2f %0.2f ", command, p.X, p.Y))
+			}
+			builder.WriteString(fmt.Sprintf("  <path d=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\" stroke-dasharray=\"6 4\" stroke-linejoin=\"round\" stroke-linecap=\"round\"/>\n", strings.TrimSpace(path.String()), s.Color))
+		}
+		for _, p := range s.Projected {
+			builder.WriteString(fmt.Sprintf("    <circle cx=\"%0.2f\" cy=\"%0.2f\" r=\"2.5\" fill=\"%s\" stroke=\"%s\" stroke-width=\"1.5\"/>\n", p.X, p.Y, theme.Background, s.Color))
+		}
+	}
+
+	for _, a := range chart.Annotations {
+		builder.WriteString(fmt.Sprintf("  <circle class=\"peak\" cx=\"%0.2f\" cy=\"%0.2f\" r=\"5\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\"/>\n", a.At.X, a.At.Y, a.Color))
+		if a.Label.Text != "" {
+			writeSVGText(&builder, a.Label)
+		}
+	}
+
+	if legend := chart.Legend; legend.W > 0 {
+		builder.WriteString(fmt.Sprintf("  <rect x=\"%0.1f\" y=\"%0.1f\" width=\"%0.1f\" height=\"%0.1f\" rx=\"%g\" fill=\"%s\" stroke=\"%s\"/>\n", legend.X, legend.Y, legend.W, legend.H, legend.Radius, legend.Fill, legend.Stroke))
+	}
+
+	for _, entry := range chart.Entries {
+		swatch := entry.Swatch
+		builder.WriteString(fmt.Sprintf("  <rect x=\"%0.1f\" y=\"%0.1f\" width=\"%g\" height=\"%g\" fill=\"%s\" rx=\"%g\"/>\n", swatch.X, swatch.Y, swatch.W, swatch.H, swatch.Fill, swatch.Radius))
+		writeSVGText(&builder, entry.Label)
+	}
+
+	builder.WriteString("</svg>\n")
+
+	return builder.String(), nil
+}
+
+func writeSVGText(builder *strings.Builder, t chartText) {
+	attrs := fmt.Sprintf("x=\"%0.1f\" y=\"%0.1f\"", t.At.X, t.At.Y)
+	if t.Size != 0 {
+		attrs += fmt.Sprintf(" font-size=\"%g\"", t.Size)
+	}
+	if t.Bold {
+		attrs += " font-weight=\"600\""
+	}
+	if t.Anchor != "" {
+		attrs += fmt.Sprintf(" text-anchor=\"%s\"", t.Anchor)
+	}
+	if t.Color != "" {
+		attrs += fmt.Sprintf(" fill=\"%s\"", t.Color)
+	}
+	builder.WriteString(fmt.Sprintf("  <text %s>%s</text>\n", attrs, html.EscapeString(t.Text)))
+}
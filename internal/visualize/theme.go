@@ -0,0 +1,159 @@
+package visualize
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Theme holds every color a chart is drawn with, so the SVG and PNG
+// backends read their styling from data rather than fixed values. Colors
+// are #rrggbb strings.
+type Theme struct {
+	Name string
+	// Text is the default text color; Subtle and AxisLabel are used for
+	// subtitles and the value axis labels.
+	Text      string
+	Subtle    string
+	AxisLabel string
+	Axis      string
+	Grid      string
+	// BackgroundTop and Background are the ends of the vertical background
+	// gradient. Background also fills hollow forecast points.
+	BackgroundTop string
+	Background    string
+	LegendFill    string
+	LegendStroke  string
+	// Palette colors the series in order, cycling when there are more
+	// series than colors.
+	Palette []string
+}
+
+// DefaultTheme is the light theme charts use unless another is chosen.
+var DefaultTheme = themes["light"]
+
+var themes = map[string]Theme{
+	"light": {
+		Name:          "light",
+		Text:          "#1f2933",
+		Subtle:        "#52606d",
+		AxisLabel:     "#6b7280",
+		Axis:          "#7b8794",
+		Grid:          "#e4e7eb",
+		BackgroundTop: "#fafafa",
+		Background:    "#ffffff",
+		LegendFill:    "#f5f7fa",
+		LegendStroke:  "#d9dde2",
+		Palette: []string{
+			"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+			"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+		},
+	},
+	"dark": {
+		Name:          "dark",
+		Text:          "#e5e7eb",
+		Subtle:        "#9ca3af",
+		AxisLabel:     "#9ca3af",
+		Axis:          "#6b7280",
+		Grid:          "#374151",
+		BackgroundTop: "#1f2937",
+		Background:    "#111827",
+		LegendFill:    "#1f2937",
+		LegendStroke:  "#374151",
+		Palette: []string{
+			"#60a5fa", "#fb923c", "#4ade80", "#f87171", "#c084fc",
+			"#f472b6", "#facc15", "#2dd4bf", "#a3e635", "#94a3b8",
+		},
+	},
+	// high-contrast uses black on white with the Okabe-Ito palette, which
+	// stays distinguishable with the common forms of color blindness.
+	"high-contrast": {
+		Name:          "high-contrast",
+		Text:          "#000000",
+		Subtle:        "#000000",
+		AxisLabel:     "#000000",
+		Axis:          "#000000",
+		Grid:          "#767676",
+		BackgroundTop: "#ffffff",
+		Background:    "#ffffff",
+		LegendFill:    "#ffffff",
+		LegendStroke:  "#000000",
+		Palette: []string{
+			"#0072b2", "#d55e00", "#009e73", "#cc79a7", "#e69f00",
+			"#56b4e9", "#000000",
+		},
+	},
+}
+
+// ThemeNames returns the names LookupTheme accepts, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTheme returns the named theme. An empty name selects DefaultTheme.
+func LookupTheme(name string) (Theme, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return DefaultTheme, nil
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unsupported theme %q (expected %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return theme, nil
+}
+
+// WithPalette returns t with its series colors replaced by palette, or t
+// unchanged when palette is empty.
+func (t Theme) WithPalette(palette []string) Theme {
+	if len(palette) > 0 {
+		t.Palette = palette
+	}
+	return t
+}
+
+// seriesColor returns the color of the i-th series.
+func (t Theme) seriesColor(i int) string {
+	return t.Palette[i%len(t.Palette)]
+}
+
+// ParsePalette parses a comma-separated list of #rgb or #rrggbb colors,
+// returning them in #rrggbb form. An empty list returns nil.
+func ParsePalette(raw string) ([]string, error) {
+	var palette []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		color, err := normalizeHexColor(part)
+		if err != nil {
+			return nil, err
+		}
+		palette = append(palette, color)
+	}
+	return palette, nil
+}
+
+// normalizeHexColor expands #rgb to #rrggbb and lower-cases the result.
+func normalizeHexColor(s string) (string, error) {
+	hex, ok := strings.CutPrefix(strings.ToLower(s), "#")
+	valid := ok && (len(hex) == 3 || len(hex) == 6)
+	for _, c := range hex {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			valid = false
+		}
+	}
+	if !valid {
+		return "", fmt.Errorf("invalid color %q (expected #rgb or #rrggbb)", s)
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	return "#" + hex, nil
+}
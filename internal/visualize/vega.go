@@ -0,0 +1,179 @@
+package visualize
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// vegaLiteSchema is the Vega-Lite version the specs target.
+const vegaLiteSchema = "https://vega.github.io/schema/vega-lite/v5.json"
+
+// vegaSpec is the top level of a Vega-Lite specification. Fields are listed
+// in the order they read best in the emitted JSON.
+type vegaSpec struct {
+	Schema      string         `json:"$schema"`
+	Title       map[string]any `json:"title"`
+	Description string         `json:"description"`
+	Width       string         `json:"width"`
+	Height      int            `json:"height"`
+	Data        map[string]any `json:"data"`
+	Mark        map[string]any `json:"mark"`
+	Encoding    map[string]any `json:"encoding"`
+}
+
+// vegaDatum is one point of one series. Value holds the plotted metric and is
+// null where the name is absent, which breaks the line there.
+type vegaDatum struct {
+	Year     int      `json:"year"`
+	Series   string   `json:"series"`
+	Value    *float64 `json:"value"`
+	Rank     *int     `json:"rank"`
+	Count    *int     `json:"count"`
+	Share    *float64 `json:"share"`
+	Forecast bool     `json:"forecast"`
+}
+
+// VegaLite builds a Vega-Lite JSON specification of the trend data, with the
+// data inlined, for rendering interactive charts in notebooks and web
+// tooling. Series are colored with palette, or with the default palette of
+// the SVG and PNG charts when it is empty. Forecast points are dashed, and
+// every point carries a tooltip.
+func VegaLite(years []int, series []namesdata.TrendSeries, totals map[int]int, metric string, scope []string, palette []string) ([]byte, error) {
+	if len(years) == 0 || len(series) == 0 {
+		return nil, errors.New("vega: no data available")
+	}
+
+	var values []vegaDatum
+	present := false
+	for _, s := range series {
+		var rolling []float64
+		if metric == "volatility" {
+			rolling = s.RollingVolatility()
+		}
+		for i, point := range s.Points {
+			datum := vegaDatum{Year: point.Year, Series: s.Label(), Forecast: point.Forecast}
+			if point.Present {
+				rank, count := point.Rank, point.Count
+				datum.Rank, datum.Count = &rank, &count
+				var share *float64
+				if total := pointTotal(point, totals); total > 0 {
+					v := float64(point.Count) / float64(total)
+					share = &v
+				}
+				datum.Share = share
+
+				var value *float64
+				switch metric {
+				case "rank":
+					v := float64(rank)
+					value = &v
+				case "count":
+					v := float64(count)
+					value = &v
+				case "share":
+					value = share
+				case "volatility":
+					if v := rolling[i]; !math.IsNaN(v) {
+						value = &v
+					}
+				default:
+					return nil, fmt.Errorf("vega: unsupported metric %q", metric)
+				}
+				datum.Value = value
+				present = present || value != nil
+			}
+			values = append(values, datum)
+		}
+	}
+	if !present {
+		return nil, errors.New("vega: no data available for the selected metric")
+	}
+
+	y := map[string]any{"field": "value", "type": "quantitative", "title": vegaMetricTitle(metric)}
+	switch metric {
+	case "rank":
+		y["scale"] = map[string]any{"reverse": true, "zero": false}
+	case "share":
+		y["axis"] = map[string]any{"format": ".2%"}
+	}
+
+	labels := make([]string, len(series))
+	for i, s := range series {
+		labels[i] = s.Label()
+	}
+
+	title := fmt.Sprintf("Trend (%s)", metric)
+	if len(scope) > 0 {
+		title = fmt.Sprintf("Trend (%s, %s)", metric, strings.Join(scope, ", "))
+	}
+	spec := vegaSpec{
+		Schema:      vegaLiteSchema,
+		Title:       map[string]any{"text": title, "subtitle": fmt.Sprintf("%d–%d", years[0], years[len(years)-1])},
+		Description: fmt.Sprintf("Yearly %s of %s.", metric, strings.Join(labels, ", ")),
+		Width:       "container",
+		Height:      360,
+		Data:        map[string]any{"values": values},
+		// A null invalid mode breaks lines at years where a name is absent
+		// instead of joining across the gap.
+		Mark: map[string]any{"type": "line", "point": true, "invalid": nil},
+		Encoding: map[string]any{
+			"x": map[string]any{"field": "year", "type": "quantitative", "title": "Year", "axis": map[string]any{"format": "d"}},
+			"y": y,
+			"color": map[string]any{
+				"field":  "series",
+				"type":   "nominal",
+				"title":  "Name",
+				"sort":   labels,
+				"scale":  map[string]any{"domain": labels, "range": vegaPalette(len(labels), palette)},
+				"legend": map[string]any{"orient": "bottom"},
+			},
+			"strokeDash": map[string]any{
+				"field":  "forecast",
+				"type":   "nominal",
+				"title":  "Forecast",
+				"scale":  map[string]any{"domain": []bool{false, true}, "range": [][]int{{1, 0}, {4, 4}}},
+				"legend": nil,
+			},
+			"tooltip": []map[string]any{
+				{"field": "series", "title": "Name"},
+				{"field": "year", "title": "Year"},
+				{"field": "rank", "title": "Rank"},
+				{"field": "count", "title": "Count"},
+				{"field": "share", "title": "Share", "format": ".3%"},
+				{"field": "forecast", "title": "Forecast"},
+			},
+		},
+	}
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// vegaMetricTitle labels the y axis for metric.
+func vegaMetricTitle(metric string) string {
+	switch metric {
+	case "rank":
+		return "Rank (lower is more popular)"
+	case "count":
+		return "Births"
+	case "share":
+		return "Share of births"
+	case "volatility":
+		return "Rank volatility"
+	}
+	return metric
+}
+
+// vegaPalette returns n colors from palette, or from the default theme's
+// when palette is empty, cycling as needed.
+func vegaPalette(n int, palette []string) []string {
+	theme := DefaultTheme.WithPalette(palette)
+	colors := make([]string, n)
+	for i := range colors {
+		colors[i] = theme.seriesColor(i)
+	}
+	return colors
+}
@@ -0,0 +1,355 @@
+// Package wasmapi implements the queries cmd/names-wasm exposes to
+// JavaScript. Each query takes the caller's options object encoded as JSON
+// and returns its result as JSON, or {"error": "..."} when it fails, so the
+// WebAssembly glue only has to move strings across the JS boundary.
+package wasmapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing/fstest"
+	"time"
+
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+const (
+	// defaultTopLimit is the number of names Top returns when limit is not
+	// positive, matching the top command's default.
+	defaultTopLimit = 10
+	// maxCount bounds the names a single Generate call draws, matching the
+	// HTTP and gRPC servers' limit.
+	maxCount = 10000
+)
+
+// Dataset answers the queries over one set of state files. Each state's
+// records are parsed on first use and kept, so repeated queries do not
+// reread the files.
+type Dataset struct {
+	fsys fs.FS
+
+	mu sync.Mutex
+	// records maps an upper-case state code, or "" for every state, to
+	// its parsed records.
+	records map[string][]namesdata.Record
+}
+
+// New returns a Dataset reading the state files in fsys, such as the
+// embedded namesbystate files.
+func New(fsys fs.FS) *Dataset {
+	return &Dataset{fsys: fsys, records: make(map[string][]namesdata.Record)}
+}
+
+// Load returns a Dataset over files fetched by the page, mapping each file
+// name such as "CA.TXT" to its contents. Every file is parsed up front, so
+// a malformed file is reported here rather than by the first query.
+func Load(files map[string][]byte) (*Dataset, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no files given")
+	}
+	fsys := make(fstest.MapFS, len(files))
+	for name, data := range files {
+		if !fs.ValidPath(name) || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid file name %q", name)
+		}
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+
+	d := New(fsys)
+	records, err := namesdata.LoadAllRecords(fsys)
+	if err != nil {
+		return nil, err
+	}
+	d.records[""] = records
+	return d, nil
+}
+
+// stateRecords returns the records of state, or of every state when state
+// is empty, parsing them on first use. A state is taken from the records of
+// every state when those are already parsed.
+func (d *Dataset) stateRecords(state string) ([]namesdata.Record, error) {
+	key := strings.ToUpper(strings.TrimSpace(state))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if records, ok := d.records[key]; ok {
+		return records, nil
+	}
+
+	var (
+		records []namesdata.Record
+		err     error
+	)
+	switch all, ok := d.records[""]; {
+	case key == "":
+		records, err = namesdata.LoadAllRecords(d.fsys)
+	case ok:
+		for _, r := range all {
+			if strings.EqualFold(r.State, key) {
+				records = append(records, r)
+			}
+		}
+		if len(records) == 0 {
+			// Reading the state reports why it has no records.
+			records, err = namesdata.LoadStateRecords(d.fsys, key)
+		}
+	default:
+		records, err = namesdata.LoadStateRecords(d.fsys, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.records[key] = records
+	return records, nil
+}
+
+// aggregate totals the names matching opts from the cached records.
+func (d *Dataset) aggregate(opts options) ([]namesdata.NameCount, int, error) {
+	records, err := d.stateRecords(opts.State)
+	if err != nil {
+		return nil, 0, err
+	}
+	aggregated, _ := namesdata.AggregateNames(records, opts.Year, opts.Gender)
+	if len(aggregated) == 0 {
+		return nil, 0, namesdata.ErrNoMatches
+	}
+	total := 0
+	for _, entry := range aggregated {
+		total += entry.Count
+	}
+	return aggregated, total, nil
+}
+
+// options holds the fields of the options object every query accepts. Each
+// query reads only the fields it documents; missing fields are zero.
+type options struct {
+	State  string   `json:"state"`
+	Gender string   `json:"gender"`
+	Year   int      `json:"year"`
+	Limit  int      `json:"limit"`
+	Names  nameList `json:"names"`
+	From   int      `json:"from"`
+	To     int      `json:"to"`
+	Count  int      `json:"count"`
+	Seed   int64    `json:"seed"`
+	Unique bool     `json:"unique"`
+}
+
+// nameList is a list of names given either as an array of strings or as a
+// single comma-separated string.
+type nameList []string
+
+// UnmarshalJSON accepts an array of strings or a comma-separated string.
+func (n *nameList) UnmarshalJSON(data []byte) error {
+	var joined string
+	if err := json.Unmarshal(data, &joined); err == nil {
+		*n = strings.Split(joined, ",")
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("names must be an array of strings or a comma-separated string")
+	}
+	*n = names
+	return nil
+}
+
+// parseOptions decodes an options object. An empty string or "null" yields
+// the zero options.
+func parseOptions(raw string) (options, error) {
+	var opts options
+	if raw = strings.TrimSpace(raw); raw == "" || raw == "null" {
+		return opts, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+		return options{}, fmt.Errorf("options: %w", err)
+	}
+	opts.State = strings.TrimSpace(opts.State)
+	opts.Gender = strings.TrimSpace(opts.Gender)
+	return opts, nil
+}
+
+// topRow is one entry of Top's result.
+type topRow struct {
+	Rank  int    `json:"rank"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// trendResult is Trend's result. Totals holds the births of each year in
+// Years.
+type trendResult struct {
+	Years  []int         `json:"years"`
+	Totals []int         `json:"totals"`
+	Series []trendSeries `json:"series"`
+}
+
+// trendSeries is one name's points in a trendResult.
+type trendSeries struct {
+	Name   string       `json:"name"`
+	Points []trendPoint `json:"points"`
+}
+
+// trendPoint is a name's rank and count in one year.
+type trendPoint struct {
+	Year    int  `json:"year"`
+	Rank    int  `json:"rank"`
+	Count   int  `json:"count"`
+	Present bool `json:"present"`
+}
+
+// pick is one entry of Generate's result.
+type pick struct {
+	Name   string  `json:"name"`
+	Count  int     `json:"count"`
+	Chance float64 `json:"chance"`
+}
+
+// Top answers the JavaScript topNames({state, year, gender, limit}) with
+// [{rank, name, count}].
+func (d *Dataset) Top(raw string) string {
+	return call(raw, func(opts options) (any, error) {
+		return d.topNames(opts)
+	})
+}
+
+// Trend answers the JavaScript trend({state, gender, names, from, to}) with
+// {years, totals, series: [{name, points}]}, where each point is {year,
+// rank, count, present}.
+func (d *Dataset) Trend(raw string) string {
+	return call(raw, func(opts options) (any, error) {
+		return d.trendNames(opts)
+	})
+}
+
+// Generate answers the JavaScript generate({state, year, gender, count,
+// seed, unique}) with [{name, count, chance}]. count may be at most
+// maxCount.
+func (d *Dataset) Generate(raw string) string {
+	return call(raw, func(opts options) (any, error) {
+		return d.generateNames(opts)
+	})
+}
+
+// topNames returns the most popular names for opts, defaultTopLimit of them
+// unless opts.Limit is positive.
+func (d *Dataset) topNames(opts options) ([]topRow, error) {
+	aggregated, _, err := d.aggregate(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultTopLimit
+	}
+	aggregated = aggregated[:min(limit, len(aggregated))]
+
+	rows := make([]topRow, len(aggregated))
+	for i, entry := range aggregated {
+		rows[i] = topRow{Rank: i + 1, Name: entry.Name, Count: entry.Count}
+	}
+	return rows, nil
+}
+
+// trendNames returns the yearly rank and count of each of opts.Names.
+func (d *Dataset) trendNames(opts options) (trendResult, error) {
+	records, err := d.stateRecords(opts.State)
+	if err != nil {
+		return trendResult{}, err
+	}
+
+	years, series, totals, err := namesdata.Trend(records, opts.Gender, opts.Names, namesdata.YearRange{From: opts.From, To: opts.To})
+	if err != nil {
+		return trendResult{}, err
+	}
+
+	result := trendResult{Years: years, Totals: make([]int, len(years)), Series: make([]trendSeries, len(series))}
+	if result.Years == nil {
+		result.Years = []int{}
+	}
+	for i, year := range years {
+		result.Totals[i] = totals[year]
+	}
+	for i, s := range series {
+		points := make([]trendPoint, len(s.Points))
+		for j, p := range s.Points {
+			points[j] = trendPoint{Year: p.Year, Rank: p.Rank, Count: p.Count, Present: p.Present}
+		}
+		result.Series[i] = trendSeries{Name: s.Name, Points: points}
+	}
+	return result, nil
+}
+
+// generateNames draws opts.Count names (at least one) weighted by
+// popularity, distinct when opts.Unique is set. A zero opts.Seed seeds the
+// draw from the clock.
+func (d *Dataset) generateNames(opts options) ([]pick, error) {
+	if opts.Count > maxCount {
+		return nil, fmt.Errorf("count must be at most %d", maxCount)
+	}
+	aggregated, total, err := d.aggregate(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	count := max(opts.Count, 1)
+	sampler, err := namesdata.NewNameSamplerWithStrategy(aggregated, namesdata.SamplerAuto, count)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	var entries []namesdata.NameCount
+	if opts.Unique {
+		entries, err = sampler.PickUnique(count, rng)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		entries = make([]namesdata.NameCount, count)
+		for i := range entries {
+			if entries[i], err = sampler.Pick(rng); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	picks := make([]pick, len(entries))
+	for i, entry := range entries {
+		picks[i] = pick{Name: entry.Name, Count: entry.Count, Chance: float64(entry.Count) / float64(total)}
+	}
+	return picks, nil
+}
+
+// call parses the options in raw, runs query, and encodes its result or error.
+func call(raw string, query func(options) (any, error)) string {
+	opts, err := parseOptions(raw)
+	if err != nil {
+		return errorJSON(err)
+	}
+	result, err := query(opts)
+	if err != nil {
+		return errorJSON(err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorJSON(err)
+	}
+	return string(data)
+}
+
+func errorJSON(err error) string {
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(data)
+}
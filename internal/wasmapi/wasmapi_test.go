@@ -0,0 +1,198 @@
+package wasmapi_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/curtiscovington/ssa-names/internal/wasmapi"
+)
+
+func sampleFS() fstest.MapFS {
+	return fstest.MapFS{
+		"CA.TXT": {Data: []byte(
+			"CA,F,2019,Olivia,100\n" +
+				"CA,F,2019,Emma,90\n" +
+				"CA,M,2019,Liam,95\n" +
+				"CA,F,2018,Olivia,80\n" +
+				"CA,F,2018,Emma,50\n",
+		)},
+		"NY.TXT": {Data: []byte(
+			"NY,F,2019,Olivia,60\n" +
+				"NY,F,2018,Emma,45\n",
+		)},
+	}
+}
+
+func decode(t *testing.T, raw string, v any) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+}
+
+func errorOf(t *testing.T, raw string) string {
+	t.Helper()
+	var out struct {
+		Error string `json:"error"`
+	}
+	decode(t, raw, &out)
+	return out.Error
+}
+
+func TestTop(t *testing.T) {
+	var rows []struct {
+		Rank  int    `json:"rank"`
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	decode(t, wasmapi.New(sampleFS()).Top(`{"state": " ca ", "year": 2019, "limit": 2}`), &rows)
+	if len(rows) != 2 || rows[0].Name != "Olivia" || rows[0].Count != 100 || rows[1].Name != "Liam" || rows[1].Rank != 2 {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+
+	// No options means every state, year, and gender, up to ten names.
+	decode(t, wasmapi.New(sampleFS()).Top(""), &rows)
+	if len(rows) != 3 || rows[0].Name != "Olivia" || rows[0].Count != 240 {
+		t.Fatalf("unexpected rows without options: %+v", rows)
+	}
+
+	if msg := errorOf(t, wasmapi.New(sampleFS()).Top(`{"year": "2019"}`)); !strings.Contains(msg, "year") {
+		t.Fatalf("expected an error for a string year, got %q", msg)
+	}
+	if msg := errorOf(t, wasmapi.New(sampleFS()).Top(`{"state": "ZZ"}`)); msg == "" {
+		t.Fatal("expected an error for an unknown state")
+	}
+}
+
+func TestTrend(t *testing.T) {
+	var result struct {
+		Years  []int `json:"years"`
+		Totals []int `json:"totals"`
+		Series []struct {
+			Name   string `json:"name"`
+			Points []struct {
+				Year    int  `json:"year"`
+				Rank    int  `json:"rank"`
+				Count   int  `json:"count"`
+				Present bool `json:"present"`
+			} `json:"points"`
+		} `json:"series"`
+	}
+	decode(t, wasmapi.New(sampleFS()).Trend(`{"state": "CA", "gender": "F", "names": ["Emma", "Olivia"]}`), &result)
+	if len(result.Years) != 2 || result.Years[0] != 2018 || result.Totals[0] != 130 || result.Totals[1] != 190 {
+		t.Fatalf("unexpected years and totals: %+v", result)
+	}
+	if len(result.Series) != 2 || result.Series[0].Name != "Emma" || result.Series[0].Points[0].Count != 50 || result.Series[0].Points[0].Rank != 2 {
+		t.Fatalf("unexpected series: %+v", result.Series)
+	}
+
+	// Names may also be one comma-separated string.
+	decode(t, wasmapi.New(sampleFS()).Trend(`{"names": "Olivia,Liam", "from": 2019}`), &result)
+	if len(result.Years) != 1 || len(result.Series) != 2 || result.Series[1].Name != "Liam" || !result.Series[1].Points[0].Present {
+		t.Fatalf("unexpected comma-separated trend: %+v", result)
+	}
+
+	if msg := errorOf(t, wasmapi.New(sampleFS()).Trend(`{"names": 7}`)); !strings.Contains(msg, "names") {
+		t.Fatalf("expected an error for numeric names, got %q", msg)
+	}
+	if msg := errorOf(t, wasmapi.New(sampleFS()).Trend(`{}`)); msg == "" {
+		t.Fatal("expected an error without names")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	type pick struct {
+		Name   string  `json:"name"`
+		Count  int     `json:"count"`
+		Chance float64 `json:"chance"`
+	}
+	options := `{"state": "CA", "year": 2019, "gender": "F", "count": 5, "seed": 7}`
+	var picks, again []pick
+	decode(t, wasmapi.New(sampleFS()).Generate(options), &picks)
+	decode(t, wasmapi.New(sampleFS()).Generate(options), &again)
+	if len(picks) != 5 {
+		t.Fatalf("expected 5 picks, got %+v", picks)
+	}
+	for i, p := range picks {
+		if p != again[i] {
+			t.Fatalf("expected the same seed to repeat its picks, got %+v and %+v", picks, again)
+		}
+		// CA girls in 2019: Olivia 100 and Emma 90.
+		if (p.Name != "Olivia" || p.Count != 100) && (p.Name != "Emma" || p.Count != 90) || p.Chance != float64(p.Count)/190 {
+			t.Fatalf("unexpected pick %+v", p)
+		}
+	}
+
+	decode(t, wasmapi.New(sampleFS()).Generate(`{"state": "CA", "year": 2019, "count": 3, "unique": true}`), &picks)
+	seen := map[string]bool{}
+	for _, p := range picks {
+		seen[p.Name] = true
+	}
+	if len(picks) != 3 || len(seen) != 3 {
+		t.Fatalf("expected three distinct names, got %+v", picks)
+	}
+
+	if msg := errorOf(t, wasmapi.New(sampleFS()).Generate(`{"state": "CA", "year": 2019, "count": 4, "unique": true}`)); msg == "" {
+		t.Fatal("expected an error drawing more distinct names than match")
+	}
+}
+
+func TestGenerateCountLimit(t *testing.T) {
+	if msg := errorOf(t, wasmapi.New(sampleFS()).Generate(`{"count": 10001}`)); !strings.Contains(msg, "at most 10000") {
+		t.Fatalf("expected an error above the count limit, got %q", msg)
+	}
+}
+
+func TestDatasetKeepsRecords(t *testing.T) {
+	fsys := sampleFS()
+	d := wasmapi.New(fsys)
+	trend := `{"state": "NY", "names": ["Olivia"]}`
+	first := d.Trend(trend)
+
+	// Later queries reuse the parsed records instead of rereading the files.
+	fsys["NY.TXT"] = &fstest.MapFile{Data: []byte("NY,F,2019,Olivia,1\n")}
+	if again := d.Trend(trend); again != first {
+		t.Fatalf("expected the cached records, got %s then %s", first, again)
+	}
+	if fresh := wasmapi.New(fsys).Trend(trend); fresh == first {
+		t.Fatalf("expected a new dataset to read the changed file, got %s", fresh)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	d, err := wasmapi.Load(map[string][]byte{
+		"ZZ.TXT": []byte("ZZ,F,2019,Ada,5\nZZ,F,2019,Bea,3\n"),
+		"YY.TXT": []byte("YY,M,2019,Cal,4\n"),
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var rows []struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	decode(t, d.Top(""), &rows)
+	if len(rows) != 3 || rows[0].Name != "Ada" || rows[1].Name != "Cal" {
+		t.Fatalf("unexpected rows from loaded files: %+v", rows)
+	}
+	decode(t, d.Top(`{"state": "yy"}`), &rows)
+	if len(rows) != 1 || rows[0].Name != "Cal" || rows[0].Count != 4 {
+		t.Fatalf("unexpected rows for one loaded state: %+v", rows)
+	}
+	if msg := errorOf(t, d.Top(`{"state": "CA"}`)); msg == "" {
+		t.Fatal("expected an error for a state missing from the loaded files")
+	}
+
+	if _, err := wasmapi.Load(nil); err == nil {
+		t.Fatal("expected an error without files")
+	}
+	if _, err := wasmapi.Load(map[string][]byte{"../CA.TXT": []byte("CA,F,2019,Ada,5\n")}); err == nil {
+		t.Fatal("expected an error for a file name outside the dataset")
+	}
+	if _, err := wasmapi.Load(map[string][]byte{"CA.TXT": []byte("CA,F,year,Ada,5\n")}); err == nil {
+		t.Fatal("expected an error for a malformed file")
+	}
+}
@@ -0,0 +1,417 @@
+// Package ssanames exposes the Social Security Administration baby names by
+// state dataset as a Go library. It wraps the same loading, aggregation,
+// ranking, trend, and sampling code used by the names CLI, with the dataset
+// embedded so no files are needed at run time.
+//
+// Most callers start from Embedded:
+//
+//	top, err := ssanames.Embedded().Top("CA", 2019, "F", 5)
+//
+// Filters follow the CLI: an empty state means national totals, a year of 0
+// means all years, and an empty gender includes both M and F.
+package ssanames
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"math/rand"
+	"strings"
+
+	"github.com/curtiscovington/ssa-names/data/namesbystate"
+	"github.com/curtiscovington/ssa-names/internal/namesdata"
+)
+
+// Record is a single row of the dataset: one name's count for a state,
+// gender, and year.
+type Record = namesdata.Record
+
+// NameCount is an aggregated count for a name.
+type NameCount = namesdata.NameCount
+
+// YearRange is an inclusive range of years; a zero bound is open.
+type YearRange = namesdata.YearRange
+
+// NameRank is one name's result from Dataset.RankMany.
+type NameRank = namesdata.NameRank
+
+// RecordTable holds records in a compact columnar form with interned
+// strings; see Dataset.Table.
+type RecordTable = namesdata.RecordTable
+
+// TrendPoint is a name's rank and count in one year of a trend.
+type TrendPoint = namesdata.TrendPoint
+
+// TrendSeries is the chronological trend of a single name.
+type TrendSeries = namesdata.TrendSeries
+
+// YearAggregate holds the ranked names and total births for one year.
+type YearAggregate = namesdata.YearAggregate
+
+// DiversityStats summarizes how evenly births are spread across names.
+type DiversityStats = namesdata.DiversityStats
+
+// Appearance summarizes the years a name is recorded in, including its
+// longest run of consecutive years.
+type Appearance = namesdata.Appearance
+
+// GenderSplit holds a name's female and male counts.
+type GenderSplit = namesdata.GenderSplit
+
+// GenderRatio is a name's gender split in every year and overall, with the
+// year its dominant gender last changed.
+type GenderRatio = namesdata.GenderRatio
+
+// NameSampler draws names at random in proportion to their counts.
+type NameSampler = namesdata.NameSampler
+
+// SamplerStrategy selects how a NameSampler turns counts into picks.
+type SamplerStrategy = namesdata.SamplerStrategy
+
+// NameConstraints restricts the names a constrained sampler may draw; see
+// NewConstrainedNameSampler.
+type NameConstraints = namesdata.NameConstraints
+
+// Filter selects records while streaming; zero-valued fields match
+// everything.
+type Filter = namesdata.Filter
+
+// DatasetInfo describes the years, states, and records a dataset covers;
+// see Dataset.Describe.
+type DatasetInfo = namesdata.DatasetInfo
+
+// StateInfo describes one state's part of a dataset.
+type StateInfo = namesdata.StateInfo
+
+// UnknownStateError is returned when a state code is not in the dataset.
+type UnknownStateError = namesdata.UnknownStateError
+
+// NameNotFoundError is returned when a name is not in the data for a query;
+// it lists the closest names that are.
+type NameNotFoundError = namesdata.NameNotFoundError
+
+// Sampler strategies; see NewNameSamplerWithStrategy.
+const (
+	SamplerAuto  = namesdata.SamplerAuto
+	SamplerAlias = namesdata.SamplerAlias
+	SamplerCDF   = namesdata.SamplerCDF
+)
+
+// ErrNoMatches is returned when no records satisfy the provided filters.
+var ErrNoMatches = namesdata.ErrNoMatches
+
+// Trend is the result of a trend query: the years covered, one series per
+// requested name, and the total births per year for the filters.
+type Trend struct {
+	Years  []int
+	Series []TrendSeries
+	Totals map[int]int
+}
+
+// Smooth returns the trend with every year replaced by the centered moving
+// average of the window years around it, cut short at either end; window
+// must be a positive odd number. Counts and totals average with absent
+// years as zero, and ranks over the years a name was present. Forecast
+// points are left as they are.
+func (t Trend) Smooth(window int) (Trend, error) {
+	series, totals, err := namesdata.Smooth(t.Years, t.Series, t.Totals, window)
+	if err != nil {
+		return Trend{}, err
+	}
+	return Trend{Years: t.Years, Series: series, Totals: totals}, nil
+}
+
+// Dataset provides queries over a names-by-state dataset: a directory of
+// per-state files such as CA.TXT with lines of the form
+// "STATE,GENDER,YEAR,NAME,COUNT".
+type Dataset struct {
+	fsys   fs.FS
+	closer io.Closer
+}
+
+// Embedded returns the dataset compiled into the package.
+func Embedded() *Dataset {
+	return &Dataset{fsys: namesbystate.Files}
+}
+
+// Open returns a Dataset backed by fsys, e.g. os.DirFS on a freshly
+// downloaded copy of the SSA files.
+func Open(fsys fs.FS) *Dataset {
+	return &Dataset{fsys: fsys}
+}
+
+// LoadFromZip returns a Dataset read straight out of a zip archive of state
+// files, such as the SSA's namesbystate.zip, without unpacking it. Call
+// Close when done with the dataset to release the archive.
+func LoadFromZip(path string) (*Dataset, error) {
+	fsys, closer, err := namesdata.OpenZip(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := namesdata.ValidateDataset(fsys); err != nil {
+		closer.Close()
+		return nil, fmt.Errorf("zip %s: %w", path, err)
+	}
+	return &Dataset{fsys: fsys, closer: closer}, nil
+}
+
+// Merge returns a Dataset layering datasets, such as the embedded snapshot
+// and a directory holding a newer year's files. Counts are summed where two
+// datasets share a state, gender, year, and name. Closing the result does
+// not close datasets.
+func Merge(datasets ...*Dataset) *Dataset {
+	sources := make([]fs.FS, len(datasets))
+	for i, d := range datasets {
+		sources[i] = d.fsys
+	}
+	return &Dataset{fsys: namesdata.Merge(sources...)}
+}
+
+// Close releases the archive opened by LoadFromZip. It is a no-op for other
+// datasets.
+func (d *Dataset) Close() error {
+	if d.closer == nil {
+		return nil
+	}
+	err := d.closer.Close()
+	d.closer = nil
+	return err
+}
+
+// FS returns the file system backing the dataset.
+func (d *Dataset) FS() fs.FS {
+	return d.fsys
+}
+
+// States returns the sorted state codes available in the dataset.
+func (d *Dataset) States() ([]string, error) {
+	return namesdata.States(d.fsys)
+}
+
+// Describe reports the years the dataset covers, the states it holds, and
+// each state's record and birth counts, scanning every record once.
+func (d *Dataset) Describe() (DatasetInfo, error) {
+	return namesdata.Describe(d.fsys)
+}
+
+// Records loads every record for a state, or for all states when state is
+// empty.
+func (d *Dataset) Records(state string) ([]Record, error) {
+	return d.RecordsContext(context.Background(), state)
+}
+
+// RecordsContext is Records, returning ctx's error if ctx is done before
+// loading finishes.
+func (d *Dataset) RecordsContext(ctx context.Context, state string) ([]Record, error) {
+	if strings.TrimSpace(state) == "" {
+		return namesdata.LoadAllRecordsContext(ctx, d.fsys)
+	}
+	return namesdata.LoadStateRecordsContext(ctx, d.fsys, state)
+}
+
+// Table loads every record for a state, or for all states when state is
+// empty, into a RecordTable. It holds the same records as Records in a
+// fraction of the memory, for callers that keep the dataset loaded; use
+// RecordTable.Stream with the streaming analyses or RecordTable.Records for
+// the APIs that take a slice.
+func (d *Dataset) Table(state string) (*RecordTable, error) {
+	return d.TableContext(context.Background(), state)
+}
+
+// TableContext is Table, returning ctx's error if ctx is done before
+// loading finishes.
+func (d *Dataset) TableContext(ctx context.Context, state string) (*RecordTable, error) {
+	return namesdata.LoadRecordTableContext(ctx, d.fsys, state)
+}
+
+// Stream returns an iterator over the records matching filter. Unlike
+// Records it reads one file at a time, so memory stays flat however much of
+// the dataset the filter selects:
+//
+//	for rec, err := range data.Stream(ssanames.Filter{Name: "Ada", Gender: "F"}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(rec.State, rec.Year, rec.Count)
+//	}
+func (d *Dataset) Stream(filter Filter) iter.Seq2[Record, error] {
+	return namesdata.Records(d.fsys, filter)
+}
+
+// StreamContext is Stream, yielding ctx's error and ending the stream once
+// ctx is done.
+func (d *Dataset) StreamContext(ctx context.Context, filter Filter) iter.Seq2[Record, error] {
+	return namesdata.RecordsContext(ctx, d.fsys, filter)
+}
+
+// Aggregate returns every name matching the filters, sorted by count
+// descending, together with the total count. It streams the files rather
+// than materializing records.
+func (d *Dataset) Aggregate(state string, year int, gender string) ([]NameCount, int, error) {
+	return namesdata.AggregateFromFS(d.fsys, state, year, gender)
+}
+
+// AggregateContext is Aggregate, returning ctx's error if ctx is done before
+// the scan finishes.
+func (d *Dataset) AggregateContext(ctx context.Context, state string, year int, gender string) ([]NameCount, int, error) {
+	return namesdata.AggregateFromFSContext(ctx, d.fsys, state, year, gender)
+}
+
+// Top returns up to limit of the most popular names for the filters.
+func (d *Dataset) Top(state string, year int, gender string, limit int) ([]NameCount, error) {
+	aggregated, _, err := d.Aggregate(state, year, gender)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(aggregated) > limit {
+		aggregated = aggregated[:limit]
+	}
+	return aggregated, nil
+}
+
+// Rank returns the 1-based rank and aggregated count of name for the
+// filters. Names are matched case-insensitively.
+func (d *Dataset) Rank(state string, year int, gender, name string) (int, NameCount, error) {
+	records, err := d.Records(state)
+	if err != nil {
+		return 0, NameCount{}, err
+	}
+	return namesdata.Rank(records, year, gender, name)
+}
+
+// RankMany is Rank for several names, aggregating the filtered records only
+// once. It returns one NameRank per name in the order given; names not in
+// the data come back with Found false instead of failing the call.
+func (d *Dataset) RankMany(state string, year int, gender string, names []string) ([]NameRank, error) {
+	records, err := d.Records(state)
+	if err != nil {
+		return nil, err
+	}
+	return namesdata.RankMany(records, year, gender, names)
+}
+
+// Trend returns the yearly rank and count of each name for the filters.
+func (d *Dataset) Trend(state, gender string, names []string) (Trend, error) {
+	return d.TrendRange(state, gender, names, YearRange{})
+}
+
+// TrendRange is Trend restricted to the years in span, where a zero bound
+// is open. Only those years are read, so the ranks and totals are computed
+// over the window alone.
+func (d *Dataset) TrendRange(state, gender string, names []string, span YearRange) (Trend, error) {
+	years, series, totals, err := namesdata.TrendSeq(d.Stream(Filter{State: state, From: span.From, To: span.To}), gender, names, span)
+	if err != nil {
+		return Trend{}, err
+	}
+	return Trend{Years: years, Series: series, Totals: totals}, nil
+}
+
+// Sampler returns a sampler over the names matching the filters. Reuse it
+// for repeated draws; it is built for many picks.
+func (d *Dataset) Sampler(state string, year int, gender string) (*NameSampler, error) {
+	aggregated, _, err := d.Aggregate(state, year, gender)
+	if err != nil {
+		return nil, err
+	}
+	return namesdata.NewNameSampler(aggregated)
+}
+
+// SampleK draws k distinct names matching the filters, each weighted by
+// count among the names not yet drawn, in a single pass over the files
+// without building a sampler. It returns the picks in draw order and the
+// total count of the matching names. When r is nil a time-seeded RNG is
+// used. Prefer Sampler when drawing repeatedly from the same filters.
+func (d *Dataset) SampleK(state string, year int, gender string, k int, r *rand.Rand) ([]NameCount, int, error) {
+	return namesdata.SampleKFromFS(d.fsys, state, year, gender, k, r)
+}
+
+// AggregateNames totals records by name for the given year (0 for all) and
+// gender (empty for both). It returns the names sorted by count descending
+// and a map from upper-cased name to 1-based rank.
+func AggregateNames(records []Record, year int, gender string) ([]NameCount, map[string]int) {
+	return namesdata.AggregateNames(records, year, gender)
+}
+
+// Records streams the matching records of the dataset in fsys; see
+// Dataset.Stream.
+func Records(fsys fs.FS, filter Filter) iter.Seq2[Record, error] {
+	return namesdata.Records(fsys, filter)
+}
+
+// AggregateByYear groups records by year and ranks the names within each.
+func AggregateByYear(records []Record, gender string) []YearAggregate {
+	return namesdata.AggregateByYear(records, gender)
+}
+
+// Diversity measures the spread of births across names in records for the
+// given year (0 for all) and gender (empty for both): Shannon entropy, the
+// Herfindahl index, the Gini coefficient, and the effective number of names.
+func Diversity(records []Record, year int, gender string) (DiversityStats, error) {
+	return namesdata.Diversity(records, year, gender)
+}
+
+// Appearances reports the first and last years name (case-insensitive)
+// appears in records for the given gender (empty for both), and its longest
+// run of consecutive years. Pass one state's records to scope it to that
+// state. A *NameNotFoundError is returned when the name never appears.
+func Appearances(records []Record, gender, name string) (Appearance, error) {
+	return namesdata.Appearances(records, gender, name)
+}
+
+// FirstAppearance returns the first year name appears in records.
+func FirstAppearance(records []Record, gender, name string) (int, error) {
+	return namesdata.FirstAppearance(records, gender, name)
+}
+
+// LastAppearance returns the last year name appears in records.
+func LastAppearance(records []Record, gender, name string) (int, error) {
+	return namesdata.LastAppearance(records, gender, name)
+}
+
+// NameGenderRatio splits name (case-insensitive) by gender for every year
+// in records and overall, reporting the crossover year if its dominant
+// gender changed. A *NameNotFoundError is returned when the name never
+// appears.
+func NameGenderRatio(records []Record, name string) (GenderRatio, error) {
+	return namesdata.NameGenderRatio(records, name)
+}
+
+// NewNameSampler builds a sampler from aggregated counts for repeated draws.
+func NewNameSampler(aggregated []NameCount) (*NameSampler, error) {
+	return namesdata.NewNameSampler(aggregated)
+}
+
+// NewLockedRand returns a seeded *rand.Rand that Pick and PickUnique can
+// share across goroutines. Use PickConcurrent when draws need not be
+// reproducible.
+func NewLockedRand(seed int64) *rand.Rand {
+	return namesdata.NewLockedRand(seed)
+}
+
+// NewNameSamplerWithStrategy builds a sampler with an explicit strategy, or
+// with SamplerAuto picks the cheaper one for the expected number of draws.
+func NewNameSamplerWithStrategy(aggregated []NameCount, strategy SamplerStrategy, expectedDraws int) (*NameSampler, error) {
+	return namesdata.NewNameSamplerWithStrategy(aggregated, strategy, expectedDraws)
+}
+
+// NewSecureRand returns a *rand.Rand backed by crypto/rand, for samplers
+// whose picks must not be predictable. Its draws cannot be reproduced.
+func NewSecureRand() *rand.Rand {
+	return namesdata.NewSecureRand()
+}
+
+// NewTemperedNameSampler builds a sampler weighting each name by its count
+// raised to temperature: below 1 favors rarer names, above 1 the most
+// popular.
+func NewTemperedNameSampler(aggregated []NameCount, temperature float64, strategy SamplerStrategy, expectedDraws int) (*NameSampler, error) {
+	return namesdata.NewTemperedNameSampler(aggregated, temperature, strategy, expectedDraws)
+}
+
+// NewConstrainedNameSampler builds a sampler over only the names matching
+// constraints, such as a prefix or a maximum length, so each pick is
+// weighted among those names.
+func NewConstrainedNameSampler(aggregated []NameCount, constraints NameConstraints, strategy SamplerStrategy, expectedDraws int) (*NameSampler, error) {
+	return namesdata.NewConstrainedNameSampler(aggregated, constraints, strategy, expectedDraws)
+}
@@ -0,0 +1,138 @@
+package ssanames_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"testing/fstest"
+
+	"github.com/curtiscovington/ssa-names/pkg/ssanames"
+)
+
+func TestDatasetQueries(t *testing.T) {
+	data := ssanames.Open(fstest.MapFS{
+		"CA.TXT": {Data: []byte(
+			"CA,F,2019,Olivia,140\n" +
+				"CA,F,2019,Emma,90\n" +
+				"CA,M,2019,Liam,95\n" +
+				"CA,F,2018,Emma,50\n"),
+		},
+	})
+
+	top, err := data.Top("CA", 2019, "F", 1)
+	if err != nil {
+		t.Fatalf("Top: %v", err)
+	}
+	if len(top) != 1 || top[0].Name != "Olivia" {
+		t.Fatalf("unexpected top names: %+v", top)
+	}
+
+	rank, entry, err := data.Rank("", 0, "", "emma")
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+	if rank != 1 || entry.Count != 140 {
+		t.Fatalf("expected Emma rank 1 with 140, got %d %+v", rank, entry)
+	}
+
+	many, err := data.RankMany("", 2019, "", []string{"Liam", "Zed", "emma"})
+	if err != nil {
+		t.Fatalf("RankMany: %v", err)
+	}
+	if len(many) != 3 || many[0].Rank != 2 || many[1].Found() || many[2].Entry.Count != 90 {
+		t.Fatalf("unexpected RankMany result: %+v", many)
+	}
+
+	trend, err := data.Trend("CA", "F", []string{"Emma"})
+	if err != nil {
+		t.Fatalf("Trend: %v", err)
+	}
+	if len(trend.Years) != 2 || trend.Series[0].Points[0].Count != 50 || trend.Totals[2019] != 230 {
+		t.Fatalf("unexpected trend: %+v", trend)
+	}
+
+	windowed, err := data.TrendRange("CA", "F", []string{"Emma"}, ssanames.YearRange{From: 2019})
+	if err != nil {
+		t.Fatalf("TrendRange: %v", err)
+	}
+	if len(windowed.Years) != 1 || windowed.Years[0] != 2019 || windowed.Series[0].Points[0].Rank != 2 || windowed.Totals[2018] != 0 {
+		t.Fatalf("unexpected windowed trend: %+v", windowed)
+	}
+	if _, err := data.TrendRange("CA", "F", []string{"Emma"}, ssanames.YearRange{From: 2019, To: 2018}); err == nil {
+		t.Fatal("expected an error for an inverted range")
+	}
+
+	smoothed, err := trend.Smooth(3)
+	if err != nil {
+		t.Fatalf("Smooth: %v", err)
+	}
+	if smoothed.Totals[2019] != 140 || smoothed.Series[0].Points[1].Count != 70 || trend.Totals[2019] != 230 {
+		t.Fatalf("unexpected smoothed trend: %+v", smoothed)
+	}
+
+	sampler, err := data.Sampler("CA", 2019, "M")
+	if err != nil {
+		t.Fatalf("Sampler: %v", err)
+	}
+	if pick, err := sampler.Pick(rand.New(rand.NewSource(1))); err != nil || pick.Name != "Liam" {
+		t.Fatalf("unexpected pick %+v: %v", pick, err)
+	}
+
+	if pick, err := sampler.Pick(ssanames.NewLockedRand(1)); err != nil || pick.Name != "Liam" {
+		t.Fatalf("unexpected locked pick %+v: %v", pick, err)
+	}
+	if pick, err := sampler.PickConcurrent(); err != nil || pick.Name != "Liam" {
+		t.Fatalf("unexpected concurrent pick %+v: %v", pick, err)
+	}
+
+	distinct, total, err := data.SampleK("", 2019, "", 3, rand.New(rand.NewSource(2)))
+	if err != nil || len(distinct) != 3 || total != 325 {
+		t.Fatalf("unexpected SampleK result %+v, %d: %v", distinct, total, err)
+	}
+
+	streamed := 0
+	for rec, err := range data.Stream(ssanames.Filter{Gender: "F", Name: "emma"}) {
+		if err != nil {
+			t.Fatalf("Stream: %v", err)
+		}
+		streamed += rec.Count
+	}
+	if streamed != 140 {
+		t.Fatalf("expected 140 streamed Emmas, got %d", streamed)
+	}
+
+	records, err := data.Records("CA")
+	if err != nil {
+		t.Fatalf("Records: %v", err)
+	}
+	if first, err := ssanames.FirstAppearance(records, "F", "emma"); err != nil || first != 2018 {
+		t.Fatalf("FirstAppearance = %d, %v", first, err)
+	}
+	if a, err := ssanames.Appearances(records, "F", "Emma"); err != nil || a.Last != 2019 || a.Streak() != 2 {
+		t.Fatalf("unexpected appearance %+v: %v", a, err)
+	}
+
+	if ratio, err := ssanames.NameGenderRatio(records, "Emma"); err != nil || ratio.Overall.Female != 140 || ratio.Crossover != 0 {
+		t.Fatalf("unexpected gender ratio %+v: %v", ratio, err)
+	}
+
+	var unknown *ssanames.UnknownStateError
+	if _, err := data.Records("TX"); !errors.As(err, &unknown) {
+		t.Fatalf("expected UnknownStateError, got %v", err)
+	}
+}
+
+func ExampleDataset_Top() {
+	top, err := ssanames.Embedded().Top("CA", 2019, "F", 3)
+	if err != nil {
+		panic(err)
+	}
+	for i, entry := range top {
+		fmt.Printf("%d. %s (%d)\n", i+1, entry.Name, entry.Count)
+	}
+	// Output:
+	// 1. Olivia (2610)
+	// 2. Emma (2402)
+	// 3. Mia (2366)
+}